@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"cloud.google.com/go/firestore"
 	"cloud.google.com/go/storage"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -83,7 +84,10 @@ func main() {
 
 	// Initialize AI clients
 	var openaiClient *clients.OpenAIClient
-	if cfg.OpenAI.APIKey != "" {
+	if cfg.Development.TestMode {
+		openaiClient = clients.NewSandboxOpenAIClient(&cfg.OpenAI, logger)
+		logger.Warn("Test mode: using sandbox OpenAI client")
+	} else if cfg.OpenAI.APIKey != "" {
 		openaiClient, err = clients.NewOpenAIClient(&cfg.OpenAI, logger)
 		if err != nil {
 			logger.Warn("Failed to initialize OpenAI client", zap.Error(err))
@@ -123,7 +127,10 @@ func main() {
 
 	// Initialize Stripe client
 	var stripeClient *clients.StripeClient
-	if cfg.Stripe.SecretKey != "" {
+	if cfg.Development.TestMode {
+		stripeClient = clients.NewSandboxStripeClient(&cfg.Stripe, logger)
+		logger.Warn("Test mode: using sandbox Stripe client")
+	} else if cfg.Stripe.SecretKey != "" {
 		var err error
 		stripeClient, err = clients.NewStripeClient(&cfg.Stripe, logger)
 		if err != nil {
@@ -137,7 +144,10 @@ func main() {
 
 	// Initialize Plaid client
 	var plaidClient *clients.PlaidClient
-	if cfg.Plaid.ClientID != "" && cfg.Plaid.Secret != "" {
+	if cfg.Development.TestMode {
+		plaidClient = clients.NewSandboxPlaidClient(logger)
+		logger.Warn("Test mode: using sandbox Plaid client")
+	} else if cfg.Plaid.ClientID != "" && cfg.Plaid.Secret != "" {
 		var err error
 		plaidClient, err = clients.NewPlaidClient(&cfg.Plaid, logger)
 		if err != nil {
@@ -158,15 +168,54 @@ func main() {
 		logger.Warn("Alpha Vantage not configured - stock price features will not work")
 	}
 
-	// Initialize repository
-	repo := repository.NewFirestoreRepository(fbAdmin.Firestore)
+	// Initialize exchange rate client
+	var fxClient services.FXRateProvider
+	if cfg.Development.TestMode {
+		logger.Warn("Test mode: currency conversion disabled")
+	} else {
+		fxClient = clients.NewFXClient("", logger)
+		logger.Info("Exchange rate client initialized")
+	}
+
+	// Initialize repository, with any databases admins may select per request
+	overrideDatabases := make(map[string]*firestore.Client, len(cfg.Firebase.OverrideDatabases))
+	for _, databaseID := range cfg.Firebase.OverrideDatabases {
+		client, err := fbAdmin.OpenFirestoreDatabase(ctx, databaseID)
+		if err != nil {
+			logger.Fatal("Failed to open override Firestore database", zap.String("database", databaseID), zap.Error(err))
+		}
+		overrideDatabases[databaseID] = client
+	}
+	repo := repository.NewFirestoreRepositoryWithDatabases(fbAdmin.Firestore, overrideDatabases)
+	if len(overrideDatabases) > 0 {
+		logger.Info("Firestore database overrides enabled for admins", zap.Strings("databases", cfg.Firebase.OverrideDatabases))
+	}
 
 	// Initialize services
-	contextGatherer := services.NewContextGathererService(repo, logger)
+	contextGatherer := services.NewContextGathererService(repo, logger, cfg.AIContext.MaxContextTokens)
 	subscriptionSvc := services.NewSubscriptionService(repo, logger, cfg.Anonymous.AIOverrideKey)
-	actionProcessor := services.NewActionProcessor(repo, logger)
+	auditService := services.NewAuditService(repo, logger)
+
+	// Initialize entity graph service
+	entityGraphSvc := services.NewEntityGraphService(repo, logger, cfg.EntityGraph.MaxRelationshipsPerEntity)
+	logger.Info("Entity graph service initialized")
+
+	actionProcessor := services.NewActionProcessor(repo, logger, entityGraphSvc)
+
+	// Initialize anonymous quota and cleanup service
+	anonymousService := services.NewAnonymousService(repo, fbAdmin.Auth, logger, &cfg.Anonymous, cfg.Workers.AnonymousCleanup.BatchSize)
+
+	// Initialize LLM log recording and compaction
+	llmLogService := services.NewLLMLogService(repo, logger, cfg.LLMLogs)
+
+	// Initialize per-user task categories
+	taskCategoryService := services.NewTaskCategoryService(repo, logger)
 
 	// Initialize thought processing service
+	var aiResponseCache *services.AIResponseCache
+	if cfg.AICache.Enabled {
+		aiResponseCache = services.NewAIResponseCache(repo, logger, cfg.AICache.TTL)
+	}
 	var thoughtProcessingSvc *services.ThoughtProcessingService
 	if openaiClient != nil || anthropicClient != nil {
 		thoughtProcessingSvc = services.NewThoughtProcessingService(
@@ -177,6 +226,10 @@ func main() {
 			subscriptionSvc,
 			actionProcessor,
 			logger,
+			cfg.AIReprocess,
+			aiResponseCache,
+			anonymousService,
+			llmLogService,
 		)
 		logger.Info("Thought processing service initialized")
 	}
@@ -184,35 +237,41 @@ func main() {
 	// Initialize Stripe billing service
 	var stripeBillingSvc *services.StripeBillingService
 	if stripeClient != nil {
-		stripeBillingSvc = services.NewStripeBillingService(stripeClient, repo, logger)
+		stripeBillingSvc = services.NewStripeBillingService(stripeClient, repo, logger, auditService)
 		logger.Info("Stripe billing service initialized")
 	}
 
+	// Initialize merchant alias service
+	merchantAliasSvc := services.NewMerchantAliasService(repo, logger, &cfg.Merchants)
+
 	// Initialize Plaid service
 	var plaidService *services.PlaidService
 	if plaidClient != nil {
-		plaidService = services.NewPlaidService(plaidClient, repo, logger)
+		plaidService = services.NewPlaidService(plaidClient, repo, logger, merchantAliasSvc)
 		logger.Info("Plaid service initialized")
 	}
 
+	// Initialize currency service (always available)
+	currencySvc := services.NewCurrencyService(repo, logger, fxClient)
+
 	// Initialize analytics services
-	dashboardAnalyticsSvc := services.NewDashboardAnalyticsService(repo, logger)
+	activityIndexSvc := services.NewActivityIndexService(repo, logger)
+	dashboardAnalyticsSvc := services.NewDashboardAnalyticsService(repo, logger, activityIndexSvc)
 	logger.Info("Dashboard analytics service initialized")
-	spendingAnalyticsSvc := services.NewSpendingAnalyticsService(repo, logger)
+	spendingAnalyticsSvc := services.NewSpendingAnalyticsService(repo, logger, merchantAliasSvc, currencySvc)
 	logger.Info("Spending analytics service initialized")
 
 	// Initialize import/export service
-	importExportSvc := services.NewImportExportService(repo, logger)
+	importExportSvc := services.NewImportExportService(
+		repo, logger, cfg.ImportExport.BatchSize, cfg.ImportExport.ExportLimits,
+		cfg.ImportExport.SummaryConcurrency, cfg.ImportExport.SummaryCacheTTL, anonymousService,
+	)
 	logger.Info("Import/export service initialized")
 
 	// Initialize investment calculation service
-	investmentCalcSvc := services.NewInvestmentCalculationService(repo, logger)
+	investmentCalcSvc := services.NewInvestmentCalculationService(repo, logger, cfg.Investment.MaxProjectionMonths, currencySvc)
 	logger.Info("Investment calculation service initialized")
 
-	// Initialize entity graph service
-	entityGraphSvc := services.NewEntityGraphService(repo, logger)
-	logger.Info("Entity graph service initialized")
-
 	// Initialize stock service
 	var stockService *services.StockService
 	if alphaVantageClient != nil {
@@ -227,6 +286,9 @@ func main() {
 		logger.Info("Investment prediction service initialized")
 	}
 
+	// Initialize CSV mapping service (always available)
+	csvMappingSvc := services.NewCSVMappingService(repo, logger)
+
 	// Initialize CSV processing service
 	var csvProcessingSvc *services.CSVProcessingService
 	if storageClient != nil && categorizationSvc != nil {
@@ -236,6 +298,7 @@ func main() {
 			categorizationSvc,
 			cfg.Firebase.StorageBucket,
 			logger,
+			csvMappingSvc,
 		)
 		logger.Info("CSV processing service initialized")
 	} else {
@@ -256,10 +319,84 @@ func main() {
 		logger.Warn("Photo service disabled (Cloud Storage not available)")
 	}
 
+	// Initialize batch photo uploads (needs Cloud Storage)
+	var photoUploadService *services.PhotoUploadService
+	if photoService != nil {
+		photoUploadService = services.NewPhotoUploadService(repo, logger, photoService, cfg.Upload)
+	}
+
+	// Initialize photo library service (tags and albums; no Cloud Storage needed)
+	photoLibraryService := services.NewPhotoLibraryService(repo, logger)
+	logger.Info("Photo library service initialized")
+
 	// Initialize packing list service
 	packingListService := services.NewPackingListService(repo, logger)
 	logger.Info("Packing list service initialized")
 
+	// Initialize task service
+	taskService := services.NewTaskService(repo, logger, openaiClient, subscriptionSvc, llmLogService)
+	logger.Info("Task service initialized")
+
+	// Initialize feature flag service
+	featureFlagService := services.NewFeatureFlagService(repo, logger, cfg.FeatureFlags.Defaults, cfg.FeatureFlags.CacheTTL)
+	logger.Info("Feature flag service initialized")
+
+	// Initialize tag service
+	tagService := services.NewTagService(repo, logger)
+	logger.Info("Tag service initialized")
+
+	// Initialize attachment service; removed attachments keep their storage
+	// objects when Cloud Storage is unavailable
+	var attachmentStorage services.AttachmentStorage
+	if photoService != nil {
+		attachmentStorage = photoService
+	}
+	attachmentService := services.NewAttachmentService(repo, logger, attachmentStorage)
+	logger.Info("Attachment service initialized")
+
+	// Initialize account usage service; usage has no storage total when
+	// Cloud Storage is unavailable
+	var storageSizer services.StorageSizer
+	if photoService != nil {
+		storageSizer = photoService
+	}
+	accountUsageService := services.NewAccountUsageService(repo, logger, storageSizer, cfg.AccountUsage)
+
+	// Initialize streak service
+	streakService := services.NewStreakService(repo, logger)
+	logger.Info("Streak service initialized")
+
+	// Initialize outbound notifications (Slack, Discord)
+	notificationService := services.NewNotificationService(repo, logger, cfg.Retry)
+	logger.Info("Notification service initialized")
+
+	// Initialize link previews for URLs in thoughts
+	linkPreviewService := services.NewLinkPreviewService(logger)
+
+	// Initialize mood service
+	moodService := services.NewMoodService(repo, logger)
+	logger.Info("Mood service initialized")
+
+	// Initialize calendar feed service
+	calendarFeedService := services.NewCalendarFeedService(repo, logger)
+	logger.Info("Calendar feed service initialized")
+
+	// Initialize document service
+	documentService := services.NewDocumentService(repo, logger, &cfg.Documents, tagService, notificationService, attachmentStorage)
+	logger.Info("Document service initialized")
+
+	// Initialize incoming webhook service
+	ingestService := services.NewIngestService(repo, logger, documentService, taskService, moodService, cfg.RateLimit.IngestPerToken)
+
+	// Initialize goal tracking service
+	goalService := services.NewGoalService(repo, logger, cfg.Goals)
+	todayService := services.NewTodayService(repo, logger, streakService, goalService)
+	financeDashboardService := services.NewFinanceDashboardService(repo, logger, spendingAnalyticsSvc, investmentCalcSvc, goalService, currencySvc, cfg.Finance)
+
+	// Initialize focus session service
+	focusSessionService := services.NewFocusSessionService(repo, logger, activityIndexSvc)
+	logger.Info("Focus session service initialized")
+
 	// Initialize place insights service
 	var placeInsightsService *services.PlaceInsightsService
 	if openaiClient != nil {
@@ -271,6 +408,33 @@ func main() {
 	visaService := services.NewVisaService(repo, logger)
 	logger.Info("Visa service initialized")
 
+	anonymousDocuments := middleware.AnonymousQuota(anonymousService, services.AnonymousQuotaDocuments)
+	anonymousAICalls := middleware.AnonymousQuota(anonymousService, services.AnonymousQuotaAICalls)
+	// uploadLimited caps each user's in-flight uploads across every route it wraps
+	uploadLimit := middleware.UploadConcurrency(cfg.Upload.MaxConcurrentPerUser)
+	uploadLimited := func(h http.HandlerFunc) http.Handler {
+		return uploadLimit(h)
+	}
+	audited := func(action string, h http.HandlerFunc) http.Handler {
+		return middleware.Audit(auditService, action)(h)
+	}
+	logger.Info("Anonymous service initialized")
+
+	// Initialize scheduled jobs triggered through POST /api/internal/cron/{job}
+	cronRegistry := services.NewCronRegistry(logger, cfg.Cron)
+	cronRegistry.Register("anonymous-cleanup", func(ctx context.Context) (map[string]interface{}, error) {
+		purged, err := anonymousService.PurgeExpired(ctx)
+		return map[string]interface{}{"purgedSessions": purged}, err
+	})
+	cronRegistry.Register("llm-log-compaction", func(ctx context.Context) (map[string]interface{}, error) {
+		compacted, err := llmLogService.CompactExpired(ctx)
+		return map[string]interface{}{"compactedLogs": compacted}, err
+	})
+	logger.Info("Cron jobs registered", zap.Strings("jobs", cronRegistry.Names()))
+
+	// Initialize webhook deduplication (shared by Stripe and Plaid)
+	webhookDedup := services.NewWebhookIdempotencyService(repo, logger, cfg.Webhooks.DedupTTL)
+
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(
 		fbAdmin.Auth,
@@ -288,16 +452,17 @@ func main() {
 
 	var stripeHandler *handlers.StripeHandler
 	if stripeBillingSvc != nil {
-		stripeHandler = handlers.NewStripeHandler(stripeClient, stripeBillingSvc, logger)
+		stripeHandler = handlers.NewStripeHandler(stripeClient, stripeBillingSvc, webhookDedup, logger)
 	}
 
 	var plaidHandler *handlers.PlaidHandler
 	if plaidService != nil {
-		plaidHandler = handlers.NewPlaidHandler(plaidService, logger)
+		plaidHandler = handlers.NewPlaidHandler(plaidService, webhookDedup, logger)
 	}
 
 	// Analytics handler (always available)
 	analyticsHandler := handlers.NewAnalyticsHandler(dashboardAnalyticsSvc, spendingAnalyticsSvc, logger)
+	streakHandler := handlers.NewStreakHandler(streakService, logger)
 
 	// Import/export handler (always available)
 	importExportHandler := handlers.NewImportExportHandler(importExportSvc, logger)
@@ -336,10 +501,73 @@ func main() {
 		logger.Info("Photo handler initialized")
 	}
 
+	// Photo upload handler
+	var photoUploadHandler *handlers.PhotoUploadHandler
+	if photoUploadService != nil {
+		photoUploadHandler = handlers.NewPhotoUploadHandler(photoUploadService, logger)
+	}
+
 	// Packing list handler (always available)
 	packingListHandler := handlers.NewPackingListHandler(packingListService, logger)
 	logger.Info("Packing list handler initialized")
 
+	// Photo library handler (always available)
+	photoLibraryHandler := handlers.NewPhotoLibraryHandler(photoLibraryService, logger)
+	logger.Info("Photo library handler initialized")
+
+	// Task handler (always available)
+	taskHandler := handlers.NewTaskHandler(taskService, logger)
+	logger.Info("Task handler initialized")
+
+	// Feature flag handler (always available)
+	featureFlagHandler := handlers.NewFeatureFlagHandler(featureFlagService, logger)
+	logger.Info("Feature flag handler initialized")
+
+	// Merchant alias handler (always available)
+	merchantAliasHandler := handlers.NewMerchantAliasHandler(merchantAliasSvc, logger)
+	logger.Info("Merchant alias handler initialized")
+
+	// Preferences handler (always available)
+	preferencesHandler := handlers.NewPreferencesHandler(currencySvc, logger)
+
+	// LLM log settings handler (always available)
+	llmLogHandler := handlers.NewLLMLogHandler(llmLogService, logger)
+
+	// Task category handler (always available)
+	taskCategoryHandler := handlers.NewTaskCategoryHandler(taskCategoryService, logger)
+
+	// Account usage handler (always available)
+	accountUsageHandler := handlers.NewAccountUsageHandler(accountUsageService, logger)
+
+	// CSV mapping handler (always available)
+	csvMappingHandler := handlers.NewCSVMappingHandler(csvMappingSvc, logger)
+	logger.Info("CSV mapping handler initialized")
+
+	// Attachment handler (always available)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, logger)
+
+	// Tag handler (always available)
+	tagHandler := handlers.NewTagHandler(tagService, logger)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, logger)
+	linkPreviewHandler := handlers.NewLinkPreviewHandler(linkPreviewService, logger)
+	calendarHandler := handlers.NewCalendarHandler(calendarFeedService, logger)
+	ingestHandler := handlers.NewIngestHandler(ingestService, logger)
+	moodHandler := handlers.NewMoodHandler(moodService, logger)
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	cronHandler := handlers.NewCronHandler(cronRegistry, cfg.Cron.Secret, logger)
+	logger.Info("Tag handler initialized")
+
+	// Document handler (always available)
+	documentHandler := handlers.NewDocumentHandler(documentService, logger)
+	logger.Info("Document handler initialized")
+
+	// Focus session handler (always available)
+	focusSessionHandler := handlers.NewFocusSessionHandler(focusSessionService, logger)
+	goalHandler := handlers.NewGoalHandler(goalService, logger)
+	todayHandler := handlers.NewTodayHandler(todayService, logger)
+	financeDashboardHandler := handlers.NewFinanceDashboardHandler(financeDashboardService, logger)
+	logger.Info("Focus session handler initialized")
+
 	// Place insights handler
 	var placeInsightsHandler *handlers.PlaceInsightsHandler
 	if placeInsightsService != nil {
@@ -358,6 +586,8 @@ func main() {
 	router.Use(middleware.Recovery(logger))
 	router.Use(middleware.Logging(logger))
 	router.Use(middleware.CORS(&cfg.Server.CORS))
+	router.Use(middleware.CSRF(&cfg.Server.CSRF))
+	router.Use(middleware.Timeout(&cfg.Server.RequestTimeout))
 
 	// Health and metrics (no auth required)
 	router.HandleFunc("/health", healthHandler.Handle).Methods("GET")
@@ -365,9 +595,29 @@ func main() {
 		router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 	}
 
+	// Calendar feed (no auth - uses the feed token); registered before the
+	// API subrouter so the generic document routes do not capture it
+	router.HandleFunc("/api/calendar/feed.ics", calendarHandler.GetFeed).Methods("GET")
+
+	// Incoming webhook (no auth - uses the ingest token)
+	router.HandleFunc("/api/ingest/{token}", ingestHandler.Ingest).Methods("POST")
+
+	// Scheduled jobs (no auth - uses the shared cron secret)
+	if cfg.Cron.Secret != "" {
+		router.HandleFunc("/api/internal/cron/{job}", cronHandler.RunJob).Methods("POST")
+		logger.Info("Cron endpoint registered")
+	} else {
+		logger.Warn("Cron endpoint disabled (no cron secret configured)")
+	}
+
 	// API routes (require authentication)
 	api := router.PathPrefix("/api").Subrouter()
 	api.Use(authMiddleware.Authenticate)
+	api.Use(middleware.DatabaseOverride(repo.HasDatabase))
+	api.Use(middleware.SafeMode(cfg.Server.SafeMode, destructiveRoutes))
+	if cfg.Server.SafeMode {
+		logger.Warn("Safe mode enabled: destructive endpoints are disabled")
+	}
 
 	// Thought processing routes (requires AI access)
 	if thoughtHandler != nil {
@@ -377,6 +627,9 @@ func main() {
 		thoughtRoutes.HandleFunc("/process-thought", thoughtHandler.ProcessThought).Methods("POST")
 		thoughtRoutes.HandleFunc("/reprocess-thought", thoughtHandler.ReprocessThought).Methods("POST")
 		thoughtRoutes.HandleFunc("/revert-thought-processing", thoughtHandler.RevertThoughtProcessing).Methods("POST")
+		thoughtRoutes.HandleFunc("/reprocess-thoughts", thoughtHandler.StartReprocess).Methods("POST")
+		thoughtRoutes.HandleFunc("/reprocess-thoughts/{jobId}", thoughtHandler.GetReprocessStatus).Methods("GET")
+		thoughtRoutes.HandleFunc("/reprocess-thoughts/{jobId}/cancel", thoughtHandler.CancelReprocess).Methods("POST")
 	} else {
 		logger.Warn("Thought processing endpoints disabled (no AI clients configured)")
 	}
@@ -392,7 +645,7 @@ func main() {
 		stripeRoutes.HandleFunc("/create-portal-session", stripeHandler.CreatePortalSession).Methods("POST")
 		stripeRoutes.HandleFunc("/invoices", stripeHandler.GetInvoices).Methods("GET")
 		stripeRoutes.HandleFunc("/payment-method", stripeHandler.GetPaymentMethod).Methods("GET")
-		stripeRoutes.HandleFunc("/reactivate-subscription", stripeHandler.ReactivateSubscription).Methods("POST")
+		stripeRoutes.Handle("/reactivate-subscription", audited(services.AuditActionSubscriptionChange, stripeHandler.ReactivateSubscription)).Methods("POST")
 		stripeRoutes.HandleFunc("/usage-stats", stripeHandler.GetUsageStats).Methods("GET")
 
 		logger.Info("Stripe endpoints registered")
@@ -407,8 +660,9 @@ func main() {
 
 		// Authenticated Plaid endpoints
 		plaidRoutes := api.PathPrefix("/plaid").Subrouter()
+		plaidRoutes.Use(middleware.RequireFeature(featureFlagService, services.FeatureBanking))
 		plaidRoutes.HandleFunc("/create-link-token", plaidHandler.CreateLinkToken).Methods("POST")
-		plaidRoutes.HandleFunc("/exchange-public-token", plaidHandler.ExchangePublicToken).Methods("POST")
+		plaidRoutes.Handle("/exchange-public-token", audited(services.AuditActionPlaidConnect, plaidHandler.ExchangePublicToken)).Methods("POST")
 		plaidRoutes.HandleFunc("/create-relink-token", plaidHandler.CreateRelinkToken).Methods("POST")
 		plaidRoutes.HandleFunc("/mark-relinking", plaidHandler.MarkRelinking).Methods("POST")
 		plaidRoutes.HandleFunc("/trigger-sync", plaidHandler.TriggerSync).Methods("POST")
@@ -418,26 +672,84 @@ func main() {
 		logger.Warn("Plaid endpoints disabled (Plaid not configured)")
 	}
 
+	// Feature flag routes (authenticated)
+	api.HandleFunc("/features", featureFlagHandler.GetFeatures).Methods("GET")
+	logger.Info("Feature flag endpoints registered")
+
+	// Task routes (authenticated)
+	taskRoutes := api.PathPrefix("/tasks").Subrouter()
+	taskRoutes.Handle("", anonymousDocuments(http.HandlerFunc(taskHandler.CreateTask))).Methods("POST")
+	taskRoutes.Handle("/quick-add", anonymousDocuments(http.HandlerFunc(taskHandler.QuickAdd))).Methods("POST")
+	taskRoutes.HandleFunc("/bulk-status", taskHandler.BulkStatus).Methods("POST")
+	logger.Info("Task endpoints registered")
+
+	// Mood routes (authenticated)
+	moodRoutes := api.PathPrefix("/moods").Subrouter()
+	moodRoutes.Handle("", anonymousDocuments(http.HandlerFunc(moodHandler.CreateMood))).Methods("POST")
+	moodRoutes.HandleFunc("/{id}", moodHandler.UpdateMood).Methods("PUT")
+	logger.Info("Mood endpoints registered")
+
+	// Focus session routes (authenticated)
+	focusSessionRoutes := api.PathPrefix("/focus-sessions").Subrouter()
+	focusSessionRoutes.Handle("/start", anonymousDocuments(http.HandlerFunc(focusSessionHandler.Start))).Methods("POST")
+	focusSessionRoutes.Handle("/from-template/{templateId}", anonymousDocuments(http.HandlerFunc(focusSessionHandler.StartFromTemplate))).Methods("POST")
+	focusSessionRoutes.HandleFunc("/complete", focusSessionHandler.Complete).Methods("POST")
+	logger.Info("Focus session endpoints registered")
+
+	// Goal tracking routes (authenticated)
+	api.HandleFunc("/goals/at-risk", goalHandler.AtRisk).Methods("GET")
+	api.HandleFunc("/goals/{id}/financial-progress", goalHandler.FinancialProgress).Methods("GET")
+	api.HandleFunc("/transactions/{id}/goal", goalHandler.TagTransaction).Methods("PUT")
+	logger.Info("Goal tracking endpoints registered")
+
+	// Home screen summary (authenticated)
+	api.HandleFunc("/today", todayHandler.GetToday).Methods("GET")
+
+	// Finance summary (authenticated)
+	api.HandleFunc("/finance/dashboard", financeDashboardHandler.GetDashboard).Methods("GET")
+
 	// Analytics routes (authenticated)
 	analyticsRoutes := api.PathPrefix("/analytics").Subrouter()
 	analyticsRoutes.HandleFunc("/dashboard", analyticsHandler.GetDashboardAnalytics).Methods("GET")
+	analyticsRoutes.HandleFunc("/best-focus-time", analyticsHandler.GetBestFocusTime).Methods("GET")
 	analyticsRoutes.HandleFunc("/spending", analyticsHandler.GetSpendingAnalytics).Methods("GET")
+	analyticsRoutes.HandleFunc("/spending/anomalies", analyticsHandler.GetSpendingAnomalies).Methods("GET")
+	analyticsRoutes.HandleFunc("/estimation", analyticsHandler.GetEstimationAnalytics).Methods("GET")
+	analyticsRoutes.HandleFunc("/ai-usage", llmLogHandler.GetAIUsage).Methods("GET")
+	analyticsRoutes.HandleFunc("/by-location", analyticsHandler.GetLocationAnalytics).Methods("GET")
+	analyticsRoutes.HandleFunc("/streak", streakHandler.GetStreak).Methods("GET")
+	analyticsRoutes.HandleFunc("/cashflow", analyticsHandler.GetCashFlow).Methods("GET")
+	analyticsRoutes.HandleFunc("/cashflow/overrides", analyticsHandler.ListCashFlowOverrides).Methods("GET")
+	analyticsRoutes.HandleFunc("/cashflow/overrides", analyticsHandler.SetCashFlowOverride).Methods("PUT")
+	analyticsRoutes.HandleFunc("/cashflow/overrides", analyticsHandler.DeleteCashFlowOverride).Methods("DELETE")
+	api.HandleFunc("/transactions/deduplicate", analyticsHandler.DeduplicateTransactions).Methods("POST")
+	api.HandleFunc("/transactions/categorize-rules/preview", analyticsHandler.PreviewCategoryRules).Methods("POST")
+	api.HandleFunc("/transactions/categorize-rules", analyticsHandler.ApplyCategoryRules).Methods("POST")
+	api.HandleFunc("/maintenance/session-task-consistency", analyticsHandler.GetSessionTaskConsistency).Methods("GET")
+	api.HandleFunc("/maintenance/session-task-consistency", analyticsHandler.RepairSessionTaskConsistency).Methods("POST")
 	logger.Info("Analytics endpoints registered")
 
 	// Import/export routes (authenticated)
 	importRoutes := api.PathPrefix("/import").Subrouter()
-	importRoutes.HandleFunc("/validate", importExportHandler.ValidateImport).Methods("POST")
-	importRoutes.HandleFunc("/execute", importExportHandler.ExecuteImport).Methods("POST")
+	importRoutes.Handle("/validate", uploadLimited(importExportHandler.ValidateImport)).Methods("POST")
+	importRoutes.Handle("/adapter/{source}", uploadLimited(importExportHandler.ValidateForeignImport)).Methods("POST")
+	importRoutes.Handle("/execute", audited(services.AuditActionImport, importExportHandler.ExecuteImport)).Methods("POST")
+	importRoutes.HandleFunc("/jobs/{jobId}", importExportHandler.GetImportJob).Methods("GET")
 
 	exportRoutes := api.PathPrefix("/export").Subrouter()
-	exportRoutes.HandleFunc("", importExportHandler.ExportData).Methods("GET")
+	exportRoutes.Handle("", audited(services.AuditActionExport, importExportHandler.ExportData)).Methods("GET")
 	exportRoutes.HandleFunc("/summary", importExportHandler.GetExportSummary).Methods("GET")
+	exportRoutes.Handle("/{collection}", audited(services.AuditActionExport, importExportHandler.ExportCollection)).Methods("GET")
+	api.Handle("/trips/{tripId}/export", audited(services.AuditActionExport, importExportHandler.ExportTrip)).Methods("GET")
+	api.HandleFunc("/maintenance/repair-references", importExportHandler.RepairReferences).Methods("POST")
 	logger.Info("Import/export endpoints registered")
 
 	// Investment calculation routes (authenticated)
 	portfolioRoutes := api.PathPrefix("/portfolio").Subrouter()
 	portfolioRoutes.HandleFunc("/{portfolioId}/metrics", investmentHandler.GetPortfolioMetrics).Methods("GET")
 	portfolioRoutes.HandleFunc("/{portfolioId}/snapshots", investmentHandler.GetPortfolioSnapshots).Methods("GET")
+	portfolioRoutes.HandleFunc("/{portfolioId}/allocation", investmentHandler.GetPortfolioAllocation).Methods("GET")
+	portfolioRoutes.HandleFunc("/{portfolioId}/rebalance", investmentHandler.SuggestRebalance).Methods("POST")
 	portfolioRoutes.HandleFunc("/projection", investmentHandler.GenerateProjection).Methods("POST")
 	portfolioRoutes.HandleFunc("/summary", investmentHandler.GetDashboardSummary).Methods("GET")
 	logger.Info("Investment calculation endpoints registered")
@@ -448,6 +760,7 @@ func main() {
 	entityGraphRoutes.HandleFunc("/linked/{entityType}/{entityId}", entityGraphHandler.GetLinkedEntities).Methods("GET")
 	entityGraphRoutes.HandleFunc("/tools", entityGraphHandler.GetToolRelationships).Methods("GET")
 	entityGraphRoutes.HandleFunc("/stats", entityGraphHandler.GetRelationshipStats).Methods("GET")
+	entityGraphRoutes.HandleFunc("/backfill", entityGraphHandler.BackfillRelationships).Methods("POST")
 	logger.Info("Entity graph endpoints registered")
 
 	// Stock routes (authenticated)
@@ -475,7 +788,7 @@ func main() {
 
 	// Chat route (authenticated, requires AI access)
 	if chatHandler != nil {
-		api.HandleFunc("/chat", chatHandler.Chat).Methods("POST")
+		api.Handle("/chat", anonymousAICalls(http.HandlerFunc(chatHandler.Chat))).Methods("POST")
 		logger.Info("Chat endpoint registered")
 	} else {
 		logger.Warn("Chat endpoint disabled (no AI clients configured)")
@@ -484,27 +797,115 @@ func main() {
 	// Photo routes (vote endpoint allows anonymous, others require auth)
 	if photoHandler != nil {
 		photoRoutes := api.PathPrefix("/photo").Subrouter()
+		photoRoutes.Use(middleware.RequireFeature(featureFlagService, services.FeaturePhotoBattles))
 		// Vote can be submitted by anonymous users
 		photoRoutes.HandleFunc("/vote", photoHandler.SubmitVote).Methods("POST")
 		// Next pair can be fetched anonymously
 		photoRoutes.HandleFunc("/next-pair", photoHandler.GetNextPair).Methods("POST")
 		// Signed URL requires authentication
-		photoRoutes.HandleFunc("/signed-url", photoHandler.GetSignedURL).Methods("POST")
-		logger.Info("Photo endpoints registered (3 endpoints)")
+		photoRoutes.Handle("/signed-url", audited(services.AuditActionSignedURL, photoHandler.GetSignedURL)).Methods("POST")
+		// Rotates uploaded photos upright per EXIF orientation
+		photoRoutes.HandleFunc("/normalize-orientation", photoHandler.NormalizeOrientation).Methods("POST")
+
+		// Battle management is limited to the battle owner
+		battleRoutes := api.PathPrefix("/photo-battle").Subrouter()
+		battleRoutes.Use(middleware.RequireFeature(featureFlagService, services.FeaturePhotoBattles))
+		battleRoutes.HandleFunc("/{id}/history", photoHandler.GetVoteHistory).Methods("GET")
+		battleRoutes.HandleFunc("/{id}/undo-last-vote", photoHandler.UndoLastVote).Methods("POST")
+		battleRoutes.HandleFunc("/{id}/results", photoHandler.GetResults).Methods("GET")
+		battleRoutes.HandleFunc("/{id}/settings", photoHandler.UpdateSettings).Methods("PUT")
+		battleRoutes.HandleFunc("/{id}/recompute", photoHandler.RecomputeRatings).Methods("POST")
+		battleRoutes.HandleFunc("/repair", photoHandler.RepairBattles).Methods("POST")
+		logger.Info("Photo endpoints registered (10 endpoints)")
 	} else {
 		logger.Warn("Photo endpoints disabled (Cloud Storage not available)")
 	}
 
+	// Batch photo uploads into the photo library (authenticated)
+	if photoUploadHandler != nil {
+		api.Handle("/storage/photos/batch", uploadLimited(photoUploadHandler.UploadBatch)).Methods("POST")
+		api.Handle("/storage/photos/export", audited(services.AuditActionExport, photoUploadHandler.ExportMedia)).Methods("GET")
+		api.HandleFunc("/storage/photos/regenerate-thumbnails", photoUploadHandler.StartThumbnailBackfill).Methods("POST")
+		api.HandleFunc("/storage/photos/regenerate-thumbnails/{jobId}", photoUploadHandler.GetThumbnailJob).Methods("GET")
+		api.HandleFunc("/storage/photos/{id}/regenerate-thumbnail", photoUploadHandler.RegenerateThumbnail).Methods("POST")
+		logger.Info("Photo upload endpoints registered (4 endpoints)")
+	} else {
+		logger.Warn("Photo upload endpoints disabled (Cloud Storage not available)")
+	}
+
+	// Photo library routes (authenticated)
+	photoLibraryRoutes := api.PathPrefix("/photo-library").Subrouter()
+	photoLibraryRoutes.HandleFunc("", photoLibraryHandler.ListPhotos).Methods("GET")
+	photoLibraryRoutes.HandleFunc("/tags/add", photoLibraryHandler.AddTags).Methods("POST")
+	photoLibraryRoutes.HandleFunc("/tags/remove", photoLibraryHandler.RemoveTags).Methods("POST")
+	photoLibraryRoutes.Handle("/albums", anonymousDocuments(http.HandlerFunc(photoLibraryHandler.CreateAlbum))).Methods("POST")
+	photoLibraryRoutes.HandleFunc("/albums", photoLibraryHandler.ListAlbums).Methods("GET")
+	logger.Info("Photo library endpoints registered")
+
 	// Packing list routes (authenticated)
 	packingRoutes := api.PathPrefix("/packing-list").Subrouter()
-	packingRoutes.HandleFunc("/create", packingListHandler.CreatePackingList).Methods("POST")
+	packingRoutes.Handle("/create", anonymousDocuments(http.HandlerFunc(packingListHandler.CreatePackingList))).Methods("POST")
 	packingRoutes.HandleFunc("/update", packingListHandler.UpdatePackingList).Methods("POST")
 	packingRoutes.HandleFunc("/toggle-item", packingListHandler.SetItemStatus).Methods("POST")
 	logger.Info("Packing list endpoints registered (3 endpoints)")
 
+	// Merchant alias routes (authenticated)
+	merchantAliasRoutes := api.PathPrefix("/merchant-aliases").Subrouter()
+	merchantAliasRoutes.HandleFunc("", merchantAliasHandler.ListAliases).Methods("GET")
+	merchantAliasRoutes.HandleFunc("", merchantAliasHandler.CreateAlias).Methods("POST")
+	merchantAliasRoutes.HandleFunc("/{id}", merchantAliasHandler.DeleteAlias).Methods("DELETE")
+	logger.Info("Merchant alias endpoints registered (3 endpoints)")
+
+	// Profile preference routes (authenticated)
+	api.HandleFunc("/profile/preferences", preferencesHandler.GetPreferences).Methods("GET")
+	api.HandleFunc("/profile/preferences", preferencesHandler.UpdatePreferences).Methods("PUT")
+	api.HandleFunc("/profile/llm-logs", llmLogHandler.GetSettings).Methods("GET")
+	api.HandleFunc("/profile/llm-logs", llmLogHandler.UpdateSettings).Methods("PUT")
+	api.HandleFunc("/profile/task-categories", taskCategoryHandler.GetCategories).Methods("GET")
+	api.HandleFunc("/profile/task-categories", taskCategoryHandler.UpdateCategories).Methods("PUT")
+	logger.Info("Preference endpoints registered (6 endpoints)")
+
+	// Account routes (authenticated)
+	api.HandleFunc("/account/usage", accountUsageHandler.GetUsage).Methods("GET")
+	api.HandleFunc("/account", accountUsageHandler.DeleteAccount).Methods("DELETE")
+	logger.Info("Account endpoints registered (1 endpoint)")
+
+	// CSV mapping routes (authenticated)
+	csvMappingRoutes := api.PathPrefix("/csv-mappings").Subrouter()
+	csvMappingRoutes.HandleFunc("", csvMappingHandler.ListMappings).Methods("GET")
+	csvMappingRoutes.HandleFunc("", csvMappingHandler.CreateMapping).Methods("POST")
+	csvMappingRoutes.HandleFunc("/preview", csvMappingHandler.PreviewMapping).Methods("POST")
+	csvMappingRoutes.HandleFunc("/{id}", csvMappingHandler.UpdateMapping).Methods("PUT")
+	csvMappingRoutes.HandleFunc("/{id}", csvMappingHandler.DeleteMapping).Methods("DELETE")
+	logger.Info("CSV mapping endpoints registered (5 endpoints)")
+
+	// Notification channel and quiet hours routes (authenticated)
+	notificationRoutes := api.PathPrefix("/notifications/channels").Subrouter()
+	notificationRoutes.HandleFunc("", notificationHandler.ListChannels).Methods("GET")
+	notificationRoutes.HandleFunc("", notificationHandler.CreateChannel).Methods("POST")
+	notificationRoutes.HandleFunc("/{id}", notificationHandler.DeleteChannel).Methods("DELETE")
+	api.HandleFunc("/notifications/quiet-hours", notificationHandler.GetQuietHours).Methods("GET")
+	api.HandleFunc("/notifications/quiet-hours", notificationHandler.UpdateQuietHours).Methods("PUT")
+	logger.Info("Notification endpoints registered (5 endpoints)")
+
+	// Link preview route (authenticated)
+	api.HandleFunc("/link-preview", linkPreviewHandler.Preview).Methods("POST")
+
+	// Calendar feed token routes (authenticated)
+	api.HandleFunc("/calendar/feed-token", calendarHandler.RotateFeedToken).Methods("POST")
+	api.HandleFunc("/calendar/feed-token", calendarHandler.RevokeFeedToken).Methods("DELETE")
+	api.HandleFunc("/ingest-token", ingestHandler.RotateToken).Methods("POST")
+	api.HandleFunc("/ingest-token", ingestHandler.RevokeToken).Methods("DELETE")
+	logger.Info("Calendar feed endpoints registered (3 endpoints)")
+
+	// Tag routes (authenticated)
+	api.HandleFunc("/tags", tagHandler.ListTags).Methods("GET")
+	api.HandleFunc("/tags/rename", tagHandler.RenameTag).Methods("POST")
+	logger.Info("Tag endpoints registered (2 endpoints)")
+
 	// Place insights routes (authenticated, requires AI access)
 	if placeInsightsHandler != nil {
-		api.HandleFunc("/place-insights", placeInsightsHandler.GenerateInsights).Methods("POST")
+		api.Handle("/place-insights", anonymousAICalls(http.HandlerFunc(placeInsightsHandler.GenerateInsights))).Methods("POST")
 		logger.Info("Place insights endpoint registered")
 	} else {
 		logger.Warn("Place insights endpoint disabled (no AI clients configured)")
@@ -514,6 +915,25 @@ func main() {
 	api.HandleFunc("/visa-requirements", visaHandler.GetVisaRequirements).Methods("GET")
 	logger.Info("Visa requirements endpoint registered")
 
+	// Audit log route (authenticated)
+	api.HandleFunc("/audit-log", auditHandler.List).Methods("GET")
+	logger.Info("Audit log endpoint registered")
+
+	// Collection schema route (authenticated)
+	api.HandleFunc("/collections/{name}/schema", documentHandler.Schema).Methods("GET")
+	logger.Info("Collection schema endpoint registered")
+
+	// Generic document routes (authenticated); registered last so specific routes take precedence
+	api.HandleFunc("/{collection}", documentHandler.List).Methods("GET")
+	api.HandleFunc("/{collection}/batch-delete", documentHandler.BatchDelete).Methods("POST")
+	api.HandleFunc("/{collection}/{id}", documentHandler.Get).Methods("GET")
+	api.HandleFunc("/{collection}/{id}/history", documentHandler.History).Methods("GET")
+	api.HandleFunc("/{collection}/{id}/attachments", attachmentHandler.AddAttachment).Methods("POST")
+	api.HandleFunc("/{collection}/{id}/attachments", attachmentHandler.RemoveAttachment).Methods("DELETE")
+	api.HandleFunc("/{collection}/{id}", documentHandler.Patch).Methods("PUT")
+	api.Handle("/{collection}/{id}/duplicate", anonymousDocuments(http.HandlerFunc(documentHandler.Duplicate))).Methods("POST")
+	logger.Info("Document endpoints registered")
+
 	// Log registered routes
 	logger.Info("Routes registered",
 		zap.Int("count", countRoutes(router)),
@@ -529,6 +949,16 @@ func main() {
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}
 
+	// Start background workers; stopped on shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if cfg.Workers.Enabled && cfg.Workers.AnonymousCleanup.Enabled {
+		go anonymousService.RunCleanup(workerCtx, cfg.Workers.AnonymousCleanup.Interval)
+		logger.Info("Anonymous cleanup worker started",
+			zap.Duration("interval", cfg.Workers.AnonymousCleanup.Interval),
+		)
+	}
+
 	// Start server in goroutine
 	go func() {
 		logger.Info("Server listening",
@@ -545,6 +975,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopWorkers()
 
 	// Give server 30 seconds to finish processing requests
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -557,6 +988,16 @@ func main() {
 	logger.Info("Server stopped")
 }
 
+// destructiveRoutes are the endpoints besides DELETEs that safe mode blocks,
+// and the DELETEs it lets through when their rule allows
+var destructiveRoutes = map[string]middleware.SafeModeRule{
+	"POST /api/{collection}/batch-delete": nil,
+	"POST /api/spending/delete-csv":       nil,
+	"POST /api/spending/delete-all":       nil,
+	"POST /api/import/execute":            middleware.BlockReplaceAllImport,
+	"DELETE /api/account":                 middleware.BlockUnlessDryRun,
+}
+
 // countRoutes counts the number of registered routes
 func countRoutes(router *mux.Router) int {
 	count := 0
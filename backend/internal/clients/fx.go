@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// FXDefaultBaseURL is the exchange rate endpoint used by the frontend
+	FXDefaultBaseURL = "https://api.exchangerate.host/latest"
+	// fxCacheTTL is how long rates for a base currency are reused
+	fxCacheTTL = 12 * time.Hour
+)
+
+// FXClient fetches foreign exchange rates, caching them per base currency
+type FXClient struct {
+	baseURL string
+	client  *http.Client
+	logger  *zap.Logger
+
+	mu    sync.Mutex
+	cache map[string]fxCacheEntry
+}
+
+type fxCacheEntry struct {
+	rates     map[string]float64
+	fetchedAt time.Time
+}
+
+// fxRatesResponse is the exchange rate API response structure
+type fxRatesResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// NewFXClient creates a new exchange rate client. An empty baseURL uses
+// FXDefaultBaseURL.
+func NewFXClient(baseURL string, logger *zap.Logger) *FXClient {
+	if baseURL == "" {
+		baseURL = FXDefaultBaseURL
+	}
+	return &FXClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+		cache:  make(map[string]fxCacheEntry),
+	}
+}
+
+// Rates returns how many units of each currency one unit of base buys
+func (c *FXClient) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+
+	c.mu.Lock()
+	entry, ok := c.cache[base]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < fxCacheTTL {
+		return entry.rates, nil
+	}
+
+	params := url.Values{}
+	params.Set("base", base)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?%s", c.baseURL, params.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result fxRatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Rates) == 0 {
+		return nil, fmt.Errorf("no exchange rates returned for %s", base)
+	}
+
+	rates := make(map[string]float64, len(result.Rates)+1)
+	for currency, rate := range result.Rates {
+		if rate > 0 {
+			rates[strings.ToUpper(currency)] = rate
+		}
+	}
+	rates[base] = 1
+
+	c.mu.Lock()
+	c.cache[base] = fxCacheEntry{rates: rates, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	c.logger.Debug("Fetched exchange rates", zap.String("base", base), zap.Int("count", len(rates)))
+	return rates, nil
+}
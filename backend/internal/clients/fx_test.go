@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFXClient_RatesCachesPerBase(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "EUR", r.URL.Query().Get("base"))
+		_, _ = w.Write([]byte(`{"base": "EUR", "rates": {"usd": 1.25, "GBP": 0.85, "BAD": 0}}`))
+	}))
+	defer server.Close()
+
+	client := NewFXClient(server.URL, zap.NewNop())
+
+	rates, err := client.Rates(context.Background(), "eur")
+	require.NoError(t, err)
+	assert.Equal(t, 1.25, rates["USD"])
+	assert.Equal(t, 1.0, rates["EUR"])
+	assert.NotContains(t, rates, "BAD")
+
+	_, err = client.Rates(context.Background(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestFXClient_RatesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("base") == "USD" {
+			_, _ = w.Write([]byte(`{"rates": {}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewFXClient(server.URL, zap.NewNop())
+
+	_, err := client.Rates(context.Background(), "USD")
+	assert.Error(t, err)
+	_, err = client.Rates(context.Background(), "EUR")
+	assert.Error(t, err)
+}
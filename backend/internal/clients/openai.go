@@ -17,6 +17,7 @@ type OpenAIClient struct {
 	config      *config.OpenAIConfig
 	logger      *zap.Logger
 	rateLimiter *RateLimiter
+	sandbox     bool // canned responses, see NewSandboxOpenAIClient
 }
 
 // NewOpenAIClient creates a new OpenAI client
@@ -84,6 +85,10 @@ func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatCompletionReq
 		req.Temperature = c.config.Temperature
 	}
 
+	if c.sandbox {
+		return sandboxChatCompletion(req), nil
+	}
+
 	// Wait for rate limit
 	if err := c.rateLimiter.WaitForRequest(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait failed: %w", err)
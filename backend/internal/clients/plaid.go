@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/plaid/plaid-go/v20/plaid"
 	"go.uber.org/zap"
 
@@ -21,6 +22,7 @@ type PlaidClient struct {
 	countryCodes []plaid.CountryCode
 	webhookURL   string
 	logger       *zap.Logger
+	sandbox      bool // canned responses, see NewSandboxPlaidClient
 }
 
 // PlaidConfig holds initialization parameters
@@ -126,6 +128,14 @@ func (c *PlaidClient) CreateLinkToken(ctx context.Context, req CreateLinkTokenRe
 		zap.Bool("updateMode", req.AccessToken != ""),
 	)
 
+	if c.sandbox {
+		return &CreateLinkTokenResponse{
+			LinkToken:  "link-sandbox-" + uuid.New().String(),
+			Expiration: time.Now().Add(4 * time.Hour),
+			RequestID:  "sandbox",
+		}, nil
+	}
+
 	// Build user info
 	user := plaid.LinkTokenCreateRequestUser{
 		ClientUserId: req.UserID,
@@ -204,6 +214,14 @@ type ExchangePublicTokenResponse struct {
 func (c *PlaidClient) ExchangePublicToken(ctx context.Context, req ExchangePublicTokenRequest) (*ExchangePublicTokenResponse, error) {
 	c.logger.Debug("Exchanging public token")
 
+	if c.sandbox {
+		return &ExchangePublicTokenResponse{
+			AccessToken: "access-sandbox-" + uuid.New().String(),
+			ItemID:      "item-sandbox-" + uuid.New().String()[:8],
+			RequestID:   "sandbox",
+		}, nil
+	}
+
 	request := plaid.NewItemPublicTokenExchangeRequest(req.PublicToken)
 
 	response, httpResp, err := c.client.PlaidApi.ItemPublicTokenExchange(ctx).ItemPublicTokenExchangeRequest(*request).Execute()
@@ -239,6 +257,15 @@ type GetItemResponse struct {
 func (c *PlaidClient) GetItem(ctx context.Context, accessToken string) (*GetItemResponse, error) {
 	c.logger.Debug("Getting Plaid item")
 
+	if c.sandbox {
+		return &GetItemResponse{
+			ItemID:            "item-sandbox",
+			InstitutionID:     sandboxInstitutionID,
+			AvailableProducts: []string{},
+			BilledProducts:    []string{"transactions"},
+		}, nil
+	}
+
 	request := plaid.NewItemGetRequest(accessToken)
 
 	response, httpResp, err := c.client.PlaidApi.ItemGet(ctx).ItemGetRequest(*request).Execute()
@@ -300,6 +327,17 @@ type InstitutionInfo struct {
 func (c *PlaidClient) GetInstitution(ctx context.Context, institutionID string) (*InstitutionInfo, error) {
 	c.logger.Debug("Getting institution", zap.String("institutionId", institutionID))
 
+	if c.sandbox {
+		return &InstitutionInfo{
+			InstitutionID: institutionID,
+			Name:          "Sandbox Bank",
+			Products:      []string{"transactions"},
+			CountryCodes:  []string{"US"},
+			URL:           "https://example.com",
+			PrimaryColor:  "#1f6feb",
+		}, nil
+	}
+
 	request := plaid.NewInstitutionsGetByIdRequest(institutionID, c.countryCodes)
 
 	response, httpResp, err := c.client.PlaidApi.InstitutionsGetById(ctx).InstitutionsGetByIdRequest(*request).Execute()
@@ -355,6 +393,10 @@ type AccountBalances struct {
 func (c *PlaidClient) GetAccounts(ctx context.Context, accessToken string) ([]Account, error) {
 	c.logger.Debug("Getting accounts")
 
+	if c.sandbox {
+		return sandboxAccounts(), nil
+	}
+
 	request := plaid.NewAccountsGetRequest(accessToken)
 
 	response, httpResp, err := c.client.PlaidApi.AccountsGet(ctx).AccountsGetRequest(*request).Execute()
@@ -433,6 +475,10 @@ type RemovedTransaction struct {
 func (c *PlaidClient) SyncTransactions(ctx context.Context, req SyncTransactionsRequest) (*SyncTransactionsResponse, error) {
 	c.logger.Debug("Syncing transactions", zap.Bool("hasCursor", req.Cursor != nil))
 
+	if c.sandbox {
+		return sandboxTransactions(req.Cursor, time.Now()), nil
+	}
+
 	request := plaid.NewTransactionsSyncRequest(req.AccessToken)
 	if req.Cursor != nil && *req.Cursor != "" {
 		request.SetCursor(*req.Cursor)
@@ -525,6 +571,10 @@ func (c *PlaidClient) SyncTransactions(ctx context.Context, req SyncTransactions
 func (c *PlaidClient) RemoveItem(ctx context.Context, accessToken string) error {
 	c.logger.Debug("Removing Plaid item")
 
+	if c.sandbox {
+		return nil
+	}
+
 	request := plaid.NewItemRemoveRequest(accessToken)
 
 	_, httpResp, err := c.client.PlaidApi.ItemRemove(ctx).ItemRemoveRequest(*request).Execute()
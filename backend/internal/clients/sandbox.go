@@ -0,0 +1,266 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+// Sandbox clients answer from canned responses instead of calling OpenAI, Stripe
+// or Plaid, so the full API works locally without credentials. They are only
+// wired in when development.test_mode is enabled, which config validation
+// refuses in production.
+
+const (
+	sandboxModel = "sandbox-gpt"
+
+	// sandboxWebhookSecret signs local Stripe webhooks, e.g. with `stripe trigger`
+	sandboxWebhookSecret = "whsec_sandbox"
+	sandboxPriceID       = "price_sandbox_pro"
+	sandboxCustomerID    = "cus_sandbox"
+	sandboxInstitutionID = "ins_sandbox"
+	sandboxSyncCursor    = "sandbox-cursor-1"
+)
+
+// NewSandboxOpenAIClient creates an OpenAI client that returns canned completions
+func NewSandboxOpenAIClient(cfg *config.OpenAIConfig, logger *zap.Logger) *OpenAIClient {
+	sandboxCfg := *cfg
+	if sandboxCfg.DefaultModel == "" {
+		sandboxCfg.DefaultModel = sandboxModel
+	}
+	return &OpenAIClient{
+		config:      &sandboxCfg,
+		logger:      logger,
+		rateLimiter: NewRateLimiter(1000, 1000000),
+		sandbox:     true,
+	}
+}
+
+// NewSandboxStripeClient creates a Stripe client that returns canned billing objects.
+// Webhooks are still signature-checked, against the sandbox secret.
+func NewSandboxStripeClient(cfg *config.StripeConfig, logger *zap.Logger) *StripeClient {
+	return &StripeClient{
+		webhookSecret: sandboxWebhookSecret,
+		proPriceID:    sandboxPriceID,
+		successURL:    cfg.SuccessURL,
+		cancelURL:     cfg.CancelURL,
+		logger:        logger,
+		sandbox:       true,
+	}
+}
+
+// NewSandboxPlaidClient creates a Plaid client that returns a canned bank with transactions
+func NewSandboxPlaidClient(logger *zap.Logger) *PlaidClient {
+	return &PlaidClient{
+		logger:  logger,
+		sandbox: true,
+	}
+}
+
+// sandboxThoughtActions are returned for thought processing prompts. Confidence
+// is above the auto-execute threshold for the task so the full flow is exercised.
+var sandboxThoughtActions = map[string]interface{}{
+	"actions": []map[string]interface{}{
+		{
+			"type":       "createTask",
+			"confidence": 96,
+			"data": map[string]interface{}{
+				"title":    "Follow up on this thought",
+				"category": "mastery",
+				"priority": "medium",
+				"notes":    "Created by the sandbox AI client",
+			},
+			"reasoning": "The thought mentions something to act on.",
+		},
+		{
+			"type":       "createMood",
+			"confidence": 80,
+			"data": map[string]interface{}{
+				"value": 7,
+				"note":  "Feeling fairly positive",
+			},
+			"reasoning": "The tone of the thought is upbeat.",
+		},
+	},
+}
+
+// sandboxTaskEnrichment is returned for task enrichment prompts
+var sandboxTaskEnrichment = map[string]interface{}{
+	"category":         "mastery",
+	"priority":         "high",
+	"estimatedMinutes": 30,
+}
+
+// sandboxChatCompletion builds a canned completion. JSON requests from thought
+// processing get suggested actions and task enrichment gets suggested fields;
+// other JSON requests get an empty object.
+func sandboxChatCompletion(req ChatCompletionRequest) *ChatCompletionResponse {
+	prompt := ""
+	for _, msg := range req.Messages {
+		prompt += msg.Content + "\n"
+	}
+
+	content := ""
+	switch {
+	case req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object":
+		content = "{}"
+		if strings.Contains(prompt, "thought processor") {
+			data, _ := json.Marshal(sandboxThoughtActions)
+			content = string(data)
+		}
+		if strings.Contains(prompt, "task enrichment assistant") {
+			data, _ := json.Marshal(sandboxTaskEnrichment)
+			content = string(data)
+		}
+	default:
+		last := ""
+		if len(req.Messages) > 0 {
+			last = req.Messages[len(req.Messages)-1].Content
+		}
+		if len(last) > 80 {
+			last = last[:80] + "..."
+		}
+		content = fmt.Sprintf("This is a sandbox reply (test mode is on, no AI provider was called). You said: %q", last)
+	}
+
+	return &ChatCompletionResponse{
+		Content:      content,
+		FinishReason: "stop",
+		TokensUsed:   (len(prompt) + len(content)) / 4,
+		Model:        req.Model,
+	}
+}
+
+func sandboxCheckoutSession(customerEmail, successURL string) *stripe.CheckoutSession {
+	id := "cs_test_sandbox_" + uuid.New().String()[:8]
+	separator := "?"
+	if strings.Contains(successURL, "?") {
+		separator = "&"
+	}
+	return &stripe.CheckoutSession{
+		ID:            id,
+		Object:        "checkout.session",
+		Mode:          stripe.CheckoutSessionModeSubscription,
+		Status:        stripe.CheckoutSessionStatusOpen,
+		CustomerEmail: customerEmail,
+		URL:           successURL + separator + "session_id=" + id,
+		Livemode:      false,
+	}
+}
+
+func sandboxPortalSession(customerID, returnURL string) *stripe.BillingPortalSession {
+	return &stripe.BillingPortalSession{
+		ID:        "bps_sandbox_" + uuid.New().String()[:8],
+		Object:    "billing_portal.session",
+		Customer:  customerID,
+		ReturnURL: returnURL,
+		URL:       returnURL,
+	}
+}
+
+func sandboxSubscriptionObject(subscriptionID string, cancelAtPeriodEnd bool) *stripe.Subscription {
+	now := time.Now()
+	return &stripe.Subscription{
+		ID:                 subscriptionID,
+		Object:             "subscription",
+		Status:             stripe.SubscriptionStatusActive,
+		Customer:           &stripe.Customer{ID: sandboxCustomerID},
+		CancelAtPeriodEnd:  cancelAtPeriodEnd,
+		CurrentPeriodStart: now.AddDate(0, 0, -15).Unix(),
+		CurrentPeriodEnd:   now.AddDate(0, 0, 15).Unix(),
+	}
+}
+
+func sandboxPaymentMethod() *stripe.PaymentMethod {
+	return &stripe.PaymentMethod{
+		ID:     "pm_sandbox_visa",
+		Object: "payment_method",
+		Type:   stripe.PaymentMethodTypeCard,
+		Card: &stripe.PaymentMethodCard{
+			Brand:    stripe.PaymentMethodCardBrandVisa,
+			Last4:    "4242",
+			ExpMonth: 12,
+			ExpYear:  int64(time.Now().Year() + 3),
+		},
+	}
+}
+
+func sandboxAccounts() []Account {
+	available := 2450.75
+	limit := 5000.0
+	return []Account{
+		{
+			AccountID:    "acc_sandbox_checking",
+			Name:         "Sandbox Checking",
+			Mask:         "0000",
+			Type:         "depository",
+			Subtype:      "checking",
+			OfficialName: "Sandbox Everyday Checking",
+			Balances:     AccountBalances{Current: 2510.32, Available: &available, IsoCurrency: "USD"},
+		},
+		{
+			AccountID:    "acc_sandbox_credit",
+			Name:         "Sandbox Credit Card",
+			Mask:         "3333",
+			Type:         "credit",
+			Subtype:      "credit card",
+			OfficialName: "Sandbox Rewards Card",
+			Balances:     AccountBalances{Current: 412.18, Limit: &limit, IsoCurrency: "USD"},
+		},
+	}
+}
+
+// sandboxTransactions returns a first sync page of recent transactions; later
+// syncs (with a cursor) report no changes
+func sandboxTransactions(cursor *string, now time.Time) *SyncTransactionsResponse {
+	result := &SyncTransactionsResponse{
+		Added:      []Transaction{},
+		Modified:   []Transaction{},
+		Removed:    []RemovedTransaction{},
+		NextCursor: sandboxSyncCursor,
+	}
+	if cursor != nil && *cursor != "" {
+		return result
+	}
+
+	canned := []struct {
+		daysAgo  int
+		account  string
+		name     string
+		merchant string
+		amount   float64
+		category string
+	}{
+		{1, "acc_sandbox_credit", "STARBUCKS STORE 1458", "Starbucks", 6.45, "FOOD_AND_DRINK"},
+		{2, "acc_sandbox_credit", "WHOLEFDS MKT 10234", "Whole Foods Market", 84.12, "FOOD_AND_DRINK"},
+		{3, "acc_sandbox_checking", "UBER TRIP", "Uber", 18.90, "TRANSPORTATION"},
+		{5, "acc_sandbox_credit", "NETFLIX.COM", "Netflix", 15.49, "ENTERTAINMENT"},
+		{7, "acc_sandbox_checking", "ACME CORP PAYROLL", "", -2350.00, "INCOME"},
+		{9, "acc_sandbox_checking", "CITY POWER & LIGHT", "City Power", 96.30, "RENT_AND_UTILITIES"},
+	}
+	for i, c := range canned {
+		txn := Transaction{
+			TransactionID: fmt.Sprintf("txn_sandbox_%d", i+1),
+			AccountID:     c.account,
+			Amount:        c.amount,
+			IsoCurrency:   "USD",
+			Date:          now.AddDate(0, 0, -c.daysAgo).Format("2006-01-02"),
+			Name:          c.name,
+		}
+		if c.merchant != "" {
+			merchant := c.merchant
+			txn.MerchantName = &merchant
+		}
+		category := c.category
+		txn.PersonalFinanceCategory = &category
+		result.Added = append(result.Added, txn)
+	}
+	return result
+}
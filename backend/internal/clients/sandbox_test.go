@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+func TestSandboxOpenAIClient_ThoughtActions(t *testing.T) {
+	client := NewSandboxOpenAIClient(&config.OpenAIConfig{}, zap.NewNop())
+
+	resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatMessage{
+			{Role: "system", Content: "You are an intelligent thought processor."},
+			{Role: "user", Content: "Call the dentist tomorrow"},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, sandboxModel, resp.Model)
+
+	var parsed struct {
+		Actions []map[string]interface{} `json:"actions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp.Content), &parsed))
+	require.Len(t, parsed.Actions, 2)
+	assert.Equal(t, "createTask", parsed.Actions[0]["type"])
+}
+
+func TestSandboxOpenAIClient_TaskEnrichment(t *testing.T) {
+	client := NewSandboxOpenAIClient(&config.OpenAIConfig{}, zap.NewNop())
+
+	resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatMessage{
+			{Role: "system", Content: "You are a task enrichment assistant."},
+			{Role: "user", Content: "Write quarterly report"},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+
+	require.NoError(t, err)
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resp.Content), &parsed))
+	assert.Equal(t, "mastery", parsed["category"])
+	assert.Equal(t, float64(30), parsed["estimatedMinutes"])
+}
+
+func TestSandboxOpenAIClient_TextReply(t *testing.T) {
+	client := NewSandboxOpenAIClient(&config.OpenAIConfig{}, zap.NewNop())
+
+	resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "hello"}},
+	})
+
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "sandbox")
+	assert.Equal(t, "stop", resp.FinishReason)
+}
+
+func TestSandboxStripeClient_CheckoutSession(t *testing.T) {
+	client := NewSandboxStripeClient(&config.StripeConfig{}, zap.NewNop())
+
+	session, err := client.CreateCheckoutSession("user@example.com", "http://localhost:3000/billing?ok=1", "http://localhost:3000/billing")
+
+	require.NoError(t, err)
+	assert.False(t, session.Livemode)
+	assert.Contains(t, session.URL, "?ok=1&session_id="+session.ID)
+	assert.Equal(t, sandboxPriceID, client.GetProPriceID())
+}
+
+func TestSandboxStripeClient_CancelSubscription(t *testing.T) {
+	client := NewSandboxStripeClient(&config.StripeConfig{}, zap.NewNop())
+
+	sub, err := client.CancelSubscription("sub_123", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, "sub_123", sub.ID)
+	assert.True(t, sub.CancelAtPeriodEnd)
+}
+
+func TestSandboxPlaidClient_SyncTransactions(t *testing.T) {
+	client := NewSandboxPlaidClient(zap.NewNop())
+	ctx := context.Background()
+
+	first, err := client.SyncTransactions(ctx, SyncTransactionsRequest{AccessToken: "access-sandbox"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Added)
+	assert.Equal(t, sandboxSyncCursor, first.NextCursor)
+
+	second, err := client.SyncTransactions(ctx, SyncTransactionsRequest{AccessToken: "access-sandbox", Cursor: &first.NextCursor})
+	require.NoError(t, err)
+	assert.Empty(t, second.Added)
+}
+
+func TestSandboxPlaidClient_GetAccounts(t *testing.T) {
+	client := NewSandboxPlaidClient(zap.NewNop())
+
+	accounts, err := client.GetAccounts(context.Background(), "access-sandbox")
+
+	require.NoError(t, err)
+	assert.Len(t, accounts, 2)
+}
@@ -24,6 +24,7 @@ type StripeClient struct {
 	successURL    string
 	cancelURL     string
 	logger        *zap.Logger
+	sandbox       bool // canned responses, see NewSandboxStripeClient
 }
 
 // NewStripeClient creates a new Stripe client
@@ -55,6 +56,10 @@ func (c *StripeClient) CreateCheckoutSession(customerEmail string, successURL, c
 		cancelURL = c.cancelURL
 	}
 
+	if c.sandbox {
+		return sandboxCheckoutSession(customerEmail, successURL), nil
+	}
+
 	params := &stripe.CheckoutSessionParams{
 		Mode: stripe.String(string(stripe.CheckoutSessionModeSubscription)),
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
@@ -103,6 +108,10 @@ func (c *StripeClient) CreatePortalSession(customerID string, returnURL string)
 		return nil, fmt.Errorf("customer ID is required")
 	}
 
+	if c.sandbox {
+		return sandboxPortalSession(customerID, returnURL), nil
+	}
+
 	params := &stripe.BillingPortalSessionParams{
 		Customer:  stripe.String(customerID),
 		ReturnURL: stripe.String(returnURL),
@@ -128,6 +137,14 @@ func (c *StripeClient) CreatePortalSession(customerID string, returnURL string)
 
 // GetCustomer retrieves a Stripe customer by ID
 func (c *StripeClient) GetCustomer(customerID string) (*stripe.Customer, error) {
+	if c.sandbox {
+		return &stripe.Customer{
+			ID:              customerID,
+			Object:          "customer",
+			InvoiceSettings: &stripe.CustomerInvoiceSettings{DefaultPaymentMethod: sandboxPaymentMethod()},
+		}, nil
+	}
+
 	cust, err := customer.Get(customerID, nil)
 	if err != nil {
 		c.logger.Error("Failed to get customer", zap.Error(err), zap.String("customerId", customerID))
@@ -139,6 +156,10 @@ func (c *StripeClient) GetCustomer(customerID string) (*stripe.Customer, error)
 
 // GetSubscription retrieves a Stripe subscription by ID
 func (c *StripeClient) GetSubscription(subscriptionID string) (*stripe.Subscription, error) {
+	if c.sandbox {
+		return sandboxSubscriptionObject(subscriptionID, false), nil
+	}
+
 	subscription, err := sub.Get(subscriptionID, nil)
 	if err != nil {
 		c.logger.Error("Failed to get subscription", zap.Error(err), zap.String("subscriptionId", subscriptionID))
@@ -153,6 +174,9 @@ func (c *StripeClient) ListInvoices(customerID string, limit int64) ([]*stripe.I
 	if limit <= 0 {
 		limit = 10
 	}
+	if c.sandbox {
+		return []*stripe.Invoice{}, nil
+	}
 
 	params := &stripe.InvoiceListParams{
 		Customer: stripe.String(customerID),
@@ -194,6 +218,10 @@ func (c *StripeClient) GetPaymentMethod(customerID string) (*stripe.PaymentMetho
 		return nil, fmt.Errorf("no default payment method found")
 	}
 
+	if c.sandbox {
+		return cust.InvoiceSettings.DefaultPaymentMethod, nil
+	}
+
 	paymentMethodID := cust.InvoiceSettings.DefaultPaymentMethod.ID
 
 	pm, err := paymentmethod.Get(paymentMethodID, nil)
@@ -207,6 +235,10 @@ func (c *StripeClient) GetPaymentMethod(customerID string) (*stripe.PaymentMetho
 
 // CancelSubscription cancels a subscription at period end
 func (c *StripeClient) CancelSubscription(subscriptionID string, cancelAtPeriodEnd bool) (*stripe.Subscription, error) {
+	if c.sandbox {
+		return sandboxSubscriptionObject(subscriptionID, cancelAtPeriodEnd), nil
+	}
+
 	params := &stripe.SubscriptionParams{
 		CancelAtPeriodEnd: stripe.Bool(cancelAtPeriodEnd),
 	}
@@ -227,6 +259,10 @@ func (c *StripeClient) CancelSubscription(subscriptionID string, cancelAtPeriodE
 
 // ReactivateSubscription reactivates a canceled subscription
 func (c *StripeClient) ReactivateSubscription(subscriptionID string) (*stripe.Subscription, error) {
+	if c.sandbox {
+		return sandboxSubscriptionObject(subscriptionID, false), nil
+	}
+
 	params := &stripe.SubscriptionParams{
 		CancelAtPeriodEnd: stripe.Bool(false),
 	}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,10 +18,25 @@ type Config struct {
 	Stripe       StripeConfig       `yaml:"stripe"`
 	Plaid        PlaidConfig        `yaml:"plaid"`
 	AlphaVantage AlphaVantageConfig `yaml:"alpha_vantage"`
+	Investment   InvestmentConfig   `yaml:"investment"`
+	ImportExport ImportExportConfig `yaml:"import_export"`
+	AIContext    AIContextConfig    `yaml:"ai_context"`
+	AIReprocess  AIReprocessConfig  `yaml:"ai_reprocess"`
+	AICache      AICacheConfig      `yaml:"ai_cache"`
+	LLMLogs      LLMLogsConfig      `yaml:"llm_logs"`
+	AccountUsage AccountUsageConfig `yaml:"account_usage"`
+	Finance      FinanceConfig      `yaml:"finance"`
+	Goals        GoalsConfig        `yaml:"goals"`
+	EntityGraph  EntityGraphConfig  `yaml:"entity_graph"`
+	FeatureFlags FeatureFlagsConfig `yaml:"feature_flags"`
+	Documents    DocumentsConfig    `yaml:"documents"`
+	Webhooks     WebhooksConfig     `yaml:"webhooks"`
+	Merchants    MerchantsConfig    `yaml:"merchants"`
 	Anonymous    AnonymousConfig    `yaml:"anonymous"`
 	Logging      LoggingConfig      `yaml:"logging"`
 	Metrics      MetricsConfig      `yaml:"metrics"`
 	Workers      WorkersConfig      `yaml:"workers"`
+	Cron         CronConfig         `yaml:"cron"`
 	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
 	Upload       UploadConfig       `yaml:"upload"`
 	Cache        CacheConfig        `yaml:"cache"`
@@ -30,13 +46,39 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	Port           int           `yaml:"port"`
-	Host           string        `yaml:"host"`
-	ReadTimeout    time.Duration `yaml:"read_timeout"`
-	WriteTimeout   time.Duration `yaml:"write_timeout"`
-	IdleTimeout    time.Duration `yaml:"idle_timeout"`
-	MaxHeaderBytes int           `yaml:"max_header_bytes"`
-	CORS           CORSConfig    `yaml:"cors"`
+	Port           int                  `yaml:"port"`
+	Host           string               `yaml:"host"`
+	ReadTimeout    time.Duration        `yaml:"read_timeout"`
+	WriteTimeout   time.Duration        `yaml:"write_timeout"`
+	IdleTimeout    time.Duration        `yaml:"idle_timeout"`
+	MaxHeaderBytes int                  `yaml:"max_header_bytes"`
+	CORS           CORSConfig           `yaml:"cors"`
+	CSRF           CSRFConfig           `yaml:"csrf"`
+	RequestTimeout RequestTimeoutConfig `yaml:"request_timeout"`
+	// SafeMode disables destructive endpoints for the whole deployment, for
+	// shared or demo instances
+	SafeMode bool `yaml:"safe_mode"`
+}
+
+// RequestTimeoutConfig bounds how long a handler may run before returning 504
+type RequestTimeoutConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Default time.Duration `yaml:"default"`
+	AI      time.Duration `yaml:"ai"`
+	AIPaths []string      `yaml:"ai_paths"` // Path prefixes that get the AI timeout
+	// Long is the limit for bulk transfers such as exports, imports and photo
+	// uploads; their connection read/write deadlines are extended to match
+	Long      time.Duration `yaml:"long"`
+	LongPaths []string      `yaml:"long_paths"` // Path prefixes that get the long timeout; {name} matches one segment
+}
+
+// CSRFConfig configures double-submit CSRF protection for cookie-authenticated requests
+type CSRFConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	CookieName     string   `yaml:"cookie_name"`
+	HeaderName     string   `yaml:"header_name"`
+	SessionCookies []string `yaml:"session_cookies"` // Cookies that mark a request as cookie-authenticated
+	SecureCookie   bool     `yaml:"secure_cookie"`
 }
 
 type CORSConfig struct {
@@ -54,6 +96,11 @@ type FirebaseConfig struct {
 	CredentialsPath string `yaml:"credentials_path"`
 	StorageBucket   string `yaml:"storage_bucket"`
 	DatabaseID      string `yaml:"database_id"`
+	// OverrideDatabases are extra databases of the project that admins (users
+	// with the "admin" custom claim) may select per request with the
+	// X-Firestore-Database header, e.g. a regional replica or a test database.
+	// Everyone else always uses DatabaseID.
+	OverrideDatabases []string `yaml:"override_databases"`
 }
 
 type OpenAIConfig struct {
@@ -104,10 +151,159 @@ type AlphaVantageConfig struct {
 	} `yaml:"rate_limit"`
 }
 
+type InvestmentConfig struct {
+	MaxProjectionMonths int `yaml:"max_projection_months"`
+}
+
+type ImportExportConfig struct {
+	BatchSize int `yaml:"batch_size"`
+	// ExportLimits caps export size per subscription tier; the "free" entry
+	// applies to users without a known tier
+	ExportLimits map[string]ExportLimit `yaml:"export_limits"`
+	// SummaryConcurrency bounds the collection reads of an export summary and
+	// SummaryCacheTTL how long it is cached per user; zero uses the defaults
+	SummaryConcurrency int           `yaml:"summary_concurrency"`
+	SummaryCacheTTL    time.Duration `yaml:"summary_cache_ttl"`
+}
+
+// ExportLimit caps the items in one export; zero means no limit
+type ExportLimit struct {
+	MaxItems              int `yaml:"max_items"`
+	MaxItemsPerCollection int `yaml:"max_items_per_collection"`
+}
+
+type AIContextConfig struct {
+	MaxContextTokens int `yaml:"max_context_tokens"`
+}
+
+// AICacheConfig controls reuse of AI responses for identical thought
+// processing requests; TTL zero falls back to the service default
+type AICacheConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+// LLMLogsConfig controls compaction of stored AI prompts and responses.
+// Logs older than CompactAfter keep their metadata but lose their bodies;
+// zero values fall back to service defaults. CostPerMillionTokens prices
+// logged tokens for usage reports, in USD keyed by model name prefix; logs
+// only record total tokens, so each rate blends input and output pricing.
+type LLMLogsConfig struct {
+	CompactAfter         time.Duration      `yaml:"compact_after"`
+	CompactBatch         int                `yaml:"compact_batch"`
+	CostPerMillionTokens map[string]float64 `yaml:"cost_per_million_tokens"`
+}
+
+// AccountUsageConfig controls GET /api/account/usage. Summing a user's Cloud
+// Storage objects means listing them, so the total is cached per user for
+// StorageCacheTTL; zero falls back to the service default.
+type AccountUsageConfig struct {
+	StorageCacheTTL time.Duration `yaml:"storage_cache_ttl"`
+}
+
+// FinanceConfig controls GET /api/finance/dashboard. The dashboard composes
+// several analytics, so it is cached per user for DashboardCacheTTL; zero
+// falls back to the service default.
+type FinanceConfig struct {
+	DashboardCacheTTL time.Duration `yaml:"dashboard_cache_ttl"`
+}
+
+// GoalsConfig controls goal deadline tracking. A goal is at risk when its
+// progress trails the share of time elapsed towards its targetDate by more
+// than AtRiskMargin (0-1); zero falls back to the service default.
+type GoalsConfig struct {
+	AtRiskMargin float64 `yaml:"at_risk_margin"`
+}
+
+// EntityGraphConfig bounds the entity graph. MaxRelationshipsPerEntity caps
+// the active relationships from any one source entity; creating one beyond
+// the cap archives the weakest. Zero falls back to the service default.
+type EntityGraphConfig struct {
+	MaxRelationshipsPerEntity int `yaml:"max_relationships_per_entity"`
+}
+
+// AIReprocessConfig paces bulk thought reprocessing jobs. Concurrency is the
+// number of thoughts in flight at once and Interval the minimum gap between
+// starting AI requests; zero values fall back to service defaults.
+type AIReprocessConfig struct {
+	Concurrency int           `yaml:"concurrency"`
+	Interval    time.Duration `yaml:"interval"`
+	MaxThoughts int           `yaml:"max_thoughts"`
+}
+
+// FeatureFlagsConfig holds default flag values; the featureFlags/global document
+// and per-user overrides in Firestore take precedence
+type FeatureFlagsConfig struct {
+	CacheTTL time.Duration   `yaml:"cache_ttl"`
+	Defaults map[string]bool `yaml:"defaults"`
+}
+
+// DocumentsConfig configures the generic document endpoints. Per-collection
+// page sizes override the global ones; zero values fall back.
+type DocumentsConfig struct {
+	DefaultPageSize int                         `yaml:"default_page_size"`
+	MaxPageSize     int                         `yaml:"max_page_size"`
+	Collections     map[string]CollectionConfig `yaml:"collections"`
+	// StrictImmutableFields rejects updates touching a collection's immutable
+	// fields instead of silently dropping those changes
+	StrictImmutableFields bool `yaml:"strict_immutable_fields"`
+}
+
+// CollectionConfig overrides document settings for a single collection.
+// ImmutableFields are top-level fields that cannot be changed by updates, in
+// addition to the server-managed id, createdAt, updatedAt, updatedBy and version.
+// Fields, when set, lists the known top-level fields that may be requested in
+// a sparse fieldset. SoftHistory records a field-level diff of every update in
+// the document's history subcollection, keeping the latest HistoryLimit entries.
+// DefaultFilters hide documents from list requests unless the request asks for
+// everything or filters the same field itself.
+type CollectionConfig struct {
+	DefaultPageSize int             `yaml:"default_page_size"`
+	MaxPageSize     int             `yaml:"max_page_size"`
+	ImmutableFields []string        `yaml:"immutable_fields"`
+	Fields          []string        `yaml:"fields"`
+	SoftHistory     bool            `yaml:"soft_history"`
+	HistoryLimit    int             `yaml:"history_limit"`
+	DefaultFilters  []DefaultFilter `yaml:"default_filters"`
+}
+
+// DefaultFilter compares a top-level field with a value; Op is == or !=.
+// A document without the field is not equal to any value.
+type DefaultFilter struct {
+	Field string `yaml:"field"`
+	Op    string `yaml:"op"`
+	Value string `yaml:"value"`
+}
+
+// WebhooksConfig configures inbound webhook handling (Stripe, Plaid)
+type WebhooksConfig struct {
+	// DedupTTL is how long processed event IDs are remembered to drop redeliveries
+	DedupTTL time.Duration `yaml:"dedup_ttl"`
+}
+
+// MerchantsConfig holds merchant alias rules shared by every user. Rules in a
+// user's merchantAliases collection are checked first.
+type MerchantsConfig struct {
+	Aliases []MerchantAliasRule `yaml:"aliases"`
+}
+
+// MerchantAliasRule maps merchant names to a canonical name. Prefix and Pattern
+// (a regular expression) are matched against the normalized merchant name:
+// lowercase letters, digits and single spaces.
+type MerchantAliasRule struct {
+	Canonical string `yaml:"canonical"`
+	Prefix    string `yaml:"prefix"`
+	Pattern   string `yaml:"pattern"`
+}
+
 type AnonymousConfig struct {
 	SessionDuration time.Duration `yaml:"session_duration"`
 	AIOverrideKey   string        `yaml:"ai_override_key"`
 	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	// Per-session quotas and how long anonymous data is kept before it is purged
+	MaxDocuments  int           `yaml:"max_documents"`
+	MaxAICalls    int           `yaml:"max_ai_calls"`
+	DataRetention time.Duration `yaml:"data_retention"`
 }
 
 type LoggingConfig struct {
@@ -133,6 +329,15 @@ type WorkersConfig struct {
 	VisaDataUpdate    CronWorkerConfig `yaml:"visa_data_update"`
 }
 
+// CronConfig protects POST /api/internal/cron/{job}. Requests must carry
+// Secret in the X-Cron-Secret header; an empty Secret disables the endpoint.
+// Timeouts overrides DefaultTimeout per job name.
+type CronConfig struct {
+	Secret         string                   `yaml:"secret"`
+	DefaultTimeout time.Duration            `yaml:"default_timeout"`
+	Timeouts       map[string]time.Duration `yaml:"timeouts"`
+}
+
 type WorkerConfig struct {
 	Enabled    bool          `yaml:"enabled"`
 	Interval   time.Duration `yaml:"interval"`
@@ -153,11 +358,15 @@ type RateLimitConfig struct {
 		FreeTier int `yaml:"free_tier"`
 		ProTier  int `yaml:"pro_tier"`
 	} `yaml:"per_user"`
+	// IngestPerToken caps the requests per minute of each incoming-webhook token
+	IngestPerToken int `yaml:"ingest_per_token"`
 }
 
 type UploadConfig struct {
 	MaxFileSize  int64               `yaml:"max_file_size"`
 	AllowedTypes map[string][]string `yaml:"allowed_types"`
+	// MaxConcurrentPerUser caps the uploads one user can have in flight
+	MaxConcurrentPerUser int `yaml:"max_concurrent_per_user"`
 }
 
 type CacheConfig struct {
@@ -195,6 +404,9 @@ type DevelopmentConfig struct {
 	DebugRoutes bool `yaml:"debug_routes"`
 	PrettyLogs  bool `yaml:"pretty_logs"`
 	DisableAuth bool `yaml:"disable_auth"`
+	// TestMode replaces the OpenAI, Stripe and Plaid clients with sandbox stubs
+	// returning canned responses. Refused by Validate in production.
+	TestMode bool `yaml:"test_mode"`
 }
 
 // Load reads and parses the configuration file
@@ -243,9 +455,33 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("openai.default_model is required when api_key is set")
 	}
 
+	for name, collection := range c.Documents.Collections {
+		for _, filter := range collection.DefaultFilters {
+			if filter.Field == "" || (filter.Op != "==" && filter.Op != "!=") {
+				return fmt.Errorf("documents.collections.%s.default_filters: each filter needs a field and an op of == or !=", name)
+			}
+		}
+	}
+
+	// Test mode must never run against production
+	if c.Development.TestMode {
+		if IsProductionEnvironment() {
+			return fmt.Errorf("development.test_mode cannot be enabled in production")
+		}
+		if strings.HasPrefix(c.Stripe.SecretKey, "sk_live_") || c.Plaid.Environment == "production" {
+			return fmt.Errorf("development.test_mode cannot be combined with live Stripe or Plaid credentials")
+		}
+	}
+
 	return nil
 }
 
+// IsProductionEnvironment reports whether the server is running as a deployed
+// service: ENVIRONMENT=production, or any Cloud Run revision (K_SERVICE is set)
+func IsProductionEnvironment() bool {
+	return os.Getenv("ENVIRONMENT") == "production" || os.Getenv("K_SERVICE") != ""
+}
+
 // GetServerAddr returns the full server address
 func (c *Config) GetServerAddr() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
@@ -354,6 +354,65 @@ func TestDevelopmentConfig_Structure(t *testing.T) {
 	assert.True(t, dev.Enabled)
 }
 
+func validTestModeConfig() *Config {
+	return &Config{
+		Server:      ServerConfig{Port: 8080},
+		Firebase:    FirebaseConfig{ProjectID: "test-project", CredentialsPath: "/path/to/credentials.json"},
+		Development: DevelopmentConfig{TestMode: true},
+	}
+}
+
+func TestValidate_TestModeAllowedLocally(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	t.Setenv("K_SERVICE", "")
+
+	assert.NoError(t, validTestModeConfig().Validate())
+}
+
+func TestValidate_TestModeRejectedInProduction(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("K_SERVICE", "")
+
+	assert.Error(t, validTestModeConfig().Validate())
+}
+
+func TestValidate_TestModeRejectedOnCloudRun(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("K_SERVICE", "focus-notebook-backend")
+
+	assert.Error(t, validTestModeConfig().Validate())
+}
+
+func TestValidate_TestModeRejectedWithLiveKeys(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("K_SERVICE", "")
+
+	cfg := validTestModeConfig()
+	cfg.Stripe.SecretKey = "sk_live_abc"
+	assert.Error(t, cfg.Validate())
+
+	cfg = validTestModeConfig()
+	cfg.Plaid.Environment = "production"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidate_DefaultFilters(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("K_SERVICE", "")
+
+	cfg := validTestModeConfig()
+	cfg.Documents.Collections = map[string]CollectionConfig{
+		"tasks": {DefaultFilters: []DefaultFilter{{Field: "status", Op: "!=", Value: "archived"}}},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Documents.Collections["tasks"] = CollectionConfig{DefaultFilters: []DefaultFilter{{Field: "status", Op: "<", Value: "archived"}}}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Documents.Collections["tasks"] = CollectionConfig{DefaultFilters: []DefaultFilter{{Op: "==", Value: "archived"}}}
+	assert.Error(t, cfg.Validate())
+}
+
 func TestRateLimitConfig_Structure(t *testing.T) {
 	rl := RateLimitConfig{
 		Enabled: true,
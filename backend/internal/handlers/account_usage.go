@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// AccountUsageHandler reports the user's data footprint
+type AccountUsageHandler struct {
+	usageService *services.AccountUsageService
+	logger       *zap.Logger
+}
+
+// NewAccountUsageHandler creates a new account usage handler
+func NewAccountUsageHandler(usageService *services.AccountUsageService, logger *zap.Logger) *AccountUsageHandler {
+	return &AccountUsageHandler{
+		usageService: usageService,
+		logger:       logger,
+	}
+}
+
+// GetUsage returns the user's document counts, storage total and AI usage
+// GET /api/account/usage
+func (h *AccountUsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	usage, err := h.usageService.GetUsage(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to compute account usage", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute account usage", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, usage, "Account usage retrieved")
+}
+
+// DeleteAccount reports what deleting the user's account would remove. Only
+// dryRun=true is supported; the deletion itself is not available yet, so
+// other requests are rejected without touching any data.
+// DELETE /api/account?dryRun=true
+func (h *AccountUsageHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	if r.URL.Query().Get("dryRun") != "true" {
+		utils.RespondError(w, "Account deletion is not available; use dryRun=true to see what it would remove", http.StatusNotImplemented)
+		return
+	}
+
+	manifest, err := h.usageService.DeletionManifest(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to compute account deletion manifest", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute account deletion manifest", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, manifest, "Account deletion dry run completed")
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -68,6 +71,71 @@ func (h *AnalyticsHandler) GetDashboardAnalytics(w http.ResponseWriter, r *http.
 	utils.RespondSuccess(w, analytics, "Dashboard analytics retrieved")
 }
 
+// GetEstimationAnalytics compares task estimates with time spent in focus sessions
+// GET /api/analytics/estimation
+func (h *AnalyticsHandler) GetEstimationAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	estimation, err := h.dashboardSvc.ComputeEstimation(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to compute estimation analytics", zap.Error(err))
+		utils.RespondError(w, "Failed to compute estimation analytics", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, estimation, "Estimation analytics retrieved")
+}
+
+// GetSessionTaskConsistency reports focus sessions referencing deleted tasks
+// and tasks whose actualMinutes disagrees with the time logged in sessions
+// GET /api/maintenance/session-task-consistency
+func (h *AnalyticsHandler) GetSessionTaskConsistency(w http.ResponseWriter, r *http.Request) {
+	h.sessionTaskConsistency(w, r, false)
+}
+
+// RepairSessionTaskConsistency recomputes actualMinutes from focus sessions
+// for the tasks where they disagree
+// POST /api/maintenance/session-task-consistency
+func (h *AnalyticsHandler) RepairSessionTaskConsistency(w http.ResponseWriter, r *http.Request) {
+	h.sessionTaskConsistency(w, r, true)
+}
+
+func (h *AnalyticsHandler) sessionTaskConsistency(w http.ResponseWriter, r *http.Request, repair bool) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	result, err := h.dashboardSvc.CheckSessionTaskConsistency(ctx, uid, repair)
+	if err != nil {
+		h.logger.Error("Failed to check session task consistency", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to check session task consistency", http.StatusInternalServerError)
+		return
+	}
+
+	message := "Session task consistency checked"
+	if repair {
+		message = "Task actual minutes recomputed from focus sessions"
+	}
+	utils.RespondSuccess(w, result, message)
+}
+
+// GetLocationAnalytics aggregates focus minutes and average mood by location label
+// GET /api/analytics/by-location?includeCoordinates=true
+func (h *AnalyticsHandler) GetLocationAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	includeCoordinates := r.URL.Query().Get("includeCoordinates") == "true"
+	analytics, err := h.dashboardSvc.ComputeByLocation(ctx, uid, includeCoordinates)
+	if err != nil {
+		h.logger.Error("Failed to compute location analytics", zap.Error(err))
+		utils.RespondError(w, "Failed to compute location analytics", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, analytics, "Location analytics retrieved")
+}
+
 // GetSpendingAnalytics returns spending analytics for a user
 // GET /api/analytics/spending?startDate=YYYY-MM-DD&endDate=YYYY-MM-DD&accountIds=id1,id2
 func (h *AnalyticsHandler) GetSpendingAnalytics(w http.ResponseWriter, r *http.Request) {
@@ -116,3 +184,233 @@ func (h *AnalyticsHandler) GetSpendingAnalytics(w http.ResponseWriter, r *http.R
 
 	utils.RespondSuccess(w, analytics, "Spending analytics retrieved")
 }
+
+// DeduplicateTransactions merges transactions imported by both CSV upload and
+// Plaid sync into the Plaid record
+// POST /api/transactions/deduplicate
+func (h *AnalyticsHandler) DeduplicateTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	summary, err := h.spendingSvc.DeduplicateTransactions(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to deduplicate transactions", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to deduplicate transactions", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, summary, "Transactions deduplicated")
+}
+
+// categorizeRulesRequest is the body of the categorize-rules endpoints
+type categorizeRulesRequest struct {
+	Rules []services.CategoryRuleInput `json:"rules"`
+}
+
+// PreviewCategoryRules reports how many transactions a rule set would
+// recategorize, with a sample, without changing anything
+// POST /api/transactions/categorize-rules/preview
+func (h *AnalyticsHandler) PreviewCategoryRules(w http.ResponseWriter, r *http.Request) {
+	h.categorizeRules(w, r, false)
+}
+
+// ApplyCategoryRules recategorizes the transactions a rule set matches
+// POST /api/transactions/categorize-rules
+func (h *AnalyticsHandler) ApplyCategoryRules(w http.ResponseWriter, r *http.Request) {
+	h.categorizeRules(w, r, true)
+}
+
+func (h *AnalyticsHandler) categorizeRules(w http.ResponseWriter, r *http.Request, apply bool) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req categorizeRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	run, message := h.spendingSvc.PreviewCategoryRules, "Category rules previewed"
+	if apply {
+		run, message = h.spendingSvc.ApplyCategoryRules, "Category rules applied"
+	}
+	result, err := run(ctx, uid, req.Rules)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCategoryRule) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to run category rules", zap.String("uid", uid), zap.Bool("apply", apply), zap.Error(err))
+		utils.RespondError(w, "Failed to run category rules", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, message)
+}
+
+// GetCashFlow returns monthly income, expenses, net and savings rate, with
+// transfers between the user's own accounts excluded
+// GET /api/analytics/cashflow?months=6
+func (h *AnalyticsHandler) GetCashFlow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	months := 0
+	if raw := r.URL.Query().Get("months"); raw != "" {
+		var err error
+		months, err = strconv.Atoi(raw)
+		if err != nil || months < 1 {
+			utils.RespondError(w, "months must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cashFlow, err := h.spendingSvc.ComputeCashFlow(ctx, uid, months)
+	if err != nil {
+		h.logger.Error("Failed to compute cash flow", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute cash flow", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, cashFlow, "Cash flow retrieved")
+}
+
+// GetSpendingAnomalies returns charges well above the user's usual spend at
+// the merchant or in the category
+// GET /api/analytics/spending/anomalies?months=6&zScore=3
+func (h *AnalyticsHandler) GetSpendingAnomalies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	query := r.URL.Query()
+
+	months := 0
+	if raw := query.Get("months"); raw != "" {
+		var err error
+		months, err = strconv.Atoi(raw)
+		if err != nil || months < 1 {
+			utils.RespondError(w, "months must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	zScore := 0.0
+	if raw := query.Get("zScore"); raw != "" {
+		var err error
+		zScore, err = strconv.ParseFloat(raw, 64)
+		if err != nil || zScore <= 0 {
+			utils.RespondError(w, "zScore must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	anomalies, err := h.spendingSvc.DetectAnomalies(ctx, uid, months, zScore)
+	if err != nil {
+		h.logger.Error("Failed to detect spending anomalies", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to detect spending anomalies", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, anomalies, "Spending anomalies retrieved")
+}
+
+// GetBestFocusTime recommends the time of day the user focuses best, from
+// the focus sessions of the last days days bucketed in timezone
+// GET /api/analytics/best-focus-time?days=90&timezone=America/Toronto
+func (h *AnalyticsHandler) GetBestFocusTime(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	query := r.URL.Query()
+
+	days := 0
+	if raw := query.Get("days"); raw != "" {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			utils.RespondError(w, "days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	best, err := h.dashboardSvc.BestFocusTime(ctx, uid, days, query.Get("timezone"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to compute best focus time", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute best focus time", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, best, "Best focus time retrieved")
+}
+
+// ListCashFlowOverrides returns the user's income/expense classification overrides
+// GET /api/analytics/cashflow/overrides
+func (h *AnalyticsHandler) ListCashFlowOverrides(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	overrides, err := h.spendingSvc.ListCashFlowOverrides(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to list cash flow overrides", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list cash flow overrides", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": overrides,
+		"count": len(overrides),
+	}, "Cash flow overrides retrieved")
+}
+
+// SetCashFlowOverride classifies a merchant or category as income, expense or transfer
+// PUT /api/analytics/cashflow/overrides
+func (h *AnalyticsHandler) SetCashFlowOverride(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.CashFlowOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	override, err := h.spendingSvc.SetCashFlowOverride(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCashFlowOverride) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to save cash flow override", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to save cash flow override", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, override, "Cash flow override saved")
+}
+
+// DeleteCashFlowOverride removes a classification override
+// DELETE /api/analytics/cashflow/overrides?kind=merchant|category&match=...
+func (h *AnalyticsHandler) DeleteCashFlowOverride(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	kind := r.URL.Query().Get("kind")
+	match := r.URL.Query().Get("match")
+
+	if kind == "" || match == "" {
+		utils.RespondError(w, "kind and match are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.spendingSvc.DeleteCashFlowOverride(ctx, uid, kind, match); err != nil {
+		if writeRepositoryError(w, err, "Cash flow override not found") {
+			return
+		}
+		h.logger.Error("Failed to delete cash flow override", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to delete cash flow override", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{"kind": kind, "match": match}, "Cash flow override deleted")
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -17,8 +19,8 @@ func TestAnalyticsHandler_GetDashboardAnalytics(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	dashboardSvc := services.NewDashboardAnalyticsService(mockRepo, logger)
-	spendingSvc := services.NewSpendingAnalyticsService(mockRepo, logger)
+	dashboardSvc := services.NewDashboardAnalyticsService(mockRepo, logger, nil)
+	spendingSvc := services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil)
 	handler := NewAnalyticsHandler(dashboardSvc, spendingSvc, logger)
 
 	uid := "test-user-123"
@@ -100,8 +102,8 @@ func TestAnalyticsHandler_GetSpendingAnalytics(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	dashboardSvc := services.NewDashboardAnalyticsService(mockRepo, logger)
-	spendingSvc := services.NewSpendingAnalyticsService(mockRepo, logger)
+	dashboardSvc := services.NewDashboardAnalyticsService(mockRepo, logger, nil)
+	spendingSvc := services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil)
 	handler := NewAnalyticsHandler(dashboardSvc, spendingSvc, logger)
 
 	uid := "test-user-123"
@@ -172,3 +174,300 @@ func TestAnalyticsHandler_GetSpendingAnalytics(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyticsHandler_DeduplicateTransactions(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	uid := "test-user-123"
+	mockRepo.AddDocument("users/"+uid+"/transactions/csv-1", map[string]interface{}{
+		"id": "csv-1", "date": "2024-01-15", "description": "NETFLIX.COM", "amount": 15.49, "source": "csv-upload",
+	})
+	mockRepo.AddDocument("transactions/txn1", map[string]interface{}{
+		"uid": uid, "plaidTransactionId": "txn1", "postedAt": "2024-01-15",
+		"amount": 15.49, "originalDescription": "NETFLIX.COM", "source": "plaid",
+	})
+
+	req := httptest.NewRequest("POST", "/api/transactions/deduplicate", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", uid))
+	w := httptest.NewRecorder()
+
+	handler.DeduplicateTransactions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data services.DeduplicationSummary `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data.Merged != 1 {
+		t.Errorf("Expected 1 merged transaction, got %d", resp.Data.Merged)
+	}
+}
+
+func TestAnalyticsHandler_CategoryRules(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	uid := "test-user-123"
+	mockRepo.AddDocument("users/"+uid+"/transactions/csv-1", map[string]interface{}{
+		"id": "csv-1", "date": "2024-01-15", "merchant": "NETFLIX.COM", "amount": 15.49, "category": "Shopping",
+	})
+
+	tests := []struct {
+		name        string
+		handle      http.HandlerFunc
+		body        string
+		wantStatus  int
+		wantChanged int
+	}{
+		{"preview", handler.PreviewCategoryRules, `{"rules": [{"category": "Entertainment", "prefix": "netflix"}]}`, http.StatusOK, 1},
+		{"apply", handler.ApplyCategoryRules, `{"rules": [{"category": "Entertainment", "prefix": "netflix"}]}`, http.StatusOK, 1},
+		{"preview after apply", handler.PreviewCategoryRules, `{"rules": [{"category": "Entertainment", "prefix": "netflix"}]}`, http.StatusOK, 0},
+		{"no rules", handler.PreviewCategoryRules, `{"rules": []}`, http.StatusBadRequest, 0},
+		{"bad pattern", handler.ApplyCategoryRules, `{"rules": [{"category": "Entertainment", "pattern": "("}]}`, http.StatusBadRequest, 0},
+		{"invalid json", handler.PreviewCategoryRules, `{`, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/transactions/categorize-rules", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", uid))
+			w := httptest.NewRecorder()
+
+			tt.handle(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data services.CategoryRulesResult `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Data.Changed != tt.wantChanged {
+				t.Errorf("Expected %d changed transactions, got %d", tt.wantChanged, resp.Data.Changed)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetLocationAnalytics(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	uid := "test-user-123"
+	mockRepo.AddDocument("users/"+uid+"/focusSessions/s1", map[string]interface{}{
+		"duration": int64(45), "isActive": false, "location": map[string]interface{}{"label": "Library", "lat": 40.7, "lng": -74.0},
+	})
+	mockRepo.AddDocument("users/"+uid+"/moods/m1", map[string]interface{}{
+		"value": int64(9), "location": map[string]interface{}{"label": "Library"},
+	})
+
+	tests := []struct {
+		name            string
+		query           string
+		wantCoordinates bool
+	}{
+		{"without coordinates", "", false},
+		{"with coordinates", "?includeCoordinates=true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/analytics/by-location"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", uid))
+			w := httptest.NewRecorder()
+
+			handler.GetLocationAnalytics(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			var resp struct {
+				Data services.LocationAnalytics `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Data.Locations) != 1 {
+				t.Fatalf("Expected 1 location, got %d", len(resp.Data.Locations))
+			}
+			library := resp.Data.Locations[0]
+			if library.FocusMinutes != 45 || library.AverageMood == nil || *library.AverageMood != 9 {
+				t.Errorf("Unexpected library stats: %+v", library)
+			}
+			if (library.Coordinates != nil) != tt.wantCoordinates {
+				t.Errorf("Expected coordinates present=%v, got %+v", tt.wantCoordinates, library.Coordinates)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetCashFlow(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"default months", "", http.StatusOK},
+		{"six months", "?months=6", http.StatusOK},
+		{"invalid months", "?months=abc", http.StatusBadRequest},
+		{"zero months", "?months=0", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/analytics/cashflow"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.GetCashFlow(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetSpendingAnomalies(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"defaults", "", http.StatusOK},
+		{"custom threshold", "?months=3&zScore=2.5", http.StatusOK},
+		{"invalid months", "?months=abc", http.StatusBadRequest},
+		{"invalid zScore", "?zScore=abc", http.StatusBadRequest},
+		{"negative zScore", "?zScore=-1", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/analytics/spending/anomalies"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.GetSpendingAnomalies(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetBestFocusTime(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"defaults", "", http.StatusOK},
+		{"custom window", "?days=30&timezone=America/Toronto", http.StatusOK},
+		{"invalid days", "?days=abc", http.StatusBadRequest},
+		{"zero days", "?days=0", http.StatusBadRequest},
+		{"invalid timezone", "?timezone=Mars/Base", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/analytics/best-focus-time"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.GetBestFocusTime(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_SessionTaskConsistency(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/task-1", map[string]interface{}{"id": "task-1", "actualMinutes": float64(60)})
+	mockRepo.AddDocument("users/test-user-123/focusSessions/session-1", map[string]interface{}{
+		"id": "session-1",
+		"tasks": []interface{}{
+			map[string]interface{}{"task": map[string]interface{}{"id": "task-1"}, "timeSpent": int64(600)},
+		},
+	})
+	logger := zap.NewNop()
+	handler := NewAnalyticsHandler(
+		services.NewDashboardAnalyticsService(mockRepo, logger, nil),
+		services.NewSpendingAnalyticsService(mockRepo, logger, nil, nil),
+		logger,
+	)
+
+	req := httptest.NewRequest("GET", "/api/maintenance/session-task-consistency", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+	handler.GetSessionTaskConsistency(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := mockRepo.Documents["users/test-user-123/tasks/task-1"]["actualMinutes"]; got != float64(60) {
+		t.Errorf("GET should not repair, actualMinutes = %v", got)
+	}
+
+	req = httptest.NewRequest("POST", "/api/maintenance/session-task-consistency", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w = httptest.NewRecorder()
+	handler.RepairSessionTaskConsistency(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := mockRepo.Documents["users/test-user-123/tasks/task-1"]["actualMinutes"]; got != float64(10) {
+		t.Errorf("Expected actualMinutes 10 after repair, got %v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// AttachmentHandler handles attaching uploaded files to tasks and thoughts
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+	logger            *zap.Logger
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(attachmentService *services.AttachmentService, logger *zap.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		logger:            logger,
+	}
+}
+
+// RemoveAttachmentRequest identifies the attachment to remove
+type RemoveAttachmentRequest struct {
+	StoragePath string `json:"storagePath"`
+}
+
+// AddAttachment links an uploaded storage object to a task or thought. The
+// object must live under the user's own storage paths.
+// POST /api/{collection}/{id}/attachments
+func (h *AttachmentHandler) AddAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+
+	var attachment services.Attachment
+	if err := json.NewDecoder(r.Body).Decode(&attachment); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := h.attachmentService.AddAttachment(ctx, uid, vars["collection"], vars["id"], attachment)
+	if err != nil {
+		h.respondAttachmentError(w, err, uid, vars["collection"], vars["id"])
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"attachments": attachments,
+	}, "Attachment added")
+}
+
+// RemoveAttachment unlinks an attachment and deletes its storage object
+// DELETE /api/{collection}/{id}/attachments
+func (h *AttachmentHandler) RemoveAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+
+	var req RemoveAttachmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StoragePath == "" {
+		utils.RespondError(w, "storagePath is required", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := h.attachmentService.RemoveAttachment(ctx, uid, vars["collection"], vars["id"], req.StoragePath)
+	if err != nil {
+		h.respondAttachmentError(w, err, uid, vars["collection"], vars["id"])
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"attachments": attachments,
+	}, "Attachment removed")
+}
+
+func (h *AttachmentHandler) respondAttachmentError(w http.ResponseWriter, err error, uid, collection, id string) {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedCollection):
+		utils.RespondError(w, "collection must be tasks or thoughts", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidAttachment):
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrAttachmentForbidden):
+		utils.RespondError(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		utils.RespondError(w, "Attachment not found", http.StatusNotFound)
+	default:
+		if writeRepositoryError(w, err, "Document not found") {
+			return
+		}
+		h.logger.Error("Failed to update attachments",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to update attachments", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestAttachmentHandler_AddAttachment(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/t1", map[string]interface{}{"title": "File taxes"})
+	handler := NewAttachmentHandler(services.NewAttachmentService(mockRepo, zap.NewNop(), nil), zap.NewNop())
+
+	tests := []struct {
+		name       string
+		collection string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{"own file", "tasks", "t1", `{"storagePath":"users/test-user-123/attachments/r.pdf","contentType":"application/pdf","fileName":"r.pdf","size":100}`, http.StatusOK},
+		{"other user's file", "tasks", "t1", `{"storagePath":"users/someone-else/attachments/r.pdf","contentType":"application/pdf","fileName":"r.pdf","size":100}`, http.StatusForbidden},
+		{"bad content type", "tasks", "t1", `{"storagePath":"users/test-user-123/attachments/r.exe","contentType":"application/x-msdownload","fileName":"r.exe","size":100}`, http.StatusBadRequest},
+		{"unsupported collection", "goals", "g1", `{"storagePath":"users/test-user-123/attachments/r.pdf","contentType":"application/pdf","fileName":"r.pdf","size":100}`, http.StatusBadRequest},
+		{"missing document", "tasks", "missing", `{"storagePath":"users/test-user-123/attachments/r.pdf","contentType":"application/pdf","fileName":"r.pdf","size":100}`, http.StatusNotFound},
+		{"invalid body", "tasks", "t1", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/"+tt.collection+"/"+tt.id+"/attachments", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"collection": tt.collection, "id": tt.id})
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.AddAttachment(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAttachmentHandler_RemoveAttachment(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/thoughts/th1", map[string]interface{}{
+		services.AttachmentsField: []interface{}{
+			map[string]interface{}{"storagePath": "users/test-user-123/attachments/a.png", "contentType": "image/png", "fileName": "a.png", "size": int64(10)},
+		},
+	})
+	handler := NewAttachmentHandler(services.NewAttachmentService(mockRepo, zap.NewNop(), nil), zap.NewNop())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"missing path", `{}`, http.StatusBadRequest},
+		{"attached file", `{"storagePath":"users/test-user-123/attachments/a.png"}`, http.StatusOK},
+		{"already removed", `{"storagePath":"users/test-user-123/attachments/a.png"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/api/thoughts/th1/attachments", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"collection": "thoughts", "id": "th1"})
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.RemoveAttachment(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
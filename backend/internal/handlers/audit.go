@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// AuditHandler serves the user's audit log of sensitive operations
+type AuditHandler struct {
+	auditService *services.AuditService
+	logger       *zap.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *services.AuditService, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// List returns a page of the current user's audit log, newest first.
+// action keeps one action type; from and to are RFC 3339 times bounding the
+// timestamp (from inclusive, to exclusive). limit defaults to 50 and is
+// capped at 500. Pass the response's nextCursor as cursor for the next page.
+// GET /api/audit-log?action=&from=&to=&limit=50&cursor=
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	params := r.URL.Query()
+
+	query := services.AuditLogQuery{
+		Action: params.Get("action"),
+		Cursor: params.Get("cursor"),
+	}
+	if raw := params.Get("limit"); raw != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(raw)
+		if err != nil || query.Limit < 0 {
+			utils.RespondError(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	for name, bound := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			utils.RespondError(w, name+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*bound = parsed
+	}
+
+	page, err := h.auditService.List(ctx, uid, query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAuditQuery) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list audit log", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list audit log", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items":      page.Entries,
+		"count":      len(page.Entries),
+		"nextCursor": page.NextCursor,
+	}, "Audit log retrieved")
+}
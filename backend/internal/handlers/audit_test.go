@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/middleware"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestAuditHandler_ExportWritesAuditEntry(t *testing.T) {
+	logger := zap.NewNop()
+	repo := mocks.NewMockRepository()
+	auditService := services.NewAuditService(repo, logger)
+	exportHandler := NewImportExportHandler(services.NewImportExportService(repo, logger, 0, nil, 0, 0, nil), logger)
+	auditHandler := NewAuditHandler(auditService, logger)
+
+	audited := middleware.Audit(auditService, services.AuditActionExport)
+	router := mux.NewRouter()
+	router.Handle("/api/export", audited(http.HandlerFunc(exportHandler.ExportData))).Methods("GET")
+	router.Handle("/api/export/{collection}", audited(http.HandlerFunc(exportHandler.ExportCollection))).Methods("GET")
+	router.HandleFunc("/api/audit-log", auditHandler.List).Methods("GET")
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Failed exports are not audited
+	w := serve("/api/export/unknown")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve("/api/export?entityTypes=none")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = serve("/api/audit-log")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data struct {
+			Items []services.AuditEntry `json:"items"`
+			Count int                   `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Data.Count)
+
+	entry := resp.Data.Items[0]
+	assert.Equal(t, services.AuditActionExport, entry.Action)
+	assert.Equal(t, "test-user-123", entry.Actor)
+	assert.Equal(t, "203.0.113.7", entry.IP)
+	assert.Equal(t, "/api/export", entry.Details["path"])
+	assert.False(t, entry.Timestamp.IsZero())
+}
+
+func TestAuditHandler_ListRejectsBadLimit(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewAuditHandler(services.NewAuditService(mocks.NewMockRepository(), logger), logger)
+
+	req := httptest.NewRequest("GET", "/api/audit-log?limit=-1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
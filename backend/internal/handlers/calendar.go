@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// CalendarHandler handles the iCalendar feed and its tokens
+type CalendarHandler struct {
+	calendarFeedService *services.CalendarFeedService
+	logger              *zap.Logger
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarFeedService *services.CalendarFeedService, logger *zap.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		calendarFeedService: calendarFeedService,
+		logger:              logger,
+	}
+}
+
+// GetFeed returns the iCalendar feed for the user owning ?token=. It is not
+// behind auth, since calendar apps cannot send bearer headers.
+// GET /api/calendar/feed.ics
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.calendarFeedService.Feed(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFeedToken) {
+			http.Error(w, "Invalid calendar feed token", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to build calendar feed", zap.Error(err))
+		http.Error(w, "Failed to build calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="focus-notebook.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, _ = io.WriteString(w, feed)
+}
+
+// RotateFeedToken issues a new calendar feed token, revoking the previous
+// one. The optional body sets the IANA timezone feed times are shown in.
+// POST /api/calendar/feed-token
+func (h *CalendarHandler) RotateFeedToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req struct {
+		Timezone string `json:"timezone"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	token, err := h.calendarFeedService.RotateToken(ctx, uid, req.Timezone)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to issue calendar feed token", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to issue calendar feed token", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, token, "Calendar feed token issued")
+}
+
+// RevokeFeedToken disables the user's calendar feed
+// DELETE /api/calendar/feed-token
+func (h *CalendarHandler) RevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	if err := h.calendarFeedService.RevokeToken(ctx, uid); err != nil {
+		h.logger.Error("Failed to revoke calendar feed token", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to revoke calendar feed token", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, nil, "Calendar feed token revoked")
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestCalendarHandler_FeedTokenFlow(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/t1", map[string]interface{}{
+		"id": "t1", "title": "Renew passport", "dueDate": "2024-05-01",
+	})
+	logger := zap.NewNop()
+	handler := NewCalendarHandler(services.NewCalendarFeedService(mockRepo, logger), logger)
+
+	req := httptest.NewRequest("POST", "/api/calendar/feed-token", strings.NewReader(`{"timezone":"Asia/Dhaka"}`))
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+	handler.RotateFeedToken(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data services.CalendarFeedToken `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	req = httptest.NewRequest("GET", "/api/calendar/feed.ics?token="+resp.Data.Token, nil)
+	w = httptest.NewRecorder()
+	handler.GetFeed(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %q", ct)
+	}
+	if body := w.Body.String(); !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, "SUMMARY:Renew passport\r\n") {
+		t.Errorf("Unexpected feed: %q", body)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/calendar/feed-token", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w = httptest.NewRecorder()
+	handler.RevokeFeedToken(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/calendar/feed.ics?token="+resp.Data.Token, nil)
+	w = httptest.NewRecorder()
+	handler.GetFeed(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after revoking, got %d", w.Code)
+	}
+}
+
+func TestCalendarHandler_RotateFeedTokenInvalidTimezone(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewCalendarHandler(services.NewCalendarFeedService(mocks.NewMockRepository(), logger), logger)
+
+	req := httptest.NewRequest("POST", "/api/calendar/feed-token", strings.NewReader(`{"timezone":"Nowhere/Special"}`))
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+	handler.RotateFeedToken(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// CronSecretHeader carries the shared secret on scheduler requests
+const CronSecretHeader = "X-Cron-Secret"
+
+// CronHandler lets an external scheduler trigger registered jobs
+type CronHandler struct {
+	registry *services.CronRegistry
+	secret   string
+	logger   *zap.Logger
+}
+
+// NewCronHandler creates a new cron handler. An empty secret rejects every request.
+func NewCronHandler(registry *services.CronRegistry, secret string, logger *zap.Logger) *CronHandler {
+	return &CronHandler{
+		registry: registry,
+		secret:   secret,
+		logger:   logger,
+	}
+}
+
+// RunJob runs a registered job and returns its result summary. Requests
+// must carry the shared secret in X-Cron-Secret. Failed or timed-out jobs
+// respond 500 so the scheduler records and retries them.
+// POST /api/internal/cron/{job}
+func (h *CronHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	provided := r.Header.Get(CronSecretHeader)
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(h.secret)) != 1 {
+		h.logger.Warn("Rejected cron request", zap.String("remoteAddr", r.RemoteAddr))
+		utils.RespondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := mux.Vars(r)["job"]
+	result, err := h.registry.Run(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownCronJob) {
+			utils.RespondJSON(w, models.ErrorResponse{
+				Error:   "Unknown cron job",
+				Details: map[string]interface{}{"jobs": h.registry.Names()},
+			}, http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to run cron job", zap.String("job", name), zap.Error(err))
+		utils.RespondError(w, "Failed to run cron job", http.StatusInternalServerError)
+		return
+	}
+
+	if result.Status != services.CronJobSucceeded {
+		utils.RespondJSON(w, models.ErrorResponse{
+			Error:   "Cron job " + result.Status,
+			Details: map[string]interface{}{"result": result},
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Cron job finished")
+}
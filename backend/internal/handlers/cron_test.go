@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestCronHandler_RunJob(t *testing.T) {
+	logger := zap.NewNop()
+	registry := services.NewCronRegistry(logger, config.CronConfig{})
+	runs := 0
+	registry.Register("retention", func(ctx context.Context) (map[string]interface{}, error) {
+		runs++
+		return map[string]interface{}{"deleted": 2}, nil
+	})
+	registry.Register("broken", func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		job        string
+		wantStatus int
+		wantRuns   int
+	}{
+		{name: "missing secret header", secret: "s3cret", job: "retention", wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret", secret: "s3cret", header: "nope", job: "retention", wantStatus: http.StatusUnauthorized},
+		{name: "endpoint without configured secret", header: "", job: "retention", wantStatus: http.StatusUnauthorized},
+		{name: "unknown job", secret: "s3cret", header: "s3cret", job: "backup-all", wantStatus: http.StatusNotFound},
+		{name: "failing job", secret: "s3cret", header: "s3cret", job: "broken", wantStatus: http.StatusInternalServerError},
+		{name: "dispatches job", secret: "s3cret", header: "s3cret", job: "retention", wantStatus: http.StatusOK, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs = 0
+			handler := NewCronHandler(registry, tt.secret, logger)
+
+			req := httptest.NewRequest("POST", "/api/internal/cron/"+tt.job, nil)
+			if tt.header != "" {
+				req.Header.Set(CronSecretHeader, tt.header)
+			}
+			req = mux.SetURLVars(req, map[string]string{"job": tt.job})
+			w := httptest.NewRecorder()
+
+			handler.RunJob(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("Expected %d runs, got %d", tt.wantRuns, runs)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// CSVMappingHandler handles per-bank CSV mapping profiles
+type CSVMappingHandler struct {
+	csvMappingService *services.CSVMappingService
+	logger            *zap.Logger
+}
+
+// NewCSVMappingHandler creates a new CSV mapping handler
+func NewCSVMappingHandler(csvMappingService *services.CSVMappingService, logger *zap.Logger) *CSVMappingHandler {
+	return &CSVMappingHandler{
+		csvMappingService: csvMappingService,
+		logger:            logger,
+	}
+}
+
+// CSVPreviewRequest is the body of a preview request. Mapping, when set, is
+// used unsaved; otherwise MappingID or the best matching saved mapping is.
+type CSVPreviewRequest struct {
+	Content   string             `json:"content"`
+	MappingID string             `json:"mappingId,omitempty"`
+	Mapping   *models.CSVMapping `json:"mapping,omitempty"`
+	Rows      int                `json:"rows,omitempty"`
+}
+
+// ListMappings returns the current user's CSV mappings
+// GET /api/csv-mappings
+func (h *CSVMappingHandler) ListMappings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	mappings, err := h.csvMappingService.ListMappings(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to list CSV mappings", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list CSV mappings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": mappings,
+		"count": len(mappings),
+	}, "CSV mappings retrieved")
+}
+
+// CreateMapping saves a CSV mapping for the current user
+// POST /api/csv-mappings
+func (h *CSVMappingHandler) CreateMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req models.CSVMapping
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := h.csvMappingService.CreateMapping(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCSVMapping) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create CSV mapping", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create CSV mapping", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, mapping, "CSV mapping created")
+}
+
+// UpdateMapping replaces one of the current user's CSV mappings
+// PUT /api/csv-mappings/{id}
+func (h *CSVMappingHandler) UpdateMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	var req models.CSVMapping
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := h.csvMappingService.UpdateMapping(ctx, uid, id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCSVMapping) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if writeRepositoryError(w, err, "CSV mapping not found") {
+			return
+		}
+		h.logger.Error("Failed to update CSV mapping",
+			zap.String("uid", uid),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to update CSV mapping", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, mapping, "CSV mapping updated")
+}
+
+// DeleteMapping removes one of the current user's CSV mappings
+// DELETE /api/csv-mappings/{id}
+func (h *CSVMappingHandler) DeleteMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	if err := h.csvMappingService.DeleteMapping(ctx, uid, id); err != nil {
+		if writeRepositoryError(w, err, "CSV mapping not found") {
+			return
+		}
+		h.logger.Error("Failed to delete CSV mapping",
+			zap.String("uid", uid),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to delete CSV mapping", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{"id": id}, "CSV mapping deleted")
+}
+
+// PreviewMapping parses the first rows of a statement so a mapping can be
+// checked before import
+// POST /api/csv-mappings/preview
+func (h *CSVMappingHandler) PreviewMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req CSVPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Content == "" {
+		utils.RespondError(w, "content is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Content) > services.MaxCSVPreviewBytes {
+		utils.RespondError(w, "content is too large to preview", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	preview, err := h.csvMappingService.Preview(ctx, uid, req.Content, req.MappingID, req.Mapping, req.Rows)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCSVMapping) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if writeRepositoryError(w, err, "CSV mapping not found") {
+			return
+		}
+		h.logger.Error("Failed to preview CSV", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to preview CSV", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, preview, "CSV preview generated")
+}
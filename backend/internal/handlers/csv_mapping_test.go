@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestCSVMappingHandler(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewCSVMappingHandler(services.NewCSVMappingService(mocks.NewMockRepository(), logger), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/csv-mappings", handler.ListMappings).Methods("GET")
+	router.HandleFunc("/api/csv-mappings", handler.CreateMapping).Methods("POST")
+	router.HandleFunc("/api/csv-mappings/preview", handler.PreviewMapping).Methods("POST")
+	router.HandleFunc("/api/csv-mappings/{id}", handler.UpdateMapping).Methods("PUT")
+	router.HandleFunc("/api/csv-mappings/{id}", handler.DeleteMapping).Methods("DELETE")
+
+	chase := `{"name": "Chase", "dateColumn": "Transaction Date", "descriptionColumn": "Description", "amountColumn": "Amount", "dateFormat": "MM/DD/YYYY", "amountSignConvention": "debit-negative"}`
+	content := `"Transaction Date,Post Date,Description,Category,Type,Amount,Memo\n01/15/2024,01/16/2024,STARBUCKS,Food,Sale,-5.75,\n"`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", "POST", "/api/csv-mappings", chase, http.StatusOK},
+		{"create - missing name", "POST", "/api/csv-mappings", `{"dateColumn": "date"}`, http.StatusBadRequest},
+		{"create - bad sign convention", "POST", "/api/csv-mappings", `{"name": "Bank", "amountSignConvention": "sideways"}`, http.StatusBadRequest},
+		{"create - invalid json", "POST", "/api/csv-mappings", `{`, http.StatusBadRequest},
+		{"list", "GET", "/api/csv-mappings", "", http.StatusOK},
+		{"preview - matched", "POST", "/api/csv-mappings/preview", `{"content": ` + content + `}`, http.StatusOK},
+		{"preview - inline mapping", "POST", "/api/csv-mappings/preview", `{"content": ` + content + `, "mapping": ` + chase + `}`, http.StatusOK},
+		{"preview - unknown mapping", "POST", "/api/csv-mappings/preview", `{"content": ` + content + `, "mappingId": "missing"}`, http.StatusNotFound},
+		{"preview - missing content", "POST", "/api/csv-mappings/preview", `{}`, http.StatusBadRequest},
+		{"update - unknown", "PUT", "/api/csv-mappings/missing", chase, http.StatusNotFound},
+		{"delete - unknown", "DELETE", "/api/csv-mappings/missing", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
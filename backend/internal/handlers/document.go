@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// DocumentHandler handles requests shared by user document collections
+type DocumentHandler struct {
+	documentService *services.DocumentService
+	logger          *zap.Logger
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documentService *services.DocumentService, logger *zap.Logger) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+		logger:          logger,
+	}
+}
+
+// List returns documents from a collection. orderBy and orderDir accept
+// comma-separated values, e.g. ?orderBy=priority,dueDate&orderDir=desc,asc.
+// limit is clamped to the collection's configured max page size, and fields
+// (e.g. ?fields=text,createdAt) trims each document to those fields plus id.
+// where (repeatable, e.g. ?where=status==active) filters on a top-level field
+// with == or !=. Collections with default filters, such as hiding archived
+// items, list everything with ?includeArchived=true; a where on the same field
+// replaces the default.
+// GET /api/{collection}
+func (h *DocumentHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	collection := mux.Vars(r)["collection"]
+	query := r.URL.Query()
+
+	orderings, err := repository.ParseOrderings(query.Get("orderBy"), query.Get("orderDir"))
+	if err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fields := parseFieldsParam(query.Get("fields"))
+	if err := h.documentService.ValidateFields(collection, fields); err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			utils.RespondError(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	filters := services.DocumentListFilters{}
+	filters.Where, err = parseWhereParams(query["where"])
+	if err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	whereFields := make([]string, len(filters.Where))
+	for i, f := range filters.Where {
+		whereFields[i] = f.Field
+	}
+	if err := h.documentService.ValidateFields(collection, whereFields); err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if raw := query.Get("includeArchived"); raw != "" {
+		filters.IncludeArchived, err = strconv.ParseBool(raw)
+		if err != nil {
+			utils.RespondError(w, "includeArchived must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	docs, err := h.documentService.List(ctx, uid, collection, orderings, filters, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCollection):
+			utils.RespondError(w, "Collection does not support listing", http.StatusBadRequest)
+		case errors.Is(err, repository.ErrInvalidOrdering), errors.Is(err, repository.ErrIndexRequired):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.logger.Error("Failed to list documents",
+				zap.String("uid", uid),
+				zap.String("collection", collection),
+				zap.Error(err),
+			)
+			utils.RespondError(w, "Failed to list documents", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	for i, doc := range docs {
+		docs[i] = services.ProjectDocument(doc, fields)
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": docs,
+		"count": len(docs),
+	}, "Documents retrieved")
+}
+
+// Get returns a single document, optionally trimmed to ?fields= plus id
+// GET /api/{collection}/{id}
+func (h *DocumentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+	collection := vars["collection"]
+	id := vars["id"]
+
+	fields := parseFieldsParam(r.URL.Query().Get("fields"))
+	if err := h.documentService.ValidateFields(collection, fields); err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.documentService.Get(ctx, uid, collection, id)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCollection) {
+			utils.RespondError(w, "Collection does not support reads", http.StatusBadRequest)
+			return
+		}
+		if writeRepositoryError(w, err, "Document not found") {
+			return
+		}
+		h.logger.Error("Failed to get document",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to get document", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, services.ProjectDocument(doc, fields), "Document retrieved")
+}
+
+// History returns a document's change log, newest first. Each entry has
+// changedAt, updatedBy, the new version and a field-level diff of the update.
+// GET /api/{collection}/{id}/history
+func (h *DocumentHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+	collection := vars["collection"]
+	id := vars["id"]
+
+	entries, err := h.documentService.History(ctx, uid, collection, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCollection), errors.Is(err, services.ErrHistoryDisabled):
+			utils.RespondError(w, "Collection does not keep history", http.StatusBadRequest)
+		default:
+			if writeRepositoryError(w, err, "Document not found") {
+				return
+			}
+			h.logger.Error("Failed to get document history",
+				zap.String("uid", uid),
+				zap.String("collection", collection),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+			utils.RespondError(w, "Failed to get document history", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": entries,
+		"count": len(entries),
+	}, "Document history retrieved")
+}
+
+// Schema returns a collection's configured schema: its known fields in order,
+// which are read-only, page sizes and whether history is kept
+// GET /api/collections/{name}/schema
+func (h *DocumentHandler) Schema(w http.ResponseWriter, r *http.Request) {
+	collection := mux.Vars(r)["name"]
+
+	schema, err := h.documentService.Schema(collection)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCollection) {
+			utils.RespondError(w, "Unknown collection", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to build collection schema",
+			zap.String("collection", collection),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to build collection schema", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, schema, "Collection schema retrieved")
+}
+
+// parseFieldsParam splits a comma-separated fields parameter, dropping blanks
+func parseFieldsParam(raw string) []string {
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseWhereParams parses field==value and field!=value filters
+func parseWhereParams(raw []string) ([]repository.Filter, error) {
+	filters := make([]repository.Filter, 0, len(raw))
+	for _, clause := range raw {
+		op := "=="
+		idx := strings.Index(clause, "!=")
+		if idx >= 0 {
+			op = "!="
+		} else {
+			idx = strings.Index(clause, "==")
+		}
+		if idx <= 0 {
+			return nil, fmt.Errorf("where must be field==value or field!=value, got %q", clause)
+		}
+		filters = append(filters, repository.Filter{
+			Field: strings.TrimSpace(clause[:idx]),
+			Op:    op,
+			Value: strings.TrimSpace(clause[idx+2:]),
+		})
+	}
+	return filters, nil
+}
+
+const (
+	// jsonPatchContentType is the media type for RFC 6902 patch documents
+	jsonPatchContentType = "application/json-patch+json"
+	// maxPatchBodyBytes caps the size of a JSON Patch request body
+	maxPatchBodyBytes = 1 << 20
+)
+
+// Patch applies an RFC 6902 JSON Patch to a document. Requests must use
+// Content-Type: application/json-patch+json. A failing "test" op returns 409.
+// PUT /api/{collection}/{id}
+func (h *DocumentHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+	collection := vars["collection"]
+	id := vars["id"]
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != jsonPatchContentType {
+		utils.RespondError(w, "Content-Type must be "+jsonPatchContentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPatchBodyBytes+1))
+	if err != nil || len(body) > maxPatchBodyBytes {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ops, err := services.DecodeJSONPatch(body)
+	if err != nil {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.documentService.Patch(ctx, uid, collection, id, ops)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCollection):
+			utils.RespondError(w, "Collection does not support patching", http.StatusBadRequest)
+		case errors.Is(err, services.ErrInvalidPatch), errors.Is(err, services.ErrImmutableField):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrProtectedField):
+			utils.RespondError(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, services.ErrPatchTestFailed):
+			utils.RespondError(w, err.Error(), http.StatusConflict)
+		default:
+			if writeRepositoryError(w, err, "Document not found") {
+				return
+			}
+			h.logger.Error("Failed to patch document",
+				zap.String("uid", uid),
+				zap.String("collection", collection),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+			utils.RespondError(w, "Failed to patch document", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, doc, "Document patched")
+}
+
+// BatchDelete deletes up to 500 documents, given as {"ids": [...]}, and the
+// storage objects of storage-backed collections (photoLibrary and task or
+// thought attachments). Individual failures do not stop the batch and are
+// reported as docErrors or fileErrors.
+// POST /api/{collection}/batch-delete
+func (h *DocumentHandler) BatchDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	collection := mux.Vars(r)["collection"]
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.documentService.BatchDelete(ctx, uid, collection, req.IDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCollection):
+			utils.RespondError(w, "Collection does not support batch deletes", http.StatusBadRequest)
+		case errors.Is(err, services.ErrInvalidBatchDelete):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.logger.Error("Failed to batch delete documents",
+				zap.String("uid", uid),
+				zap.String("collection", collection),
+				zap.Error(err),
+			)
+			utils.RespondError(w, "Failed to batch delete documents", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Batch delete finished")
+}
+
+// Duplicate copies a document, applying any override fields from the request body
+// POST /api/{collection}/{id}/duplicate
+func (h *DocumentHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	vars := mux.Vars(r)
+	collection := vars["collection"]
+	id := vars["id"]
+
+	if collection == "" || id == "" {
+		utils.RespondError(w, "collection and id are required", http.StatusBadRequest)
+		return
+	}
+
+	overrides := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	duplicate, err := h.documentService.Duplicate(ctx, uid, collection, id, overrides)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCollection) {
+			utils.RespondError(w, "Collection does not support duplication", http.StatusBadRequest)
+			return
+		}
+		if writeRepositoryError(w, err, "Document not found") {
+			return
+		}
+		h.logger.Error("Failed to duplicate document",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to duplicate document", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, duplicate, "Document duplicated")
+}
@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestDocumentHandler_Duplicate(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/task1", map[string]interface{}{
+		"id":    "task1",
+		"title": "Original",
+	})
+	logger := zap.NewNop()
+	handler := NewDocumentHandler(services.NewDocumentService(mockRepo, logger, nil, nil, nil, nil), logger)
+
+	tests := []struct {
+		name       string
+		collection string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{name: "override title", collection: "tasks", id: "task1", body: `{"title": "Copy"}`, wantStatus: http.StatusOK},
+		{name: "empty body", collection: "tasks", id: "task1", body: "", wantStatus: http.StatusOK},
+		{name: "invalid json", collection: "tasks", id: "task1", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "unsupported collection", collection: "usageStats", id: "task1", body: "{}", wantStatus: http.StatusBadRequest},
+		{name: "missing document", collection: "tasks", id: "missing", body: "{}", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/"+tt.collection+"/"+tt.id+"/duplicate", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"collection": tt.collection, "id": tt.id})
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			handler.Duplicate(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.name == "override title" {
+				var resp struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Data["title"] != "Copy" || resp.Data["id"] == "task1" {
+					t.Errorf("Unexpected duplicate: %v", resp.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentHandler_List(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/a", map[string]interface{}{"priority": 1, "dueDate": "2024-03-02"})
+	mockRepo.AddDocument("users/test-user-123/tasks/b", map[string]interface{}{"priority": 2, "dueDate": "2024-03-03"})
+	mockRepo.AddDocument("users/test-user-123/tasks/c", map[string]interface{}{"priority": 2, "dueDate": "2024-03-01"})
+	mockRepo.AddDocument("users/test-user-123/tasks/d", map[string]interface{}{"priority": 3, "dueDate": "2024-03-04", "status": "archived"})
+	logger := zap.NewNop()
+	handler := NewDocumentHandler(services.NewDocumentService(mockRepo, logger, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"tasks": {DefaultFilters: []config.DefaultFilter{{Field: "status", Op: "!=", Value: "archived"}}},
+		},
+	}, nil, nil, nil), logger)
+
+	tests := []struct {
+		name       string
+		collection string
+		query      string
+		wantStatus int
+		wantIDs    []interface{}
+	}{
+		{
+			name:       "two field ordering",
+			collection: "tasks",
+			query:      "orderBy=priority,dueDate&orderDir=desc,asc",
+			wantStatus: http.StatusOK,
+			wantIDs:    []interface{}{"c", "b", "a"},
+		},
+		{
+			name:       "archived hidden by default",
+			collection: "tasks",
+			query:      "orderBy=dueDate",
+			wantStatus: http.StatusOK,
+			wantIDs:    []interface{}{"c", "a", "b"},
+		},
+		{
+			name:       "include archived",
+			collection: "tasks",
+			query:      "orderBy=dueDate&includeArchived=true",
+			wantStatus: http.StatusOK,
+			wantIDs:    []interface{}{"c", "a", "b", "d"},
+		},
+		{
+			name:       "where overrides default filter",
+			collection: "tasks",
+			query:      "orderBy=dueDate&where=status==archived",
+			wantStatus: http.StatusOK,
+			wantIDs:    []interface{}{"d"},
+		},
+		{name: "invalid where", collection: "tasks", query: "where=status", wantStatus: http.StatusBadRequest},
+		{name: "invalid includeArchived", collection: "tasks", query: "includeArchived=maybe", wantStatus: http.StatusBadRequest},
+		{name: "invalid direction", collection: "tasks", query: "orderBy=priority&orderDir=sideways", wantStatus: http.StatusBadRequest},
+		{name: "too many fields", collection: "tasks", query: "orderBy=a,b,c,d", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", collection: "tasks", query: "limit=abc", wantStatus: http.StatusBadRequest},
+		{name: "unsupported collection", collection: "usageStats", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/"+tt.collection+"?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"collection": tt.collection})
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			handler.List(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantIDs == nil {
+				return
+			}
+			var resp struct {
+				Data struct {
+					Items []map[string]interface{} `json:"items"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var ids []interface{}
+			for _, item := range resp.Data.Items {
+				ids = append(ids, item["id"])
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("Expected %v, got %v", tt.wantIDs, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("Expected %v, got %v", tt.wantIDs, ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentHandler_PatchValidation(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewDocumentHandler(services.NewDocumentService(mocks.NewMockRepository(), logger, nil, nil, nil, nil), logger)
+
+	tests := []struct {
+		name        string
+		contentType string
+		collection  string
+		body        string
+		wantStatus  int
+	}{
+		{name: "wrong content type", contentType: "application/json", collection: "tasks", body: `[]`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "empty patch", contentType: "application/json-patch+json", collection: "tasks", body: `[]`, wantStatus: http.StatusBadRequest},
+		{name: "malformed patch", contentType: "application/json-patch+json", collection: "tasks", body: `{`, wantStatus: http.StatusBadRequest},
+		{
+			name:        "unsupported collection",
+			contentType: "application/json-patch+json; charset=utf-8",
+			collection:  "usageStats",
+			body:        `[{"op": "replace", "path": "/title", "value": "x"}]`,
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/"+tt.collection+"/doc1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = mux.SetURLVars(req, map[string]string{"collection": tt.collection, "id": "doc1"})
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.Patch(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDocumentHandler_PatchImmutableFieldStrict(t *testing.T) {
+	logger := zap.NewNop()
+	svc := services.NewDocumentService(mocks.NewMockRepository(), logger, &config.DocumentsConfig{
+		StrictImmutableFields: true,
+		Collections: map[string]config.CollectionConfig{
+			"transactions": {ImmutableFields: []string{"uid", "plaidTransactionId"}},
+		},
+	}, nil, nil, nil)
+	handler := NewDocumentHandler(svc, logger)
+
+	body := `[{"op": "replace", "path": "/plaidTransactionId", "value": "forged"}]`
+	req := httptest.NewRequest("PUT", "/api/transactions/txn1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	req = mux.SetURLVars(req, map[string]string{"collection": "transactions", "id": "txn1"})
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+
+	handler.Patch(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "plaidTransactionId") {
+		t.Errorf("Expected error to name the field, got %s", w.Body.String())
+	}
+}
+
+func TestDocumentHandler_SparseFieldsets(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/thoughts/th1", map[string]interface{}{
+		"text":       "Plan the offsite",
+		"createdAt":  "2024-03-01T09:00:00Z",
+		"tags":       []interface{}{"work"},
+		"aiMetadata": map[string]interface{}{"model": "gpt-4o", "tokensUsed": 1200},
+	})
+	logger := zap.NewNop()
+	svc := services.NewDocumentService(mockRepo, logger, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"thoughts": {Fields: []string{"text", "tags", "createdAt", "aiMetadata"}},
+		},
+	}, nil, nil, nil)
+	handler := NewDocumentHandler(svc, logger)
+
+	tests := []struct {
+		name       string
+		get        bool
+		query      string
+		wantStatus int
+		wantKeys   []string
+	}{
+		{name: "list projected", query: "fields=text", wantStatus: http.StatusOK, wantKeys: []string{"id", "text"}},
+		{name: "list full", wantStatus: http.StatusOK, wantKeys: []string{"id", "text", "createdAt", "tags", "aiMetadata"}},
+		{name: "list unknown field", query: "fields=text,bogus", wantStatus: http.StatusBadRequest},
+		{name: "get projected", get: true, query: "fields=text, tags", wantStatus: http.StatusOK, wantKeys: []string{"id", "text", "tags"}},
+		{name: "get full", get: true, wantStatus: http.StatusOK, wantKeys: []string{"id", "text", "createdAt", "tags", "aiMetadata"}},
+		{name: "get unknown field", get: true, query: "fields=bogus", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{"collection": "thoughts"}
+			url := "/api/thoughts?" + tt.query
+			if tt.get {
+				vars["id"] = "th1"
+				url = "/api/thoughts/th1?" + tt.query
+			}
+			req := httptest.NewRequest("GET", strings.ReplaceAll(url, " ", "%20"), nil)
+			req = mux.SetURLVars(req, vars)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			if tt.get {
+				handler.Get(w, req)
+			} else {
+				handler.List(w, req)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantKeys == nil {
+				return
+			}
+
+			var doc map[string]interface{}
+			if tt.get {
+				var resp struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				doc = resp.Data
+			} else {
+				var resp struct {
+					Data struct {
+						Items []map[string]interface{} `json:"items"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(resp.Data.Items) != 1 {
+					t.Fatalf("Expected 1 item, got %d", len(resp.Data.Items))
+				}
+				doc = resp.Data.Items[0]
+			}
+
+			if len(doc) != len(tt.wantKeys) {
+				t.Errorf("Expected keys %v, got %v", tt.wantKeys, doc)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := doc[key]; !ok {
+					t.Errorf("Expected key %q in %v", key, doc)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentHandler_GetNotFound(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewDocumentHandler(services.NewDocumentService(mocks.NewMockRepository(), logger, nil, nil, nil, nil), logger)
+
+	req := httptest.NewRequest("GET", "/api/tasks/missing", nil)
+	req = mux.SetURLVars(req, map[string]string{"collection": "tasks", "id": "missing"})
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDocumentHandler_Schema(t *testing.T) {
+	logger := zap.NewNop()
+	svc := services.NewDocumentService(mocks.NewMockRepository(), logger, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"tasks": {ImmutableFields: []string{"attachments"}},
+		},
+	}, nil, nil, nil)
+	handler := NewDocumentHandler(svc, logger)
+
+	tests := []struct {
+		name       string
+		collection string
+		wantStatus int
+	}{
+		{name: "known collection", collection: "tasks", wantStatus: http.StatusOK},
+		{name: "unknown collection", collection: "subscriptions", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/collections/"+tt.collection+"/schema", nil)
+			req = mux.SetURLVars(req, map[string]string{"name": tt.collection})
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.Schema(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data services.CollectionSchema `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !resp.Data.Properties["attachments"].Immutable {
+				t.Errorf("Expected attachments to be immutable, got %+v", resp.Data.Properties)
+			}
+			if !resp.Data.Properties["version"].ServerManaged {
+				t.Errorf("Expected version to be server managed, got %+v", resp.Data.Properties)
+			}
+		})
+	}
+}
@@ -200,6 +200,27 @@ func (h *EntityGraphHandler) GetRelationshipStats(w http.ResponseWriter, r *http
 	utils.RespondSuccess(w, stats, "Relationship stats retrieved")
 }
 
+// BackfillRelationships fills missing defaults and normalizes legacy
+// relationship types on the user's relationships. Safe to call repeatedly.
+// POST /api/entity-graph/backfill
+func (h *EntityGraphHandler) BackfillRelationships(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	h.logger.Debug("BackfillRelationships request",
+		zap.String("uid", uid),
+	)
+
+	result, err := h.svc.BackfillRelationships(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to backfill relationships", zap.Error(err))
+		utils.RespondError(w, "Failed to backfill relationships", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Relationships backfilled")
+}
+
 // Helper to parse integer from string
 func parseInt(s string, defaultVal int) int {
 	if s == "" {
@@ -20,7 +20,7 @@ func TestEntityGraphHandler_QueryRelationships(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger)
+	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger, 0)
 	handler := NewEntityGraphHandler(entityGraphSvc, logger)
 
 	uid := "test-user-123"
@@ -104,7 +104,7 @@ func TestEntityGraphHandler_GetLinkedEntities(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger)
+	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger, 0)
 	handler := NewEntityGraphHandler(entityGraphSvc, logger)
 
 	uid := "test-user-123"
@@ -189,7 +189,7 @@ func TestEntityGraphHandler_GetToolRelationships(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger)
+	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger, 0)
 	handler := NewEntityGraphHandler(entityGraphSvc, logger)
 
 	uid := "test-user-123"
@@ -260,7 +260,7 @@ func TestEntityGraphHandler_GetRelationshipStats(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger)
+	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger, 0)
 	handler := NewEntityGraphHandler(entityGraphSvc, logger)
 
 	uid := "test-user-123"
@@ -306,3 +306,47 @@ func TestEntityGraphHandler_GetRelationshipStats(t *testing.T) {
 		t.Error("Expected 'data' field in response")
 	}
 }
+
+func TestEntityGraphHandler_BackfillRelationships(t *testing.T) {
+	// Setup
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+
+	entityGraphSvc := services.NewEntityGraphService(mockRepo, logger, 0)
+	handler := NewEntityGraphHandler(entityGraphSvc, logger)
+
+	uid := "test-user-123"
+
+	// Add a legacy relationship
+	mockRepo.AddDocument("entityRelationships/rel1", map[string]interface{}{
+		"id":               "rel1",
+		"uid":              uid,
+		"relationshipType": "linked_to",
+	})
+
+	// Create request
+	req := httptest.NewRequest("POST", "/api/entity-graph/backfill", nil)
+	ctx := context.WithValue(req.Context(), "uid", uid)
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	handler.BackfillRelationships(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data services.BackfillResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Data.Scanned != 1 || response.Data.Updated != 1 {
+		t.Errorf("Expected 1 scanned and 1 updated, got %+v", response.Data)
+	}
+	if mockRepo.Documents["entityRelationships/rel1"]["relationshipType"] != "linked-to" {
+		t.Errorf("Expected relationship type normalized, got %v", mockRepo.Documents["entityRelationships/rel1"]["relationshipType"])
+	}
+}
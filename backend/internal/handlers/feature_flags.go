@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// FeatureFlagHandler handles feature flag requests
+type FeatureFlagHandler struct {
+	featureFlagService *services.FeatureFlagService
+	logger             *zap.Logger
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(featureFlagService *services.FeatureFlagService, logger *zap.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+		logger:             logger,
+	}
+}
+
+// GetFeatures returns the feature flags resolved for the current user
+// GET /api/features
+func (h *FeatureFlagHandler) GetFeatures(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"flags": h.featureFlagService.Resolve(ctx, uid),
+	}, "Feature flags retrieved")
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// FinanceDashboardHandler handles the consolidated finance summary
+type FinanceDashboardHandler struct {
+	dashboardService *services.FinanceDashboardService
+	logger           *zap.Logger
+}
+
+// NewFinanceDashboardHandler creates a new finance dashboard handler
+func NewFinanceDashboardHandler(dashboardService *services.FinanceDashboardService, logger *zap.Logger) *FinanceDashboardHandler {
+	return &FinanceDashboardHandler{
+		dashboardService: dashboardService,
+		logger:           logger,
+	}
+}
+
+// GetDashboard returns this month's cash flow and top spending categories,
+// net worth with its trend, spending goal budgets and the portfolio summary
+// in one response, in the user's preferred currency
+// GET /api/finance/dashboard
+func (h *FinanceDashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	dashboard, err := h.dashboardService.GetDashboard(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to build finance dashboard", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to build finance dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, dashboard, "Finance dashboard retrieved")
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// FocusSessionHandler handles focus session requests
+type FocusSessionHandler struct {
+	focusSessionService *services.FocusSessionService
+	logger              *zap.Logger
+}
+
+// NewFocusSessionHandler creates a new focus session handler
+func NewFocusSessionHandler(focusSessionService *services.FocusSessionService, logger *zap.Logger) *FocusSessionHandler {
+	return &FocusSessionHandler{
+		focusSessionService: focusSessionService,
+		logger:              logger,
+	}
+}
+
+// Start starts a new focus session
+// POST /api/focus-sessions/start
+func (h *FocusSessionHandler) Start(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.StartFocusSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.focusSessionService.Start(ctx, uid, req)
+	if err != nil {
+		h.writeError(w, "Failed to start focus session", err)
+		return
+	}
+
+	utils.RespondSuccess(w, session, "Focus session started")
+}
+
+// StartFromTemplate starts a new focus session from one of the user's
+// session templates
+// POST /api/focus-sessions/from-template/{templateId}
+func (h *FocusSessionHandler) StartFromTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	session, err := h.focusSessionService.StartFromTemplate(ctx, uid, mux.Vars(r)["templateId"])
+	if err != nil {
+		h.writeError(w, "Failed to start focus session from template", err)
+		return
+	}
+
+	utils.RespondSuccess(w, session, "Focus session started")
+}
+
+// Complete completes an active focus session
+// POST /api/focus-sessions/complete
+func (h *FocusSessionHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.CompleteFocusSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Non-numeric timeSpent values are rejected here
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.focusSessionService.Complete(ctx, uid, req)
+	if err != nil {
+		h.writeError(w, "Failed to complete focus session", err)
+		return
+	}
+
+	utils.RespondSuccess(w, session, "Focus session completed")
+}
+
+// writeError maps focus session errors to HTTP responses
+func (h *FocusSessionHandler) writeError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidFocusSession):
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrFocusSessionCompleted):
+		utils.RespondError(w, err.Error(), http.StatusConflict)
+	case writeRepositoryError(w, err, "Focus session not found"):
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.RespondError(w, message, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestFocusSessionHandler(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewFocusSessionHandler(services.NewFocusSessionService(mockRepo, logger, nil), logger)
+	mockRepo.AddDocument("users/test-user-123/sessionTemplates/pomodoro", map[string]interface{}{
+		"duration": 25.0,
+		"tasks":    []interface{}{map[string]interface{}{"id": "task1"}},
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/focus-sessions/start", handler.Start).Methods("POST")
+	router.HandleFunc("/api/focus-sessions/from-template/{templateId}", handler.StartFromTemplate).Methods("POST")
+	router.HandleFunc("/api/focus-sessions/complete", handler.Complete).Methods("POST")
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "start - valid",
+			path:       "/api/focus-sessions/start",
+			body:       `{"duration": 25, "tasks": [{"id": "task1"}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "start - missing tasks",
+			path:       "/api/focus-sessions/start",
+			body:       `{"duration": 25}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "from template - valid",
+			path:       "/api/focus-sessions/from-template/pomodoro",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "from template - unknown template",
+			path:       "/api/focus-sessions/from-template/missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "complete - non-numeric timeSpent",
+			path:       "/api/focus-sessions/complete",
+			body:       `{"sessionId": "s1", "tasks": [{"taskId": "task1", "timeSpent": "ten"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "complete - unknown session",
+			path:       "/api/focus-sessions/complete",
+			body:       `{"sessionId": "missing"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
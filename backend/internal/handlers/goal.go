@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// GoalHandler handles goal tracking requests
+type GoalHandler struct {
+	goalService *services.GoalService
+	logger      *zap.Logger
+}
+
+// NewGoalHandler creates a new goal handler
+func NewGoalHandler(goalService *services.GoalService, logger *zap.Logger) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+		logger:      logger,
+	}
+}
+
+// AtRisk returns the active goals whose progress trails their target date
+// GET /api/goals/at-risk
+func (h *GoalHandler) AtRisk(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	result, err := h.goalService.AtRisk(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to compute goals at risk", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute goals at risk", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Goals at risk retrieved")
+}
+
+// FinancialProgress sums the transactions tagged with a financial goal
+// towards its target amount
+// GET /api/goals/{id}/financial-progress
+func (h *GoalHandler) FinancialProgress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	progress, err := h.goalService.FinancialProgress(ctx, uid, id)
+	if err != nil {
+		if h.writeGoalError(w, err) {
+			return
+		}
+		h.logger.Error("Failed to compute goal financial progress",
+			zap.String("uid", uid),
+			zap.String("goalId", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to compute goal financial progress", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, progress, "Goal financial progress retrieved")
+}
+
+// TagTransaction attributes a transaction to a financial goal, or removes
+// the tag when goalId is empty
+// PUT /api/transactions/{id}/goal
+func (h *GoalHandler) TagTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	var req struct {
+		GoalID string `json:"goalId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.goalService.TagTransaction(ctx, uid, id, req.GoalID); err != nil {
+		if h.writeGoalError(w, err) {
+			return
+		}
+		h.logger.Error("Failed to tag transaction",
+			zap.String("uid", uid),
+			zap.String("transactionId", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to tag transaction", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{"transactionId": id, "goalId": req.GoalID}, "Transaction goal updated")
+}
+
+// writeGoalError responds to the goal service's client errors, returning
+// false for anything else
+func (h *GoalHandler) writeGoalError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrGoalNotFound):
+		utils.RespondError(w, "Goal not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrTransactionNotFound):
+		utils.RespondError(w, "Transaction not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotFinancialGoal):
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+	default:
+		return false
+	}
+	return true
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
@@ -32,48 +34,89 @@ func (h *ImportExportHandler) ValidateImport(w http.ResponseWriter, r *http.Requ
 	ctx := r.Context()
 	uid := ctx.Value("uid").(string)
 
-	// Read uploaded file (max 50MB)
+	data, ok := h.readImportFile(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Debug("ValidateImport request",
+		zap.String("uid", uid),
+		zap.Int("fileSize", len(data)),
+	)
+
+	// Validate import
+	result, err := h.svc.ValidateImport(ctx, uid, data)
+	if err != nil {
+		h.logger.Error("Failed to validate import", zap.Error(err))
+		utils.RespondError(w, "Failed to validate import: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Import validation completed")
+}
+
+// ValidateForeignImport converts another app's export (source "todoist" for a
+// tasks CSV, "dayone" for a journal JSON) and validates it like ValidateImport.
+// The converted data in parsedData is then sent to ExecuteImport.
+// POST /api/import/adapter/{source}
+func (h *ImportExportHandler) ValidateForeignImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	source := mux.Vars(r)["source"]
+
+	data, ok := h.readImportFile(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.ValidateForeignImport(ctx, uid, source, data)
+	if errors.Is(err, services.ErrUnknownImportSource) {
+		utils.RespondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidForeignImport) {
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to validate foreign import", zap.String("source", source), zap.Error(err))
+		utils.RespondError(w, "Failed to validate import", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Import converted and validated")
+}
+
+// readImportFile reads the "file" field of a multipart upload (max 50MB),
+// responding with an error when it is missing or unreadable
+func (h *ImportExportHandler) readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
 	r.Body = http.MaxBytesReader(w, r.Body, 50*1024*1024)
 
 	// Parse multipart form
 	if err := r.ParseMultipartForm(50 * 1024 * 1024); err != nil {
 		utils.RespondError(w, "File too large or invalid multipart form", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	// Get file from form
 	file, _, err := r.FormFile("file")
 	if err != nil {
 		utils.RespondError(w, "No file provided", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 	defer func() { _ = file.Close() }()
 
-	// Read file contents
 	data, err := io.ReadAll(file)
 	if err != nil {
 		h.logger.Error("Failed to read file", zap.Error(err))
 		utils.RespondError(w, "Failed to read file", http.StatusInternalServerError)
-		return
-	}
-
-	h.logger.Debug("ValidateImport request",
-		zap.String("uid", uid),
-		zap.Int("fileSize", len(data)),
-	)
-
-	// Validate import
-	result, err := h.svc.ValidateImport(ctx, uid, data)
-	if err != nil {
-		h.logger.Error("Failed to validate import", zap.Error(err))
-		utils.RespondError(w, "Failed to validate import: "+err.Error(), http.StatusBadRequest)
-		return
+		return nil, false
 	}
-
-	utils.RespondSuccess(w, result, "Import validation completed")
+	return data, true
 }
 
-// ExecuteImport executes the import with the given options
+// ExecuteImport executes the import with the given options. options.replaceAll
+// restores a backup as a full replace and needs options.confirmReplaceAll.
 // POST /api/import/execute
 func (h *ImportExportHandler) ExecuteImport(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -94,10 +137,19 @@ func (h *ImportExportHandler) ExecuteImport(w http.ResponseWriter, r *http.Reque
 		zap.String("uid", uid),
 		zap.Int("totalItems", req.Data.Metadata.TotalItems),
 		zap.Bool("updateReferences", req.Options.UpdateReferences),
+		zap.Bool("replaceAll", req.Options.ReplaceAll),
 	)
 
 	// Execute import
 	result, err := h.svc.ExecuteImport(ctx, uid, &req.Data, req.Options)
+	if errors.Is(err, services.ErrReplaceNotConfirmed) {
+		utils.RespondError(w, "replaceAll deletes existing data and requires confirmReplaceAll", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrAnonymousQuotaExceeded) {
+		utils.RespondError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to execute import", zap.Error(err))
 		utils.RespondError(w, "Failed to execute import", http.StatusInternalServerError)
@@ -109,19 +161,42 @@ func (h *ImportExportHandler) ExecuteImport(w http.ResponseWriter, r *http.Reque
 		zap.Int("importedCount", result.ImportedCount),
 		zap.Int("skippedCount", result.SkippedCount),
 		zap.Int("errorCount", result.ErrorCount),
+		zap.Strings("errors", result.ErrorMessages()),
 	)
 
 	utils.RespondSuccess(w, result, "Import completed")
 }
 
-// ExportData exports user data with optional filters
+// GetImportJob returns the progress of a replaceAll import
+// GET /api/import/jobs/{jobId}
+func (h *ImportExportHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value("uid").(string)
+	jobID := mux.Vars(r)["jobId"]
+
+	job, err := h.svc.GetImportJob(r.Context(), uid, jobID)
+	if errors.Is(err, services.ErrImportJobNotFound) {
+		utils.RespondError(w, "Import job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get import job", zap.String("uid", uid), zap.String("jobId", jobID), zap.Error(err))
+		utils.RespondError(w, "Failed to get import job", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, job, "Import job retrieved")
+}
+
+// ExportData exports user data with optional filters. Exports over the
+// user's plan limit return 422 with a message suggesting narrower filters.
+// With consistent=true, all collections are read at the same point in time.
 // GET /api/export?entityTypes=tasks,projects&startDate=2024-01-01&endDate=2024-12-31
 func (h *ImportExportHandler) ExportData(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	uid := ctx.Value("uid").(string)
 
 	// Parse query parameters
-	filters := services.ExportFilters{}
+	filters := parseExportFilters(r)
 
 	// Parse entity types
 	if entityTypesStr := r.URL.Query().Get("entityTypes"); entityTypesStr != "" {
@@ -132,6 +207,161 @@ func (h *ImportExportHandler) ExportData(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	h.logger.Debug("ExportData request",
+		zap.String("uid", uid),
+		zap.Int("entityTypeCount", len(filters.EntityTypes)),
+	)
+
+	// Execute export
+	exportData, err := h.svc.ExportData(ctx, uid, filters)
+	if err != nil {
+		if errors.Is(err, services.ErrExportTooLarge) {
+			utils.RespondError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		h.logger.Error("Failed to export data", zap.Error(err))
+		utils.RespondError(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Export completed",
+		zap.String("uid", uid),
+		zap.Int("totalItems", exportData.Metadata.TotalItems),
+	)
+
+	// Set headers for file download
+	filename := "focus-notebook-export-" + time.Now().Format("2006-01-02") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	// Stream JSON response
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Pretty print
+	if err := encoder.Encode(exportData); err != nil {
+		h.logger.Error("Failed to encode export data", zap.Error(err))
+		return
+	}
+}
+
+// ExportCollection exports a single collection as a JSON array or CSV, with
+// the same date and status filters as ExportData. status applies to the
+// collection's own status field.
+// GET /api/export/{collection}?format=csv&startDate=2024-01-01&status=active
+func (h *ImportExportHandler) ExportCollection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	collection := mux.Vars(r)["collection"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		utils.RespondError(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	entityType, ok := services.ExportEntityTypeFor(collection)
+	if !ok {
+		utils.RespondError(w, "Unknown export collection: "+collection, http.StatusBadRequest)
+		return
+	}
+
+	filters := parseExportFilters(r)
+	if status := r.URL.Query().Get("status"); status != "" {
+		statuses := splitAndTrim(status, ",")
+		switch entityType {
+		case services.EntityTypeTasks:
+			filters.TaskStatus = statuses
+		case services.EntityTypeProjects:
+			filters.ProjectStatus = statuses
+		case services.EntityTypeGoals:
+			filters.GoalStatus = statuses
+		default:
+			utils.RespondError(w, "status filter is not supported for "+collection, http.StatusBadRequest)
+			return
+		}
+	}
+
+	docs, err := h.svc.ExportCollection(ctx, uid, collection, filters)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportTooLarge):
+			utils.RespondError(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, services.ErrUnsupportedCollection):
+			utils.RespondError(w, "Unknown export collection: "+collection, http.StatusBadRequest)
+		default:
+			h.logger.Error("Failed to export collection", zap.String("collection", collection), zap.Error(err))
+			utils.RespondError(w, "Failed to export data", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.logger.Info("Collection export completed",
+		zap.String("uid", uid),
+		zap.String("entityType", string(entityType)),
+		zap.Int("totalItems", len(docs)),
+	)
+
+	filename := "focus-notebook-" + string(entityType) + "-" + time.Now().Format("2006-01-02") + "." + format
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		if err := services.EncodeDocumentsCSV(w, docs); err != nil {
+			h.logger.Error("Failed to encode export CSV", zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Pretty print
+	if err := encoder.Encode(docs); err != nil {
+		h.logger.Error("Failed to encode export data", zap.Error(err))
+	}
+}
+
+// ExportTrip downloads a trip with its packing list, places and linked
+// transactions as one JSON file
+// GET /api/trips/{tripId}/export
+func (h *ImportExportHandler) ExportTrip(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	tripID := mux.Vars(r)["tripId"]
+
+	bundle, err := h.svc.ExportTrip(ctx, uid, tripID)
+	if err != nil {
+		if writeRepositoryError(w, err, "Trip not found") {
+			return
+		}
+		h.logger.Error("Failed to export trip", zap.String("uid", uid), zap.String("tripId", tripID), zap.Error(err))
+		utils.RespondError(w, "Failed to export trip", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Trip export completed",
+		zap.String("uid", uid),
+		zap.String("tripId", tripID),
+		zap.Int("totalItems", bundle.Metadata.TotalItems),
+	)
+
+	filename := "focus-notebook-trip-" + tripID + "-" + time.Now().Format("2006-01-02") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Pretty print
+	if err := encoder.Encode(bundle); err != nil {
+		h.logger.Error("Failed to encode trip export", zap.Error(err))
+	}
+}
+
+// parseExportFilters reads the date and per-entity filters shared by the
+// export endpoints
+func parseExportFilters(r *http.Request) services.ExportFilters {
+	filters := services.ExportFilters{}
+
 	// Parse dates
 	if startDateStr := r.URL.Query().Get("startDate"); startDateStr != "" {
 		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
@@ -165,36 +395,9 @@ func (h *ImportExportHandler) ExportData(w http.ResponseWriter, r *http.Request)
 		filters.GoalStatus = splitAndTrim(goalStatusStr, ",")
 	}
 
-	h.logger.Debug("ExportData request",
-		zap.String("uid", uid),
-		zap.Int("entityTypeCount", len(filters.EntityTypes)),
-	)
+	filters.Consistent = r.URL.Query().Get("consistent") == "true"
 
-	// Execute export
-	exportData, err := h.svc.ExportData(ctx, uid, filters)
-	if err != nil {
-		h.logger.Error("Failed to export data", zap.Error(err))
-		utils.RespondError(w, "Failed to export data", http.StatusInternalServerError)
-		return
-	}
-
-	h.logger.Info("Export completed",
-		zap.String("uid", uid),
-		zap.Int("totalItems", exportData.Metadata.TotalItems),
-	)
-
-	// Set headers for file download
-	filename := "focus-notebook-export-" + time.Now().Format("2006-01-02") + ".json"
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
-
-	// Stream JSON response
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ") // Pretty print
-	if err := encoder.Encode(exportData); err != nil {
-		h.logger.Error("Failed to encode export data", zap.Error(err))
-		return
-	}
+	return filters
 }
 
 // GetExportSummary returns export summary statistics
@@ -218,6 +421,39 @@ func (h *ImportExportHandler) GetExportSummary(w http.ResponseWriter, r *http.Re
 	utils.RespondSuccess(w, summary, "Export summary retrieved")
 }
 
+// RepairReferences finds references to deleted tasks, projects, goals and
+// thoughts. Dry run (the default) only reports them; with {"dryRun": false}
+// single references are nulled and dangling IDs removed from lists.
+// POST /api/maintenance/repair-references
+func (h *ImportExportHandler) RepairReferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req struct {
+		DryRun *bool `json:"dryRun"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	result, err := h.svc.RepairReferences(ctx, uid, dryRun)
+	if err != nil {
+		h.logger.Error("Failed to repair references", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to repair references", http.StatusInternalServerError)
+		return
+	}
+
+	message := "Broken references repaired"
+	if dryRun {
+		message = "Broken references found"
+	}
+	utils.RespondSuccess(w, result, message)
+}
+
 // splitAndTrim splits a string by delimiter and trims whitespace
 func splitAndTrim(s, delim string) []string {
 	parts := make([]string, 0)
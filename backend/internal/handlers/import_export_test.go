@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
 )
 
@@ -221,3 +228,122 @@ func TestTrimSpace_SingleCharacter(t *testing.T) {
 
 	assert.Equal(t, "a", result)
 }
+
+func TestImportExportHandler_RepairReferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantProject interface{}
+	}{
+		{name: "defaults to dry run", body: "", wantStatus: http.StatusOK, wantProject: "project-deleted"},
+		{name: "explicit dry run", body: `{"dryRun": true}`, wantStatus: http.StatusOK, wantProject: "project-deleted"},
+		{name: "repair", body: `{"dryRun": false}`, wantStatus: http.StatusOK, wantProject: nil},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest, wantProject: "project-deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockRepository()
+			repo.AddDocument("tasks/task-1", map[string]interface{}{
+				"id":        "task-1",
+				"uid":       "test-user-123",
+				"projectId": "project-deleted",
+			})
+			handler := NewImportExportHandler(services.NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil), zap.NewNop())
+			router := mux.NewRouter()
+			router.HandleFunc("/api/maintenance/repair-references", handler.RepairReferences).Methods("POST")
+
+			req := httptest.NewRequest("POST", "/api/maintenance/repair-references", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantProject, repo.Documents["tasks/task-1"]["projectId"])
+		})
+	}
+}
+
+func TestImportExportHandler_ReplaceImport(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/test-user-123/importJobs/job-1", map[string]interface{}{"id": "job-1", "status": "completed"})
+	handler := NewImportExportHandler(services.NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil), zap.NewNop())
+	router := mux.NewRouter()
+	router.HandleFunc("/api/import/execute", handler.ExecuteImport).Methods("POST")
+	router.HandleFunc("/api/import/jobs/{jobId}", handler.GetImportJob).Methods("GET")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "replaceAll without confirmation",
+			method:     "POST",
+			path:       "/api/import/execute",
+			body:       `{"data": {"entities": {"tasks": [{"id": "task-1"}]}}, "options": {"replaceAll": true}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "job found", method: "GET", path: "/api/import/jobs/job-1", wantStatus: http.StatusOK},
+		{name: "job not found", method: "GET", path: "/api/import/jobs/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestImportExportHandler_ExportTrip(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/test-user-123/trips/trip1", map[string]interface{}{"name": "Lisbon", "destination": "Lisbon"})
+	repo.AddDocument("users/test-user-123/trips/trip1/packingList/data", map[string]interface{}{
+		"tripId":        "trip1",
+		"packedItemIds": []interface{}{"passport"},
+	})
+	repo.AddDocument("users/test-user-123/places/lisbon", map[string]interface{}{"id": "lisbon", "name": "Lisbon"})
+	logger := zap.NewNop()
+	handler := NewImportExportHandler(services.NewImportExportService(repo, logger, 0, nil, 0, 0, nil), logger)
+
+	tests := []struct {
+		name       string
+		tripID     string
+		wantStatus int
+	}{
+		{name: "exports bundle", tripID: "trip1", wantStatus: http.StatusOK},
+		{name: "unknown trip", tripID: "missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/trips/"+tt.tripID+"/export", nil)
+			req = mux.SetURLVars(req, map[string]string{"tripId": tt.tripID})
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.ExportTrip(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Contains(t, w.Header().Get("Content-Disposition"), "focus-notebook-trip-trip1-")
+
+			var bundle services.TripBundle
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+			require.NotNil(t, bundle.PackingList)
+			assert.Equal(t, []string{"passport"}, bundle.PackingList.PackedItemIDs)
+			require.Len(t, bundle.Places, 1)
+			assert.Equal(t, "lisbon", bundle.Places[0]["id"])
+		})
+	}
+}
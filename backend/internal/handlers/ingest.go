@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// maxIngestBodyBytes caps the size of an incoming webhook payload
+const maxIngestBodyBytes = 64 * 1024
+
+// IngestHandler handles the incoming webhook and its tokens
+type IngestHandler struct {
+	ingestService *services.IngestService
+	logger        *zap.Logger
+}
+
+// NewIngestHandler creates a new ingest handler
+func NewIngestHandler(ingestService *services.IngestService, logger *zap.Logger) *IngestHandler {
+	return &IngestHandler{
+		ingestService: ingestService,
+		logger:        logger,
+	}
+}
+
+// Ingest creates a task, mood or thought for the owner of the token. It is
+// not behind auth; the token in the URL authenticates the integration.
+// POST /api/ingest/{token}
+func (h *IngestHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxIngestBodyBytes)
+
+	var payload services.IngestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.ingestService.Ingest(r.Context(), mux.Vars(r)["token"], payload)
+	switch {
+	case errors.Is(err, services.ErrInvalidIngestToken):
+		utils.RespondError(w, "Invalid ingest token", http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrIngestRateLimited):
+		utils.RespondError(w, "Too many requests for this token; try again in a minute", http.StatusTooManyRequests)
+		return
+	case errors.Is(err, services.ErrInvalidIngestPayload):
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		h.logger.Error("Failed to ingest document", zap.String("type", payload.Type), zap.Error(err))
+		utils.RespondError(w, "Failed to ingest document", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, result, "Document created")
+}
+
+// RotateToken issues a new ingest token, revoking the previous one
+// POST /api/ingest-token
+func (h *IngestHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	token, err := h.ingestService.RotateToken(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to issue ingest token", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to issue ingest token", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, token, "Ingest token issued")
+}
+
+// RevokeToken disables the user's incoming webhook
+// DELETE /api/ingest-token
+func (h *IngestHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	if err := h.ingestService.RevokeToken(ctx, uid); err != nil {
+		h.logger.Error("Failed to revoke ingest token", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to revoke ingest token", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, nil, "Ingest token revoked")
+}
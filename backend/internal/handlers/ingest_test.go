@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestIngestHandler_Ingest(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	documents := services.NewDocumentService(mockRepo, logger, nil, nil, nil, nil)
+	svc := services.NewIngestService(mockRepo, logger, documents, services.NewTaskService(mockRepo, logger, nil, nil, nil), services.NewMoodService(mockRepo, logger), 0)
+	handler := NewIngestHandler(svc, logger)
+
+	req := httptest.NewRequest("POST", "/api/ingest-token", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+	w := httptest.NewRecorder()
+	handler.RotateToken(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data services.IngestToken `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{"creates task", resp.Data.Token, `{"type":"task","data":{"title":"Water plants"}}`, http.StatusOK},
+		{"unknown token", "nope", `{"type":"task","data":{"title":"Water plants"}}`, http.StatusNotFound},
+		{"invalid json", resp.Data.Token, `{`, http.StatusBadRequest},
+		{"invalid payload", resp.Data.Token, `{"type":"mood","data":{"value":42}}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/ingest/"+tt.token, strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"token": tt.token})
+			w := httptest.NewRecorder()
+
+			handler.Ingest(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if len(mockRepo.GetCollectionDocuments("users/test-user-123/tasks")) != 1 {
+		t.Errorf("Expected one ingested task")
+	}
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -57,6 +60,73 @@ func (h *InvestmentHandler) GetPortfolioMetrics(w http.ResponseWriter, r *http.R
 	utils.RespondSuccess(w, metrics, "Portfolio metrics calculated")
 }
 
+// GetPortfolioAllocation returns the share of a portfolio's value by
+// assetClass (default), sector or currency, flagging holdings above the
+// concentration threshold percentage (default 25)
+// GET /api/portfolio/{portfolioId}/allocation?groupBy=sector&threshold=20
+func (h *InvestmentHandler) GetPortfolioAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	portfolioID := mux.Vars(r)["portfolioId"]
+
+	var threshold float64
+	if raw := r.URL.Query().Get("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			utils.RespondError(w, "threshold must be a number", http.StatusBadRequest)
+			return
+		}
+		threshold = parsed
+	}
+
+	allocation, err := h.svc.ComputeAllocation(ctx, uid, portfolioID, r.URL.Query().Get("groupBy"), threshold)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAllocationRequest):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrPortfolioNotFound):
+			utils.RespondError(w, "Portfolio not found", http.StatusNotFound)
+		default:
+			h.logger.Error("Failed to compute portfolio allocation", zap.String("uid", uid), zap.Error(err))
+			utils.RespondError(w, "Failed to compute allocation", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, allocation, "Portfolio allocation calculated")
+}
+
+// SuggestRebalance computes the trades that bring a portfolio to target
+// allocation percentages, optionally deploying new cash. Nothing is traded.
+// POST /api/portfolio/{portfolioId}/rebalance
+func (h *InvestmentHandler) SuggestRebalance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	portfolioID := mux.Vars(r)["portfolioId"]
+
+	var req services.RebalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.svc.SuggestRebalance(ctx, uid, portfolioID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRebalanceRequest), errors.Is(err, services.ErrInvalidAllocationRequest):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrPortfolioNotFound):
+			utils.RespondError(w, "Portfolio not found", http.StatusNotFound)
+		default:
+			h.logger.Error("Failed to compute rebalance", zap.String("uid", uid), zap.Error(err))
+			utils.RespondError(w, "Failed to compute rebalance", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, plan, "Rebalance suggestions calculated")
+}
+
 // GenerateProjection generates a compound interest projection
 // POST /api/portfolio/projection
 func (h *InvestmentHandler) GenerateProjection(w http.ResponseWriter, r *http.Request) {
@@ -95,11 +165,8 @@ func (h *InvestmentHandler) GetDashboardSummary(w http.ResponseWriter, r *http.R
 	ctx := r.Context()
 	uid := ctx.Value("uid").(string)
 
-	// Get base currency from query params (default to USD)
-	baseCurrency := r.URL.Query().Get("currency")
-	if baseCurrency == "" {
-		baseCurrency = "USD"
-	}
+	// Get base currency from query params (defaults to the user's preference)
+	baseCurrency := strings.ToUpper(r.URL.Query().Get("currency"))
 
 	h.logger.Debug("GetDashboardSummary request",
 		zap.String("uid", uid),
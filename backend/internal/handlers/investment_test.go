@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
 )
 
@@ -73,3 +78,37 @@ func TestNewInvestmentHandler_FieldAssignment(t *testing.T) {
 	assert.Equal(t, svc, handler.svc)
 	assert.Equal(t, logger, handler.logger)
 }
+
+func TestInvestmentHandler_GetPortfolioAllocation(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"id": "p1", "uid": "test-user-123"})
+	repo.AddDocument("investments/inv1", map[string]interface{}{
+		"id": "inv1", "uid": "test-user-123", "portfolioId": "p1", "assetClass": "equity", "currentValue": 100.0,
+	})
+	handler := NewInvestmentHandler(services.NewInvestmentCalculationService(repo, zap.NewNop(), 0, nil), zap.NewNop())
+	router := mux.NewRouter()
+	router.HandleFunc("/api/portfolio/{portfolioId}/allocation", handler.GetPortfolioAllocation).Methods("GET")
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "default grouping", path: "/api/portfolio/p1/allocation", wantStatus: http.StatusOK},
+		{name: "by sector with threshold", path: "/api/portfolio/p1/allocation?groupBy=sector&threshold=20", wantStatus: http.StatusOK},
+		{name: "unknown grouping", path: "/api/portfolio/p1/allocation?groupBy=color", wantStatus: http.StatusBadRequest},
+		{name: "invalid threshold", path: "/api/portfolio/p1/allocation?threshold=high", wantStatus: http.StatusBadRequest},
+		{name: "unknown portfolio", path: "/api/portfolio/missing/allocation", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
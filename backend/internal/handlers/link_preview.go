@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// LinkPreviewHandler handles link preview requests
+type LinkPreviewHandler struct {
+	linkPreviewService *services.LinkPreviewService
+	logger             *zap.Logger
+}
+
+// NewLinkPreviewHandler creates a new link preview handler
+func NewLinkPreviewHandler(linkPreviewService *services.LinkPreviewService, logger *zap.Logger) *LinkPreviewHandler {
+	return &LinkPreviewHandler{
+		linkPreviewService: linkPreviewService,
+		logger:             logger,
+	}
+}
+
+// Preview fetches the title, description, image and site name of a URL
+// POST /api/link-preview
+func (h *LinkPreviewHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := h.linkPreviewService.Preview(ctx, req.URL)
+	switch {
+	case errors.Is(err, services.ErrInvalidLinkPreviewURL), errors.Is(err, services.ErrLinkPreviewBlocked):
+		utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrLinkPreviewFetch):
+		h.logger.Info("Link preview fetch failed", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Could not fetch the link", http.StatusBadGateway)
+		return
+	case err != nil:
+		h.logger.Error("Failed to build link preview", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to build link preview", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, preview, "Link preview retrieved")
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestLinkPreviewHandler_Preview(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewLinkPreviewHandler(services.NewLinkPreviewService(logger), logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"missing url", `{}`, http.StatusBadRequest},
+		{"not http", `{"url": "file:///etc/passwd"}`, http.StatusBadRequest},
+		{"loopback", `{"url": "http://127.0.0.1:9/"}`, http.StatusBadRequest},
+		{"metadata server", `{"url": "http://169.254.169.254/latest/meta-data/"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/link-preview", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.Preview(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// LLMLogHandler handles the user's LLM log settings and AI usage reports
+type LLMLogHandler struct {
+	llmLogService *services.LLMLogService
+	logger        *zap.Logger
+}
+
+// NewLLMLogHandler creates a new LLM log handler
+func NewLLMLogHandler(llmLogService *services.LLMLogService, logger *zap.Logger) *LLMLogHandler {
+	return &LLMLogHandler{
+		llmLogService: llmLogService,
+		logger:        logger,
+	}
+}
+
+// GetSettings returns whether full prompts and responses are stored
+// GET /api/profile/llm-logs
+func (h *LLMLogHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	settings, err := h.llmLogService.GetSettings(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to get LLM log settings", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to get LLM log settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, settings, "LLM log settings retrieved")
+}
+
+// UpdateSettings turns storing full prompts and responses on or off for
+// future logs
+// PUT /api/profile/llm-logs
+func (h *LLMLogHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req struct {
+		StoreBodies *bool `json:"storeBodies"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StoreBodies == nil {
+		utils.RespondError(w, "storeBodies is required", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.llmLogService.UpdateSettings(ctx, uid, services.LLMLogSettings{StoreBodies: *req.StoreBodies})
+	if err != nil {
+		h.logger.Error("Failed to update LLM log settings", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update LLM log settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, settings, "LLM log settings updated")
+}
+
+// GetAIUsage returns the user's AI tokens and estimated cost by feature
+// GET /api/analytics/ai-usage?days=30
+func (h *LLMLogHandler) GetAIUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	days := 0
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			utils.RespondError(w, "days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.llmLogService.AIUsage(ctx, uid, days)
+	if err != nil {
+		h.logger.Error("Failed to compute AI usage", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to compute AI usage", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, report, "AI usage retrieved")
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// MerchantAliasHandler handles user-defined merchant alias rules
+type MerchantAliasHandler struct {
+	merchantAliasService *services.MerchantAliasService
+	logger               *zap.Logger
+}
+
+// NewMerchantAliasHandler creates a new merchant alias handler
+func NewMerchantAliasHandler(merchantAliasService *services.MerchantAliasService, logger *zap.Logger) *MerchantAliasHandler {
+	return &MerchantAliasHandler{
+		merchantAliasService: merchantAliasService,
+		logger:               logger,
+	}
+}
+
+// ListAliases returns the current user's merchant aliases
+// GET /api/merchant-aliases
+func (h *MerchantAliasHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	aliases, err := h.merchantAliasService.ListAliases(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to list merchant aliases", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list merchant aliases", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": aliases,
+		"count": len(aliases),
+	}, "Merchant aliases retrieved")
+}
+
+// CreateAlias adds a merchant alias rule for the current user. The body has a
+// canonical name and either a prefix or a regular expression pattern.
+// POST /api/merchant-aliases
+func (h *MerchantAliasHandler) CreateAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.MerchantAliasInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	alias, err := h.merchantAliasService.AddAlias(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMerchantAlias) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create merchant alias", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create merchant alias", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, alias, "Merchant alias created")
+}
+
+// DeleteAlias removes one of the current user's merchant aliases
+// DELETE /api/merchant-aliases/{id}
+func (h *MerchantAliasHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	if err := h.merchantAliasService.DeleteAlias(ctx, uid, id); err != nil {
+		if writeRepositoryError(w, err, "Merchant alias not found") {
+			return
+		}
+		h.logger.Error("Failed to delete merchant alias",
+			zap.String("uid", uid),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to delete merchant alias", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{"id": id}, "Merchant alias deleted")
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestMerchantAliasHandler(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewMerchantAliasHandler(services.NewMerchantAliasService(mocks.NewMockRepository(), logger, nil), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/merchant-aliases", handler.ListAliases).Methods("GET")
+	router.HandleFunc("/api/merchant-aliases", handler.CreateAlias).Methods("POST")
+	router.HandleFunc("/api/merchant-aliases/{id}", handler.DeleteAlias).Methods("DELETE")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create - prefix", "POST", "/api/merchant-aliases", `{"canonical": "Costco", "prefix": "COSTCO WHSE"}`, http.StatusOK},
+		{"create - pattern", "POST", "/api/merchant-aliases", `{"canonical": "Amazon", "pattern": "^amzn"}`, http.StatusOK},
+		{"create - bad pattern", "POST", "/api/merchant-aliases", `{"canonical": "Amazon", "pattern": "(amzn"}`, http.StatusBadRequest},
+		{"create - missing canonical", "POST", "/api/merchant-aliases", `{"prefix": "amzn"}`, http.StatusBadRequest},
+		{"create - invalid json", "POST", "/api/merchant-aliases", `{`, http.StatusBadRequest},
+		{"list", "GET", "/api/merchant-aliases", "", http.StatusOK},
+		{"delete - unknown", "DELETE", "/api/merchant-aliases/missing", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
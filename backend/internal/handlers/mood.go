@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// MoodHandler handles mood entry requests
+type MoodHandler struct {
+	moodService *services.MoodService
+	logger      *zap.Logger
+}
+
+// NewMoodHandler creates a new mood handler
+func NewMoodHandler(moodService *services.MoodService, logger *zap.Logger) *MoodHandler {
+	return &MoodHandler{
+		moodService: moodService,
+		logger:      logger,
+	}
+}
+
+// CreateMood records a mood entry. value must be an integer from 1 to 10;
+// date defaults to now and emotions must come from the tracker's vocabulary.
+// POST /api/moods
+func (h *MoodHandler) CreateMood(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var fields map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil || fields == nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	mood, err := h.moodService.CreateMood(ctx, uid, fields)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMood) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create mood", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create mood", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, mood, "Mood created")
+}
+
+// UpdateMood changes fields of a mood entry, validating them like CreateMood
+// PUT /api/moods/{id}
+func (h *MoodHandler) UpdateMood(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	var fields map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil || fields == nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	mood, err := h.moodService.UpdateMood(ctx, uid, id, fields)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMood) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if writeRepositoryError(w, err, "Mood not found") {
+			return
+		}
+		h.logger.Error("Failed to update mood",
+			zap.String("uid", uid),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to update mood", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, mood, "Mood updated")
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestMoodHandler(t *testing.T) {
+	logger := zap.NewNop()
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/test-user-123/moods/m1", map[string]interface{}{"id": "m1", "value": int64(5)})
+	handler := NewMoodHandler(services.NewMoodService(repo, logger), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/moods", handler.CreateMood).Methods("POST")
+	router.HandleFunc("/api/moods/{id}", handler.UpdateMood).Methods("PUT")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", "POST", "/api/moods", `{"value": 7, "emotions": ["calm"]}`, http.StatusOK},
+		{"create - out of range", "POST", "/api/moods", `{"value": 11}`, http.StatusBadRequest},
+		{"create - missing value", "POST", "/api/moods", `{"note": "meh"}`, http.StatusBadRequest},
+		{"create - invalid json", "POST", "/api/moods", `{`, http.StatusBadRequest},
+		{"update", "PUT", "/api/moods/m1", `{"value": 3}`, http.StatusOK},
+		{"update - out of range", "PUT", "/api/moods/m1", `{"value": 0}`, http.StatusBadRequest},
+		{"update - unknown", "PUT", "/api/moods/missing", `{"value": 3}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
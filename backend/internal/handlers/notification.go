@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// NotificationHandler handles outbound notification channel settings
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	logger              *zap.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *services.NotificationService, logger *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// ListChannels returns the current user's Slack and Discord channels
+// GET /api/notifications/channels
+func (h *NotificationHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	channels, err := h.notificationService.ListChannels(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to list notification channels", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list notification channels", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": channels,
+		"count": len(channels),
+	}, "Notification channels retrieved")
+}
+
+// CreateChannel adds a notification channel. The body has a kind (slack or
+// discord), the webhook URL and the event types to deliver.
+// POST /api/notifications/channels
+func (h *NotificationHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.NotificationChannel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	channel, err := h.notificationService.CreateChannel(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidNotificationChannel) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create notification channel", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create notification channel", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, channel, "Notification channel created")
+}
+
+// DeleteChannel removes one of the current user's notification channels
+// DELETE /api/notifications/channels/{id}
+func (h *NotificationHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	id := mux.Vars(r)["id"]
+
+	if err := h.notificationService.DeleteChannel(ctx, uid, id); err != nil {
+		if writeRepositoryError(w, err, "Notification channel not found") {
+			return
+		}
+		h.logger.Error("Failed to delete notification channel",
+			zap.String("uid", uid),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to delete notification channel", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{"id": id}, "Notification channel deleted")
+}
+
+// GetQuietHours returns the current user's quiet hours and whether they may
+// be notified right now, with the time quiet hours end when they may not
+// GET /api/notifications/quiet-hours
+func (h *NotificationHandler) GetQuietHours(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	window, err := h.notificationService.NotificationWindow(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to get quiet hours", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to get quiet hours", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, window, "Quiet hours retrieved")
+}
+
+// UpdateQuietHours sets the daily window, {start, end, timezone} with HH:MM
+// bounds, in which notifications are held back. Empty bounds turn it off.
+// PUT /api/notifications/quiet-hours
+func (h *NotificationHandler) UpdateQuietHours(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.QuietHours
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	quiet, err := h.notificationService.UpdateQuietHours(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuietHours) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to update quiet hours", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update quiet hours", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, quiet, "Quiet hours updated")
+}
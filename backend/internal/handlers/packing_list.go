@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
 )
@@ -68,8 +70,7 @@ func (h *PackingListHandler) CreatePackingList(w http.ResponseWriter, r *http.Re
 		)
 
 		// Check for specific error types
-		if err.Error() == "trip not found" || err.Error() == "trip not found: not found" {
-			utils.WriteError(w, "Trip not found", http.StatusNotFound)
+		if writeRepositoryError(w, err, "Trip not found") {
 			return
 		}
 
@@ -126,12 +127,11 @@ func (h *PackingListHandler) UpdatePackingList(w http.ResponseWriter, r *http.Re
 		)
 
 		// Check for specific error types
-		if err.Error() == "trip not found" || err.Error() == "trip not found: not found" {
-			utils.WriteError(w, "Trip not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrPackingListNotFound) {
+			utils.WriteError(w, "Packing list not found. Create one first.", http.StatusNotFound)
 			return
 		}
-		if err.Error() == "packing list not found" || err.Error() == "packing list not found: not found" {
-			utils.WriteError(w, "Packing list not found. Create one first.", http.StatusNotFound)
+		if writeRepositoryError(w, err, "Trip not found") {
 			return
 		}
 
@@ -192,12 +192,11 @@ func (h *PackingListHandler) SetItemStatus(w http.ResponseWriter, r *http.Reques
 		)
 
 		// Check for specific error types
-		if err.Error() == "trip not found" || err.Error() == "trip not found: not found" {
-			utils.WriteError(w, "Trip not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrPackingListNotFound) {
+			utils.WriteError(w, "Packing list not found", http.StatusNotFound)
 			return
 		}
-		if err.Error() == "packing list not found" || err.Error() == "packing list not found: not found" {
-			utils.WriteError(w, "Packing list not found", http.StatusNotFound)
+		if writeRepositoryError(w, err, "Trip not found") {
 			return
 		}
 		if err.Error() == "invalid status: must be one of unpacked, packed, later, no-need" {
@@ -215,3 +214,17 @@ func (h *PackingListHandler) SetItemStatus(w http.ResponseWriter, r *http.Reques
 
 	utils.WriteJSON(w, response, http.StatusOK)
 }
+
+// writeRepositoryError maps repository sentinel errors to HTTP responses.
+// Returns true if a response was written.
+func writeRepositoryError(w http.ResponseWriter, err error, notFoundMessage string) bool {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		utils.WriteError(w, notFoundMessage, http.StatusNotFound)
+		return true
+	case errors.Is(err, repository.ErrPermissionDenied):
+		utils.WriteError(w, "Permission denied", http.StatusForbidden)
+		return true
+	}
+	return false
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
@@ -67,6 +70,10 @@ func (h *PhotoHandler) SubmitVote(w http.ResponseWriter, r *http.Request) {
 	)
 
 	err := h.photoService.SubmitVote(ctx, req.SessionID, req.WinnerID, req.LoserID, voterID)
+	if errors.Is(err, services.ErrInvalidVote) || errors.Is(err, services.ErrPhotoNotInBattle) {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to submit vote",
 			zap.String("sessionId", req.SessionID),
@@ -199,3 +206,187 @@ func (h *PhotoHandler) GetSignedURL(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, response, http.StatusOK)
 }
+
+// NormalizeOrientationRequest represents the request to normalize a library photo's orientation
+type NormalizeOrientationRequest struct {
+	LibraryID string `json:"libraryId"`
+}
+
+// NormalizeOrientation handles POST /api/photo/normalize-orientation.
+// Clients call it after uploading a photo and its thumbnail.
+func (h *PhotoHandler) NormalizeOrientation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req NormalizeOrientationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.LibraryID == "" {
+		utils.WriteError(w, "libraryId is required", http.StatusBadRequest)
+		return
+	}
+
+	rewritten, err := h.photoService.NormalizePhotoOrientation(ctx, uid, req.LibraryID)
+	if err != nil {
+		if writeRepositoryError(w, err, "Photo not found") {
+			return
+		}
+		h.logger.Error("Failed to normalize photo orientation",
+			zap.String("uid", uid),
+			zap.String("libraryId", req.LibraryID),
+			zap.Error(err),
+		)
+		utils.WriteError(w, "Failed to normalize photo orientation", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"rewritten": rewritten,
+	}, http.StatusOK)
+}
+
+// GetVoteHistory handles GET /api/photo-battle/{id}/history?limit=&startAfter=.
+// Votes are newest first; pass the response's nextCursor as startAfter for
+// the next page. Only the battle owner can read its history.
+func (h *PhotoHandler) GetVoteHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	sessionID := mux.Vars(r)["id"]
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			utils.WriteError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.photoService.ListVoteHistory(ctx, uid, sessionID, limit, r.URL.Query().Get("startAfter"))
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, sessionID, "Failed to list vote history")
+		return
+	}
+
+	utils.WriteJSON(w, page, http.StatusOK)
+}
+
+// UndoLastVote handles POST /api/photo-battle/{id}/undo-last-vote, reversing
+// the battle's most recent vote. Only the battle owner can undo votes.
+func (h *PhotoHandler) UndoLastVote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	sessionID := mux.Vars(r)["id"]
+
+	vote, err := h.photoService.UndoLastVote(ctx, uid, sessionID)
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, sessionID, "Failed to undo vote")
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"undone": vote,
+	}, http.StatusOK)
+}
+
+// RepairBattles handles POST /api/photo-battle/repair, merging photos that
+// appear more than once in any of the user's battles
+func (h *PhotoHandler) RepairBattles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	result, err := h.photoService.RepairBattles(ctx, uid)
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, "", "Failed to repair photo battles")
+		return
+	}
+
+	utils.WriteJSON(w, result, http.StatusOK)
+}
+
+// GetResults handles GET /api/photo-battle/{id}/results, ranking the
+// battle's photos and reporting whether every photo has the minimum votes.
+// Only the battle owner can read its results.
+func (h *PhotoHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	sessionID := mux.Vars(r)["id"]
+
+	results, err := h.photoService.GetResults(ctx, uid, sessionID)
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, sessionID, "Failed to get battle results")
+		return
+	}
+
+	utils.WriteJSON(w, results, http.StatusOK)
+}
+
+// UpdateSettings handles PUT /api/photo-battle/{id}/settings, setting the
+// votes every photo needs before the results are settled
+func (h *PhotoHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	sessionID := mux.Vars(r)["id"]
+
+	var req services.BattleSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.photoService.UpdateSettings(ctx, uid, sessionID, req)
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, sessionID, "Failed to update battle settings")
+		return
+	}
+
+	utils.WriteJSON(w, settings, http.StatusOK)
+}
+
+// RecomputeRatings handles POST /api/photo-battle/{id}/recompute, replaying
+// the vote history to rebuild every photo's rating. Only the battle owner can
+// recompute.
+func (h *PhotoHandler) RecomputeRatings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	sessionID := mux.Vars(r)["id"]
+
+	result, err := h.photoService.RecomputeRatings(ctx, uid, sessionID)
+	if err != nil {
+		h.writePhotoBattleError(w, err, uid, sessionID, "Failed to recompute ratings")
+		return
+	}
+
+	utils.WriteJSON(w, result, http.StatusOK)
+}
+
+// writePhotoBattleError maps battle management errors to responses
+func (h *PhotoHandler) writePhotoBattleError(w http.ResponseWriter, err error, uid, sessionID, message string) {
+	switch {
+	case writeRepositoryError(w, err, "Photo battle not found"):
+	case errors.Is(err, services.ErrNotPhotoBattleOwner):
+		utils.WriteError(w, "Photo battle not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidVoteHistoryCursor):
+		utils.WriteError(w, "startAfter is not a vote in this battle", http.StatusBadRequest)
+	case errors.Is(err, services.ErrNoVoteToUndo):
+		utils.WriteError(w, "No vote to undo", http.StatusConflict)
+	case errors.Is(err, services.ErrVoteNotUndoable):
+		utils.WriteError(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidBattleSettings):
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrBattleChanged):
+		utils.WriteError(w, "Battle received a vote while recomputing, try again", http.StatusConflict)
+	default:
+		h.logger.Error(message,
+			zap.String("uid", uid),
+			zap.String("sessionId", sessionID),
+			zap.Error(err),
+		)
+		utils.WriteError(w, message, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// PhotoLibraryHandler handles photo library tag and album requests
+type PhotoLibraryHandler struct {
+	photoLibraryService *services.PhotoLibraryService
+	logger              *zap.Logger
+}
+
+// NewPhotoLibraryHandler creates a new photo library handler
+func NewPhotoLibraryHandler(photoLibraryService *services.PhotoLibraryService, logger *zap.Logger) *PhotoLibraryHandler {
+	return &PhotoLibraryHandler{
+		photoLibraryService: photoLibraryService,
+		logger:              logger,
+	}
+}
+
+// PhotoTagsRequest represents a bulk tag update
+type PhotoTagsRequest struct {
+	PhotoIDs []string `json:"photoIds"`
+	Tags     []string `json:"tags"`
+}
+
+// CreateAlbumRequest represents a request to create an album
+type CreateAlbumRequest struct {
+	Name     string   `json:"name"`
+	PhotoIDs []string `json:"photoIds"`
+}
+
+// ListPhotos returns library photos, optionally filtered by ?tag= and/or ?albumId=
+// GET /api/photo-library
+func (h *PhotoLibraryHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	filter := services.PhotoLibraryFilter{
+		Tag:     r.URL.Query().Get("tag"),
+		AlbumID: r.URL.Query().Get("albumId"),
+	}
+
+	photos, err := h.photoLibraryService.ListPhotos(ctx, uid, filter)
+	if err != nil {
+		if writeRepositoryError(w, err, "Album not found") {
+			return
+		}
+		h.logger.Error("Failed to list photos", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list photos", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"photos": photos,
+		"count":  len(photos),
+	}, "Photos retrieved")
+}
+
+// AddTags adds tags to many photos
+// POST /api/photo-library/tags/add
+func (h *PhotoLibraryHandler) AddTags(w http.ResponseWriter, r *http.Request) {
+	h.updateTags(w, r, true)
+}
+
+// RemoveTags removes tags from many photos
+// POST /api/photo-library/tags/remove
+func (h *PhotoLibraryHandler) RemoveTags(w http.ResponseWriter, r *http.Request) {
+	h.updateTags(w, r, false)
+}
+
+func (h *PhotoLibraryHandler) updateTags(w http.ResponseWriter, r *http.Request, add bool) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req PhotoTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	update := h.photoLibraryService.RemoveTags
+	if add {
+		update = h.photoLibraryService.AddTags
+	}
+	results, err := update(ctx, uid, req.PhotoIDs, req.Tags)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPhotoLibraryRequest) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to update photo tags", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update photo tags", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"results": results,
+	}, "Photo tags updated")
+}
+
+// CreateAlbum creates an album from library photos
+// POST /api/photo-library/albums
+func (h *PhotoLibraryHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req CreateAlbumRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	album, err := h.photoLibraryService.CreateAlbum(ctx, uid, req.Name, req.PhotoIDs)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPhotoLibraryRequest) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create album", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create album", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, album, "Album created")
+}
+
+// ListAlbums returns the user's albums
+// GET /api/photo-library/albums
+func (h *PhotoLibraryHandler) ListAlbums(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	albums, err := h.photoLibraryService.ListAlbums(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to list albums", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to list albums", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"albums": albums,
+	}, "Albums retrieved")
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestPhotoLibraryHandler_CreateAlbum(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/photoLibrary/p1", map[string]interface{}{"url": "a.jpg"})
+	logger := zap.NewNop()
+	handler := NewPhotoLibraryHandler(services.NewPhotoLibraryService(mockRepo, logger), logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid json", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "missing name", body: `{"photoIds": ["p1"]}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown photo", body: `{"name": "Trip", "photoIds": ["nope"]}`, wantStatus: http.StatusBadRequest},
+		{name: "valid", body: `{"name": "Trip", "photoIds": ["p1"]}`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/photo-library/albums", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.CreateAlbum(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPhotoLibraryHandler_ListPhotosByAlbum(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/photoLibrary/p1", map[string]interface{}{"url": "a.jpg", "tags": []interface{}{"beach"}})
+	mockRepo.AddDocument("users/test-user-123/albums/a1", map[string]interface{}{"id": "a1", "photoIds": []interface{}{"p1"}})
+	logger := zap.NewNop()
+	handler := NewPhotoLibraryHandler(services.NewPhotoLibraryService(mockRepo, logger), logger)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "album", query: "albumId=a1", wantStatus: http.StatusOK, wantBody: `"count":1`},
+		{name: "album and non-matching tag", query: "albumId=a1&tag=city", wantStatus: http.StatusOK, wantBody: `"count":0`},
+		{name: "unknown album", query: "albumId=missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/photo-library?"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.ListPhotos(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
 )
 
@@ -73,3 +79,118 @@ func TestNewPhotoHandler_FieldAssignment(t *testing.T) {
 	assert.Equal(t, svc, handler.photoService)
 	assert.Equal(t, logger, handler.logger)
 }
+
+func TestPhotoHandler_NormalizeOrientation(t *testing.T) {
+	logger := zap.NewNop()
+	svc := services.NewPhotoService(mocks.NewMockRepository(), nil, "test-bucket", logger)
+	handler := NewPhotoHandler(svc, logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid json", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "missing libraryId", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown photo", body: `{"libraryId": "missing"}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/photo/normalize-orientation", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.NormalizeOrientation(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPhotoHandler_VoteHistoryAndUndo(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("photoBattles/battle-1", map[string]interface{}{
+		"ownerId": "test-user-123",
+		"photos":  []interface{}{},
+	})
+	repo.AddDocument("photoBattles/battle-2", map[string]interface{}{
+		"ownerId": "other-user",
+		"photos":  []interface{}{},
+	})
+	handler := NewPhotoHandler(services.NewPhotoService(repo, nil, "test-bucket", zap.NewNop()), zap.NewNop())
+	router := mux.NewRouter()
+	router.HandleFunc("/api/photo-battle/{id}/history", handler.GetVoteHistory).Methods("GET")
+	router.HandleFunc("/api/photo-battle/{id}/undo-last-vote", handler.UndoLastVote).Methods("POST")
+	router.HandleFunc("/api/photo-battle/repair", handler.RepairBattles).Methods("POST")
+	router.HandleFunc("/api/photo-battle/{id}/results", handler.GetResults).Methods("GET")
+	router.HandleFunc("/api/photo-battle/{id}/settings", handler.UpdateSettings).Methods("PUT")
+	router.HandleFunc("/api/photo-battle/{id}/recompute", handler.RecomputeRatings).Methods("POST")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid limit", method: "GET", path: "/api/photo-battle/battle-1/history?limit=abc", wantStatus: http.StatusBadRequest},
+		{name: "history of another user's battle", method: "GET", path: "/api/photo-battle/battle-2/history", wantStatus: http.StatusNotFound},
+		{name: "history of unknown battle", method: "GET", path: "/api/photo-battle/missing/history", wantStatus: http.StatusNotFound},
+		{name: "undo without votes", method: "POST", path: "/api/photo-battle/battle-1/undo-last-vote", wantStatus: http.StatusConflict},
+		{name: "undo on another user's battle", method: "POST", path: "/api/photo-battle/battle-2/undo-last-vote", wantStatus: http.StatusNotFound},
+		{name: "repair", method: "POST", path: "/api/photo-battle/repair", wantStatus: http.StatusOK},
+		{name: "results", method: "GET", path: "/api/photo-battle/battle-1/results", wantStatus: http.StatusOK},
+		{name: "results of another user's battle", method: "GET", path: "/api/photo-battle/battle-2/results", wantStatus: http.StatusNotFound},
+		{name: "settings", method: "PUT", path: "/api/photo-battle/battle-1/settings", body: `{"minVotesPerPhoto": 5}`, wantStatus: http.StatusOK},
+		{name: "settings out of range", method: "PUT", path: "/api/photo-battle/battle-1/settings", body: `{"minVotesPerPhoto": 500}`, wantStatus: http.StatusBadRequest},
+		{name: "settings invalid json", method: "PUT", path: "/api/photo-battle/battle-1/settings", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "recompute", method: "POST", path: "/api/photo-battle/battle-1/recompute", wantStatus: http.StatusOK},
+		{name: "recompute another user's battle", method: "POST", path: "/api/photo-battle/battle-2/recompute", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPhotoHandler_SubmitVote_InvalidPhotos(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("photoBattles/battle-1", map[string]interface{}{
+		"ownerId": "test-user-123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "photo1"},
+			map[string]interface{}{"id": "photo1"},
+			map[string]interface{}{"id": "photo2"},
+		},
+	})
+	handler := NewPhotoHandler(services.NewPhotoService(repo, nil, "test-bucket", zap.NewNop()), zap.NewNop())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"valid vote", `{"sessionId": "battle-1", "winnerId": "photo1", "loserId": "photo2"}`, http.StatusOK},
+		{"unknown photo", `{"sessionId": "battle-1", "winnerId": "photo1", "loserId": "photo9"}`, http.StatusBadRequest},
+		{"same photo", `{"sessionId": "battle-1", "winnerId": "photo1", "loserId": "photo1"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/photo/vote", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SubmitVote(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// batchPhotoFormMemory is how much of a batch upload is parsed in memory;
+// the rest of the files spill to temporary files
+const batchPhotoFormMemory = 32 << 20
+
+// PhotoUploadHandler handles photo uploads
+type PhotoUploadHandler struct {
+	photoUploadService *services.PhotoUploadService
+	logger             *zap.Logger
+}
+
+// NewPhotoUploadHandler creates a new photo upload handler
+func NewPhotoUploadHandler(photoUploadService *services.PhotoUploadService, logger *zap.Logger) *PhotoUploadHandler {
+	return &PhotoUploadHandler{
+		photoUploadService: photoUploadService,
+		logger:             logger,
+	}
+}
+
+// UploadBatch uploads the "files" of a multipart form into the photo library,
+// reporting success or failure per file so the client can retry the failures
+// POST /api/storage/photos/batch
+func (h *PhotoUploadHandler) UploadBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	if isAnonymous, _ := ctx.Value("isAnonymous").(bool); isAnonymous {
+		utils.RespondError(w, "You must be signed in to upload photos", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxBatchPhotoBytes)
+	if err := r.ParseMultipartForm(batchPhotoFormMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondError(w, fmt.Sprintf("Upload is larger than %d MB", services.MaxBatchPhotoBytes>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.RespondError(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	headers := r.MultipartForm.File["files"]
+	if len(headers) == 0 {
+		utils.RespondError(w, "No files provided", http.StatusBadRequest)
+		return
+	}
+	if len(headers) > services.MaxBatchPhotoFiles {
+		utils.RespondError(w, fmt.Sprintf("At most %d files can be uploaded at once", services.MaxBatchPhotoFiles), http.StatusBadRequest)
+		return
+	}
+
+	files := make([]services.PhotoUploadFile, len(headers))
+	for i, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			h.logger.Error("Failed to open uploaded file", zap.String("uid", uid), zap.Error(err))
+			utils.RespondError(w, "Failed to read upload", http.StatusInternalServerError)
+			return
+		}
+		data, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			h.logger.Error("Failed to read uploaded file", zap.String("uid", uid), zap.Error(err))
+			utils.RespondError(w, "Failed to read upload", http.StatusInternalServerError)
+			return
+		}
+		files[i] = services.PhotoUploadFile{Name: header.Filename, Data: data}
+	}
+
+	results, err := h.photoUploadService.UploadBatch(ctx, uid, files)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPhotoUpload) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to upload photos", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to upload photos", http.StatusInternalServerError)
+		return
+	}
+
+	uploaded := 0
+	for _, result := range results {
+		if result.Success {
+			uploaded++
+		}
+	}
+	utils.RespondSuccess(w, map[string]interface{}{
+		"results":  results,
+		"uploaded": uploaded,
+		"failed":   len(results) - uploaded,
+	}, "Photos uploaded")
+}
+
+// RegenerateThumbnail rebuilds a library photo's thumbnail from its stored original
+// POST /api/storage/photos/{id}/regenerate-thumbnail
+func (h *PhotoUploadHandler) RegenerateThumbnail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	photoID := mux.Vars(r)["id"]
+
+	photo, err := h.photoUploadService.RegenerateThumbnail(ctx, uid, photoID)
+	if err != nil {
+		if writeRepositoryError(w, err, "Photo not found") {
+			return
+		}
+		if errors.Is(err, services.ErrThumbnailUnavailable) {
+			utils.RespondError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		h.logger.Error("Failed to regenerate thumbnail", zap.String("uid", uid), zap.String("photoId", photoID), zap.Error(err))
+		utils.RespondError(w, "Failed to regenerate thumbnail", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, photo, "Thumbnail regenerated")
+}
+
+// StartThumbnailBackfill starts a background job regenerating the thumbnails
+// of library photos that have none
+// POST /api/storage/photos/regenerate-thumbnails
+func (h *PhotoUploadHandler) StartThumbnailBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	job, err := h.photoUploadService.StartThumbnailBackfill(ctx, uid)
+	if err != nil {
+		if errors.Is(err, services.ErrThumbnailJobRunning) {
+			utils.RespondError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		h.logger.Error("Failed to start thumbnail job", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to start thumbnail job", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, job, "Thumbnail job started")
+}
+
+// GetThumbnailJob returns a thumbnail backfill's progress
+// GET /api/storage/photos/regenerate-thumbnails/{jobId}
+func (h *PhotoUploadHandler) GetThumbnailJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	jobID := mux.Vars(r)["jobId"]
+
+	job, err := h.photoUploadService.GetThumbnailJob(ctx, uid, jobID)
+	if err != nil {
+		if errors.Is(err, services.ErrThumbnailJobNotFound) {
+			utils.RespondError(w, "Thumbnail job not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to load thumbnail job", zap.String("uid", uid), zap.String("jobId", jobID), zap.Error(err))
+		utils.RespondError(w, "Failed to load thumbnail job", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, job, "Thumbnail job retrieved")
+}
+
+// ExportMedia downloads a ZIP of the user's library photo originals with a
+// manifest.json. ?since=<RFC 3339 time> limits it to originals added or
+// changed after that time, for incremental backups. Exports are paged: when
+// more originals remain, the X-Next-Cursor header (and the manifest's
+// nextCursor) is the ?cursor= for the next page.
+// GET /api/storage/photos/export
+func (h *PhotoUploadHandler) ExportMedia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			utils.RespondError(w, "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	// Built in memory, like every response behind the timeout middleware, so
+	// a failure part way through is reported instead of sending a broken ZIP;
+	// the service bounds each page's size
+	var archive bytes.Buffer
+	manifest, err := h.photoUploadService.ExportMedia(ctx, uid, since, r.URL.Query().Get("cursor"), &archive)
+	if err != nil {
+		h.logger.Error("Failed to export media", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to export media", http.StatusInternalServerError)
+		return
+	}
+
+	filename := "focus-notebook-media-" + time.Now().Format("2006-01-02") + ".zip"
+	if !since.IsZero() {
+		filename = "focus-notebook-media-delta-" + time.Now().Format("2006-01-02") + ".zip"
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if manifest.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", manifest.NextCursor)
+	}
+	_, _ = archive.WriteTo(w)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+// memoryPhotoStorage accepts every write and stores nothing
+type memoryPhotoStorage struct{}
+
+func (memoryPhotoStorage) ReadObject(ctx context.Context, path string) ([]byte, error) {
+	return nil, storage.ErrObjectNotExist
+}
+
+func (memoryPhotoStorage) WriteObject(ctx context.Context, path, contentType string, data []byte) error {
+	return nil
+}
+
+func (memoryPhotoStorage) DeleteObject(ctx context.Context, path string) error {
+	return errors.New("unexpected delete")
+}
+
+func (memoryPhotoStorage) GetSignedURL(ctx context.Context, userID, path string, expiresAt *time.Time) (string, time.Time, error) {
+	return "https://storage.test/" + path, time.Now(), nil
+}
+
+// multipartPhotos builds a multipart body with each file under "files"
+func multipartPhotos(t *testing.T, files map[string][]byte) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, data := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestPhotoUploadHandler_UploadBatch(t *testing.T) {
+	var photo bytes.Buffer
+	require.NoError(t, jpeg.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 40, 30)), nil))
+
+	tooMany := map[string][]byte{}
+	for i := 0; i <= services.MaxBatchPhotoFiles; i++ {
+		tooMany[string(rune('a'+i))+".jpg"] = photo.Bytes()
+	}
+
+	tests := []struct {
+		name         string
+		files        map[string][]byte
+		anonymous    bool
+		wantStatus   int
+		wantUploaded int
+		wantFailed   int
+	}{
+		{
+			name:         "partial success",
+			files:        map[string][]byte{"good.jpg": photo.Bytes(), "bad.jpg": []byte("not an image")},
+			wantStatus:   http.StatusOK,
+			wantUploaded: 1,
+			wantFailed:   1,
+		},
+		{name: "no files", files: map[string][]byte{}, wantStatus: http.StatusBadRequest},
+		{name: "too many files", files: tooMany, wantStatus: http.StatusBadRequest},
+		{name: "anonymous", files: map[string][]byte{"good.jpg": photo.Bytes()}, anonymous: true, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			service := services.NewPhotoUploadService(mocks.NewMockRepository(), logger, memoryPhotoStorage{}, config.UploadConfig{})
+			handler := NewPhotoUploadHandler(service, logger)
+
+			body, contentType := multipartPhotos(t, tt.files)
+			req := httptest.NewRequest("POST", "/api/storage/photos/batch", body)
+			req.Header.Set("Content-Type", contentType)
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			ctx = context.WithValue(ctx, "isAnonymous", tt.anonymous)
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			handler.UploadBatch(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data struct {
+					Results  []services.PhotoUploadResult `json:"results"`
+					Uploaded int                          `json:"uploaded"`
+					Failed   int                          `json:"failed"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantUploaded, resp.Data.Uploaded)
+			assert.Equal(t, tt.wantFailed, resp.Data.Failed)
+			for _, result := range resp.Data.Results {
+				assert.Equal(t, result.Name == "good.jpg", result.Success, result.Error)
+			}
+		})
+	}
+}
+
+func TestPhotoUploadHandler_RegenerateThumbnail(t *testing.T) {
+	logger := zap.NewNop()
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/test-user-123/photoLibrary/p1", map[string]interface{}{
+		"id": "p1", "storagePath": "images/original/test-user-123/p1.jpg",
+	})
+	handler := NewPhotoUploadHandler(services.NewPhotoUploadService(repo, logger, memoryPhotoStorage{}, config.UploadConfig{}), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/storage/photos/{id}/regenerate-thumbnail", handler.RegenerateThumbnail).Methods("POST")
+	router.HandleFunc("/api/storage/photos/regenerate-thumbnails/{jobId}", handler.GetThumbnailJob).Methods("GET")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"unknown photo", "POST", "/api/storage/photos/missing/regenerate-thumbnail", http.StatusNotFound},
+		{"original missing from storage", "POST", "/api/storage/photos/p1/regenerate-thumbnail", http.StatusUnprocessableEntity},
+		{"unknown job", "GET", "/api/storage/photos/regenerate-thumbnails/missing", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
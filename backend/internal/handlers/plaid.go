@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -15,13 +16,16 @@ import (
 // PlaidHandler handles Plaid banking requests
 type PlaidHandler struct {
 	plaidService *services.PlaidService
+	webhookDedup *services.WebhookIdempotencyService
 	logger       *zap.Logger
 }
 
-// NewPlaidHandler creates a new Plaid handler
-func NewPlaidHandler(plaidService *services.PlaidService, logger *zap.Logger) *PlaidHandler {
+// NewPlaidHandler creates a new Plaid handler. webhookDedup may be nil to
+// process every webhook delivery.
+func NewPlaidHandler(plaidService *services.PlaidService, webhookDedup *services.WebhookIdempotencyService, logger *zap.Logger) *PlaidHandler {
 	return &PlaidHandler{
 		plaidService: plaidService,
+		webhookDedup: webhookDedup,
 		logger:       logger,
 	}
 }
@@ -246,11 +250,12 @@ func (h *PlaidHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 
 	// Parse webhook payload
 	var webhook struct {
-		WebhookType string  `json:"webhook_type"`
-		WebhookCode string  `json:"webhook_code"`
-		ItemID      string  `json:"item_id"`
-		Error       *string `json:"error"`
-		ErrorCode   *string `json:"error_code"`
+		WebhookType string          `json:"webhook_type"`
+		WebhookCode string          `json:"webhook_code"`
+		ItemID      string          `json:"item_id"`
+		Error       *string         `json:"error"`
+		ErrorCode   *string         `json:"error_code"`
+		Timestamp   json.RawMessage `json:"timestamp"`
 	}
 
 	if err := json.Unmarshal(body, &webhook); err != nil {
@@ -265,9 +270,20 @@ func (h *PlaidHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		zap.String("itemId", webhook.ItemID),
 	)
 
-	// Process webhook (async to return 200 quickly)
+	// Plaid has no event ID; skip redeliveries of the same webhook
+	eventKey := services.PlaidWebhookKey(webhook.WebhookType, webhook.WebhookCode, webhook.ItemID,
+		strings.Trim(string(webhook.Timestamp), `"`), body)
+	if !claimWebhook(r.Context(), h.webhookDedup, services.WebhookProviderPlaid, eventKey, h.logger) {
+		utils.RespondSuccess(w, map[string]interface{}{
+			"received":  true,
+			"duplicate": true,
+		}, "Duplicate webhook ignored")
+		return
+	}
+
+	// Process webhook (async to return 200 quickly); detached from the request deadline
+	processCtx := context.WithoutCancel(r.Context())
 	go func() {
-		processCtx := context.Background()
 		if err := h.plaidService.HandleWebhook(
 			processCtx,
 			webhook.WebhookType,
@@ -276,6 +292,9 @@ func (h *PlaidHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 			webhook.ErrorCode,
 		); err != nil {
 			h.logger.Error("Failed to process webhook", zap.Error(err))
+			if h.webhookDedup != nil {
+				h.webhookDedup.Release(processCtx, services.WebhookProviderPlaid, eventKey)
+			}
 		}
 	}()
 
@@ -14,7 +14,7 @@ func TestNewPlaidHandler(t *testing.T) {
 	svc := &services.PlaidService{}
 	logger := zap.NewNop()
 
-	handler := NewPlaidHandler(svc, logger)
+	handler := NewPlaidHandler(svc, nil, logger)
 
 	require.NotNil(t, handler)
 	assert.Equal(t, svc, handler.plaidService)
@@ -24,7 +24,7 @@ func TestNewPlaidHandler(t *testing.T) {
 func TestNewPlaidHandler_WithNilService(t *testing.T) {
 	logger := zap.NewNop()
 
-	handler := NewPlaidHandler(nil, logger)
+	handler := NewPlaidHandler(nil, nil, logger)
 
 	require.NotNil(t, handler)
 	assert.Nil(t, handler.plaidService)
@@ -34,7 +34,7 @@ func TestNewPlaidHandler_WithNilService(t *testing.T) {
 func TestNewPlaidHandler_WithNilLogger(t *testing.T) {
 	svc := &services.PlaidService{}
 
-	handler := NewPlaidHandler(svc, nil)
+	handler := NewPlaidHandler(svc, nil, nil)
 
 	require.NotNil(t, handler)
 	assert.Equal(t, svc, handler.plaidService)
@@ -42,7 +42,7 @@ func TestNewPlaidHandler_WithNilLogger(t *testing.T) {
 }
 
 func TestNewPlaidHandler_BothNil(t *testing.T) {
-	handler := NewPlaidHandler(nil, nil)
+	handler := NewPlaidHandler(nil, nil, nil)
 
 	require.NotNil(t, handler)
 	assert.Nil(t, handler.plaidService)
@@ -53,8 +53,8 @@ func TestNewPlaidHandler_MultipleInstances(t *testing.T) {
 	svc := &services.PlaidService{}
 	logger := zap.NewNop()
 
-	handler1 := NewPlaidHandler(svc, logger)
-	handler2 := NewPlaidHandler(svc, logger)
+	handler1 := NewPlaidHandler(svc, nil, logger)
+	handler2 := NewPlaidHandler(svc, nil, logger)
 
 	require.NotNil(t, handler1)
 	require.NotNil(t, handler2)
@@ -66,7 +66,7 @@ func TestNewPlaidHandler_FieldAssignment(t *testing.T) {
 	svc := &services.PlaidService{}
 	logger := zap.NewNop()
 
-	handler := NewPlaidHandler(svc, logger)
+	handler := NewPlaidHandler(svc, nil, logger)
 
 	assert.NotNil(t, handler.plaidService)
 	assert.NotNil(t, handler.logger)
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// PreferencesHandler handles the user's currency and locale preferences
+type PreferencesHandler struct {
+	currencyService *services.CurrencyService
+	logger          *zap.Logger
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(currencyService *services.CurrencyService, logger *zap.Logger) *PreferencesHandler {
+	return &PreferencesHandler{
+		currencyService: currencyService,
+		logger:          logger,
+	}
+}
+
+// GetPreferences returns the current user's currency and locale
+// GET /api/profile/preferences
+func (h *PreferencesHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	prefs, err := h.currencyService.GetPreferences(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to get preferences", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to get preferences", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, prefs, "Preferences retrieved")
+}
+
+// UpdatePreferences sets the current user's currency and/or locale
+// PUT /api/profile/preferences
+func (h *PreferencesHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.UserPreferences
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	prefs, err := h.currencyService.UpdatePreferences(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPreferences) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to update preferences", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update preferences", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, prefs, "Preferences updated")
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestPreferencesHandler(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewPreferencesHandler(services.NewCurrencyService(mocks.NewMockRepository(), logger, nil), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/profile/preferences", handler.GetPreferences).Methods("GET")
+	router.HandleFunc("/api/profile/preferences", handler.UpdatePreferences).Methods("PUT")
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"get - defaults", "GET", "", http.StatusOK, `"currency":"USD"`},
+		{"update", "PUT", `{"currency": "eur", "locale": "de-DE"}`, http.StatusOK, `"currency":"EUR"`},
+		{"get - saved", "GET", "", http.StatusOK, `"locale":"de-DE"`},
+		{"update - bad currency", "PUT", `{"currency": "euro"}`, http.StatusBadRequest, ""},
+		{"update - bad locale", "PUT", `{"locale": "german"}`, http.StatusBadRequest, ""},
+		{"update - invalid json", "PUT", `{`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/profile/preferences", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestTaskCategoryHandler(t *testing.T) {
+	logger := zap.NewNop()
+	handler := NewTaskCategoryHandler(services.NewTaskCategoryService(mocks.NewMockRepository(), logger), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/profile/task-categories", handler.GetCategories).Methods("GET")
+	router.HandleFunc("/api/profile/task-categories", handler.UpdateCategories).Methods("PUT")
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"get - defaults", "GET", "", http.StatusOK, `"categories":["mastery","pleasure"]`},
+		{"update", "PUT", `{"categories": ["Health", "wealth", "health"]}`, http.StatusOK, `"categories":["health","wealth"]`},
+		{"get - saved", "GET", "", http.StatusOK, `"categories":["health","wealth"]`},
+		{"update - empty", "PUT", `{"categories": []}`, http.StatusBadRequest, ""},
+		{"update - reserved name", "PUT", `{"categories": ["total"]}`, http.StatusBadRequest, ""},
+		{"update - invalid json", "PUT", `{`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/profile/task-categories", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -31,6 +31,7 @@ func NewSpendingHandler(
 type ProcessCSVRequest struct {
 	FileName    string `json:"fileName"`
 	StoragePath string `json:"storagePath"`
+	MappingID   string `json:"mappingId,omitempty"`
 }
 
 // ProcessCSVResponse represents the response from CSV processing
@@ -72,6 +73,7 @@ func (h *SpendingHandler) ProcessCSV(w http.ResponseWriter, r *http.Request) {
 		userID,
 		req.FileName,
 		req.StoragePath,
+		req.MappingID,
 	)
 
 	if err != nil {
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// StreakHandler handles habit streak requests
+type StreakHandler struct {
+	streakService *services.StreakService
+	logger        *zap.Logger
+}
+
+// NewStreakHandler creates a new streak handler
+func NewStreakHandler(streakService *services.StreakService, logger *zap.Logger) *StreakHandler {
+	return &StreakHandler{
+		streakService: streakService,
+		logger:        logger,
+	}
+}
+
+// GetStreak returns the number of consecutive days, ending today, with at
+// least one document in the collection dated that day. timezone is an IANA
+// name and defaults to UTC.
+// GET /api/analytics/streak?collection=moods&dateField=date&timezone=America/Toronto
+func (h *StreakHandler) GetStreak(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	query := r.URL.Query()
+
+	collection := query.Get("collection")
+	dateField := query.Get("dateField")
+	if collection == "" || dateField == "" {
+		utils.RespondError(w, "collection and dateField are required", http.StatusBadRequest)
+		return
+	}
+
+	streak, err := h.streakService.Compute(ctx, uid, collection, dateField, query.Get("timezone"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStreakRequest) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to compute streak",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to compute streak", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, streak, "Streak retrieved")
+}
@@ -16,18 +16,22 @@ import (
 type StripeHandler struct {
 	stripeClient     *clients.StripeClient
 	stripeBillingSvc *services.StripeBillingService
+	webhookDedup     *services.WebhookIdempotencyService
 	logger           *zap.Logger
 }
 
-// NewStripeHandler creates a new Stripe handler
+// NewStripeHandler creates a new Stripe handler. webhookDedup may be nil to
+// process every webhook delivery.
 func NewStripeHandler(
 	stripeClient *clients.StripeClient,
 	stripeBillingSvc *services.StripeBillingService,
+	webhookDedup *services.WebhookIdempotencyService,
 	logger *zap.Logger,
 ) *StripeHandler {
 	return &StripeHandler{
 		stripeClient:     stripeClient,
 		stripeBillingSvc: stripeBillingSvc,
+		webhookDedup:     webhookDedup,
 		logger:           logger,
 	}
 }
@@ -150,6 +154,16 @@ func (h *StripeHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		zap.String("eventId", event.ID),
 	)
 
+	// Stripe may deliver an event more than once
+	if !claimWebhook(r.Context(), h.webhookDedup, services.WebhookProviderStripe, event.ID, h.logger) {
+		utils.RespondSuccess(w, map[string]interface{}{
+			"received":  true,
+			"eventId":   event.ID,
+			"duplicate": true,
+		}, "Duplicate webhook ignored")
+		return
+	}
+
 	// Process event
 	err = h.stripeBillingSvc.HandleWebhookEvent(r.Context(), event)
 	if err != nil {
@@ -158,6 +172,9 @@ func (h *StripeHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 			zap.String("type", string(event.Type)),
 			zap.String("eventId", event.ID),
 		)
+		if h.webhookDedup != nil {
+			h.webhookDedup.Release(r.Context(), services.WebhookProviderStripe, event.ID)
+		}
 		// Still return 200 to Stripe to acknowledge receipt
 		// Log the error but don't fail the webhook
 	}
@@ -16,7 +16,7 @@ func TestNewStripeHandler(t *testing.T) {
 	stripeSvc := &services.StripeBillingService{}
 	logger := zap.NewNop()
 
-	handler := NewStripeHandler(stripeClient, stripeSvc, logger)
+	handler := NewStripeHandler(stripeClient, stripeSvc, nil, logger)
 
 	require.NotNil(t, handler)
 	assert.Equal(t, stripeClient, handler.stripeClient)
@@ -28,7 +28,7 @@ func TestNewStripeHandler_WithNilService(t *testing.T) {
 	stripeClient := &clients.StripeClient{}
 	logger := zap.NewNop()
 
-	handler := NewStripeHandler(stripeClient, nil, logger)
+	handler := NewStripeHandler(stripeClient, nil, nil, logger)
 
 	require.NotNil(t, handler)
 	assert.Equal(t, stripeClient, handler.stripeClient)
@@ -40,7 +40,7 @@ func TestNewStripeHandler_WithNilLogger(t *testing.T) {
 	stripeClient := &clients.StripeClient{}
 	stripeSvc := &services.StripeBillingService{}
 
-	handler := NewStripeHandler(stripeClient, stripeSvc, nil)
+	handler := NewStripeHandler(stripeClient, stripeSvc, nil, nil)
 
 	require.NotNil(t, handler)
 	assert.Equal(t, stripeClient, handler.stripeClient)
@@ -49,7 +49,7 @@ func TestNewStripeHandler_WithNilLogger(t *testing.T) {
 }
 
 func TestNewStripeHandler_BothNil(t *testing.T) {
-	handler := NewStripeHandler(nil, nil, nil)
+	handler := NewStripeHandler(nil, nil, nil, nil)
 
 	require.NotNil(t, handler)
 	assert.Nil(t, handler.stripeClient)
@@ -62,8 +62,8 @@ func TestNewStripeHandler_MultipleInstances(t *testing.T) {
 	stripeSvc := &services.StripeBillingService{}
 	logger := zap.NewNop()
 
-	handler1 := NewStripeHandler(stripeClient, stripeSvc, logger)
-	handler2 := NewStripeHandler(stripeClient, stripeSvc, logger)
+	handler1 := NewStripeHandler(stripeClient, stripeSvc, nil, logger)
+	handler2 := NewStripeHandler(stripeClient, stripeSvc, nil, logger)
 
 	require.NotNil(t, handler1)
 	require.NotNil(t, handler2)
@@ -77,7 +77,7 @@ func TestNewStripeHandler_FieldAssignment(t *testing.T) {
 	stripeSvc := &services.StripeBillingService{}
 	logger := zap.NewNop()
 
-	handler := NewStripeHandler(stripeClient, stripeSvc, logger)
+	handler := NewStripeHandler(stripeClient, stripeSvc, nil, logger)
 
 	assert.NotNil(t, handler.stripeClient)
 	assert.NotNil(t, handler.stripeBillingSvc)
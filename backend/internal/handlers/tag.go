@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// TagHandler handles tag autocomplete and renames for tagged collections
+type TagHandler struct {
+	tagService *services.TagService
+	logger     *zap.Logger
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService *services.TagService, logger *zap.Logger) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		logger:     logger,
+	}
+}
+
+// RenameTagRequest renames a tag across a collection
+type RenameTagRequest struct {
+	Collection string `json:"collection"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// ListTags returns a collection's tags sorted by usage, for autocomplete
+// GET /api/tags?collection=tasks
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+	collection := r.URL.Query().Get("collection")
+
+	tags, err := h.tagService.ListTags(ctx, uid, collection)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCollection) {
+			utils.RespondError(w, "collection must be tasks, thoughts or notes", http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list tags",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.Error(err),
+		)
+		utils.RespondError(w, "Failed to list tags", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"items": tags,
+		"count": len(tags),
+	}, "Tags retrieved")
+}
+
+// RenameTag replaces a tag on every document in a collection that uses it
+// POST /api/tags/rename
+func (h *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req RenameTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.tagService.RenameTag(ctx, uid, req.Collection, req.From, req.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCollection):
+			utils.RespondError(w, "collection must be tasks, thoughts or notes", http.StatusBadRequest)
+		case errors.Is(err, services.ErrInvalidTag):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.logger.Error("Failed to rename tag",
+				zap.String("uid", uid),
+				zap.String("collection", req.Collection),
+				zap.Error(err),
+			)
+			utils.RespondError(w, "Failed to rename tag", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"from":    req.From,
+		"to":      req.To,
+		"updated": updated,
+	}, "Tag renamed")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestTagHandler_ListTags(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/tasks/t1", map[string]interface{}{"tags": []interface{}{"work"}})
+	handler := NewTagHandler(services.NewTagService(mockRepo, zap.NewNop()), zap.NewNop())
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"tasks", "?collection=tasks", http.StatusOK},
+		{"missing collection", "", http.StatusBadRequest},
+		{"untagged collection", "?collection=portfolios", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/tags"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.ListTags(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTagHandler_RenameTag(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.AddDocument("users/test-user-123/notes/n1", map[string]interface{}{"tags": []interface{}{"recipies"}})
+	handler := NewTagHandler(services.NewTagService(mockRepo, zap.NewNop()), zap.NewNop())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"rename", `{"collection": "notes", "from": "recipies", "to": "recipes"}`, http.StatusOK},
+		{"same name", `{"collection": "notes", "from": "recipes", "to": "recipes"}`, http.StatusBadRequest},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/tags/rename", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.RenameTag(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	tags := mockRepo.Documents["users/test-user-123/notes/n1"]["tags"]
+	if got, ok := tags.([]string); !ok || len(got) != 1 || got[0] != "recipes" {
+		t.Errorf("Expected tags [recipes], got %v", tags)
+	}
+}
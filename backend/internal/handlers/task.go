@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// TaskHandler handles task requests
+type TaskHandler struct {
+	taskService *services.TaskService
+	logger      *zap.Logger
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(taskService *services.TaskService, logger *zap.Logger) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+		logger:      logger,
+	}
+}
+
+// CreateTask creates a task. With ?enrich=true, missing category, priority
+// and estimatedMinutes are suggested by AI for users with AI access; the
+// returned task is then marked aiEnriched.
+// POST /api/tasks
+func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var fields map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil || fields == nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	enrich := r.URL.Query().Get("enrich") == "true"
+
+	task, _, err := h.taskService.CreateTask(ctx, uid, fields, enrich)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTask) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to create task", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create task", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, task, "Task created")
+}
+
+// QuickAddRequest is natural-language task input. Timezone is the IANA name
+// relative dates are resolved in; empty means UTC.
+type QuickAddRequest struct {
+	Text     string `json:"text"`
+	Timezone string `json:"timezone"`
+}
+
+// QuickAdd parses input like "call mom tomorrow 3pm #family high" into a
+// title, due date and time, tags and priority, and creates the task. The
+// response includes what was parsed so the client can confirm it.
+// POST /api/tasks/quick-add
+func (h *TaskHandler) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req QuickAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, parsed, err := h.taskService.QuickAdd(ctx, uid, req.Text, req.Timezone)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTask) || errors.Is(err, services.ErrInvalidTimezone) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to quick-add task", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to create task", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"task":   task,
+		"parsed": parsed,
+	}, "Task created")
+}
+
+// BulkStatusRequest represents a request to complete or reopen many tasks
+type BulkStatusRequest struct {
+	IDs  []string `json:"ids"`
+	Done *bool    `json:"done"`
+}
+
+// BulkStatus completes or reopens multiple tasks
+// POST /api/tasks/bulk-status
+func (h *TaskHandler) BulkStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req BulkStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.IDs) == 0 {
+		utils.RespondError(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) > services.MaxBulkTaskUpdates {
+		utils.RespondError(w, "Too many ids", http.StatusBadRequest)
+		return
+	}
+	if req.Done == nil {
+		utils.RespondError(w, "done is required", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.taskService.BulkUpdateStatus(ctx, uid, req.IDs, *req.Done)
+	if err != nil {
+		h.logger.Error("Failed to update task status", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update tasks", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"results": results,
+	}, "Task status updated")
+}
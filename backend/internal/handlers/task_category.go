@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// TaskCategoryHandler handles the user's task category set
+type TaskCategoryHandler struct {
+	taskCategoryService *services.TaskCategoryService
+	logger              *zap.Logger
+}
+
+// NewTaskCategoryHandler creates a new task category handler
+func NewTaskCategoryHandler(taskCategoryService *services.TaskCategoryService, logger *zap.Logger) *TaskCategoryHandler {
+	return &TaskCategoryHandler{
+		taskCategoryService: taskCategoryService,
+		logger:              logger,
+	}
+}
+
+// GetCategories returns the user's task categories
+// GET /api/profile/task-categories
+func (h *TaskCategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	settings, err := h.taskCategoryService.GetCategories(ctx, uid)
+	if err != nil {
+		h.logger.Error("Failed to get task categories", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to get task categories", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, settings, "Task categories retrieved")
+}
+
+// UpdateCategories replaces the user's task categories
+// PUT /api/profile/task-categories
+func (h *TaskCategoryHandler) UpdateCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	var req services.TaskCategorySettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.taskCategoryService.UpdateCategories(ctx, uid, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTaskCategories) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to update task categories", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to update task categories", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, settings, "Task categories updated")
+}
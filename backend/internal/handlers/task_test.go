@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestTaskHandler_BulkStatus(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewTaskHandler(services.NewTaskService(mockRepo, logger, nil, nil, nil), logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "invalid json",
+			body:       "{",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing ids",
+			body:       `{"done": true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing done",
+			body:       `{"ids": ["task1"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown task returns per-id result",
+			body:       `{"ids": ["missing"], "done": true}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/tasks/bulk-status", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			handler.BulkStatus(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if w.Code == http.StatusOK && !strings.Contains(w.Body.String(), "task not found") {
+				t.Errorf("Expected per-id error in response, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_CreateTask(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewTaskHandler(services.NewTaskService(mockRepo, logger, nil, nil, nil), logger)
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "invalid json",
+			url:        "/api/tasks",
+			body:       "{",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing title",
+			url:        "/api/tasks",
+			body:       `{"priority": "high"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "creates task",
+			url:        "/api/tasks",
+			body:       `{"title": "Buy groceries"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "enrich without ai client falls back",
+			url:        "/api/tasks?enrich=true",
+			body:       `{"title": "Buy groceries"}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), "uid", "test-user-123")
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			handler.CreateTask(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if w.Code == http.StatusOK && strings.Contains(w.Body.String(), "aiEnriched") {
+				t.Errorf("Expected task without aiEnriched marker, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_QuickAdd(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	handler := NewTaskHandler(services.NewTaskService(mockRepo, logger, nil, nil, nil), logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid json", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "no title left", body: `{"text": "#family tomorrow"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid timezone", body: `{"text": "buy milk", "timezone": "Mars/Base"}`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "creates parsed task",
+			body:       `{"text": "call mom 2026-10-20 3pm #family high", "timezone": "America/Toronto"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"parsed":{"title":"call mom","dueDate":"2026-10-20","dueTime":"15:00","tags":["family"],"priority":"high"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/tasks/quick-add", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.QuickAdd(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
@@ -60,7 +62,11 @@ func (h *ThoughtHandler) ProcessThought(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Process thought
-	err := h.thoughtProcessingSvc.ProcessThought(r.Context(), thoughtID, thought, req.Model)
+	result, err := h.thoughtProcessingSvc.ProcessThought(r.Context(), thoughtID, thought, req.Model, req.Force)
+	if errors.Is(err, services.ErrAnonymousQuotaExceeded) {
+		utils.RespondError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to process thought",
 			zap.Error(err),
@@ -73,8 +79,11 @@ func (h *ThoughtHandler) ProcessThought(w http.ResponseWriter, r *http.Request)
 
 	// Success response
 	utils.RespondSuccess(w, map[string]interface{}{
-		"thoughtId": thoughtID,
-		"processed": true,
+		"thoughtId":    thoughtID,
+		"processed":    true,
+		"cached":       result.Cached,
+		"contextUsage": result.ContextUsage,
+		"warnings":     result.Warnings,
 	}, "Thought processed successfully")
 }
 
@@ -109,18 +118,14 @@ func (h *ThoughtHandler) ReprocessThought(w http.ResponseWriter, r *http.Request
 	}
 
 	// Remove "processed" tag to allow reprocessing
-	if tags, ok := thought["tags"].([]interface{}); ok {
-		var newTags []interface{}
-		for _, tag := range tags {
-			if tag != "processed" {
-				newTags = append(newTags, tag)
-			}
-		}
-		thought["tags"] = newTags
-	}
+	thought = services.StripProcessedTag(thought)
 
 	// Process thought
-	err := h.thoughtProcessingSvc.ProcessThought(r.Context(), thoughtID, thought, req.Model)
+	result, err := h.thoughtProcessingSvc.ProcessThought(r.Context(), thoughtID, thought, req.Model, req.Force)
+	if errors.Is(err, services.ErrAnonymousQuotaExceeded) {
+		utils.RespondError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to reprocess thought",
 			zap.Error(err),
@@ -132,11 +137,94 @@ func (h *ThoughtHandler) ReprocessThought(w http.ResponseWriter, r *http.Request
 	}
 
 	utils.RespondSuccess(w, map[string]interface{}{
-		"thoughtId":   thoughtID,
-		"reprocessed": true,
+		"thoughtId":    thoughtID,
+		"reprocessed":  true,
+		"cached":       result.Cached,
+		"contextUsage": result.ContextUsage,
+		"warnings":     result.Warnings,
 	}, "Thought reprocessed successfully")
 }
 
+// StartReprocess queues many thoughts for reprocessing as a background job
+// paced to the AI rate limits and stopped when the subscription budget runs out.
+// POST /api/reprocess-thoughts
+func (h *ThoughtHandler) StartReprocess(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ThoughtIDs []string `json:"thoughtIds"`
+		Model      string   `json:"model"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.RespondError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	uid := r.Context().Value("uid").(string)
+
+	job, err := h.thoughtProcessingSvc.StartReprocess(r.Context(), req.ThoughtIDs, req.Model)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReprocessRequest):
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrAIAccessDenied):
+			utils.RespondError(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, services.ErrReprocessJobRunning):
+			utils.RespondError(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error("Failed to start reprocess job",
+				zap.Error(err),
+				zap.String("uid", uid),
+			)
+			utils.RespondError(w, "Failed to start reprocess job", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.RespondSuccess(w, job, "Reprocess job started")
+}
+
+// GetReprocessStatus returns a reprocess job's progress
+// GET /api/reprocess-thoughts/{jobId}
+func (h *ThoughtHandler) GetReprocessStatus(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value("uid").(string)
+	jobID := mux.Vars(r)["jobId"]
+
+	status, err := h.thoughtProcessingSvc.GetReprocessStatus(r.Context(), uid, jobID)
+	if err != nil {
+		h.respondReprocessError(w, err, uid, jobID)
+		return
+	}
+
+	utils.RespondSuccess(w, status, "Reprocess status retrieved")
+}
+
+// CancelReprocess stops a reprocess job from starting further thoughts
+// POST /api/reprocess-thoughts/{jobId}/cancel
+func (h *ThoughtHandler) CancelReprocess(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value("uid").(string)
+	jobID := mux.Vars(r)["jobId"]
+
+	status, err := h.thoughtProcessingSvc.CancelReprocess(r.Context(), uid, jobID)
+	if err != nil {
+		h.respondReprocessError(w, err, uid, jobID)
+		return
+	}
+
+	utils.RespondSuccess(w, status, "Reprocess job cancellation requested")
+}
+
+func (h *ThoughtHandler) respondReprocessError(w http.ResponseWriter, err error, uid, jobID string) {
+	if errors.Is(err, services.ErrReprocessJobNotFound) {
+		utils.RespondError(w, "Reprocess job not found", http.StatusNotFound)
+		return
+	}
+	h.logger.Error("Failed to load reprocess job",
+		zap.Error(err),
+		zap.String("uid", uid),
+		zap.String("jobId", jobID),
+	)
+	utils.RespondError(w, "Failed to load reprocess job", http.StatusInternalServerError)
+}
+
 // RevertThoughtProcessing handles POST /api/revert-thought-processing
 func (h *ThoughtHandler) RevertThoughtProcessing(w http.ResponseWriter, r *http.Request) {
 	// Parse request
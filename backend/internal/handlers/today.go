@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// TodayHandler handles the home screen summary
+type TodayHandler struct {
+	todayService *services.TodayService
+	logger       *zap.Logger
+}
+
+// NewTodayHandler creates a new today handler
+func NewTodayHandler(todayService *services.TodayService, logger *zap.Logger) *TodayHandler {
+	return &TodayHandler{
+		todayService: todayService,
+		logger:       logger,
+	}
+}
+
+// GetToday returns today's tasks, streak, mood prompt, focus minutes and
+// at-risk goals in one response. timezone is an IANA name and defaults to UTC.
+// GET /api/today?timezone=America/Toronto
+func (h *TodayHandler) GetToday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := ctx.Value("uid").(string)
+
+	today, err := h.todayService.GetToday(ctx, uid, r.URL.Query().Get("timezone"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			utils.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to build today summary", zap.String("uid", uid), zap.Error(err))
+		utils.RespondError(w, "Failed to build today summary", http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondSuccess(w, today, "Today retrieved")
+}
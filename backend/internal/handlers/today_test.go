@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func TestTodayHandler_GetToday(t *testing.T) {
+	logger := zap.NewNop()
+	repo := mocks.NewMockRepository()
+	handler := NewTodayHandler(services.NewTodayService(
+		repo, logger,
+		services.NewStreakService(repo, logger),
+		services.NewGoalService(repo, logger, config.GoalsConfig{}),
+	), logger)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"default timezone", "", http.StatusOK},
+		{"named timezone", "?timezone=Europe/Paris", http.StatusOK},
+		{"unknown timezone", "?timezone=Mars/Olympus", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/today"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "test-user-123"))
+			w := httptest.NewRecorder()
+
+			handler.GetToday(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
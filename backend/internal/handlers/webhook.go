@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+// claimWebhook reports whether a webhook delivery should be processed. Duplicates
+// return false; if the idempotency store is unavailable the delivery is processed
+// anyway, since dropping a real event is worse than handling it twice.
+func claimWebhook(ctx context.Context, dedup *services.WebhookIdempotencyService, provider, eventID string, logger *zap.Logger) bool {
+	if dedup == nil {
+		return true
+	}
+
+	claimed, err := dedup.Claim(ctx, provider, eventID)
+	if err != nil {
+		logger.Warn("Webhook deduplication unavailable, processing delivery",
+			zap.String("provider", provider),
+			zap.String("eventId", eventID),
+			zap.Error(err),
+		)
+		return true
+	}
+	if !claimed {
+		logger.Info("Skipping duplicate webhook delivery",
+			zap.String("provider", provider),
+			zap.String("eventId", eventID),
+		)
+	}
+	return claimed
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/services"
+)
+
+func decodeWebhookResponse(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestPlaidHandler_WebhookReplayIsDeduplicated(t *testing.T) {
+	logger := zap.NewNop()
+	repo := mocks.NewMockRepository()
+	handler := NewPlaidHandler(
+		services.NewPlaidService(nil, repo, logger, nil),
+		services.NewWebhookIdempotencyService(repo, logger, 0),
+		logger,
+	)
+	body := []byte(`{"webhook_type":"TEST","webhook_code":"PING","item_id":"item1"}`)
+
+	send := func() map[string]interface{} {
+		req := httptest.NewRequest("POST", "/api/plaid/webhook", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		return decodeWebhookResponse(t, w)
+	}
+
+	first := send()
+	assert.Nil(t, first["duplicate"])
+	assert.Equal(t, "PING", first["code"])
+
+	second := send()
+	assert.Equal(t, true, second["duplicate"])
+}
+
+func TestStripeHandler_WebhookReplayIsDeduplicated(t *testing.T) {
+	logger := zap.NewNop()
+	secret := "whsec_test"
+	stripeClient, err := clients.NewStripeClient(&config.StripeConfig{SecretKey: "sk_test_123", WebhookSecret: secret}, logger)
+	require.NoError(t, err)
+	handler := NewStripeHandler(
+		stripeClient,
+		services.NewStripeBillingService(stripeClient, nil, logger, nil),
+		services.NewWebhookIdempotencyService(mocks.NewMockRepository(), logger, 0),
+		logger,
+	)
+
+	payload := []byte(`{"id":"evt_replay","object":"event","type":"ping","api_version":"` + stripe.APIVersion + `","data":{"object":{}}}`)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+
+	send := func() map[string]interface{} {
+		req := httptest.NewRequest("POST", "/api/stripe/webhook", bytes.NewReader(payload))
+		req.Header.Set("Stripe-Signature", signed.Header)
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		return decodeWebhookResponse(t, w)
+	}
+
+	first := send()
+	assert.Equal(t, "evt_replay", first["eventId"])
+	assert.Nil(t, first["duplicate"])
+
+	second := send()
+	assert.Equal(t, true, second["duplicate"])
+}
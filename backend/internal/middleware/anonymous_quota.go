@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// AnonymousQuotaConsumer records usage against an anonymous session's quota,
+// reporting false when the quota is used up
+type AnonymousQuotaConsumer interface {
+	ConsumeAnonymousQuota(ctx context.Context, uid, kind string, units int) (bool, error)
+}
+
+// AnonymousQuota consumes one unit of the named quota for anonymous sessions and
+// responds 429 once it is used up. Signed-in users pass through untouched.
+// Only use it on routes where a request costs exactly one unit; bulk endpoints
+// charge their actual usage in the service layer. Must run after authentication.
+func AnonymousQuota(consumer AnonymousQuotaConsumer, kind string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isAnonymous, _ := r.Context().Value("isAnonymous").(bool)
+			if !isAnonymous {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			uid, _ := r.Context().Value("uid").(string)
+			allowed, err := consumer.ConsumeAnonymousQuota(r.Context(), uid, kind, 1)
+			if err != nil {
+				utils.RespondError(w, "Failed to check anonymous usage", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				utils.RespondError(w, "Anonymous usage limit reached; sign in to continue", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
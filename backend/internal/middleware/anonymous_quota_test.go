@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubQuotaConsumer struct {
+	remaining int
+	err       error
+	calls     int
+}
+
+func (s *stubQuotaConsumer) ConsumeAnonymousQuota(ctx context.Context, uid, kind string, units int) (bool, error) {
+	s.calls++
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.remaining <= 0 {
+		return false, nil
+	}
+	s.remaining--
+	return true, nil
+}
+
+func TestAnonymousQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymous  bool
+		consumer   *stubQuotaConsumer
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "signed-in user bypasses quota", anonymous: false, consumer: &stubQuotaConsumer{}, wantStatus: http.StatusOK, wantCalls: 0},
+		{name: "anonymous within quota", anonymous: true, consumer: &stubQuotaConsumer{remaining: 1}, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "anonymous over quota", anonymous: true, consumer: &stubQuotaConsumer{}, wantStatus: http.StatusTooManyRequests, wantCalls: 1},
+		{name: "quota lookup fails", anonymous: true, consumer: &stubQuotaConsumer{err: errors.New("boom")}, wantStatus: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AnonymousQuota(tt.consumer, "aiCalls")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("POST", "/api/chat", nil)
+			ctx := context.WithValue(req.Context(), "uid", "user1")
+			ctx = context.WithValue(ctx, "isAnonymous", tt.anonymous)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req.WithContext(ctx))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalls, tt.consumer.calls)
+		})
+	}
+}
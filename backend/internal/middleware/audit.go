@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AuditRecorder appends entries to a user's audit log
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, uid, action, ip string, details map[string]interface{})
+}
+
+// Audit records action in the user's audit log once the wrapped handler
+// responds with a non-error status. Must run after authentication.
+func Audit(recorder AuditRecorder, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped := newResponseWriter(w)
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.statusCode >= http.StatusBadRequest {
+				return
+			}
+			uid, _ := r.Context().Value("uid").(string)
+			details := map[string]interface{}{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+			if r.URL.RawQuery != "" {
+				details["query"] = r.URL.RawQuery
+			}
+			// The handler has already responded, so the client may have gone;
+			// the entry must still be written
+			recorder.RecordAudit(context.WithoutCancel(r.Context()), uid, action, ClientIP(r), details)
+		})
+	}
+}
+
+// ClientIP returns the originating client address: the last X-Forwarded-For
+// entry, which the load balancer appends, or the connection's remote address.
+// Earlier entries come from the client and can be forged.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		entries := strings.Split(forwarded, ",")
+		if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
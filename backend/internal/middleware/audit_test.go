@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditCall struct {
+	uid, action, ip string
+	details         map[string]interface{}
+}
+
+type stubAuditRecorder struct {
+	calls []auditCall
+}
+
+func (s *stubAuditRecorder) RecordAudit(ctx context.Context, uid, action, ip string, details map[string]interface{}) {
+	s.calls = append(s.calls, auditCall{uid: uid, action: action, ip: ip, details: details})
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int
+	}{
+		{name: "success is recorded", status: http.StatusOK, wantCalls: 1},
+		{name: "client error is not recorded", status: http.StatusBadRequest, wantCalls: 0},
+		{name: "server error is not recorded", status: http.StatusInternalServerError, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &stubAuditRecorder{}
+			handler := Audit(recorder, "data.export")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/export?entityTypes=tasks", nil)
+			req.RemoteAddr = "198.51.100.4:52100"
+			req = req.WithContext(context.WithValue(req.Context(), "uid", "user1"))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			require.Len(t, recorder.calls, tt.wantCalls)
+			if tt.wantCalls > 0 {
+				call := recorder.calls[0]
+				assert.Equal(t, "user1", call.uid)
+				assert.Equal(t, "data.export", call.action)
+				assert.Equal(t, "198.51.100.4", call.ip)
+				assert.Equal(t, "/api/export", call.details["path"])
+				assert.Equal(t, "entityTypes=tasks", call.details["query"])
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "10.0.0.1", ClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	assert.Equal(t, "203.0.113.7", ClientIP(req))
+
+	// A client-supplied entry is ignored in favour of the one the load
+	// balancer appended
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+	assert.Equal(t, "203.0.113.7", ClientIP(req))
+}
+
+func TestAudit_RecordsAfterClientCancels(t *testing.T) {
+	var recordCtx context.Context
+	recorder := auditRecorderFunc(func(ctx context.Context) { recordCtx = ctx })
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "uid", "user1"))
+	handler := Audit(recorder, "data.export")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		cancel()
+	}))
+	req := httptest.NewRequest("GET", "/api/export", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, recordCtx)
+	assert.NoError(t, recordCtx.Err())
+	assert.Equal(t, "user1", recordCtx.Value("uid"))
+}
+
+type auditRecorderFunc func(ctx context.Context)
+
+func (f auditRecorderFunc) RecordAudit(ctx context.Context, uid, action, ip string, details map[string]interface{}) {
+	f(ctx)
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+func newCSRFTestHandler(cfg *config.CSRFConfig) http.Handler {
+	return CSRF(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func enabledCSRFConfig() *config.CSRFConfig {
+	return &config.CSRFConfig{
+		Enabled:        true,
+		SessionCookies: []string{"__session"},
+	}
+}
+
+func TestCSRF_Disabled(t *testing.T) {
+	handler := newCSRFTestHandler(&config.CSRFConfig{Enabled: false, SessionCookies: []string{"__session"}})
+
+	req := httptest.NewRequest("POST", "/api/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: "__session", Value: "abc"})
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+}
+
+func TestCSRF_SafeMethodIssuesToken(t *testing.T) {
+	handler := newCSRFTestHandler(enabledCSRFConfig())
+
+	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "csrf_token", cookies[0].Name)
+		assert.Len(t, cookies[0].Value, 64)
+	}
+}
+
+func TestCSRF_BearerTokenBypasses(t *testing.T) {
+	handler := newCSRFTestHandler(enabledCSRFConfig())
+
+	req := httptest.NewRequest("POST", "/api/tasks", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.AddCookie(&http.Cookie{Name: "__session", Value: "abc"})
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRF_NoSessionCookieBypasses(t *testing.T) {
+	handler := newCSRFTestHandler(enabledCSRFConfig())
+
+	req := httptest.NewRequest("POST", "/api/stripe/webhook", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRF_CookieAuthenticated(t *testing.T) {
+	tests := []struct {
+		name       string
+		cookie     string
+		header     string
+		wantStatus int
+	}{
+		{name: "matching token", cookie: "token123", header: "token123", wantStatus: http.StatusOK},
+		{name: "missing header", cookie: "token123", wantStatus: http.StatusForbidden},
+		{name: "missing cookie", header: "token123", wantStatus: http.StatusForbidden},
+		{name: "mismatched token", cookie: "token123", header: "other", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newCSRFTestHandler(enabledCSRFConfig())
+
+			req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+			req.AddCookie(&http.Cookie{Name: "__session", Value: "abc"})
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "csrf_token", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set("X-CSRF-Token", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestCSRF_CustomNames(t *testing.T) {
+	handler := newCSRFTestHandler(&config.CSRFConfig{
+		Enabled:        true,
+		CookieName:     "xsrf",
+		HeaderName:     "X-XSRF-Token",
+		SessionCookies: []string{"sid"},
+	})
+
+	req := httptest.NewRequest("PUT", "/api/tasks/1", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "abc"})
+	req.AddCookie(&http.Cookie{Name: "xsrf", Value: "t"})
+	req.Header.Set("X-XSRF-Token", "t")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"firebase.google.com/go/v4/auth"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// DatabaseOverrideHeader names the Firestore database a request should use
+const DatabaseOverrideHeader = "X-Firestore-Database"
+
+// DatabaseOverride points the repository at the database named in the
+// X-Firestore-Database header, for admins testing against a secondary or
+// regional database. Only users whose token carries the "admin" custom claim
+// may set it, and only to a database allowed reports as configured; anyone
+// else gets 403. The override is read-only: writes go through batches and
+// transactions on the default client as well as through the repository, so
+// they could land in two databases, and non-GET/HEAD requests with the
+// header get 400. Requests without the header keep the default database.
+// Must run after Authenticate.
+func DatabaseOverride(allowed func(databaseID string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			databaseID := r.Header.Get(DatabaseOverrideHeader)
+			if databaseID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				utils.RespondError(w, DatabaseOverrideHeader+" is only supported on GET and HEAD requests", http.StatusBadRequest)
+				return
+			}
+			if !isAdmin(r) {
+				utils.RespondError(w, "Selecting a database requires admin access", http.StatusForbidden)
+				return
+			}
+			if !allowed(databaseID) {
+				utils.RespondError(w, "Unknown database: "+databaseID, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(repository.WithDatabase(r.Context(), databaseID)))
+		})
+	}
+}
+
+// isAdmin reports whether the verified token has the "admin" custom claim
+func isAdmin(r *http.Request) bool {
+	token, ok := r.Context().Value("decodedToken").(*auth.Token)
+	if !ok || token == nil {
+		return false
+	}
+	admin, _ := token.Claims["admin"].(bool)
+	return admin
+}
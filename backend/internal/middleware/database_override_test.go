@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+func TestDatabaseOverride(t *testing.T) {
+	allowed := func(databaseID string) bool { return databaseID == "test-secondary" }
+	admin := &auth.Token{UID: "admin-1", Claims: map[string]interface{}{"admin": true}}
+	user := &auth.Token{UID: "user-1", Claims: map[string]interface{}{}}
+
+	tests := []struct {
+		name         string
+		method       string
+		header       string
+		token        *auth.Token
+		wantStatus   int
+		wantDatabase string
+	}{
+		{"no header keeps default", "GET", "", user, http.StatusOK, ""},
+		{"admin selects configured database", "GET", "test-secondary", admin, http.StatusOK, "test-secondary"},
+		{"admin reads headers from configured database", "HEAD", "test-secondary", admin, http.StatusOK, "test-secondary"},
+		{"non-admin rejected", "GET", "test-secondary", user, http.StatusForbidden, ""},
+		{"unknown database rejected", "GET", "other", admin, http.StatusForbidden, ""},
+		{"missing token rejected", "GET", "test-secondary", nil, http.StatusForbidden, ""},
+		{"writes rejected", "POST", "test-secondary", admin, http.StatusBadRequest, ""},
+		{"deletes rejected", "DELETE", "test-secondary", admin, http.StatusBadRequest, ""},
+		{"writes without header keep default", "PATCH", "", admin, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotDatabase string
+			handler := DatabaseOverride(allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotDatabase = repository.DatabaseFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/api/tasks", nil)
+			if tt.header != "" {
+				req.Header.Set(DatabaseOverrideHeader, tt.header)
+			}
+			if tt.token != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "decodedToken", tt.token))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantDatabase, gotDatabase)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// FeatureChecker reports whether a feature flag is enabled for a user
+type FeatureChecker interface {
+	IsEnabled(ctx context.Context, uid, flag string) bool
+}
+
+// RequireFeature responds 404 unless the flag is enabled for the authenticated user,
+// so disabled experimental endpoints look like they do not exist.
+// Must run after authentication.
+func RequireFeature(checker FeatureChecker, flag string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid, _ := r.Context().Value("uid").(string)
+			if uid == "" || !checker.IsEnabled(r.Context(), uid, flag) {
+				utils.RespondError(w, "Not found", http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
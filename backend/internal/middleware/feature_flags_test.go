@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubFeatureChecker map[string]bool
+
+func (s stubFeatureChecker) IsEnabled(ctx context.Context, uid, flag string) bool {
+	return s[uid+":"+flag]
+}
+
+func TestRequireFeature(t *testing.T) {
+	checker := stubFeatureChecker{"user1:banking": true}
+	handler := RequireFeature(checker, "banking")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		uid        string
+		wantStatus int
+	}{
+		{name: "enabled", uid: "user1", wantStatus: http.StatusOK},
+		{name: "disabled", uid: "user2", wantStatus: http.StatusNotFound},
+		{name: "unauthenticated", uid: "", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/plaid/trigger-sync", nil)
+			if tt.uid != "" {
+				req = req.WithContext(context.WithValue(req.Context(), "uid", tt.uid))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// SafeModeMessage is the error returned for requests blocked by safe mode
+const SafeModeMessage = "Safe mode is enabled on this deployment; destructive actions are disabled"
+
+// SafeModeRule reports whether safe mode blocks a request to its route; a
+// nil rule blocks every request
+type SafeModeRule func(r *http.Request) bool
+
+// SafeMode responds 403 to destructive requests when enabled: every DELETE,
+// plus the routes in destructive, keyed "METHOD /path/template" such as
+// "POST /api/{collection}/batch-delete". A DELETE route listed with a rule
+// is blocked only when the rule says so. It is a deployment-wide guardrail
+// for shared or demo instances and applies to every user; reads and creates
+// are unaffected.
+func SafeMode(enabled bool, destructive map[string]SafeModeRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, listed := destructive[r.Method+" "+routeTemplate(r)]
+			blocked := r.Method == http.MethodDelete && !listed
+			if listed {
+				blocked = rule == nil || rule(r)
+			}
+			if blocked {
+				utils.RespondError(w, SafeModeMessage, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BlockUnlessDryRun is a SafeModeRule letting dryRun=true requests through
+func BlockUnlessDryRun(r *http.Request) bool {
+	return r.URL.Query().Get("dryRun") != "true"
+}
+
+// BlockReplaceAllImport is a SafeModeRule blocking imports that set
+// options.replaceAll. The body is restored for the handler; one that cannot
+// be read is blocked, and one that is not JSON is left for the handler to
+// reject.
+func BlockReplaceAllImport(r *http.Request) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return true
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Options struct {
+			ReplaceAll bool `json:"replaceAll"`
+		} `json:"options"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	return req.Options.ReplaceAll
+}
+
+// routeTemplate returns the path template of the matched route, or the
+// request path outside a mux router
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func safeModeRouter(enabled bool) *mux.Router {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	api.Use(SafeMode(enabled, map[string]SafeModeRule{
+		"POST /api/{collection}/batch-delete": nil,
+		"POST /api/spending/delete-all":       nil,
+		"POST /api/import/execute":            BlockReplaceAllImport,
+		"DELETE /api/account":                 BlockUnlessDryRun,
+	}))
+
+	spending := api.PathPrefix("/spending").Subrouter()
+	spending.HandleFunc("/delete-all", ok).Methods("POST")
+	spending.HandleFunc("/process-csv", ok).Methods("POST")
+	api.HandleFunc("/merchant-aliases/{id}", ok).Methods("DELETE")
+	api.HandleFunc("/account", ok).Methods("DELETE")
+	api.HandleFunc("/import/execute", func(w http.ResponseWriter, r *http.Request) {
+		// The handler still sees the whole body
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "options") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}).Methods("POST")
+	api.HandleFunc("/tasks", ok).Methods("POST")
+	api.HandleFunc("/{collection}", ok).Methods("GET")
+	api.HandleFunc("/{collection}/batch-delete", ok).Methods("POST")
+	api.HandleFunc("/{collection}/{id}", ok).Methods("GET")
+	api.HandleFunc("/{collection}/{id}", ok).Methods("PUT")
+	return router
+}
+
+func TestSafeMode(t *testing.T) {
+	tests := []struct {
+		method    string
+		path      string
+		body      string
+		wantBlock bool
+	}{
+		{method: "POST", path: "/api/tasks/batch-delete", wantBlock: true},
+		{method: "POST", path: "/api/spending/delete-all", wantBlock: true},
+		{method: "DELETE", path: "/api/merchant-aliases/a1", wantBlock: true},
+		{method: "GET", path: "/api/tasks", wantBlock: false},
+		{method: "GET", path: "/api/tasks/t1", wantBlock: false},
+		{method: "POST", path: "/api/tasks", wantBlock: false},
+		{method: "PUT", path: "/api/tasks/t1", wantBlock: false},
+		{method: "POST", path: "/api/spending/process-csv", wantBlock: false},
+		{method: "POST", path: "/api/import/execute", body: `{"data": {}, "options": {"replaceAll": true, "confirmReplaceAll": true}}`, wantBlock: true},
+		{method: "POST", path: "/api/import/execute", body: `{"data": {}, "options": {"updateReferences": true}}`, wantBlock: false},
+		{method: "DELETE", path: "/api/account", wantBlock: true},
+		{method: "DELETE", path: "/api/account?dryRun=false", wantBlock: true},
+		{method: "DELETE", path: "/api/account?dryRun=true", wantBlock: false},
+	}
+
+	for _, enabled := range []bool{true, false} {
+		router := safeModeRouter(enabled)
+		for _, tt := range tests {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if enabled && tt.wantBlock {
+				assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", tt.method, tt.path)
+				assert.Contains(t, w.Body.String(), SafeModeMessage)
+			} else {
+				assert.Equal(t, http.StatusOK, w.Code, "%s %s (safe mode %v)", tt.method, tt.path, enabled)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+const (
+	defaultRequestTimeout     = 15 * time.Second
+	defaultAIRequestTimeout   = 60 * time.Second
+	defaultLongRequestTimeout = 5 * time.Minute
+
+	// longConnGrace is how far past the handler deadline a long request's
+	// connection deadlines reach, leaving time to send the response
+	longConnGrace = 15 * time.Second
+)
+
+// Timeout middleware bounds each request with a deadline on its context so
+// Firestore and AI calls made with r.Context() are cancelled when it expires.
+//
+// Requests whose path starts with one of cfg.LongPaths get cfg.Long (a
+// {name} segment in a configured path matches any one segment), and the
+// server's read and write timeouts are pushed back to match so bulk uploads and
+// downloads are not cut off. Paths in cfg.AIPaths get cfg.AI; everything else
+// gets cfg.Default. The handler's response is buffered; if the deadline passes
+// first the client receives 504 and anything the handler writes afterwards is
+// discarded. Work that must outlive the request should use context.WithoutCancel.
+func Timeout(cfg *config.RequestTimeoutConfig) func(http.Handler) http.Handler {
+	defaultTimeout := cfg.Default
+	if defaultTimeout <= 0 {
+		defaultTimeout = defaultRequestTimeout
+	}
+	aiTimeout := cfg.AI
+	if aiTimeout <= 0 {
+		aiTimeout = defaultAIRequestTimeout
+	}
+	longTimeout := cfg.Long
+	if longTimeout <= 0 {
+		longTimeout = defaultLongRequestTimeout
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timeout := defaultTimeout
+			switch {
+			case hasPathPrefix(r.URL.Path, cfg.LongPaths):
+				timeout = longTimeout
+				extendConnDeadlines(w, timeout+longConnGrace)
+			case hasPathPrefix(r.URL.Path, cfg.AIPaths):
+				timeout = aiTimeout
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{header: make(http.Header), statusCode: http.StatusOK}
+			done := make(chan struct{})
+			panicChan := make(chan interface{}, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				// Re-panic on the serving goroutine so Recovery handles it
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for key, values := range tw.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tw.statusCode)
+				_, _ = w.Write(tw.buf.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if r.Context().Err() != nil {
+					// Client went away; there is no one to respond to
+					return
+				}
+				utils.RespondError(w, "Request timed out", http.StatusGatewayTimeout)
+			}
+		})
+	}
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		if strings.Contains(prefix, "{") {
+			if matchesPathTemplate(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPathTemplate reports whether path starts with the segments of a
+// template such as /api/{collection}/batch-delete, where a {name} segment
+// matches any one non-empty segment
+func matchesPathTemplate(path, template string) bool {
+	segments := strings.Split(path, "/")
+	templateSegments := strings.Split(template, "/")
+	if len(segments) < len(templateSegments) {
+		return false
+	}
+	for i, want := range templateSegments {
+		if strings.HasPrefix(want, "{") && strings.HasSuffix(want, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segments[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// extendConnDeadlines moves the connection's read and write deadlines to d
+// from now. Writers that cannot reach the connection keep the server limits.
+func extendConnDeadlines(w http.ResponseWriter, d time.Duration) {
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(d)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
+
+// timeoutWriter buffers a handler's response until it completes or times out
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	statusCode  int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the buffered headers. Once the request has timed out a
+// handler that is still running gets a throwaway map instead, so it never
+// races the middleware.
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return make(http.Header)
+	}
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.statusCode = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.buf.Write(b)
+}
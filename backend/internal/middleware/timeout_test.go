@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+// slowOperation simulates a Firestore or AI call that honours cancellation
+func slowOperation(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTimeout_CancelsSlowOperation(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{Enabled: true, Default: 20 * time.Millisecond, AI: time.Second}
+	opErr := make(chan error, 1)
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := slowOperation(r.Context(), time.Second)
+		opErr <- err
+		if err != nil {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	w := httptest.NewRecorder()
+	start := time.Now()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	select {
+	case err := <-opErr:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("slow operation was not cancelled")
+	}
+}
+
+func TestTimeout_AIPathsGetLongerTimeout(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{
+		Enabled: true,
+		Default: 10 * time.Millisecond,
+		AI:      time.Second,
+		AIPaths: []string{"/api/chat"},
+	}
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := slowOperation(r.Context(), 50*time.Millisecond); err != nil {
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/chat", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/tasks/bulk-status", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestTimeout_LongPathsGetLongTimeout(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{
+		Enabled:   true,
+		Default:   10 * time.Millisecond,
+		AI:        20 * time.Millisecond,
+		Long:      time.Second,
+		AIPaths:   []string{"/api/import"},
+		LongPaths: []string{"/api/export", "/api/import", "/api/{collection}/batch-delete"},
+	}
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := slowOperation(r.Context(), 50*time.Millisecond); err != nil {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Long paths win over AI paths
+	for _, path := range []string{"/api/export", "/api/import/execute", "/api/tasks/batch-delete"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	// A template segment stands for exactly one path segment
+	for _, path := range []string{"/api/batch-delete", "/api/tasks/t1/batch-delete", "/api/tasks"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code, path)
+	}
+}
+
+func TestTimeout_HeaderAfterTimeoutIsDetached(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{Enabled: true, Default: 10 * time.Millisecond}
+	headerSet := make(chan struct{})
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("X-Late", "yes")
+		close(headerSet)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks", nil))
+	<-headerSet
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Empty(t, w.Header().Get("X-Late"))
+}
+
+func TestTimeout_PassesThroughFastResponse(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{Enabled: true, Default: time.Second}
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.True(t, hasDeadline)
+		w.Header().Set("X-Test", "yes")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/features", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "yes", w.Header().Get("X-Test"))
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestTimeout_Disabled(t *testing.T) {
+	handler := Timeout(&config.RequestTimeoutConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.False(t, hasDeadline)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/tasks", nil))
+}
+
+func TestTimeout_PropagatesPanic(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{Enabled: true, Default: time.Second}
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	require.Panics(t, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/tasks", nil))
+	})
+}
+
+func TestTimeout_WithoutCancelOutlivesDeadline(t *testing.T) {
+	cfg := &config.RequestTimeoutConfig{Enabled: true, Default: 10 * time.Millisecond}
+	background := make(chan error, 1)
+	handler := Timeout(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		detached := context.WithoutCancel(r.Context())
+		go func() {
+			background <- slowOperation(detached, 50*time.Millisecond)
+		}()
+		_ = slowOperation(r.Context(), time.Second)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/plaid/trigger-sync", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NoError(t, <-background)
+}
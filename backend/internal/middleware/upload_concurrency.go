@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+// DefaultMaxConcurrentUploads applies when upload.max_concurrent_per_user is not set
+const DefaultMaxConcurrentUploads = 2
+
+// UploadConcurrency limits each user to limit upload requests in flight at
+// once, responding 429 to any beyond that. Uploads are read into memory, so
+// this bounds what one user can make the server hold. The returned middleware
+// shares one set of counters across every route it wraps. Must run after
+// authentication.
+func UploadConcurrency(limit int) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = DefaultMaxConcurrentUploads
+	}
+	var mu sync.Mutex
+	inFlight := map[string]int{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid, _ := r.Context().Value("uid").(string)
+
+			mu.Lock()
+			if inFlight[uid] >= limit {
+				mu.Unlock()
+				utils.RespondError(w, fmt.Sprintf("Too many uploads in progress; at most %d at a time", limit), http.StatusTooManyRequests)
+				return
+			}
+			inFlight[uid]++
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				if inFlight[uid]--; inFlight[uid] <= 0 {
+					delete(inFlight, uid)
+				}
+				mu.Unlock()
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
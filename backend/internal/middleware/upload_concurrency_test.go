@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadConcurrency(t *testing.T) {
+	const limit = 3
+	entered := make(chan struct{}, limit+2)
+	release := make(chan struct{})
+	handler := UploadConcurrency(limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	upload := func(uid string) int {
+		req := httptest.NewRequest("POST", "/api/storage/photos/batch", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "uid", uid))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	codes := make(chan int, limit+1)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- upload("user-1")
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-entered
+	}
+
+	// The next upload from the same user is rejected; another user is unaffected
+	assert.Equal(t, http.StatusTooManyRequests, upload("user-1"))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes <- upload("user-2")
+	}()
+	<-entered
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// Finished uploads free their slots
+	assert.Equal(t, http.StatusOK, upload("user-1"))
+}
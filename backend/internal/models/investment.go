@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// InvestmentLot represents a tax lot of shares bought together
+type InvestmentLot struct {
+	Shares    float64   `firestore:"shares" json:"shares"`
+	CostBasis float64   `firestore:"costBasis" json:"costBasis"` // total cost of the lot, not per share
+	Date      time.Time `firestore:"date" json:"date"`
+}
+
+// CostPerShare returns the lot's cost basis per share
+func (l InvestmentLot) CostPerShare() float64 {
+	if l.Shares == 0 {
+		return 0
+	}
+	return l.CostBasis / l.Shares
+}
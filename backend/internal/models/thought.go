@@ -23,6 +23,8 @@ type ThoughtProcessingRequest struct {
 	Model       string                 `json:"model,omitempty"`
 	Context     *UserContext           `json:"context,omitempty"`
 	ToolSpecIds []string               `json:"toolSpecIds,omitempty"`
+	// Force bypasses the AI response cache
+	Force bool `json:"force,omitempty"`
 }
 
 // ThoughtProcessingResponse represents the AI response
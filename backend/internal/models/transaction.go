@@ -30,6 +30,32 @@ type CSVTransaction struct {
 	Amount      float64 `json:"amount"`
 }
 
+// CSV amount sign conventions: how a bank writes money going out
+const (
+	// AmountSignDebitPositive: spending is positive and refunds negative; amounts are kept as-is
+	AmountSignDebitPositive = "debit-positive"
+	// AmountSignDebitNegative: spending is negative; amounts are negated
+	AmountSignDebitNegative = "debit-negative"
+)
+
+// CSVMapping is a saved profile describing one bank's CSV layout. Columns are
+// header names matched case-insensitively; empty columns are detected from the
+// header. DateFormat uses YYYY, YY, MM, M, DD and D tokens (e.g. MM/DD/YYYY);
+// empty keeps dates as written. HeaderSignature is the normalized header row
+// used to pick a mapping for uploads that do not name one.
+type CSVMapping struct {
+	ID                   string    `json:"id"`
+	Name                 string    `json:"name"`
+	DateColumn           string    `json:"dateColumn,omitempty"`
+	AmountColumn         string    `json:"amountColumn,omitempty"`
+	DescriptionColumn    string    `json:"descriptionColumn,omitempty"`
+	DateFormat           string    `json:"dateFormat,omitempty"`
+	AmountSignConvention string    `json:"amountSignConvention,omitempty"`
+	HeaderSignature      []string  `json:"headerSignature,omitempty"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
 // EnhancedTransaction represents an AI-enhanced transaction
 type EnhancedTransaction struct {
 	OriginalDescription string `json:"originalDescription"`
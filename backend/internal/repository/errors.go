@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Sentinel errors, re-exported so handlers can check them via the repository package
+var (
+	ErrNotFound         = interfaces.ErrNotFound
+	ErrPermissionDenied = interfaces.ErrPermissionDenied
+	ErrInvalidOrdering  = interfaces.ErrInvalidOrdering
+	ErrIndexRequired    = interfaces.ErrIndexRequired
+)
+
+// wrapError attaches the matching sentinel error to a Firestore error so
+// callers can use errors.Is instead of matching on the message
+func wrapError(op string, path string, err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("failed to %s document at %s: %w: %w", op, path, ErrNotFound, err)
+	case codes.PermissionDenied:
+		return fmt.Errorf("failed to %s document at %s: %w: %w", op, path, ErrPermissionDenied, err)
+	default:
+		return fmt.Errorf("failed to %s document at %s: %w", op, path, err)
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
@@ -15,17 +19,68 @@ var _ interfaces.Repository = (*FirestoreRepository)(nil)
 
 // FirestoreRepository handles Firestore CRUD operations
 type FirestoreRepository struct {
-	client *firestore.Client
+	client    *firestore.Client
+	databases map[string]*firestore.Client // keyed by database ID
 }
 
-// NewFirestoreRepository creates a new Firestore repository
+// NewFirestoreRepository creates a new Firestore repository against the
+// database the client was opened on
 func NewFirestoreRepository(client *firestore.Client) *FirestoreRepository {
 	return &FirestoreRepository{
 		client: client,
 	}
 }
 
-// Client returns the underlying Firestore client
+// NewFirestoreRepositoryWithDatabases creates a Firestore repository whose
+// default database is client's, and which can also serve the databases in
+// databases (keyed by database ID) to requests whose context selects them
+// with WithDatabase.
+//
+// Only the path-based methods (Get, List*, Query*, *Document, transactions and
+// the atomic field updates) follow the context. Client, Collection, Batch and
+// NewBatchWrite always use the default database, as do ForEach, CollectAll,
+// CollectAllConsistent and Count, which run queries their callers built from
+// Client().
+func NewFirestoreRepositoryWithDatabases(client *firestore.Client, databases map[string]*firestore.Client) *FirestoreRepository {
+	return &FirestoreRepository{
+		client:    client,
+		databases: databases,
+	}
+}
+
+type databaseContextKey struct{}
+
+// WithDatabase returns a context that makes the repository use the named
+// database instead of its default one
+func WithDatabase(ctx context.Context, databaseID string) context.Context {
+	return context.WithValue(ctx, databaseContextKey{}, databaseID)
+}
+
+// DatabaseFromContext returns the database ID selected with WithDatabase, or
+// "" when the request uses the default database
+func DatabaseFromContext(ctx context.Context) string {
+	databaseID, _ := ctx.Value(databaseContextKey{}).(string)
+	return databaseID
+}
+
+// HasDatabase reports whether a request may select databaseID with WithDatabase
+func (r *FirestoreRepository) HasDatabase(databaseID string) bool {
+	_, ok := r.databases[databaseID]
+	return ok
+}
+
+// clientFor returns the client of the database ctx selects. Unknown IDs fall
+// back to the default database; the request middleware rejects them first.
+func (r *FirestoreRepository) clientFor(ctx context.Context) *firestore.Client {
+	if databaseID := DatabaseFromContext(ctx); databaseID != "" {
+		if client, ok := r.databases[databaseID]; ok {
+			return client
+		}
+	}
+	return r.client
+}
+
+// Client returns the underlying Firestore client of the default database
 func (r *FirestoreRepository) Client() *firestore.Client {
 	return r.client
 }
@@ -42,10 +97,10 @@ func (r *FirestoreRepository) Batch() *firestore.WriteBatch {
 
 // Get retrieves a document and returns its data as a map
 func (r *FirestoreRepository) Get(ctx context.Context, path string) (map[string]interface{}, error) {
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	snap, err := ref.Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get document at %s: %w", path, err)
+		return nil, wrapError("get", path, err)
 	}
 	return snap.Data(), nil
 }
@@ -65,9 +120,59 @@ func (r *FirestoreRepository) Delete(ctx context.Context, path string) error {
 	return r.DeleteDocument(ctx, path)
 }
 
+// maxBatchWrites is the Firestore limit on writes in one batch
+const maxBatchWrites = 500
+
+// GetAll reads the documents at paths in one round trip; missing documents
+// are nil
+func (r *FirestoreRepository) GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error) {
+	client := r.clientFor(ctx)
+	refs := make([]*firestore.DocumentRef, len(paths))
+	for i, path := range paths {
+		refs[i] = client.Doc(path)
+		if refs[i] == nil {
+			return nil, fmt.Errorf("invalid document path %s", path)
+		}
+	}
+
+	snaps, err := client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %d documents: %w", len(paths), err)
+	}
+	docs := make([]map[string]interface{}, len(snaps))
+	for i, snap := range snaps {
+		if snap.Exists() {
+			docs[i] = snap.Data()
+		}
+	}
+	return docs, nil
+}
+
+// DeleteAll deletes the documents at paths in batches of maxBatchWrites
+func (r *FirestoreRepository) DeleteAll(ctx context.Context, paths []string) (int, error) {
+	client := r.clientFor(ctx)
+	deleted := 0
+	for start := 0; start < len(paths); start += maxBatchWrites {
+		end := min(start+maxBatchWrites, len(paths))
+		batch := client.Batch()
+		for _, path := range paths[start:end] {
+			ref := client.Doc(path)
+			if ref == nil {
+				return deleted, fmt.Errorf("invalid document path %s", path)
+			}
+			batch.Delete(ref)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return deleted, fmt.Errorf("failed to delete %d documents: %w", end-start, err)
+		}
+		deleted = end
+	}
+	return deleted, nil
+}
+
 // List retrieves documents from a collection with optional limit
 func (r *FirestoreRepository) List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error) {
-	query := r.client.Collection(collectionPath).Query
+	query := r.clientFor(ctx).Collection(collectionPath).Query
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -84,6 +189,93 @@ func (r *FirestoreRepository) List(ctx context.Context, collectionPath string, l
 	return result, nil
 }
 
+// ListOrdered retrieves documents from a collection sorted by one or more fields.
+// Multi-field orderings need a composite index; a missing index is reported as
+// ErrIndexRequired with a hint describing the index to create.
+func (r *FirestoreRepository) ListOrdered(ctx context.Context, collectionPath string, orderings []Ordering, limit int) ([]map[string]interface{}, error) {
+	if err := ValidateOrderings(orderings); err != nil {
+		return nil, err
+	}
+
+	query := OrderByAll(orderings)(r.clientFor(ctx).Collection(collectionPath).Query)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, fmt.Errorf("failed to list collection %s: %w (%s): %w",
+				collectionPath, ErrIndexRequired, IndexHint(collectionPath, orderings), err)
+		}
+		return nil, fmt.Errorf("failed to list collection %s: %w", collectionPath, err)
+	}
+
+	result := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.Data()
+	}
+	return result, nil
+}
+
+// ListWhere retrieves documents from a collection matching every filter, so
+// only the matching documents are read
+func (r *FirestoreRepository) ListWhere(ctx context.Context, collectionPath string, filters []Filter, limit int) ([]map[string]interface{}, error) {
+	query := r.clientFor(ctx).Collection(collectionPath).Query
+	for _, f := range filters {
+		query = query.Where(f.Field, f.Op, f.Value)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, fmt.Errorf("failed to query collection %s: %w: %w", collectionPath, ErrIndexRequired, err)
+		}
+		return nil, fmt.Errorf("failed to query collection %s: %w", collectionPath, err)
+	}
+
+	result := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.Data()
+	}
+	return result, nil
+}
+
+// ListWhereOrdered retrieves documents matching every filter, sorted by
+// orderings, so filtering, ordering and the limit all happen in Firestore
+func (r *FirestoreRepository) ListWhereOrdered(ctx context.Context, collectionPath string, filters []Filter, orderings []Ordering, limit int) ([]map[string]interface{}, error) {
+	if err := ValidateOrderings(orderings); err != nil {
+		return nil, err
+	}
+
+	query := r.clientFor(ctx).Collection(collectionPath).Query
+	for _, f := range filters {
+		query = query.Where(f.Field, f.Op, f.Value)
+	}
+	query = OrderByAll(orderings)(query)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, fmt.Errorf("failed to query collection %s: %w (%s): %w",
+				collectionPath, ErrIndexRequired, IndexHint(collectionPath, orderings), err)
+		}
+		return nil, fmt.Errorf("failed to query collection %s: %w", collectionPath, err)
+	}
+
+	result := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.Data()
+	}
+	return result, nil
+}
+
 // CreateDocument creates a new document with metadata
 // Matches createAt() from src/lib/data/gateway.ts:59-68
 func (r *FirestoreRepository) CreateDocument(ctx context.Context, path string, data map[string]interface{}) error {
@@ -99,10 +291,10 @@ func (r *FirestoreRepository) CreateDocument(ctx context.Context, path string, d
 	// Remove undefined values
 	cleanData := RemoveUndefinedValues(data)
 
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	_, err := ref.Set(ctx, cleanData)
 	if err != nil {
-		return fmt.Errorf("failed to create document at %s: %w", path, err)
+		return wrapError("create", path, err)
 	}
 
 	return nil
@@ -120,10 +312,10 @@ func (r *FirestoreRepository) SetDocument(ctx context.Context, path string, data
 
 	cleanData := RemoveUndefinedValues(data)
 
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	_, err := ref.Set(ctx, cleanData, firestore.MergeAll)
 	if err != nil {
-		return fmt.Errorf("failed to set document at %s: %w", path, err)
+		return wrapError("set", path, err)
 	}
 
 	return nil
@@ -154,10 +346,10 @@ func (r *FirestoreRepository) UpdateDocument(ctx context.Context, path string, u
 		})
 	}
 
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	_, err := ref.Update(ctx, fieldUpdates)
 	if err != nil {
-		return fmt.Errorf("failed to update document at %s: %w", path, err)
+		return wrapError("update", path, err)
 	}
 
 	return nil
@@ -166,10 +358,10 @@ func (r *FirestoreRepository) UpdateDocument(ctx context.Context, path string, u
 // DeleteDocument deletes a document
 // Matches deleteAt() from src/lib/data/gateway.ts:105-108
 func (r *FirestoreRepository) DeleteDocument(ctx context.Context, path string) error {
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	_, err := ref.Delete(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete document at %s: %w", path, err)
+		return wrapError("delete", path, err)
 	}
 
 	return nil
@@ -177,10 +369,10 @@ func (r *FirestoreRepository) DeleteDocument(ctx context.Context, path string) e
 
 // GetDocument retrieves a single document
 func (r *FirestoreRepository) GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error) {
-	ref := r.client.Doc(path)
+	ref := r.clientFor(ctx).Doc(path)
 	snap, err := ref.Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get document at %s: %w", path, err)
+		return nil, wrapError("get", path, err)
 	}
 
 	return snap, nil
@@ -188,7 +380,7 @@ func (r *FirestoreRepository) GetDocument(ctx context.Context, path string) (*fi
 
 // QueryCollection queries a collection with optional filters
 func (r *FirestoreRepository) QueryCollection(ctx context.Context, collectionPath string, opts ...interfaces.QueryOption) ([]*firestore.DocumentSnapshot, error) {
-	query := r.client.Collection(collectionPath).Query
+	query := r.clientFor(ctx).Collection(collectionPath).Query
 
 	// Apply options
 	for _, opt := range opts {
@@ -203,6 +395,190 @@ func (r *FirestoreRepository) QueryCollection(ctx context.Context, collectionPat
 	return docs, nil
 }
 
+// ForEach runs fn for every document returned by the query.
+// Iteration stops at the first error from the query or from fn, and the
+// iterator is always stopped.
+func (r *FirestoreRepository) ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to iterate query: %w", err)
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// CollectAll returns the data of every document returned by the query
+func (r *FirestoreRepository) CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error) {
+	results := []map[string]interface{}{}
+	err := r.ForEach(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		results = append(results, doc.Data())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RunTransaction runs fn in a Firestore transaction
+func (r *FirestoreRepository) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx interfaces.Transaction) error) error {
+	client := r.clientFor(ctx)
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return fn(ctx, &firestoreTransaction{client: client, tx: tx})
+	})
+}
+
+// firestoreTransaction adapts *firestore.Transaction to path-based access
+type firestoreTransaction struct {
+	client *firestore.Client
+	tx     *firestore.Transaction
+}
+
+func (t *firestoreTransaction) Get(path string) (map[string]interface{}, error) {
+	snap, err := t.tx.Get(t.client.Doc(path))
+	if err != nil {
+		return nil, wrapError("get", path, err)
+	}
+	return snap.Data(), nil
+}
+
+func (t *firestoreTransaction) Set(path string, data map[string]interface{}) error {
+	return t.tx.Set(t.client.Doc(path), data, firestore.MergeAll)
+}
+
+func (t *firestoreTransaction) Delete(path string) error {
+	return t.tx.Delete(t.client.Doc(path))
+}
+
+// IncrementWithinLimit reads and increments the counter in one transaction,
+// so concurrent callers cannot push it past limit
+func (r *FirestoreRepository) IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error) {
+	client := r.clientFor(ctx)
+	ref := client.Doc(path)
+	var allowed bool
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		allowed = false
+		snap, err := tx.Get(ref)
+		if err != nil {
+			return wrapError("get", path, err)
+		}
+		current := counterValue(snap.Data()[field])
+		if current+delta > limit {
+			return nil
+		}
+		data := make(map[string]interface{}, len(updates)+1)
+		for k, v := range updates {
+			data[k] = v
+		}
+		data[field] = current + delta
+		allowed = true
+		return tx.Set(ref, data, firestore.MergeAll)
+	})
+	if err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
+
+// Increment atomically adds delta to a numeric field, creating the document
+// and field if needed
+func (r *FirestoreRepository) Increment(ctx context.Context, path, field string, delta int64) error {
+	return r.setTransform(ctx, "increment", path, field, firestore.Increment(delta))
+}
+
+// ArrayUnion atomically adds each value not already in an array field,
+// creating the document and field if needed
+func (r *FirestoreRepository) ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error {
+	return r.setTransform(ctx, "update", path, field, firestore.ArrayUnion(values...))
+}
+
+// ArrayRemove atomically removes every instance of each value from an array
+// field; returns ErrNotFound for a missing document
+func (r *FirestoreRepository) ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error {
+	_, err := r.clientFor(ctx).Doc(path).Update(ctx, []firestore.Update{
+		{FieldPath: firestore.FieldPath{field}, Value: firestore.ArrayRemove(values...)},
+		{Path: "updatedAt", Value: time.Now()},
+	})
+	if err != nil {
+		return wrapError("update", path, err)
+	}
+	return nil
+}
+
+// setTransform merges a field transform into a document, creating it if needed.
+// Map keys are not split on dots, so field is always a top-level field.
+func (r *FirestoreRepository) setTransform(ctx context.Context, op, path, field string, transform interface{}) error {
+	_, err := r.clientFor(ctx).Doc(path).Set(ctx, map[string]interface{}{
+		field:       transform,
+		"updatedAt": time.Now(),
+	}, firestore.MergeAll)
+	if err != nil {
+		return wrapError(op, path, err)
+	}
+	return nil
+}
+
+// counterValue reads a stored counter, which Firestore returns as int64 or,
+// when written by a client SDK, float64
+func counterValue(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// CollectAllConsistent reads every query inside one read-only transaction, so
+// all results come from the same snapshot even while other writes commit
+func (r *FirestoreRepository) CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error) {
+	results := make([][]map[string]interface{}, len(queries))
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for i, query := range queries {
+			docs, err := tx.Documents(query).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to read query: %w", err)
+			}
+			data := make([]map[string]interface{}, 0, len(docs))
+			for _, doc := range docs {
+				data = append(data, doc.Data())
+			}
+			results[i] = data
+		}
+		return nil
+	}, firestore.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Count runs a count aggregation, which is billed per 1000 index entries
+// rather than per document read
+func (r *FirestoreRepository) Count(ctx context.Context, query firestore.Query) (int64, error) {
+	results, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := results["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result type %T", results["count"])
+	}
+	return value.GetIntegerValue(), nil
+}
+
 // GetCollection retrieves all documents in a collection
 func (r *FirestoreRepository) GetCollection(ctx context.Context, collectionPath string) ([]*firestore.DocumentSnapshot, error) {
 	return r.QueryCollection(ctx, collectionPath)
@@ -211,6 +587,14 @@ func (r *FirestoreRepository) GetCollection(ctx context.Context, collectionPath
 // QueryOption is a function that modifies a Firestore query (alias for interfaces.QueryOption)
 type QueryOption = interfaces.QueryOption
 
+// Filter is a single where clause (alias for interfaces.Filter)
+type Filter = interfaces.Filter
+
+// Eq returns an equality filter for ListWhere
+func Eq(field string, value interface{}) Filter {
+	return Filter{Field: field, Op: "==", Value: value}
+}
+
 // Where adds a where clause to the query
 func Where(field string, op string, value interface{}) QueryOption {
 	return func(q firestore.Query) firestore.Query {
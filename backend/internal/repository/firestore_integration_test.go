@@ -0,0 +1,62 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+func TestFirestoreRepository_IncrementConcurrent_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	repo := NewFirestoreRepository(client)
+	ctx := context.Background()
+	path := "users/" + uid + "/stats/library"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.Increment(ctx, path, "photos", 1))
+		}()
+	}
+	wg.Wait()
+
+	doc, err := repo.Get(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), doc["photos"])
+}
+
+func TestFirestoreRepository_ArrayUnionRemove_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	repo := NewFirestoreRepository(client)
+	ctx := context.Background()
+	path := "users/" + uid + "/photoLibrary/p1"
+
+	var wg sync.WaitGroup
+	for _, tag := range []string{"beach", "city", "beach"} {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+			assert.NoError(t, repo.ArrayUnion(ctx, path, "tags", tag))
+		}(tag)
+	}
+	wg.Wait()
+
+	require.NoError(t, repo.ArrayRemove(ctx, path, "tags", "city"))
+	doc, err := repo.Get(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"beach"}, doc["tags"])
+
+	err = repo.ArrayRemove(ctx, "users/"+uid+"/photoLibrary/missing", "tags", "beach")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
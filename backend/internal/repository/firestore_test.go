@@ -5,10 +5,50 @@ import (
 	"fmt"
 	"testing"
 
+	"cloud.google.com/go/firestore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// offlineDatabaseClient opens a client on databaseID that never dials out,
+// enough to inspect the resource names it builds
+func offlineDatabaseClient(t *testing.T, databaseID string) *firestore.Client {
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "localhost:0")
+	client, err := firestore.NewClientWithDatabase(context.Background(), "test-project", databaseID)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestFirestoreRepository_TargetsConfiguredDatabase(t *testing.T) {
+	primary := offlineDatabaseClient(t, "eu-primary")
+	repo := NewFirestoreRepository(primary)
+
+	assert.Equal(t, "projects/test-project/databases/eu-primary/documents/users/u1/tasks/t1",
+		repo.clientFor(context.Background()).Doc("users/u1/tasks/t1").Path)
+	assert.Contains(t, repo.Collection("users/u1/tasks").Path, "/databases/eu-primary/")
+}
+
+func TestFirestoreRepository_DatabaseOverride(t *testing.T) {
+	primary := offlineDatabaseClient(t, "eu-primary")
+	secondary := offlineDatabaseClient(t, "test-secondary")
+	repo := NewFirestoreRepositoryWithDatabases(primary, map[string]*firestore.Client{"test-secondary": secondary})
+
+	assert.True(t, repo.HasDatabase("test-secondary"))
+	assert.False(t, repo.HasDatabase("eu-primary"))
+
+	ctx := context.Background()
+	assert.Equal(t, "", DatabaseFromContext(ctx))
+	assert.Same(t, primary, repo.clientFor(ctx))
+
+	override := WithDatabase(ctx, "test-secondary")
+	assert.Equal(t, "test-secondary", DatabaseFromContext(override))
+	assert.Contains(t, repo.clientFor(override).Doc("users/u1/tasks/t1").Path, "/databases/test-secondary/")
+
+	assert.Same(t, primary, repo.clientFor(WithDatabase(ctx, "unknown")), "unknown databases fall back to the default")
+	assert.Same(t, primary, repo.Client(), "Client always returns the default database")
+}
+
 func TestNewFirestoreRepository(t *testing.T) {
 	repo := NewFirestoreRepository(nil)
 
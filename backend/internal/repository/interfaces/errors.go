@@ -0,0 +1,19 @@
+package interfaces
+
+import "errors"
+
+// Sentinel errors returned (wrapped) by Repository implementations.
+// Use errors.Is to check for them.
+var (
+	// ErrNotFound indicates the requested document does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrPermissionDenied indicates the caller may not access the document
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrInvalidOrdering indicates a requested ordering cannot be expressed as a Firestore query
+	ErrInvalidOrdering = errors.New("invalid ordering")
+
+	// ErrIndexRequired indicates the query needs a composite index that does not exist
+	ErrIndexRequired = errors.New("composite index required")
+)
@@ -13,7 +13,19 @@ type Repository interface {
 	Create(ctx context.Context, path string, data map[string]interface{}) error
 	Update(ctx context.Context, path string, data map[string]interface{}) error
 	Delete(ctx context.Context, path string) error
+	// GetAll reads the documents at paths in one round trip. The result is
+	// aligned with paths, with nil for a document that does not exist.
+	GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error)
+	// DeleteAll deletes the documents at paths in batched writes of up to
+	// 500, stopping at the first batch that fails. Returns how many paths,
+	// from the start, were deleted.
+	DeleteAll(ctx context.Context, paths []string) (int, error)
 	List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error)
+	ListOrdered(ctx context.Context, collectionPath string, orderings []Ordering, limit int) ([]map[string]interface{}, error)
+	ListWhere(ctx context.Context, collectionPath string, filters []Filter, limit int) ([]map[string]interface{}, error)
+	// ListWhereOrdered retrieves documents matching every filter, sorted by
+	// orderings; the combination may need a composite index
+	ListWhereOrdered(ctx context.Context, collectionPath string, filters []Filter, orderings []Ordering, limit int) ([]map[string]interface{}, error)
 
 	// Firestore-specific operations (for compatibility with existing code)
 	GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error)
@@ -23,11 +35,66 @@ type Repository interface {
 	CreateDocument(ctx context.Context, path string, data map[string]interface{}) error
 	QueryCollection(ctx context.Context, collectionPath string, opts ...QueryOption) ([]*firestore.DocumentSnapshot, error)
 
+	// RunTransaction runs fn in a transaction, retrying it on contention. fn
+	// must be safe to run more than once; its writes are applied only when it
+	// returns nil.
+	RunTransaction(ctx context.Context, fn func(ctx context.Context, tx Transaction) error) error
+	// IncrementWithinLimit adds delta to a numeric field in a transaction unless
+	// the result would exceed limit, writing updates alongside it. Reports false
+	// without writing when over the limit; returns ErrNotFound for a missing document.
+	IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error)
+
+	// Atomic field operations. Each writes a single top-level field (the name
+	// is not split on dots) plus updatedAt, so concurrent calls never lose
+	// each other's changes.
+
+	// Increment adds delta to a numeric field, creating the document and
+	// field if needed
+	Increment(ctx context.Context, path, field string, delta int64) error
+	// ArrayUnion adds each value not already in an array field, creating the
+	// document and field if needed
+	ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error
+	// ArrayRemove removes every instance of each value from an array field;
+	// returns ErrNotFound for a missing document
+	ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error
+
+	// Iteration helpers (handle iterator cleanup and error propagation)
+	ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error
+	CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error)
+	// CollectAllConsistent returns the data of every document returned by each
+	// query, all read in one read-only transaction so they share a snapshot
+	CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error)
+	// Count returns the number of documents matching the query without reading them
+	Count(ctx context.Context, query firestore.Query) (int64, error)
+
 	// Collection and batch operations
 	Collection(path string) *firestore.CollectionRef
 	Batch() *firestore.WriteBatch
 	Client() *firestore.Client
 }
 
+// Transaction reads and writes documents by path inside RunTransaction. As in
+// Firestore, all reads must happen before the first write.
+type Transaction interface {
+	// Get returns a document's data, or ErrNotFound if it does not exist
+	Get(path string) (map[string]interface{}, error)
+	// Set merges data into the document, creating it if needed
+	Set(path string, data map[string]interface{}) error
+	Delete(path string) error
+}
+
 // QueryOption is a function that modifies a Firestore query
 type QueryOption func(firestore.Query) firestore.Query
+
+// Filter is a single where clause; multiple filters are combined with AND
+type Filter struct {
+	Field string
+	Op    string // ==, !=, <, <=, >, >=
+	Value interface{}
+}
+
+// Ordering is a single orderBy clause; multiple orderings are applied in sequence
+type Ordering struct {
+	Field     string
+	Direction firestore.Direction
+}
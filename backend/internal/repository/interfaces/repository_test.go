@@ -83,6 +83,7 @@ func TestRepositoryInterface_DocumentOperations(t *testing.T) {
 	// - Create(ctx context.Context, path string, data map[string]interface{}) error
 	// - Update(ctx context.Context, path string, data map[string]interface{}) error
 	// - Delete(ctx context.Context, path string) error
+	// - ListOrdered(ctx context.Context, collectionPath string, orderings []Ordering, limit int) ([]map[string]interface{}, error)
 	// - List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error)
 
 	assert.True(t, true)
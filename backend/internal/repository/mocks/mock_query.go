@@ -0,0 +1,431 @@
+package mocks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// queryDoc is a stored document returned by a query. name is the full
+// resource name Firestore orders __name__ by.
+type queryDoc struct {
+	path string
+	name string
+	data map[string]interface{}
+}
+
+func (d queryDoc) value(field string) (interface{}, bool) {
+	if field == firestore.DocumentID {
+		return d.name, true
+	}
+	return lookupField(d.data, field)
+}
+
+// runQuery evaluates query against Documents. The query is decoded from its
+// serialized form, so it must be built on a client, e.g. from Collection
+// with Client_ set. Collection, filters, order, cursors, offset, limit and
+// field selection are applied; OR filters are not supported.
+func (m *MockRepository) runQuery(query firestore.Query) ([]queryDoc, error) {
+	serialized, err := query.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	var req firestorepb.RunQueryRequest
+	if err := proto.Unmarshal(serialized, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode query: %w", err)
+	}
+	sq := req.GetStructuredQuery()
+	if sq == nil || len(sq.From) != 1 {
+		return nil, fmt.Errorf("mock query must select exactly one collection")
+	}
+	filters, err := queryFilters(sq.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parent is projects/{p}/databases/{d}/documents, then any parent document
+	root := req.Parent
+	if i := strings.Index(root, "/documents"); i >= 0 {
+		root = root[:i+len("/documents")]
+	}
+	parent := strings.TrimPrefix(strings.TrimPrefix(req.Parent, root), "/")
+	from := sq.From[0]
+
+	var docs []queryDoc
+	for path, data := range m.Documents {
+		if !inQueryCollection(path, parent, from.CollectionId, from.AllDescendants) {
+			continue
+		}
+		doc := queryDoc{path: path, name: root + "/" + path, data: data}
+		if matchesQueryFilters(doc, filters) && hasQueryOrderFields(doc, sq.OrderBy) {
+			docs = append(docs, doc)
+		}
+	}
+
+	// Ties are broken by document name in the direction of the last order
+	lastDesc := len(sq.OrderBy) > 0 && sq.OrderBy[len(sq.OrderBy)-1].Direction == firestorepb.StructuredQuery_DESCENDING
+	sort.Slice(docs, func(i, j int) bool {
+		if cmp := compareQueryPosition(docs[i], docs[j], sq.OrderBy); cmp != 0 {
+			return cmp < 0
+		}
+		if lastDesc {
+			return docs[i].name > docs[j].name
+		}
+		return docs[i].name < docs[j].name
+	})
+
+	results := make([]queryDoc, 0, len(docs))
+	for _, doc := range docs {
+		if withinCursors(doc, sq) {
+			results = append(results, doc)
+		}
+	}
+	if offset := int(sq.Offset); offset > 0 {
+		results = results[min(offset, len(results)):]
+	}
+	if sq.Limit != nil && int(sq.Limit.Value) < len(results) {
+		results = results[:sq.Limit.Value]
+	}
+
+	for i, doc := range results {
+		results[i].data = selectFields(doc.data, sq.Select)
+	}
+	return results, nil
+}
+
+// inQueryCollection reports whether the document at path is directly in the
+// collection, or for a collection group in any collection with that ID under
+// parent
+func inQueryCollection(path, parent, collectionID string, allDescendants bool) bool {
+	if !allDescendants {
+		collection := collectionID
+		if parent != "" {
+			collection = parent + "/" + collectionID
+		}
+		rest, ok := strings.CutPrefix(path, collection+"/")
+		return ok && rest != "" && !strings.Contains(rest, "/")
+	}
+	if parent != "" && !strings.HasPrefix(path, parent+"/") {
+		return false
+	}
+	segments := strings.Split(path, "/")
+	return len(segments)%2 == 0 && segments[len(segments)-2] == collectionID
+}
+
+// queryFilters flattens a query's where clause into filters that must all match
+func queryFilters(where *firestorepb.StructuredQuery_Filter) ([]interfaces.Filter, error) {
+	if where == nil {
+		return nil, nil
+	}
+	switch f := where.FilterType.(type) {
+	case *firestorepb.StructuredQuery_Filter_CompositeFilter:
+		if f.CompositeFilter.Op != firestorepb.StructuredQuery_CompositeFilter_AND {
+			return nil, fmt.Errorf("mock query does not support %s filters", f.CompositeFilter.Op)
+		}
+		var filters []interfaces.Filter
+		for _, sub := range f.CompositeFilter.Filters {
+			subFilters, err := queryFilters(sub)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, subFilters...)
+		}
+		return filters, nil
+	case *firestorepb.StructuredQuery_Filter_FieldFilter:
+		op, ok := fieldFilterOps[f.FieldFilter.Op]
+		if !ok {
+			return nil, fmt.Errorf("mock query does not support the %s operator", f.FieldFilter.Op)
+		}
+		return []interfaces.Filter{{
+			Field: f.FieldFilter.Field.GetFieldPath(),
+			Op:    op,
+			Value: fromProtoValue(f.FieldFilter.Value),
+		}}, nil
+	case *firestorepb.StructuredQuery_Filter_UnaryFilter:
+		filter := interfaces.Filter{Field: f.UnaryFilter.GetField().GetFieldPath()}
+		switch f.UnaryFilter.Op {
+		case firestorepb.StructuredQuery_UnaryFilter_IS_NULL:
+			filter.Op = "=="
+		case firestorepb.StructuredQuery_UnaryFilter_IS_NOT_NULL:
+			filter.Op = "is-not-null"
+		default:
+			return nil, fmt.Errorf("mock query does not support the %s operator", f.UnaryFilter.Op)
+		}
+		return []interfaces.Filter{filter}, nil
+	}
+	return nil, fmt.Errorf("mock query does not support filter %T", where.FilterType)
+}
+
+var fieldFilterOps = map[firestorepb.StructuredQuery_FieldFilter_Operator]string{
+	firestorepb.StructuredQuery_FieldFilter_EQUAL:                 "==",
+	firestorepb.StructuredQuery_FieldFilter_NOT_EQUAL:             "!=",
+	firestorepb.StructuredQuery_FieldFilter_LESS_THAN:             "<",
+	firestorepb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL:    "<=",
+	firestorepb.StructuredQuery_FieldFilter_GREATER_THAN:          ">",
+	firestorepb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL: ">=",
+	firestorepb.StructuredQuery_FieldFilter_ARRAY_CONTAINS:        "array-contains",
+	firestorepb.StructuredQuery_FieldFilter_ARRAY_CONTAINS_ANY:    "array-contains-any",
+	firestorepb.StructuredQuery_FieldFilter_IN:                    "in",
+	firestorepb.StructuredQuery_FieldFilter_NOT_IN:                "not-in",
+}
+
+func matchesQueryFilters(doc queryDoc, filters []interfaces.Filter) bool {
+	for _, f := range filters {
+		value, ok := doc.value(f.Field)
+		if !matchesFilter(value, ok, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasQueryOrderFields reports whether doc has every ordered field; like
+// Firestore, documents missing one are not returned
+func hasQueryOrderFields(doc queryDoc, orders []*firestorepb.StructuredQuery_Order) bool {
+	for _, order := range orders {
+		if _, ok := doc.value(order.Field.GetFieldPath()); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareQueryPosition orders a before b by the query's order clauses
+func compareQueryPosition(a, b queryDoc, orders []*firestorepb.StructuredQuery_Order) int {
+	for _, order := range orders {
+		av, _ := a.value(order.Field.GetFieldPath())
+		bv, _ := b.value(order.Field.GetFieldPath())
+		if cmp := compareValues(av, bv); cmp != 0 {
+			if order.Direction == firestorepb.StructuredQuery_DESCENDING {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareCursor orders doc against a cursor's values, which follow the
+// query's order clauses
+func compareCursor(doc queryDoc, cursor *firestorepb.Cursor, orders []*firestorepb.StructuredQuery_Order) int {
+	for i, value := range cursor.Values {
+		if i >= len(orders) {
+			break
+		}
+		docValue, _ := doc.value(orders[i].Field.GetFieldPath())
+		if cmp := compareValues(docValue, fromProtoValue(value)); cmp != 0 {
+			if orders[i].Direction == firestorepb.StructuredQuery_DESCENDING {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
+
+// withinCursors applies StartAt/StartAfter and EndAt/EndBefore. Before is set
+// for StartAt and EndBefore.
+func withinCursors(doc queryDoc, sq *firestorepb.StructuredQuery) bool {
+	if sq.StartAt != nil {
+		cmp := compareCursor(doc, sq.StartAt, sq.OrderBy)
+		if cmp < 0 || (cmp == 0 && !sq.StartAt.Before) {
+			return false
+		}
+	}
+	if sq.EndAt != nil {
+		cmp := compareCursor(doc, sq.EndAt, sq.OrderBy)
+		if cmp > 0 || (cmp == 0 && sq.EndAt.Before) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField returns the value at a dot-separated field path
+func lookupField(data map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = fields[strings.Trim(segment, "`")]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// selectFields copies data, keeping only the selected fields when the query
+// has a projection
+func selectFields(data map[string]interface{}, projection *firestorepb.StructuredQuery_Projection) map[string]interface{} {
+	selected := make(map[string]interface{}, len(data))
+	if projection == nil {
+		for k, v := range data {
+			selected[k] = v
+		}
+		return selected
+	}
+	for _, field := range projection.Fields {
+		path := field.GetFieldPath()
+		value, ok := lookupField(data, path)
+		if !ok {
+			continue
+		}
+		segments := strings.Split(path, ".")
+		parent := selected
+		for _, segment := range segments[:len(segments)-1] {
+			segment = strings.Trim(segment, "`")
+			child, ok := parent[segment].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				parent[segment] = child
+			}
+			parent = child
+		}
+		parent[strings.Trim(segments[len(segments)-1], "`")] = value
+	}
+	return selected
+}
+
+// fromProtoValue converts a query value to the Go type stored in Documents.
+// References become their full resource name, which __name__ compares to.
+func fromProtoValue(value *firestorepb.Value) interface{} {
+	switch v := value.GetValueType().(type) {
+	case *firestorepb.Value_BooleanValue:
+		return v.BooleanValue
+	case *firestorepb.Value_IntegerValue:
+		return v.IntegerValue
+	case *firestorepb.Value_DoubleValue:
+		return v.DoubleValue
+	case *firestorepb.Value_TimestampValue:
+		return v.TimestampValue.AsTime()
+	case *firestorepb.Value_StringValue:
+		return v.StringValue
+	case *firestorepb.Value_ReferenceValue:
+		return v.ReferenceValue
+	case *firestorepb.Value_BytesValue:
+		return v.BytesValue
+	case *firestorepb.Value_ArrayValue:
+		items := make([]interface{}, len(v.ArrayValue.GetValues()))
+		for i, item := range v.ArrayValue.GetValues() {
+			items[i] = fromProtoValue(item)
+		}
+		return items
+	case *firestorepb.Value_MapValue:
+		fields := make(map[string]interface{}, len(v.MapValue.GetFields()))
+		for k, item := range v.MapValue.GetFields() {
+			fields[k] = fromProtoValue(item)
+		}
+		return fields
+	}
+	return nil
+}
+
+// snapshot builds the DocumentSnapshot the client would return for data at
+// path. DocumentSnapshot has no exported constructor, so its document and
+// client are set through reflection.
+func (m *MockRepository) snapshot(path string, data map[string]interface{}) (*firestore.DocumentSnapshot, error) {
+	if m.Client_ == nil {
+		return nil, fmt.Errorf("mock snapshots need Client_ to be set")
+	}
+	ref := m.Client_.Doc(path)
+	if ref == nil {
+		return nil, fmt.Errorf("invalid document path %s", path)
+	}
+	fields, err := toProtoFields(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert document at %s: %w", path, err)
+	}
+
+	snap := &firestore.DocumentSnapshot{Ref: ref}
+	setUnexportedField(snap, "c", m.Client_)
+	setUnexportedField(snap, "proto", &firestorepb.Document{Name: ref.Path, Fields: fields})
+	return snap, nil
+}
+
+func setUnexportedField(snap *firestore.DocumentSnapshot, name string, value interface{}) {
+	field := reflect.ValueOf(snap).Elem().FieldByName(name)
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(value))
+}
+
+func toProtoFields(data map[string]interface{}) (map[string]*firestorepb.Value, error) {
+	fields := make(map[string]*firestorepb.Value, len(data))
+	for k, v := range data {
+		value, err := toProtoValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", k, err)
+		}
+		fields[k] = value
+	}
+	return fields, nil
+}
+
+// toProtoValue converts a stored value to a document value. Slices and maps
+// of any element type are accepted, as tests store them in many shapes.
+func toProtoValue(v interface{}) (*firestorepb.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_NullValue{}}, nil
+	case bool:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_BooleanValue{BooleanValue: v}}, nil
+	case int:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_IntegerValue{IntegerValue: int64(v)}}, nil
+	case int32:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_IntegerValue{IntegerValue: int64(v)}}, nil
+	case int64:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_IntegerValue{IntegerValue: v}}, nil
+	case float32:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_DoubleValue{DoubleValue: float64(v)}}, nil
+	case float64:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_DoubleValue{DoubleValue: v}}, nil
+	case string:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_StringValue{StringValue: v}}, nil
+	case []byte:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_BytesValue{BytesValue: v}}, nil
+	case time.Time:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_TimestampValue{TimestampValue: timestamppb.New(v)}}, nil
+	case *firestore.DocumentRef:
+		return &firestorepb.Value{ValueType: &firestorepb.Value_ReferenceValue{ReferenceValue: v.Path}}, nil
+	case map[string]interface{}:
+		fields, err := toProtoFields(v)
+		if err != nil {
+			return nil, err
+		}
+		return &firestorepb.Value{ValueType: &firestorepb.Value_MapValue{MapValue: &firestorepb.MapValue{Fields: fields}}}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]*firestorepb.Value, rv.Len())
+		for i := range values {
+			value, err := toProtoValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return &firestorepb.Value{ValueType: &firestorepb.Value_ArrayValue{ArrayValue: &firestorepb.ArrayValue{Values: values}}}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			fields := make(map[string]interface{}, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				fields[iter.Key().String()] = iter.Value().Interface()
+			}
+			return toProtoValue(fields)
+		}
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
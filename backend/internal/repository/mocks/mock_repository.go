@@ -2,7 +2,11 @@ package mocks
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/firestore"
 
@@ -14,6 +18,14 @@ type MockRepository struct {
 	// Storage for mock data
 	Documents map[string]map[string]interface{} // path -> data
 	Client_   *firestore.Client
+
+	// QueryErr, when set, is returned by ForEach, Count and CollectAll in
+	// place of evaluating the query against Documents
+	QueryErr error
+
+	// mu serializes transactions and the atomic field operations, which tests
+	// call concurrently
+	mu sync.Mutex
 }
 
 // Ensure MockRepository implements interfaces.Repository
@@ -47,12 +59,12 @@ func (m *MockRepository) Batch() *firestore.WriteBatch {
 	return nil
 }
 
-// Get retrieves a document data
+// Get retrieves a document data, returning interfaces.ErrNotFound if it does not exist
 func (m *MockRepository) Get(ctx context.Context, path string) (map[string]interface{}, error) {
 	if data, ok := m.Documents[path]; ok {
 		return data, nil
 	}
-	return nil, nil
+	return nil, fmt.Errorf("failed to get document at %s: %w", path, interfaces.ErrNotFound)
 }
 
 // Create creates a new document
@@ -79,6 +91,23 @@ func (m *MockRepository) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
+// GetAll returns the documents at paths, nil where one does not exist
+func (m *MockRepository) GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error) {
+	docs := make([]map[string]interface{}, len(paths))
+	for i, path := range paths {
+		docs[i] = m.Documents[path]
+	}
+	return docs, nil
+}
+
+// DeleteAll deletes the documents at paths
+func (m *MockRepository) DeleteAll(ctx context.Context, paths []string) (int, error) {
+	for _, path := range paths {
+		delete(m.Documents, path)
+	}
+	return len(paths), nil
+}
+
 // List retrieves documents from a collection
 func (m *MockRepository) List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
@@ -93,10 +122,224 @@ func (m *MockRepository) List(ctx context.Context, collectionPath string, limit
 	return results, nil
 }
 
-// GetDocument retrieves a document snapshot
+// ListOrdered retrieves documents from a collection sorted in memory.
+// Like Firestore, documents missing an ordered field are excluded.
+func (m *MockRepository) ListOrdered(ctx context.Context, collectionPath string, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	for _, data := range m.GetCollectionDocuments(collectionPath) {
+		if hasFields(data, orderings) {
+			results = append(results, data)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		for _, o := range orderings {
+			cmp := compareValues(results[i][o.Field], results[j][o.Field])
+			if cmp == 0 {
+				continue
+			}
+			if o.Direction == firestore.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ListWhere retrieves documents from a collection matching every filter,
+// evaluated in memory. Like Firestore, documents missing a filtered field never match.
+func (m *MockRepository) ListWhere(ctx context.Context, collectionPath string, filters []interfaces.Filter, limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	for _, data := range m.GetCollectionDocuments(collectionPath) {
+		if matchesFilters(data, filters) {
+			results = append(results, data)
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// ListWhereOrdered filters then sorts in memory, with the same rules as
+// ListWhere and ListOrdered
+func (m *MockRepository) ListWhereOrdered(ctx context.Context, collectionPath string, filters []interfaces.Filter, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	ordered, err := m.ListOrdered(ctx, collectionPath, orderings, 0)
+	if err != nil {
+		return nil, err
+	}
+	var results []map[string]interface{}
+	for _, data := range ordered {
+		if matchesFilters(data, filters) {
+			results = append(results, data)
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+func matchesFilters(data map[string]interface{}, filters []interfaces.Filter) bool {
+	for _, f := range filters {
+		value, ok := data[f.Field]
+		if !matchesFilter(value, ok, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesFilter reports whether a field value, present when ok, satisfies f
+func matchesFilter(value interface{}, ok bool, f interfaces.Filter) bool {
+	if !ok {
+		return false
+	}
+	switch f.Op {
+	case "array-contains":
+		return arrayContains(value, f.Value)
+	case "array-contains-any":
+		for _, candidate := range arrayValues(f.Value) {
+			if arrayContains(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case "in":
+		return arrayContains(f.Value, value)
+	case "not-in":
+		return value != nil && !arrayContains(f.Value, value)
+	case "is-not-null":
+		return value != nil
+	}
+	if typeRank(value) != typeRank(f.Value) {
+		return false
+	}
+	cmp := compareValues(value, f.Value)
+	switch f.Op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func arrayContains(array, value interface{}) bool {
+	switch items := array.(type) {
+	case []interface{}:
+		for _, item := range items {
+			if typeRank(item) == typeRank(value) && compareValues(item, value) == 0 {
+				return true
+			}
+		}
+	case []string:
+		s, ok := value.(string)
+		for _, item := range items {
+			if ok && item == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasFields(data map[string]interface{}, orderings []interfaces.Ordering) bool {
+	for _, o := range orderings {
+		if _, ok := data[o.Field]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues orders values of the same kind; mixed kinds fall back to
+// Firestore's type ordering (bool < number < timestamp < string)
+func compareValues(a, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(av, b.(string))
+	}
+
+	af, bf := toNumber(a), toNumber(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int, int64, float64:
+		return 2
+	case time.Time:
+		return 3
+	case string:
+		return 4
+	}
+	return 5
+}
+
+func toNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// GetDocument retrieves a document snapshot. Without Client_ no snapshot can
+// be built, so existing documents return nil; tests should use Get() instead.
 func (m *MockRepository) GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error) {
-	// For mock purposes, return nil - tests should use Get() instead
-	return nil, nil
+	data, ok := m.Documents[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to get document at %s: %w", path, interfaces.ErrNotFound)
+	}
+	if m.Client_ == nil {
+		return nil, nil
+	}
+	return m.snapshot(path, data)
 }
 
 // SetDocument sets a document with merge
@@ -125,6 +368,248 @@ func (m *MockRepository) QueryCollection(ctx context.Context, collectionPath str
 	return []*firestore.DocumentSnapshot{}, nil
 }
 
+// ForEach evaluates the query against Documents and runs fn for each match in
+// query order, stopping at the first error. Returns QueryErr if set.
+func (m *MockRepository) ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error {
+	if m.QueryErr != nil {
+		return m.QueryErr
+	}
+	docs, err := m.runQuery(query)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		snap, err := m.snapshot(doc.path, doc.data)
+		if err != nil {
+			return err
+		}
+		if err := fn(snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the number of Documents matching the query, or QueryErr if set
+func (m *MockRepository) Count(ctx context.Context, query firestore.Query) (int64, error) {
+	if m.QueryErr != nil {
+		return 0, m.QueryErr
+	}
+	docs, err := m.runQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
+
+// CollectAll returns copies of the Documents matching the query, or QueryErr if set
+func (m *MockRepository) CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error) {
+	if m.QueryErr != nil {
+		return nil, m.QueryErr
+	}
+	docs, err := m.runQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, doc.data)
+	}
+	return results, nil
+}
+
+// RunTransaction runs fn once. Writes are buffered and applied only when fn
+// returns nil, so a failed transaction leaves Documents untouched.
+// Transactions hold mu, so concurrent ones are serialized as Firestore's are.
+func (m *MockRepository) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx interfaces.Transaction) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &mockTransaction{repo: m}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	for _, write := range tx.writes {
+		if write.data == nil {
+			delete(m.Documents, write.path)
+			continue
+		}
+		existing, ok := m.Documents[write.path]
+		if !ok {
+			existing = make(map[string]interface{}, len(write.data))
+			m.Documents[write.path] = existing
+		}
+		for k, v := range write.data {
+			existing[k] = v
+		}
+	}
+	return nil
+}
+
+// mockTransaction buffers writes until the transaction commits. A write
+// with nil data is a delete.
+type mockTransaction struct {
+	repo   *MockRepository
+	writes []mockWrite
+}
+
+type mockWrite struct {
+	path string
+	data map[string]interface{}
+}
+
+func (t *mockTransaction) Get(path string) (map[string]interface{}, error) {
+	if len(t.writes) > 0 {
+		return nil, fmt.Errorf("read of %s after write in transaction", path)
+	}
+	data, ok := t.repo.Documents[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to get document at %s: %w", path, interfaces.ErrNotFound)
+	}
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+	return copied, nil
+}
+
+func (t *mockTransaction) Set(path string, data map[string]interface{}) error {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	t.writes = append(t.writes, mockWrite{path: path, data: data})
+	return nil
+}
+
+func (t *mockTransaction) Delete(path string) error {
+	t.writes = append(t.writes, mockWrite{path: path})
+	return nil
+}
+
+// IncrementWithinLimit adds delta to the field unless the result would exceed limit
+func (m *MockRepository) IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error) {
+	existing, ok := m.Documents[path]
+	if !ok {
+		return false, fmt.Errorf("failed to get document at %s: %w", path, interfaces.ErrNotFound)
+	}
+	var current int64
+	switch v := existing[field].(type) {
+	case int64:
+		current = v
+	case int:
+		current = int64(v)
+	case float64:
+		current = int64(v)
+	}
+	if current+delta > limit {
+		return false, nil
+	}
+	for k, v := range updates {
+		existing[k] = v
+	}
+	existing[field] = current + delta
+	return true, nil
+}
+
+// Increment adds delta to a numeric field, creating the document and field if needed
+func (m *MockRepository) Increment(ctx context.Context, path, field string, delta int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc := m.documentForWrite(path)
+	var current int64
+	switch v := doc[field].(type) {
+	case int64:
+		current = v
+	case int:
+		current = int64(v)
+	case float64:
+		current = int64(v)
+	}
+	doc[field] = current + delta
+	doc["updatedAt"] = time.Now()
+	return nil
+}
+
+// ArrayUnion adds each value not already in an array field, creating the
+// document and field if needed
+func (m *MockRepository) ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc := m.documentForWrite(path)
+	current := arrayValues(doc[field])
+	for _, value := range values {
+		if !arrayContains(current, value) {
+			current = append(current, value)
+		}
+	}
+	doc[field] = current
+	doc["updatedAt"] = time.Now()
+	return nil
+}
+
+// ArrayRemove removes every instance of each value from an array field;
+// returns interfaces.ErrNotFound for a missing document
+func (m *MockRepository) ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc, ok := m.Documents[path]
+	if !ok {
+		return fmt.Errorf("failed to update document at %s: %w", path, interfaces.ErrNotFound)
+	}
+	remaining := []interface{}{}
+	for _, item := range arrayValues(doc[field]) {
+		if !arrayContains(values, item) {
+			remaining = append(remaining, item)
+		}
+	}
+	doc[field] = remaining
+	doc["updatedAt"] = time.Now()
+	return nil
+}
+
+// documentForWrite returns the stored document at path, creating an empty one
+func (m *MockRepository) documentForWrite(path string) map[string]interface{} {
+	doc, ok := m.Documents[path]
+	if !ok {
+		doc = map[string]interface{}{}
+		m.Documents[path] = doc
+	}
+	return doc
+}
+
+// arrayValues copies a stored array; anything else is treated as empty, as
+// Firestore replaces non-array values on a union
+func arrayValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return append([]interface{}{}, v...)
+	case []string:
+		result := make([]interface{}, len(v))
+		for i, s := range v {
+			result[i] = s
+		}
+		return result
+	}
+	return []interface{}{}
+}
+
+// CollectAllConsistent returns CollectAll's results for every query, or
+// QueryErr if set
+func (m *MockRepository) CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error) {
+	results := make([][]map[string]interface{}, len(queries))
+	for i, query := range queries {
+		data, err := m.CollectAll(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = data
+	}
+	return results, nil
+}
+
 // AddDocument is a helper for tests to add mock data
 func (m *MockRepository) AddDocument(path string, data map[string]interface{}) {
 	m.Documents[path] = data
@@ -2,10 +2,17 @@ package mocks
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
+	"cloud.google.com/go/firestore"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
 )
 
 func TestNewMockRepository(t *testing.T) {
@@ -63,7 +70,7 @@ func TestMockRepository_Get_NonExistentDocument(t *testing.T) {
 
 	result, err := repo.Get(ctx, "users/nonexistent")
 
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
 	assert.Nil(t, result)
 }
 
@@ -73,10 +80,20 @@ func TestMockRepository_Get_EmptyPath(t *testing.T) {
 
 	result, err := repo.Get(ctx, "")
 
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
 	assert.Nil(t, result)
 }
 
+func TestMockRepository_GetDocument_NonExistentDocument(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+
+	snap, err := repo.GetDocument(ctx, "users/nonexistent")
+
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+	assert.Nil(t, snap)
+}
+
 // Create operation tests
 func TestMockRepository_Create_NewDocument(t *testing.T) {
 	repo := NewMockRepository()
@@ -482,3 +499,272 @@ func TestMockRepository_ConcurrentOperations(t *testing.T) {
 	assert.Equal(t, 1, len(repo.Documents))
 	assert.Equal(t, "active", repo.Documents["users/user1"]["status"])
 }
+
+func TestMockRepository_IncrementWithinLimit(t *testing.T) {
+	repo := NewMockRepository()
+	repo.AddDocument("sessions/s1", map[string]interface{}{"count": float64(1)})
+	ctx := context.Background()
+
+	allowed, err := repo.IncrementWithinLimit(ctx, "sessions/s1", "count", 2, 3, map[string]interface{}{"touched": true})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int64(3), repo.Documents["sessions/s1"]["count"])
+	assert.Equal(t, true, repo.Documents["sessions/s1"]["touched"])
+
+	allowed, err = repo.IncrementWithinLimit(ctx, "sessions/s1", "count", 1, 3, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(3), repo.Documents["sessions/s1"]["count"])
+
+	_, err = repo.IncrementWithinLimit(ctx, "sessions/missing", "count", 1, 3, nil)
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+// Iteration helper tests
+func TestMockRepository_RunTransaction(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+	repo.AddDocument("counters/a", map[string]interface{}{"n": int64(1), "keep": true})
+	repo.AddDocument("counters/b", map[string]interface{}{"n": int64(2)})
+
+	// Writes are dropped when the transaction fails
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		require.NoError(t, tx.Set("counters/a", map[string]interface{}{"n": int64(10)}))
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	assert.Equal(t, int64(1), repo.Documents["counters/a"]["n"])
+
+	err = repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		a, err := tx.Get("counters/a")
+		require.NoError(t, err)
+		_, err = tx.Get("counters/missing")
+		assert.ErrorIs(t, err, interfaces.ErrNotFound)
+		require.NoError(t, tx.Set("counters/a", map[string]interface{}{"n": a["n"].(int64) + 1}))
+		return tx.Delete("counters/b")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), repo.Documents["counters/a"]["n"])
+	assert.Equal(t, true, repo.Documents["counters/a"]["keep"])
+	assert.NotContains(t, repo.Documents, "counters/b")
+}
+
+func newQueryRepository(t *testing.T) *MockRepository {
+	repo := NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	repo.AddDocument("users/u1/tasks/a", map[string]interface{}{"title": "First", "priority": int64(2), "tags": []interface{}{"home"}})
+	repo.AddDocument("users/u1/tasks/b", map[string]interface{}{"title": "Second", "priority": int64(1)})
+	repo.AddDocument("users/u1/tasks/c", map[string]interface{}{"title": "Third", "priority": int64(3), "tags": []interface{}{"work"}})
+	repo.AddDocument("users/u1/tasks/d", map[string]interface{}{"title": "Unranked"})
+	repo.AddDocument("users/u1/tasks/a/comments/x", map[string]interface{}{"priority": int64(9)})
+	repo.AddDocument("users/u2/tasks/e", map[string]interface{}{"title": "Other user", "priority": int64(1)})
+	return repo
+}
+
+func TestMockRepository_CollectAll_EvaluatesQuery(t *testing.T) {
+	repo := newQueryRepository(t)
+	ctx := context.Background()
+	tasks := repo.Collection("users/u1/tasks")
+
+	// Only direct children of the collection, ordered, without subcollections
+	results, err := repo.CollectAll(ctx, tasks.OrderBy("priority", firestore.Desc))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "Third", results[0]["title"])
+	assert.Equal(t, "Second", results[2]["title"])
+
+	results, err = repo.CollectAll(ctx, tasks.Where("priority", ">=", 2).Where("tags", "array-contains", "work"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Third", results[0]["title"])
+
+	results, err = repo.CollectAll(ctx, tasks.Where("priority", "in", []int{1, 3}).OrderBy("priority", firestore.Asc).Limit(1))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Second", results[0]["title"])
+
+	// Selected fields only, and mutating results leaves the stored documents alone
+	results, err = repo.CollectAll(ctx, tasks.Where("priority", "==", 2).Select("title"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, map[string]interface{}{"title": "First"}, results[0])
+	results[0]["title"] = "Changed"
+	assert.Equal(t, "First", repo.Documents["users/u1/tasks/a"]["title"])
+}
+
+func TestMockRepository_Count_ByCollectionAndFilters(t *testing.T) {
+	repo := newQueryRepository(t)
+	ctx := context.Background()
+
+	count, err := repo.Count(ctx, repo.Collection("users/u1/tasks").Query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	count, err = repo.Count(ctx, repo.Collection("users/u1/tasks").Where("priority", "<", 3))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = repo.Count(ctx, repo.Client_.CollectionGroup("tasks").Where("priority", "==", 1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = repo.Count(ctx, repo.Collection("users/u3/tasks").Query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMockRepository_CollectAll_Error(t *testing.T) {
+	repo := newQueryRepository(t)
+	repo.QueryErr = errors.New("query failed")
+
+	results, err := repo.CollectAll(context.Background(), repo.Collection("users/u1/tasks").Query)
+
+	assert.EqualError(t, err, "query failed")
+	assert.Nil(t, results)
+
+	// Queries not built on a client cannot be evaluated
+	repo.QueryErr = nil
+	_, err = repo.CollectAll(context.Background(), firestore.Query{})
+	assert.Error(t, err)
+}
+
+func TestMockRepository_ForEach_InvokesFnInOrder(t *testing.T) {
+	repo := newQueryRepository(t)
+	ctx := context.Background()
+	query := repo.Collection("users/u1/tasks").OrderBy("priority", firestore.Asc)
+
+	var ids, titles []string
+	err := repo.ForEach(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		ids = append(ids, doc.Ref.ID)
+		titles = append(titles, doc.Data()["title"].(string))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a", "c"}, ids)
+	assert.Equal(t, []string{"Second", "First", "Third"}, titles)
+
+	// Paging from a snapshot continues after it
+	cursor, err := repo.GetDocument(ctx, "users/u1/tasks/a")
+	require.NoError(t, err)
+	ids = nil
+	err = repo.ForEach(ctx, query.StartAfter(cursor), func(doc *firestore.DocumentSnapshot) error {
+		ids = append(ids, doc.Ref.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, ids)
+
+	// The first error from fn stops iteration
+	calls := 0
+	err = repo.ForEach(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		calls++
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
+
+func TestMockRepository_ForEach_PropagatesError(t *testing.T) {
+	repo := newQueryRepository(t)
+	called := false
+	fn := func(doc *firestore.DocumentSnapshot) error {
+		called = true
+		return nil
+	}
+
+	repo.QueryErr = errors.New("query failed")
+	assert.EqualError(t, repo.ForEach(context.Background(), repo.Collection("users/u1/tasks").Query, fn), "query failed")
+	assert.False(t, called)
+}
+
+func TestMockRepository_ListOrdered_TwoFields(t *testing.T) {
+	repo := NewMockRepository()
+	repo.AddDocument("users/u1/tasks/a", map[string]interface{}{"priority": 1, "dueDate": "2024-03-02"})
+	repo.AddDocument("users/u1/tasks/b", map[string]interface{}{"priority": 3, "dueDate": "2024-03-05"})
+	repo.AddDocument("users/u1/tasks/c", map[string]interface{}{"priority": 3, "dueDate": "2024-03-01"})
+	repo.AddDocument("users/u1/tasks/d", map[string]interface{}{"priority": 1, "dueDate": "2024-03-01"})
+	repo.AddDocument("users/u1/tasks/e", map[string]interface{}{"priority": 2})
+
+	orderings := []interfaces.Ordering{
+		{Field: "priority", Direction: firestore.Desc},
+		{Field: "dueDate", Direction: firestore.Asc},
+	}
+	results, err := repo.ListOrdered(context.Background(), "users/u1/tasks", orderings, 0)
+	require.NoError(t, err)
+
+	ids := make([]string, len(results))
+	for i, doc := range results {
+		ids[i] = doc["id"].(string)
+	}
+	// e is excluded because it has no dueDate
+	assert.Equal(t, []string{"c", "b", "d", "a"}, ids)
+
+	limited, err := repo.ListOrdered(context.Background(), "users/u1/tasks", orderings, 2)
+	require.NoError(t, err)
+	assert.Len(t, limited, 2)
+}
+
+func TestMockRepository_ListWhere(t *testing.T) {
+	repo := NewMockRepository()
+	repo.AddDocument("investments/a", map[string]interface{}{"uid": "u1", "portfolioId": "p1"})
+	repo.AddDocument("investments/b", map[string]interface{}{"uid": "u1", "portfolioId": "p2"})
+	repo.AddDocument("investments/c", map[string]interface{}{"uid": "u2", "portfolioId": "p1"})
+	repo.AddDocument("investments/d", map[string]interface{}{"portfolioId": "p1"})
+
+	results, err := repo.ListWhere(context.Background(), "investments", []interfaces.Filter{
+		{Field: "uid", Op: "==", Value: "u1"},
+		{Field: "portfolioId", Op: "==", Value: "p1"},
+	}, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a", results[0]["id"])
+
+	byUser, err := repo.ListWhere(context.Background(), "investments", []interfaces.Filter{
+		{Field: "uid", Op: "==", Value: "u1"},
+	}, 1)
+	require.NoError(t, err)
+	assert.Len(t, byUser, 1)
+}
+
+func TestMockRepository_IncrementConcurrent(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.Increment(ctx, "stats/library", "photos", 2))
+			assert.NoError(t, repo.Increment(ctx, "stats/library", "albums", -1))
+		}()
+	}
+	wg.Wait()
+
+	doc, err := repo.Get(ctx, "stats/library")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), doc["photos"])
+	assert.Equal(t, int64(-50), doc["albums"])
+}
+
+func TestMockRepository_ArrayUnionRemove(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, tag := range []string{"a", "b", "c", "a", "b"} {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+			assert.NoError(t, repo.ArrayUnion(ctx, "photos/p1", "tags", tag))
+		}(tag)
+	}
+	wg.Wait()
+	assert.ElementsMatch(t, []interface{}{"a", "b", "c"}, repo.Documents["photos/p1"]["tags"])
+
+	require.NoError(t, repo.ArrayRemove(ctx, "photos/p1", "tags", "a", "missing"))
+	assert.ElementsMatch(t, []interface{}{"b", "c"}, repo.Documents["photos/p1"]["tags"])
+
+	err := repo.ArrayRemove(ctx, "photos/none", "tags", "a")
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Ordering is a single orderBy clause (alias for interfaces.Ordering)
+type Ordering = interfaces.Ordering
+
+// MaxOrderFields caps the number of orderBy clauses in one query. Each extra
+// field needs its own composite index, so longer chains are rejected up front.
+const MaxOrderFields = 3
+
+// ParseOrderings parses comma-separated orderBy and orderDir query values.
+// orderDir may be empty (all ascending), a single direction applied to every
+// field, or one direction per field.
+func ParseOrderings(orderBy, orderDir string) ([]Ordering, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+
+	fields := splitList(orderBy)
+	dirs := splitList(orderDir)
+	if len(dirs) > 1 && len(dirs) != len(fields) {
+		return nil, fmt.Errorf("%w: got %d orderDir values for %d orderBy fields", ErrInvalidOrdering, len(dirs), len(fields))
+	}
+
+	orderings := make([]Ordering, len(fields))
+	for i, field := range fields {
+		dir := ""
+		switch len(dirs) {
+		case 1:
+			dir = dirs[0]
+		case len(fields):
+			dir = dirs[i]
+		}
+
+		direction, err := parseDirection(dir)
+		if err != nil {
+			return nil, err
+		}
+		orderings[i] = Ordering{Field: field, Direction: direction}
+	}
+
+	if err := ValidateOrderings(orderings); err != nil {
+		return nil, err
+	}
+	return orderings, nil
+}
+
+// ValidateOrderings rejects orderings Firestore cannot serve
+func ValidateOrderings(orderings []Ordering) error {
+	if len(orderings) > MaxOrderFields {
+		return fmt.Errorf("%w: at most %d orderBy fields are supported", ErrInvalidOrdering, MaxOrderFields)
+	}
+
+	seen := make(map[string]bool, len(orderings))
+	for i, o := range orderings {
+		switch {
+		case o.Field == "":
+			return fmt.Errorf("%w: empty orderBy field", ErrInvalidOrdering)
+		case seen[o.Field]:
+			return fmt.Errorf("%w: field %q is ordered more than once", ErrInvalidOrdering, o.Field)
+		case o.Field == firestore.DocumentID && i != len(orderings)-1:
+			return fmt.Errorf("%w: %s must be the last orderBy field", ErrInvalidOrdering, firestore.DocumentID)
+		}
+		seen[o.Field] = true
+	}
+	return nil
+}
+
+// OrderByAll applies each ordering in sequence
+func OrderByAll(orderings []Ordering) QueryOption {
+	return func(q firestore.Query) firestore.Query {
+		for _, o := range orderings {
+			q = q.OrderBy(o.Field, o.Direction)
+		}
+		return q
+	}
+}
+
+// IndexHint describes the composite index needed for an ordered query
+func IndexHint(collectionPath string, orderings []Ordering) string {
+	parts := make([]string, len(orderings))
+	for i, o := range orderings {
+		dir := "ASC"
+		if o.Direction == firestore.Desc {
+			dir = "DESC"
+		}
+		parts[i] = o.Field + " " + dir
+	}
+
+	collection := collectionPath
+	if idx := strings.LastIndex(collectionPath, "/"); idx >= 0 {
+		collection = collectionPath[idx+1:]
+	}
+	return fmt.Sprintf("create a composite index on %s (%s)", collection, strings.Join(parts, ", "))
+}
+
+func parseDirection(dir string) (firestore.Direction, error) {
+	switch strings.ToLower(dir) {
+	case "", "asc":
+		return firestore.Asc, nil
+	case "desc":
+		return firestore.Desc, nil
+	default:
+		return 0, fmt.Errorf("%w: orderDir must be asc or desc, got %q", ErrInvalidOrdering, dir)
+	}
+}
+
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
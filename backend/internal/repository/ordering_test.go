@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderings(t *testing.T) {
+	tests := []struct {
+		name     string
+		orderBy  string
+		orderDir string
+		want     []Ordering
+	}{
+		{name: "empty", orderBy: "", want: nil},
+		{
+			name:    "single field defaults to ascending",
+			orderBy: "createdAt",
+			want:    []Ordering{{Field: "createdAt", Direction: firestore.Asc}},
+		},
+		{
+			name:     "two fields with directions",
+			orderBy:  "priority, dueDate",
+			orderDir: "desc,asc",
+			want: []Ordering{
+				{Field: "priority", Direction: firestore.Desc},
+				{Field: "dueDate", Direction: firestore.Asc},
+			},
+		},
+		{
+			name:     "single direction applies to all fields",
+			orderBy:  "priority,dueDate",
+			orderDir: "DESC",
+			want: []Ordering{
+				{Field: "priority", Direction: firestore.Desc},
+				{Field: "dueDate", Direction: firestore.Desc},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOrderings(tt.orderBy, tt.orderDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseOrderings_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		orderBy  string
+		orderDir string
+	}{
+		{name: "mismatched direction count", orderBy: "a,b,c", orderDir: "asc,desc"},
+		{name: "unknown direction", orderBy: "a", orderDir: "up"},
+		{name: "empty field", orderBy: "a,,b"},
+		{name: "duplicate field", orderBy: "a,a"},
+		{name: "too many fields", orderBy: "a,b,c,d"},
+		{name: "document id not last", orderBy: "__name__,a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOrderings(tt.orderBy, tt.orderDir)
+			assert.ErrorIs(t, err, ErrInvalidOrdering)
+		})
+	}
+}
+
+func TestIndexHint(t *testing.T) {
+	hint := IndexHint("users/u1/tasks", []Ordering{
+		{Field: "priority", Direction: firestore.Desc},
+		{Field: "dueDate", Direction: firestore.Asc},
+	})
+
+	assert.Equal(t, "create a composite index on tasks (priority DESC, dueDate ASC)", hint)
+}
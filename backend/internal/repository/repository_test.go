@@ -82,7 +82,7 @@ func TestRepository_CreateAndDelete(t *testing.T) {
 
 	// Verify deleted
 	data, err = repo.Get(ctx, "users/bob")
-	require.NoError(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
 	assert.Nil(t, data)
 }
 
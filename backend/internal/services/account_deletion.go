@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Kinds of external integration listed in an account deletion manifest
+const (
+	DeletionIntegrationPlaid               = "plaid"
+	DeletionIntegrationStripe              = "stripe"
+	DeletionIntegrationNotificationChannel = "notification-channel"
+	DeletionIntegrationCalendarFeed        = "calendar-feed"
+	DeletionIntegrationIngestToken         = "ingest-token"
+)
+
+// DeletionIntegration is an external connection that deleting the account
+// would remove or disconnect. Detail names the bank, subscription status or
+// webhook kind where there is one.
+type DeletionIntegration struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AccountDeletionManifest lists what deleting the user's account would
+// remove. Computing it changes nothing.
+type AccountDeletionManifest struct {
+	DryRun         bool                  `json:"dryRun"`
+	Collections    map[string]int64      `json:"collections"`
+	TotalDocuments int64                 `json:"totalDocuments"`
+	Storage        []StoragePrefixUsage  `json:"storage"` // nil when Cloud Storage is unavailable
+	StorageObjects int                   `json:"storageObjects"`
+	StorageBytes   int64                 `json:"storageBytes"`
+	Integrations   []DeletionIntegration `json:"integrations"`
+	GeneratedAt    time.Time             `json:"generatedAt"`
+}
+
+// DeletionManifest is the dry run of deleting the user's account: the
+// per-collection document counts of the usage footprint, a fresh size of
+// each storage prefix and the external integrations tied to the user. It
+// only reads.
+func (s *AccountUsageService) DeletionManifest(ctx context.Context, uid string) (*AccountDeletionManifest, error) {
+	manifest := &AccountDeletionManifest{
+		DryRun:       true,
+		Integrations: []DeletionIntegration{},
+		GeneratedAt:  s.now(),
+	}
+
+	counts, err := s.countDocuments(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Collections = counts
+	for _, count := range counts {
+		manifest.TotalDocuments += count
+	}
+
+	// Storage is sized fresh rather than from the usage cache, since the
+	// manifest is read right before an irreversible delete
+	if s.storage != nil {
+		prefixes, err := s.storageByPrefix(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Storage = prefixes
+		for _, prefix := range prefixes {
+			manifest.StorageObjects += prefix.Objects
+			manifest.StorageBytes += prefix.Bytes
+		}
+	}
+
+	if manifest.Integrations, err = s.deletionIntegrations(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Account deletion manifest computed",
+		zap.String("uid", uid),
+		zap.Int64("documents", manifest.TotalDocuments),
+		zap.Int("storageObjects", manifest.StorageObjects),
+		zap.Int("integrations", len(manifest.Integrations)),
+	)
+	return manifest, nil
+}
+
+// deletionIntegrations lists the user's linked banks, Stripe customers,
+// outbound webhooks and token-authenticated feeds
+func (s *AccountUsageService) deletionIntegrations(ctx context.Context, uid string) ([]DeletionIntegration, error) {
+	integrations := []DeletionIntegration{}
+
+	items, err := s.repo.ListWhere(ctx, "plaidItems", []interfaces.Filter{{Field: "uid", Op: "==", Value: uid}}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Plaid items: %w", err)
+	}
+	for _, item := range items {
+		integrations = append(integrations, DeletionIntegration{
+			Kind:   DeletionIntegrationPlaid,
+			Detail: getStringField(item, "institutionName"),
+		})
+	}
+
+	customers, err := s.repo.ListWhere(ctx, StripeCustomersCollection, []interfaces.Filter{{Field: "uid", Op: "==", Value: uid}}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Stripe customers: %w", err)
+	}
+	if len(customers) > 0 {
+		status, err := s.optionalDocument(ctx, fmt.Sprintf("users/%s/subscriptionStatus/%s", uid, SubscriptionStatusDoc))
+		if err != nil {
+			return nil, err
+		}
+		for range customers {
+			integrations = append(integrations, DeletionIntegration{
+				Kind:   DeletionIntegrationStripe,
+				Detail: getStringField(status, "status"),
+			})
+		}
+	}
+
+	channels, err := s.repo.List(ctx, channelsPath(uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification channels: %w", err)
+	}
+	for _, channel := range channels {
+		integrations = append(integrations, DeletionIntegration{
+			Kind:   DeletionIntegrationNotificationChannel,
+			ID:     getStringField(channel, "id"),
+			Detail: getStringField(channel, "kind"),
+		})
+	}
+
+	for kind, path := range map[string]string{
+		DeletionIntegrationCalendarFeed: userCalendarFeedPath(uid),
+		DeletionIntegrationIngestToken:  userIngestTokenPath(uid),
+	} {
+		token, err := s.optionalDocument(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
+			integrations = append(integrations, DeletionIntegration{Kind: kind})
+		}
+	}
+
+	sort.SliceStable(integrations, func(i, j int) bool {
+		return integrations[i].Kind < integrations[j].Kind
+	})
+	return integrations, nil
+}
+
+// optionalDocument reads a document, returning nil when it does not exist
+func (s *AccountUsageService) optionalDocument(ctx context.Context, path string) (map[string]interface{}, error) {
+	doc, err := s.repo.Get(ctx, path)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return doc, nil
+}
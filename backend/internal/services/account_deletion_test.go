@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountUsageService_DeletionManifest(t *testing.T) {
+	storage := &fakeStorageSizer{sizes: map[string]int64{
+		"users/user1/":           2048,
+		"images/original/user1/": 1000,
+	}}
+	service, repo := newTestAccountUsageService(t, storage)
+	now := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"title": "Plan"})
+	repo.AddDocument("users/user1/tasks/t2", map[string]interface{}{"title": "Pack"})
+	repo.AddDocument("tasks/imported1", map[string]interface{}{"uid": "user1", "title": "Imported"})
+	repo.AddDocument("tasks/imported2", map[string]interface{}{"uid": "user2", "title": "Not mine"})
+	repo.AddDocument("users/user1/notes/n1", map[string]interface{}{"title": "Ideas"})
+	repo.AddDocument("users/user2/notes/n2", map[string]interface{}{"title": "Not mine"})
+	repo.AddDocument("plaidItems/item1", map[string]interface{}{"uid": "user1", "institutionName": "First Bank"})
+	repo.AddDocument("plaidItems/item2", map[string]interface{}{"uid": "user2", "institutionName": "Other Bank"})
+	repo.AddDocument("stripeCustomers/cus_1", map[string]interface{}{"uid": "user1"})
+	repo.AddDocument("users/user1/subscriptionStatus/"+SubscriptionStatusDoc, map[string]interface{}{"status": "active"})
+	repo.AddDocument("users/user1/notificationChannels/ch1", map[string]interface{}{"id": "ch1", "kind": "slack"})
+	repo.AddDocument("users/user1/ingestToken/current", map[string]interface{}{"token": "secret"})
+
+	before := make(map[string]map[string]interface{}, len(repo.Documents))
+	for path, doc := range repo.Documents {
+		copied := make(map[string]interface{}, len(doc))
+		for key, value := range doc {
+			copied[key] = value
+		}
+		before[path] = copied
+	}
+
+	manifest, err := service.DeletionManifest(context.Background(), "user1")
+	require.NoError(t, err)
+
+	// Nothing is deleted or changed
+	assert.Equal(t, before, repo.Documents)
+
+	assert.True(t, manifest.DryRun)
+	assert.Equal(t, now, manifest.GeneratedAt)
+
+	// tasks are counted under users/{uid} and as imported top-level documents
+	assert.Equal(t, int64(3), manifest.Collections["tasks"])
+	assert.Equal(t, int64(1), manifest.Collections["notes"])
+	assert.Equal(t, int64(0), manifest.Collections["goals"])
+	assert.Equal(t, int64(4), manifest.TotalDocuments)
+
+	require.Len(t, manifest.Storage, 4)
+	assert.Equal(t, StoragePrefixUsage{Prefix: "users/user1/", Bytes: 2048, Objects: 1}, manifest.Storage[0])
+	assert.Equal(t, 2, manifest.StorageObjects)
+	assert.Equal(t, int64(3048), manifest.StorageBytes)
+
+	assert.Equal(t, []DeletionIntegration{
+		{Kind: DeletionIntegrationIngestToken},
+		{Kind: DeletionIntegrationNotificationChannel, ID: "ch1", Detail: "slack"},
+		{Kind: DeletionIntegrationPlaid, Detail: "First Bank"},
+		{Kind: DeletionIntegrationStripe, Detail: "active"},
+	}, manifest.Integrations)
+}
+
+func TestAccountUsageService_DeletionManifest_SizesStorageFresh(t *testing.T) {
+	storage := &fakeStorageSizer{sizes: map[string]int64{"users/user1/": 10}}
+	service, _ := newTestAccountUsageService(t, storage)
+	ctx := context.Background()
+
+	_, err := service.GetUsage(ctx, "user1")
+	require.NoError(t, err)
+
+	// The usage total is cached, but the manifest lists objects again
+	storage.sizes["users/user1/"] = 99
+	manifest, err := service.DeletionManifest(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(99), manifest.StorageBytes)
+	assert.Empty(t, manifest.Integrations)
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	defaultStorageUsageCacheTTL = 5 * time.Minute
+
+	// accountUsageConcurrency caps the count aggregations run at once
+	accountUsageConcurrency = 4
+)
+
+// accountUsageCollections are the collections counted for a user's footprint,
+// both under users/{uid} and, for imported data, top level with a uid field
+var accountUsageCollections = []string{
+	"tasks", "projects", "goals", "thoughts", "notes", "errands", "trips",
+	"moods", "focusSessions", "people", "portfolios", "transactions", "accounts",
+	"entityRelationships", "photoLibrary", "llmLogs", "sessionTemplates",
+}
+
+// accountStorageImageVariants are the image sizes stored under images/{variant}/{uid}
+var accountStorageImageVariants = []string{"original", "thumb", "medium"}
+
+// StorageSizer totals the Cloud Storage objects under a prefix
+type StorageSizer interface {
+	PrefixSize(ctx context.Context, prefix string) (bytes int64, objects int, err error)
+}
+
+// AccountUsage is a user's data footprint
+type AccountUsage struct {
+	Collections    map[string]int64 `json:"collections"`
+	TotalDocuments int64            `json:"totalDocuments"`
+	Storage        *StorageUsage    `json:"storage"`
+	AI             AITokenUsage     `json:"ai"`
+	GeneratedAt    time.Time        `json:"generatedAt"`
+}
+
+// StorageUsage is the size of a user's Cloud Storage objects as of ComputedAt
+type StorageUsage struct {
+	Bytes      int64     `json:"bytes"`
+	Objects    int       `json:"objects"`
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+// StoragePrefixUsage is the size of the objects under one storage prefix
+type StoragePrefixUsage struct {
+	Prefix  string `json:"prefix"`
+	Bytes   int64  `json:"bytes"`
+	Objects int    `json:"objects"`
+}
+
+// AITokenUsage is the AI usage logged since the start of the current
+// calendar month (UTC)
+type AITokenUsage struct {
+	PeriodStart time.Time `json:"periodStart"`
+	Requests    int       `json:"requests"`
+	Tokens      int64     `json:"tokens"`
+}
+
+type cachedStorageUsage struct {
+	usage     StorageUsage
+	expiresAt time.Time
+}
+
+// AccountUsageService reports how much data and storage a user has
+type AccountUsageService struct {
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	storage  StorageSizer
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu           sync.Mutex
+	storageCache map[string]cachedStorageUsage
+}
+
+// NewAccountUsageService creates a new account usage service. storage may be
+// nil when Cloud Storage is unavailable; usage then has no storage section.
+func NewAccountUsageService(repo interfaces.Repository, logger *zap.Logger, storage StorageSizer, cfg config.AccountUsageConfig) *AccountUsageService {
+	if cfg.StorageCacheTTL <= 0 {
+		cfg.StorageCacheTTL = defaultStorageUsageCacheTTL
+	}
+	return &AccountUsageService{
+		repo:         repo,
+		logger:       logger,
+		storage:      storage,
+		cacheTTL:     cfg.StorageCacheTTL,
+		now:          time.Now,
+		storageCache: make(map[string]cachedStorageUsage),
+	}
+}
+
+// GetUsage returns the user's per-collection document counts, Cloud Storage
+// total and AI token usage this month
+func (s *AccountUsageService) GetUsage(ctx context.Context, uid string) (*AccountUsage, error) {
+	usage := &AccountUsage{GeneratedAt: s.now()}
+
+	counts, err := s.countDocuments(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	usage.Collections = counts
+	for _, count := range counts {
+		usage.TotalDocuments += count
+	}
+
+	if usage.AI, err = s.aiUsage(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	if s.storage != nil {
+		storage, err := s.storageUsage(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		usage.Storage = &storage
+	}
+	return usage, nil
+}
+
+// countDocuments runs a count aggregation for each collection the user can own
+func (s *AccountUsageService) countDocuments(ctx context.Context, uid string) (map[string]int64, error) {
+	type countQuery struct {
+		collection string
+		query      firestore.Query
+	}
+	queries := make([]countQuery, 0, 2*len(accountUsageCollections))
+	for _, collection := range accountUsageCollections {
+		queries = append(queries, countQuery{collection, s.repo.Collection(fmt.Sprintf("users/%s/%s", uid, collection)).Query})
+	}
+	for _, collection := range userOwnedTopLevelCollections {
+		queries = append(queries, countQuery{collection, s.repo.Collection(collection).Where("uid", "==", uid)})
+	}
+
+	results := make([]int64, len(queries))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(accountUsageConcurrency)
+	for i, q := range queries {
+		i, q := i, q
+		g.Go(func() error {
+			count, err := s.repo.Count(gctx, q.query)
+			if err != nil {
+				return fmt.Errorf("failed to count %s: %w", q.collection, err)
+			}
+			results[i] = count
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(accountUsageCollections))
+	for i, q := range queries {
+		counts[q.collection] += results[i]
+	}
+	return counts, nil
+}
+
+// aiUsage sums the tokens recorded in the user's LLM logs this month
+func (s *AccountUsageService) aiUsage(ctx context.Context, uid string) (AITokenUsage, error) {
+	now := s.now().UTC()
+	usage := AITokenUsage{PeriodStart: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+
+	// Only the usage field is read; prompts and responses can be large
+	logs, err := s.repo.CollectAll(ctx, s.repo.Collection(fmt.Sprintf("users/%s/llmLogs", uid)).
+		Where("createdAt", ">=", usage.PeriodStart).
+		Select("usage"))
+	if err != nil {
+		return usage, fmt.Errorf("failed to read LLM logs: %w", err)
+	}
+	for _, log := range logs {
+		usage.Requests++
+		if tokens, ok := log["usage"].(map[string]interface{}); ok {
+			usage.Tokens += tokenCount(tokens["total_tokens"])
+		}
+	}
+	return usage, nil
+}
+
+// storageUsage sums the objects under the user's storage prefixes, reusing a
+// recent total when there is one
+func (s *AccountUsageService) storageUsage(ctx context.Context, uid string) (StorageUsage, error) {
+	now := s.now()
+	s.mu.Lock()
+	cached, ok := s.storageCache[uid]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.usage, nil
+	}
+
+	prefixes, err := s.storageByPrefix(ctx, uid)
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	usage := StorageUsage{ComputedAt: now}
+	for _, prefix := range prefixes {
+		usage.Bytes += prefix.Bytes
+		usage.Objects += prefix.Objects
+	}
+
+	s.mu.Lock()
+	for cachedUID, entry := range s.storageCache {
+		if !now.Before(entry.expiresAt) {
+			delete(s.storageCache, cachedUID)
+		}
+	}
+	s.storageCache[uid] = cachedStorageUsage{usage: usage, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return usage, nil
+}
+
+// storageByPrefix sizes each of the user's storage prefixes, uncached
+func (s *AccountUsageService) storageByPrefix(ctx context.Context, uid string) ([]StoragePrefixUsage, error) {
+	prefixes := []string{fmt.Sprintf("users/%s/", uid)}
+	for _, variant := range accountStorageImageVariants {
+		prefixes = append(prefixes, fmt.Sprintf("images/%s/%s/", variant, uid))
+	}
+
+	usage := make([]StoragePrefixUsage, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		bytes, objects, err := s.storage.PrefixSize(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, StoragePrefixUsage{Prefix: prefix, Bytes: bytes, Objects: objects})
+	}
+	return usage, nil
+}
+
+// tokenCount reads a token count written by Go (int, int64) or by the
+// Cloud Functions (float64)
+func tokenCount(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
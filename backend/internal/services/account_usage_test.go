@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+type fakeStorageSizer struct {
+	sizes    map[string]int64
+	prefixes []string
+	err      error
+}
+
+func (f *fakeStorageSizer) PrefixSize(ctx context.Context, prefix string) (int64, int, error) {
+	f.prefixes = append(f.prefixes, prefix)
+	if f.err != nil {
+		return 0, 0, f.err
+	}
+	if size, ok := f.sizes[prefix]; ok {
+		return size, 1, nil
+	}
+	return 0, 0, nil
+}
+
+func newTestAccountUsageService(t *testing.T, storage StorageSizer) (*AccountUsageService, *mocks.MockRepository) {
+	repo := mocks.NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	return NewAccountUsageService(repo, zap.NewNop(), storage, config.AccountUsageConfig{StorageCacheTTL: time.Minute}), repo
+}
+
+func TestAccountUsageService_GetUsage(t *testing.T) {
+	storage := &fakeStorageSizer{sizes: map[string]int64{
+		"users/user1/":           2048,
+		"images/original/user1/": 1000,
+		"images/thumb/user1/":    24,
+	}}
+	service, repo := newTestAccountUsageService(t, storage)
+	service.now = func() time.Time { return time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC) }
+
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"title": "Plan"})
+	repo.AddDocument("users/user1/tasks/t2", map[string]interface{}{"title": "Pack"})
+	repo.AddDocument("tasks/imported1", map[string]interface{}{"uid": "user1"})
+	repo.AddDocument("tasks/imported2", map[string]interface{}{"uid": "user2"})
+	repo.AddDocument("users/user1/notes/n1", map[string]interface{}{"title": "Ideas"})
+	repo.AddDocument("users/user1/llmLogs/l1", map[string]interface{}{
+		"createdAt": time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC),
+		"usage":     map[string]interface{}{"total_tokens": int64(100)},
+	})
+	repo.AddDocument("users/user1/llmLogs/l2", map[string]interface{}{
+		"createdAt": time.Date(2024, 5, 17, 9, 0, 0, 0, time.UTC),
+		"usage":     map[string]interface{}{"total_tokens": float64(50)},
+	})
+	// Last month's log is counted as a document but not towards this month's tokens
+	repo.AddDocument("users/user1/llmLogs/l0", map[string]interface{}{
+		"createdAt": time.Date(2024, 4, 30, 23, 0, 0, 0, time.UTC),
+		"usage":     map[string]interface{}{"total_tokens": int64(1000)},
+	})
+
+	usage, err := service.GetUsage(context.Background(), "user1")
+	require.NoError(t, err)
+
+	// tasks are counted under users/{uid} and as imported top-level documents
+	assert.Equal(t, int64(3), usage.Collections["tasks"])
+	assert.Equal(t, int64(1), usage.Collections["notes"])
+	assert.Equal(t, int64(3), usage.Collections["llmLogs"])
+	assert.Len(t, usage.Collections, len(accountUsageCollections))
+	assert.Equal(t, int64(7), usage.TotalDocuments)
+
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), usage.AI.PeriodStart)
+	assert.Equal(t, 2, usage.AI.Requests)
+	assert.Equal(t, int64(150), usage.AI.Tokens)
+
+	require.NotNil(t, usage.Storage)
+	assert.Equal(t, int64(3072), usage.Storage.Bytes)
+	assert.Equal(t, 3, usage.Storage.Objects)
+	assert.ElementsMatch(t, []string{
+		"users/user1/", "images/original/user1/", "images/thumb/user1/", "images/medium/user1/",
+	}, storage.prefixes)
+}
+
+func TestAccountUsageService_CachesStorageTotal(t *testing.T) {
+	storage := &fakeStorageSizer{sizes: map[string]int64{"users/user1/": 10}}
+	service, _ := newTestAccountUsageService(t, storage)
+	now := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := service.GetUsage(ctx, "user1")
+	require.NoError(t, err)
+	listed := len(storage.prefixes)
+
+	// Within the TTL the storage total is reused, even if objects changed
+	storage.sizes["users/user1/"] = 99
+	now = now.Add(30 * time.Second)
+	second, err := service.GetUsage(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, storage.prefixes, listed)
+	assert.Equal(t, first.Storage, second.Storage)
+
+	// Once it expires the objects are listed again
+	now = now.Add(time.Minute)
+	third, err := service.GetUsage(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, storage.prefixes, 2*listed)
+	assert.Equal(t, int64(99), third.Storage.Bytes)
+	assert.Equal(t, now, third.Storage.ComputedAt)
+}
+
+func TestAccountUsageService_WithoutStorage(t *testing.T) {
+	service, _ := newTestAccountUsageService(t, nil)
+
+	usage, err := service.GetUsage(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Nil(t, usage.Storage)
+	assert.Equal(t, int64(0), usage.TotalDocuments)
+}
+
+func TestAccountUsageService_Errors(t *testing.T) {
+	service, repo := newTestAccountUsageService(t, &fakeStorageSizer{})
+	repo.QueryErr = errors.New("aggregation unavailable")
+	_, err := service.GetUsage(context.Background(), "user1")
+	assert.ErrorContains(t, err, "aggregation unavailable")
+
+	service, _ = newTestAccountUsageService(t, &fakeStorageSizer{err: errors.New("bucket unavailable")})
+	_, err = service.GetUsage(context.Background(), "user1")
+	assert.ErrorContains(t, err, "bucket unavailable")
+}
@@ -3,8 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
@@ -15,13 +15,16 @@ import (
 type ActionProcessor struct {
 	repo   *repository.FirestoreRepository
 	logger *zap.Logger
+	graph  *EntityGraphService
 }
 
-// NewActionProcessor creates a new action processor
-func NewActionProcessor(repo *repository.FirestoreRepository, logger *zap.Logger) *ActionProcessor {
+// NewActionProcessor creates a new action processor. Relationship actions are
+// stored through graph, which bounds the relationships per entity.
+func NewActionProcessor(repo *repository.FirestoreRepository, logger *zap.Logger, graph *EntityGraphService) *ActionProcessor {
 	return &ActionProcessor{
 		repo:   repo,
 		logger: logger,
+		graph:  graph,
 	}
 }
 
@@ -54,7 +57,8 @@ func (a *ActionProcessor) ExecuteAction(ctx context.Context, uid, thoughtID stri
 
 // createTask creates a new task
 func (a *ActionProcessor) createTask(ctx context.Context, uid, thoughtID string, data map[string]interface{}) error {
-	// Generate task ID
+	// IDs are always generated here; action data comes from the AI and must
+	// not be able to address (and overwrite) an existing document
 	taskID := generateID()
 	taskPath := fmt.Sprintf("users/%s/tasks/%s", uid, taskID)
 
@@ -181,36 +185,70 @@ func (a *ActionProcessor) createMood(ctx context.Context, uid, thoughtID string,
 
 // createRelationship creates a relationship in the entity graph
 func (a *ActionProcessor) createRelationship(ctx context.Context, uid, thoughtID string, data map[string]interface{}) error {
-	relationshipID := generateID()
-	relationshipPath := fmt.Sprintf("users/%s/entity_graph/%s", uid, relationshipID)
-
-	relationshipData := map[string]interface{}{
-		"id":               relationshipID,
-		"sourceType":       "thought",
-		"sourceId":         thoughtID,
-		"targetType":       getStringFieldFromMap(data, "targetType"),
-		"targetId":         getStringFieldFromMap(data, "targetId"),
-		"relationshipType": getStringFieldFromMap(data, "relationshipType"),
-		"reasoning":        getStringFieldFromMap(data, "reasoning"),
-		"createdBy":        "ai",
-	}
-
-	err := a.repo.CreateDocument(ctx, relationshipPath, relationshipData)
+	relationship, err := a.graph.CreateRelationship(ctx, uid, thoughtRelationship(thoughtID, data))
 	if err != nil {
 		return fmt.Errorf("failed to create relationship: %w", err)
 	}
-
 	a.logger.Info("Relationship created",
 		zap.String("uid", uid),
-		zap.String("relationshipId", relationshipID),
+		zap.String("relationshipId", getStringField(relationship, "id")),
 		zap.String("thoughtId", thoughtID),
-		zap.String("targetType", getStringFieldFromMap(data, "targetType")),
-		zap.String("targetId", getStringFieldFromMap(data, "targetId")),
+		zap.String("targetType", getStringField(relationship, "targetType")),
+		zap.String("targetId", getStringField(relationship, "targetId")),
 	)
-
 	return nil
 }
 
+// CreateRelationships creates the relationships of several createRelationship
+// actions from one thought together, so the thought's relationship cap is
+// applied once to the whole set. Invalid actions are skipped and logged;
+// returns how many relationships were created.
+func (a *ActionProcessor) CreateRelationships(ctx context.Context, uid, thoughtID string, items []map[string]interface{}) (int, error) {
+	relationships := make([]map[string]interface{}, 0, len(items))
+	for _, data := range items {
+		relationships = append(relationships, thoughtRelationship(thoughtID, data))
+	}
+
+	created, skipped, err := a.graph.CreateRelationships(ctx, uid, relationships)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create relationship: %w", err)
+	}
+
+	for _, relationship := range created {
+		a.logger.Info("Relationship created",
+			zap.String("uid", uid),
+			zap.String("relationshipId", getStringField(relationship, "id")),
+			zap.String("thoughtId", thoughtID),
+			zap.String("targetType", getStringField(relationship, "targetType")),
+			zap.String("targetId", getStringField(relationship, "targetId")),
+		)
+	}
+	for _, skip := range skipped {
+		a.logger.Warn("Skipped invalid createRelationship action",
+			zap.String("uid", uid),
+			zap.String("thoughtId", thoughtID),
+			zap.Int("index", skip.Index),
+			zap.Error(skip.Err),
+		)
+	}
+
+	return len(created), nil
+}
+
+// thoughtRelationship builds the relationship from a thought to the target of
+// a createRelationship action
+func thoughtRelationship(thoughtID string, data map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"sourceType":       string(EntityTypeThought),
+		"sourceId":         thoughtID,
+		"targetType":       getStringFieldFromMap(data, "targetType"),
+		"targetId":         getStringFieldFromMap(data, "targetId"),
+		"relationshipType": getStringFieldFromMap(data, "relationshipType"),
+		"reasoning":        getStringFieldFromMap(data, "reasoning"),
+		"createdBy":        "ai",
+	}
+}
+
 // enhanceTask enhances an existing task with information from the thought
 func (a *ActionProcessor) enhanceTask(ctx context.Context, uid, thoughtID string, data map[string]interface{}) error {
 	taskID := getStringFieldFromMap(data, "taskId")
@@ -273,8 +311,7 @@ func getIntFieldFromMap(m map[string]interface{}, key string) int {
 	return 0
 }
 
-// generateID generates a unique ID (similar to Firestore auto-ID)
+// generateID generates a random, collision-resistant ID for server-created documents
 func generateID() string {
-	// Use timestamp + random component
-	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), time.Now().Unix()%1000000)
+	return uuid.New().String()
 }
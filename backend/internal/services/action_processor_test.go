@@ -3,6 +3,7 @@ package services
 import (
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 
@@ -15,7 +16,7 @@ func TestNewActionProcessor(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
 	logger := zap.NewNop()
 
-	processor := NewActionProcessor(repo, logger)
+	processor := NewActionProcessor(repo, logger, nil)
 
 	assert.NotNil(t, processor)
 	assert.Equal(t, repo, processor.repo)
@@ -25,7 +26,7 @@ func TestNewActionProcessor(t *testing.T) {
 func TestNewActionProcessor_WithNilRepository(t *testing.T) {
 	logger := zap.NewNop()
 
-	processor := NewActionProcessor(nil, logger)
+	processor := NewActionProcessor(nil, logger, nil)
 
 	assert.NotNil(t, processor)
 	assert.Nil(t, processor.repo)
@@ -35,7 +36,7 @@ func TestNewActionProcessor_WithNilRepository(t *testing.T) {
 func TestNewActionProcessor_WithNilLogger(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
 
-	processor := NewActionProcessor(repo, nil)
+	processor := NewActionProcessor(repo, nil, nil)
 
 	assert.NotNil(t, processor)
 	assert.Equal(t, repo, processor.repo)
@@ -43,7 +44,7 @@ func TestNewActionProcessor_WithNilLogger(t *testing.T) {
 }
 
 func TestNewActionProcessor_AllNil(t *testing.T) {
-	processor := NewActionProcessor(nil, nil)
+	processor := NewActionProcessor(nil, nil, nil)
 
 	assert.NotNil(t, processor)
 	assert.Nil(t, processor.repo)
@@ -53,7 +54,7 @@ func TestNewActionProcessor_AllNil(t *testing.T) {
 func TestActionProcessor_Fields(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
 	logger := zap.NewNop()
-	processor := NewActionProcessor(repo, logger)
+	processor := NewActionProcessor(repo, logger, nil)
 
 	assert.NotNil(t, processor.repo)
 	assert.NotNil(t, processor.logger)
@@ -61,20 +62,20 @@ func TestActionProcessor_Fields(t *testing.T) {
 
 func TestActionProcessor_RepositoryStorage(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
-	processor := NewActionProcessor(repo, nil)
+	processor := NewActionProcessor(repo, nil, nil)
 
 	assert.Equal(t, repo, processor.repo)
 }
 
 func TestActionProcessor_LoggerStorage(t *testing.T) {
 	logger := zap.NewNop()
-	processor := NewActionProcessor(nil, logger)
+	processor := NewActionProcessor(nil, logger, nil)
 
 	assert.Equal(t, logger, processor.logger)
 }
 
 func TestActionProcessor_Constructor(t *testing.T) {
-	processor := NewActionProcessor(nil, nil)
+	processor := NewActionProcessor(nil, nil, nil)
 
 	assert.Nil(t, processor.repo)
 	assert.Nil(t, processor.logger)
@@ -84,8 +85,8 @@ func TestActionProcessor_MultipleInstances(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
 	logger := zap.NewNop()
 
-	processor1 := NewActionProcessor(repo, logger)
-	processor2 := NewActionProcessor(repo, logger)
+	processor1 := NewActionProcessor(repo, logger, nil)
+	processor2 := NewActionProcessor(repo, logger, nil)
 
 	assert.NotNil(t, processor1)
 	assert.NotNil(t, processor2)
@@ -95,7 +96,7 @@ func TestActionProcessor_MultipleInstances(t *testing.T) {
 
 func TestActionProcessor_WithRepository(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
-	processor := NewActionProcessor(repo, nil)
+	processor := NewActionProcessor(repo, nil, nil)
 
 	assert.NotNil(t, processor)
 	assert.NotNil(t, processor.repo)
@@ -103,14 +104,14 @@ func TestActionProcessor_WithRepository(t *testing.T) {
 
 func TestActionProcessor_WithLogger(t *testing.T) {
 	logger := zap.NewNop()
-	processor := NewActionProcessor(nil, logger)
+	processor := NewActionProcessor(nil, logger, nil)
 
 	assert.NotNil(t, processor)
 	assert.NotNil(t, processor.logger)
 }
 
 func TestActionProcessor_ImplementsExpectedMethods(t *testing.T) {
-	processor := NewActionProcessor(nil, nil)
+	processor := NewActionProcessor(nil, nil, nil)
 
 	assert.NotNil(t, processor)
 	// Processor should have ExecuteAction method
@@ -234,10 +235,9 @@ func TestGenerateID_Unique(t *testing.T) {
 	id1 := generateID()
 	id2 := generateID()
 
-	// IDs may or may not be unique due to timing (nano vs unix seconds)
-	// but both should be non-empty
 	assert.NotEmpty(t, id1)
 	assert.NotEmpty(t, id2)
+	assert.NotEqual(t, id1, id2)
 }
 
 func TestGenerateID_HasDash(t *testing.T) {
@@ -249,10 +249,10 @@ func TestGenerateID_HasDash(t *testing.T) {
 func TestGenerateID_Format(t *testing.T) {
 	id := generateID()
 
-	// Should have format: timestamp-random
-	parts := len(id)
-	assert.Greater(t, parts, 0)
-	assert.Contains(t, id, "-")
+	// Should be a UUID
+	_, err := uuid.Parse(id)
+	assert.NoError(t, err)
+	assert.Len(t, id, 36)
 }
 
 func TestGenerateID_MultipleGeneration(t *testing.T) {
@@ -272,7 +272,7 @@ func TestActionProcessor_AllFields(t *testing.T) {
 	repo := repository.NewFirestoreRepository(nil)
 	logger := zap.NewNop()
 
-	processor := NewActionProcessor(repo, logger)
+	processor := NewActionProcessor(repo, logger, nil)
 
 	assert.NotNil(t, processor.repo)
 	assert.NotNil(t, processor.logger)
@@ -314,24 +314,24 @@ func TestGetStringFieldFromMap_EmptyString(t *testing.T) {
 
 func TestActionProcessor_ConstructorVariations(t *testing.T) {
 	// Variation 1: both nil
-	p1 := NewActionProcessor(nil, nil)
+	p1 := NewActionProcessor(nil, nil, nil)
 	assert.Nil(t, p1.repo)
 	assert.Nil(t, p1.logger)
 
 	// Variation 2: repo only
 	repo := repository.NewFirestoreRepository(nil)
-	p2 := NewActionProcessor(repo, nil)
+	p2 := NewActionProcessor(repo, nil, nil)
 	assert.NotNil(t, p2.repo)
 	assert.Nil(t, p2.logger)
 
 	// Variation 3: logger only
 	logger := zap.NewNop()
-	p3 := NewActionProcessor(nil, logger)
+	p3 := NewActionProcessor(nil, logger, nil)
 	assert.Nil(t, p3.repo)
 	assert.NotNil(t, p3.logger)
 
 	// Variation 4: both
-	p4 := NewActionProcessor(repo, logger)
+	p4 := NewActionProcessor(repo, logger, nil)
 	assert.NotNil(t, p4.repo)
 	assert.NotNil(t, p4.logger)
 }
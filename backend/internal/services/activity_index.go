@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// maxActivityIndexDays caps the days kept in an activity index, about ten years
+	maxActivityIndexDays = 3660
+	// activityIndexOverlap is how far before the last sync a refresh re-reads
+	// sessions, covering sessions written with a slightly earlier startTime
+	activityIndexOverlap = 24 * time.Hour
+)
+
+// ActivityIndexService keeps a per-user index of the days with focus sessions
+// at users/{uid}/activityIndex/focusSessions, so streaks can be computed
+// without scanning every session. The index is built lazily from a full scan,
+// updated when the backend starts a session, and refreshed with the sessions
+// started since its last sync to pick up sessions written by clients directly.
+type ActivityIndexService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewActivityIndexService creates a new activity index service
+func NewActivityIndexService(repo interfaces.Repository, logger *zap.Logger) *ActivityIndexService {
+	return &ActivityIndexService{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// ActiveDays returns the set of days (YYYY-MM-DD) with at least one focus
+// session, building the index on first use
+func (s *ActivityIndexService) ActiveDays(ctx context.Context, uid string) (map[string]bool, error) {
+	path := activityIndexPath(uid)
+	doc, err := s.repo.Get(ctx, path)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return s.rebuild(ctx, uid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity index: %w", err)
+	}
+
+	days := make(map[string]bool)
+	for _, day := range toStringSlice(doc["days"]) {
+		days[day] = true
+	}
+
+	// Pick up sessions started since the last sync
+	syncedAt, _ := parseFlexibleDate(doc["syncedAt"])
+	now := s.now()
+	sessions, err := s.repo.ListWhere(ctx, fmt.Sprintf("users/%s/focusSessions", uid), []interfaces.Filter{
+		{Field: "startTime", Op: ">=", Value: syncedAt.Add(-activityIndexOverlap)},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh activity index: %w", err)
+	}
+	added := mergeSessionDays(days, sessions)
+
+	if added > 0 || now.Sub(syncedAt) > activityIndexOverlap {
+		if err := s.save(ctx, uid, days, now); err != nil {
+			return nil, err
+		}
+	}
+	return days, nil
+}
+
+// RecordSession adds the day of a session's startTime to the index. An index
+// that has not been built yet is left for ActiveDays to build. Failures are
+// logged so indexing never fails the session write.
+func (s *ActivityIndexService) RecordSession(ctx context.Context, uid string, startTime time.Time) {
+	if s == nil {
+		return
+	}
+	doc, err := s.repo.Get(ctx, activityIndexPath(uid))
+	if err != nil {
+		if !errors.Is(err, interfaces.ErrNotFound) {
+			s.logger.Warn("Failed to load activity index", zap.String("uid", uid), zap.Error(err))
+		}
+		return
+	}
+
+	day := activityIndexDay(startTime)
+	days := toStringSlice(doc["days"])
+	for _, existing := range days {
+		if existing == day {
+			return
+		}
+	}
+	days = appendActivityDays(days, day)
+
+	if err := s.repo.Update(ctx, activityIndexPath(uid), map[string]interface{}{
+		"days":      toInterfaceSlice(days),
+		"updatedAt": s.now(),
+	}); err != nil {
+		s.logger.Warn("Failed to update activity index", zap.String("uid", uid), zap.Error(err))
+	}
+}
+
+// rebuild scans all of the user's focus sessions and stores a fresh index
+func (s *ActivityIndexService) rebuild(ctx context.Context, uid string) (map[string]bool, error) {
+	now := s.now()
+	sessions, err := s.repo.List(ctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list focus sessions: %w", err)
+	}
+
+	days := make(map[string]bool)
+	mergeSessionDays(days, sessions)
+	if err := s.save(ctx, uid, days, now); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Activity index rebuilt", zap.String("uid", uid), zap.Int("days", len(days)))
+	return days, nil
+}
+
+// save stores days, keeping the most recent maxActivityIndexDays
+func (s *ActivityIndexService) save(ctx context.Context, uid string, days map[string]bool, syncedAt time.Time) error {
+	sorted := make([]string, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Strings(sorted)
+	if len(sorted) > maxActivityIndexDays {
+		sorted = sorted[len(sorted)-maxActivityIndexDays:]
+	}
+
+	if err := s.repo.SetDocument(ctx, activityIndexPath(uid), map[string]interface{}{
+		"days":      toInterfaceSlice(sorted),
+		"syncedAt":  syncedAt,
+		"updatedAt": syncedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to save activity index: %w", err)
+	}
+	return nil
+}
+
+// mergeSessionDays adds the startTime day of each session to days and
+// returns how many days were new
+func mergeSessionDays(days map[string]bool, sessions []map[string]interface{}) int {
+	added := 0
+	for _, session := range sessions {
+		startTime, ok := session["startTime"].(time.Time)
+		if !ok {
+			continue
+		}
+		day := activityIndexDay(startTime)
+		if !days[day] {
+			days[day] = true
+			added++
+		}
+	}
+	return added
+}
+
+// appendActivityDays inserts day into sorted days, dropping the oldest days
+// beyond maxActivityIndexDays
+func appendActivityDays(days []string, day string) []string {
+	days = append(days, day)
+	sort.Strings(days)
+	if len(days) > maxActivityIndexDays {
+		days = days[len(days)-maxActivityIndexDays:]
+	}
+	return days
+}
+
+// activityIndexDay is the calendar day of t in its own location, matching how
+// the dashboard has always bucketed sessions
+func activityIndexDay(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func activityIndexPath(uid string) string {
+	return fmt.Sprintf("users/%s/activityIndex/focusSessions", uid)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func addFocusSession(repo *mocks.MockRepository, uid, id string, start time.Time) {
+	repo.AddDocument(fmt.Sprintf("users/%s/focusSessions/%s", uid, id), map[string]interface{}{
+		"id":        id,
+		"startTime": start,
+		"duration":  25,
+	})
+}
+
+func TestActivityIndex_MatchesScan(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 6, 15, 18, 0, 0, 0, time.UTC)
+
+	// Active today and the 4 days before, then a gap, then older activity;
+	// some days have several sessions
+	for _, daysAgo := range []int{0, 0, 1, 2, 3, 3, 4, 6, 7, 30, 400} {
+		start := now.AddDate(0, 0, -daysAgo).Add(-time.Duration(daysAgo%3) * time.Hour)
+		addFocusSession(repo, "user1", fmt.Sprintf("s%d-%d", daysAgo, start.Hour()), start)
+	}
+	repo.AddDocument("users/user1/focusSessions/no-start", map[string]interface{}{"id": "no-start"})
+
+	activity := NewActivityIndexService(repo, zap.NewNop())
+	activity.now = func() time.Time { return now }
+	indexed := NewDashboardAnalyticsService(repo, zap.NewNop(), activity)
+	scanned := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+
+	for daysAgo := 0; daysAgo <= 8; daysAgo++ {
+		reference := now.AddDate(0, 0, -daysAgo)
+		assert.Equal(t, scanned.calculateStreak(ctx, "user1", reference), indexed.calculateStreak(ctx, "user1", reference), "reference %s", reference)
+	}
+	assert.Equal(t, 5, indexed.calculateStreak(ctx, "user1", now))
+
+	days, err := activity.ActiveDays(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, days, 9)
+}
+
+func TestActivityIndex_DoesNotRescanSessions(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	ctx := context.Background()
+	now := time.Now()
+	addFocusSession(repo, "user1", "yesterday", now.AddDate(0, 0, -1))
+
+	// The first read builds the index from a full scan
+	_, err := NewActivityIndexService(repo, zap.NewNop()).ActiveDays(ctx, "user1")
+	require.NoError(t, err)
+
+	// Later reads only query recent sessions; a session written directly by a
+	// client is picked up by the refresh
+	addFocusSession(repo, "user1", "today", now)
+	scoped := NewActivityIndexService(&scopedQueryRepository{MockRepository: repo}, zap.NewNop())
+	days, err := scoped.ActiveDays(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, countStreak(days, now))
+}
+
+func TestActivityIndex_RecordSession(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	ctx := context.Background()
+	activity := NewActivityIndexService(repo, zap.NewNop())
+
+	// Without an index nothing is written; the index is built on first read
+	activity.RecordSession(ctx, "user1", time.Now())
+	_, err := repo.Get(ctx, activityIndexPath("user1"))
+	assert.Error(t, err)
+
+	_, err = activity.ActiveDays(ctx, "user1")
+	require.NoError(t, err)
+
+	sessions := NewFocusSessionService(repo, zap.NewNop(), activity)
+	_, err = sessions.Start(ctx, "user1", StartFocusSessionRequest{
+		Duration: 25,
+		Tasks:    []map[string]interface{}{{"id": "task1"}},
+	})
+	require.NoError(t, err)
+
+	index, err := repo.Get(ctx, activityIndexPath("user1"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{activityIndexDay(time.Now())}, toStringSlice(index["days"]))
+
+	var nilIndex *ActivityIndexService
+	nilIndex.RecordSession(ctx, "user1", time.Now())
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const defaultAIResponseCacheTTL = 24 * time.Hour
+
+// AIResponseCache stores AI completions per user in
+// users/{uid}/aiResponseCache/{hash}, keyed by a hash of everything that
+// determines the response. Entries carry an expiresAt field, so a Firestore
+// TTL policy on it can delete them once stale.
+type AIResponseCache struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	ttl    time.Duration
+}
+
+// NewAIResponseCache creates a new AI response cache. ttl <= 0 falls back to
+// defaultAIResponseCacheTTL.
+func NewAIResponseCache(repo interfaces.Repository, logger *zap.Logger, ttl time.Duration) *AIResponseCache {
+	if ttl <= 0 {
+		ttl = defaultAIResponseCacheTTL
+	}
+	return &AIResponseCache{
+		repo:   repo,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+// AIResponseCacheKey hashes the parts of an AI request into a cache key
+func AIResponseCacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab", "c") and ("a", "bc") differ
+		fmt.Fprintf(h, "%d:%s|", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GetOrCall returns the unexpired cached response for key, or calls the
+// provider and caches a successful result. force skips the lookup but still
+// refreshes the entry. cached reports whether the response came from the cache.
+func (c *AIResponseCache) GetOrCall(
+	ctx context.Context,
+	uid, key string,
+	force bool,
+	call func() (*clients.ChatCompletionResponse, error),
+) (response *clients.ChatCompletionResponse, cached bool, err error) {
+	if c == nil {
+		response, err = call()
+		return response, false, err
+	}
+
+	path := fmt.Sprintf("users/%s/aiResponseCache/%s", uid, key)
+	if !force {
+		if response, ok := c.lookup(ctx, path); ok {
+			return response, true, nil
+		}
+	}
+
+	response, err = call()
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	if err := c.repo.SetDocument(ctx, path, map[string]interface{}{
+		"content":    response.Content,
+		"model":      response.Model,
+		"tokensUsed": response.TokensUsed,
+		"cachedAt":   now,
+		"expiresAt":  now.Add(c.ttl),
+	}); err != nil {
+		// Caching is best effort; the caller still has its response
+		c.logger.Warn("Failed to cache AI response", zap.String("uid", uid), zap.Error(err))
+	}
+	return response, false, nil
+}
+
+func (c *AIResponseCache) lookup(ctx context.Context, path string) (*clients.ChatCompletionResponse, bool) {
+	data, err := c.repo.Get(ctx, path)
+	if err != nil {
+		return nil, false
+	}
+	expiresAt, ok := data["expiresAt"].(time.Time)
+	if !ok || !time.Now().Before(expiresAt) {
+		return nil, false
+	}
+	content, _ := data["content"].(string)
+	if content == "" {
+		return nil, false
+	}
+	model, _ := data["model"].(string)
+	return &clients.ChatCompletionResponse{
+		Content: content,
+		Model:   model,
+	}, true
+}
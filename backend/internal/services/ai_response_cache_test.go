@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// countingProvider stands in for an AI client and counts its calls
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) call() (*clients.ChatCompletionResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &clients.ChatCompletionResponse{Content: `{"actions":[]}`, Model: "gpt-4o", TokensUsed: 900}, nil
+}
+
+func TestAIResponseCache_HitSkipsProvider(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	cache := NewAIResponseCache(repo, zap.NewNop(), time.Hour)
+	provider := &countingProvider{}
+	ctx := context.Background()
+	key := AIResponseCacheKey(thoughtPromptVersion, "gpt-4o", "Call the dentist")
+
+	response, cached, err := cache.GetOrCall(ctx, "user1", key, false, provider.call)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, 1, provider.calls)
+	assert.Contains(t, repo.Documents, "users/user1/aiResponseCache/"+key)
+
+	response, cached, err = cache.GetOrCall(ctx, "user1", key, false, provider.call)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, 1, provider.calls, "provider must not be called on a cache hit")
+	assert.Equal(t, `{"actions":[]}`, response.Content)
+	assert.Zero(t, response.TokensUsed)
+}
+
+func TestAIResponseCache_ForceBypassesCache(t *testing.T) {
+	cache := NewAIResponseCache(mocks.NewMockRepository(), zap.NewNop(), time.Hour)
+	provider := &countingProvider{}
+	ctx := context.Background()
+
+	_, _, err := cache.GetOrCall(ctx, "user1", "key", false, provider.call)
+	require.NoError(t, err)
+
+	_, cached, err := cache.GetOrCall(ctx, "user1", "key", true, provider.call)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, 2, provider.calls)
+}
+
+func TestAIResponseCache_ExpiredAndScopedEntries(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	cache := NewAIResponseCache(repo, zap.NewNop(), time.Hour)
+	provider := &countingProvider{}
+	ctx := context.Background()
+
+	repo.AddDocument("users/user1/aiResponseCache/key", map[string]interface{}{
+		"content":   `{"actions":[]}`,
+		"expiresAt": time.Now().Add(-time.Minute),
+	})
+	_, cached, err := cache.GetOrCall(ctx, "user1", "key", false, provider.call)
+	require.NoError(t, err)
+	assert.False(t, cached, "expired entries must not be reused")
+
+	// Entries are per user
+	_, cached, err = cache.GetOrCall(ctx, "user2", "key", false, provider.call)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, 2, provider.calls)
+}
+
+func TestAIResponseCache_ProviderErrorNotCached(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	cache := NewAIResponseCache(repo, zap.NewNop(), time.Hour)
+	provider := &countingProvider{err: errors.New("rate limited")}
+
+	_, _, err := cache.GetOrCall(context.Background(), "user1", "key", false, provider.call)
+	assert.Error(t, err)
+	assert.NotContains(t, repo.Documents, "users/user1/aiResponseCache/key")
+}
+
+func TestAIResponseCache_NilCacheAlwaysCalls(t *testing.T) {
+	var cache *AIResponseCache
+	provider := &countingProvider{}
+
+	for i := 0; i < 2; i++ {
+		_, cached, err := cache.GetOrCall(context.Background(), "user1", "key", false, provider.call)
+		require.NoError(t, err)
+		assert.False(t, cached)
+	}
+	assert.Equal(t, 2, provider.calls)
+}
+
+func TestAIResponseCacheKey(t *testing.T) {
+	base := AIResponseCacheKey(thoughtPromptVersion, "gpt-4o", "Call the dentist")
+
+	assert.Equal(t, base, AIResponseCacheKey(thoughtPromptVersion, "gpt-4o", "Call the dentist"))
+	assert.NotEqual(t, base, AIResponseCacheKey(thoughtPromptVersion, "claude-3", "Call the dentist"))
+	assert.NotEqual(t, base, AIResponseCacheKey("thought-prompt-v2", "gpt-4o", "Call the dentist"))
+	assert.NotEqual(t, base, AIResponseCacheKey(thoughtPromptVersion, "gpt-4o", "Call the doctor"))
+	assert.NotEqual(t, AIResponseCacheKey("ab", "c"), AIResponseCacheKey("a", "bc"))
+}
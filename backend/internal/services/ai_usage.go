@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// DefaultAIUsageDays is the period of an AI usage report when none is given
+	DefaultAIUsageDays = 30
+	// unknownAIFeature groups logs written before features were tagged
+	unknownAIFeature = "unknown"
+)
+
+// AIFeatureUsage is the AI usage of one feature over a report's period
+type AIFeatureUsage struct {
+	Feature          string  `json:"feature"`
+	Requests         int     `json:"requests"`
+	FailedRequests   int     `json:"failedRequests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	TotalTokens      int     `json:"totalTokens"`
+	EstimatedCostUSD float64 `json:"estimatedCostUsd"`
+	UnpricedTokens   int     `json:"unpricedTokens"` // tokens from models without a configured rate
+}
+
+// AIUsageReport breaks a user's AI token use and estimated cost down by
+// feature, most expensive first
+type AIUsageReport struct {
+	Since            time.Time        `json:"since"`
+	Until            time.Time        `json:"until"`
+	Features         []AIFeatureUsage `json:"features"`
+	TotalTokens      int              `json:"totalTokens"`
+	EstimatedCostUSD float64          `json:"estimatedCostUsd"`
+	UnpricedTokens   int              `json:"unpricedTokens"`
+}
+
+// AIUsage aggregates the user's LLM logs from the last days (DefaultAIUsageDays
+// when <= 0) by feature. Logs from before features were tagged fall back to
+// their promptType, then to "unknown".
+func (s *LLMLogService) AIUsage(ctx context.Context, uid string, days int) (*AIUsageReport, error) {
+	if days <= 0 {
+		days = DefaultAIUsageDays
+	}
+	until := s.now()
+	since := until.AddDate(0, 0, -days)
+
+	logs, err := s.repo.ListWhere(ctx, fmt.Sprintf("users/%s/llmLogs", uid), []interfaces.Filter{
+		{Field: "createdAt", Op: ">=", Value: since},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LLM logs: %w", err)
+	}
+
+	report := s.aggregateAIUsage(logs)
+	report.Since = since
+	report.Until = until
+	return report, nil
+}
+
+// aggregateAIUsage groups LLM logs by feature and prices their tokens
+func (s *LLMLogService) aggregateAIUsage(logs []map[string]interface{}) *AIUsageReport {
+	byFeature := make(map[string]*AIFeatureUsage)
+	for _, log := range logs {
+		feature := getStringField(log, "feature")
+		if feature == "" {
+			feature = getStringField(log, "promptType")
+		}
+		if feature == "" {
+			feature = unknownAIFeature
+		}
+		usage, ok := byFeature[feature]
+		if !ok {
+			usage = &AIFeatureUsage{Feature: feature}
+			byFeature[feature] = usage
+		}
+
+		usage.Requests++
+		if getStringField(log, "status") == "failed" {
+			usage.FailedRequests++
+		}
+		tokens, _ := log["usage"].(map[string]interface{})
+		prompt := logTokenCount(tokens, "prompt_tokens")
+		completion := logTokenCount(tokens, "completion_tokens")
+		total := logTokenCount(tokens, "total_tokens")
+		if total == 0 {
+			total = prompt + completion
+		}
+		usage.PromptTokens += prompt
+		usage.CompletionTokens += completion
+		usage.TotalTokens += total
+
+		metadata, _ := log["metadata"].(map[string]interface{})
+		if rate, ok := s.modelCost(getStringField(metadata, "model")); ok {
+			usage.EstimatedCostUSD += float64(total) * rate / 1e6
+		} else {
+			usage.UnpricedTokens += total
+		}
+	}
+
+	report := &AIUsageReport{Features: make([]AIFeatureUsage, 0, len(byFeature))}
+	for _, usage := range byFeature {
+		usage.EstimatedCostUSD = math.Round(usage.EstimatedCostUSD*1e4) / 1e4
+		report.Features = append(report.Features, *usage)
+		report.TotalTokens += usage.TotalTokens
+		report.EstimatedCostUSD += usage.EstimatedCostUSD
+		report.UnpricedTokens += usage.UnpricedTokens
+	}
+	report.EstimatedCostUSD = math.Round(report.EstimatedCostUSD*1e4) / 1e4
+	sort.Slice(report.Features, func(i, j int) bool {
+		a, b := report.Features[i], report.Features[j]
+		if a.EstimatedCostUSD != b.EstimatedCostUSD {
+			return a.EstimatedCostUSD > b.EstimatedCostUSD
+		}
+		if a.TotalTokens != b.TotalTokens {
+			return a.TotalTokens > b.TotalTokens
+		}
+		return a.Feature < b.Feature
+	})
+	return report
+}
+
+// modelCost returns the USD per million tokens of the longest configured
+// model prefix matching model
+func (s *LLMLogService) modelCost(model string) (float64, bool) {
+	if model == "" {
+		return 0, false
+	}
+	best := ""
+	for prefix := range s.modelCosts {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0, false
+	}
+	return s.modelCosts[best], true
+}
+
+// logTokenCount reads a token count from an LLM log's usage map, which
+// Firestore returns as int64
+func logTokenCount(usage map[string]interface{}, key string) int {
+	count, _ := toFloat(usage[key])
+	return int(count)
+}
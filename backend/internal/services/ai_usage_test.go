@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestLLMLogService_AIUsage_GroupsByFeature(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{
+		CostPerMillionTokens: map[string]float64{"gpt-4o": 5, "gpt-4o-mini": 0.5},
+	})
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	entries := []LLMLogEntry{
+		{Feature: AIFeatureThoughtProcessing, Model: "gpt-4o-2024-08-06", Status: "completed", TotalTokens: 1000},
+		{Feature: AIFeatureThoughtProcessing, Model: "gpt-4o", Status: "failed"},
+		{Feature: AIFeatureThoughtProcessing, Model: "gpt-4o", Status: "completed", TotalTokens: 3000},
+		{Feature: AIFeatureTaskEnrichment, Model: "gpt-4o-mini", Status: "completed", PromptTokens: 1500, CompletionTokens: 500},
+		{Feature: AIFeatureTaskEnrichment, Model: "local-model", Status: "completed", TotalTokens: 700},
+	}
+	for _, entry := range entries {
+		_, err := service.Record(ctx, "user1", entry)
+		require.NoError(t, err)
+	}
+
+	// Logs from before features were tagged fall back to their promptType
+	repo.AddDocument("users/user1/llmLogs/legacy", map[string]interface{}{
+		"promptType": "thought-processing",
+		"usage":      map[string]interface{}{"total_tokens": int64(1000)},
+		"metadata":   map[string]interface{}{"model": "gpt-4o"},
+		"createdAt":  now.Add(-48 * time.Hour),
+	})
+	repo.AddDocument("users/user1/llmLogs/untagged", map[string]interface{}{
+		"usage":     map[string]interface{}{"total_tokens": int64(200)},
+		"createdAt": now.Add(-time.Hour),
+	})
+	// Outside the period, and another user's log
+	repo.AddDocument("users/user1/llmLogs/old", map[string]interface{}{
+		"feature":   AIFeatureTaskEnrichment,
+		"usage":     map[string]interface{}{"total_tokens": int64(9999)},
+		"createdAt": now.AddDate(0, 0, -31),
+	})
+	repo.AddDocument("users/user2/llmLogs/other", map[string]interface{}{
+		"feature":   AIFeatureTaskEnrichment,
+		"usage":     map[string]interface{}{"total_tokens": int64(9999)},
+		"createdAt": now,
+	})
+
+	report, err := service.AIUsage(ctx, "user1", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, now.AddDate(0, 0, -DefaultAIUsageDays), report.Since)
+	require.Len(t, report.Features, 3)
+
+	thoughts := report.Features[0]
+	assert.Equal(t, AIFeatureThoughtProcessing, thoughts.Feature)
+	assert.Equal(t, 4, thoughts.Requests)
+	assert.Equal(t, 1, thoughts.FailedRequests)
+	assert.Equal(t, 5000, thoughts.TotalTokens)
+	assert.InDelta(t, 0.025, thoughts.EstimatedCostUSD, 1e-9)
+	assert.Equal(t, 0, thoughts.UnpricedTokens)
+
+	// gpt-4o-mini matches its own rate rather than gpt-4o's
+	enrichment := report.Features[1]
+	assert.Equal(t, AIFeatureTaskEnrichment, enrichment.Feature)
+	assert.Equal(t, 2, enrichment.Requests)
+	assert.Equal(t, 1500, enrichment.PromptTokens)
+	assert.Equal(t, 500, enrichment.CompletionTokens)
+	assert.Equal(t, 2700, enrichment.TotalTokens)
+	assert.InDelta(t, 0.001, enrichment.EstimatedCostUSD, 1e-9)
+	assert.Equal(t, 700, enrichment.UnpricedTokens)
+
+	unknown := report.Features[2]
+	assert.Equal(t, "unknown", unknown.Feature)
+	assert.Equal(t, 200, unknown.UnpricedTokens)
+
+	assert.Equal(t, 7900, report.TotalTokens)
+	assert.InDelta(t, 0.026, report.EstimatedCostUSD, 1e-9)
+	assert.Equal(t, 900, report.UnpricedTokens)
+}
+
+func TestLLMLogService_Record_StoresFeature(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{})
+
+	id, err := service.Record(context.Background(), "user1", LLMLogEntry{
+		Feature: AIFeatureTaskEnrichment,
+		Status:  "completed",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AIFeatureTaskEnrichment, repo.Documents["users/user1/llmLogs/"+id]["feature"])
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"firebase.google.com/go/v4/auth"
+	"go.uber.org/zap"
+	"google.golang.org/api/iterator"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Anonymous quota kinds. Usage is counted on the anonymousSessions/{uid} document.
+const (
+	AnonymousQuotaDocuments = "documents"
+	AnonymousQuotaAICalls   = "aiCalls"
+)
+
+const (
+	defaultAnonymousMaxDocuments  = 200
+	defaultAnonymousMaxAICalls    = 20
+	defaultAnonymousDataRetention = 72 * time.Hour
+	defaultAnonymousCleanupBatch  = 100
+	defaultAnonymousCleanupEvery  = 24 * time.Hour
+)
+
+// anonymousUsageFields maps quota kinds to their counter field on the session document
+var anonymousUsageFields = map[string]string{
+	AnonymousQuotaDocuments: "documentCount",
+	AnonymousQuotaAICalls:   "aiCallCount",
+}
+
+// userOwnedTopLevelCollections hold documents owned through a uid field
+// (written by import) rather than under users/{uid}
+var userOwnedTopLevelCollections = []string{
+	"tasks", "projects", "goals", "thoughts", "moods", "focusSessions",
+	"people", "portfolios", "transactions", "entityRelationships", "llmLogs",
+}
+
+// AnonymousIdentityStore looks up and deletes Firebase Auth users; *auth.Client satisfies it
+type AnonymousIdentityStore interface {
+	GetUser(ctx context.Context, uid string) (*auth.UserRecord, error)
+	DeleteUser(ctx context.Context, uid string) error
+}
+
+// AnonymousService enforces usage quotas for anonymous sessions and purges
+// their data once it is older than the retention window. The sweep starts from
+// anonymousSessions and confirms each identity has no linked sign-in provider,
+// so real accounts are never touched.
+type AnonymousService struct {
+	repo         interfaces.Repository
+	identities   AnonymousIdentityStore
+	logger       *zap.Logger
+	limits       map[string]int
+	retention    time.Duration
+	overrideKey  string
+	cleanupBatch int
+	now          func() time.Time
+}
+
+// NewAnonymousService creates a new anonymous service. Zero limits, retention
+// and batch size use defaults.
+func NewAnonymousService(repo interfaces.Repository, identities AnonymousIdentityStore, logger *zap.Logger, cfg *config.AnonymousConfig, cleanupBatch int) *AnonymousService {
+	maxDocuments, maxAICalls, retention := cfg.MaxDocuments, cfg.MaxAICalls, cfg.DataRetention
+	if maxDocuments <= 0 {
+		maxDocuments = defaultAnonymousMaxDocuments
+	}
+	if maxAICalls <= 0 {
+		maxAICalls = defaultAnonymousMaxAICalls
+	}
+	if retention <= 0 {
+		retention = defaultAnonymousDataRetention
+	}
+	if cleanupBatch <= 0 {
+		cleanupBatch = defaultAnonymousCleanupBatch
+	}
+	return &AnonymousService{
+		repo:       repo,
+		identities: identities,
+		logger:     logger,
+		limits: map[string]int{
+			AnonymousQuotaDocuments: maxDocuments,
+			AnonymousQuotaAICalls:   maxAICalls,
+		},
+		retention:    retention,
+		overrideKey:  cfg.AIOverrideKey,
+		cleanupBatch: cleanupBatch,
+		now:          time.Now,
+	}
+}
+
+// ErrAnonymousQuotaExceeded is returned when an anonymous session has used
+// up a quota
+var ErrAnonymousQuotaExceeded = errors.New("anonymous usage limit reached; sign in to continue")
+
+// AnonymousQuotaConsumer records usage against an anonymous session's quota,
+// reporting false when the units do not fit in what is left
+type AnonymousQuotaConsumer interface {
+	ConsumeAnonymousQuota(ctx context.Context, uid, kind string, units int) (bool, error)
+}
+
+// consumeAnonymousQuota charges units of kind when the request comes from an
+// anonymous session, returning ErrAnonymousQuotaExceeded once the quota is
+// used up. Signed-in users and a nil consumer are never charged.
+func consumeAnonymousQuota(ctx context.Context, quota AnonymousQuotaConsumer, kind string, units int) error {
+	isAnonymous, _ := ctx.Value("isAnonymous").(bool)
+	if quota == nil || !isAnonymous || units <= 0 {
+		return nil
+	}
+	uid, _ := ctx.Value("uid").(string)
+	allowed, err := quota.ConsumeAnonymousQuota(ctx, uid, kind, units)
+	if err != nil {
+		return fmt.Errorf("failed to check anonymous usage: %w", err)
+	}
+	if !allowed {
+		return ErrAnonymousQuotaExceeded
+	}
+	return nil
+}
+
+// ConsumeAnonymousQuota records units of usage for an anonymous session. The
+// check and increment run in one transaction, so concurrent requests cannot
+// overshoot the limit. Returns false without recording anything when the
+// units do not fit in the remaining quota or the session does not exist.
+func (s *AnonymousService) ConsumeAnonymousQuota(ctx context.Context, uid, kind string, units int) (bool, error) {
+	field, ok := anonymousUsageFields[kind]
+	if !ok {
+		return false, fmt.Errorf("unknown anonymous quota: %s", kind)
+	}
+	if units <= 0 {
+		return true, nil
+	}
+
+	sessionPath := fmt.Sprintf("%s/%s", AnonymousSessionCollection, uid)
+	allowed, err := s.repo.IncrementWithinLimit(ctx, sessionPath, field, int64(units), int64(s.limits[kind]), map[string]interface{}{
+		"lastUsageAt": s.now(),
+	})
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record anonymous usage: %w", err)
+	}
+	if !allowed {
+		s.logger.Info("Anonymous quota exceeded",
+			zap.String("uid", uid),
+			zap.String("quota", kind),
+			zap.Int("units", units),
+			zap.Int("limit", s.limits[kind]),
+		)
+	}
+	return allowed, nil
+}
+
+// PurgeExpired deletes the data of anonymous sessions created before the
+// retention window, up to one cleanup batch. Expired sessions are read oldest
+// first a page at a time, so CI sessions, which are kept, never hold back the
+// ones behind them; Firestore cannot filter them out in the query because
+// its != drops sessions without the field. The session of a user who has
+// since signed in is deleted and their data kept. Returns the number of
+// sessions purged.
+func (s *AnonymousService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-s.retention)
+
+	purged, upgraded := 0, 0
+	var after *firestore.DocumentSnapshot
+	for purged < s.cleanupBatch {
+		opts := []interfaces.QueryOption{
+			repository.Where("createdAt", "<", cutoff),
+			repository.OrderBy("createdAt", firestore.Asc),
+			repository.Limit(s.cleanupBatch),
+		}
+		if after != nil {
+			cursor := after
+			opts = append(opts, func(q firestore.Query) firestore.Query { return q.StartAfter(cursor) })
+		}
+		docs, err := s.repo.QueryCollection(ctx, AnonymousSessionCollection, opts...)
+		if err != nil {
+			return purged, fmt.Errorf("failed to query anonymous sessions: %w", err)
+		}
+
+		for _, doc := range docs {
+			if purged >= s.cleanupBatch {
+				break
+			}
+			switch s.purgeSession(ctx, doc, cutoff) {
+			case sessionPurged:
+				purged++
+			case sessionUpgraded:
+				upgraded++
+			}
+		}
+		if len(docs) < s.cleanupBatch {
+			break
+		}
+		after = docs[len(docs)-1]
+	}
+
+	if purged > 0 || upgraded > 0 {
+		s.logger.Info("Purged anonymous data",
+			zap.Int("sessions", purged),
+			zap.Int("upgradedSessions", upgraded),
+			zap.Time("cutoff", cutoff),
+		)
+	}
+	return purged, nil
+}
+
+// Outcomes of purgeSession
+const (
+	sessionKept = iota
+	sessionPurged
+	sessionUpgraded
+)
+
+// purgeSession purges an expired session's data, or only the session
+// document when the user has linked a sign-in provider since. Failures are
+// logged and leave the session for the next run.
+func (s *AnonymousService) purgeSession(ctx context.Context, doc *firestore.DocumentSnapshot, cutoff time.Time) int {
+	if !anonymousSessionPurgeable(doc.Data(), cutoff, s.overrideKey) {
+		return sessionKept
+	}
+	uid := doc.Ref.ID
+	anonymous, err := s.isStillAnonymous(ctx, uid)
+	if err != nil {
+		s.logger.Error("Failed to look up anonymous user", zap.String("uid", uid), zap.Error(err))
+		return sessionKept
+	}
+	if !anonymous {
+		// The quota counters no longer apply to a signed-in account
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			s.logger.Error("Failed to delete upgraded anonymous session", zap.String("uid", uid), zap.Error(err))
+			return sessionKept
+		}
+		return sessionUpgraded
+	}
+	if err := s.purgeAnonymousData(ctx, uid); err != nil {
+		s.logger.Error("Failed to purge anonymous data", zap.String("uid", uid), zap.Error(err))
+		return sessionKept
+	}
+	if _, err := doc.Ref.Delete(ctx); err != nil {
+		s.logger.Error("Failed to delete anonymous session", zap.String("uid", uid), zap.Error(err))
+		return sessionKept
+	}
+	if err := s.identities.DeleteUser(ctx, uid); err != nil && !auth.IsUserNotFound(err) {
+		s.logger.Warn("Failed to delete anonymous auth user", zap.String("uid", uid), zap.Error(err))
+	}
+	return sessionPurged
+}
+
+// RunCleanup purges expired anonymous data every interval until ctx is cancelled
+func (s *AnonymousService) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultAnonymousCleanupEvery
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeExpired(ctx); err != nil {
+			s.logger.Error("Anonymous cleanup failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// isStillAnonymous reports whether uid has no linked sign-in provider.
+// A user already deleted from Auth counts as anonymous so its data is swept.
+func (s *AnonymousService) isStillAnonymous(ctx context.Context, uid string) (bool, error) {
+	user, err := s.identities.GetUser(ctx, uid)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return len(user.ProviderUserInfo) == 0, nil
+}
+
+// purgeAnonymousData deletes users/{uid} with its subcollections and the
+// user's documents in top-level collections
+func (s *AnonymousService) purgeAnonymousData(ctx context.Context, uid string) error {
+	if err := deleteDocumentTree(ctx, s.repo.Collection("users").Doc(uid)); err != nil {
+		return err
+	}
+
+	for _, collection := range userOwnedTopLevelCollections {
+		docs, err := s.repo.QueryCollection(ctx, collection, repository.Where("uid", "==", uid))
+		if err != nil {
+			return fmt.Errorf("failed to query %s: %w", collection, err)
+		}
+		for _, doc := range docs {
+			if err := deleteDocumentTree(ctx, doc.Ref); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// anonymousSessionPurgeable reports whether a session's data may be purged:
+// created before the cutoff and not a CI session holding the override key
+func anonymousSessionPurgeable(session map[string]interface{}, cutoff time.Time, overrideKey string) bool {
+	if key, _ := session["ciOverrideKey"].(string); overrideKey != "" && key == overrideKey {
+		return false
+	}
+	createdAt, ok := session["createdAt"].(time.Time)
+	return ok && createdAt.Before(cutoff)
+}
+
+// deleteDocumentTree deletes a document after recursively deleting its subcollections
+func deleteDocumentTree(ctx context.Context, doc *firestore.DocumentRef) error {
+	collections := doc.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list subcollections of %s: %w", doc.Path, err)
+		}
+
+		refs, err := collection.DocumentRefs(ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", collection.Path, err)
+		}
+		for _, ref := range refs {
+			if err := deleteDocumentTree(ctx, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := doc.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", doc.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+//go:build integration
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+// fakeIdentityStore reports the uids in linked as signed in with a provider
+// and every other uid as anonymous
+type fakeIdentityStore struct {
+	linked  map[string]bool
+	deleted []string
+}
+
+func (f *fakeIdentityStore) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
+	user := &auth.UserRecord{UserInfo: &auth.UserInfo{UID: uid}}
+	if f.linked[uid] {
+		user.ProviderUserInfo = []*auth.UserInfo{{UID: uid, ProviderID: "google.com"}}
+	}
+	return user, nil
+}
+
+func (f *fakeIdentityStore) DeleteUser(ctx context.Context, uid string) error {
+	f.deleted = append(f.deleted, uid)
+	return nil
+}
+
+func TestAnonymousService_PurgeExpiredPastSkippedSessions_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	ctx := context.Background()
+	const batch = 2
+
+	// More upgraded sessions than one batch, then a CI session, all older
+	// than the anonymous session that should be purged
+	identities := &fakeIdentityStore{linked: map[string]bool{}}
+	createdAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	var upgraded []string
+	for i := 0; i < batch+1; i++ {
+		uid := testutil.NewTestUser(t, client)
+		identities.linked[uid] = true
+		upgraded = append(upgraded, uid)
+		_, err := client.Collection(AnonymousSessionCollection).Doc(uid).Set(ctx, map[string]interface{}{
+			"createdAt": createdAt.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	ciUID := testutil.NewTestUser(t, client)
+	_, err := client.Collection(AnonymousSessionCollection).Doc(ciUID).Set(ctx, map[string]interface{}{
+		"createdAt": createdAt.Add(time.Hour), "ciOverrideKey": "ci-key",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Collection(AnonymousSessionCollection).Doc(ciUID).Delete(context.Background()) })
+
+	anonUID := testutil.NewTestUser(t, client)
+	_, err = client.Collection(AnonymousSessionCollection).Doc(anonUID).Set(ctx, map[string]interface{}{
+		"createdAt": createdAt.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	testutil.SeedUserDocs(t, client, anonUID, "tasks", map[string]interface{}{"id": "t1", "title": "Scratch"})
+	testutil.SeedUserDocs(t, client, upgraded[0], "tasks", map[string]interface{}{"id": "t1", "title": "Keep"})
+
+	svc := NewAnonymousService(repository.NewFirestoreRepository(client), identities, zap.NewNop(),
+		&config.AnonymousConfig{AIOverrideKey: "ci-key", DataRetention: time.Hour}, batch)
+
+	purged, err := svc.PurgeExpired(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, 1)
+	assert.Contains(t, identities.deleted, anonUID)
+
+	_, err = client.Doc(fmt.Sprintf("users/%s/tasks/t1", anonUID)).Get(ctx)
+	assert.Error(t, err, "anonymous data should be purged")
+	_, err = client.Collection(AnonymousSessionCollection).Doc(anonUID).Get(ctx)
+	assert.Error(t, err, "anonymous session should be deleted")
+
+	// Upgraded users keep their data but lose the session; CI sessions stay
+	for _, uid := range upgraded {
+		_, err = client.Collection(AnonymousSessionCollection).Doc(uid).Get(ctx)
+		assert.Error(t, err, "upgraded session %s should be deleted", uid)
+		assert.NotContains(t, identities.deleted, uid)
+	}
+	_, err = client.Doc(fmt.Sprintf("users/%s/tasks/t1", upgraded[0])).Get(ctx)
+	assert.NoError(t, err)
+	_, err = client.Collection(AnonymousSessionCollection).Doc(ciUID).Get(ctx)
+	assert.NoError(t, err)
+}
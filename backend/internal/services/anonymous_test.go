@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestNewAnonymousService_Defaults(t *testing.T) {
+	svc := NewAnonymousService(nil, nil, zap.NewNop(), &config.AnonymousConfig{}, 0)
+
+	assert.Equal(t, defaultAnonymousMaxDocuments, svc.limits[AnonymousQuotaDocuments])
+	assert.Equal(t, defaultAnonymousMaxAICalls, svc.limits[AnonymousQuotaAICalls])
+	assert.Equal(t, defaultAnonymousDataRetention, svc.retention)
+	assert.Equal(t, defaultAnonymousCleanupBatch, svc.cleanupBatch)
+}
+
+func TestAnonymousService_ConsumeQuotaEnforcesLimit(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("anonymousSessions/anon1", map[string]interface{}{"uid": "anon1"})
+	svc := NewAnonymousService(repo, nil, zap.NewNop(), &config.AnonymousConfig{MaxAICalls: 2}, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, err := svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaAICalls, 1)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, err := svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaAICalls, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(2), repo.Documents["anonymousSessions/anon1"]["aiCallCount"])
+
+	// Quotas are counted separately per kind
+	allowed, err = svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaDocuments, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestAnonymousService_ConsumeQuotaChargesUnits(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("anonymousSessions/anon1", map[string]interface{}{"uid": "anon1"})
+	svc := NewAnonymousService(repo, nil, zap.NewNop(), &config.AnonymousConfig{MaxDocuments: 10}, 0)
+	ctx := context.Background()
+
+	allowed, err := svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaDocuments, 8)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// A batch that does not fit is rejected whole, leaving the count untouched
+	allowed, err = svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaDocuments, 3)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(8), repo.Documents["anonymousSessions/anon1"]["documentCount"])
+
+	allowed, err = svc.ConsumeAnonymousQuota(ctx, "anon1", AnonymousQuotaDocuments, 2)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestConsumeAnonymousQuota_OnlyChargesAnonymousSessions(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("anonymousSessions/anon1", map[string]interface{}{"uid": "anon1"})
+	svc := NewAnonymousService(repo, nil, zap.NewNop(), &config.AnonymousConfig{MaxAICalls: 1}, 0)
+
+	signedIn := context.WithValue(context.Background(), "uid", "anon1")
+	require.NoError(t, consumeAnonymousQuota(signedIn, svc, AnonymousQuotaAICalls, 5))
+	assert.Nil(t, repo.Documents["anonymousSessions/anon1"]["aiCallCount"])
+
+	anonymous := context.WithValue(signedIn, "isAnonymous", true)
+	require.NoError(t, consumeAnonymousQuota(anonymous, svc, AnonymousQuotaAICalls, 1))
+	assert.ErrorIs(t, consumeAnonymousQuota(anonymous, svc, AnonymousQuotaAICalls, 1), ErrAnonymousQuotaExceeded)
+}
+
+func TestAnonymousService_ConsumeQuotaWithoutSession(t *testing.T) {
+	svc := NewAnonymousService(mocks.NewMockRepository(), nil, zap.NewNop(), &config.AnonymousConfig{}, 0)
+
+	allowed, err := svc.ConsumeAnonymousQuota(context.Background(), "ghost", AnonymousQuotaDocuments, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = svc.ConsumeAnonymousQuota(context.Background(), "ghost", "uploads", 1)
+	assert.Error(t, err)
+}
+
+func TestAnonymousSessionPurgeable(t *testing.T) {
+	cutoff := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	old := cutoff.Add(-time.Hour)
+
+	assert.True(t, anonymousSessionPurgeable(map[string]interface{}{"createdAt": old}, cutoff, "ci-key"))
+	assert.False(t, anonymousSessionPurgeable(map[string]interface{}{"createdAt": cutoff.Add(time.Hour)}, cutoff, "ci-key"))
+	assert.False(t, anonymousSessionPurgeable(map[string]interface{}{}, cutoff, "ci-key"))
+	assert.False(t, anonymousSessionPurgeable(map[string]interface{}{"createdAt": old, "ciOverrideKey": "ci-key"}, cutoff, "ci-key"))
+	assert.True(t, anonymousSessionPurgeable(map[string]interface{}{"createdAt": old, "ciOverrideKey": ""}, cutoff, ""))
+}
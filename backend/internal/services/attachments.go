@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// AttachmentsField is the document field holding a task's or thought's attachments
+const AttachmentsField = "attachments"
+
+const (
+	// MaxAttachmentsPerDocument caps the attachments on one document
+	MaxAttachmentsPerDocument = 20
+	// MaxAttachmentSize caps the size of one attached file (25MB)
+	MaxAttachmentSize = 25 * 1024 * 1024
+)
+
+var (
+	// ErrInvalidAttachment is returned for malformed attachments
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentForbidden is returned for storage paths the user does not own
+	ErrAttachmentForbidden = errors.New("attachment path not owned by user")
+	// ErrAttachmentNotFound is returned when removing an attachment the document does not have
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// attachableCollections lists the user collections that accept attachments
+var attachableCollections = map[string]bool{
+	"tasks":    true,
+	"thoughts": true,
+}
+
+// Attachment is an uploaded storage object linked to a task or thought
+type Attachment struct {
+	StoragePath string `json:"storagePath" firestore:"storagePath"`
+	ContentType string `json:"contentType" firestore:"contentType"`
+	FileName    string `json:"fileName" firestore:"fileName"`
+	Size        int64  `json:"size" firestore:"size"`
+}
+
+// AttachmentStorage removes stored attachment objects
+type AttachmentStorage interface {
+	DeleteObject(ctx context.Context, path string) error
+}
+
+// AttachmentService links uploaded files to tasks and thoughts
+type AttachmentService struct {
+	repo    interfaces.Repository
+	logger  *zap.Logger
+	storage AttachmentStorage
+}
+
+// NewAttachmentService creates a new attachment service. storage may be nil
+// when Cloud Storage is unavailable; removed attachments then leave their
+// objects in place.
+func NewAttachmentService(repo interfaces.Repository, logger *zap.Logger, storage AttachmentStorage) *AttachmentService {
+	return &AttachmentService{
+		repo:    repo,
+		logger:  logger,
+		storage: storage,
+	}
+}
+
+// AddAttachment links an uploaded object to a document. Re-attaching the same
+// storage path replaces its metadata. Returns the document's attachments.
+func (s *AttachmentService) AddAttachment(ctx context.Context, uid, collection, id string, attachment Attachment) ([]Attachment, error) {
+	if !attachableCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	if err := validateAttachment(uid, &attachment); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("users/%s/%s/%s", uid, collection, id)
+	doc, err := s.repo.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := parseAttachments(doc[AttachmentsField])
+	replaced := false
+	for i, existing := range attachments {
+		if existing.StoragePath == attachment.StoragePath {
+			attachments[i] = attachment
+			replaced = true
+		}
+	}
+	if !replaced {
+		if len(attachments) >= MaxAttachmentsPerDocument {
+			return nil, fmt.Errorf("%w: at most %d attachments per document", ErrInvalidAttachment, MaxAttachmentsPerDocument)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := s.saveAttachments(ctx, path, attachments); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Attachment added",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.String("id", id),
+		zap.String("storagePath", attachment.StoragePath),
+	)
+	return attachments, nil
+}
+
+// RemoveAttachment unlinks an attachment from a document and deletes its
+// storage object. A failed storage delete is logged but does not fail the
+// removal, since the document no longer references the object.
+func (s *AttachmentService) RemoveAttachment(ctx context.Context, uid, collection, id, storagePath string) ([]Attachment, error) {
+	if !attachableCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	if err := userOwnsPath(uid, storagePath); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAttachmentForbidden, err)
+	}
+
+	path := fmt.Sprintf("users/%s/%s/%s", uid, collection, id)
+	doc, err := s.repo.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := parseAttachments(doc[AttachmentsField])
+	kept := make([]Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.StoragePath != storagePath {
+			kept = append(kept, attachment)
+		}
+	}
+	if len(kept) == len(attachments) {
+		return nil, ErrAttachmentNotFound
+	}
+
+	if err := s.saveAttachments(ctx, path, kept); err != nil {
+		return nil, err
+	}
+
+	// Attachments may link to photo library originals, which belong to the
+	// library; only objects uploaded as attachments are deleted with them
+	if s.storage != nil && isAttachmentObject(storagePath) {
+		if err := s.storage.DeleteObject(ctx, storagePath); err != nil {
+			s.logger.Warn("Failed to delete attachment object",
+				zap.String("uid", uid),
+				zap.String("storagePath", storagePath),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.logger.Info("Attachment removed",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.String("id", id),
+		zap.String("storagePath", storagePath),
+	)
+	return kept, nil
+}
+
+func (s *AttachmentService) saveAttachments(ctx context.Context, path string, attachments []Attachment) error {
+	stored := make([]interface{}, 0, len(attachments))
+	for _, attachment := range attachments {
+		stored = append(stored, map[string]interface{}{
+			"storagePath": attachment.StoragePath,
+			"contentType": attachment.ContentType,
+			"fileName":    attachment.FileName,
+			"size":        attachment.Size,
+		})
+	}
+	if err := s.repo.Update(ctx, path, map[string]interface{}{
+		AttachmentsField: stored,
+		"updatedAt":      time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to save attachments: %w", err)
+	}
+	return nil
+}
+
+// isAttachmentObject reports whether a storage path is an uploaded
+// attachment, users/{uid}/attachments/{filename}, rather than a photo
+// library object. Ownership is checked separately with userOwnsPath.
+func isAttachmentObject(path string) bool {
+	segments := splitPath(path)
+	return strings.HasPrefix(path, "users/") && len(segments) >= 4 && segments[2] == "attachments"
+}
+
+// validateAttachment checks an attachment's metadata and that its storage
+// path belongs to uid, trimming its string fields in place
+func validateAttachment(uid string, attachment *Attachment) error {
+	attachment.StoragePath = strings.TrimSpace(attachment.StoragePath)
+	attachment.ContentType = strings.TrimSpace(attachment.ContentType)
+	attachment.FileName = strings.TrimSpace(attachment.FileName)
+
+	if attachment.StoragePath == "" {
+		return fmt.Errorf("%w: storagePath is required", ErrInvalidAttachment)
+	}
+	if err := userOwnsPath(uid, attachment.StoragePath); err != nil {
+		return fmt.Errorf("%w: %v", ErrAttachmentForbidden, err)
+	}
+	if !strings.HasPrefix(attachment.ContentType, "image/") && attachment.ContentType != "application/pdf" {
+		return fmt.Errorf("%w: contentType must be an image or application/pdf", ErrInvalidAttachment)
+	}
+	if attachment.FileName == "" {
+		return fmt.Errorf("%w: fileName is required", ErrInvalidAttachment)
+	}
+	if attachment.Size <= 0 || attachment.Size > MaxAttachmentSize {
+		return fmt.Errorf("%w: size must be between 1 byte and %d bytes", ErrInvalidAttachment, MaxAttachmentSize)
+	}
+	return nil
+}
+
+// parseAttachments reads a stored attachments array, skipping malformed entries
+func parseAttachments(value interface{}) []Attachment {
+	items, _ := value.([]interface{})
+	attachments := make([]Attachment, 0, len(items))
+	for _, item := range items {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		storagePath, _ := data["storagePath"].(string)
+		if storagePath == "" {
+			continue
+		}
+		contentType, _ := data["contentType"].(string)
+		fileName, _ := data["fileName"].(string)
+		attachment := Attachment{
+			StoragePath: storagePath,
+			ContentType: contentType,
+			FileName:    fileName,
+		}
+		switch size := data["size"].(type) {
+		case int64:
+			attachment.Size = size
+		case int:
+			attachment.Size = int64(size)
+		case float64:
+			attachment.Size = int64(size)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
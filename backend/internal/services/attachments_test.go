@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+type fakeAttachmentStorage struct {
+	deleted []string
+	err     error
+	failing map[string]bool // paths that fail to delete
+}
+
+func (f *fakeAttachmentStorage) DeleteObject(ctx context.Context, path string) error {
+	if f.failing[path] {
+		return errors.New("storage unavailable")
+	}
+	f.deleted = append(f.deleted, path)
+	return f.err
+}
+
+func testAttachment(path string) Attachment {
+	return Attachment{StoragePath: path, ContentType: "application/pdf", FileName: "receipt.pdf", Size: 1024}
+}
+
+func TestAttachmentService_AddAttachmentValidatesOwnership(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"title": "File taxes"})
+	svc := NewAttachmentService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"own attachment", "users/user1/attachments/receipt.pdf", nil},
+		{"own image", "images/original/user1/photo.jpg", nil},
+		{"other user's attachment", "users/user2/attachments/receipt.pdf", ErrAttachmentForbidden},
+		{"other user's image", "images/original/user2/photo.jpg", ErrAttachmentForbidden},
+		{"outside user storage", "photo-feedback/session/photo.jpg", ErrAttachmentForbidden},
+		{"path traversal", "users/user1/attachments/../../user2/attachments/x.pdf", ErrAttachmentForbidden},
+		{"missing path", "", ErrInvalidAttachment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.AddAttachment(ctx, "user1", "tasks", "t1", testAttachment(tt.path))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	attachments := parseAttachments(repo.Documents["users/user1/tasks/t1"][AttachmentsField])
+	assert.Len(t, attachments, 2)
+}
+
+func TestAttachmentService_AddAttachmentValidatesMetadata(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/thoughts/th1", map[string]interface{}{"text": "idea"})
+	svc := NewAttachmentService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+	path := "users/user1/attachments/file"
+
+	_, err := svc.AddAttachment(ctx, "user1", "thoughts", "th1", Attachment{StoragePath: path, ContentType: "text/html", FileName: "a.html", Size: 10})
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+
+	_, err = svc.AddAttachment(ctx, "user1", "thoughts", "th1", Attachment{StoragePath: path, ContentType: "image/png", Size: 10})
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+
+	_, err = svc.AddAttachment(ctx, "user1", "thoughts", "th1", Attachment{StoragePath: path, ContentType: "image/png", FileName: "a.png", Size: MaxAttachmentSize + 1})
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+
+	_, err = svc.AddAttachment(ctx, "user1", "goals", "g1", testAttachment(path))
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.AddAttachment(ctx, "user1", "thoughts", "missing", testAttachment(path))
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestAttachmentService_AddAttachmentReplacesSamePath(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"title": "File taxes"})
+	svc := NewAttachmentService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+	path := "users/user1/attachments/receipt.pdf"
+
+	_, err := svc.AddAttachment(ctx, "user1", "tasks", "t1", testAttachment(path))
+	require.NoError(t, err)
+
+	renamed := testAttachment(path)
+	renamed.FileName = "receipt-2024.pdf"
+	attachments, err := svc.AddAttachment(ctx, "user1", "tasks", "t1", renamed)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "receipt-2024.pdf", attachments[0].FileName)
+}
+
+func TestAttachmentService_RemoveAttachmentDeletesObject(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{
+		AttachmentsField: []interface{}{
+			map[string]interface{}{"storagePath": "users/user1/attachments/a.pdf", "contentType": "application/pdf", "fileName": "a.pdf", "size": int64(10)},
+			map[string]interface{}{"storagePath": "users/user1/attachments/b.png", "contentType": "image/png", "fileName": "b.png", "size": int64(20)},
+		},
+	})
+	storage := &fakeAttachmentStorage{}
+	svc := NewAttachmentService(repo, zap.NewNop(), storage)
+	ctx := context.Background()
+
+	attachments, err := svc.RemoveAttachment(ctx, "user1", "tasks", "t1", "users/user1/attachments/a.pdf")
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "users/user1/attachments/b.png", attachments[0].StoragePath)
+	assert.Equal(t, []string{"users/user1/attachments/a.pdf"}, storage.deleted)
+
+	_, err = svc.RemoveAttachment(ctx, "user1", "tasks", "t1", "users/user1/attachments/a.pdf")
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+
+	_, err = svc.RemoveAttachment(ctx, "user1", "tasks", "t1", "users/user2/attachments/b.png")
+	assert.ErrorIs(t, err, ErrAttachmentForbidden)
+	assert.Len(t, storage.deleted, 1)
+}
+
+func TestAttachmentService_RemoveAttachmentLeavesLibraryPhotos(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{
+		AttachmentsField: []interface{}{
+			map[string]interface{}{"storagePath": "images/original/user1/photo.jpg", "contentType": "image/jpeg", "fileName": "photo.jpg", "size": int64(10)},
+		},
+	})
+	storage := &fakeAttachmentStorage{}
+	svc := NewAttachmentService(repo, zap.NewNop(), storage)
+
+	attachments, err := svc.RemoveAttachment(context.Background(), "user1", "tasks", "t1", "images/original/user1/photo.jpg")
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+	assert.Empty(t, storage.deleted)
+}
+
+func TestAttachmentService_RemoveAttachmentToleratesStorageFailure(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/thoughts/th1", map[string]interface{}{
+		AttachmentsField: []interface{}{
+			map[string]interface{}{"storagePath": "users/user1/attachments/a.pdf", "contentType": "application/pdf", "fileName": "a.pdf", "size": int64(10)},
+		},
+	})
+	svc := NewAttachmentService(repo, zap.NewNop(), &fakeAttachmentStorage{err: errors.New("storage unavailable")})
+
+	attachments, err := svc.RemoveAttachment(context.Background(), "user1", "thoughts", "th1", "users/user1/attachments/a.pdf")
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Audited actions
+const (
+	AuditActionExport             = "data.export"
+	AuditActionImport             = "data.import"
+	AuditActionAccountDelete      = "account.delete"
+	AuditActionPlaidConnect       = "plaid.connect"
+	AuditActionPlaidDisconnect    = "plaid.disconnect"
+	AuditActionSubscriptionChange = "subscription.change"
+	AuditActionSignedURL          = "storage.signed_url"
+)
+
+// AuditActorStripe is the actor of subscription changes made by Stripe webhooks
+const AuditActorStripe = "stripe"
+
+const (
+	// DefaultAuditLogLimit is the number of entries returned when no limit is given
+	DefaultAuditLogLimit = 50
+	// MaxAuditLogLimit caps audit log reads
+	MaxAuditLogLimit = 500
+)
+
+// ErrInvalidAuditQuery is returned for malformed audit log filters or cursors
+var ErrInvalidAuditQuery = errors.New("invalid audit log query")
+
+// AuditEntry records one sensitive operation on a user's account. Entries are
+// append-only: they are created with a fresh ID and never updated.
+type AuditEntry struct {
+	ID        string                 `json:"id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	Timestamp time.Time              `json:"timestamp"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	IP        string                 `json:"ip,omitempty"`
+}
+
+// AuditService keeps the per-user audit log at users/{uid}/auditLog
+type AuditService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo interfaces.Repository, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record appends an entry to the user's audit log. Actor defaults to uid.
+// Failures are logged rather than returned so auditing never fails the
+// operation being audited. A nil service does nothing.
+func (s *AuditService) Record(ctx context.Context, uid string, entry AuditEntry) {
+	if s == nil || uid == "" {
+		return
+	}
+	if entry.Actor == "" {
+		entry.Actor = uid
+	}
+	entry.ID = uuid.New().String()
+	entry.Timestamp = s.now()
+
+	data := map[string]interface{}{
+		"id":        entry.ID,
+		"action":    entry.Action,
+		"actor":     entry.Actor,
+		"timestamp": entry.Timestamp,
+	}
+	if len(entry.Details) > 0 {
+		data["details"] = entry.Details
+	}
+	if entry.IP != "" {
+		data["ip"] = entry.IP
+	}
+
+	if err := s.repo.Create(ctx, fmt.Sprintf("%s/%s", auditLogPath(uid), entry.ID), data); err != nil {
+		s.logger.Error("Failed to write audit entry",
+			zap.String("uid", uid),
+			zap.String("action", entry.Action),
+			zap.Error(err),
+		)
+	}
+}
+
+// RecordAudit records an action performed by the user from ip; it lets
+// middleware audit requests without depending on this package
+func (s *AuditService) RecordAudit(ctx context.Context, uid, action, ip string, details map[string]interface{}) {
+	s.Record(ctx, uid, AuditEntry{Action: action, IP: ip, Details: details})
+}
+
+// AuditLogQuery selects a page of the audit log. Action keeps only entries of
+// that action; From and To bound the timestamp, From inclusive and To
+// exclusive, and are ignored when zero. Cursor is the NextCursor of the
+// previous page.
+type AuditLogQuery struct {
+	Action string
+	From   time.Time
+	To     time.Time
+	Cursor string
+	Limit  int
+}
+
+// AuditLogPage is one page of the audit log, newest first. NextCursor is
+// empty on the last page.
+type AuditLogPage struct {
+	Entries    []AuditEntry `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// List returns a page of the user's audit log, newest first. Filters,
+// ordering and the cursor are all applied by the query, so only the page is
+// read. Filtering by action needs the (action, timestamp desc) index.
+func (s *AuditService) List(ctx context.Context, uid string, query AuditLogQuery) (*AuditLogPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLogLimit
+	}
+	if limit > MaxAuditLogLimit {
+		limit = MaxAuditLogLimit
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidAuditQuery)
+	}
+
+	var filters []interfaces.Filter
+	if query.Action != "" {
+		filters = append(filters, interfaces.Filter{Field: "action", Op: "==", Value: query.Action})
+	}
+	if !query.From.IsZero() {
+		filters = append(filters, interfaces.Filter{Field: "timestamp", Op: ">=", Value: query.From})
+	}
+	if !query.To.IsZero() {
+		filters = append(filters, interfaces.Filter{Field: "timestamp", Op: "<", Value: query.To})
+	}
+	// The cursor is the timestamp of the last entry returned; entries are
+	// written one per operation, so two never share a timestamp in practice
+	if query.Cursor != "" {
+		after, err := time.Parse(time.RFC3339Nano, query.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidAuditQuery)
+		}
+		filters = append(filters, interfaces.Filter{Field: "timestamp", Op: "<", Value: after})
+	}
+
+	// Read one extra entry to know whether there is a next page
+	docs, err := s.repo.ListWhereOrdered(ctx, auditLogPath(uid), filters,
+		[]interfaces.Ordering{{Field: "timestamp", Direction: firestore.Desc}}, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	page := &AuditLogPage{Entries: make([]AuditEntry, 0, len(docs))}
+	for _, doc := range docs {
+		entry := AuditEntry{
+			ID:     getStringField(doc, "id"),
+			Action: getStringField(doc, "action"),
+			Actor:  getStringField(doc, "actor"),
+			IP:     getStringField(doc, "ip"),
+		}
+		entry.Timestamp, _ = parseFlexibleDate(doc["timestamp"])
+		entry.Details, _ = doc["details"].(map[string]interface{})
+		page.Entries = append(page.Entries, entry)
+	}
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		page.NextCursor = page.Entries[limit-1].Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return page, nil
+}
+
+func auditLogPath(uid string) string {
+	return fmt.Sprintf("users/%s/auditLog", uid)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestAuditService_RecordAndList(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewAuditService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.RecordAudit(ctx, "user1", AuditActionExport, "203.0.113.7", map[string]interface{}{"path": "/api/export"})
+
+	now = now.Add(time.Minute)
+	svc.Record(ctx, "user1", AuditEntry{Action: AuditActionSubscriptionChange, Actor: AuditActorStripe})
+
+	// Other users' entries and records without a user are not visible
+	svc.RecordAudit(ctx, "user2", AuditActionImport, "", nil)
+	svc.RecordAudit(ctx, "", AuditActionImport, "", nil)
+
+	page, err := svc.List(ctx, "user1", AuditLogQuery{})
+	require.NoError(t, err)
+	entries := page.Entries
+	require.Len(t, entries, 2)
+	assert.Empty(t, page.NextCursor)
+
+	assert.Equal(t, AuditActionSubscriptionChange, entries[0].Action)
+	assert.Equal(t, AuditActorStripe, entries[0].Actor)
+
+	assert.Equal(t, AuditActionExport, entries[1].Action)
+	assert.Equal(t, "user1", entries[1].Actor)
+	assert.Equal(t, "203.0.113.7", entries[1].IP)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), entries[1].Timestamp)
+	assert.Equal(t, "/api/export", entries[1].Details["path"])
+	assert.NotEmpty(t, entries[1].ID)
+
+	limited, err := svc.List(ctx, "user1", AuditLogQuery{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited.Entries, 1)
+}
+
+func TestAuditService_ListFiltersByActionAndPages(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewAuditService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	actions := []string{
+		AuditActionExport, AuditActionImport, AuditActionExport, AuditActionPlaidConnect,
+		AuditActionExport, AuditActionExport, AuditActionImport, AuditActionExport,
+	}
+	for i, action := range actions {
+		now := start.Add(time.Duration(i) * time.Hour)
+		svc.now = func() time.Time { return now }
+		svc.RecordAudit(ctx, "user1", action, "", map[string]interface{}{"n": i})
+	}
+	svc.RecordAudit(ctx, "user2", AuditActionExport, "", nil)
+
+	// Five exports, newest first, two at a time
+	var seen []interface{}
+	query := AuditLogQuery{Action: AuditActionExport, Limit: 2}
+	for pages := 1; ; pages++ {
+		page, err := svc.List(ctx, "user1", query)
+		require.NoError(t, err)
+		for _, entry := range page.Entries {
+			assert.Equal(t, AuditActionExport, entry.Action)
+			seen = append(seen, entry.Details["n"])
+		}
+		if page.NextCursor == "" {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []interface{}{7, 5, 4, 2, 0}, seen)
+
+	// Imports within [01:00, 06:00)
+	page, err := svc.List(ctx, "user1", AuditLogQuery{
+		Action: AuditActionImport,
+		From:   start.Add(time.Hour),
+		To:     start.Add(6 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, 1, page.Entries[0].Details["n"])
+
+	_, err = svc.List(ctx, "user1", AuditLogQuery{Cursor: "yesterday"})
+	assert.ErrorIs(t, err, ErrInvalidAuditQuery)
+	_, err = svc.List(ctx, "user1", AuditLogQuery{From: start, To: start})
+	assert.ErrorIs(t, err, ErrInvalidAuditQuery)
+}
+
+func TestAuditService_NilIsNoop(t *testing.T) {
+	var svc *AuditService
+	svc.Record(context.Background(), "user1", AuditEntry{Action: AuditActionExport})
+}
+
+func TestDocumentService_AuditLogIsReadOnly(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/auditLog/a1", map[string]interface{}{"id": "a1", "action": AuditActionExport})
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, nil)
+	ctx := context.Background()
+
+	doc, err := svc.Get(ctx, "user1", "auditLog", "a1")
+	require.NoError(t, err)
+	assert.Equal(t, AuditActionExport, doc["action"])
+
+	_, err = svc.Patch(ctx, "user1", "auditLog", "a1", []PatchOperation{{Op: "replace", Path: "/action", Value: "tampered"}})
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.Duplicate(ctx, "user1", "auditLog", "a1", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.BatchDelete(ctx, "user1", "auditLog", []string{"a1"})
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.List(ctx, "user1", "auditLog", nil, DocumentListFilters{}, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	assert.Equal(t, AuditActionExport, repo.Documents["users/user1/auditLog/a1"]["action"])
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultBestFocusDays = 90
+	maxBestFocusDays     = 365
+
+	// minBestFocusSessions is the fewest sessions a time of day needs to be recommended
+	minBestFocusSessions = 3
+	// fullConfidenceSessions is how many sessions in the recommended time of
+	// day give full confidence
+	fullConfidenceSessions = 20
+)
+
+// Best focus time confidence levels
+const (
+	FocusConfidenceLow    = "low"
+	FocusConfidenceMedium = "medium"
+	FocusConfidenceHigh   = "high"
+)
+
+// timeOfDayPeriods lists the computeTimeOfDayData buckets in the order of the day
+var timeOfDayPeriods = []string{"morning", "afternoon", "evening", "night"}
+
+// timeOfDayPhrases words each bucket for the recommendation text
+var timeOfDayPhrases = map[string]string{
+	"morning":   "in the morning",
+	"afternoon": "in the afternoon",
+	"evening":   "in the evening",
+	"night":     "at night",
+}
+
+// BestFocusTime recommends the time of day the user focuses best. Period is
+// empty when no time of day has enough sessions yet.
+type BestFocusTime struct {
+	Period          string             `json:"period"`
+	Recommendation  string             `json:"recommendation"`
+	Confidence      float64            `json:"confidence"` // 0-1
+	ConfidenceLevel string             `json:"confidenceLevel"`
+	Days            int                `json:"days"`
+	Timezone        string             `json:"timezone"`
+	Periods         []FocusPeriodStats `json:"periods"`
+}
+
+// FocusPeriodStats are the supporting stats for one time of day. Score
+// weighs the completion rate and the focus minutes per session equally.
+type FocusPeriodStats struct {
+	Period string `json:"period"`
+	TimeOfDayStats
+	AvgFocusMinutes float64 `json:"avgFocusMinutes"` // per session
+	Score           float64 `json:"score"`           // 0-1
+}
+
+// BestFocusTime buckets the focus sessions of the last days days by time of
+// day in the given IANA timezone (UTC when empty) and recommends the bucket
+// with the best mix of task completion rate and focus minutes per session.
+// Confidence grows with the number of sessions behind the recommendation.
+func (s *DashboardAnalyticsService) BestFocusTime(ctx context.Context, uid string, days int, timezone string) (*BestFocusTime, error) {
+	if days <= 0 {
+		days = defaultBestFocusDays
+	}
+	if days > maxBestFocusDays {
+		days = maxBestFocusDays
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+
+	now := time.Now()
+	sessions, err := s.fetchSessions(ctx, uid, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bucket by the user's local hour rather than the stored UTC one
+	local := make([]map[string]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		startTime, ok := session["startTime"].(time.Time)
+		if !ok {
+			continue
+		}
+		copied := make(map[string]interface{}, len(session))
+		for key, value := range session {
+			copied[key] = value
+		}
+		copied["startTime"] = startTime.In(loc)
+		local = append(local, copied)
+	}
+
+	result := recommendFocusTime(s.computeTimeOfDayData(local))
+	result.Days = days
+	result.Timezone = timezone
+	return result, nil
+}
+
+// recommendFocusTime scores each time of day and picks the best one with at
+// least minBestFocusSessions sessions
+func recommendFocusTime(timeOfDay map[string]TimeOfDayStats) *BestFocusTime {
+	result := &BestFocusTime{Periods: make([]FocusPeriodStats, 0, len(timeOfDayPeriods))}
+
+	maxMinutes := 0.0
+	for _, period := range timeOfDayPeriods {
+		stats := timeOfDay[period]
+		entry := FocusPeriodStats{Period: period, TimeOfDayStats: stats}
+		if stats.Sessions > 0 {
+			entry.AvgFocusMinutes = float64(stats.TotalTime) / 60 / float64(stats.Sessions)
+		}
+		maxMinutes = math.Max(maxMinutes, entry.AvgFocusMinutes)
+		result.Periods = append(result.Periods, entry)
+	}
+
+	best := -1
+	for i := range result.Periods {
+		entry := &result.Periods[i]
+		if entry.Sessions == 0 {
+			continue
+		}
+		focus := 0.0
+		if maxMinutes > 0 {
+			focus = entry.AvgFocusMinutes / maxMinutes
+		}
+		entry.Score = math.Round((entry.AvgCompletion/100*0.5+focus*0.5)*100) / 100
+		entry.AvgFocusMinutes = math.Round(entry.AvgFocusMinutes*10) / 10
+		entry.AvgCompletion = math.Round(entry.AvgCompletion*10) / 10
+
+		if entry.Sessions >= minBestFocusSessions && (best < 0 || entry.Score > result.Periods[best].Score) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		result.ConfidenceLevel = FocusConfidenceLow
+		result.Recommendation = fmt.Sprintf("Log at least %d focus sessions at one time of day to get a recommendation", minBestFocusSessions)
+		return result
+	}
+
+	chosen := result.Periods[best]
+	result.Period = chosen.Period
+	result.Confidence = math.Round(math.Min(1, float64(chosen.Sessions)/fullConfidenceSessions)*100) / 100
+	switch {
+	case result.Confidence >= 0.75:
+		result.ConfidenceLevel = FocusConfidenceHigh
+	case result.Confidence >= 0.4:
+		result.ConfidenceLevel = FocusConfidenceMedium
+	default:
+		result.ConfidenceLevel = FocusConfidenceLow
+	}
+	result.Recommendation = fmt.Sprintf("You focus best %s: %.0f%% of tasks completed and %.0f minutes per session on average",
+		timeOfDayPhrases[chosen.Period], chosen.AvgCompletion, chosen.AvgFocusMinutes)
+	return result
+}
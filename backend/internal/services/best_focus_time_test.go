@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestDashboardAnalyticsService_BestFocusTime(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+	uid := "user-1"
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+	today := time.Now().In(toronto)
+	at := func(daysAgo, hour int) time.Time {
+		return time.Date(today.Year(), today.Month(), today.Day()-daysAgo, hour, 0, 0, 0, toronto).UTC()
+	}
+	addSession := func(id string, start time.Time, minutes int64, completed ...bool) {
+		tasks := []interface{}{}
+		for _, done := range completed {
+			tasks = append(tasks, map[string]interface{}{"timeSpent": minutes * 60 / int64(len(completed)), "completed": done})
+		}
+		repo.AddDocument(fmt.Sprintf("users/%s/focusSessions/%s", uid, id), map[string]interface{}{
+			"startTime": start,
+			"tasks":     tasks,
+		})
+	}
+
+	// Long, productive mornings; short, scattered evenings
+	for i := 1; i <= 12; i++ {
+		addSession(fmt.Sprintf("morning-%d", i), at(i, 8), 50, true, true)
+	}
+	for i := 1; i <= 6; i++ {
+		addSession(fmt.Sprintf("evening-%d", i), at(i, 19), 20, true, false, false)
+	}
+	// Two late sessions are too few to recommend
+	addSession("night-1", at(3, 23), 90, true)
+	addSession("night-2", at(4, 23), 90, true)
+	// Sessions outside the window are ignored
+	for i := 0; i < 10; i++ {
+		addSession(fmt.Sprintf("old-%d", i), at(200+i, 19), 120, true)
+	}
+
+	result, err := service.BestFocusTime(ctx, uid, 90, "America/Toronto")
+	require.NoError(t, err)
+	assert.Equal(t, "morning", result.Period)
+	assert.Equal(t, 0.6, result.Confidence)
+	assert.Equal(t, FocusConfidenceMedium, result.ConfidenceLevel)
+	assert.Contains(t, result.Recommendation, "in the morning")
+	require.Len(t, result.Periods, 4)
+
+	morning, evening, night := result.Periods[0], result.Periods[2], result.Periods[3]
+	assert.Equal(t, 12, morning.Sessions)
+	assert.Equal(t, 100.0, morning.AvgCompletion)
+	assert.Equal(t, 50.0, morning.AvgFocusMinutes)
+	assert.Equal(t, 6, evening.Sessions)
+	assert.Equal(t, 33.3, evening.AvgCompletion)
+	assert.Greater(t, morning.Score, evening.Score)
+	assert.Equal(t, 2, night.Sessions)
+	assert.Greater(t, night.Score, morning.Score, "night scores best but has too few sessions")
+
+	// In UTC the same mornings fall in the afternoon
+	utc, err := service.BestFocusTime(ctx, uid, 90, "")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", utc.Timezone)
+	assert.NotEqual(t, "morning", utc.Period)
+
+	_, err = service.BestFocusTime(ctx, uid, 90, "Mars/Base")
+	assert.True(t, errors.Is(err, ErrInvalidTimezone))
+}
+
+func TestDashboardAnalyticsService_BestFocusTime_NotEnoughData(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+	repo.AddDocument("users/user-1/focusSessions/s1", map[string]interface{}{
+		"startTime": time.Now().Add(-time.Hour),
+		"tasks":     []interface{}{map[string]interface{}{"timeSpent": int64(1500), "completed": true}},
+	})
+
+	result, err := service.BestFocusTime(context.Background(), "user-1", 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Period)
+	assert.Equal(t, FocusConfidenceLow, result.ConfidenceLevel)
+	assert.Equal(t, defaultBestFocusDays, result.Days)
+	assert.Zero(t, result.Confidence)
+}
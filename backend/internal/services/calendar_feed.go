@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// ErrInvalidFeedToken is returned for unknown or revoked calendar feed tokens
+var ErrInvalidFeedToken = errors.New("invalid calendar feed token")
+
+// ErrInvalidTimezone is returned for timezones that are not IANA names
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+const (
+	icsDateFormat     = "20060102"
+	icsDateTimeFormat = "20060102T150405Z"
+	// icsLineLimit is the maximum line length in octets before folding (RFC 5545 3.1)
+	icsLineLimit = 75
+)
+
+// CalendarFeedToken is a user's secret calendar feed token. Calendar apps
+// cannot send bearer headers, so the token in the feed URL authenticates it.
+type CalendarFeedToken struct {
+	Token     string    `json:"token"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CalendarFeedService builds iCalendar feeds of tasks and focus sessions.
+// Tokens are stored at calendarFeeds/{token} so a feed request can be
+// resolved to its user, with the user's current token at
+// users/{uid}/calendarFeed/current so it can be revoked.
+type CalendarFeedService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewCalendarFeedService creates a new calendar feed service
+func NewCalendarFeedService(repo interfaces.Repository, logger *zap.Logger) *CalendarFeedService {
+	return &CalendarFeedService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RotateToken issues a new feed token for the user, revoking the previous
+// one. Feed times are rendered in timezone, an IANA name; empty means UTC.
+func (s *CalendarFeedService) RotateToken(ctx context.Context, uid, timezone string) (*CalendarFeedToken, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+
+	if err := s.RevokeToken(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := &CalendarFeedToken{
+		Token:     hex.EncodeToString(secret),
+		Timezone:  timezone,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.SetDocument(ctx, calendarFeedPath(token.Token), map[string]interface{}{
+		"uid":       uid,
+		"timezone":  token.Timezone,
+		"createdAt": token.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save feed token: %w", err)
+	}
+	if err := s.repo.SetDocument(ctx, userCalendarFeedPath(uid), map[string]interface{}{
+		"token":     token.Token,
+		"timezone":  token.Timezone,
+		"createdAt": token.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save feed token: %w", err)
+	}
+
+	s.logger.Info("Calendar feed token issued", zap.String("uid", uid))
+	return token, nil
+}
+
+// RevokeToken deletes the user's feed token, if any
+func (s *CalendarFeedService) RevokeToken(ctx context.Context, uid string) error {
+	current, err := s.repo.Get(ctx, userCalendarFeedPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load feed token: %w", err)
+	}
+
+	if token := getStringField(current, "token"); token != "" {
+		if err := s.repo.Delete(ctx, calendarFeedPath(token)); err != nil {
+			return fmt.Errorf("failed to revoke feed token: %w", err)
+		}
+	}
+	if err := s.repo.Delete(ctx, userCalendarFeedPath(uid)); err != nil {
+		return fmt.Errorf("failed to revoke feed token: %w", err)
+	}
+	return nil
+}
+
+// Feed resolves a feed token and renders the user's feed
+func (s *CalendarFeedService) Feed(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidFeedToken
+	}
+	data, err := s.repo.Get(ctx, calendarFeedPath(token))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return "", ErrInvalidFeedToken
+		}
+		return "", fmt.Errorf("failed to load feed token: %w", err)
+	}
+	uid := getStringField(data, "uid")
+	if uid == "" {
+		return "", ErrInvalidFeedToken
+	}
+	loc, err := time.LoadLocation(getStringField(data, "timezone"))
+	if err != nil {
+		loc = time.UTC
+	}
+
+	tasks, err := s.repo.List(ctx, fmt.Sprintf("users/%s/tasks", uid), 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tasks: %w", err)
+	}
+	sessions, err := s.repo.List(ctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to list focus sessions: %w", err)
+	}
+
+	return BuildCalendarFeed(tasks, sessions, loc, time.Now()), nil
+}
+
+// BuildCalendarFeed renders tasks with a dueDate as all-day events on their
+// due day in loc, and focus sessions as timed events. Archived tasks and
+// sessions without a start time are skipped. now stamps entries that have no
+// updatedAt.
+func BuildCalendarFeed(tasks, sessions []map[string]interface{}, loc *time.Location, now time.Time) string {
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//Focus Notebook//Calendar Feed//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:Focus Notebook")
+	writeICSLine(&b, "X-WR-TIMEZONE:"+loc.String())
+
+	sortByID(tasks)
+	for _, task := range tasks {
+		if getStringField(task, "status") == "archived" {
+			continue
+		}
+		due, ok := activityDay(task["dueDate"], loc)
+		if !ok {
+			continue
+		}
+		start, _ := time.Parse("2006-01-02", due)
+
+		title := getStringField(task, "title")
+		if title == "" {
+			title = "Untitled Task"
+		}
+		if done, _ := task["done"].(bool); done || getStringField(task, "status") == "completed" {
+			title = "✓ " + title
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:task-"+getStringField(task, "id")+"@focus-notebook")
+		writeICSLine(&b, "DTSTAMP:"+icsStamp(task, now))
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+start.Format(icsDateFormat))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+start.AddDate(0, 0, 1).Format(icsDateFormat))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(title))
+		if notes := getStringField(task, "notes"); notes != "" {
+			writeICSLine(&b, "DESCRIPTION:"+escapeICSText(notes))
+		}
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	sortByID(sessions)
+	for _, session := range sessions {
+		start, ok := parseFlexibleDate(session["startTime"])
+		if !ok {
+			continue
+		}
+		end, ok := parseFlexibleDate(session["endTime"])
+		if !ok || !end.After(start) {
+			minutes, _ := toFloat(session["duration"])
+			if minutes <= 0 {
+				minutes = 25
+			}
+			end = start.Add(time.Duration(minutes * float64(time.Minute)))
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:focus-"+getStringField(session, "id")+"@focus-notebook")
+		writeICSLine(&b, "DTSTAMP:"+icsStamp(session, now))
+		writeICSLine(&b, "DTSTART:"+start.UTC().Format(icsDateTimeFormat))
+		writeICSLine(&b, "DTEND:"+end.UTC().Format(icsDateTimeFormat))
+		writeICSLine(&b, "SUMMARY:Focus session")
+		if titles := focusSessionTaskTitles(session); len(titles) > 0 {
+			writeICSLine(&b, "DESCRIPTION:"+escapeICSText(strings.Join(titles, "\n")))
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// focusSessionTaskTitles lists the titles of a session's tasks
+func focusSessionTaskTitles(session map[string]interface{}) []string {
+	items, _ := session["tasks"].([]interface{})
+	var titles []string
+	for _, item := range items {
+		entry, _ := item.(map[string]interface{})
+		task, _ := entry["task"].(map[string]interface{})
+		if title := getStringField(task, "title"); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// icsStamp formats a document's updatedAt, or now, as a UTC DTSTAMP
+func icsStamp(doc map[string]interface{}, now time.Time) string {
+	if updatedAt, ok := parseFlexibleDate(doc["updatedAt"]); ok {
+		return updatedAt.UTC().Format(icsDateTimeFormat)
+	}
+	return now.UTC().Format(icsDateTimeFormat)
+}
+
+// escapeICSText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeICSText(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, ";", "\\;")
+	value = strings.ReplaceAll(value, ",", "\\,")
+	value = strings.ReplaceAll(value, "\r\n", "\\n")
+	value = strings.ReplaceAll(value, "\n", "\\n")
+	return value
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it at
+// icsLineLimit octets without splitting UTF-8 characters
+func writeICSLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// sortByID orders documents by id so feeds are stable between requests
+func sortByID(docs []map[string]interface{}) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return getStringField(docs[i], "id") < getStringField(docs[j], "id")
+	})
+}
+
+func calendarFeedPath(token string) string {
+	return fmt.Sprintf("calendarFeeds/%s", token)
+}
+
+func userCalendarFeedPath(uid string) string {
+	return fmt.Sprintf("users/%s/calendarFeed/current", uid)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// icsComponent is a parsed iCalendar component
+type icsComponent struct {
+	name       string
+	properties map[string]string // property name with parameters -> value
+	children   []*icsComponent
+}
+
+// parseICS unfolds and parses an iCalendar document, failing on structural errors
+func parseICS(t *testing.T, feed string) *icsComponent {
+	t.Helper()
+	require.True(t, strings.HasSuffix(feed, "\r\n"), "feed must end with CRLF")
+
+	var lines []string
+	for _, raw := range strings.Split(strings.TrimSuffix(feed, "\r\n"), "\r\n") {
+		require.NotContains(t, raw, "\n", "bare LF in content line")
+		require.LessOrEqual(t, len(raw), icsLineLimit, "line exceeds 75 octets: %q", raw)
+		if strings.HasPrefix(raw, " ") {
+			require.NotEmpty(t, lines, "continuation line without a preceding line")
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		lines = append(lines, raw)
+	}
+
+	var stack []*icsComponent
+	var root *icsComponent
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		require.True(t, ok, "content line without a value: %q", line)
+		switch name {
+		case "BEGIN":
+			component := &icsComponent{name: value, properties: map[string]string{}}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, component)
+			} else {
+				require.Nil(t, root, "more than one top-level component")
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			require.NotEmpty(t, stack, "END without BEGIN")
+			require.Equal(t, stack[len(stack)-1].name, value, "mismatched END")
+			stack = stack[:len(stack)-1]
+		default:
+			require.NotEmpty(t, stack, "property outside a component: %q", line)
+			stack[len(stack)-1].properties[name] = value
+		}
+	}
+	require.Empty(t, stack, "unterminated component")
+	require.NotNil(t, root)
+	return root
+}
+
+func TestBuildCalendarFeed(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tasks := []map[string]interface{}{
+		{"id": "t2", "title": "Pay rent; call landlord, today", "dueDate": "2024-03-05", "notes": "line one\nline two"},
+		// 02:30 UTC on the 10th is still the 9th in New York
+		{"id": "t1", "title": "File taxes", "dueDate": time.Date(2024, 3, 10, 2, 30, 0, 0, time.UTC), "done": true},
+		{"id": "t3", "title": "No due date"},
+		{"id": "t4", "title": "Archived", "dueDate": "2024-03-06", "status": "archived"},
+	}
+	sessions := []map[string]interface{}{
+		{
+			"id":        "f1",
+			"startTime": time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC),
+			"duration":  45.0,
+			"tasks": []interface{}{
+				map[string]interface{}{"task": map[string]interface{}{"id": "t2", "title": strings.Repeat("Long task title ", 8)}},
+			},
+		},
+		{"id": "f2", "startTime": time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC), "endTime": time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC)},
+		{"id": "f3"},
+	}
+
+	calendar := parseICS(t, BuildCalendarFeed(tasks, sessions, loc, now))
+	assert.Equal(t, "VCALENDAR", calendar.name)
+	assert.Equal(t, "2.0", calendar.properties["VERSION"])
+	assert.NotEmpty(t, calendar.properties["PRODID"])
+	assert.Equal(t, "America/New_York", calendar.properties["X-WR-TIMEZONE"])
+	require.Len(t, calendar.children, 4)
+
+	for _, event := range calendar.children {
+		assert.Equal(t, "VEVENT", event.name)
+		assert.NotEmpty(t, event.properties["UID"])
+		assert.Equal(t, "20240301T120000Z", event.properties["DTSTAMP"])
+	}
+
+	taxes := calendar.children[0]
+	assert.Equal(t, "task-t1@focus-notebook", taxes.properties["UID"])
+	assert.Equal(t, "20240309", taxes.properties["DTSTART;VALUE=DATE"])
+	assert.Equal(t, "20240310", taxes.properties["DTEND;VALUE=DATE"])
+	assert.Equal(t, "✓ File taxes", taxes.properties["SUMMARY"])
+
+	rent := calendar.children[1]
+	assert.Equal(t, "20240305", rent.properties["DTSTART;VALUE=DATE"])
+	assert.Equal(t, `Pay rent\; call landlord\, today`, rent.properties["SUMMARY"])
+	assert.Equal(t, `line one\nline two`, rent.properties["DESCRIPTION"])
+
+	focus := calendar.children[2]
+	assert.Equal(t, "focus-f1@focus-notebook", focus.properties["UID"])
+	assert.Equal(t, "20240301T140000Z", focus.properties["DTSTART"])
+	assert.Equal(t, "20240301T144500Z", focus.properties["DTEND"])
+	assert.Equal(t, strings.TrimSpace(strings.Repeat("Long task title ", 8)), strings.TrimSpace(focus.properties["DESCRIPTION"]))
+
+	assert.Equal(t, "20240302T093000Z", calendar.children[3].properties["DTEND"])
+}
+
+func TestWriteICSLine_FoldsWithoutSplittingRunes(t *testing.T) {
+	var b strings.Builder
+	line := "SUMMARY:" + strings.Repeat("é", 100)
+	writeICSLine(&b, line)
+
+	folded := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n ")
+	for _, part := range folded {
+		assert.LessOrEqual(t, len(part), icsLineLimit)
+		assert.True(t, strings.ToValidUTF8(part, "?") == part, "fold split a character")
+	}
+	assert.Equal(t, line, strings.Join(folded, ""))
+}
+
+func TestCalendarFeedService_TokenLifecycle(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/u1/tasks/t1", map[string]interface{}{"id": "t1", "title": "Ship", "dueDate": "2024-03-05"})
+	svc := NewCalendarFeedService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.RotateToken(ctx, "u1", "Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidTimezone)
+
+	first, err := svc.RotateToken(ctx, "u1", "Europe/Berlin")
+	require.NoError(t, err)
+	assert.Len(t, first.Token, 48)
+
+	feed, err := svc.Feed(ctx, first.Token)
+	require.NoError(t, err)
+	assert.Contains(t, feed, "SUMMARY:Ship\r\n")
+	assert.Contains(t, feed, "X-WR-TIMEZONE:Europe/Berlin\r\n")
+
+	// Rotating revokes the previous token
+	second, err := svc.RotateToken(ctx, "u1", "")
+	require.NoError(t, err)
+	_, err = svc.Feed(ctx, first.Token)
+	assert.ErrorIs(t, err, ErrInvalidFeedToken)
+	_, err = svc.Feed(ctx, second.Token)
+	assert.NoError(t, err)
+
+	require.NoError(t, svc.RevokeToken(ctx, "u1"))
+	_, err = svc.Feed(ctx, second.Token)
+	assert.ErrorIs(t, err, ErrInvalidFeedToken)
+	_, err = svc.Feed(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalidFeedToken)
+}
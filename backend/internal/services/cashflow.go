@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cash flow classifications
+const (
+	CashFlowIncome   = "income"
+	CashFlowExpense  = "expense"
+	CashFlowTransfer = "transfer"
+)
+
+// Cash flow override kinds
+const (
+	CashFlowOverrideMerchant = "merchant"
+	CashFlowOverrideCategory = "category"
+)
+
+const (
+	defaultCashFlowMonths = 6
+	maxCashFlowMonths     = 24
+
+	// transferMatchWindow is how far apart the two legs of a transfer may post
+	transferMatchWindow = 3 * 24 * time.Hour
+)
+
+// ErrInvalidCashFlowOverride is returned for an override with an unknown kind or classification
+var ErrInvalidCashFlowOverride = errors.New("invalid cash flow override")
+
+// CashFlow holds monthly income and expenses
+type CashFlow struct {
+	Months            []CashFlowMonth `json:"months"`
+	Totals            CashFlowTotals  `json:"totals"`
+	TransfersExcluded int             `json:"transfersExcluded"`
+	Currency          string          `json:"currency"`
+}
+
+// CashFlowMonth holds income and expenses for one calendar month (YYYY-MM)
+type CashFlowMonth struct {
+	Month string `json:"month"`
+	CashFlowTotals
+}
+
+// CashFlowTotals holds income, expenses, net and savings rate (percent of income)
+type CashFlowTotals struct {
+	Income      float64 `json:"income"`
+	Expenses    float64 `json:"expenses"`
+	Net         float64 `json:"net"`
+	SavingsRate float64 `json:"savingsRate"`
+}
+
+// CashFlowOverride forces a classification for a merchant or category
+type CashFlowOverride struct {
+	Kind           string `json:"kind"`
+	Match          string `json:"match"`
+	Classification string `json:"classification"`
+}
+
+// classifiedTransaction is a transaction with its cash flow classification
+type classifiedTransaction struct {
+	date           time.Time
+	amount         float64 // signed: positive = outflow, negative = inflow
+	accountID      string
+	classification string
+	overridden     bool
+}
+
+// ComputeCashFlow returns income, expenses and net for the last months calendar
+// months, including the current one. Amounts follow Plaid's convention:
+// positive is money out (expense), negative is money in (income). User
+// overrides by merchant or category take precedence over the sign, and pairs of
+// offsetting amounts between two of the user's accounts are excluded as transfers.
+func (s *SpendingAnalyticsService) ComputeCashFlow(ctx context.Context, uid string, months int) (*CashFlow, error) {
+	if months <= 0 {
+		months = defaultCashFlowMonths
+	}
+	if months > maxCashFlowMonths {
+		months = maxCashFlowMonths
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.fetchTransactions(ctx, uid, start, now, nil)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.ListCashFlowOverrides(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := s.currency.Converter(ctx, prefs.Currency)
+	transactions = s.convertTransactions(transactions, converter)
+	classified := s.classifyTransactions(transactions, overrides, s.merchantAliases.Matcher(ctx, uid))
+	cashFlow := summarizeCashFlow(classified, start, months)
+	cashFlow.Currency = converter.Target()
+	return cashFlow, nil
+}
+
+// classifyTransactions classifies each transaction with a parseable date and
+// then marks offsetting transfers
+func (s *SpendingAnalyticsService) classifyTransactions(transactions []map[string]interface{}, overrides []CashFlowOverride, merchants *MerchantMatcher) []*classifiedTransaction {
+	byMerchant := map[string]string{}
+	byCategory := map[string]string{}
+	for _, o := range overrides {
+		if o.Kind == CashFlowOverrideMerchant {
+			byMerchant[o.Match] = o.Classification
+		} else {
+			byCategory[o.Match] = o.Classification
+		}
+	}
+
+	classified := make([]*classifiedTransaction, 0, len(transactions))
+	for _, txn := range transactions {
+		date, ok := parseTransactionDate(txn["postedAt"])
+		if !ok {
+			date, ok = parseTransactionDate(txn["date"])
+		}
+		if !ok {
+			continue
+		}
+
+		ct := &classifiedTransaction{
+			date:      date,
+			amount:    s.getSignedAmount(txn),
+			accountID: s.getStringField(txn, "accountId"),
+		}
+
+		merchant, _ := merchants.Canonicalize(s.getMerchantName(txn))
+		if class, ok := byMerchant[normalizeMerchantName(merchant)]; ok {
+			ct.classification, ct.overridden = class, true
+		} else if class, ok := byCategory[strings.ToLower(s.getCategory(txn))]; ok {
+			ct.classification, ct.overridden = class, true
+		} else if ct.amount < 0 {
+			ct.classification = CashFlowIncome
+		} else {
+			ct.classification = CashFlowExpense
+		}
+		classified = append(classified, ct)
+	}
+
+	markTransfers(classified)
+	return classified
+}
+
+// markTransfers pairs an outflow with an inflow of the same amount in a
+// different account of the user, posted within transferMatchWindow, and marks
+// both legs as a transfer. Overridden transactions are left alone.
+func markTransfers(transactions []*classifiedTransaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].date.Before(transactions[j].date)
+	})
+
+	for i, out := range transactions {
+		if out.overridden || out.classification == CashFlowTransfer || out.amount <= 0 || out.accountID == "" {
+			continue
+		}
+		for _, in := range transactions[i+1:] {
+			if in.date.Sub(out.date) > transferMatchWindow {
+				break
+			}
+			if isTransferLeg(out, in) {
+				out.classification, in.classification = CashFlowTransfer, CashFlowTransfer
+				break
+			}
+		}
+		if out.classification == CashFlowTransfer {
+			continue
+		}
+		// The inflow may have posted first
+		for j := i - 1; j >= 0; j-- {
+			in := transactions[j]
+			if out.date.Sub(in.date) > transferMatchWindow {
+				break
+			}
+			if isTransferLeg(out, in) {
+				out.classification, in.classification = CashFlowTransfer, CashFlowTransfer
+				break
+			}
+		}
+	}
+}
+
+func isTransferLeg(out, in *classifiedTransaction) bool {
+	return !in.overridden &&
+		in.classification != CashFlowTransfer &&
+		in.amount < 0 &&
+		in.accountID != "" &&
+		in.accountID != out.accountID &&
+		math.Abs(out.amount+in.amount) <= duplicateAmountTolerance
+}
+
+// summarizeCashFlow buckets classified transactions into calendar months
+func summarizeCashFlow(transactions []*classifiedTransaction, start time.Time, months int) *CashFlow {
+	result := &CashFlow{Months: make([]CashFlowMonth, months)}
+	index := make(map[string]int, months)
+	for i := 0; i < months; i++ {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		result.Months[i].Month = month
+		index[month] = i
+	}
+
+	for _, txn := range transactions {
+		i, ok := index[txn.date.Format("2006-01")]
+		if !ok {
+			continue
+		}
+		month := &result.Months[i].CashFlowTotals
+		switch txn.classification {
+		case CashFlowIncome:
+			month.Income += math.Abs(txn.amount)
+		case CashFlowExpense:
+			month.Expenses += math.Abs(txn.amount)
+		case CashFlowTransfer:
+			result.TransfersExcluded++
+		}
+	}
+
+	for i := range result.Months {
+		month := &result.Months[i].CashFlowTotals
+		month.finalize()
+		result.Totals.Income += month.Income
+		result.Totals.Expenses += month.Expenses
+	}
+	result.Totals.finalize()
+	return result
+}
+
+func (t *CashFlowTotals) finalize() {
+	t.Net = t.Income - t.Expenses
+	if t.Income > 0 {
+		t.SavingsRate = t.Net / t.Income * 100
+	}
+}
+
+// ListCashFlowOverrides returns the user's classification overrides
+func (s *SpendingAnalyticsService) ListCashFlowOverrides(ctx context.Context, uid string) ([]CashFlowOverride, error) {
+	docs, err := s.repo.List(ctx, cashFlowOverridesPath(uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cash flow overrides: %w", err)
+	}
+
+	overrides := make([]CashFlowOverride, 0, len(docs))
+	for _, doc := range docs {
+		overrides = append(overrides, CashFlowOverride{
+			Kind:           s.getStringField(doc, "kind"),
+			Match:          s.getStringField(doc, "match"),
+			Classification: s.getStringField(doc, "classification"),
+		})
+	}
+	return overrides, nil
+}
+
+// SetCashFlowOverride creates or replaces the override for a merchant or
+// category. Merchant names are normalized; categories are matched case-insensitively.
+func (s *SpendingAnalyticsService) SetCashFlowOverride(ctx context.Context, uid string, override CashFlowOverride) (*CashFlowOverride, error) {
+	switch override.Kind {
+	case CashFlowOverrideMerchant:
+		override.Match = normalizeMerchantName(override.Match)
+	case CashFlowOverrideCategory:
+		override.Match = strings.ToLower(strings.TrimSpace(override.Match))
+	default:
+		return nil, fmt.Errorf("%w: kind must be merchant or category", ErrInvalidCashFlowOverride)
+	}
+	switch override.Classification {
+	case CashFlowIncome, CashFlowExpense, CashFlowTransfer:
+	default:
+		return nil, fmt.Errorf("%w: classification must be income, expense or transfer", ErrInvalidCashFlowOverride)
+	}
+	if override.Match == "" {
+		return nil, fmt.Errorf("%w: match is required", ErrInvalidCashFlowOverride)
+	}
+
+	path := fmt.Sprintf("%s/%s", cashFlowOverridesPath(uid), cashFlowOverrideID(override.Kind, override.Match))
+	if err := s.repo.SetDocument(ctx, path, map[string]interface{}{
+		"kind":           override.Kind,
+		"match":          override.Match,
+		"classification": override.Classification,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save cash flow override: %w", err)
+	}
+	return &override, nil
+}
+
+// DeleteCashFlowOverride removes the override for a merchant or category
+func (s *SpendingAnalyticsService) DeleteCashFlowOverride(ctx context.Context, uid, kind, match string) error {
+	if kind == CashFlowOverrideMerchant {
+		match = normalizeMerchantName(match)
+	} else {
+		match = strings.ToLower(strings.TrimSpace(match))
+	}
+	path := fmt.Sprintf("%s/%s", cashFlowOverridesPath(uid), cashFlowOverrideID(kind, match))
+	if _, err := s.repo.Get(ctx, path); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, path)
+}
+
+// cashFlowOverrideID derives a stable document ID for an override, so setting
+// the same merchant or category twice replaces the earlier override
+func cashFlowOverrideID(kind, match string) string {
+	sum := sha256.Sum256([]byte(match))
+	return kind + "_" + hex.EncodeToString(sum[:8])
+}
+
+func cashFlowOverridesPath(uid string) string {
+	return fmt.Sprintf("users/%s/cashflowOverrides", uid)
+}
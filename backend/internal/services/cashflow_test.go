@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func classificationsByAmount(classified []*classifiedTransaction) map[float64]string {
+	result := make(map[float64]string, len(classified))
+	for _, txn := range classified {
+		result[txn.amount] = txn.classification
+	}
+	return result
+}
+
+func TestClassifyTransactions_ExcludesTransfers(t *testing.T) {
+	service := NewSpendingAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil, nil)
+
+	transactions := []map[string]interface{}{
+		// Paycheck into checking
+		{"postedAt": "2024-03-01", "accountId": "checking", "amount": -3000.0, "name": "ACME PAYROLL"},
+		// Checking -> savings: outflow and inflow a day apart
+		{"postedAt": "2024-03-02", "accountId": "checking", "amount": 500.0},
+		{"postedAt": "2024-03-03", "accountId": "savings", "amount": -500.0},
+		// Credit card payment: inflow on the card posts before the checking outflow
+		{"postedAt": "2024-03-09", "accountId": "credit", "amount": -250.25},
+		{"postedAt": "2024-03-10", "accountId": "checking", "amount": 250.25},
+		// Refund in the same account as the purchase is not a transfer
+		{"postedAt": "2024-03-11", "accountId": "credit", "amount": 42.0},
+		{"postedAt": "2024-03-12", "accountId": "credit", "amount": -42.0},
+		// Offsetting amounts too far apart are not a transfer
+		{"postedAt": "2024-03-15", "accountId": "checking", "amount": 75.0},
+		{"postedAt": "2024-03-25", "accountId": "savings", "amount": -75.0},
+	}
+
+	classified := service.classifyTransactions(transactions, nil, nil)
+	got := classificationsByAmount(classified)
+
+	assert.Equal(t, CashFlowIncome, got[-3000.0])
+	assert.Equal(t, CashFlowTransfer, got[500.0])
+	assert.Equal(t, CashFlowTransfer, got[-500.0])
+	assert.Equal(t, CashFlowTransfer, got[250.25])
+	assert.Equal(t, CashFlowTransfer, got[-250.25])
+	assert.Equal(t, CashFlowExpense, got[42.0])
+	assert.Equal(t, CashFlowIncome, got[-42.0])
+	assert.Equal(t, CashFlowExpense, got[75.0])
+	assert.Equal(t, CashFlowIncome, got[-75.0])
+}
+
+func TestClassifyTransactions_EachLegMatchesOnce(t *testing.T) {
+	service := NewSpendingAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil, nil)
+
+	transactions := []map[string]interface{}{
+		{"postedAt": "2024-03-02", "accountId": "checking", "amount": 100.0},
+		{"postedAt": "2024-03-02", "accountId": "checking", "amount": 100.0},
+		{"postedAt": "2024-03-03", "accountId": "savings", "amount": -100.0},
+	}
+
+	classified := service.classifyTransactions(transactions, nil, nil)
+
+	counts := map[string]int{}
+	for _, txn := range classified {
+		counts[txn.classification]++
+	}
+	assert.Equal(t, 2, counts[CashFlowTransfer])
+	assert.Equal(t, 1, counts[CashFlowExpense])
+}
+
+func TestClassifyTransactions_Overrides(t *testing.T) {
+	service := NewSpendingAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil, nil)
+
+	overrides := []CashFlowOverride{
+		{Kind: CashFlowOverrideMerchant, Match: "venmo", Classification: CashFlowIncome},
+		{Kind: CashFlowOverrideCategory, Match: "transfer_out", Classification: CashFlowTransfer},
+	}
+	transactions := []map[string]interface{}{
+		{"postedAt": "2024-03-02", "accountId": "checking", "amount": 60.0, "merchant": "VENMO"},
+		{"postedAt": "2024-03-03", "accountId": "checking", "amount": 900.0, "category": "TRANSFER_OUT"},
+		// Would pair with the overridden Venmo outflow if overrides were ignored
+		{"postedAt": "2024-03-03", "accountId": "savings", "amount": -60.0},
+	}
+
+	got := classificationsByAmount(service.classifyTransactions(transactions, overrides, nil))
+
+	assert.Equal(t, CashFlowIncome, got[60.0])
+	assert.Equal(t, CashFlowTransfer, got[900.0])
+	assert.Equal(t, CashFlowIncome, got[-60.0])
+}
+
+func TestSummarizeCashFlow(t *testing.T) {
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	classified := []*classifiedTransaction{
+		{date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), amount: -4000, classification: CashFlowIncome},
+		{date: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), amount: 1000, classification: CashFlowExpense},
+		{date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), amount: 500, classification: CashFlowTransfer},
+		{date: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), amount: 250, classification: CashFlowExpense},
+		{date: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), amount: 999, classification: CashFlowExpense},
+	}
+
+	result := summarizeCashFlow(classified, start, 2)
+
+	require.Len(t, result.Months, 2)
+	assert.Equal(t, "2024-02", result.Months[0].Month)
+	assert.Equal(t, 4000.0, result.Months[0].Income)
+	assert.Equal(t, 1000.0, result.Months[0].Expenses)
+	assert.Equal(t, 3000.0, result.Months[0].Net)
+	assert.Equal(t, 75.0, result.Months[0].SavingsRate)
+
+	assert.Equal(t, "2024-03", result.Months[1].Month)
+	assert.Equal(t, -250.0, result.Months[1].Net)
+	assert.Equal(t, 0.0, result.Months[1].SavingsRate)
+
+	assert.Equal(t, 2750.0, result.Totals.Net)
+	assert.Equal(t, 1, result.TransfersExcluded)
+}
+
+func TestComputeCashFlow(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, nil)
+	ctx := context.Background()
+	uid := "user-1"
+
+	today := time.Now().UTC().Format("2006-01-02")
+	repo.AddDocument("users/"+uid+"/transactions/a", map[string]interface{}{"postedAt": today, "accountId": "checking", "amount": -2000.0})
+	repo.AddDocument("users/"+uid+"/transactions/b", map[string]interface{}{"postedAt": today, "accountId": "checking", "amount": 300.0})
+	repo.AddDocument("users/"+uid+"/transactions/c", map[string]interface{}{"postedAt": today, "accountId": "savings", "amount": -300.0})
+	repo.AddDocument("users/"+uid+"/transactions/d", map[string]interface{}{"postedAt": today, "accountId": "credit", "amount": 500.0})
+
+	result, err := service.ComputeCashFlow(ctx, uid, 3)
+	require.NoError(t, err)
+	require.Len(t, result.Months, 3)
+
+	current := result.Months[2]
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), current.Month)
+	assert.Equal(t, 2000.0, current.Income)
+	assert.Equal(t, 500.0, current.Expenses)
+	assert.Equal(t, 75.0, current.SavingsRate)
+	assert.Equal(t, 2, result.TransfersExcluded)
+}
+
+func TestCashFlowOverrides_SetListDelete(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, nil)
+	ctx := context.Background()
+
+	saved, err := service.SetCashFlowOverride(ctx, "user-1", CashFlowOverride{
+		Kind: CashFlowOverrideMerchant, Match: "Venmo *Alex", Classification: CashFlowIncome,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "venmo alex", saved.Match)
+
+	// Setting the same merchant again replaces the override
+	_, err = service.SetCashFlowOverride(ctx, "user-1", CashFlowOverride{
+		Kind: CashFlowOverrideMerchant, Match: "VENMO alex", Classification: CashFlowTransfer,
+	})
+	require.NoError(t, err)
+
+	overrides, err := service.ListCashFlowOverrides(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, CashFlowTransfer, overrides[0].Classification)
+
+	require.NoError(t, service.DeleteCashFlowOverride(ctx, "user-1", CashFlowOverrideMerchant, "Venmo Alex"))
+	overrides, err = service.ListCashFlowOverrides(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	_, err = service.SetCashFlowOverride(ctx, "user-1", CashFlowOverride{Kind: "account", Match: "x", Classification: CashFlowIncome})
+	assert.True(t, errors.Is(err, ErrInvalidCashFlowOverride))
+	_, err = service.SetCashFlowOverride(ctx, "user-1", CashFlowOverride{Kind: CashFlowOverrideCategory, Match: "x", Classification: "savings"})
+	assert.True(t, errors.Is(err, ErrInvalidCashFlowOverride))
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"go.uber.org/zap"
@@ -11,23 +14,65 @@ import (
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
 )
 
+const (
+	// defaultMaxContextTokens is the context budget used when none is configured
+	defaultMaxContextTokens = 8000
+	// charsPerToken is a conservative approximation used to estimate token counts
+	charsPerToken = 4
+	// significantTruncationRatio is the share of dropped items that triggers a warning
+	significantTruncationRatio = 0.25
+)
+
 // ContextGathererService gathers user context for AI processing
 // Matches contextGatherer.ts from Firebase Functions
 type ContextGathererService struct {
-	repo   *repository.FirestoreRepository
-	logger *zap.Logger
+	repo             *repository.FirestoreRepository
+	logger           *zap.Logger
+	maxContextTokens int
+}
+
+// ContextUsage reports how much gathered context fit within the token budget
+type ContextUsage struct {
+	IncludedItems   int `json:"includedItems"`
+	DroppedItems    int `json:"droppedItems"`
+	EstimatedTokens int `json:"estimatedTokens"`
+	MaxTokens       int `json:"maxTokens"`
+}
+
+// Truncated reports whether any context was dropped
+func (u *ContextUsage) Truncated() bool {
+	return u != nil && u.DroppedItems > 0
+}
+
+// Warning returns a user-facing message when a significant share of context was dropped
+func (u *ContextUsage) Warning() string {
+	if !u.Truncated() {
+		return ""
+	}
+	total := u.IncludedItems + u.DroppedItems
+	if float64(u.DroppedItems)/float64(total) < significantTruncationRatio {
+		return ""
+	}
+	return fmt.Sprintf("AI context was truncated: %d of %d items were left out to stay within the %d token limit",
+		u.DroppedItems, total, u.MaxTokens)
 }
 
-// NewContextGathererService creates a new context gatherer service
-func NewContextGathererService(repo *repository.FirestoreRepository, logger *zap.Logger) *ContextGathererService {
+// NewContextGathererService creates a new context gatherer service.
+// maxContextTokens <= 0 falls back to defaultMaxContextTokens.
+func NewContextGathererService(repo *repository.FirestoreRepository, logger *zap.Logger, maxContextTokens int) *ContextGathererService {
+	if maxContextTokens <= 0 {
+		maxContextTokens = defaultMaxContextTokens
+	}
 	return &ContextGathererService{
-		repo:   repo,
-		logger: logger,
+		repo:             repo,
+		logger:           logger,
+		maxContextTokens: maxContextTokens,
 	}
 }
 
-// GatherContext gathers user's goals, tasks, projects, etc. for AI context
-func (s *ContextGathererService) GatherContext(ctx context.Context, uid string) (*models.UserContext, error) {
+// GatherContext gathers user's goals, tasks, projects, etc. for AI context,
+// trimmed to the configured token budget
+func (s *ContextGathererService) GatherContext(ctx context.Context, uid string) (*models.UserContext, *ContextUsage, error) {
 	s.logger.Debug("Gathering user context", zap.String("uid", uid))
 
 	userContext := &models.UserContext{
@@ -96,15 +141,141 @@ func (s *ContextGathererService) GatherContext(ctx context.Context, uid string)
 		userContext.Errands = errands
 	}
 
+	userContext, usage := applyContextBudget(userContext, s.maxContextTokens, time.Now())
+	if usage.Truncated() {
+		s.logger.Info("Context truncated to fit token budget",
+			zap.String("uid", uid),
+			zap.Int("included", usage.IncludedItems),
+			zap.Int("dropped", usage.DroppedItems),
+			zap.Int("maxTokens", usage.MaxTokens),
+		)
+	}
+
 	s.logger.Debug("Context gathered",
 		zap.String("uid", uid),
 		zap.Int("goals", len(userContext.Goals)),
 		zap.Int("projects", len(userContext.Projects)),
 		zap.Int("tasks", len(userContext.Tasks)),
 		zap.Int("moods", len(userContext.Moods)),
+		zap.Int("estimatedTokens", usage.EstimatedTokens),
 	)
 
-	return userContext, nil
+	return userContext, usage, nil
+}
+
+// contextItem is a single gathered document ranked for inclusion in the budget
+type contextItem struct {
+	collection *[]map[string]interface{}
+	index      int
+	tier       int
+	tokens     int
+}
+
+// applyContextBudget keeps the highest-signal items that fit within maxTokens.
+// Items are ranked by tier (active goals and today's tasks first, then other
+// tasks, recent moods, active projects, remaining goals/projects, and finally
+// errands, people and notes); within a tier the gathered (most recent first)
+// order is preserved, and the original order is kept in the result.
+func applyContextBudget(full *models.UserContext, maxTokens int, now time.Time) (*models.UserContext, *ContextUsage) {
+	var items []contextItem
+	add := func(collection *[]map[string]interface{}, tierOf func(map[string]interface{}) int) {
+		for i, doc := range *collection {
+			items = append(items, contextItem{
+				collection: collection,
+				index:      i,
+				tier:       tierOf(doc),
+				tokens:     estimateTokens(doc),
+			})
+		}
+	}
+
+	constant := func(tier int) func(map[string]interface{}) int {
+		return func(map[string]interface{}) int { return tier }
+	}
+	add(&full.Goals, func(doc map[string]interface{}) int {
+		if isActiveStatus(doc) {
+			return 0
+		}
+		return 4
+	})
+	add(&full.Tasks, func(doc map[string]interface{}) int {
+		if isDueByToday(doc, now) {
+			return 0
+		}
+		return 1
+	})
+	add(&full.Moods, constant(2))
+	add(&full.Projects, func(doc map[string]interface{}) int {
+		if isActiveStatus(doc) {
+			return 3
+		}
+		return 4
+	})
+	add(&full.Errands, constant(5))
+	add(&full.Relationships, constant(5))
+	add(&full.Notes, constant(5))
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].tier < items[j].tier })
+
+	usage := &ContextUsage{MaxTokens: maxTokens}
+	kept := make(map[*[]map[string]interface{}]map[int]bool)
+	for _, item := range items {
+		if usage.EstimatedTokens+item.tokens > maxTokens {
+			usage.DroppedItems++
+			continue
+		}
+		usage.EstimatedTokens += item.tokens
+		usage.IncludedItems++
+		if kept[item.collection] == nil {
+			kept[item.collection] = make(map[int]bool)
+		}
+		kept[item.collection][item.index] = true
+	}
+
+	filter := func(collection *[]map[string]interface{}) []map[string]interface{} {
+		result := []map[string]interface{}{}
+		for i, doc := range *collection {
+			if kept[collection][i] {
+				result = append(result, doc)
+			}
+		}
+		return result
+	}
+
+	return &models.UserContext{
+		Goals:         filter(&full.Goals),
+		Projects:      filter(&full.Projects),
+		Tasks:         filter(&full.Tasks),
+		Moods:         filter(&full.Moods),
+		Relationships: filter(&full.Relationships),
+		Notes:         filter(&full.Notes),
+		Errands:       filter(&full.Errands),
+	}, usage
+}
+
+// estimateTokens approximates the prompt cost of a document from its JSON size
+func estimateTokens(doc map[string]interface{}) int {
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		encoded = []byte(fmt.Sprint(doc))
+	}
+	return (len(encoded) + charsPerToken - 1) / charsPerToken
+}
+
+// isActiveStatus reports whether a goal or project is active
+func isActiveStatus(doc map[string]interface{}) bool {
+	status, _ := doc["status"].(string)
+	return status == "" || status == "active"
+}
+
+// isDueByToday reports whether a task is due today or overdue
+func isDueByToday(doc map[string]interface{}, now time.Time) bool {
+	due, ok := parseFlexibleDate(doc["dueDate"])
+	if !ok {
+		return false
+	}
+	endOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return due.Before(endOfToday)
 }
 
 // getCollection fetches documents from a collection
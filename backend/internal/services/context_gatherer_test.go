@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+)
+
+func TestNewContextGathererService_DefaultBudget(t *testing.T) {
+	svc := NewContextGathererService(nil, nil, 0)
+	assert.Equal(t, defaultMaxContextTokens, svc.maxContextTokens)
+
+	svc = NewContextGathererService(nil, nil, 2000)
+	assert.Equal(t, 2000, svc.maxContextTokens)
+}
+
+func TestApplyContextBudget_FitsWithinBudget(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	full := &models.UserContext{
+		Goals: []map[string]interface{}{{"id": "g1", "title": "Run", "status": "active"}},
+		Tasks: []map[string]interface{}{{"id": "t1", "title": "Stretch"}},
+	}
+
+	trimmed, usage := applyContextBudget(full, 1000, now)
+
+	assert.Len(t, trimmed.Goals, 1)
+	assert.Len(t, trimmed.Tasks, 1)
+	assert.Equal(t, 2, usage.IncludedItems)
+	assert.Equal(t, 0, usage.DroppedItems)
+	assert.False(t, usage.Truncated())
+	assert.Empty(t, usage.Warning())
+	assert.NotNil(t, trimmed.Notes)
+}
+
+func TestApplyContextBudget_PrioritizesHighSignalItems(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	padding := strings.Repeat("x", 200)
+	full := &models.UserContext{
+		Goals: []map[string]interface{}{
+			{"id": "g-done", "status": "completed", "title": padding},
+			{"id": "g-active", "status": "active", "title": padding},
+		},
+		Tasks: []map[string]interface{}{
+			{"id": "t-later", "dueDate": "2024-04-01", "title": padding},
+			{"id": "t-today", "dueDate": "2024-03-15", "title": padding},
+		},
+		Notes: []map[string]interface{}{
+			{"id": "n1", "title": padding},
+		},
+	}
+
+	budget := estimateTokens(full.Goals[1]) + estimateTokens(full.Tasks[1])
+	trimmed, usage := applyContextBudget(full, budget, now)
+
+	assert.Equal(t, []map[string]interface{}{full.Goals[1]}, trimmed.Goals)
+	assert.Equal(t, []map[string]interface{}{full.Tasks[1]}, trimmed.Tasks)
+	assert.Empty(t, trimmed.Notes)
+	assert.Equal(t, 2, usage.IncludedItems)
+	assert.Equal(t, 3, usage.DroppedItems)
+	assert.LessOrEqual(t, usage.EstimatedTokens, budget)
+	assert.True(t, usage.Truncated())
+	assert.Contains(t, usage.Warning(), "3 of 5 items")
+}
+
+func TestContextUsage_WarningOnlyWhenSignificant(t *testing.T) {
+	minor := &ContextUsage{IncludedItems: 9, DroppedItems: 1, MaxTokens: 100}
+	assert.True(t, minor.Truncated())
+	assert.Empty(t, minor.Warning())
+
+	major := &ContextUsage{IncludedItems: 3, DroppedItems: 1, MaxTokens: 100}
+	assert.NotEmpty(t, major.Warning())
+
+	var none *ContextUsage
+	assert.False(t, none.Truncated())
+	assert.Empty(t, none.Warning())
+}
+
+func TestIsDueByToday(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, isDueByToday(map[string]interface{}{"dueDate": "2024-03-15"}, now))
+	assert.True(t, isDueByToday(map[string]interface{}{"dueDate": "2024-03-10"}, now))
+	assert.False(t, isDueByToday(map[string]interface{}{"dueDate": "2024-03-16"}, now))
+	assert.False(t, isDueByToday(map[string]interface{}{}, now))
+}
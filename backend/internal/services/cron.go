@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+// Cron job result statuses
+const (
+	CronJobSucceeded = "succeeded"
+	CronJobFailed    = "failed"
+	CronJobTimedOut  = "timed_out"
+)
+
+const defaultCronJobTimeout = 50 * time.Second
+
+// ErrUnknownCronJob is returned when running a job that is not registered
+var ErrUnknownCronJob = errors.New("unknown cron job")
+
+// CronJobFunc runs one pass of a scheduled job and summarizes what it did
+type CronJobFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// CronJobResult is the outcome of one run of a scheduled job
+type CronJobResult struct {
+	Job        string                 `json:"job"`
+	Status     string                 `json:"status"`
+	Summary    map[string]interface{} `json:"summary,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	StartedAt  time.Time              `json:"startedAt"`
+	DurationMs int64                  `json:"durationMs"`
+}
+
+type cronJob struct {
+	run     CronJobFunc
+	timeout time.Duration
+}
+
+// CronRegistry maps job names to the work a scheduler can trigger, so new
+// jobs only need registering rather than their own routes
+type CronRegistry struct {
+	mu     sync.RWMutex
+	jobs   map[string]cronJob
+	cfg    config.CronConfig
+	logger *zap.Logger
+}
+
+// NewCronRegistry creates an empty job registry. Job timeouts come from
+// cfg.Timeouts, then cfg.DefaultTimeout, then defaultCronJobTimeout.
+func NewCronRegistry(logger *zap.Logger, cfg config.CronConfig) *CronRegistry {
+	return &CronRegistry{
+		jobs:   make(map[string]cronJob),
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Register adds a named job. Registering a name twice panics, since it is a
+// wiring mistake.
+func (r *CronRegistry) Register(name string, run CronJobFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.jobs[name]; exists {
+		panic(fmt.Sprintf("cron job %q registered twice", name))
+	}
+
+	timeout := r.cfg.Timeouts[name]
+	if timeout <= 0 {
+		timeout = r.cfg.DefaultTimeout
+	}
+	if timeout <= 0 {
+		timeout = defaultCronJobTimeout
+	}
+	r.jobs[name] = cronJob{run: run, timeout: timeout}
+}
+
+// Names returns the registered job names in order
+func (r *CronRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes a job within its timeout. A failing job is reported in the
+// result rather than as an error; errors are for unknown jobs only.
+func (r *CronRegistry) Run(ctx context.Context, name string) (*CronJobResult, error) {
+	r.mu.RLock()
+	job, ok := r.jobs[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCronJob, name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+
+	result := &CronJobResult{Job: name, StartedAt: time.Now()}
+	summary, err := job.run(ctx)
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+	result.Summary = summary
+
+	switch {
+	case err == nil:
+		result.Status = CronJobSucceeded
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status = CronJobTimedOut
+		result.Error = err.Error()
+	default:
+		result.Status = CronJobFailed
+		result.Error = err.Error()
+	}
+
+	fields := []zap.Field{
+		zap.String("job", name),
+		zap.String("status", result.Status),
+		zap.Int64("durationMs", result.DurationMs),
+	}
+	if err != nil {
+		r.logger.Error("Cron job did not succeed", append(fields, zap.Error(err))...)
+	} else {
+		r.logger.Info("Cron job finished", fields...)
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+func TestCronRegistry_Run(t *testing.T) {
+	registry := NewCronRegistry(zap.NewNop(), config.CronConfig{
+		DefaultTimeout: time.Second,
+		Timeouts:       map[string]time.Duration{"slow": 10 * time.Millisecond},
+	})
+	registry.Register("retention", func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"deleted": 3}, nil
+	})
+	registry.Register("broken", func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	registry.Register("slow", func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return map[string]interface{}{"processed": 1}, ctx.Err()
+	})
+
+	assert.Equal(t, []string{"broken", "retention", "slow"}, registry.Names())
+
+	result, err := registry.Run(context.Background(), "retention")
+	require.NoError(t, err)
+	assert.Equal(t, CronJobSucceeded, result.Status)
+	assert.Equal(t, 3, result.Summary["deleted"])
+
+	result, err = registry.Run(context.Background(), "broken")
+	require.NoError(t, err)
+	assert.Equal(t, CronJobFailed, result.Status)
+	assert.Equal(t, "boom", result.Error)
+
+	// The per-job timeout applies, and partial summaries are kept
+	result, err = registry.Run(context.Background(), "slow")
+	require.NoError(t, err)
+	assert.Equal(t, CronJobTimedOut, result.Status)
+	assert.Equal(t, 1, result.Summary["processed"])
+
+	_, err = registry.Run(context.Background(), "backup-all")
+	assert.ErrorIs(t, err, ErrUnknownCronJob)
+}
+
+func TestCronRegistry_RegisterTwicePanics(t *testing.T) {
+	registry := NewCronRegistry(zap.NewNop(), config.CronConfig{})
+	job := func(ctx context.Context) (map[string]interface{}, error) { return nil, nil }
+	registry.Register("retention", job)
+	assert.Panics(t, func() { registry.Register("retention", job) })
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+const (
+	// MaxCSVMappings caps the mapping profiles one user can save
+	MaxCSVMappings = 50
+	// DefaultCSVPreviewRows and MaxCSVPreviewRows bound preview parsing
+	DefaultCSVPreviewRows = 5
+	MaxCSVPreviewRows     = 20
+	// MaxCSVPreviewBytes caps the CSV content accepted for a preview
+	MaxCSVPreviewBytes = 1 << 20
+	// minCSVMappingMatchScore is the header overlap (Jaccard index) a saved
+	// mapping needs to be picked for an upload that does not name one
+	minCSVMappingMatchScore = 0.5
+)
+
+// ErrInvalidCSVMapping is returned for malformed mapping profiles
+var ErrInvalidCSVMapping = errors.New("invalid CSV mapping")
+
+// CSVPreview is the result of parsing the first rows of a statement
+type CSVPreview struct {
+	Header       []string                `json:"header"`
+	Mapping      *models.CSVMapping      `json:"mapping,omitempty"`
+	Matched      bool                    `json:"matched"`
+	Transactions []models.CSVTransaction `json:"transactions"`
+}
+
+// CSVMappingService stores per-bank CSV mapping profiles at
+// users/{uid}/csvMappings/{id} and parses statements with them
+type CSVMappingService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewCSVMappingService creates a new CSV mapping service
+func NewCSVMappingService(repo interfaces.Repository, logger *zap.Logger) *CSVMappingService {
+	return &CSVMappingService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListMappings returns the user's mapping profiles
+func (s *CSVMappingService) ListMappings(ctx context.Context, uid string) ([]models.CSVMapping, error) {
+	docs, err := s.repo.List(ctx, csvMappingsPath(uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSV mappings: %w", err)
+	}
+	mappings := make([]models.CSVMapping, 0, len(docs))
+	for _, doc := range docs {
+		mappings = append(mappings, parseCSVMapping(doc))
+	}
+	return mappings, nil
+}
+
+// GetMapping returns one mapping profile
+func (s *CSVMappingService) GetMapping(ctx context.Context, uid, id string) (*models.CSVMapping, error) {
+	doc, err := s.repo.Get(ctx, fmt.Sprintf("%s/%s", csvMappingsPath(uid), id))
+	if err != nil {
+		return nil, err
+	}
+	mapping := parseCSVMapping(doc)
+	mapping.ID = id
+	return &mapping, nil
+}
+
+// CreateMapping validates and stores a new mapping profile
+func (s *CSVMappingService) CreateMapping(ctx context.Context, uid string, mapping models.CSVMapping) (*models.CSVMapping, error) {
+	if err := validateCSVMapping(&mapping); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ListMappings(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxCSVMappings {
+		return nil, fmt.Errorf("%w: at most %d mappings", ErrInvalidCSVMapping, MaxCSVMappings)
+	}
+
+	mapping.ID = uuid.New().String()
+	mapping.CreatedAt = time.Now()
+	mapping.UpdatedAt = mapping.CreatedAt
+	if err := s.repo.SetDocument(ctx, fmt.Sprintf("%s/%s", csvMappingsPath(uid), mapping.ID), csvMappingData(mapping)); err != nil {
+		return nil, fmt.Errorf("failed to save CSV mapping: %w", err)
+	}
+
+	s.logger.Info("CSV mapping created", zap.String("uid", uid), zap.String("mappingId", mapping.ID))
+	return &mapping, nil
+}
+
+// UpdateMapping replaces the editable fields of a mapping profile
+func (s *CSVMappingService) UpdateMapping(ctx context.Context, uid, id string, mapping models.CSVMapping) (*models.CSVMapping, error) {
+	if err := validateCSVMapping(&mapping); err != nil {
+		return nil, err
+	}
+	existing, err := s.GetMapping(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping.ID = id
+	mapping.CreatedAt = existing.CreatedAt
+	mapping.UpdatedAt = time.Now()
+	if err := s.repo.SetDocument(ctx, fmt.Sprintf("%s/%s", csvMappingsPath(uid), id), csvMappingData(mapping)); err != nil {
+		return nil, fmt.Errorf("failed to save CSV mapping: %w", err)
+	}
+	return &mapping, nil
+}
+
+// DeleteMapping removes a mapping profile
+func (s *CSVMappingService) DeleteMapping(ctx context.Context, uid, id string) error {
+	path := fmt.Sprintf("%s/%s", csvMappingsPath(uid), id)
+	if _, err := s.repo.Get(ctx, path); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete CSV mapping: %w", err)
+	}
+	return nil
+}
+
+// MatchMapping picks the saved mapping whose header signature best matches
+// header, or nil when none matches closely enough. A mapping only matches
+// headers containing every column it names.
+func (s *CSVMappingService) MatchMapping(ctx context.Context, uid string, header []string) (*models.CSVMapping, error) {
+	mappings, err := s.ListMappings(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *models.CSVMapping
+	bestScore := 0.0
+	for i := range mappings {
+		score := csvMappingScore(mappings[i], header)
+		if score < minCSVMappingMatchScore {
+			continue
+		}
+		if best == nil || score > bestScore || (score == bestScore && mappings[i].UpdatedAt.After(best.UpdatedAt)) {
+			best, bestScore = &mappings[i], score
+		}
+	}
+	return best, nil
+}
+
+// ParseStatement parses CSV content with the named mapping, or with the best
+// matching saved mapping when mappingID is empty. Without a mapping the
+// default Date,Description,Amount layout is assumed.
+func (s *CSVMappingService) ParseStatement(ctx context.Context, uid, csvContent, mappingID string) ([]models.CSVTransaction, error) {
+	mapping, err := s.resolveMapping(ctx, uid, csvContent, mappingID)
+	if err != nil {
+		return nil, err
+	}
+	if mapping == nil {
+		return utils.ParseCSV(csvContent)
+	}
+	return utils.ParseCSVWithMapping(csvContent, *mapping, 0)
+}
+
+// Preview parses the first rows of CSV content. The mapping is mapping when
+// given (to try a profile before saving it), else the saved mappingID, else
+// the best match by header signature, else column detection.
+func (s *CSVMappingService) Preview(ctx context.Context, uid, csvContent, mappingID string, mapping *models.CSVMapping, rows int) (*CSVPreview, error) {
+	if rows <= 0 {
+		rows = DefaultCSVPreviewRows
+	}
+	if rows > MaxCSVPreviewRows {
+		rows = MaxCSVPreviewRows
+	}
+
+	preview := &CSVPreview{Header: utils.CSVHeader(csvContent)}
+	if mapping != nil {
+		if err := validateCSVMapping(mapping); err != nil {
+			return nil, err
+		}
+		preview.Mapping = mapping
+	} else {
+		resolved, err := s.resolveMapping(ctx, uid, csvContent, mappingID)
+		if err != nil {
+			return nil, err
+		}
+		preview.Mapping = resolved
+		preview.Matched = resolved != nil && mappingID == ""
+	}
+
+	parseWith := models.CSVMapping{}
+	if preview.Mapping != nil {
+		parseWith = *preview.Mapping
+	}
+	transactions, err := utils.ParseCSVWithMapping(csvContent, parseWith, rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSVMapping, err)
+	}
+	preview.Transactions = transactions
+	return preview, nil
+}
+
+// resolveMapping loads the named mapping or matches one by header
+func (s *CSVMappingService) resolveMapping(ctx context.Context, uid, csvContent, mappingID string) (*models.CSVMapping, error) {
+	if mappingID != "" {
+		return s.GetMapping(ctx, uid, mappingID)
+	}
+	return s.MatchMapping(ctx, uid, utils.CSVHeader(csvContent))
+}
+
+// csvMappingScore is the Jaccard index of a mapping's signature (plus the
+// columns it names) and a header, or 0 when a named column is missing
+func csvMappingScore(mapping models.CSVMapping, header []string) float64 {
+	headerSet := make(map[string]bool, len(header))
+	for _, column := range header {
+		headerSet[column] = true
+	}
+
+	signature := make(map[string]bool, len(mapping.HeaderSignature)+3)
+	for _, column := range mapping.HeaderSignature {
+		signature[column] = true
+	}
+	for _, column := range []string{mapping.DateColumn, mapping.AmountColumn, mapping.DescriptionColumn} {
+		if column == "" {
+			continue
+		}
+		if !headerSet[column] {
+			return 0
+		}
+		signature[column] = true
+	}
+	if len(signature) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for column := range signature {
+		if headerSet[column] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(signature)+len(headerSet)-shared)
+}
+
+// validateCSVMapping checks a mapping profile, normalizing column names to
+// the lowercase form CSVHeader produces
+func validateCSVMapping(mapping *models.CSVMapping) error {
+	mapping.Name = strings.TrimSpace(mapping.Name)
+	if mapping.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCSVMapping)
+	}
+	mapping.DateColumn = strings.ToLower(strings.TrimSpace(mapping.DateColumn))
+	mapping.AmountColumn = strings.ToLower(strings.TrimSpace(mapping.AmountColumn))
+	mapping.DescriptionColumn = strings.ToLower(strings.TrimSpace(mapping.DescriptionColumn))
+
+	mapping.DateFormat = strings.TrimSpace(mapping.DateFormat)
+	if mapping.DateFormat != "" {
+		if _, err := utils.CSVDateLayout(mapping.DateFormat); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCSVMapping, err)
+		}
+	}
+
+	switch mapping.AmountSignConvention {
+	case "":
+		mapping.AmountSignConvention = models.AmountSignDebitPositive
+	case models.AmountSignDebitPositive, models.AmountSignDebitNegative:
+	default:
+		return fmt.Errorf("%w: amountSignConvention must be %s or %s", ErrInvalidCSVMapping, models.AmountSignDebitPositive, models.AmountSignDebitNegative)
+	}
+
+	signature := make([]string, 0, len(mapping.HeaderSignature))
+	for _, column := range mapping.HeaderSignature {
+		signature = append(signature, strings.ToLower(strings.TrimSpace(column)))
+	}
+	mapping.HeaderSignature = dedupeStrings(signature)
+	return nil
+}
+
+func csvMappingData(mapping models.CSVMapping) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   mapping.ID,
+		"name":                 mapping.Name,
+		"dateColumn":           mapping.DateColumn,
+		"amountColumn":         mapping.AmountColumn,
+		"descriptionColumn":    mapping.DescriptionColumn,
+		"dateFormat":           mapping.DateFormat,
+		"amountSignConvention": mapping.AmountSignConvention,
+		"headerSignature":      toInterfaceSlice(mapping.HeaderSignature),
+		"createdAt":            mapping.CreatedAt,
+		"updatedAt":            mapping.UpdatedAt,
+	}
+}
+
+func parseCSVMapping(doc map[string]interface{}) models.CSVMapping {
+	mapping := models.CSVMapping{
+		ID:                   getStringField(doc, "id"),
+		Name:                 getStringField(doc, "name"),
+		DateColumn:           getStringField(doc, "dateColumn"),
+		AmountColumn:         getStringField(doc, "amountColumn"),
+		DescriptionColumn:    getStringField(doc, "descriptionColumn"),
+		DateFormat:           getStringField(doc, "dateFormat"),
+		AmountSignConvention: getStringField(doc, "amountSignConvention"),
+		HeaderSignature:      toStringSlice(doc["headerSignature"]),
+	}
+	mapping.CreatedAt, _ = parseFlexibleDate(doc["createdAt"])
+	mapping.UpdatedAt, _ = parseFlexibleDate(doc["updatedAt"])
+	return mapping
+}
+
+func csvMappingsPath(uid string) string {
+	return fmt.Sprintf("users/%s/csvMappings", uid)
+}
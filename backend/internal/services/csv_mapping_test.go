@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/utils"
+)
+
+const chaseStatement = "Transaction Date,Post Date,Description,Category,Type,Amount,Memo\n" +
+	"01/15/2024,01/16/2024,STARBUCKS STORE 123,Food & Drink,Sale,-5.75,\n" +
+	"01/17/2024,01/17/2024,WHOLEFDS MKT 10234,Groceries,Sale,-82.10,\n"
+
+const ukStatement = "Date,Type,Details,Value,Balance\n" +
+	"31/01/2024,DEB,TESCO STORES 2041,-23.10,1000.00\n"
+
+func chaseMapping() models.CSVMapping {
+	return models.CSVMapping{
+		Name:                 "Chase checking",
+		DateColumn:           "Transaction Date",
+		DescriptionColumn:    "Description",
+		AmountColumn:         "Amount",
+		DateFormat:           "MM/DD/YYYY",
+		AmountSignConvention: models.AmountSignDebitNegative,
+		HeaderSignature:      utils.CSVHeader(chaseStatement),
+	}
+}
+
+func TestCSVMappingService_CRUD(t *testing.T) {
+	svc := NewCSVMappingService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateMapping(ctx, "user1", chaseMapping())
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "transaction date", created.DateColumn)
+
+	got, err := svc.GetMapping(ctx, "user1", created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Chase checking", got.Name)
+	assert.Equal(t, models.AmountSignDebitNegative, got.AmountSignConvention)
+	assert.Equal(t, created.HeaderSignature, got.HeaderSignature)
+
+	update := chaseMapping()
+	update.Name = "Chase Sapphire"
+	updated, err := svc.UpdateMapping(ctx, "user1", created.ID, update)
+	require.NoError(t, err)
+	assert.Equal(t, "Chase Sapphire", updated.Name)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	mappings, err := svc.ListMappings(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, mappings, 1)
+
+	require.NoError(t, svc.DeleteMapping(ctx, "user1", created.ID))
+	assert.ErrorIs(t, svc.DeleteMapping(ctx, "user1", created.ID), interfaces.ErrNotFound)
+	_, err = svc.UpdateMapping(ctx, "user1", created.ID, update)
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestCSVMappingService_Validation(t *testing.T) {
+	svc := NewCSVMappingService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		mapping models.CSVMapping
+	}{
+		{"missing name", models.CSVMapping{DateColumn: "date"}},
+		{"bad date format", models.CSVMapping{Name: "Bank", DateFormat: "MM/YYYY"}},
+		{"bad sign convention", models.CSVMapping{Name: "Bank", AmountSignConvention: "credit-positive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateMapping(ctx, "user1", tt.mapping)
+			assert.ErrorIs(t, err, ErrInvalidCSVMapping)
+		})
+	}
+
+	created, err := svc.CreateMapping(ctx, "user1", models.CSVMapping{Name: "Bank"})
+	require.NoError(t, err)
+	assert.Equal(t, models.AmountSignDebitPositive, created.AmountSignConvention)
+}
+
+func TestCSVMappingService_MatchMapping(t *testing.T) {
+	svc := NewCSVMappingService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	chase, err := svc.CreateMapping(ctx, "user1", chaseMapping())
+	require.NoError(t, err)
+	_, err = svc.CreateMapping(ctx, "user1", models.CSVMapping{
+		Name:              "UK current account",
+		DateColumn:        "date",
+		DescriptionColumn: "details",
+		AmountColumn:      "value",
+		DateFormat:        "DD/MM/YYYY",
+		HeaderSignature:   utils.CSVHeader(ukStatement),
+	})
+	require.NoError(t, err)
+
+	match, err := svc.MatchMapping(ctx, "user1", utils.CSVHeader(chaseStatement))
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, chase.ID, match.ID)
+
+	match, err = svc.MatchMapping(ctx, "user1", utils.CSVHeader(ukStatement))
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "UK current account", match.Name)
+
+	match, err = svc.MatchMapping(ctx, "user1", []string{"date", "description", "amount"})
+	require.NoError(t, err)
+	assert.Nil(t, match)
+}
+
+func TestCSVMappingService_ParseStatementUsesMatchedMapping(t *testing.T) {
+	svc := NewCSVMappingService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.CreateMapping(ctx, "user1", chaseMapping())
+	require.NoError(t, err)
+
+	transactions, err := svc.ParseStatement(ctx, "user1", chaseStatement, "")
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "2024-01-15", transactions[0].Date)
+	assert.Equal(t, 5.75, transactions[0].Amount)
+
+	// Without a matching mapping the default layout is used
+	transactions, err = svc.ParseStatement(ctx, "user2", "Date,Description,Amount\n2024-01-01,Coffee,5.50", "")
+	require.NoError(t, err)
+	assert.Equal(t, 5.5, transactions[0].Amount)
+
+	_, err = svc.ParseStatement(ctx, "user1", chaseStatement, "missing")
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestCSVMappingService_Preview(t *testing.T) {
+	svc := NewCSVMappingService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateMapping(ctx, "user1", chaseMapping())
+	require.NoError(t, err)
+
+	preview, err := svc.Preview(ctx, "user1", chaseStatement, "", nil, 1)
+	require.NoError(t, err)
+	assert.True(t, preview.Matched)
+	assert.Equal(t, created.ID, preview.Mapping.ID)
+	require.Len(t, preview.Transactions, 1)
+	assert.Equal(t, 5.75, preview.Transactions[0].Amount)
+
+	preview, err = svc.Preview(ctx, "user1", chaseStatement, created.ID, nil, 0)
+	require.NoError(t, err)
+	assert.False(t, preview.Matched)
+	assert.Len(t, preview.Transactions, 2)
+
+	inline := models.CSVMapping{Name: "Draft", DateFormat: "DD/MM/YYYY", AmountSignConvention: models.AmountSignDebitNegative}
+	preview, err = svc.Preview(ctx, "user1", ukStatement, "", &inline, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "type", "details", "value", "balance"}, preview.Header)
+	require.Len(t, preview.Transactions, 1)
+	assert.Equal(t, "2024-01-31", preview.Transactions[0].Date)
+	assert.Equal(t, 23.10, preview.Transactions[0].Amount)
+
+	_, err = svc.Preview(ctx, "user1", "Posted,Payee,Debit\n", "", nil, 0)
+	assert.ErrorIs(t, err, ErrInvalidCSVMapping)
+}
@@ -25,6 +25,7 @@ type CSVProcessingService struct {
 	repo              interfaces.Repository
 	storageClient     *storage.Client
 	categorizationSvc *TransactionCategorizationService
+	mappings          *CSVMappingService
 	logger            *zap.Logger
 	bucketName        string
 }
@@ -36,22 +37,27 @@ func NewCSVProcessingService(
 	categorizationSvc *TransactionCategorizationService,
 	bucketName string,
 	logger *zap.Logger,
+	mappings *CSVMappingService,
 ) *CSVProcessingService {
 	return &CSVProcessingService{
 		repo:              repo,
 		storageClient:     storageClient,
 		categorizationSvc: categorizationSvc,
+		mappings:          mappings,
 		bucketName:        bucketName,
 		logger:            logger,
 	}
 }
 
-// ProcessCSVFile processes an uploaded CSV file
+// ProcessCSVFile processes an uploaded CSV file. The file is parsed with the
+// saved mapping mappingID, or with the user's best matching mapping when
+// mappingID is empty.
 func (s *CSVProcessingService) ProcessCSVFile(
 	ctx context.Context,
 	userID string,
 	fileName string,
 	storagePath string,
+	mappingID string,
 ) (int, error) {
 	s.logger.Info("Processing CSV file",
 		zap.String("uid", userID),
@@ -66,7 +72,7 @@ func (s *CSVProcessingService) ProcessCSVFile(
 	}
 
 	// Parse CSV
-	transactions, err := utils.ParseCSV(csvContent)
+	transactions, err := s.parseStatement(ctx, userID, csvContent, mappingID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse CSV: %w", err)
 	}
@@ -159,6 +165,15 @@ func (s *CSVProcessingService) DeleteCSVStatement(
 }
 
 // downloadFile downloads a file from Cloud Storage
+// parseStatement parses with the user's mappings when the mapping service is
+// configured, else with the default Date,Description,Amount layout
+func (s *CSVProcessingService) parseStatement(ctx context.Context, userID, csvContent, mappingID string) ([]models.CSVTransaction, error) {
+	if s.mappings == nil {
+		return utils.ParseCSV(csvContent)
+	}
+	return s.mappings.ParseStatement(ctx, userID, csvContent, mappingID)
+}
+
 func (s *CSVProcessingService) downloadFile(ctx context.Context, objectPath string) (string, error) {
 	bucket := s.storageClient.Bucket(s.bucketName)
 	obj := bucket.Object(objectPath)
@@ -14,7 +14,7 @@ func TestNewCSVProcessingService(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
 
-	service := NewCSVProcessingService(mockRepo, nil, nil, "test-bucket", logger)
+	service := NewCSVProcessingService(mockRepo, nil, nil, "test-bucket", logger, nil)
 
 	require.NotNil(t, service)
 	assert.Equal(t, "test-bucket", service.bucketName)
@@ -88,7 +88,7 @@ func TestDeleteAllTransactionsSummary_Empty(t *testing.T) {
 }
 
 func TestCSVProcessingService_NilDependencies(t *testing.T) {
-	service := NewCSVProcessingService(nil, nil, nil, "", nil)
+	service := NewCSVProcessingService(nil, nil, nil, "", nil, nil)
 
 	require.NotNil(t, service)
 	assert.Nil(t, service.repo)
@@ -100,7 +100,7 @@ func TestCSVProcessingService_NilDependencies(t *testing.T) {
 
 func TestCSVProcessingService_WithMockRepo(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
-	service := NewCSVProcessingService(mockRepo, nil, nil, "my-bucket", nil)
+	service := NewCSVProcessingService(mockRepo, nil, nil, "my-bucket", nil, nil)
 
 	require.NotNil(t, service)
 	assert.NotNil(t, service.repo)
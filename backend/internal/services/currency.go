@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// DefaultCurrency and DefaultLocale apply to users without preferences
+	DefaultCurrency = "USD"
+	DefaultLocale   = "en-US"
+)
+
+// ErrInvalidPreferences is returned for an unrecognized currency or locale
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+var (
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	localePattern       = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+)
+
+// UserPreferences holds the currency analytics amounts are reported in and
+// the locale they are formatted for
+type UserPreferences struct {
+	Currency string `json:"currency"`
+	Locale   string `json:"locale"`
+}
+
+// FXRateProvider returns how many units of each currency one unit of base buys
+type FXRateProvider interface {
+	Rates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// CurrencyService keeps users' currency and locale preferences at
+// users/{uid}/settings/preferences and converts amounts into the preferred
+// currency
+type CurrencyService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	fx     FXRateProvider
+}
+
+// NewCurrencyService creates a new currency service. fx may be nil, in which
+// case amounts in other currencies are reported unconverted.
+func NewCurrencyService(repo interfaces.Repository, logger *zap.Logger, fx FXRateProvider) *CurrencyService {
+	return &CurrencyService{
+		repo:   repo,
+		logger: logger,
+		fx:     fx,
+	}
+}
+
+// GetPreferences returns the user's preferences, filling unset fields with
+// DefaultCurrency and DefaultLocale. A nil service returns the defaults.
+func (s *CurrencyService) GetPreferences(ctx context.Context, uid string) (UserPreferences, error) {
+	prefs := UserPreferences{Currency: DefaultCurrency, Locale: DefaultLocale}
+	if s == nil {
+		return prefs, nil
+	}
+
+	doc, err := s.repo.Get(ctx, preferencesPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return prefs, nil
+		}
+		return prefs, fmt.Errorf("failed to load preferences: %w", err)
+	}
+	if currency := getStringField(doc, "currency"); currency != "" {
+		prefs.Currency = currency
+	}
+	if locale := getStringField(doc, "locale"); locale != "" {
+		prefs.Locale = locale
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences validates and stores the user's preferences. Empty fields
+// keep their current value.
+func (s *CurrencyService) UpdatePreferences(ctx context.Context, uid string, update UserPreferences) (UserPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, uid)
+	if err != nil {
+		return prefs, err
+	}
+
+	if update.Currency != "" {
+		currency := strings.ToUpper(strings.TrimSpace(update.Currency))
+		if !currencyCodePattern.MatchString(currency) {
+			return prefs, fmt.Errorf("%w: currency must be an ISO 4217 code", ErrInvalidPreferences)
+		}
+		prefs.Currency = currency
+	}
+	if update.Locale != "" {
+		locale := strings.TrimSpace(update.Locale)
+		if !localePattern.MatchString(locale) {
+			return prefs, fmt.Errorf("%w: locale must be a language tag such as en-US", ErrInvalidPreferences)
+		}
+		prefs.Locale = locale
+	}
+
+	if err := s.repo.SetDocument(ctx, preferencesPath(uid), map[string]interface{}{
+		"currency":  prefs.Currency,
+		"locale":    prefs.Locale,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return prefs, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// Converter returns a converter into target. When rates are unavailable the
+// converter only handles amounts already in target.
+func (s *CurrencyService) Converter(ctx context.Context, target string) *CurrencyConverter {
+	converter := &CurrencyConverter{
+		target:      strings.ToUpper(target),
+		unconverted: make(map[string]bool),
+	}
+	if s == nil || s.fx == nil {
+		return converter
+	}
+
+	rates, err := s.fx.Rates(ctx, converter.target)
+	if err != nil {
+		s.logger.Warn("Exchange rates unavailable", zap.String("base", converter.target), zap.Error(err))
+		return converter
+	}
+	converter.rates = rates
+	return converter
+}
+
+// CurrencyConverter converts amounts into one target currency
+type CurrencyConverter struct {
+	target      string
+	rates       map[string]float64
+	unconverted map[string]bool
+}
+
+// Target returns the currency amounts are converted into
+func (c *CurrencyConverter) Target() string {
+	return c.target
+}
+
+// Convert converts amount from currency into the target. An empty currency
+// is taken to be DefaultCurrency. Amounts in a currency without a rate are
+// returned as-is and reported by Unconverted.
+func (c *CurrencyConverter) Convert(amount float64, currency string) float64 {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if currency == c.target || amount == 0 {
+		return amount
+	}
+	rate, ok := c.rates[currency]
+	if !ok || rate <= 0 {
+		c.unconverted[currency] = true
+		return amount
+	}
+	return amount / rate
+}
+
+// Unconverted lists the currencies Convert had no rate for
+func (c *CurrencyConverter) Unconverted() []string {
+	currencies := make([]string, 0, len(c.unconverted))
+	for currency := range c.unconverted {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+func preferencesPath(uid string) string {
+	return fmt.Sprintf("users/%s/settings/preferences", uid)
+}
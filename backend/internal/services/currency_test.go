@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// fakeFXRates serves fixed rates for EUR and fails for other bases
+type fakeFXRates struct{}
+
+func (fakeFXRates) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	if base != "EUR" {
+		return nil, errors.New("no rates for " + base)
+	}
+	return map[string]float64{"EUR": 1, "USD": 1.25, "GBP": 0.8}, nil
+}
+
+func newEURUser(t *testing.T) (*mocks.MockRepository, *CurrencyService) {
+	repo := mocks.NewMockRepository()
+	currency := NewCurrencyService(repo, zap.NewNop(), fakeFXRates{})
+	_, err := currency.UpdatePreferences(context.Background(), "user1", UserPreferences{Currency: "eur", Locale: "de-DE"})
+	require.NoError(t, err)
+	return repo, currency
+}
+
+func TestCurrencyService_Preferences(t *testing.T) {
+	svc := NewCurrencyService(mocks.NewMockRepository(), zap.NewNop(), nil)
+	ctx := context.Background()
+
+	prefs, err := svc.GetPreferences(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{Currency: "USD", Locale: "en-US"}, prefs)
+
+	prefs, err = svc.UpdatePreferences(ctx, "user1", UserPreferences{Currency: "cad"})
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{Currency: "CAD", Locale: "en-US"}, prefs)
+
+	prefs, err = svc.UpdatePreferences(ctx, "user1", UserPreferences{Locale: "fr-CA"})
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{Currency: "CAD", Locale: "fr-CA"}, prefs)
+
+	for _, invalid := range []UserPreferences{{Currency: "dollars"}, {Currency: "U5D"}, {Locale: "english"}, {Locale: "en_US"}} {
+		_, err := svc.UpdatePreferences(ctx, "user1", invalid)
+		assert.ErrorIs(t, err, ErrInvalidPreferences, "%+v", invalid)
+	}
+
+	var nilSvc *CurrencyService
+	prefs, err = nilSvc.GetPreferences(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCurrency, prefs.Currency)
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	svc := NewCurrencyService(mocks.NewMockRepository(), zap.NewNop(), fakeFXRates{})
+	ctx := context.Background()
+
+	converter := svc.Converter(ctx, "eur")
+	assert.Equal(t, "EUR", converter.Target())
+	assert.InDelta(t, 100.0, converter.Convert(125, "USD"), 0.0001)
+	assert.InDelta(t, 100.0, converter.Convert(125, ""), 0.0001)
+	assert.InDelta(t, 50.0, converter.Convert(40, "gbp"), 0.0001)
+	assert.Equal(t, 10.0, converter.Convert(10, "JPY"))
+	assert.Equal(t, []string{"JPY"}, converter.Unconverted())
+
+	// Without rates only amounts already in the target pass through cleanly
+	fallback := svc.Converter(ctx, "CAD")
+	assert.Equal(t, 10.0, fallback.Convert(10, "CAD"))
+	assert.Equal(t, 10.0, fallback.Convert(10, "USD"))
+	assert.Equal(t, []string{"USD"}, fallback.Unconverted())
+}
+
+func TestSpendingAnalytics_ConvertsToPreferredCurrency(t *testing.T) {
+	repo, currency := newEURUser(t)
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, currency)
+
+	repo.AddDocument("users/user1/transactions/usd", map[string]interface{}{
+		"signedAmount": 125.0, "isoCurrency": "USD", "postedAt": "2024-01-15", "category": "Food",
+	})
+	repo.AddDocument("users/user1/transactions/eur", map[string]interface{}{
+		"signedAmount": 50.0, "isoCurrency": "EUR", "postedAt": "2024-01-16", "category": "Food",
+	})
+	repo.AddDocument("users/user1/transactions/gbp", map[string]interface{}{
+		"signedAmount": -80.0, "isoCurrency": "GBP", "postedAt": "2024-01-17",
+	})
+
+	analytics, err := service.ComputeSpendingAnalytics(context.Background(), "user1", "2024-01-01", "2024-01-31", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", analytics.Currency)
+	assert.Equal(t, "de-DE", analytics.Locale)
+	assert.InDelta(t, 150.0, analytics.Stats.TotalSpend, 0.0001)
+	assert.InDelta(t, 100.0, analytics.Stats.TotalIncome, 0.0001)
+	require.Len(t, analytics.CategoryBreakdown, 1)
+	assert.InDelta(t, 150.0, analytics.CategoryBreakdown[0].Value, 0.0001)
+	assert.Empty(t, analytics.UnconvertedCurrencies)
+
+	// Stored transactions keep their original amounts
+	stored, err := repo.Get(context.Background(), "users/user1/transactions/usd")
+	require.NoError(t, err)
+	assert.Equal(t, 125.0, stored["signedAmount"])
+}
+
+func TestInvestmentSummary_ConvertsToPreferredCurrency(t *testing.T) {
+	repo, currency := newEURUser(t)
+	service := NewInvestmentCalculationService(repo, zap.NewNop(), 0, currency)
+	ctx := context.Background()
+
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"id": "p1", "uid": "user1"})
+	repo.AddDocument("investments/usd", map[string]interface{}{
+		"id": "usd", "uid": "user1", "portfolioId": "p1",
+		"currentValue": 250.0, "initialAmount": 125.0, "currency": "USD",
+	})
+	repo.AddDocument("investments/eur", map[string]interface{}{
+		"id": "eur", "uid": "user1", "portfolioId": "p1",
+		"currentValue": 100.0, "initialAmount": 100.0, "currency": "EUR",
+	})
+
+	summary, err := service.CalculateDashboardSummary(ctx, "user1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", summary.Currency)
+	assert.InDelta(t, 300.0, summary.TotalValue, 0.0001)
+	assert.InDelta(t, 200.0, summary.TotalInvested, 0.0001)
+	assert.InDelta(t, 100.0, summary.TotalGain, 0.0001)
+	assert.Equal(t, 250.0, summary.ByCurrency["USD"].TotalValue)
+
+	metrics, err := service.CalculatePortfolioMetrics(ctx, "user1", "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", metrics.Currency)
+	assert.InDelta(t, 300.0, metrics.TotalValue, 0.0001)
+	assert.InDelta(t, 200.0, metrics.TotalInvested, 0.0001)
+}
@@ -7,21 +7,25 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
 
 // DashboardAnalyticsService handles dashboard analytics computations
 type DashboardAnalyticsService struct {
-	repo   interfaces.Repository
-	logger *zap.Logger
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	activity *ActivityIndexService
 }
 
-// NewDashboardAnalyticsService creates a new dashboard analytics service
-func NewDashboardAnalyticsService(repo interfaces.Repository, logger *zap.Logger) *DashboardAnalyticsService {
+// NewDashboardAnalyticsService creates a new dashboard analytics service.
+// activity may be nil, in which case streaks are computed by scanning sessions.
+func NewDashboardAnalyticsService(repo interfaces.Repository, logger *zap.Logger, activity *ActivityIndexService) *DashboardAnalyticsService {
 	return &DashboardAnalyticsService{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		logger:   logger,
+		activity: activity,
 	}
 }
 
@@ -44,18 +48,22 @@ type TimeOfDayStats struct {
 
 // DashboardAnalytics holds the complete analytics response
 type DashboardAnalytics struct {
-	FocusData     []map[string]interface{}  `json:"focusData"`
-	TaskData      []map[string]interface{}  `json:"taskData"`
-	CategoryData  []map[string]interface{}  `json:"categoryData"`
-	TimeOfDayData map[string]TimeOfDayStats `json:"timeOfDayData"`
-	Stats         struct {
-		TotalFocusTime int     `json:"totalFocusTime"` // in minutes
-		TotalSessions  int     `json:"totalSessions"`
-		CompletedTasks int     `json:"completedTasks"`
-		MasteryTasks   int     `json:"masteryTasks"`
-		PleasureTasks  int     `json:"pleasureTasks"`
-		CurrentStreak  int     `json:"currentStreak"`
-		CompletionRate float64 `json:"completionRate"` // percentage
+	FocusData    []map[string]interface{} `json:"focusData"`
+	TaskData     []map[string]interface{} `json:"taskData"`
+	CategoryData []map[string]interface{} `json:"categoryData"`
+	// TaskCategories is the user's category set, in the order configured
+	TaskCategories []string                  `json:"taskCategories"`
+	TimeOfDayData  map[string]TimeOfDayStats `json:"timeOfDayData"`
+	Stats          struct {
+		TotalFocusTime int `json:"totalFocusTime"` // in minutes
+		TotalSessions  int `json:"totalSessions"`
+		CompletedTasks int `json:"completedTasks"`
+		MasteryTasks   int `json:"masteryTasks"`
+		PleasureTasks  int `json:"pleasureTasks"`
+		// Categories counts completed tasks in each of TaskCategories
+		Categories     map[string]int `json:"categories"`
+		CurrentStreak  int            `json:"currentStreak"`
+		CompletionRate float64        `json:"completionRate"` // percentage
 	} `json:"stats"`
 	Comparison *struct {
 		FocusTime float64 `json:"focusTime"` // percentage change
@@ -88,70 +96,57 @@ func (s *DashboardAnalyticsService) ComputeAnalytics(ctx context.Context, uid st
 	// Determine date range
 	startDate, endDate, days := s.resolvePeriodRange(period, time.Now())
 
-	// Fetch data in parallel
-	tasksCh := make(chan []map[string]interface{}, 1)
-	sessionsCh := make(chan []map[string]interface{}, 1)
-	goalsCh := make(chan []map[string]interface{}, 1)
-	projectsCh := make(chan []map[string]interface{}, 1)
-	errCh := make(chan error, 4)
+	// Fetch data in parallel. The errgroup context is cancelled on the first
+	// failure so sibling fetches stop early instead of running to completion.
+	var tasks, sessions, goals, projects []map[string]interface{}
+	var categories []string
+	g, gctx := errgroup.WithContext(ctx)
 
 	// Fetch tasks
-	go func() {
-		tasks, err := s.fetchTasks(ctx, uid, startDate, endDate)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		tasksCh <- tasks
-	}()
+	g.Go(func() error {
+		var err error
+		tasks, err = s.fetchTasks(gctx, uid, startDate, endDate)
+		return err
+	})
 
 	// Fetch sessions
-	go func() {
-		sessions, err := s.fetchSessions(ctx, uid, startDate, endDate)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		sessionsCh <- sessions
-	}()
+	g.Go(func() error {
+		var err error
+		sessions, err = s.fetchSessions(gctx, uid, startDate, endDate)
+		return err
+	})
 
 	// Fetch goals
-	go func() {
-		goals, err := s.fetchGoals(ctx, uid)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		goalsCh <- goals
-	}()
+	g.Go(func() error {
+		var err error
+		goals, err = s.fetchGoals(gctx, uid)
+		return err
+	})
 
 	// Fetch projects
-	go func() {
-		projects, err := s.fetchProjects(ctx, uid)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		projectsCh <- projects
-	}()
+	g.Go(func() error {
+		var err error
+		projects, err = s.fetchProjects(gctx, uid)
+		return err
+	})
+
+	// Fetch the user's task categories
+	g.Go(func() error {
+		var err error
+		categories, err = loadTaskCategories(gctx, s.repo, uid)
+		return err
+	})
 
 	// Wait for all fetches
-	var tasks, sessions, goals, projects []map[string]interface{}
-	for i := 0; i < 4; i++ {
-		select {
-		case err := <-errCh:
-			return nil, err
-		case tasks = <-tasksCh:
-		case sessions = <-sessionsCh:
-		case goals = <-goalsCh:
-		case projects = <-projectsCh:
-		}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
 	// Compute analytics
 	analytics := &DashboardAnalytics{
-		Period: string(period),
-		Days:   days,
+		TaskCategories: categories,
+		Period:         string(period),
+		Days:           days,
 	}
 
 	// Build date offsets for the period
@@ -168,10 +163,10 @@ func (s *DashboardAnalyticsService) ComputeAnalytics(ctx context.Context, uid st
 	analytics.FocusData = s.computeFocusData(dateOffsets, sessionsByDate)
 
 	// Compute task data (tasks per day by category)
-	analytics.TaskData = s.computeTaskData(dateOffsets, tasksByDate)
+	analytics.TaskData = s.computeTaskData(dateOffsets, tasksByDate, categories)
 
-	// Compute category data (mastery vs pleasure)
-	analytics.CategoryData = s.computeCategoryData(dateOffsets, tasksByDate)
+	// Compute category data (one count per configured category)
+	analytics.CategoryData = s.computeCategoryData(dateOffsets, tasksByDate, categories)
 
 	// Compute time of day statistics
 	analytics.TimeOfDayData = s.computeTimeOfDayData(sessions)
@@ -181,7 +176,8 @@ func (s *DashboardAnalyticsService) ComputeAnalytics(ctx context.Context, uid st
 	analytics.Stats.TotalSessions = len(sessions)
 	analytics.Stats.CompletedTasks = len(completedTasks)
 
-	categoryTotals := s.countTasksByCategory(completedTasks)
+	categoryTotals := s.countTasksByCategory(completedTasks, categories)
+	analytics.Stats.Categories = categoryTotals
 	analytics.Stats.MasteryTasks = categoryTotals["mastery"]
 	analytics.Stats.PleasureTasks = categoryTotals["pleasure"]
 
@@ -374,38 +370,38 @@ func (s *DashboardAnalyticsService) computeFocusData(dateOffsets []time.Time, se
 }
 
 // computeTaskData computes task counts per day
-func (s *DashboardAnalyticsService) computeTaskData(dateOffsets []time.Time, tasksByDate map[string][]map[string]interface{}) []map[string]interface{} {
+func (s *DashboardAnalyticsService) computeTaskData(dateOffsets []time.Time, tasksByDate map[string][]map[string]interface{}, categories []string) []map[string]interface{} {
 	taskData := make([]map[string]interface{}, len(dateOffsets))
 	for i, date := range dateOffsets {
 		dateStr := date.Format("Mon Jan 02 2006")
 		tasks := tasksByDate[dateStr]
 
-		categories := s.countTasksByCategory(tasks)
-
-		taskData[i] = map[string]interface{}{
-			"date":     date.Format(time.RFC3339),
-			"total":    len(tasks),
-			"mastery":  categories["mastery"],
-			"pleasure": categories["pleasure"],
+		day := map[string]interface{}{
+			"date":  date.Format(time.RFC3339),
+			"total": len(tasks),
+		}
+		for category, count := range s.countTasksByCategory(tasks, categories) {
+			day[category] = count
 		}
+		taskData[i] = day
 	}
 	return taskData
 }
 
 // computeCategoryData computes category distribution per day
-func (s *DashboardAnalyticsService) computeCategoryData(dateOffsets []time.Time, tasksByDate map[string][]map[string]interface{}) []map[string]interface{} {
+func (s *DashboardAnalyticsService) computeCategoryData(dateOffsets []time.Time, tasksByDate map[string][]map[string]interface{}, categories []string) []map[string]interface{} {
 	categoryData := make([]map[string]interface{}, len(dateOffsets))
 	for i, date := range dateOffsets {
 		dateStr := date.Format("Mon Jan 02 2006")
 		tasks := tasksByDate[dateStr]
 
-		categories := s.countTasksByCategory(tasks)
-
-		categoryData[i] = map[string]interface{}{
-			"date":     date.Format(time.RFC3339),
-			"mastery":  categories["mastery"],
-			"pleasure": categories["pleasure"],
+		day := map[string]interface{}{
+			"date": date.Format(time.RFC3339),
+		}
+		for category, count := range s.countTasksByCategory(tasks, categories) {
+			day[category] = count
 		}
+		categoryData[i] = day
 	}
 	return categoryData
 }
@@ -417,11 +413,7 @@ func (s *DashboardAnalyticsService) sumSessionTime(sessions []map[string]interfa
 		if tasks, ok := session["tasks"].([]interface{}); ok {
 			for _, task := range tasks {
 				if taskMap, ok := task.(map[string]interface{}); ok {
-					if timeSpent, ok := taskMap["timeSpent"].(int64); ok {
-						total += int(timeSpent)
-					} else if timeSpent, ok := taskMap["timeSpent"].(float64); ok {
-						total += int(timeSpent)
-					}
+					total += s.sessionTaskTime(taskMap)
 				}
 			}
 		}
@@ -429,15 +421,40 @@ func (s *DashboardAnalyticsService) sumSessionTime(sessions []map[string]interfa
 	return total
 }
 
-// countTasksByCategory counts tasks by mastery/pleasure category
-func (s *DashboardAnalyticsService) countTasksByCategory(tasks []map[string]interface{}) map[string]int {
-	counts := map[string]int{"mastery": 0, "pleasure": 0}
+// sessionTaskTime returns the seconds spent on a session task entry
+func (s *DashboardAnalyticsService) sessionTaskTime(taskMap map[string]interface{}) int {
+	if timeSpent, ok := taskMap["timeSpent"].(int64); ok {
+		return int(timeSpent)
+	} else if timeSpent, ok := taskMap["timeSpent"].(float64); ok {
+		return int(timeSpent)
+	}
+	return 0
+}
+
+// sessionTaskID returns the ID of the task a session task entry refers to
+func (s *DashboardAnalyticsService) sessionTaskID(taskMap map[string]interface{}) string {
+	if task, ok := taskMap["task"].(map[string]interface{}); ok {
+		if id, ok := task["id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// countTasksByCategory counts tasks in each of the categories. Tasks without
+// a category count towards the first one; tasks in other categories are not
+// counted.
+func (s *DashboardAnalyticsService) countTasksByCategory(tasks []map[string]interface{}, categories []string) map[string]int {
+	counts := make(map[string]int, len(categories))
+	for _, category := range categories {
+		counts[category] = 0
+	}
 	for _, task := range tasks {
-		category := "mastery" // default
-		if cat, ok := task["category"].(string); ok {
-			category = cat
+		category, ok := task["category"].(string)
+		if !ok && len(categories) > 0 {
+			category = categories[0]
 		}
-		if category == "mastery" || category == "pleasure" {
+		if _, known := counts[category]; known {
 			counts[category]++
 		}
 	}
@@ -473,11 +490,7 @@ func (s *DashboardAnalyticsService) computeTimeOfDayData(sessions []map[string]i
 		if tasks, ok := session["tasks"].([]interface{}); ok {
 			for _, task := range tasks {
 				if taskMap, ok := task.(map[string]interface{}); ok {
-					if timeSpent, ok := taskMap["timeSpent"].(int64); ok {
-						stats.TotalTime += int(timeSpent)
-					} else if timeSpent, ok := taskMap["timeSpent"].(float64); ok {
-						stats.TotalTime += int(timeSpent)
-					}
+					stats.TotalTime += s.sessionTaskTime(taskMap)
 
 					// Count completed tasks
 					if completed, ok := taskMap["completed"].(bool); ok && completed {
@@ -517,8 +530,21 @@ func (s *DashboardAnalyticsService) getTimeOfDayCategory(t time.Time) string {
 	return "night"
 }
 
-// calculateStreak calculates consecutive days with focus sessions
+// calculateStreak calculates consecutive days with focus sessions, from the
+// activity index when available
 func (s *DashboardAnalyticsService) calculateStreak(ctx context.Context, uid string, referenceDate time.Time) int {
+	if s.activity != nil {
+		days, err := s.activity.ActiveDays(ctx, uid)
+		if err == nil {
+			return countStreak(days, referenceDate)
+		}
+		s.logger.Warn("Activity index unavailable, scanning sessions", zap.String("uid", uid), zap.Error(err))
+	}
+	return s.scanStreak(ctx, uid, referenceDate)
+}
+
+// scanStreak calculates the streak by reading every focus session
+func (s *DashboardAnalyticsService) scanStreak(ctx context.Context, uid string, referenceDate time.Time) int {
 	// Fetch ALL sessions to calculate streak (we need historical data)
 	collectionPath := fmt.Sprintf("users/%s/focusSessions", uid)
 	sessions, err := s.repo.List(ctx, collectionPath, 0)
@@ -537,20 +563,7 @@ func (s *DashboardAnalyticsService) calculateStreak(ctx context.Context, uid str
 		}
 	}
 
-	// Count backwards from reference date
-	streak := 0
-	currentDate := s.startOfDay(referenceDate)
-	for {
-		dateStr := currentDate.Format("2006-01-02")
-		if sessionsByDate[dateStr] {
-			streak++
-			currentDate = currentDate.AddDate(0, 0, -1)
-		} else {
-			break
-		}
-	}
-
-	return streak
+	return countStreak(sessionsByDate, referenceDate)
 }
 
 // countAllTasks counts all tasks (for completion rate)
@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,7 +17,7 @@ func TestDashboardAnalyticsService_ComputeAnalytics(t *testing.T) {
 	// Setup
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewDashboardAnalyticsService(mockRepo, logger)
+	service := NewDashboardAnalyticsService(mockRepo, logger, nil)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -123,7 +126,7 @@ func TestDashboardAnalyticsService_ComputeAnalytics(t *testing.T) {
 func TestDashboardAnalyticsService_CalculateStreak(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewDashboardAnalyticsService(mockRepo, logger)
+	service := NewDashboardAnalyticsService(mockRepo, logger, nil)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -204,3 +207,53 @@ func TestDashboardAnalyticsService_ResolvePeriodRange(t *testing.T) {
 		})
 	}
 }
+
+// failingListRepository fails List for one collection and records how many
+// fetches observed a cancelled context
+type failingListRepository struct {
+	*mocks.MockRepository
+	failSuffix string
+	cancelled  int32
+}
+
+func (r *failingListRepository) List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error) {
+	if strings.HasSuffix(collectionPath, r.failSuffix) {
+		return nil, errors.New("firestore unavailable")
+	}
+	select {
+	case <-ctx.Done():
+		atomic.AddInt32(&r.cancelled, 1)
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return r.MockRepository.List(ctx, collectionPath, limit)
+	}
+}
+
+func TestDashboardAnalyticsService_ComputeAnalytics_FetchError(t *testing.T) {
+	repo := &failingListRepository{
+		MockRepository: mocks.NewMockRepository(),
+		failSuffix:     "/goals",
+	}
+	service := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+
+	start := time.Now()
+	analytics, err := service.ComputeAnalytics(context.Background(), "test-user-123", PeriodWeek)
+
+	if err == nil {
+		t.Fatal("Expected error when a fetch fails")
+	}
+	if analytics != nil {
+		t.Error("Expected nil analytics on error")
+	}
+	if !strings.Contains(err.Error(), "firestore unavailable") {
+		t.Errorf("Expected the first fetch error to be returned, got %v", err)
+	}
+
+	// Sibling fetches should be cancelled rather than left running
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Errorf("Expected sibling fetches to be cancelled, took %v", elapsed)
+	}
+	if got := atomic.LoadInt32(&repo.cancelled); got != 3 {
+		t.Errorf("Expected 3 cancelled sibling fetches, got %d", got)
+	}
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+var (
+	// ErrUnsupportedCollection is returned for collections not exposed through the document endpoints
+	ErrUnsupportedCollection = errors.New("unsupported collection")
+	// ErrUnknownField is returned when a sparse fieldset names a field the
+	// collection's configured schema does not have
+	ErrUnknownField = errors.New("unknown field")
+	// ErrHistoryDisabled is returned when reading history for a collection
+	// without soft history enabled
+	ErrHistoryDisabled = errors.New("history is not enabled for this collection")
+	// ErrImmutableField is returned in strict mode when an update would change a
+	// field configured as immutable for the collection
+	ErrImmutableField = errors.New("field is immutable")
+)
+
+const (
+	// DefaultDocumentListLimit is used when neither the request nor config sets a page size
+	DefaultDocumentListLimit = 100
+	// MaxDocumentListLimit caps list requests when config sets no max page size
+	MaxDocumentListLimit = 500
+	// DefaultDocumentHistoryLimit is the number of history entries kept when
+	// soft history is enabled without a limit
+	DefaultDocumentHistoryLimit = 50
+)
+
+// historyMetadataFields change on every update and are left out of history diffs
+var historyMetadataFields = map[string]bool{"updatedAt": true, "updatedBy": true, "version": true}
+
+// FieldChange is a field's value before and after an update; nil means absent
+type FieldChange struct {
+	From interface{} `json:"from" firestore:"from"`
+	To   interface{} `json:"to" firestore:"to"`
+}
+
+// defaultDocumentOrdering is applied when a list request does not specify an ordering
+var defaultDocumentOrdering = []interfaces.Ordering{{Field: "createdAt", Direction: firestore.Desc}}
+
+// documentCollections lists the user collections exposed through the generic document endpoints
+var documentCollections = map[string]bool{
+	"tasks":            true,
+	"projects":         true,
+	"goals":            true,
+	"thoughts":         true,
+	"notes":            true,
+	"errands":          true,
+	"trips":            true,
+	"sessionTemplates": true,
+}
+
+// patchOnlyCollections can be updated through the document endpoints but are
+// not listed or duplicated there; their records come from imports and syncs
+var patchOnlyCollections = map[string]bool{
+	"transactions": true,
+	"accounts":     true,
+	"portfolios":   true,
+}
+
+// readOnlyCollections can be read through the document endpoints but never
+// written there; the server appends their records
+var readOnlyCollections = map[string]bool{
+	"auditLog": true,
+}
+
+// protectedDocumentFields are managed by the server: they are regenerated on
+// duplicates and cannot be modified by patches
+var protectedDocumentFields = []string{"id", "createdAt", "updatedAt", "updatedBy", "version"}
+
+// DocumentService handles operations shared by user document collections
+type DocumentService struct {
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	cfg      *config.DocumentsConfig
+	tags     *TagService
+	notifier *NotificationService
+	storage  AttachmentStorage
+}
+
+// NewDocumentService creates a new document service. cfg, tags, notifier and
+// storage may be nil; without a tag service, writes do not update tag counts,
+// without a notifier, completed goals are not announced, and without storage,
+// batch deletes report every storage object as a failure.
+func NewDocumentService(repo interfaces.Repository, logger *zap.Logger, cfg *config.DocumentsConfig, tags *TagService, notifier *NotificationService, storage AttachmentStorage) *DocumentService {
+	if cfg == nil {
+		cfg = &config.DocumentsConfig{}
+	}
+	return &DocumentService{
+		repo:     repo,
+		logger:   logger,
+		cfg:      cfg,
+		tags:     tags,
+		notifier: notifier,
+		storage:  storage,
+	}
+}
+
+// pageSizes resolves the default and max page size for a collection:
+// per-collection config, then global config, then the built-in limits
+func (s *DocumentService) pageSizes(collection string) (defaultSize, maxSize int) {
+	defaultSize, maxSize = s.cfg.DefaultPageSize, s.cfg.MaxPageSize
+	if override, ok := s.cfg.Collections[collection]; ok {
+		if override.DefaultPageSize > 0 {
+			defaultSize = override.DefaultPageSize
+		}
+		if override.MaxPageSize > 0 {
+			maxSize = override.MaxPageSize
+		}
+	}
+	if maxSize <= 0 {
+		maxSize = MaxDocumentListLimit
+	}
+	if defaultSize <= 0 {
+		defaultSize = DefaultDocumentListLimit
+	}
+	if defaultSize > maxSize {
+		defaultSize = maxSize
+	}
+	return defaultSize, maxSize
+}
+
+// List returns documents from a user collection sorted by the given orderings,
+// newest first when none are given. The collection's default filters apply
+// unless filters.IncludeArchived is set or a where filter names the same field.
+// Filters are matched after reading, so a filtered list reads up to the max
+// page size to fill the requested limit.
+func (s *DocumentService) List(ctx context.Context, uid, collection string, orderings []interfaces.Ordering, filters DocumentListFilters, limit int) ([]map[string]interface{}, error) {
+	if !documentCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	if len(orderings) == 0 {
+		orderings = defaultDocumentOrdering
+	}
+	defaultSize, maxSize := s.pageSizes(collection)
+	if limit <= 0 {
+		limit = defaultSize
+	}
+	if limit > maxSize {
+		limit = maxSize
+	}
+
+	where := s.listFilters(collection, filters)
+	if len(where) == 0 {
+		return s.repo.ListOrdered(ctx, fmt.Sprintf("users/%s/%s", uid, collection), orderings, limit)
+	}
+
+	docs, err := s.repo.ListOrdered(ctx, fmt.Sprintf("users/%s/%s", uid, collection), orderings, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]map[string]interface{}, 0, limit)
+	for _, doc := range docs {
+		if matchesDocumentFilters(doc, where) {
+			matched = append(matched, doc)
+			if len(matched) == limit {
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// Get returns a single document from a user collection
+func (s *DocumentService) Get(ctx context.Context, uid, collection, id string) (map[string]interface{}, error) {
+	if !documentCollections[collection] && !patchOnlyCollections[collection] && !readOnlyCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	doc, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/%s/%s", uid, collection, id))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := doc["id"]; !ok {
+		doc["id"] = id
+	}
+	return doc, nil
+}
+
+// ValidateFields checks a sparse fieldset against the collection's configured
+// fields. Collections without a configured schema accept any field.
+func (s *DocumentService) ValidateFields(collection string, fields []string) error {
+	known := s.cfg.Collections[collection].Fields
+	if len(known) == 0 {
+		return nil
+	}
+	for _, field := range fields {
+		if field == "id" {
+			continue
+		}
+		found := false
+		for _, k := range known {
+			if k == field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+	}
+	return nil
+}
+
+// ProjectDocument returns a copy of doc holding only the requested top-level
+// fields plus id. An empty fieldset returns doc unchanged.
+func ProjectDocument(doc map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return doc
+	}
+	projected := make(map[string]interface{}, len(fields)+1)
+	if id, ok := doc["id"]; ok {
+		projected["id"] = id
+	}
+	for _, field := range fields {
+		if value, ok := doc[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected
+}
+
+// Duplicate creates a shallow copy of a document under a new ID, applying overrides.
+// Nested structures (such as a trip's packing list) are copied as-is.
+func (s *DocumentService) Duplicate(ctx context.Context, uid, collection, id string, overrides map[string]interface{}) (map[string]interface{}, error) {
+	if !documentCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	source, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/%s/%s", uid, collection, id))
+	if err != nil {
+		return nil, err
+	}
+
+	newID := generateID()
+	duplicate := buildDuplicate(source, overrides, newID)
+
+	if err := s.repo.CreateDocument(ctx, fmt.Sprintf("users/%s/%s/%s", uid, collection, newID), duplicate); err != nil {
+		return nil, fmt.Errorf("failed to create duplicate: %w", err)
+	}
+	s.recordTagChange(ctx, uid, collection, nil, duplicate)
+
+	s.logger.Info("Document duplicated",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.String("sourceId", id),
+		zap.String("id", newID),
+	)
+
+	return duplicate, nil
+}
+
+// Patch applies RFC 6902 operations to a document inside a transaction, so
+// "test" operations guard against concurrent edits. Returns the updated document.
+func (s *DocumentService) Patch(ctx context.Context, uid, collection, id string, ops []PatchOperation) (map[string]interface{}, error) {
+	if !documentCollections[collection] && !patchOnlyCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	ops, dropped, err := guardImmutableFields(ops, s.cfg.Collections[collection].ImmutableFields, s.cfg.StrictImmutableFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(dropped) > 0 {
+		s.logger.Warn("Dropped changes to immutable fields",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.String("id", id),
+			zap.Strings("fields", dropped),
+		)
+	}
+
+	path := fmt.Sprintf("users/%s/%s/%s", uid, collection, id)
+	ref := s.repo.Client().Doc(path)
+
+	var original, patched map[string]interface{}
+	err = s.repo.Client().RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return fmt.Errorf("failed to get document at %s: %w", path, interfaces.ErrNotFound)
+			}
+			return err
+		}
+
+		original = snap.Data()
+		patched, err = applyJSONPatch(original, ops, protectedDocumentFields)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		stampPatchedDocument(patched, uid, now)
+
+		if s.cfg.Collections[collection].SoftHistory {
+			entry := buildHistoryEntry(original, patched, uid, now)
+			if err := tx.Create(ref.Collection("history").Doc(entry["id"].(string)), entry); err != nil {
+				return err
+			}
+		}
+		return tx.Set(ref, patched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordTagChange(ctx, uid, collection, original, patched)
+	if s.cfg.Collections[collection].SoftHistory {
+		s.pruneHistory(ctx, uid, collection, id)
+	}
+	if collection == "goals" && goalCompleted(original, patched) {
+		s.notifier.Notify(ctx, uid, NotificationEvent{
+			Type:  NotificationEventGoalCompleted,
+			Title: "Goal completed",
+			Body:  getStringField(patched, "title"),
+		})
+	}
+
+	s.logger.Info("Document patched",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.String("id", id),
+		zap.Int("operations", len(ops)),
+	)
+
+	return patched, nil
+}
+
+// History returns a document's change log, newest first
+func (s *DocumentService) History(ctx context.Context, uid, collection, id string) ([]map[string]interface{}, error) {
+	if !documentCollections[collection] && !patchOnlyCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	if !s.cfg.Collections[collection].SoftHistory {
+		return nil, fmt.Errorf("%w: %s", ErrHistoryDisabled, collection)
+	}
+
+	if _, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/%s/%s", uid, collection, id)); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOrdered(ctx, historyPath(uid, collection, id), historyOrdering, s.historyLimit(collection))
+}
+
+// historyOrdering lists history entries newest first
+var historyOrdering = []interfaces.Ordering{{Field: "changedAt", Direction: firestore.Desc}}
+
+func (s *DocumentService) historyLimit(collection string) int {
+	if limit := s.cfg.Collections[collection].HistoryLimit; limit > 0 {
+		return limit
+	}
+	return DefaultDocumentHistoryLimit
+}
+
+// pruneHistory deletes history entries beyond the collection's limit. Failures
+// are logged; the extra entries are removed by the next update.
+func (s *DocumentService) pruneHistory(ctx context.Context, uid, collection, id string) {
+	path := historyPath(uid, collection, id)
+	entries, err := s.repo.ListOrdered(ctx, path, historyOrdering, 0)
+	if err != nil {
+		s.logger.Warn("Failed to list document history", zap.String("path", path), zap.Error(err))
+		return
+	}
+
+	for _, entry := range entries[min(len(entries), s.historyLimit(collection)):] {
+		entryID, _ := entry["id"].(string)
+		if entryID == "" {
+			continue
+		}
+		if err := s.repo.Delete(ctx, fmt.Sprintf("%s/%s", path, entryID)); err != nil {
+			s.logger.Warn("Failed to prune document history", zap.String("path", path), zap.Error(err))
+			return
+		}
+	}
+}
+
+// buildHistoryEntry records who changed a document, when, and a field-level
+// diff of the change
+func buildHistoryEntry(before, after map[string]interface{}, uid string, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        generateID(),
+		"changedAt": now,
+		"updatedBy": uid,
+		"version":   after["version"],
+		"changes":   diffDocuments(before, after),
+	}
+}
+
+// diffDocuments returns the top-level fields that differ between before and
+// after, ignoring update metadata
+func diffDocuments(before, after map[string]interface{}) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	for field, from := range before {
+		if historyMetadataFields[field] {
+			continue
+		}
+		to, ok := after[field]
+		if !ok {
+			changes[field] = FieldChange{From: from}
+		} else if !patchValuesEqual(from, to) {
+			changes[field] = FieldChange{From: from, To: to}
+		}
+	}
+	for field, to := range after {
+		if _, ok := before[field]; !ok && !historyMetadataFields[field] {
+			changes[field] = FieldChange{To: to}
+		}
+	}
+	return changes
+}
+
+func historyPath(uid, collection, id string) string {
+	return fmt.Sprintf("users/%s/%s/%s/history", uid, collection, id)
+}
+
+// recordTagChange updates tag counts after a write. Counts are advisory, so a
+// failure is logged rather than failing the write.
+func (s *DocumentService) recordTagChange(ctx context.Context, uid, collection string, before, after map[string]interface{}) {
+	if err := s.tags.RecordChange(ctx, uid, collection, before, after); err != nil {
+		s.logger.Warn("Failed to update tag counts",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.Error(err),
+		)
+	}
+}
+
+// goalCompleted reports whether an update moved a goal into the completed status
+func goalCompleted(before, after map[string]interface{}) bool {
+	return getStringField(before, "status") != "completed" && getStringField(after, "status") == "completed"
+}
+
+// guardImmutableFields checks ops against a collection's immutable fields. In
+// strict mode any mutating op on one is an error; otherwise those ops are
+// removed and the affected fields returned. "test" ops are always kept.
+func guardImmutableFields(ops []PatchOperation, immutable []string, strict bool) ([]PatchOperation, []string, error) {
+	if len(immutable) == 0 {
+		return ops, nil, nil
+	}
+
+	kept := make([]PatchOperation, 0, len(ops))
+	var dropped []string
+	for _, op := range ops {
+		field := immutablePatchTarget(op, immutable)
+		if field == "" {
+			kept = append(kept, op)
+			continue
+		}
+		if strict {
+			return nil, nil, fmt.Errorf("%w: %s", ErrImmutableField, field)
+		}
+		dropped = append(dropped, field)
+	}
+	return kept, dropped, nil
+}
+
+// immutablePatchTarget returns the immutable field a mutating op targets, if any
+func immutablePatchTarget(op PatchOperation, immutable []string) string {
+	if op.Op == "test" {
+		return ""
+	}
+	tokens, err := parseJSONPointer(op.Path)
+	if err != nil || len(tokens) == 0 {
+		// Left for applyJSONPatch to reject
+		return ""
+	}
+	for _, field := range immutable {
+		if tokens[0] == field {
+			return field
+		}
+	}
+	return ""
+}
+
+// stampPatchedDocument updates the metadata a patch is not allowed to touch
+func stampPatchedDocument(doc map[string]interface{}, uid string, now time.Time) {
+	version := int64(0)
+	switch v := doc["version"].(type) {
+	case int64:
+		version = v
+	case float64:
+		version = int64(v)
+	}
+	doc["version"] = version + 1
+	doc["updatedAt"] = now
+	doc["updatedBy"] = uid
+}
+
+// buildDuplicate copies source without its identity and metadata fields, then applies overrides
+func buildDuplicate(source, overrides map[string]interface{}, newID string) map[string]interface{} {
+	duplicate := make(map[string]interface{}, len(source)+len(overrides))
+	for k, v := range source {
+		duplicate[k] = v
+	}
+	for k, v := range overrides {
+		duplicate[k] = v
+	}
+	for _, field := range protectedDocumentFields {
+		delete(duplicate, field)
+	}
+	// Attachments point at storage objects owned by the source; sharing them
+	// would let removing one copy's attachment delete the other's file
+	delete(duplicate, AttachmentsField)
+	duplicate["id"] = newID
+	return duplicate
+}
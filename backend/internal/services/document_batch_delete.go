@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// MaxBatchDeleteItems caps the documents in one batch delete
+const MaxBatchDeleteItems = 500
+
+// ErrInvalidBatchDelete is returned for an empty or oversized batch delete
+var ErrInvalidBatchDelete = errors.New("invalid batch delete request")
+
+// storageBackedCollections maps collections whose documents own Cloud Storage
+// objects to a function listing those objects' paths
+var storageBackedCollections = map[string]func(doc map[string]interface{}) []string{
+	"photoLibrary": photoStoragePaths,
+	"tasks":        attachmentStoragePaths,
+	"thoughts":     attachmentStoragePaths,
+}
+
+// BatchDeleteError is a failure for one document or storage object
+type BatchDeleteError struct {
+	ID    string `json:"id"`
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error"`
+}
+
+// BatchDeleteResult summarizes a batch delete. Document and storage failures
+// are reported separately; a document whose files failed to delete is still
+// counted as deleted.
+type BatchDeleteResult struct {
+	DeletedDocs  int                `json:"deletedDocs"`
+	DeletedFiles int                `json:"deletedFiles"`
+	DocErrors    []BatchDeleteError `json:"docErrors"`
+	FileErrors   []BatchDeleteError `json:"fileErrors"`
+}
+
+// BatchDelete deletes documents from a user collection, reporting missing
+// ones rather than failing. The documents are read in one round trip and
+// deleted in batched writes. For storage-backed collections the documents'
+// storage objects are deleted too, once their document is gone.
+func (s *DocumentService) BatchDelete(ctx context.Context, uid, collection string, ids []string) (*BatchDeleteResult, error) {
+	if !documentCollections[collection] && storageBackedCollections[collection] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	ids = dedupeStrings(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one id is required", ErrInvalidBatchDelete)
+	}
+	if len(ids) > MaxBatchDeleteItems {
+		return nil, fmt.Errorf("%w: maximum is %d items", ErrInvalidBatchDelete, MaxBatchDeleteItems)
+	}
+
+	result := &BatchDeleteResult{
+		DocErrors:  []BatchDeleteError{},
+		FileErrors: []BatchDeleteError{},
+	}
+	storagePaths := storageBackedCollections[collection]
+
+	paths := make([]string, len(ids))
+	for i, id := range ids {
+		paths[i] = fmt.Sprintf("users/%s/%s/%s", uid, collection, id)
+	}
+	docs, err := s.repo.GetAll(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load documents: %w", err)
+	}
+
+	var foundIDs, foundPaths []string
+	var found []map[string]interface{}
+	for i, doc := range docs {
+		if doc == nil {
+			result.DocErrors = append(result.DocErrors, BatchDeleteError{ID: ids[i], Error: "document not found"})
+			continue
+		}
+		foundIDs = append(foundIDs, ids[i])
+		foundPaths = append(foundPaths, paths[i])
+		found = append(found, doc)
+	}
+
+	deleted, err := s.repo.DeleteAll(ctx, foundPaths)
+	if err != nil {
+		s.logger.Warn("Failed to delete documents in batch",
+			zap.String("uid", uid),
+			zap.String("collection", collection),
+			zap.Int("deleted", deleted),
+			zap.Int("failed", len(foundPaths)-deleted),
+			zap.Error(err),
+		)
+		for _, id := range foundIDs[deleted:] {
+			result.DocErrors = append(result.DocErrors, BatchDeleteError{ID: id, Error: "failed to delete document"})
+		}
+	}
+	result.DeletedDocs = deleted
+
+	for i, doc := range found[:deleted] {
+		s.recordTagChange(ctx, uid, collection, doc, nil)
+
+		if storagePaths == nil {
+			continue
+		}
+		for _, objectPath := range storagePaths(doc) {
+			if err := s.deleteStorageObject(ctx, uid, objectPath); err != nil {
+				s.logger.Warn("Failed to delete storage object in batch",
+					zap.String("uid", uid),
+					zap.String("storagePath", objectPath),
+					zap.Error(err),
+				)
+				result.FileErrors = append(result.FileErrors, BatchDeleteError{ID: foundIDs[i], Path: objectPath, Error: err.Error()})
+				continue
+			}
+			result.DeletedFiles++
+		}
+	}
+
+	s.logger.Info("Batch delete finished",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.Int("deletedDocs", result.DeletedDocs),
+		zap.Int("deletedFiles", result.DeletedFiles),
+		zap.Int("docErrors", len(result.DocErrors)),
+		zap.Int("fileErrors", len(result.FileErrors)),
+	)
+	return result, nil
+}
+
+// deleteStorageObject deletes one object after checking the user owns it
+func (s *DocumentService) deleteStorageObject(ctx context.Context, uid, path string) error {
+	if err := userOwnsPath(uid, path); err != nil {
+		return err
+	}
+	if s.storage == nil {
+		return errors.New("storage is not configured")
+	}
+	return s.storage.DeleteObject(ctx, path)
+}
+
+// photoStoragePaths lists a library photo's original and thumbnail objects
+func photoStoragePaths(doc map[string]interface{}) []string {
+	var paths []string
+	for _, field := range []string{"storagePath", "thumbnailPath"} {
+		if path := getStringField(doc, field); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// attachmentStoragePaths lists the objects uploaded as attachments to a task
+// or thought. Linked photo library objects are left to the library.
+func attachmentStoragePaths(doc map[string]interface{}) []string {
+	var paths []string
+	for _, attachment := range parseAttachments(doc[AttachmentsField]) {
+		if isAttachmentObject(attachment.StoragePath) {
+			paths = append(paths, attachment.StoragePath)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestDocumentService_BatchDeletePhotosRemovesStorageObjects(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/u1/photoLibrary/p1", map[string]interface{}{
+		"storagePath":   "images/original/u1/p1.jpg",
+		"thumbnailPath": "images/thumb/u1/p1.jpg",
+	})
+	repo.AddDocument("users/u1/photoLibrary/p2", map[string]interface{}{
+		"storagePath": "images/original/u1/p2.jpg",
+	})
+	repo.AddDocument("users/u1/photoLibrary/p3", map[string]interface{}{
+		"storagePath":   "images/original/u1/p3.jpg",
+		"thumbnailPath": "images/thumb/u1/p3.jpg",
+	})
+	// A path pointing at another user's file is never deleted
+	repo.AddDocument("users/u1/photoLibrary/p4", map[string]interface{}{
+		"storagePath": "images/original/u2/p9.jpg",
+	})
+	storage := &fakeAttachmentStorage{failing: map[string]bool{"images/thumb/u1/p3.jpg": true}}
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, storage)
+
+	result, err := svc.BatchDelete(context.Background(), "u1", "photoLibrary", []string{"p1", "p2", "p3", "p4", "missing", "p1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, result.DeletedDocs)
+	assert.Equal(t, 4, result.DeletedFiles)
+	assert.ElementsMatch(t, []string{
+		"images/original/u1/p1.jpg",
+		"images/thumb/u1/p1.jpg",
+		"images/original/u1/p2.jpg",
+		"images/original/u1/p3.jpg",
+	}, storage.deleted)
+
+	require.Len(t, result.DocErrors, 1)
+	assert.Equal(t, BatchDeleteError{ID: "missing", Error: "document not found"}, result.DocErrors[0])
+	require.Len(t, result.FileErrors, 2)
+	assert.Equal(t, "images/thumb/u1/p3.jpg", result.FileErrors[0].Path)
+	assert.Equal(t, "p4", result.FileErrors[1].ID)
+
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		_, err := repo.Get(context.Background(), "users/u1/photoLibrary/"+id)
+		assert.Error(t, err, "document %s should be deleted", id)
+	}
+}
+
+func TestDocumentService_BatchDeleteAttachmentsAndPlainCollections(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/u1/tasks/t1", map[string]interface{}{
+		AttachmentsField: []interface{}{
+			map[string]interface{}{"storagePath": "users/u1/attachments/a.pdf", "contentType": "application/pdf"},
+			// A linked library photo stays with the library
+			map[string]interface{}{"storagePath": "images/original/u1/p1.jpg", "contentType": "image/jpeg"},
+		},
+	})
+	repo.AddDocument("users/u1/notes/n1", map[string]interface{}{"title": "note"})
+	storage := &fakeAttachmentStorage{}
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, storage)
+
+	result, err := svc.BatchDelete(context.Background(), "u1", "tasks", []string{"t1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.DeletedDocs)
+	assert.Equal(t, []string{"users/u1/attachments/a.pdf"}, storage.deleted)
+
+	result, err = svc.BatchDelete(context.Background(), "u1", "notes", []string{"n1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.DeletedDocs)
+	assert.Equal(t, 0, result.DeletedFiles)
+	assert.Empty(t, result.FileErrors)
+}
+
+func TestDocumentService_BatchDeleteValidation(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil, nil)
+
+	_, err := svc.BatchDelete(context.Background(), "u1", "subscriptions", []string{"a"})
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.BatchDelete(context.Background(), "u1", "tasks", nil)
+	assert.ErrorIs(t, err, ErrInvalidBatchDelete)
+
+	ids := make([]string, MaxBatchDeleteItems+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+	_, err = svc.BatchDelete(context.Background(), "u1", "tasks", ids)
+	assert.ErrorIs(t, err, ErrInvalidBatchDelete)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// DocumentListFilters narrows a document list request
+type DocumentListFilters struct {
+	// Where filters use == or != and are matched against top-level fields
+	Where []interfaces.Filter
+	// IncludeArchived skips the collection's default filters
+	IncludeArchived bool
+}
+
+// listFilters returns the request's where filters plus the collection's
+// default filters on fields the request does not filter itself
+func (s *DocumentService) listFilters(collection string, filters DocumentListFilters) []interfaces.Filter {
+	where := append([]interfaces.Filter{}, filters.Where...)
+	if filters.IncludeArchived {
+		return where
+	}
+
+	requested := make(map[string]bool, len(filters.Where))
+	for _, f := range filters.Where {
+		requested[f.Field] = true
+	}
+	for _, f := range s.cfg.Collections[collection].DefaultFilters {
+		if !requested[f.Field] {
+			where = append(where, interfaces.Filter{Field: f.Field, Op: f.Op, Value: f.Value})
+		}
+	}
+	return where
+}
+
+// matchesDocumentFilters reports whether doc passes every filter. Values are
+// compared in their string form, so "true" matches a boolean field; a missing
+// field is not equal to any value.
+func matchesDocumentFilters(doc map[string]interface{}, filters []interfaces.Filter) bool {
+	for _, f := range filters {
+		value, ok := doc[f.Field]
+		equal := ok && value != nil && fmt.Sprint(value) == fmt.Sprint(f.Value)
+		if (f.Op == "==") != equal {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+//go:build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+func newEmulatorDocumentService(t *testing.T, cfg *config.DocumentsConfig) (*DocumentService, *firestore.Client, string) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	return NewDocumentService(repository.NewFirestoreRepository(client), zap.NewNop(), cfg, nil, nil, nil), client, uid
+}
+
+func TestDocumentService_ListOrderingAndPageSize_Emulator(t *testing.T) {
+	svc, client, uid := newEmulatorDocumentService(t, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"tasks": {DefaultPageSize: 2, MaxPageSize: 3},
+		},
+	})
+	ctx := context.Background()
+
+	testutil.SeedUserDocs(t, client, uid, "tasks",
+		map[string]interface{}{"id": "a", "priority": "high", "dueDate": "2024-03-02", "createdAt": "1"},
+		map[string]interface{}{"id": "b", "priority": "low", "dueDate": "2024-03-01", "createdAt": "2"},
+		map[string]interface{}{"id": "c", "priority": "high", "dueDate": "2024-03-01", "createdAt": "3"},
+		map[string]interface{}{"id": "d", "priority": "medium", "dueDate": "2024-03-03", "createdAt": "4"},
+	)
+
+	docs, err := svc.List(ctx, uid, "tasks", []interfaces.Ordering{
+		{Field: "priority", Direction: firestore.Asc},
+		{Field: "dueDate", Direction: firestore.Asc},
+	}, DocumentListFilters{}, 3)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, []interface{}{"c", "a", "b"}, []interface{}{docs[0]["id"], docs[1]["id"], docs[2]["id"]})
+
+	// Default ordering is newest first with the collection's default page size
+	docs, err = svc.List(ctx, uid, "tasks", nil, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "d", docs[0]["id"])
+
+	// Oversized requests are clamped to the max page size
+	docs, err = svc.List(ctx, uid, "tasks", nil, DocumentListFilters{}, 1000)
+	require.NoError(t, err)
+	assert.Len(t, docs, 3)
+}
+
+func TestDocumentService_DuplicateAndPatch_Emulator(t *testing.T) {
+	svc, client, uid := newEmulatorDocumentService(t, nil)
+	ctx := context.Background()
+
+	testutil.SeedUserDocs(t, client, uid, "tasks",
+		map[string]interface{}{"id": "task1", "title": "Write report", "tags": []interface{}{"work"}, "version": int64(1)},
+	)
+
+	copied, err := svc.Duplicate(ctx, uid, "tasks", "task1", map[string]interface{}{"title": "Write report (copy)"})
+	require.NoError(t, err)
+	assert.NotEqual(t, "task1", copied["id"])
+
+	snap, err := client.Doc("users/" + uid + "/tasks/" + copied["id"].(string)).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Write report (copy)", snap.Data()["title"])
+
+	// Patch runs in a transaction against the user-scoped path
+	patched, err := svc.Patch(ctx, uid, "tasks", "task1", []PatchOperation{
+		{Op: "test", Path: "/title", Value: "Write report"},
+		{Op: "add", Path: "/tags/-", Value: "urgent"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), patched["version"])
+
+	snap, err = client.Doc("users/" + uid + "/tasks/task1").Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"work", "urgent"}, snap.Data()["tags"])
+
+	_, err = svc.Patch(ctx, uid, "tasks", "missing", []PatchOperation{{Op: "remove", Path: "/title"}})
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestDocumentService_PatchRecordsHistory_Emulator(t *testing.T) {
+	svc, client, uid := newEmulatorDocumentService(t, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"goals": {SoftHistory: true, HistoryLimit: 2},
+		},
+	})
+	ctx := context.Background()
+
+	testutil.SeedUserDocs(t, client, uid, "goals",
+		map[string]interface{}{"id": "g1", "title": "Learn Go", "progress": int64(0), "version": int64(1)},
+	)
+
+	for _, progress := range []int64{25, 50, 75} {
+		_, err := svc.Patch(ctx, uid, "goals", "g1", []PatchOperation{
+			{Op: "replace", Path: "/progress", Value: progress},
+		})
+		require.NoError(t, err)
+	}
+
+	history, err := svc.History(ctx, uid, "goals", "g1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, int64(4), history[0]["version"])
+	assert.Equal(t, uid, history[0]["updatedBy"])
+	changes := history[0]["changes"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"from": int64(50), "to": int64(75)}, changes["progress"])
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+)
+
+// CollectionSchema describes a document collection in a JSON-schema-like shape
+// so clients can generate and validate forms. Properties are keyed by field
+// name; FieldOrder gives the configured display order. Collections without a
+// configured field list allow additional properties.
+type CollectionSchema struct {
+	Collection           string                 `json:"collection"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]FieldSchema `json:"properties"`
+	FieldOrder           []string               `json:"fieldOrder"`
+	AdditionalProperties bool                   `json:"additionalProperties"`
+	Listable             bool                   `json:"listable"`
+	Duplicable           bool                   `json:"duplicable"`
+	History              bool                   `json:"history"`
+	DefaultPageSize      int                    `json:"defaultPageSize"`
+	MaxPageSize          int                    `json:"maxPageSize"`
+}
+
+// FieldSchema describes one top-level field of a collection. ReadOnly fields
+// cannot be changed through the document endpoints, either because the server
+// manages them or because the collection configures them as immutable.
+type FieldSchema struct {
+	ReadOnly      bool `json:"readOnly"`
+	ServerManaged bool `json:"serverManaged"`
+	Immutable     bool `json:"immutable"`
+}
+
+// Schema returns the configured schema of a document collection. Field types,
+// enums and ranges are not configured yet, so properties only carry flags.
+func (s *DocumentService) Schema(collection string) (*CollectionSchema, error) {
+	if !documentCollections[collection] && !patchOnlyCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	cfg := s.cfg.Collections[collection]
+	defaultSize, maxSize := s.pageSizes(collection)
+	schema := &CollectionSchema{
+		Collection:           collection,
+		Type:                 "object",
+		Properties:           make(map[string]FieldSchema),
+		FieldOrder:           []string{},
+		AdditionalProperties: len(cfg.Fields) == 0,
+		Listable:             documentCollections[collection],
+		Duplicable:           documentCollections[collection],
+		History:              cfg.SoftHistory,
+		DefaultPageSize:      defaultSize,
+		MaxPageSize:          maxSize,
+	}
+
+	addField := func(name string) {
+		if _, ok := schema.Properties[name]; ok {
+			return
+		}
+		schema.Properties[name] = FieldSchema{}
+		schema.FieldOrder = append(schema.FieldOrder, name)
+	}
+	for _, field := range cfg.Fields {
+		addField(field)
+	}
+	for _, field := range protectedDocumentFields {
+		addField(field)
+		schema.Properties[field] = FieldSchema{ReadOnly: true, ServerManaged: true}
+	}
+	for _, field := range cfg.ImmutableFields {
+		addField(field)
+		property := schema.Properties[field]
+		property.ReadOnly = true
+		property.Immutable = true
+		schema.Properties[field] = property
+	}
+
+	return schema, nil
+}
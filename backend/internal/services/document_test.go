@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestDocumentService_DuplicateTaskWithOverride(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/task1", map[string]interface{}{
+		"id":        "task1",
+		"title":     "Weekly review",
+		"priority":  "high",
+		"createdAt": "2024-01-01T00:00:00Z",
+		"version":   3,
+	})
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, nil)
+
+	duplicate, err := svc.Duplicate(context.Background(), "user1", "tasks", "task1", map[string]interface{}{
+		"title": "Weekly review (template)",
+	})
+	require.NoError(t, err)
+
+	newID, _ := duplicate["id"].(string)
+	assert.NotEmpty(t, newID)
+	assert.NotEqual(t, "task1", newID)
+	assert.Equal(t, "Weekly review (template)", duplicate["title"])
+	assert.Equal(t, "high", duplicate["priority"])
+	assert.NotContains(t, duplicate, "createdAt")
+	assert.NotContains(t, duplicate, "version")
+
+	stored, err := repo.Get(context.Background(), "users/user1/tasks/"+newID)
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly review (template)", stored["title"])
+
+	source, _ := repo.Get(context.Background(), "users/user1/tasks/task1")
+	assert.Equal(t, "Weekly review", source["title"])
+}
+
+func TestDocumentService_DuplicateErrors(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil, nil)
+
+	_, err := svc.Duplicate(context.Background(), "user1", "subscriptionStatus", "x", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+
+	_, err = svc.Duplicate(context.Background(), "user1", "tasks", "missing", nil)
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestBuildDuplicate_OverridesCannotSetIdentity(t *testing.T) {
+	duplicate := buildDuplicate(
+		map[string]interface{}{"id": "a", "title": "A"},
+		map[string]interface{}{"id": "b", "version": 9},
+		"new-id",
+	)
+
+	assert.Equal(t, map[string]interface{}{"id": "new-id", "title": "A"}, duplicate)
+}
+
+func TestDocumentService_ListOrdersByMultipleFields(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/a", map[string]interface{}{"priority": "high", "dueDate": "2024-03-02", "createdAt": "1"})
+	repo.AddDocument("users/user1/tasks/b", map[string]interface{}{"priority": "low", "dueDate": "2024-03-01", "createdAt": "2"})
+	repo.AddDocument("users/user1/tasks/c", map[string]interface{}{"priority": "high", "dueDate": "2024-03-01", "createdAt": "3"})
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, nil)
+
+	docs, err := svc.List(context.Background(), "user1", "tasks", []interfaces.Ordering{
+		{Field: "priority", Direction: firestore.Asc},
+		{Field: "dueDate", Direction: firestore.Asc},
+	}, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, []interface{}{"c", "a", "b"}, []interface{}{docs[0]["id"], docs[1]["id"], docs[2]["id"]})
+
+	// Without orderings the newest documents come first
+	docs, err = svc.List(context.Background(), "user1", "tasks", nil, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "c", docs[0]["id"])
+
+	_, err = svc.List(context.Background(), "user1", "usageStats", nil, DocumentListFilters{}, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
+
+func TestDocumentService_PageSizes(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil, nil)
+	def, max := svc.pageSizes("tasks")
+	assert.Equal(t, DefaultDocumentListLimit, def)
+	assert.Equal(t, MaxDocumentListLimit, max)
+
+	svc = NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), &config.DocumentsConfig{
+		DefaultPageSize: 50,
+		MaxPageSize:     200,
+		Collections: map[string]config.CollectionConfig{
+			"notes":    {DefaultPageSize: 24},
+			"thoughts": {MaxPageSize: 1000},
+			"goals":    {DefaultPageSize: 300},
+		},
+	}, nil, nil, nil)
+
+	def, max = svc.pageSizes("tasks")
+	assert.Equal(t, 50, def)
+	assert.Equal(t, 200, max)
+
+	def, max = svc.pageSizes("notes")
+	assert.Equal(t, 24, def)
+	assert.Equal(t, 200, max)
+
+	_, max = svc.pageSizes("thoughts")
+	assert.Equal(t, 1000, max)
+
+	// A default above the max is clamped to the max
+	def, _ = svc.pageSizes("goals")
+	assert.Equal(t, 200, def)
+}
+
+func TestDocumentService_ListClampsOversizedLimit(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	for _, id := range []string{"a", "b", "c"} {
+		repo.AddDocument("users/user1/notes/"+id, map[string]interface{}{"createdAt": id})
+	}
+	svc := NewDocumentService(repo, zap.NewNop(), &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"notes": {DefaultPageSize: 1, MaxPageSize: 2},
+		},
+	}, nil, nil, nil)
+
+	docs, err := svc.List(context.Background(), "user1", "notes", nil, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+
+	docs, err = svc.List(context.Background(), "user1", "notes", nil, DocumentListFilters{}, 1000)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+}
+
+func TestDocumentService_ListDefaultFilters(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/a", map[string]interface{}{"status": "active", "createdAt": "1"})
+	repo.AddDocument("users/user1/tasks/b", map[string]interface{}{"status": "archived", "createdAt": "2"})
+	repo.AddDocument("users/user1/tasks/c", map[string]interface{}{"createdAt": "3"})
+	repo.AddDocument("users/user1/tasks/d", map[string]interface{}{"status": "archived", "createdAt": "4"})
+	svc := NewDocumentService(repo, zap.NewNop(), &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"tasks": {DefaultFilters: []config.DefaultFilter{{Field: "status", Op: "!=", Value: "archived"}}},
+		},
+	}, nil, nil, nil)
+	ctx := context.Background()
+	ids := func(docs []map[string]interface{}) []interface{} {
+		result := []interface{}{}
+		for _, doc := range docs {
+			result = append(result, doc["id"])
+		}
+		return result
+	}
+
+	// Archived tasks are hidden; tasks without a status are kept
+	docs, err := svc.List(ctx, "user1", "tasks", nil, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"c", "a"}, ids(docs))
+
+	// The limit counts matching documents only
+	docs, err = svc.List(ctx, "user1", "tasks", nil, DocumentListFilters{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"c"}, ids(docs))
+
+	docs, err = svc.List(ctx, "user1", "tasks", nil, DocumentListFilters{IncludeArchived: true}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"d", "c", "b", "a"}, ids(docs))
+
+	// A where on the same field replaces the default filter
+	docs, err = svc.List(ctx, "user1", "tasks", nil, DocumentListFilters{
+		Where: []interfaces.Filter{{Field: "status", Op: "==", Value: "archived"}},
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"d", "b"}, ids(docs))
+
+	// Collections without default filters list everything
+	repo.AddDocument("users/user1/notes/n", map[string]interface{}{"status": "archived", "createdAt": "1"})
+	docs, err = svc.List(ctx, "user1", "notes", nil, DocumentListFilters{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+}
+
+func TestStampPatchedDocument(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	doc := map[string]interface{}{"version": int64(3)}
+
+	stampPatchedDocument(doc, "user1", now)
+
+	assert.Equal(t, int64(4), doc["version"])
+	assert.Equal(t, "user1", doc["updatedBy"])
+	assert.Equal(t, now, doc["updatedAt"])
+}
+
+func TestDocumentService_PatchUnsupportedCollection(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil, nil)
+
+	_, err := svc.Patch(context.Background(), "user1", "usageStats", "x", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
+
+func TestGuardImmutableFields(t *testing.T) {
+	immutable := []string{"uid", "plaidTransactionId"}
+	ops := []PatchOperation{
+		{Op: "test", Path: "/plaidTransactionId", Value: "txn1"},
+		{Op: "replace", Path: "/category", Value: "Dining"},
+		{Op: "replace", Path: "/plaidTransactionId", Value: "forged"},
+		{Op: "remove", Path: "/uid"},
+	}
+
+	kept, dropped, err := guardImmutableFields(ops, immutable, false)
+	require.NoError(t, err)
+	assert.Equal(t, ops[:2], kept)
+	assert.Equal(t, []string{"plaidTransactionId", "uid"}, dropped)
+
+	_, _, err = guardImmutableFields(ops, immutable, true)
+	assert.ErrorIs(t, err, ErrImmutableField)
+	assert.Contains(t, err.Error(), "plaidTransactionId")
+
+	kept, dropped, err = guardImmutableFields(ops[:2], immutable, true)
+	require.NoError(t, err)
+	assert.Equal(t, ops[:2], kept)
+	assert.Empty(t, dropped)
+}
+
+func TestGuardImmutableFields_NestedPath(t *testing.T) {
+	ops := []PatchOperation{{Op: "add", Path: "/uid/nested", Value: "x"}}
+
+	_, _, err := guardImmutableFields(ops, []string{"uid"}, true)
+	assert.ErrorIs(t, err, ErrImmutableField)
+
+	kept, _, err := guardImmutableFields(ops, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, ops, kept)
+}
+
+func TestDocumentService_PatchStrictImmutableField(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), &config.DocumentsConfig{
+		StrictImmutableFields: true,
+		Collections: map[string]config.CollectionConfig{
+			"portfolios": {ImmutableFields: []string{"uid"}},
+		},
+	}, nil, nil, nil)
+
+	_, err := svc.Patch(context.Background(), "user1", "portfolios", "p1", []PatchOperation{
+		{Op: "replace", Path: "/uid", Value: "user2"},
+	})
+	assert.ErrorIs(t, err, ErrImmutableField)
+}
+
+func TestProjectDocument(t *testing.T) {
+	doc := map[string]interface{}{
+		"id":         "th1",
+		"text":       "Plan the offsite",
+		"aiMetadata": map[string]interface{}{"tokensUsed": 1200},
+	}
+
+	assert.Equal(t, map[string]interface{}{"id": "th1", "text": "Plan the offsite"},
+		ProjectDocument(doc, []string{"text", "missing"}))
+	assert.Equal(t, doc, ProjectDocument(doc, nil))
+}
+
+func TestDocumentService_ValidateFields(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"thoughts": {Fields: []string{"text", "tags"}},
+		},
+	}, nil, nil, nil)
+
+	assert.NoError(t, svc.ValidateFields("thoughts", []string{"id", "text"}))
+	assert.ErrorIs(t, svc.ValidateFields("thoughts", []string{"text", "bogus"}), ErrUnknownField)
+	// No schema configured: anything goes
+	assert.NoError(t, svc.ValidateFields("tasks", []string{"bogus"}))
+}
+
+func TestDiffDocuments(t *testing.T) {
+	before := map[string]interface{}{
+		"title":     "Run a marathon",
+		"progress":  int64(40),
+		"notes":     "Base training",
+		"version":   int64(3),
+		"updatedAt": "2024-01-01",
+	}
+	after := map[string]interface{}{
+		"title":     "Run a marathon",
+		"progress":  float64(55),
+		"status":    "active",
+		"version":   int64(4),
+		"updatedAt": "2024-01-02",
+	}
+
+	assert.Equal(t, map[string]FieldChange{
+		"progress": {From: int64(40), To: float64(55)},
+		"notes":    {From: "Base training"},
+		"status":   {To: "active"},
+	}, diffDocuments(before, after))
+}
+
+func TestDocumentService_HistoryGrowsAndIsCapped(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/goals/g1", map[string]interface{}{"id": "g1", "progress": 0})
+	svc := NewDocumentService(repo, zap.NewNop(), &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"goals": {SoftHistory: true, HistoryLimit: 3},
+		},
+	}, nil, nil, nil)
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	// Mirrors what Patch writes inside its transaction
+	doc := map[string]interface{}{"id": "g1", "progress": 0}
+	for i := 1; i <= 5; i++ {
+		updated := map[string]interface{}{"id": "g1", "progress": i * 10, "version": int64(i + 1)}
+		entry := buildHistoryEntry(doc, updated, "user1", start.Add(time.Duration(i)*time.Hour))
+		repo.AddDocument("users/user1/goals/g1/history/"+entry["id"].(string), entry)
+		svc.pruneHistory(ctx, "user1", "goals", "g1")
+		doc = updated
+
+		history, err := svc.History(ctx, "user1", "goals", "g1")
+		require.NoError(t, err)
+		assert.Len(t, history, min(i, 3))
+	}
+
+	history, err := svc.History(ctx, "user1", "goals", "g1")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	// Newest first; the two oldest entries were pruned
+	assert.Equal(t, int64(6), history[0]["version"])
+	assert.Equal(t, int64(4), history[2]["version"])
+	assert.Equal(t, FieldChange{From: 40, To: 50}, history[0]["changes"].(map[string]FieldChange)["progress"])
+	assert.Equal(t, "user1", history[0]["updatedBy"])
+}
+
+func TestDocumentService_HistoryDisabled(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"id": "t1"})
+	svc := NewDocumentService(repo, zap.NewNop(), nil, nil, nil, nil)
+
+	_, err := svc.History(context.Background(), "user1", "tasks", "t1")
+	assert.ErrorIs(t, err, ErrHistoryDisabled)
+}
+
+func TestDocumentService_Schema(t *testing.T) {
+	svc := NewDocumentService(mocks.NewMockRepository(), zap.NewNop(), &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"thoughts": {
+				DefaultPageSize: 50,
+				Fields:          []string{"id", "text", "tags", "attachments"},
+				ImmutableFields: []string{"attachments"},
+			},
+			"transactions": {ImmutableFields: []string{"uid"}},
+			"goals":        {SoftHistory: true},
+		},
+	}, nil, nil, nil)
+
+	schema, err := svc.Schema("thoughts")
+	require.NoError(t, err)
+	assert.Equal(t, "object", schema.Type)
+	assert.False(t, schema.AdditionalProperties)
+	assert.True(t, schema.Listable)
+	assert.Equal(t, 50, schema.DefaultPageSize)
+	assert.Equal(t, []string{"id", "text", "tags", "attachments", "createdAt", "updatedAt", "updatedBy", "version"}, schema.FieldOrder)
+	assert.Equal(t, FieldSchema{}, schema.Properties["text"])
+	assert.Equal(t, FieldSchema{ReadOnly: true, ServerManaged: true}, schema.Properties["id"])
+	assert.Equal(t, FieldSchema{ReadOnly: true, Immutable: true}, schema.Properties["attachments"])
+
+	schema, err = svc.Schema("transactions")
+	require.NoError(t, err)
+	assert.True(t, schema.AdditionalProperties)
+	assert.False(t, schema.Listable)
+	assert.False(t, schema.Duplicable)
+	assert.True(t, schema.Properties["uid"].Immutable)
+
+	schema, err = svc.Schema("goals")
+	require.NoError(t, err)
+	assert.True(t, schema.History)
+
+	_, err = svc.Schema("subscriptions")
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
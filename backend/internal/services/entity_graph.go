@@ -3,24 +3,37 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
 
+// DefaultMaxRelationshipsPerEntity is the cap on active relationships from
+// one source entity when config sets none
+const DefaultMaxRelationshipsPerEntity = 50
+
 // EntityGraphService handles entity graph operations
 type EntityGraphService struct {
-	repo   interfaces.Repository
-	logger *zap.Logger
+	repo                      interfaces.Repository
+	logger                    *zap.Logger
+	maxRelationshipsPerEntity int
 }
 
-// NewEntityGraphService creates a new entity graph service
-func NewEntityGraphService(repo interfaces.Repository, logger *zap.Logger) *EntityGraphService {
+// NewEntityGraphService creates a new entity graph service.
+// maxRelationshipsPerEntity <= 0 uses DefaultMaxRelationshipsPerEntity.
+func NewEntityGraphService(repo interfaces.Repository, logger *zap.Logger, maxRelationshipsPerEntity int) *EntityGraphService {
+	if maxRelationshipsPerEntity <= 0 {
+		maxRelationshipsPerEntity = DefaultMaxRelationshipsPerEntity
+	}
 	return &EntityGraphService{
-		repo:   repo,
-		logger: logger,
+		repo:                      repo,
+		logger:                    logger,
+		maxRelationshipsPerEntity: maxRelationshipsPerEntity,
 	}
 }
 
@@ -63,6 +76,39 @@ const (
 	RelationshipTypeToolProcessed  RelationshipType = "tool-processed"
 )
 
+// knownRelationshipTypes is the current relationship type enum
+var knownRelationshipTypes = map[RelationshipType]bool{
+	RelationshipTypeCreatedFrom:    true,
+	RelationshipTypeLinkedTo:       true,
+	RelationshipTypeRelatedTo:      true,
+	RelationshipTypeDependsOn:      true,
+	RelationshipTypeToolProcessing: true,
+	RelationshipTypeToolPending:    true,
+	RelationshipTypeToolProcessed:  true,
+}
+
+// legacyRelationshipTypes maps relationship types written before the enum was
+// settled, after kebab-casing, to their current values
+var legacyRelationshipTypes = map[string]RelationshipType{
+	"created":    RelationshipTypeCreatedFrom,
+	"linked":     RelationshipTypeLinkedTo,
+	"link":       RelationshipTypeLinkedTo,
+	"related":    RelationshipTypeRelatedTo,
+	"depends":    RelationshipTypeDependsOn,
+	"dependency": RelationshipTypeDependsOn,
+	"processing": RelationshipTypeToolProcessing,
+	"pending":    RelationshipTypeToolPending,
+	"processed":  RelationshipTypeToolProcessed,
+}
+
+// Defaults filled in by BackfillRelationships for fields missing on
+// relationships created before they were required
+const (
+	DefaultRelationshipStrength  = 50
+	DefaultRelationshipCreatedBy = "user"
+	DefaultRelationshipStatus    = "active"
+)
+
 // RelationshipFilters represents filters for querying relationships
 type RelationshipFilters struct {
 	SourceType       *EntityType       `json:"sourceType,omitempty"`
@@ -120,6 +166,12 @@ type RelationshipStats struct {
 	ToolUsage          map[string]ToolUsageStat `json:"toolUsage"`
 }
 
+// BackfillResult reports the outcome of a relationship backfill
+type BackfillResult struct {
+	Scanned int `json:"scanned"`
+	Updated int `json:"updated"`
+}
+
 // ToolUsageStat represents usage statistics for a specific tool
 type ToolUsageStat struct {
 	ProcessedCount int       `json:"processedCount"`
@@ -489,6 +541,99 @@ func (s *EntityGraphService) GetRelationshipStats(ctx context.Context, uid strin
 	return stats, nil
 }
 
+// BackfillRelationships fills in defaults for a user's relationships that are
+// missing strength, createdBy or status, and rewrites legacy relationship
+// types to the current enum. Only changed documents are written, so running
+// it again updates nothing.
+func (s *EntityGraphService) BackfillRelationships(ctx context.Context, uid string) (*BackfillResult, error) {
+	relationships, err := s.repo.ListWhere(ctx, "entityRelationships", []repository.Filter{
+		{Field: "uid", Op: "==", Value: uid},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	result := &BackfillResult{}
+	for _, relationship := range relationships {
+		id, _ := relationship["id"].(string)
+		if id == "" {
+			continue
+		}
+		result.Scanned++
+
+		updates := relationshipBackfillUpdates(relationship)
+		if len(updates) == 0 {
+			continue
+		}
+		updates["updatedAt"] = time.Now()
+		if err := s.repo.Update(ctx, "entityRelationships/"+id, updates); err != nil {
+			return result, fmt.Errorf("failed to backfill relationship %s: %w", id, err)
+		}
+		result.Updated++
+	}
+
+	s.logger.Info("Relationships backfilled",
+		zap.String("uid", uid),
+		zap.Int("scanned", result.Scanned),
+		zap.Int("updated", result.Updated),
+	)
+	return result, nil
+}
+
+// relationshipBackfillUpdates returns the fields a relationship needs changed
+// to match the current schema; empty when it is already up to date
+func relationshipBackfillUpdates(relationship map[string]interface{}) map[string]interface{} {
+	updates := map[string]interface{}{}
+
+	switch relationship["strength"].(type) {
+	case float64, int64, int:
+	default:
+		updates["strength"] = DefaultRelationshipStrength
+	}
+	if createdBy, _ := relationship["createdBy"].(string); createdBy == "" {
+		updates["createdBy"] = DefaultRelationshipCreatedBy
+	}
+	if status, _ := relationship["status"].(string); status == "" {
+		updates["status"] = DefaultRelationshipStatus
+	}
+	if relType, ok := relationship["relationshipType"].(string); ok {
+		if normalized, ok := normalizeRelationshipType(relType); ok && string(normalized) != relType {
+			updates["relationshipType"] = string(normalized)
+		}
+	}
+
+	return updates
+}
+
+// normalizeRelationshipType maps a relationship type in any legacy spelling
+// (snake_case, camelCase, short aliases) to the current enum. ok is false for
+// types that cannot be mapped, which are left untouched.
+func normalizeRelationshipType(relType string) (RelationshipType, bool) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(relType) {
+		switch {
+		case r == '_' || r == ' ':
+			b.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	kebab := strings.Join(strings.FieldsFunc(b.String(), func(r rune) bool { return r == '-' }), "-")
+
+	if knownRelationshipTypes[RelationshipType(kebab)] {
+		return RelationshipType(kebab), true
+	}
+	if mapped, ok := legacyRelationshipTypes[kebab]; ok {
+		return mapped, true
+	}
+	return "", false
+}
+
 // Helper methods
 func (s *EntityGraphService) getStringFromMap(m map[string]interface{}, key string, defaultVal string) string {
 	if val, ok := m[key]; ok {
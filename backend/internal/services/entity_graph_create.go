@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+// ErrInvalidRelationship is returned for relationships missing their source or target
+var ErrInvalidRelationship = errors.New("invalid relationship")
+
+// SkippedRelationship is an item of a relationship batch that was not
+// created because it is invalid
+type SkippedRelationship struct {
+	Index int   // position of the item in the batch
+	Err   error // wraps ErrInvalidRelationship
+}
+
+// CreateRelationship stores a new relationship for the user, archiving the
+// weakest active relationships from its source entity beyond the cap
+func (s *EntityGraphService) CreateRelationship(ctx context.Context, uid string, data map[string]interface{}) (map[string]interface{}, error) {
+	created, skipped, err := s.CreateRelationships(ctx, uid, []map[string]interface{}{data})
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		return nil, skipped[0].Err
+	}
+	return created[0], nil
+}
+
+// CreateRelationships stores new relationships for the user. Items missing
+// their source or target are skipped, logged and returned as skipped; the
+// rest are created. strength, status and createdBy default as in
+// BackfillRelationships. Once written, each source entity is held to the
+// maximum active relationships: the weakest are archived, by strength and then
+// age, with the new relationships only archived if the batch alone exceeds it.
+// The cap is applied after the relationships are written and outside a
+// transaction, so concurrent creates for one source can leave it over the cap
+// until its next create.
+func (s *EntityGraphService) CreateRelationships(ctx context.Context, uid string, items []map[string]interface{}) ([]map[string]interface{}, []SkippedRelationship, error) {
+	now := time.Now()
+	created := make([]map[string]interface{}, 0, len(items))
+	var skipped []SkippedRelationship
+	for i, data := range items {
+		relationship, err := newRelationship(uid, data, now)
+		if err != nil {
+			s.logger.Warn("Skipping invalid relationship",
+				zap.String("uid", uid),
+				zap.Int("index", i),
+				zap.Error(err),
+			)
+			skipped = append(skipped, SkippedRelationship{Index: i, Err: err})
+			continue
+		}
+		created = append(created, relationship)
+	}
+
+	type source struct{ entityType, id string }
+	var sources []source
+	fresh := make(map[string]bool, len(created))
+	seen := make(map[source]bool)
+	for _, relationship := range created {
+		id := relationship["id"].(string)
+		if err := s.repo.Create(ctx, "entityRelationships/"+id, relationship); err != nil {
+			return nil, skipped, fmt.Errorf("failed to create relationship: %w", err)
+		}
+		fresh[id] = true
+
+		src := source{relationship["sourceType"].(string), relationship["sourceId"].(string)}
+		if !seen[src] {
+			seen[src] = true
+			sources = append(sources, src)
+		}
+	}
+
+	archived := 0
+	for _, src := range sources {
+		n, err := s.enforceRelationshipCap(ctx, uid, src.entityType, src.id, fresh)
+		if err != nil {
+			return nil, skipped, err
+		}
+		archived += n
+	}
+
+	s.logger.Info("Relationships created",
+		zap.String("uid", uid),
+		zap.Int("created", len(created)),
+		zap.Int("skipped", len(skipped)),
+		zap.Int("archived", archived),
+	)
+	return created, skipped, nil
+}
+
+// newRelationship builds a relationship document from caller data
+func newRelationship(uid string, data map[string]interface{}, now time.Time) (map[string]interface{}, error) {
+	relationship := map[string]interface{}{
+		"uid":       uid,
+		"strength":  DefaultRelationshipStrength,
+		"status":    DefaultRelationshipStatus,
+		"createdBy": DefaultRelationshipCreatedBy,
+	}
+	for _, field := range []string{"sourceType", "sourceId", "targetType", "targetId"} {
+		value := getStringField(data, field)
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidRelationship, field)
+		}
+		relationship[field] = value
+	}
+	if relType := getStringField(data, "relationshipType"); relType != "" {
+		if normalized, ok := normalizeRelationshipType(relType); ok {
+			relType = string(normalized)
+		}
+		relationship["relationshipType"] = relType
+	}
+	if reasoning := getStringField(data, "reasoning"); reasoning != "" {
+		relationship["reasoning"] = reasoning
+	}
+	if createdBy := getStringField(data, "createdBy"); createdBy != "" {
+		relationship["createdBy"] = createdBy
+	}
+	if strength, ok := toFloat(data["strength"]); ok {
+		relationship["strength"] = strength
+	}
+
+	relationship["id"] = uuid.New().String()
+	relationship["createdAt"] = now
+	relationship["updatedAt"] = now
+	return relationship, nil
+}
+
+// enforceRelationshipCap archives the weakest active relationships from a
+// source entity beyond maxRelationshipsPerEntity, returning how many it
+// archived. Relationships in fresh were just created and are archived last.
+func (s *EntityGraphService) enforceRelationshipCap(ctx context.Context, uid, sourceType, sourceID string, fresh map[string]bool) (int, error) {
+	active, err := s.repo.ListWhere(ctx, "entityRelationships", []repository.Filter{
+		{Field: "uid", Op: "==", Value: uid},
+		{Field: "sourceType", Op: "==", Value: sourceType},
+		{Field: "sourceId", Op: "==", Value: sourceID},
+		{Field: "status", Op: "==", Value: DefaultRelationshipStatus},
+	}, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list relationships: %w", err)
+	}
+	excess := len(active) - s.maxRelationshipsPerEntity
+	if excess <= 0 {
+		return 0, nil
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		a, b := active[i], active[j]
+		if freshA, freshB := fresh[getStringField(a, "id")], fresh[getStringField(b, "id")]; freshA != freshB {
+			return freshB
+		}
+		strengthA := s.getFloatFromMap(a, "strength", DefaultRelationshipStrength)
+		strengthB := s.getFloatFromMap(b, "strength", DefaultRelationshipStrength)
+		if strengthA != strengthB {
+			return strengthA < strengthB
+		}
+		createdA, _ := parseFlexibleDate(a["createdAt"])
+		createdB, _ := parseFlexibleDate(b["createdAt"])
+		if !createdA.Equal(createdB) {
+			return createdA.Before(createdB)
+		}
+		return getStringField(a, "id") < getStringField(b, "id")
+	})
+
+	now := time.Now()
+	for _, relationship := range active[:excess] {
+		id := getStringField(relationship, "id")
+		updates := map[string]interface{}{
+			"status":     "archived",
+			"archivedAt": now,
+			"updatedAt":  now,
+		}
+		if err := s.repo.Update(ctx, "entityRelationships/"+id, updates); err != nil {
+			return 0, fmt.Errorf("failed to archive relationship %s: %w", id, err)
+		}
+		s.logger.Debug("Relationship archived over cap",
+			zap.String("uid", uid),
+			zap.String("relationshipId", id),
+			zap.String("sourceType", sourceType),
+			zap.String("sourceId", sourceID),
+		)
+	}
+	return excess, nil
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -12,7 +14,7 @@ import (
 func TestEntityGraphService_QueryRelationships(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewEntityGraphService(mockRepo, logger)
+	service := NewEntityGraphService(mockRepo, logger, 0)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -132,7 +134,7 @@ func TestEntityGraphService_QueryRelationships(t *testing.T) {
 func TestEntityGraphService_GetLinkedEntities(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewEntityGraphService(mockRepo, logger)
+	service := NewEntityGraphService(mockRepo, logger, 0)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -207,7 +209,7 @@ func TestEntityGraphService_GetLinkedEntities(t *testing.T) {
 func TestEntityGraphService_GetToolRelationships(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewEntityGraphService(mockRepo, logger)
+	service := NewEntityGraphService(mockRepo, logger, 0)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -260,7 +262,7 @@ func TestEntityGraphService_GetToolRelationships(t *testing.T) {
 func TestEntityGraphService_GetRelationshipStats(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewEntityGraphService(mockRepo, logger)
+	service := NewEntityGraphService(mockRepo, logger, 0)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -343,3 +345,313 @@ func TestEntityGraphService_GetRelationshipStats(t *testing.T) {
 		t.Errorf("Expected average strength ~%f, got %f", expectedAvgStrength, stats.AverageStrength)
 	}
 }
+
+func TestEntityGraphService_BackfillRelationships(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	logger := zap.NewNop()
+	service := NewEntityGraphService(mockRepo, logger, 0)
+
+	uid := "test-user-123"
+	ctx := context.Background()
+
+	// Legacy relationship missing strength, createdBy and status
+	mockRepo.AddDocument("entityRelationships/rel1", map[string]interface{}{
+		"id":               "rel1",
+		"uid":              uid,
+		"sourceType":       "task",
+		"targetType":       "project",
+		"relationshipType": "created_from",
+	})
+
+	// Legacy camelCase type, otherwise complete
+	mockRepo.AddDocument("entityRelationships/rel2", map[string]interface{}{
+		"id":               "rel2",
+		"uid":              uid,
+		"relationshipType": "dependsOn",
+		"strength":         70.0,
+		"status":           "archived",
+		"createdBy":        "ai",
+	})
+
+	// Short alias and a stringly-typed strength
+	mockRepo.AddDocument("entityRelationships/rel3", map[string]interface{}{
+		"id":               "rel3",
+		"uid":              uid,
+		"relationshipType": "related",
+		"strength":         "high",
+		"status":           "active",
+		"createdBy":        "ai",
+	})
+
+	// Already current
+	mockRepo.AddDocument("entityRelationships/rel4", map[string]interface{}{
+		"id":               "rel4",
+		"uid":              uid,
+		"relationshipType": "linked-to",
+		"strength":         85.0,
+		"status":           "active",
+		"createdBy":        "user",
+	})
+
+	// Unknown type is left as is
+	mockRepo.AddDocument("entityRelationships/rel5", map[string]interface{}{
+		"id":               "rel5",
+		"uid":              uid,
+		"relationshipType": "inspired-by",
+		"strength":         60.0,
+		"status":           "active",
+		"createdBy":        "user",
+	})
+
+	// Another user's legacy relationship is not touched
+	mockRepo.AddDocument("entityRelationships/rel6", map[string]interface{}{
+		"id":               "rel6",
+		"uid":              "other-user",
+		"relationshipType": "linked",
+	})
+
+	result, err := service.BackfillRelationships(ctx, uid)
+	if err != nil {
+		t.Fatalf("BackfillRelationships() error = %v", err)
+	}
+	if result.Scanned != 5 {
+		t.Errorf("Expected 5 scanned relationships, got %d", result.Scanned)
+	}
+	if result.Updated != 3 {
+		t.Errorf("Expected 3 updated relationships, got %d", result.Updated)
+	}
+
+	rel1 := mockRepo.Documents["entityRelationships/rel1"]
+	if rel1["relationshipType"] != "created-from" {
+		t.Errorf("Expected rel1 type 'created-from', got %v", rel1["relationshipType"])
+	}
+	if rel1["strength"] != DefaultRelationshipStrength {
+		t.Errorf("Expected rel1 strength %d, got %v", DefaultRelationshipStrength, rel1["strength"])
+	}
+	if rel1["createdBy"] != "user" {
+		t.Errorf("Expected rel1 createdBy 'user', got %v", rel1["createdBy"])
+	}
+	if rel1["status"] != "active" {
+		t.Errorf("Expected rel1 status 'active', got %v", rel1["status"])
+	}
+
+	rel2 := mockRepo.Documents["entityRelationships/rel2"]
+	if rel2["relationshipType"] != "depends-on" {
+		t.Errorf("Expected rel2 type 'depends-on', got %v", rel2["relationshipType"])
+	}
+	if rel2["status"] != "archived" || rel2["createdBy"] != "ai" {
+		t.Errorf("Expected rel2 status and createdBy unchanged, got %v/%v", rel2["status"], rel2["createdBy"])
+	}
+
+	rel3 := mockRepo.Documents["entityRelationships/rel3"]
+	if rel3["relationshipType"] != "related-to" || rel3["strength"] != DefaultRelationshipStrength {
+		t.Errorf("Expected rel3 normalized, got type %v strength %v", rel3["relationshipType"], rel3["strength"])
+	}
+
+	if _, ok := mockRepo.Documents["entityRelationships/rel4"]["updatedAt"]; ok {
+		t.Error("Expected current relationship rel4 not to be rewritten")
+	}
+	if mockRepo.Documents["entityRelationships/rel5"]["relationshipType"] != "inspired-by" {
+		t.Error("Expected unknown relationship type to be left untouched")
+	}
+	if mockRepo.Documents["entityRelationships/rel6"]["relationshipType"] != "linked" {
+		t.Error("Expected other user's relationship to be left untouched")
+	}
+
+	// Running again is a no-op
+	result, err = service.BackfillRelationships(ctx, uid)
+	if err != nil {
+		t.Fatalf("second BackfillRelationships() error = %v", err)
+	}
+	if result.Updated != 0 {
+		t.Errorf("Expected second backfill to update 0 relationships, got %d", result.Updated)
+	}
+
+	stats, err := service.GetRelationshipStats(ctx, uid)
+	if err != nil {
+		t.Fatalf("GetRelationshipStats() error = %v", err)
+	}
+	if stats.ByCreator["unknown"] != 0 || stats.ByStatus["unknown"] != 0 {
+		t.Errorf("Expected no unknown creators or statuses after backfill, got %v / %v", stats.ByCreator, stats.ByStatus)
+	}
+}
+
+func TestNormalizeRelationshipType(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   RelationshipType
+		wantOK bool
+	}{
+		{"linked-to", RelationshipTypeLinkedTo, true},
+		{"linked_to", RelationshipTypeLinkedTo, true},
+		{"LinkedTo", RelationshipTypeLinkedTo, true},
+		{"Related To", RelationshipTypeRelatedTo, true},
+		{"tool_processed", RelationshipTypeToolProcessed, true},
+		{"pending", RelationshipTypeToolPending, true},
+		{"inspired-by", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := normalizeRelationshipType(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("normalizeRelationshipType(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestEntityGraphService_CreateRelationship_EvictsWeakestOverCap(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewEntityGraphService(mockRepo, zap.NewNop(), 3)
+
+	uid := "test-user-123"
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	existing := []struct {
+		id       string
+		strength float64
+		age      time.Duration
+	}{
+		{"strong", 90, 3 * time.Hour},
+		{"weak-new", 20, 1 * time.Hour},
+		{"weak-old", 20, 2 * time.Hour},
+	}
+	for _, rel := range existing {
+		mockRepo.AddDocument("entityRelationships/"+rel.id, map[string]interface{}{
+			"id":         rel.id,
+			"uid":        uid,
+			"sourceType": "thought",
+			"sourceId":   "thought1",
+			"targetType": "task",
+			"targetId":   "task-" + rel.id,
+			"strength":   rel.strength,
+			"status":     "active",
+			"createdAt":  base.Add(-rel.age),
+		})
+	}
+	// Archived and other-source relationships do not count towards the cap
+	mockRepo.AddDocument("entityRelationships/archived", map[string]interface{}{
+		"id": "archived", "uid": uid, "sourceType": "thought", "sourceId": "thought1",
+		"strength": 10.0, "status": "archived",
+	})
+	mockRepo.AddDocument("entityRelationships/other", map[string]interface{}{
+		"id": "other", "uid": uid, "sourceType": "thought", "sourceId": "thought2",
+		"strength": 10.0, "status": "active",
+	})
+
+	created, err := service.CreateRelationship(ctx, uid, map[string]interface{}{
+		"sourceType":       "thought",
+		"sourceId":         "thought1",
+		"targetType":       "project",
+		"targetId":         "proj1",
+		"relationshipType": "linked_to",
+		"strength":         10.0,
+	})
+	if err != nil {
+		t.Fatalf("CreateRelationship() error = %v", err)
+	}
+	if created["status"] != "active" || created["relationshipType"] != "linked-to" {
+		t.Errorf("Expected active linked-to relationship, got %v / %v", created["status"], created["relationshipType"])
+	}
+
+	// The new edge is the weakest but is kept; of the two weak existing
+	// edges, the older one is archived
+	statuses := map[string]interface{}{
+		"strong":   "active",
+		"weak-new": "active",
+		"weak-old": "archived",
+		"archived": "archived",
+		"other":    "active",
+	}
+	for id, want := range statuses {
+		if got := mockRepo.Documents["entityRelationships/"+id]["status"]; got != want {
+			t.Errorf("Expected %s to be %v, got %v", id, want, got)
+		}
+	}
+	if got := mockRepo.Documents["entityRelationships/"+created["id"].(string)]["status"]; got != "active" {
+		t.Errorf("Expected new relationship to stay active, got %v", got)
+	}
+}
+
+func TestEntityGraphService_CreateRelationships_BatchBeyondCap(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewEntityGraphService(mockRepo, zap.NewNop(), 2)
+
+	uid := "test-user-123"
+	ctx := context.Background()
+
+	mockRepo.AddDocument("entityRelationships/existing", map[string]interface{}{
+		"id": "existing", "uid": uid, "sourceType": "thought", "sourceId": "thought1",
+		"strength": 100.0, "status": "active",
+	})
+
+	items := []map[string]interface{}{
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "task", "targetId": "t1", "strength": 70.0},
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "task", "targetId": "t2", "strength": 30.0},
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "task", "targetId": "t3", "strength": 50.0},
+	}
+	created, skipped, err := service.CreateRelationships(ctx, uid, items)
+	if err != nil {
+		t.Fatalf("CreateRelationships() error = %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("Expected no skipped relationships, got %v", skipped)
+	}
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 relationships created, got %d", len(created))
+	}
+
+	// Existing edges are evicted first, then the weakest of the batch
+	if got := mockRepo.Documents["entityRelationships/existing"]["status"]; got != "archived" {
+		t.Errorf("Expected existing relationship to be archived, got %v", got)
+	}
+	wantStatus := map[string]string{"t1": "active", "t2": "archived", "t3": "active"}
+	for _, rel := range created {
+		doc := mockRepo.Documents["entityRelationships/"+rel["id"].(string)]
+		if got, want := doc["status"], wantStatus[doc["targetId"].(string)]; got != want {
+			t.Errorf("Expected relationship to %v to be %s, got %v", doc["targetId"], want, got)
+		}
+	}
+}
+
+func TestEntityGraphService_CreateRelationships_SkipsInvalidItems(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewEntityGraphService(mockRepo, zap.NewNop(), 0)
+
+	items := []map[string]interface{}{
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "task", "targetId": "t1"},
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "task"},
+		{"sourceType": "thought", "sourceId": "thought1", "targetType": "goal", "targetId": "g1"},
+	}
+	created, skipped, err := service.CreateRelationships(context.Background(), "test-user-123", items)
+	if err != nil {
+		t.Fatalf("CreateRelationships() error = %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("Expected 2 relationships created, got %d", len(created))
+	}
+	if len(skipped) != 1 || skipped[0].Index != 1 || !errors.Is(skipped[0].Err, ErrInvalidRelationship) {
+		t.Errorf("Expected item 1 skipped as invalid, got %+v", skipped)
+	}
+	if len(mockRepo.Documents) != 2 {
+		t.Errorf("Expected 2 documents written, got %d", len(mockRepo.Documents))
+	}
+}
+
+func TestEntityGraphService_CreateRelationship_RequiresSourceAndTarget(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewEntityGraphService(mockRepo, zap.NewNop(), 0)
+
+	_, err := service.CreateRelationship(context.Background(), "test-user-123", map[string]interface{}{
+		"sourceType": "thought",
+		"sourceId":   "thought1",
+		"targetType": "task",
+	})
+	if !errors.Is(err, ErrInvalidRelationship) {
+		t.Errorf("Expected ErrInvalidRelationship, got %v", err)
+	}
+	if len(mockRepo.Documents) != 0 {
+		t.Errorf("Expected nothing written, got %d documents", len(mockRepo.Documents))
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// estimationAccuracyTolerance is how far actual may deviate from the estimate
+// (as a fraction) and still count as accurate
+const estimationAccuracyTolerance = 0.2
+
+// TaskEstimation compares a completed task's estimate with the time actually spent
+type TaskEstimation struct {
+	TaskID           string  `json:"taskId"`
+	Title            string  `json:"title"`
+	EstimatedMinutes float64 `json:"estimatedMinutes"`
+	ActualMinutes    float64 `json:"actualMinutes"`
+	Ratio            float64 `json:"ratio"` // actual / estimated; > 1 means underestimated
+}
+
+// EstimationAnalytics summarizes estimation accuracy across completed tasks
+type EstimationAnalytics struct {
+	Tasks                 []TaskEstimation `json:"tasks"`
+	TaskCount             int              `json:"taskCount"`
+	TotalEstimatedMinutes float64          `json:"totalEstimatedMinutes"`
+	TotalActualMinutes    float64          `json:"totalActualMinutes"`
+	OverallRatio          float64          `json:"overallRatio"`
+	Underestimated        int              `json:"underestimated"`
+	Overestimated         int              `json:"overestimated"`
+	Accurate              int              `json:"accurate"`
+	// CalibrationScore is 100 * exp(-mean |ln ratio|): 100 for perfect estimates,
+	// 50 when estimates are off by a factor of 2 on average
+	CalibrationScore float64 `json:"calibrationScore"`
+}
+
+// ComputeEstimation compares estimatedMinutes on completed tasks with the time
+// recorded for them in focus sessions
+func (s *DashboardAnalyticsService) ComputeEstimation(ctx context.Context, uid string) (*EstimationAnalytics, error) {
+	var tasks, sessions []map[string]interface{}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		tasks, err = s.repo.List(gctx, fmt.Sprintf("users/%s/tasks", uid), 0)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		sessions, err = s.repo.List(gctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := s.computeEstimation(tasks, sessions)
+
+	s.logger.Info("Estimation analytics computed",
+		zap.String("uid", uid),
+		zap.Int("tasks", result.TaskCount),
+	)
+
+	return result, nil
+}
+
+// computeEstimation builds estimation analytics from tasks and sessions
+func (s *DashboardAnalyticsService) computeEstimation(tasks, sessions []map[string]interface{}) *EstimationAnalytics {
+	// Seconds spent per task across all sessions
+	secondsByTask := make(map[string]int)
+	for _, session := range sessions {
+		sessionTasks, ok := session["tasks"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, task := range sessionTasks {
+			if taskMap, ok := task.(map[string]interface{}); ok {
+				if id := s.sessionTaskID(taskMap); id != "" {
+					secondsByTask[id] += s.sessionTaskTime(taskMap)
+				}
+			}
+		}
+	}
+
+	result := &EstimationAnalytics{Tasks: []TaskEstimation{}}
+	totalAbsLogError := 0.0
+
+	for _, task := range tasks {
+		if !s.isTaskCompleted(task) {
+			continue
+		}
+		estimated := 0.0
+		switch v := task["estimatedMinutes"].(type) {
+		case float64:
+			estimated = v
+		case int64:
+			estimated = float64(v)
+		}
+		id, _ := task["id"].(string)
+		actual := float64(secondsByTask[id]) / 60
+		if estimated <= 0 || actual <= 0 {
+			continue
+		}
+
+		title, _ := task["title"].(string)
+		ratio := actual / estimated
+		result.Tasks = append(result.Tasks, TaskEstimation{
+			TaskID:           id,
+			Title:            title,
+			EstimatedMinutes: estimated,
+			ActualMinutes:    math.Round(actual*10) / 10,
+			Ratio:            math.Round(ratio*100) / 100,
+		})
+
+		result.TotalEstimatedMinutes += estimated
+		result.TotalActualMinutes += actual
+		totalAbsLogError += math.Abs(math.Log(ratio))
+
+		switch {
+		case ratio > 1+estimationAccuracyTolerance:
+			result.Underestimated++
+		case ratio < 1-estimationAccuracyTolerance:
+			result.Overestimated++
+		default:
+			result.Accurate++
+		}
+	}
+
+	result.TaskCount = len(result.Tasks)
+	if result.TaskCount == 0 {
+		return result
+	}
+
+	// Worst estimates first
+	sort.Slice(result.Tasks, func(i, j int) bool {
+		return math.Abs(math.Log(result.Tasks[i].Ratio)) > math.Abs(math.Log(result.Tasks[j].Ratio))
+	})
+
+	result.TotalActualMinutes = math.Round(result.TotalActualMinutes*10) / 10
+	result.OverallRatio = math.Round(result.TotalActualMinutes/result.TotalEstimatedMinutes*100) / 100
+	result.CalibrationScore = math.Round(100 * math.Exp(-totalAbsLogError/float64(result.TaskCount)))
+
+	return result
+}
+
+// isTaskCompleted reports whether a task is marked done or completed
+func (s *DashboardAnalyticsService) isTaskCompleted(task map[string]interface{}) bool {
+	if done, ok := task["done"].(bool); ok && done {
+		return true
+	}
+	status, _ := task["status"].(string)
+	return status == "completed"
+}
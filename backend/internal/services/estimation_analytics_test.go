@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func focusSessionWith(entries ...map[string]interface{}) map[string]interface{} {
+	tasks := make([]interface{}, len(entries))
+	for i, e := range entries {
+		tasks[i] = e
+	}
+	return map[string]interface{}{"tasks": tasks}
+}
+
+func sessionTask(id string, seconds int64) map[string]interface{} {
+	return map[string]interface{}{
+		"task":      map[string]interface{}{"id": id},
+		"timeSpent": seconds,
+	}
+}
+
+func TestDashboardAnalyticsService_ComputeEstimation(t *testing.T) {
+	svc := NewDashboardAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil)
+
+	tasks := []map[string]interface{}{
+		{"id": "under", "title": "Underestimated", "done": true, "estimatedMinutes": float64(30)},
+		{"id": "over", "title": "Overestimated", "status": "completed", "estimatedMinutes": int64(60)},
+		{"id": "exact", "done": true, "estimatedMinutes": float64(20)},
+		{"id": "open", "done": false, "estimatedMinutes": float64(10)},
+		{"id": "no-estimate", "done": true},
+		{"id": "no-sessions", "done": true, "estimatedMinutes": float64(15)},
+	}
+	sessions := []map[string]interface{}{
+		focusSessionWith(sessionTask("under", 1800), sessionTask("exact", 1200)),
+		focusSessionWith(sessionTask("under", 1800), sessionTask("over", 1800), sessionTask("open", 600)),
+		{"tasks": "malformed"},
+	}
+
+	result := svc.computeEstimation(tasks, sessions)
+
+	require.Equal(t, 3, result.TaskCount)
+	assert.Equal(t, 1, result.Underestimated)
+	assert.Equal(t, 1, result.Overestimated)
+	assert.Equal(t, 1, result.Accurate)
+	assert.Equal(t, 110.0, result.TotalEstimatedMinutes)
+	assert.Equal(t, 110.0, result.TotalActualMinutes)
+	assert.Equal(t, 1.0, result.OverallRatio)
+
+	byID := make(map[string]TaskEstimation)
+	for _, task := range result.Tasks {
+		byID[task.TaskID] = task
+	}
+	assert.Equal(t, 2.0, byID["under"].Ratio)
+	assert.Equal(t, 0.5, byID["over"].Ratio)
+	assert.Equal(t, 1.0, byID["exact"].Ratio)
+
+	// mean |ln ratio| = (ln2 + ln2 + 0) / 3
+	want := math.Round(100 * math.Exp(-2*math.Ln2/3))
+	assert.Equal(t, want, result.CalibrationScore)
+}
+
+func TestDashboardAnalyticsService_ComputeEstimation_Empty(t *testing.T) {
+	svc := NewDashboardAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil)
+
+	result, err := svc.ComputeEstimation(context.Background(), "user1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.TaskCount)
+	assert.NotNil(t, result.Tasks)
+	assert.Equal(t, 0.0, result.CalibrationScore)
+}
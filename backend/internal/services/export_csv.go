@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// EncodeDocumentsCSV writes documents as CSV with one column per top-level
+// field, id first and the rest sorted. Times are written as RFC 3339 and
+// nested maps and arrays as JSON.
+func EncodeDocumentsCSV(w io.Writer, docs []map[string]interface{}) error {
+	fieldSet := map[string]bool{}
+	for _, doc := range docs {
+		for field := range doc {
+			fieldSet[field] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		if field != "id" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	if fieldSet["id"] || len(fields) == 0 {
+		fields = append([]string{"id"}, fields...)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fields); err != nil {
+		return err
+	}
+	row := make([]string, len(fields))
+	for _, doc := range docs {
+		for i, field := range fields {
+			value, err := csvValue(doc[field])
+			if err != nil {
+				return fmt.Errorf("failed to encode field %s: %w", field, err)
+			}
+			row[i] = value
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// FeatureBanking gates the Plaid banking endpoints
+	FeatureBanking = "banking"
+	// FeaturePhotoBattles gates the photo battle endpoints
+	FeaturePhotoBattles = "photo_battles"
+
+	// globalFeatureFlagsPath holds flag values that apply to every user
+	globalFeatureFlagsPath = "featureFlags/global"
+
+	defaultFeatureFlagCacheTTL = time.Minute
+)
+
+// FeatureFlagService resolves feature flags for a user.
+// Precedence (highest first): per-user override, global flag document, config default.
+type FeatureFlagService struct {
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	defaults map[string]bool
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedFeatureFlags
+}
+
+type cachedFeatureFlags struct {
+	flags     map[string]bool
+	expiresAt time.Time
+}
+
+// NewFeatureFlagService creates a new feature flag service.
+// cacheTTL <= 0 falls back to defaultFeatureFlagCacheTTL.
+func NewFeatureFlagService(repo interfaces.Repository, logger *zap.Logger, defaults map[string]bool, cacheTTL time.Duration) *FeatureFlagService {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultFeatureFlagCacheTTL
+	}
+	return &FeatureFlagService{
+		repo:     repo,
+		logger:   logger,
+		defaults: defaults,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    make(map[string]cachedFeatureFlags),
+	}
+}
+
+// Resolve returns every known flag resolved for the user
+func (s *FeatureFlagService) Resolve(ctx context.Context, uid string) map[string]bool {
+	now := s.now()
+
+	s.mu.Lock()
+	if cached, ok := s.cache[uid]; ok && now.Before(cached.expiresAt) {
+		s.mu.Unlock()
+		return copyFlags(cached.flags)
+	}
+	s.mu.Unlock()
+
+	global, globalErr := s.loadFlags(ctx, globalFeatureFlagsPath)
+	user, userErr := s.loadFlags(ctx, fmt.Sprintf("users/%s/featureFlags/overrides", uid))
+	flags := resolveFeatureFlags(s.defaults, global, user)
+
+	// Only cache complete results so a transient read failure is not pinned for the TTL
+	if globalErr == nil && userErr == nil {
+		s.mu.Lock()
+		s.cache[uid] = cachedFeatureFlags{flags: flags, expiresAt: now.Add(s.cacheTTL)}
+		s.mu.Unlock()
+	}
+
+	return copyFlags(flags)
+}
+
+// IsEnabled reports whether a flag is on for the user; unknown flags are off
+func (s *FeatureFlagService) IsEnabled(ctx context.Context, uid, flag string) bool {
+	return s.Resolve(ctx, uid)[flag]
+}
+
+// Invalidate drops the cached flags for a user, e.g. after changing an override
+func (s *FeatureFlagService) Invalidate(uid string) {
+	s.mu.Lock()
+	delete(s.cache, uid)
+	s.mu.Unlock()
+}
+
+// loadFlags reads a flag document; a missing document is treated as empty
+func (s *FeatureFlagService) loadFlags(ctx context.Context, path string) (map[string]interface{}, error) {
+	data, err := s.repo.Get(ctx, path)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil, nil
+		}
+		s.logger.Warn("Failed to load feature flags", zap.String("path", path), zap.Error(err))
+		return nil, err
+	}
+	return data, nil
+}
+
+// resolveFeatureFlags layers the global document and user overrides over the defaults.
+// Non-boolean values are ignored so metadata fields can live alongside flags.
+func resolveFeatureFlags(defaults map[string]bool, layers ...map[string]interface{}) map[string]bool {
+	flags := make(map[string]bool, len(defaults))
+	for name, enabled := range defaults {
+		flags[name] = enabled
+	}
+	for _, layer := range layers {
+		for name, value := range layer {
+			if enabled, ok := value.(bool); ok {
+				flags[name] = enabled
+			}
+		}
+	}
+	return flags
+}
+
+func copyFlags(flags map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		result[name] = enabled
+	}
+	return result
+}
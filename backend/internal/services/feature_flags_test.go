@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestResolveFeatureFlags_Precedence(t *testing.T) {
+	defaults := map[string]bool{"banking": true, "photo_battles": false, "new_ai": false}
+	global := map[string]interface{}{"photo_battles": true, "new_ai": true, "updatedBy": "admin"}
+	user := map[string]interface{}{"new_ai": false, "beta_only": true}
+
+	flags := resolveFeatureFlags(defaults, global, user)
+
+	assert.Equal(t, map[string]bool{
+		"banking":       true,  // default
+		"photo_battles": true,  // global overrides default
+		"new_ai":        false, // user overrides global
+		"beta_only":     true,  // user-only flag
+	}, flags)
+}
+
+func TestFeatureFlagService_Resolve(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("featureFlags/global", map[string]interface{}{"banking": false})
+	repo.AddDocument("users/user1/featureFlags/overrides", map[string]interface{}{"banking": true})
+	svc := NewFeatureFlagService(repo, zap.NewNop(), map[string]bool{"banking": true, "photo_battles": true}, 0)
+	ctx := context.Background()
+
+	assert.True(t, svc.IsEnabled(ctx, "user1", FeatureBanking))
+	assert.False(t, svc.IsEnabled(ctx, "user2", FeatureBanking))
+	assert.True(t, svc.IsEnabled(ctx, "user2", FeaturePhotoBattles))
+	assert.False(t, svc.IsEnabled(ctx, "user2", "unknown"))
+}
+
+func TestFeatureFlagService_CachesPerUser(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/featureFlags/overrides", map[string]interface{}{"banking": true})
+	svc := NewFeatureFlagService(repo, zap.NewNop(), nil, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.True(t, svc.IsEnabled(ctx, "user1", FeatureBanking))
+
+	repo.AddDocument("users/user1/featureFlags/overrides", map[string]interface{}{"banking": false})
+	assert.True(t, svc.IsEnabled(ctx, "user1", FeatureBanking), "cached value should be served within the TTL")
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, svc.IsEnabled(ctx, "user1", FeatureBanking), "cache should expire after the TTL")
+
+	repo.AddDocument("users/user1/featureFlags/overrides", map[string]interface{}{"banking": true})
+	svc.Invalidate("user1")
+	assert.True(t, svc.IsEnabled(ctx, "user1", FeatureBanking))
+}
+
+func TestFeatureFlagService_ResolveReturnsCopy(t *testing.T) {
+	svc := NewFeatureFlagService(mocks.NewMockRepository(), zap.NewNop(), map[string]bool{"banking": true}, 0)
+
+	flags := svc.Resolve(context.Background(), "user1")
+	flags["banking"] = false
+
+	assert.True(t, svc.IsEnabled(context.Background(), "user1", FeatureBanking))
+}
+
+func TestFeatureFlagService_ReadErrorFallsBackToDefaults(t *testing.T) {
+	svc := NewFeatureFlagService(&erroringGetRepository{MockRepository: mocks.NewMockRepository()}, zap.NewNop(), map[string]bool{"banking": true}, 0)
+
+	assert.True(t, svc.IsEnabled(context.Background(), "user1", FeatureBanking))
+	assert.Empty(t, svc.cache, "failed reads should not be cached")
+}
+
+type erroringGetRepository struct {
+	*mocks.MockRepository
+}
+
+func (r *erroringGetRepository) Get(ctx context.Context, path string) (map[string]interface{}, error) {
+	return nil, errors.New("unavailable")
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	defaultFinanceDashboardCacheTTL = time.Minute
+
+	// financeDashboardConcurrency caps the fetches run at once for a dashboard
+	financeDashboardConcurrency = 3
+	// financeDashboardMonths is the number of calendar months in the net
+	// worth trend, including the current one
+	financeDashboardMonths = 6
+	// financeDashboardTopCategories is the number of spending categories shown
+	financeDashboardTopCategories = 5
+)
+
+// liabilityAccountTypes are the linked account types whose balance is owed
+var liabilityAccountTypes = map[string]bool{"credit": true, "loan": true}
+
+// FinanceDashboard is everything the finance screen shows, in the user's
+// preferred currency. CashFlow and TopCategories cover the current calendar
+// month (UTC).
+type FinanceDashboard struct {
+	Month         string                  `json:"month"`
+	Currency      string                  `json:"currency"`
+	Locale        string                  `json:"locale"`
+	CashFlow      CashFlowTotals          `json:"cashFlow"`
+	NetWorth      NetWorth                `json:"netWorth"`
+	TopCategories []CategoryItem          `json:"topCategories"`
+	Budgets       []GoalFinancialProgress `json:"budgets"`
+	Portfolio     *DashboardSummary       `json:"portfolio"`
+	// UnconvertedCurrencies lists currencies with no exchange rate; their
+	// amounts are included unconverted
+	UnconvertedCurrencies []string  `json:"unconvertedCurrencies,omitempty"`
+	GeneratedAt           time.Time `json:"generatedAt"`
+}
+
+// NetWorth is linked account assets less liabilities plus the investment
+// portfolio value. Trend has one point per month end, the last being now;
+// earlier points take account balances back by each later month's net cash
+// flow and investments from the portfolio snapshots recorded by then.
+type NetWorth struct {
+	Total       float64         `json:"total"`
+	Assets      float64         `json:"assets"`
+	Liabilities float64         `json:"liabilities"`
+	Investments float64         `json:"investments"`
+	Change      float64         `json:"change"` // since the first point of Trend
+	Trend       []NetWorthPoint `json:"trend"`
+}
+
+// NetWorthPoint is the net worth at the end of a calendar month (YYYY-MM)
+type NetWorthPoint struct {
+	Month string  `json:"month"`
+	Value float64 `json:"value"`
+}
+
+type cachedFinanceDashboard struct {
+	dashboard *FinanceDashboard
+	expiresAt time.Time
+}
+
+// FinanceDashboardService composes the cash flow, spending, net worth,
+// budget and portfolio views into one finance summary
+type FinanceDashboardService struct {
+	repo        interfaces.Repository
+	logger      *zap.Logger
+	spending    *SpendingAnalyticsService
+	investments *InvestmentCalculationService
+	goals       *GoalService
+	currency    *CurrencyService
+	cacheTTL    time.Duration
+	now         func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedFinanceDashboard
+}
+
+// NewFinanceDashboardService creates a new finance dashboard service.
+// currency may be nil, in which case amounts are reported in DefaultCurrency.
+func NewFinanceDashboardService(
+	repo interfaces.Repository,
+	logger *zap.Logger,
+	spending *SpendingAnalyticsService,
+	investments *InvestmentCalculationService,
+	goals *GoalService,
+	currency *CurrencyService,
+	cfg config.FinanceConfig,
+) *FinanceDashboardService {
+	if cfg.DashboardCacheTTL <= 0 {
+		cfg.DashboardCacheTTL = defaultFinanceDashboardCacheTTL
+	}
+	return &FinanceDashboardService{
+		repo:        repo,
+		logger:      logger,
+		spending:    spending,
+		investments: investments,
+		goals:       goals,
+		currency:    currency,
+		cacheTTL:    cfg.DashboardCacheTTL,
+		now:         time.Now,
+		cache:       make(map[string]cachedFinanceDashboard),
+	}
+}
+
+// GetDashboard returns the user's finance summary: this month's cash flow
+// and top spending categories, net worth with its trend, spending goal
+// budgets and the portfolio summary. A dashboard built in the last cache TTL
+// for the same currency is reused.
+func (s *FinanceDashboardService) GetDashboard(ctx context.Context, uid string) (*FinanceDashboard, error) {
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	cacheKey := uid + "|" + prefs.Currency
+	s.mu.Lock()
+	cached, ok := s.cache[cacheKey]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.dashboard, nil
+	}
+
+	dashboard, err := s.buildDashboard(ctx, uid, prefs, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for key, entry := range s.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(s.cache, key)
+		}
+	}
+	s.cache[cacheKey] = cachedFinanceDashboard{dashboard: dashboard, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return dashboard, nil
+}
+
+// buildDashboard fetches every section, at most financeDashboardConcurrency
+// at a time, then converts the raw account and snapshot values
+func (s *FinanceDashboardService) buildDashboard(ctx context.Context, uid string, prefs UserPreferences, now time.Time) (*FinanceDashboard, error) {
+	today := now.UTC()
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	trendStart := monthStart.AddDate(0, -(financeDashboardMonths - 1), 0)
+
+	var (
+		cashFlow    *CashFlow
+		spending    *SpendingAnalytics
+		portfolio   *DashboardSummary
+		accounts    []map[string]interface{}
+		snapshots   []map[string]interface{}
+		budgets     []GoalFinancialProgress
+		unconverted []string
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(financeDashboardConcurrency)
+	g.Go(func() error {
+		var err error
+		cashFlow, err = s.spending.ComputeCashFlow(gctx, uid, financeDashboardMonths)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		spending, err = s.spending.ComputeSpendingAnalytics(gctx, uid, monthStart.Format("2006-01-02"), today.Format("2006-01-02"), nil)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		portfolio, err = s.investments.CalculateDashboardSummary(gctx, uid, prefs.Currency)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		accounts, err = s.repo.ListWhere(gctx, "accounts", []interfaces.Filter{{Field: "uid", Op: "==", Value: uid}}, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		// Uses the (uid, date) composite index
+		var err error
+		snapshots, err = s.repo.ListWhere(gctx, "portfolioSnapshots", []interfaces.Filter{
+			{Field: "uid", Op: "==", Value: uid},
+			{Field: "date", Op: ">=", Value: trendStart.Format("2006-01-02")},
+		}, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list portfolio snapshots: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		// Converters are not safe for concurrent use, so budgets get their own
+		converter := s.currency.Converter(gctx, prefs.Currency)
+		var err error
+		if budgets, err = s.goals.Budgets(gctx, uid, converter); err != nil {
+			return err
+		}
+		unconverted = converter.Unconverted()
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	converter := s.currency.Converter(ctx, prefs.Currency)
+	dashboard := &FinanceDashboard{
+		Month:         monthStart.Format("2006-01"),
+		Currency:      converter.Target(),
+		Locale:        prefs.Locale,
+		TopCategories: spending.CategoryBreakdown,
+		Budgets:       budgets,
+		Portfolio:     portfolio,
+		GeneratedAt:   now,
+	}
+	if len(cashFlow.Months) > 0 {
+		current := cashFlow.Months[len(cashFlow.Months)-1]
+		dashboard.Month = current.Month
+		dashboard.CashFlow = current.CashFlowTotals
+	}
+	if len(dashboard.TopCategories) > financeDashboardTopCategories {
+		dashboard.TopCategories = dashboard.TopCategories[:financeDashboardTopCategories]
+	}
+	dashboard.NetWorth = computeNetWorth(accounts, portfolio.TotalValue, cashFlow.Months, snapshots, converter)
+
+	seen := make(map[string]bool)
+	for _, list := range [][]string{spending.UnconvertedCurrencies, portfolio.UnconvertedCurrencies, unconverted, converter.Unconverted()} {
+		for _, currency := range list {
+			if !seen[currency] {
+				seen[currency] = true
+				dashboard.UnconvertedCurrencies = append(dashboard.UnconvertedCurrencies, currency)
+			}
+		}
+	}
+	sort.Strings(dashboard.UnconvertedCurrencies)
+
+	s.logger.Info("Finance dashboard computed",
+		zap.String("uid", uid),
+		zap.String("currency", dashboard.Currency),
+		zap.Float64("netWorth", dashboard.NetWorth.Total),
+		zap.Int("budgets", len(dashboard.Budgets)),
+	)
+	return dashboard, nil
+}
+
+// computeNetWorth totals the linked account balances and the portfolio
+// value, and walks them back month by month for the trend
+func computeNetWorth(accounts []map[string]interface{}, investments float64, months []CashFlowMonth, snapshots []map[string]interface{}, converter *CurrencyConverter) NetWorth {
+	netWorth := NetWorth{Investments: roundCents(investments), Trend: []NetWorthPoint{}}
+	for _, account := range accounts {
+		balances, _ := account["balances"].(map[string]interface{})
+		current, ok := toFloat(balances["current"])
+		if !ok {
+			continue
+		}
+		current = converter.Convert(current, getStringField(balances, "isoCurrency"))
+		if liabilityAccountTypes[getStringField(account, "type")] {
+			netWorth.Liabilities += current
+		} else {
+			netWorth.Assets += current
+		}
+	}
+	netWorth.Assets = roundCents(netWorth.Assets)
+	netWorth.Liabilities = roundCents(netWorth.Liabilities)
+	netWorth.Total = roundCents(netWorth.Assets - netWorth.Liabilities + netWorth.Investments)
+	if len(months) == 0 {
+		return netWorth
+	}
+
+	labels := make([]string, len(months))
+	for i, month := range months {
+		labels[i] = month.Month
+	}
+	invested := snapshotValuesByMonth(snapshots, labels, converter)
+
+	netWorth.Trend = make([]NetWorthPoint, len(months))
+	last := len(months) - 1
+	netWorth.Trend[last] = NetWorthPoint{Month: labels[last], Value: netWorth.Total}
+	cash := netWorth.Assets - netWorth.Liabilities
+	for i := last - 1; i >= 0; i-- {
+		cash -= months[i+1].Net
+		netWorth.Trend[i] = NetWorthPoint{Month: labels[i], Value: roundCents(cash + invested[labels[i]])}
+	}
+	netWorth.Change = roundCents(netWorth.Total - netWorth.Trend[0].Value)
+	return netWorth
+}
+
+// snapshotValuesByMonth sums, for each month, the latest snapshot of every
+// portfolio taken by the end of that month
+func snapshotValuesByMonth(snapshots []map[string]interface{}, months []string, converter *CurrencyConverter) map[string]float64 {
+	sorted := make([]map[string]interface{}, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return getStringField(sorted[i], "date") < getStringField(sorted[j], "date")
+	})
+
+	values := make(map[string]float64, len(months))
+	latest := make(map[string]float64)
+	next := 0
+	for _, month := range months {
+		// Snapshot dates are YYYY-MM-DD, so every date in the month sorts
+		// at or before YYYY-MM-31
+		end := month + "-31"
+		for ; next < len(sorted) && getStringField(sorted[next], "date") <= end; next++ {
+			snapshot := sorted[next]
+			value, _ := toFloat(snapshot["totalValue"])
+			latest[getStringField(snapshot, "portfolioId")] = converter.Convert(value, getStringField(snapshot, "currency"))
+		}
+		for _, value := range latest {
+			values[month] += value
+		}
+	}
+	return values
+}
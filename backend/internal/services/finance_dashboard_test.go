@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newTestFinanceDashboardService(t *testing.T) (*FinanceDashboardService, *mocks.MockRepository) {
+	// Amounts are reported in EUR; 1 EUR buys 1.25 USD
+	repo, currency := newEURUser(t)
+	logger := zap.NewNop()
+	svc := NewFinanceDashboardService(repo, logger,
+		NewSpendingAnalyticsService(repo, logger, nil, currency),
+		NewInvestmentCalculationService(repo, logger, 0, currency),
+		NewGoalService(repo, logger, config.GoalsConfig{}),
+		currency,
+		config.FinanceConfig{},
+	)
+	return svc, repo
+}
+
+func TestFinanceDashboardService_ComposesSectionsInBaseCurrency(t *testing.T) {
+	svc, repo := newTestFinanceDashboardService(t)
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	today := now.Format("2006-01-02")
+	lastMonth := monthStart.AddDate(0, -1, 0)
+
+	// Cash flow and spending: 2000 EUR in and 150 EUR out this month
+	repo.AddDocument("users/user1/transactions/t1", map[string]interface{}{
+		"postedAt": today, "amount": 125.0, "isoCurrency": "USD", "category": "Groceries", "goalId": "food",
+	})
+	repo.AddDocument("users/user1/transactions/t2", map[string]interface{}{
+		"postedAt": today, "amount": 50.0, "isoCurrency": "EUR", "category": "Dining",
+	})
+	repo.AddDocument("users/user1/transactions/t3", map[string]interface{}{
+		"postedAt": today, "amount": 2500.0, "isoCurrency": "USD", "isIncome": true, "category": "Salary",
+	})
+	repo.AddDocument("users/user1/transactions/t4", map[string]interface{}{
+		"postedAt": lastMonth.AddDate(0, 0, 14).Format("2006-01-02"), "amount": 250.0, "isoCurrency": "USD", "category": "Travel",
+	})
+
+	// Net worth: linked accounts and the portfolio
+	repo.AddDocument("accounts/a1", map[string]interface{}{
+		"uid": "user1", "type": "depository",
+		"balances": map[string]interface{}{"current": 12500.0, "isoCurrency": "USD"},
+	})
+	repo.AddDocument("accounts/a2", map[string]interface{}{
+		"uid": "user1", "type": "credit",
+		"balances": map[string]interface{}{"current": 500.0, "isoCurrency": "EUR"},
+	})
+	repo.AddDocument("accounts/a3", map[string]interface{}{
+		"uid": "user2", "type": "depository",
+		"balances": map[string]interface{}{"current": 99999.0, "isoCurrency": "EUR"},
+	})
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"uid": "user1", "name": "Retirement"})
+	repo.AddDocument("investments/i1", map[string]interface{}{
+		"uid": "user1", "portfolioId": "p1", "ticker": "VT", "currentValue": 6250.0, "initialAmount": 5000.0, "currency": "USD",
+	})
+	repo.AddDocument("portfolioSnapshots/s1", map[string]interface{}{
+		"uid": "user1", "portfolioId": "p1", "date": lastMonth.AddDate(0, 0, 9).Format("2006-01-02"), "totalValue": 5000.0, "currency": "USD",
+	})
+
+	// Budgets: active spending goals only
+	repo.AddDocument("users/user1/goals/food", map[string]interface{}{
+		"id": "food", "title": "Groceries", "type": "financial", "direction": "spend", "targetAmount": 400.0,
+	})
+	repo.AddDocument("users/user1/goals/old", map[string]interface{}{
+		"id": "old", "title": "Last year", "type": "financial", "direction": "spend", "targetAmount": 400.0, "status": "archived",
+	})
+	repo.AddDocument("users/user1/goals/trip", map[string]interface{}{
+		"id": "trip", "title": "Trip", "type": "financial", "targetAmount": 5000.0,
+	})
+
+	dashboard, err := svc.GetDashboard(context.Background(), "user1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "EUR", dashboard.Currency)
+	assert.Equal(t, "de-DE", dashboard.Locale)
+	assert.Equal(t, monthStart.Format("2006-01"), dashboard.Month)
+
+	// From the cash flow analytics
+	assert.InDelta(t, 2000, dashboard.CashFlow.Income, 1e-9)
+	assert.InDelta(t, 150, dashboard.CashFlow.Expenses, 1e-9)
+	assert.InDelta(t, 1850, dashboard.CashFlow.Net, 1e-9)
+
+	// From the spending analytics, this month only
+	assert.Equal(t, []CategoryItem{{Name: "Groceries", Value: 100}, {Name: "Dining", Value: 50}}, dashboard.TopCategories)
+
+	// From the portfolio summary
+	require.NotNil(t, dashboard.Portfolio)
+	assert.Equal(t, "EUR", dashboard.Portfolio.Currency)
+	assert.InDelta(t, 5000, dashboard.Portfolio.TotalValue, 1e-9)
+
+	// Accounts less the card balance plus the portfolio
+	netWorth := dashboard.NetWorth
+	assert.Equal(t, 10000.0, netWorth.Assets)
+	assert.Equal(t, 500.0, netWorth.Liabilities)
+	assert.Equal(t, 5000.0, netWorth.Investments)
+	assert.Equal(t, 14500.0, netWorth.Total)
+
+	// Last month ended 1850 lower in accounts with the 4000 EUR snapshot; the
+	// months before had neither last month's spend nor any snapshot
+	require.Len(t, netWorth.Trend, financeDashboardMonths)
+	assert.Equal(t, NetWorthPoint{Month: monthStart.Format("2006-01"), Value: 14500}, netWorth.Trend[5])
+	assert.Equal(t, NetWorthPoint{Month: lastMonth.Format("2006-01"), Value: 11650}, netWorth.Trend[4])
+	assert.Equal(t, 7850.0, netWorth.Trend[3].Value)
+	assert.Equal(t, 7850.0, netWorth.Trend[0].Value)
+	assert.Equal(t, 6650.0, netWorth.Change)
+
+	// From the goals, with tagged transactions converted
+	require.Len(t, dashboard.Budgets, 1)
+	assert.Equal(t, "food", dashboard.Budgets[0].GoalID)
+	assert.Equal(t, 100.0, dashboard.Budgets[0].Amount)
+	assert.Equal(t, 25.0, dashboard.Budgets[0].ProgressPercent)
+
+	assert.Empty(t, dashboard.UnconvertedCurrencies)
+}
+
+func TestFinanceDashboardService_CachesBriefly(t *testing.T) {
+	svc, repo := newTestFinanceDashboardService(t)
+	ctx := context.Background()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	first, err := svc.GetDashboard(ctx, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, first.Budgets)
+
+	repo.AddDocument("users/user1/goals/food", map[string]interface{}{
+		"id": "food", "type": "financial", "direction": "spend", "targetAmount": 400.0,
+	})
+	cached, err := svc.GetDashboard(ctx, "user1")
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+
+	now = now.Add(defaultFinanceDashboardCacheTTL)
+	fresh, err := svc.GetDashboard(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, fresh.Budgets, 1)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+var (
+	// ErrInvalidFocusSession is wrapped into validation errors for focus session requests
+	ErrInvalidFocusSession = errors.New("invalid focus session")
+
+	// ErrFocusSessionCompleted is returned when completing a session that is no longer active
+	ErrFocusSessionCompleted = errors.New("focus session already completed")
+)
+
+// FocusSessionService creates and completes focus sessions with a consistent schema.
+// Sessions always have startTime/endTime as timestamps, duration in minutes, and
+// per-task timeSpent as an integer number of seconds, as the analytics expect.
+type FocusSessionService struct {
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	activity *ActivityIndexService
+}
+
+// NewFocusSessionService creates a new focus session service. activity may be
+// nil, in which case started sessions are not added to the activity index.
+func NewFocusSessionService(repo interfaces.Repository, logger *zap.Logger, activity *ActivityIndexService) *FocusSessionService {
+	return &FocusSessionService{
+		repo:     repo,
+		logger:   logger,
+		activity: activity,
+	}
+}
+
+// StartFocusSessionRequest represents a request to start a focus session
+type StartFocusSessionRequest struct {
+	Duration float64                  `json:"duration"`           // Planned duration in minutes
+	Tasks    []map[string]interface{} `json:"tasks"`              // Tasks to focus on (each must have an id)
+	Category string                   `json:"category,omitempty"` // Optional label, e.g. "deep work"
+	Location *Location                `json:"location,omitempty"` // Optional place the session happens
+}
+
+// FocusTaskProgress reports time spent on one task of a session
+type FocusTaskProgress struct {
+	TaskID    string  `json:"taskId"`
+	TimeSpent float64 `json:"timeSpent"` // Seconds
+	Completed bool    `json:"completed"`
+	Notes     string  `json:"notes,omitempty"`
+}
+
+// CompleteFocusSessionRequest represents a request to complete a focus session
+type CompleteFocusSessionRequest struct {
+	SessionID string              `json:"sessionId"`
+	Tasks     []FocusTaskProgress `json:"tasks"`
+	Feedback  string              `json:"feedback,omitempty"`
+	Rating    *int                `json:"rating,omitempty"` // 1-5
+}
+
+// Start validates and creates a new active focus session
+func (s *FocusSessionService) Start(ctx context.Context, uid string, req StartFocusSessionRequest) (map[string]interface{}, error) {
+	return s.start(ctx, uid, req, "")
+}
+
+// StartFromTemplate starts a focus session with the duration, tasks and
+// category of one of the user's templates in users/{uid}/sessionTemplates.
+// The session records the template it came from.
+func (s *FocusSessionService) StartFromTemplate(ctx context.Context, uid, templateID string) (map[string]interface{}, error) {
+	if templateID == "" {
+		return nil, fmt.Errorf("%w: templateId is required", ErrInvalidFocusSession)
+	}
+	template, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/sessionTemplates/%s", uid, templateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session template: %w", err)
+	}
+
+	req := StartFocusSessionRequest{}
+	req.Duration, _ = toFloat(template["duration"])
+	req.Category, _ = template["category"].(string)
+	items, _ := template["tasks"].([]interface{})
+	for _, item := range items {
+		if task, ok := item.(map[string]interface{}); ok {
+			req.Tasks = append(req.Tasks, task)
+		}
+	}
+
+	session, err := s.start(ctx, uid, req, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("session template %s: %w", templateID, err)
+	}
+	return session, nil
+}
+
+func (s *FocusSessionService) start(ctx context.Context, uid string, req StartFocusSessionRequest, templateID string) (map[string]interface{}, error) {
+	if req.Duration <= 0 || math.IsNaN(req.Duration) || math.IsInf(req.Duration, 0) {
+		return nil, fmt.Errorf("%w: duration must be a positive number of minutes", ErrInvalidFocusSession)
+	}
+	if len(req.Tasks) == 0 {
+		return nil, fmt.Errorf("%w: at least one task is required", ErrInvalidFocusSession)
+	}
+
+	tasks := make([]interface{}, 0, len(req.Tasks))
+	for i, task := range req.Tasks {
+		if id, _ := task["id"].(string); id == "" {
+			return nil, fmt.Errorf("%w: task %d is missing an id", ErrInvalidFocusSession, i)
+		}
+		tasks = append(tasks, map[string]interface{}{
+			"task":      task,
+			"timeSpent": int64(0),
+			"completed": false,
+		})
+	}
+	var location map[string]interface{}
+	if req.Location != nil {
+		var err error
+		if location, err = req.Location.normalize(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFocusSession, err)
+		}
+	}
+
+	sessionID := uuid.New().String()
+	startTime := time.Now()
+	session := map[string]interface{}{
+		"id":               sessionID,
+		"startTime":        startTime,
+		"plannedDuration":  req.Duration,
+		"duration":         req.Duration,
+		"tasks":            tasks,
+		"currentTaskIndex": 0,
+		"isActive":         true,
+		"isOnBreak":        false,
+		"breaks":           []interface{}{},
+	}
+	if category := strings.TrimSpace(req.Category); category != "" {
+		session["category"] = category
+	}
+	if location != nil {
+		session["location"] = location
+	}
+	if templateID != "" {
+		session["templateId"] = templateID
+	}
+
+	path := fmt.Sprintf("users/%s/focusSessions/%s", uid, sessionID)
+	if err := s.repo.Create(ctx, path, session); err != nil {
+		return nil, fmt.Errorf("failed to create focus session: %w", err)
+	}
+	s.activity.RecordSession(ctx, uid, startTime)
+
+	s.logger.Info("Focus session started",
+		zap.String("uid", uid),
+		zap.String("sessionId", sessionID),
+		zap.Int("tasks", len(tasks)),
+		zap.String("templateId", templateID),
+	)
+
+	return session, nil
+}
+
+// Complete validates task progress, stamps endTime, computes duration, and
+// marks the session inactive
+func (s *FocusSessionService) Complete(ctx context.Context, uid string, req CompleteFocusSessionRequest) (map[string]interface{}, error) {
+	if req.SessionID == "" {
+		return nil, fmt.Errorf("%w: sessionId is required", ErrInvalidFocusSession)
+	}
+	if req.Rating != nil && (*req.Rating < 1 || *req.Rating > 5) {
+		return nil, fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidFocusSession)
+	}
+	progress := make(map[string]FocusTaskProgress, len(req.Tasks))
+	for _, p := range req.Tasks {
+		if p.TaskID == "" {
+			return nil, fmt.Errorf("%w: task progress is missing taskId", ErrInvalidFocusSession)
+		}
+		if p.TimeSpent < 0 || math.IsNaN(p.TimeSpent) || math.IsInf(p.TimeSpent, 0) {
+			return nil, fmt.Errorf("%w: timeSpent for task %s must be a non-negative number", ErrInvalidFocusSession, p.TaskID)
+		}
+		progress[p.TaskID] = p
+	}
+
+	path := fmt.Sprintf("users/%s/focusSessions/%s", uid, req.SessionID)
+	session, err := s.repo.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get focus session: %w", err)
+	}
+	if active, ok := session["isActive"].(bool); ok && !active {
+		return nil, ErrFocusSessionCompleted
+	}
+
+	startTime, ok := parseFlexibleDate(session["startTime"])
+	if !ok {
+		return nil, fmt.Errorf("%w: session has no valid startTime", ErrInvalidFocusSession)
+	}
+	endTime := time.Now()
+	if endTime.Before(startTime) {
+		endTime = startTime
+	}
+
+	tasks := s.normalizeTasks(session["tasks"], progress)
+
+	// Duration excludes paused time (stored in milliseconds)
+	elapsed := endTime.Sub(startTime)
+	if paused, ok := toFloat(session["totalPausedTime"]); ok && paused > 0 {
+		elapsed -= time.Duration(paused) * time.Millisecond
+	}
+	duration := int64(math.Round(elapsed.Minutes()))
+	if duration < 0 {
+		duration = 0
+	}
+
+	updates := map[string]interface{}{
+		"startTime": startTime,
+		"endTime":   endTime,
+		"duration":  duration,
+		"tasks":     tasks,
+		"isActive":  false,
+		"isOnBreak": false,
+	}
+	if req.Feedback != "" {
+		updates["feedback"] = req.Feedback
+	}
+	if req.Rating != nil {
+		updates["rating"] = *req.Rating
+	}
+
+	if err := s.repo.Update(ctx, path, updates); err != nil {
+		return nil, fmt.Errorf("failed to complete focus session: %w", err)
+	}
+
+	for k, v := range updates {
+		session[k] = v
+	}
+
+	s.logger.Info("Focus session completed",
+		zap.String("uid", uid),
+		zap.String("sessionId", req.SessionID),
+		zap.Int64("duration", duration),
+	)
+
+	return session, nil
+}
+
+// normalizeTasks applies reported progress to the stored session tasks and
+// coerces timeSpent to whole seconds, dropping malformed entries
+func (s *FocusSessionService) normalizeTasks(raw interface{}, progress map[string]FocusTaskProgress) []interface{} {
+	stored, _ := raw.([]interface{})
+	tasks := make([]interface{}, 0, len(stored))
+
+	for _, item := range stored {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			s.logger.Warn("Dropping malformed focus session task")
+			continue
+		}
+		task, _ := entry["task"].(map[string]interface{})
+		taskID, _ := task["id"].(string)
+
+		timeSpent, ok := toFloat(entry["timeSpent"])
+		if !ok || timeSpent < 0 {
+			timeSpent = 0
+		}
+		completed, _ := entry["completed"].(bool)
+
+		normalized := map[string]interface{}{
+			"task":      task,
+			"completed": completed,
+		}
+		if notes, ok := entry["notes"].(string); ok && notes != "" {
+			normalized["notes"] = notes
+		}
+		if p, ok := progress[taskID]; ok && taskID != "" {
+			timeSpent = p.TimeSpent
+			normalized["completed"] = p.Completed
+			if p.Notes != "" {
+				normalized["notes"] = p.Notes
+			}
+		}
+		normalized["timeSpent"] = int64(math.Round(timeSpent))
+
+		tasks = append(tasks, normalized)
+	}
+
+	return tasks
+}
+
+// toFloat converts a numeric Firestore value to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestFocusSessionService_Start(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewFocusSessionService(mockRepo, zap.NewNop(), nil)
+
+	session, err := svc.Start(context.Background(), "user1", StartFocusSessionRequest{
+		Duration: 25,
+		Tasks:    []map[string]interface{}{{"id": "task1", "title": "Write"}},
+	})
+	require.NoError(t, err)
+
+	id := session["id"].(string)
+	stored := mockRepo.Documents["users/user1/focusSessions/"+id]
+	require.NotNil(t, stored)
+
+	_, ok := stored["startTime"].(time.Time)
+	assert.True(t, ok, "startTime must be a time.Time")
+	assert.Equal(t, true, stored["isActive"])
+
+	tasks := stored["tasks"].([]interface{})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, int64(0), tasks[0].(map[string]interface{})["timeSpent"])
+}
+
+func TestFocusSessionService_Start_Validation(t *testing.T) {
+	svc := NewFocusSessionService(mocks.NewMockRepository(), zap.NewNop(), nil)
+
+	tests := []struct {
+		name string
+		req  StartFocusSessionRequest
+	}{
+		{name: "zero duration", req: StartFocusSessionRequest{Tasks: []map[string]interface{}{{"id": "t"}}}},
+		{name: "no tasks", req: StartFocusSessionRequest{Duration: 25}},
+		{name: "task without id", req: StartFocusSessionRequest{Duration: 25, Tasks: []map[string]interface{}{{"title": "x"}}}},
+		{name: "location without label", req: StartFocusSessionRequest{Duration: 25, Tasks: []map[string]interface{}{{"id": "t"}}, Location: &Location{Label: " "}}},
+		{name: "location with lat only", req: StartFocusSessionRequest{Duration: 25, Tasks: []map[string]interface{}{{"id": "t"}}, Location: &Location{Label: "Home", Lat: floatPtr(45)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Start(context.Background(), "user1", tt.req)
+			assert.True(t, errors.Is(err, ErrInvalidFocusSession))
+		})
+	}
+}
+
+func TestFocusSessionService_Complete(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewFocusSessionService(mockRepo, zap.NewNop(), nil)
+	path := "users/user1/focusSessions/s1"
+
+	// Legacy shape: string startTime, float and malformed timeSpent values
+	mockRepo.AddDocument(path, map[string]interface{}{
+		"id":              "s1",
+		"startTime":       time.Now().Add(-30 * time.Minute).Format(time.RFC3339),
+		"isActive":        true,
+		"totalPausedTime": float64(5 * 60 * 1000),
+		"tasks": []interface{}{
+			map[string]interface{}{"task": map[string]interface{}{"id": "task1"}, "timeSpent": "oops"},
+			map[string]interface{}{"task": map[string]interface{}{"id": "task2"}, "timeSpent": float64(90.4)},
+			"not a task",
+		},
+	})
+
+	rating := 4
+	session, err := svc.Complete(context.Background(), "user1", CompleteFocusSessionRequest{
+		SessionID: "s1",
+		Tasks:     []FocusTaskProgress{{TaskID: "task1", TimeSpent: 600, Completed: true}},
+		Rating:    &rating,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, false, session["isActive"])
+	_, ok := session["startTime"].(time.Time)
+	assert.True(t, ok, "startTime must be normalized to time.Time")
+	_, ok = session["endTime"].(time.Time)
+	assert.True(t, ok, "endTime must be set")
+	assert.InDelta(t, 25, session["duration"].(int64), 1)
+
+	tasks := session["tasks"].([]interface{})
+	require.Len(t, tasks, 2)
+	assert.Equal(t, int64(600), tasks[0].(map[string]interface{})["timeSpent"])
+	assert.Equal(t, true, tasks[0].(map[string]interface{})["completed"])
+	assert.Equal(t, int64(90), tasks[1].(map[string]interface{})["timeSpent"])
+
+	// Analytics can sum the normalized session
+	dashboard := NewDashboardAnalyticsService(mockRepo, zap.NewNop(), nil)
+	assert.Equal(t, 690, dashboard.sumSessionTime([]map[string]interface{}{mockRepo.Documents[path]}))
+}
+
+func TestFocusSessionService_Complete_Errors(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewFocusSessionService(mockRepo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	mockRepo.AddDocument("users/user1/focusSessions/done", map[string]interface{}{
+		"startTime": time.Now().Add(-time.Hour),
+		"isActive":  false,
+	})
+
+	_, err := svc.Complete(ctx, "user1", CompleteFocusSessionRequest{SessionID: "done"})
+	assert.ErrorIs(t, err, ErrFocusSessionCompleted)
+
+	_, err = svc.Complete(ctx, "user1", CompleteFocusSessionRequest{SessionID: "missing"})
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+
+	_, err = svc.Complete(ctx, "user1", CompleteFocusSessionRequest{
+		SessionID: "done",
+		Tasks:     []FocusTaskProgress{{TaskID: "task1", TimeSpent: -1}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidFocusSession)
+
+	badRating := 9
+	_, err = svc.Complete(ctx, "user1", CompleteFocusSessionRequest{SessionID: "done", Rating: &badRating})
+	assert.ErrorIs(t, err, ErrInvalidFocusSession)
+}
+
+func TestFocusSessionService_StartFromTemplate(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewFocusSessionService(mockRepo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	mockRepo.AddDocument("users/user1/sessionTemplates/pomodoro", map[string]interface{}{
+		"name":     "Pomodoro",
+		"duration": int64(25),
+		"category": "deep work",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "task1", "title": "Write"},
+			map[string]interface{}{"id": "task2", "title": "Review"},
+		},
+		"startTime": time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+	})
+
+	before := time.Now()
+	session, err := svc.StartFromTemplate(ctx, "user1", "pomodoro")
+	require.NoError(t, err)
+
+	id := session["id"].(string)
+	stored := mockRepo.Documents["users/user1/focusSessions/"+id]
+	require.NotNil(t, stored)
+	assert.Equal(t, float64(25), stored["plannedDuration"])
+	assert.Equal(t, "deep work", stored["category"])
+	assert.Equal(t, "pomodoro", stored["templateId"])
+	assert.Equal(t, true, stored["isActive"])
+
+	// The session gets a fresh start time, not one copied from the template
+	startTime, ok := stored["startTime"].(time.Time)
+	require.True(t, ok)
+	assert.False(t, startTime.Before(before))
+
+	tasks := stored["tasks"].([]interface{})
+	require.Len(t, tasks, 2)
+	first := tasks[0].(map[string]interface{})
+	assert.Equal(t, "task1", first["task"].(map[string]interface{})["id"])
+	assert.Equal(t, int64(0), first["timeSpent"])
+
+	// Each start is a new session
+	again, err := svc.StartFromTemplate(ctx, "user1", "pomodoro")
+	require.NoError(t, err)
+	assert.NotEqual(t, id, again["id"])
+}
+
+func TestFocusSessionService_StartFromTemplate_Errors(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewFocusSessionService(mockRepo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	mockRepo.AddDocument("users/user1/sessionTemplates/pomodoro", map[string]interface{}{
+		"duration": 25.0,
+		"tasks":    []interface{}{map[string]interface{}{"id": "task1"}},
+	})
+	mockRepo.AddDocument("users/user1/sessionTemplates/empty", map[string]interface{}{
+		"duration": 25.0,
+	})
+
+	// Another user's template is not found under this user
+	_, err := svc.StartFromTemplate(ctx, "user2", "pomodoro")
+	assert.True(t, errors.Is(err, interfaces.ErrNotFound))
+
+	_, err = svc.StartFromTemplate(ctx, "user1", "empty")
+	assert.True(t, errors.Is(err, ErrInvalidFocusSession))
+
+	_, err = svc.StartFromTemplate(ctx, "user1", "")
+	assert.True(t, errors.Is(err, ErrInvalidFocusSession))
+
+	for path := range mockRepo.Documents {
+		assert.NotContains(t, path, "/focusSessions/")
+	}
+}
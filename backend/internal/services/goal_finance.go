@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+var (
+	// ErrGoalNotFound is returned for goals the user does not have
+	ErrGoalNotFound = errors.New("goal not found")
+	// ErrTransactionNotFound is returned for transactions the user does not have
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrNotFinancialGoal is returned when a goal has no financial target
+	ErrNotFinancialGoal = errors.New("goal is not a financial goal")
+)
+
+const (
+	// GoalTypeFinancial marks goals measured by tagged transactions against targetAmount
+	GoalTypeFinancial = "financial"
+
+	// Directions of a financial goal: saving counts tagged inflows towards
+	// the target, spending counts tagged outflows
+	GoalDirectionSave  = "save"
+	GoalDirectionSpend = "spend"
+
+	// goalPaceWindow is how far back the pace behind a projection looks
+	goalPaceWindow = 90 * 24 * time.Hour
+)
+
+// GoalFinancialProgress is how far the transactions tagged to a financial
+// goal have come towards its targetAmount. Amount is the net inflow for a
+// saving goal and the net outflow for a spending goal. ProjectedCompletion
+// extrapolates the pace over the last 90 days and is nil when the goal is
+// reached or there is no pace towards it.
+type GoalFinancialProgress struct {
+	GoalID              string     `json:"goalId"`
+	Title               string     `json:"title"`
+	Direction           string     `json:"direction"`
+	TargetAmount        float64    `json:"targetAmount"`
+	Inflows             float64    `json:"inflows"`
+	Outflows            float64    `json:"outflows"`
+	Amount              float64    `json:"amount"`
+	Remaining           float64    `json:"remaining"`
+	ProgressPercent     float64    `json:"progressPercent"`
+	Transactions        int        `json:"transactions"`
+	PacePerDay          float64    `json:"pacePerDay"`
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"`
+}
+
+// TagTransaction attributes a transaction to a financial goal; an empty
+// goalID removes the tag
+func (s *GoalService) TagTransaction(ctx context.Context, uid, transactionID, goalID string) error {
+	path := fmt.Sprintf("users/%s/transactions/%s", uid, transactionID)
+	if _, err := s.repo.Get(ctx, path); err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return ErrTransactionNotFound
+		}
+		return fmt.Errorf("failed to load transaction: %w", err)
+	}
+
+	var value interface{} = firestore.Delete
+	if goalID != "" {
+		if _, err := s.financialGoal(ctx, uid, goalID); err != nil {
+			return err
+		}
+		value = goalID
+	}
+	if err := s.repo.Update(ctx, path, map[string]interface{}{"goalId": value}); err != nil {
+		return fmt.Errorf("failed to tag transaction: %w", err)
+	}
+
+	s.logger.Info("Transaction goal tag updated",
+		zap.String("uid", uid),
+		zap.String("transactionId", transactionID),
+		zap.String("goalId", goalID),
+	)
+	return nil
+}
+
+// FinancialProgress sums the transactions tagged with a financial goal
+// towards its targetAmount
+func (s *GoalService) FinancialProgress(ctx context.Context, uid, goalID string) (*GoalFinancialProgress, error) {
+	goal, err := s.financialGoal(ctx, uid, goalID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.repo.ListWhere(ctx, fmt.Sprintf("users/%s/transactions", uid), []interfaces.Filter{
+		{Field: "goalId", Op: "==", Value: goalID},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	progress := computeGoalFinancialProgress(goal, transactions, s.now())
+	progress.GoalID = goalID
+	return progress, nil
+}
+
+// Budgets returns the progress of the user's active spending goals, with
+// tagged transactions converted by converter, ordered by progress descending
+func (s *GoalService) Budgets(ctx context.Context, uid string, converter *CurrencyConverter) ([]GoalFinancialProgress, error) {
+	goals, err := s.repo.ListWhere(ctx, fmt.Sprintf("users/%s/goals", uid), []interfaces.Filter{
+		{Field: "type", Op: "==", Value: GoalTypeFinancial},
+		{Field: "direction", Op: "==", Value: GoalDirectionSpend},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spending goals: %w", err)
+	}
+
+	now := s.now()
+	budgets := []GoalFinancialProgress{}
+	for _, goal := range goals {
+		if status := getStringField(goal, "status"); status == "completed" || status == "archived" {
+			continue
+		}
+		if target, ok := toFloat(goal["targetAmount"]); !ok || target <= 0 {
+			continue
+		}
+		goalID := getStringField(goal, "id")
+		transactions, err := s.repo.ListWhere(ctx, fmt.Sprintf("users/%s/transactions", uid), []interfaces.Filter{
+			{Field: "goalId", Op: "==", Value: goalID},
+		}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		progress := computeGoalFinancialProgress(goal, convertTransactionAmounts(transactions, converter), now)
+		progress.GoalID = goalID
+		budgets = append(budgets, *progress)
+	}
+	sort.SliceStable(budgets, func(i, j int) bool {
+		return budgets[i].ProgressPercent > budgets[j].ProgressPercent
+	})
+	return budgets, nil
+}
+
+// financialGoal loads a goal, which must be of type financial with a positive
+// targetAmount
+func (s *GoalService) financialGoal(ctx context.Context, uid, goalID string) (map[string]interface{}, error) {
+	goal, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/goals/%s", uid, goalID))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil, ErrGoalNotFound
+		}
+		return nil, fmt.Errorf("failed to load goal: %w", err)
+	}
+	if getStringField(goal, "type") != GoalTypeFinancial {
+		return nil, ErrNotFinancialGoal
+	}
+	if target, ok := toFloat(goal["targetAmount"]); !ok || target <= 0 {
+		return nil, fmt.Errorf("%w: targetAmount must be positive", ErrNotFinancialGoal)
+	}
+	return goal, nil
+}
+
+// computeGoalFinancialProgress totals a financial goal's tagged transactions
+func computeGoalFinancialProgress(goal map[string]interface{}, transactions []map[string]interface{}, now time.Time) *GoalFinancialProgress {
+	target, _ := toFloat(goal["targetAmount"])
+	progress := &GoalFinancialProgress{
+		Title:        getStringField(goal, "title"),
+		Direction:    GoalDirectionSave,
+		TargetAmount: target,
+		Transactions: len(transactions),
+	}
+	if getStringField(goal, "direction") == GoalDirectionSpend {
+		progress.Direction = GoalDirectionSpend
+	}
+	// Signed amounts are positive for outflows; toward is the sign that
+	// moves this goal forward
+	toward := -1.0
+	if progress.Direction == GoalDirectionSpend {
+		toward = 1.0
+	}
+
+	windowStart := now.Add(-goalPaceWindow)
+	var recent float64
+	for _, txn := range transactions {
+		amount := signedTransactionAmount(txn)
+		if amount > 0 {
+			progress.Outflows += amount
+		} else {
+			progress.Inflows -= amount
+		}
+
+		date, ok := parseTransactionDate(txn["postedAt"])
+		if !ok {
+			date, ok = parseTransactionDate(txn["date"])
+		}
+		if ok && !date.Before(windowStart) && !date.After(now) {
+			recent += amount * toward
+		}
+	}
+
+	progress.Inflows = roundCents(progress.Inflows)
+	progress.Outflows = roundCents(progress.Outflows)
+	if progress.Direction == GoalDirectionSpend {
+		progress.Amount = roundCents(progress.Outflows - progress.Inflows)
+	} else {
+		progress.Amount = roundCents(progress.Inflows - progress.Outflows)
+	}
+	progress.Remaining = roundCents(math.Max(target-progress.Amount, 0))
+	progress.ProgressPercent = math.Round(math.Min(math.Max(progress.Amount/target, 0), 1)*10000) / 100
+
+	pace := recent / (goalPaceWindow.Hours() / 24)
+	progress.PacePerDay = roundCents(pace)
+	if progress.Remaining > 0 && pace > 0 {
+		days := progress.Remaining / pace
+		projected := now.Add(time.Duration(days * float64(24*time.Hour)))
+		progress.ProjectedCompletion = &projected
+	}
+	return progress
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newTestGoalFinanceService(now time.Time) (*GoalService, *mocks.MockRepository) {
+	repo := mocks.NewMockRepository()
+	service := NewGoalService(repo, zap.NewNop(), config.GoalsConfig{})
+	service.now = func() time.Time { return now }
+
+	repo.AddDocument("users/user1/goals/trip", map[string]interface{}{
+		"id": "trip", "title": "Save for Japan", "type": "financial", "targetAmount": int64(5000), "status": "active",
+	})
+	repo.AddDocument("users/user1/goals/groceries", map[string]interface{}{
+		"id": "groceries", "title": "Grocery budget", "type": "financial", "direction": "spend", "targetAmount": 400.0,
+	})
+	repo.AddDocument("users/user1/goals/books", map[string]interface{}{
+		"id": "books", "title": "Read 10 books", "status": "active",
+	})
+	return service, repo
+}
+
+func TestGoalService_FinancialProgress_SumsTaggedTransactions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service, repo := newTestGoalFinanceService(now)
+	day := func(daysAgo int) string { return now.AddDate(0, 0, -daysAgo).Format("2006-01-02") }
+
+	repo.AddDocument("users/user1/transactions/t1", map[string]interface{}{
+		"goalId": "trip", "amount": 1000.0, "isIncome": true, "date": day(30),
+	})
+	repo.AddDocument("users/user1/transactions/t2", map[string]interface{}{
+		"goalId": "trip", "signedAmount": -800.0, "date": day(10),
+	})
+	// Spending out of the savings counts against the goal
+	repo.AddDocument("users/user1/transactions/t3", map[string]interface{}{
+		"goalId": "trip", "amount": int64(300), "date": day(5),
+	})
+	// Counts towards the total but is too old for the pace
+	repo.AddDocument("users/user1/transactions/t4", map[string]interface{}{
+		"goalId": "trip", "amount": 2000.0, "isIncome": true, "date": day(200),
+	})
+	// Untagged and tagged to another goal
+	repo.AddDocument("users/user1/transactions/t5", map[string]interface{}{
+		"amount": 999.0, "isIncome": true, "date": day(1),
+	})
+	repo.AddDocument("users/user1/transactions/t6", map[string]interface{}{
+		"goalId": "groceries", "amount": 120.0, "date": day(1),
+	})
+
+	progress, err := service.FinancialProgress(context.Background(), "user1", "trip")
+	require.NoError(t, err)
+
+	assert.Equal(t, "trip", progress.GoalID)
+	assert.Equal(t, GoalDirectionSave, progress.Direction)
+	assert.Equal(t, 4, progress.Transactions)
+	assert.Equal(t, 3800.0, progress.Inflows)
+	assert.Equal(t, 300.0, progress.Outflows)
+	assert.Equal(t, 3500.0, progress.Amount)
+	assert.Equal(t, 1500.0, progress.Remaining)
+	assert.Equal(t, 70.0, progress.ProgressPercent)
+
+	// 1500 saved over the last 90 days leaves 1500 to go at the same pace
+	assert.Equal(t, 16.67, progress.PacePerDay)
+	require.NotNil(t, progress.ProjectedCompletion)
+	assert.WithinDuration(t, now.AddDate(0, 0, 90), *progress.ProjectedCompletion, time.Minute)
+}
+
+func TestGoalService_FinancialProgress_SpendingGoal(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service, repo := newTestGoalFinanceService(now)
+
+	repo.AddDocument("users/user1/transactions/t1", map[string]interface{}{
+		"goalId": "groceries", "amount": 250.0, "date": "2024-05-20",
+	})
+	repo.AddDocument("users/user1/transactions/t2", map[string]interface{}{
+		"goalId": "groceries", "amount": 250.0, "date": "2024-05-25",
+	})
+	// A refund reduces the spend
+	repo.AddDocument("users/user1/transactions/t3", map[string]interface{}{
+		"goalId": "groceries", "amount": 20.0, "isIncome": true, "date": "2024-05-26",
+	})
+
+	progress, err := service.FinancialProgress(context.Background(), "user1", "groceries")
+	require.NoError(t, err)
+	assert.Equal(t, GoalDirectionSpend, progress.Direction)
+	assert.Equal(t, 480.0, progress.Amount)
+	assert.Equal(t, 0.0, progress.Remaining)
+	assert.Equal(t, 100.0, progress.ProgressPercent)
+	assert.Nil(t, progress.ProjectedCompletion)
+}
+
+func TestGoalService_FinancialProgress_RequiresFinancialGoal(t *testing.T) {
+	service, repo := newTestGoalFinanceService(time.Now())
+	repo.AddDocument("users/user1/goals/no-target", map[string]interface{}{
+		"id": "no-target", "type": "financial",
+	})
+	ctx := context.Background()
+
+	_, err := service.FinancialProgress(ctx, "user1", "books")
+	assert.ErrorIs(t, err, ErrNotFinancialGoal)
+	_, err = service.FinancialProgress(ctx, "user1", "no-target")
+	assert.ErrorIs(t, err, ErrNotFinancialGoal)
+	_, err = service.FinancialProgress(ctx, "user1", "missing")
+	assert.ErrorIs(t, err, ErrGoalNotFound)
+}
+
+func TestGoalService_TagTransaction(t *testing.T) {
+	service, repo := newTestGoalFinanceService(time.Now())
+	repo.AddDocument("users/user1/transactions/t1", map[string]interface{}{"amount": 50.0})
+	ctx := context.Background()
+
+	require.NoError(t, service.TagTransaction(ctx, "user1", "t1", "trip"))
+	assert.Equal(t, "trip", repo.Documents["users/user1/transactions/t1"]["goalId"])
+
+	assert.ErrorIs(t, service.TagTransaction(ctx, "user1", "t1", "books"), ErrNotFinancialGoal)
+	assert.ErrorIs(t, service.TagTransaction(ctx, "user1", "t1", "missing"), ErrGoalNotFound)
+	assert.ErrorIs(t, service.TagTransaction(ctx, "user1", "missing", "trip"), ErrTransactionNotFound)
+	assert.Equal(t, "trip", repo.Documents["users/user1/transactions/t1"]["goalId"])
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const defaultGoalAtRiskMargin = 0.15
+
+// Sources of a goal's progress
+const (
+	GoalProgressFromTasks  = "tasks"
+	GoalProgressFromManual = "manual"
+)
+
+// GoalProgress is how far a goal has come. Progress is a fraction (0-1) taken
+// from the goal's linked tasks, or from its manual progress (0-100) when no
+// tasks are linked.
+type GoalProgress struct {
+	Progress       float64 `json:"progress"`
+	Source         string  `json:"source"`
+	CompletedTasks int     `json:"completedTasks"`
+	TotalTasks     int     `json:"totalTasks"`
+}
+
+// GoalRisk is an active goal whose progress trails its deadline
+type GoalRisk struct {
+	GoalID   string `json:"goalId"`
+	Title    string `json:"title"`
+	Priority string `json:"priority,omitempty"`
+	GoalProgress
+	TargetDate          time.Time  `json:"targetDate"`
+	ExpectedProgress    float64    `json:"expectedProgress"`
+	Gap                 float64    `json:"gap"`
+	Overdue             bool       `json:"overdue"`
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"`
+}
+
+// GoalsAtRisk lists the at-risk goals, largest gap first. Evaluated counts the
+// active goals with a usable targetDate.
+type GoalsAtRisk struct {
+	Goals     []GoalRisk `json:"goals"`
+	Evaluated int        `json:"evaluated"`
+	Margin    float64    `json:"margin"`
+}
+
+// GoalService tracks goal progress against target dates
+type GoalService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	margin float64
+	now    func() time.Time
+}
+
+// NewGoalService creates a new goal service
+func NewGoalService(repo interfaces.Repository, logger *zap.Logger, cfg config.GoalsConfig) *GoalService {
+	if cfg.AtRiskMargin <= 0 || cfg.AtRiskMargin >= 1 {
+		cfg.AtRiskMargin = defaultGoalAtRiskMargin
+	}
+	return &GoalService{
+		repo:   repo,
+		logger: logger,
+		margin: cfg.AtRiskMargin,
+		now:    time.Now,
+	}
+}
+
+// AtRisk compares each active goal's progress with the share of time elapsed
+// between its creation and its targetDate, and returns the goals trailing by
+// more than the margin. Goals past their targetDate and not finished are
+// always at risk.
+func (s *GoalService) AtRisk(ctx context.Context, uid string) (*GoalsAtRisk, error) {
+	goals, err := s.repo.List(ctx, fmt.Sprintf("users/%s/goals", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+	projects, err := s.repo.List(ctx, fmt.Sprintf("users/%s/projects", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	tasks, err := s.repo.List(ctx, fmt.Sprintf("users/%s/tasks", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	linked := goalLinkedTasks(projects, tasks)
+	now := s.now()
+	result := &GoalsAtRisk{Goals: []GoalRisk{}, Margin: s.margin}
+	for _, goal := range goals {
+		if getStringField(goal, "status") != "active" {
+			continue
+		}
+		start, ok := parseFlexibleDate(goal["createdAt"])
+		if !ok {
+			continue
+		}
+		target, ok := parseFlexibleDate(goal["targetDate"])
+		if !ok || !target.After(start) {
+			continue
+		}
+		result.Evaluated++
+
+		id := getStringField(goal, "id")
+		risk, atRisk := assessGoalRisk(start, target, now, goalProgress(goal, linked[id]), s.margin)
+		if !atRisk {
+			continue
+		}
+		risk.GoalID = id
+		risk.Title = getStringField(goal, "title")
+		risk.Priority = getStringField(goal, "priority")
+		result.Goals = append(result.Goals, risk)
+	}
+
+	sort.SliceStable(result.Goals, func(i, j int) bool {
+		return result.Goals[i].Gap > result.Goals[j].Gap
+	})
+	return result, nil
+}
+
+// assessGoalRisk compares progress with elapsed time. The projected
+// completion extrapolates the average pace since start and is left nil when
+// there has been no progress.
+func assessGoalRisk(start, target, now time.Time, progress GoalProgress, margin float64) (GoalRisk, bool) {
+	elapsed := now.Sub(start)
+	expected := math.Min(math.Max(elapsed.Seconds()/target.Sub(start).Seconds(), 0), 1)
+
+	risk := GoalRisk{
+		GoalProgress:     progress,
+		TargetDate:       target,
+		ExpectedProgress: roundFraction(expected),
+		Gap:              roundFraction(expected - progress.Progress),
+		Overdue:          now.After(target) && progress.Progress < 1,
+	}
+	if progress.Progress > 0 && progress.Progress < 1 && elapsed > 0 {
+		projected := start.Add(time.Duration(float64(elapsed) / progress.Progress))
+		risk.ProjectedCompletion = &projected
+	}
+	// Compare the rounded gap so a gap equal to the margin is not at risk
+	return risk, risk.Overdue || risk.Gap > margin
+}
+
+// goalProgress measures a goal by its linked tasks, falling back to the
+// goal's own progress field
+func goalProgress(goal map[string]interface{}, tasks []map[string]interface{}) GoalProgress {
+	if len(tasks) > 0 {
+		progress := GoalProgress{Source: GoalProgressFromTasks, TotalTasks: len(tasks)}
+		for _, task := range tasks {
+			if taskDone(task) {
+				progress.CompletedTasks++
+			}
+		}
+		progress.Progress = roundFraction(float64(progress.CompletedTasks) / float64(progress.TotalTasks))
+		return progress
+	}
+
+	manual, _ := toFloat(goal["progress"])
+	return GoalProgress{
+		Progress: roundFraction(math.Min(math.Max(manual, 0), 100) / 100),
+		Source:   GoalProgressFromManual,
+	}
+}
+
+// goalLinkedTasks groups tasks by goal. A task counts towards a goal when it
+// names the goal itself or belongs to a project, or a sub-project of one,
+// that names the goal.
+func goalLinkedTasks(projects, tasks []map[string]interface{}) map[string][]map[string]interface{} {
+	projectsByID := make(map[string]map[string]interface{}, len(projects))
+	for _, project := range projects {
+		projectsByID[getStringField(project, "id")] = project
+	}
+	projectGoals := func(projectID string) []string {
+		// Walk up to the first project linked to goals; parents form a tree,
+		// but a cycle in bad data must not loop forever
+		for seen := map[string]bool{}; projectID != "" && !seen[projectID]; {
+			seen[projectID] = true
+			project, ok := projectsByID[projectID]
+			if !ok {
+				return nil
+			}
+			goalIDs := append([]string{}, toStringSlice(project["goalIds"])...)
+			if goalID := getStringField(project, "goalId"); goalID != "" {
+				goalIDs = append(goalIDs, goalID)
+			}
+			if len(goalIDs) > 0 {
+				return goalIDs
+			}
+			projectID = getStringField(project, "parentProjectId")
+		}
+		return nil
+	}
+
+	linked := map[string][]map[string]interface{}{}
+	for _, task := range tasks {
+		goalIDs := map[string]bool{}
+		if goalID := getStringField(task, "goalId"); goalID != "" {
+			goalIDs[goalID] = true
+		}
+		for _, goalID := range projectGoals(getStringField(task, "projectId")) {
+			goalIDs[goalID] = true
+		}
+		for goalID := range goalIDs {
+			linked[goalID] = append(linked[goalID], task)
+		}
+	}
+	return linked
+}
+
+func taskDone(task map[string]interface{}) bool {
+	if done, _ := task["done"].(bool); done {
+		return true
+	}
+	return getStringField(task, "status") == "completed"
+}
+
+// roundFraction rounds a 0-1 fraction to four decimal places
+func roundFraction(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
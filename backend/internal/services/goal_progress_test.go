@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestAssessGoalRisk_Threshold(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := start.Add(100 * 24 * time.Hour)
+	halfway := start.Add(50 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		progress   float64
+		wantAtRisk bool
+		wantGap    float64
+	}{
+		{"on pace", halfway, 0.5, false, 0},
+		{"ahead", halfway, 0.8, false, -0.3},
+		{"behind within margin", halfway, 0.4, false, 0.1},
+		{"behind exactly at margin", halfway, 0.35, false, 0.15},
+		{"behind past margin", halfway, 0.34, true, 0.16},
+		{"not started yet", start.Add(-time.Hour), 0, false, 0},
+		{"overdue and unfinished", target.Add(time.Hour), 0.95, true, 0.05},
+		{"overdue but finished", target.Add(time.Hour), 1, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk, atRisk := assessGoalRisk(start, target, tt.now, GoalProgress{Progress: tt.progress}, 0.15)
+			assert.Equal(t, tt.wantAtRisk, atRisk)
+			assert.InDelta(t, tt.wantGap, risk.Gap, 1e-9)
+		})
+	}
+}
+
+func TestAssessGoalRisk_ProjectedCompletion(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := start.Add(100 * 24 * time.Hour)
+	now := start.Add(50 * 24 * time.Hour)
+
+	// A quarter done in 50 days projects completion at day 200
+	risk, _ := assessGoalRisk(start, target, now, GoalProgress{Progress: 0.25}, 0.15)
+	require.NotNil(t, risk.ProjectedCompletion)
+	assert.Equal(t, start.Add(200*24*time.Hour), *risk.ProjectedCompletion)
+
+	// No progress means no pace to extrapolate
+	risk, _ = assessGoalRisk(start, target, now, GoalProgress{}, 0.15)
+	assert.Nil(t, risk.ProjectedCompletion)
+}
+
+func TestGoalService_AtRisk(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	uid := "user1"
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-30 * 24 * time.Hour)
+	target := now.Add(30 * 24 * time.Hour)
+
+	// Halfway to the deadline with one of four linked tasks done
+	repo.AddDocument("users/user1/goals/g1", map[string]interface{}{
+		"id": "g1", "title": "Ship v2", "status": "active", "createdAt": created, "targetDate": target.Format("2006-01-02"),
+	})
+	// Halfway with manual progress on pace
+	repo.AddDocument("users/user1/goals/g2", map[string]interface{}{
+		"id": "g2", "title": "Read 10 books", "status": "active", "createdAt": created, "targetDate": target, "progress": int64(50),
+	})
+	// Past its deadline
+	repo.AddDocument("users/user1/goals/g3", map[string]interface{}{
+		"id": "g3", "title": "Tax return", "status": "active", "createdAt": created.Add(-30 * 24 * time.Hour), "targetDate": now.Add(-24 * time.Hour), "progress": 90.0,
+	})
+	// Not evaluated: no deadline, or not active
+	repo.AddDocument("users/user1/goals/g4", map[string]interface{}{
+		"id": "g4", "title": "Someday", "status": "active", "createdAt": created,
+	})
+	repo.AddDocument("users/user1/goals/g5", map[string]interface{}{
+		"id": "g5", "title": "Paused", "status": "paused", "createdAt": created, "targetDate": target,
+	})
+
+	repo.AddDocument("users/user1/projects/p1", map[string]interface{}{"id": "p1", "goalId": "g1"})
+	repo.AddDocument("users/user1/projects/p2", map[string]interface{}{"id": "p2", "parentProjectId": "p1"})
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"id": "t1", "goalId": "g1", "done": true})
+	repo.AddDocument("users/user1/tasks/t2", map[string]interface{}{"id": "t2", "projectId": "p1"})
+	repo.AddDocument("users/user1/tasks/t3", map[string]interface{}{"id": "t3", "projectId": "p2", "status": "active"})
+	repo.AddDocument("users/user1/tasks/t4", map[string]interface{}{"id": "t4", "projectId": "p2", "done": false})
+
+	service := NewGoalService(repo, zap.NewNop(), config.GoalsConfig{AtRiskMargin: 0.2})
+	service.now = func() time.Time { return now }
+
+	result, err := service.AtRisk(context.Background(), uid)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Evaluated)
+	assert.Equal(t, 0.2, result.Margin)
+	require.Len(t, result.Goals, 2)
+
+	shipping := result.Goals[0]
+	assert.Equal(t, "g1", shipping.GoalID)
+	assert.Equal(t, GoalProgressFromTasks, shipping.Source)
+	assert.Equal(t, 1, shipping.CompletedTasks)
+	assert.Equal(t, 4, shipping.TotalTasks)
+	assert.Equal(t, 0.25, shipping.Progress)
+	assert.Equal(t, 0.5, shipping.ExpectedProgress)
+	assert.Equal(t, 0.25, shipping.Gap)
+	assert.False(t, shipping.Overdue)
+	require.NotNil(t, shipping.ProjectedCompletion)
+	assert.Equal(t, created.Add(120*24*time.Hour), *shipping.ProjectedCompletion)
+
+	taxes := result.Goals[1]
+	assert.Equal(t, "g3", taxes.GoalID)
+	assert.Equal(t, GoalProgressFromManual, taxes.Source)
+	assert.True(t, taxes.Overdue)
+	assert.InDelta(t, 0.1, taxes.Gap, 1e-9)
+}
+
+func TestNewGoalService_DefaultMargin(t *testing.T) {
+	for _, margin := range []float64{0, -0.1, 1, 2} {
+		service := NewGoalService(mocks.NewMockRepository(), zap.NewNop(), config.GoalsConfig{AtRiskMargin: margin})
+		assert.Equal(t, defaultGoalAtRiskMargin, service.margin)
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Import sources with a built-in adapter
+const (
+	ImportSourceTodoist = "todoist"
+	ImportSourceDayOne  = "dayone"
+)
+
+var (
+	// ErrUnknownImportSource is returned for a source with no adapter
+	ErrUnknownImportSource = errors.New("unknown import source")
+	// ErrInvalidForeignImport is returned when a file does not match its source's format
+	ErrInvalidForeignImport = errors.New("invalid import file for source")
+)
+
+// ImportAdapter converts another app's export into the native import shape
+// so it can go through the usual validate and execute steps. Converted
+// entities get IDs derived from the source file, so importing the same file
+// twice reports duplicates instead of creating copies.
+type ImportAdapter interface {
+	Convert(data []byte) (*ImportData, error)
+}
+
+var importAdapters = map[string]ImportAdapter{
+	ImportSourceTodoist: todoistCSVAdapter{},
+	ImportSourceDayOne:  dayOneJSONAdapter{},
+}
+
+// ValidateForeignImport converts a file exported by another app and validates
+// the result like a native import. The converted data is returned in
+// ParsedData for ExecuteImport.
+func (s *ImportExportService) ValidateForeignImport(ctx context.Context, uid, source string, data []byte) (*ValidationResult, error) {
+	adapter, ok := importAdapters[strings.ToLower(source)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownImportSource, source)
+	}
+	importData, err := adapter.Convert(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.validateImportData(ctx, uid, *importData)
+}
+
+func newAdaptedImportData(source string) *ImportData {
+	return &ImportData{
+		Metadata: ExportMetadata{
+			Version:     "1.0",
+			ExportedAt:  time.Now(),
+			Description: "Converted from " + source,
+		},
+	}
+}
+
+// adaptedID derives a stable entity ID from the parts of a source record
+func adaptedID(source string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return source + "-" + hex.EncodeToString(sum[:8])
+}
+
+// todoistCSVAdapter reads a Todoist-style tasks CSV. Columns are matched by
+// name, case-insensitively: CONTENT (the title) is required; TYPE,
+// DESCRIPTION, PRIORITY and DATE are optional. Rows whose TYPE is not "task",
+// such as sections and notes, are skipped.
+type todoistCSVAdapter struct{}
+
+// todoistPriorities maps Todoist's p1-p4 onto native priorities
+var todoistPriorities = map[string]string{
+	"1": "urgent",
+	"2": "high",
+	"3": "medium",
+	"4": "low",
+}
+
+func (todoistCSVAdapter) Convert(data []byte) (*ImportData, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: missing header: %v", ErrInvalidForeignImport, ImportSourceTodoist, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, fmt.Errorf("%w: %s: no CONTENT column", ErrInvalidForeignImport, ImportSourceTodoist)
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	importData := newAdaptedImportData(ImportSourceTodoist)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: row %d: %v", ErrInvalidForeignImport, ImportSourceTodoist, row, err)
+		}
+		if recordType := strings.ToLower(field(record, "TYPE")); recordType != "" && recordType != "task" {
+			continue
+		}
+		title := field(record, "CONTENT")
+		if title == "" {
+			continue
+		}
+
+		task := map[string]interface{}{
+			"id":       adaptedID(ImportSourceTodoist, fmt.Sprint(row), strings.Join(record, "\x1f")),
+			"title":    title,
+			"done":     false,
+			"status":   "active",
+			"priority": "medium",
+		}
+		if notes := field(record, "DESCRIPTION"); notes != "" {
+			task["notes"] = notes
+		}
+		if priority, ok := todoistPriorities[field(record, "PRIORITY")]; ok {
+			task["priority"] = priority
+		}
+		// Recurring and natural-language dates ("every monday") are dropped
+		if due, ok := parseFlexibleDate(field(record, "DATE")); ok {
+			task["dueDate"] = due.Format("2006-01-02")
+		}
+		importData.Entities.Tasks = append(importData.Entities.Tasks, task)
+	}
+
+	importData.Metadata.TotalItems = len(importData.Entities.Tasks)
+	return importData, nil
+}
+
+// dayOneJSONAdapter reads a Day One-style journal export: an object with an
+// "entries" array whose entries have uuid, text, creationDate and tags.
+// Entries become thoughts.
+type dayOneJSONAdapter struct{}
+
+type dayOneExport struct {
+	Entries []struct {
+		UUID         string   `json:"uuid"`
+		Text         string   `json:"text"`
+		CreationDate string   `json:"creationDate"`
+		Tags         []string `json:"tags"`
+	} `json:"entries"`
+}
+
+func (dayOneJSONAdapter) Convert(data []byte) (*ImportData, error) {
+	var export dayOneExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidForeignImport, ImportSourceDayOne, err)
+	}
+	if export.Entries == nil {
+		return nil, fmt.Errorf("%w: %s: no entries", ErrInvalidForeignImport, ImportSourceDayOne)
+	}
+
+	importData := newAdaptedImportData(ImportSourceDayOne)
+	for i, entry := range export.Entries {
+		text := strings.TrimSpace(entry.Text)
+		if text == "" {
+			continue
+		}
+		id := strings.ToLower(entry.UUID)
+		if id == "" {
+			id = fmt.Sprint(i)
+		}
+		thought := map[string]interface{}{
+			"id":   adaptedID(ImportSourceDayOne, id),
+			"text": text,
+		}
+		if tags := dedupeStrings(entry.Tags); len(tags) > 0 {
+			thought["tags"] = tags
+		}
+		if created, ok := parseFlexibleDate(entry.CreationDate); ok {
+			thought["createdAt"] = created.UTC().Format(time.RFC3339)
+		}
+		importData.Entities.Thoughts = append(importData.Entities.Thoughts, thought)
+	}
+
+	importData.Metadata.TotalItems = len(importData.Entities.Thoughts)
+	return importData, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestTodoistCSVAdapter_Convert(t *testing.T) {
+	csvData := "\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,DATE\n" +
+		"section,Errands,,,,\n" +
+		"task,Buy milk,Semi-skimmed,1,1,2024-05-01\n" +
+		"task,Water plants,,4,1,every monday\n" +
+		"note,Remember the oat milk,,,,\n" +
+		"task,,,,,\n"
+
+	importData, err := todoistCSVAdapter{}.Convert([]byte(csvData))
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", importData.Metadata.Version)
+	assert.Equal(t, 2, importData.Metadata.TotalItems)
+	require.Len(t, importData.Entities.Tasks, 2)
+
+	milk := importData.Entities.Tasks[0]
+	assert.Equal(t, "Buy milk", milk["title"])
+	assert.Equal(t, "Semi-skimmed", milk["notes"])
+	assert.Equal(t, "urgent", milk["priority"])
+	assert.Equal(t, "2024-05-01", milk["dueDate"])
+	assert.Equal(t, false, milk["done"])
+	assert.Contains(t, milk["id"], "todoist-")
+
+	plants := importData.Entities.Tasks[1]
+	assert.Equal(t, "low", plants["priority"])
+	assert.NotContains(t, plants, "dueDate")
+	assert.NotEqual(t, milk["id"], plants["id"])
+
+	// Converting the same file again gives the same IDs
+	again, err := todoistCSVAdapter{}.Convert([]byte(csvData))
+	require.NoError(t, err)
+	assert.Equal(t, milk["id"], again.Entities.Tasks[0]["id"])
+}
+
+func TestTodoistCSVAdapter_MissingContentColumn(t *testing.T) {
+	_, err := todoistCSVAdapter{}.Convert([]byte("Title,Due\nBuy milk,2024-05-01\n"))
+	assert.ErrorIs(t, err, ErrInvalidForeignImport)
+
+	_, err = todoistCSVAdapter{}.Convert(nil)
+	assert.ErrorIs(t, err, ErrInvalidForeignImport)
+}
+
+func TestDayOneJSONAdapter_Convert(t *testing.T) {
+	jsonData := `{
+		"metadata": {"version": "1.0"},
+		"entries": [
+			{"uuid": "A1B2", "text": "  Walked to the lake  ", "creationDate": "2023-07-04T18:30:00Z", "tags": ["walk", "walk", "summer"]},
+			{"uuid": "C3D4", "text": ""},
+			{"text": "No uuid", "creationDate": "not a date"}
+		]
+	}`
+
+	importData, err := dayOneJSONAdapter{}.Convert([]byte(jsonData))
+	require.NoError(t, err)
+	assert.Equal(t, 2, importData.Metadata.TotalItems)
+	require.Len(t, importData.Entities.Thoughts, 2)
+
+	walk := importData.Entities.Thoughts[0]
+	assert.Equal(t, adaptedID(ImportSourceDayOne, "a1b2"), walk["id"])
+	assert.Equal(t, "Walked to the lake", walk["text"])
+	assert.Equal(t, []string{"walk", "summer"}, walk["tags"])
+	assert.Equal(t, "2023-07-04T18:30:00Z", walk["createdAt"])
+
+	noUUID := importData.Entities.Thoughts[1]
+	assert.NotEmpty(t, noUUID["id"])
+	assert.NotContains(t, noUUID, "createdAt")
+	assert.NotContains(t, noUUID, "tags")
+}
+
+func TestDayOneJSONAdapter_InvalidFile(t *testing.T) {
+	_, err := dayOneJSONAdapter{}.Convert([]byte(`{"journal": []}`))
+	assert.ErrorIs(t, err, ErrInvalidForeignImport)
+
+	_, err = dayOneJSONAdapter{}.Convert([]byte("TYPE,CONTENT\n"))
+	assert.ErrorIs(t, err, ErrInvalidForeignImport)
+}
+
+func TestImportExportService_ValidateForeignImport_UnknownSource(t *testing.T) {
+	service := NewImportExportService(mocks.NewMockRepository(), zap.NewNop(), 0, nil, 0, 0, nil)
+
+	_, err := service.ValidateForeignImport(context.Background(), "user1", "evernote", []byte("{}"))
+	assert.ErrorIs(t, err, ErrUnknownImportSource)
+}
@@ -3,28 +3,101 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"google.golang.org/api/iterator"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
 
+// ErrExportTooLarge is returned when an export exceeds the user's plan limits
+var ErrExportTooLarge = errors.New("export too large")
+
+// ErrReplaceNotConfirmed is returned for a ReplaceAll import without ConfirmReplaceAll
+var ErrReplaceNotConfirmed = errors.New("replaceAll import requires confirmReplaceAll")
+
+// MaxConsistentExportItems caps exports that read all collections in a single
+// read-only transaction. A transaction must finish inside Firestore's
+// transaction time limit, so larger exports fall back to eventually-consistent
+// reads.
+const MaxConsistentExportItems = 20000
+
+const (
+	// defaultExportSummaryConcurrency bounds the collection reads an export
+	// summary runs at once
+	defaultExportSummaryConcurrency = 4
+	// defaultExportSummaryCacheTTL keeps a summary long enough for the export
+	// preview screen to re-render without re-reading every collection
+	defaultExportSummaryCacheTTL = 30 * time.Second
+)
+
 // ImportExportService handles import/export operations
 type ImportExportService struct {
-	repo   interfaces.Repository
-	logger *zap.Logger
+	repo         interfaces.Repository
+	logger       *zap.Logger
+	batchSize    int
+	exportLimits map[string]config.ExportLimit
+	// consistentExportMaxItems defaults to MaxConsistentExportItems
+	consistentExportMaxItems int
+	summaryConcurrency       int
+	summaryCacheTTL          time.Duration
+	anonymousQuota           AnonymousQuotaConsumer
+	now                      func() time.Time
+
+	summaryMu    sync.Mutex
+	summaryCache map[string]cachedExportSummary
+}
+
+type cachedExportSummary struct {
+	summary   ExportSummary
+	expiresAt time.Time
 }
 
-// NewImportExportService creates a new import/export service
-func NewImportExportService(repo interfaces.Repository, logger *zap.Logger) *ImportExportService {
+// NewImportExportService creates a new import/export service.
+// batchSize is capped at the Firestore limit of 500; values <= 0 use the limit.
+// exportLimits is keyed by subscription tier; nil disables export limits.
+// summaryConcurrency and summaryCacheTTL <= 0 use the export summary defaults.
+// Imports by anonymous sessions are charged to anonymousQuota per document;
+// nil disables the charge.
+func NewImportExportService(
+	repo interfaces.Repository,
+	logger *zap.Logger,
+	batchSize int,
+	exportLimits map[string]config.ExportLimit,
+	summaryConcurrency int,
+	summaryCacheTTL time.Duration,
+	anonymousQuota AnonymousQuotaConsumer,
+) *ImportExportService {
+	if batchSize <= 0 || batchSize > importBatchLimit {
+		batchSize = importBatchLimit
+	}
+	if summaryConcurrency <= 0 {
+		summaryConcurrency = defaultExportSummaryConcurrency
+	}
+	if summaryCacheTTL <= 0 {
+		summaryCacheTTL = defaultExportSummaryCacheTTL
+	}
 	return &ImportExportService{
-		repo:   repo,
-		logger: logger,
+		repo:                     repo,
+		logger:                   logger,
+		batchSize:                batchSize,
+		exportLimits:             exportLimits,
+		consistentExportMaxItems: MaxConsistentExportItems,
+		summaryConcurrency:       summaryConcurrency,
+		summaryCacheTTL:          summaryCacheTTL,
+		anonymousQuota:           anonymousQuota,
+		now:                      time.Now,
+		summaryCache:             make(map[string]cachedExportSummary),
 	}
 }
 
@@ -45,6 +118,42 @@ const (
 	ImportExportEntityTypeLLMLogs       ImportExportEntityType = "llmLogs"
 )
 
+// allExportEntityTypes are exported when a request does not name any
+var allExportEntityTypes = []EntityType{
+	EntityTypeTasks, EntityTypeProjects, EntityTypeGoals,
+	EntityTypeThoughts, EntityTypeMoods, EntityTypeFocusSessions,
+	EntityTypePeople, EntityTypePortfolios, EntityTypeSpending,
+	EntityTypeRelationships, EntityTypeLLMLogs,
+}
+
+// exportCollections maps entity types to their top-level Firestore collections
+var exportCollections = map[EntityType]string{
+	EntityTypeTasks:         "tasks",
+	EntityTypeProjects:      "projects",
+	EntityTypeGoals:         "goals",
+	EntityTypeThoughts:      "thoughts",
+	EntityTypeMoods:         "moods",
+	EntityTypeFocusSessions: "focusSessions",
+	EntityTypePeople:        "people",
+	EntityTypePortfolios:    "portfolios",
+	EntityTypeSpending:      "transactions",
+	EntityTypeRelationships: "entityRelationships",
+	EntityTypeLLMLogs:       "llmLogs",
+}
+
+// exportDateFields is the field startDate/endDate filters apply to; types
+// without one ignore date filters
+var exportDateFields = map[EntityType]string{
+	EntityTypeTasks:         "createdAt",
+	EntityTypeProjects:      "createdAt",
+	EntityTypeGoals:         "createdAt",
+	EntityTypeThoughts:      "createdAt",
+	EntityTypeMoods:         "date",
+	EntityTypeFocusSessions: "startedAt",
+	EntityTypeSpending:      "date",
+	EntityTypeLLMLogs:       "timestamp",
+}
+
 // EntityCollection represents a collection of entities
 type EntityCollection struct {
 	Tasks         []map[string]interface{} `json:"tasks,omitempty"`
@@ -68,6 +177,10 @@ type ExportMetadata struct {
 	TotalItems  int       `json:"totalItems"`
 	AppVersion  string    `json:"appVersion,omitempty"`
 	Description string    `json:"description,omitempty"`
+	// Consistent reports that every collection was read from one snapshot,
+	// taken at ReadTime
+	Consistent bool       `json:"consistent"`
+	ReadTime   *time.Time `json:"readTime,omitempty"`
 }
 
 // ImportData represents the structure of import data
@@ -115,6 +228,11 @@ type ImportOptions struct {
 	SkipConflicts      bool                    `json:"skipConflicts"`
 	Selection          map[EntityType][]string `json:"selection"`          // Entity IDs to import per type
 	ConflictResolution map[string]string       `json:"conflictResolution"` // Entity ID -> resolution action
+	// ReplaceAll deletes all of the user's existing documents of each imported
+	// entity type before importing, restoring a backup as a full replace.
+	// It is refused unless ConfirmReplaceAll is also set.
+	ReplaceAll        bool `json:"replaceAll"`
+	ConfirmReplaceAll bool `json:"confirmReplaceAll"`
 }
 
 // ImportResult represents the result of import execution
@@ -124,7 +242,32 @@ type ImportResult struct {
 	SkippedCount  int                `json:"skippedCount"`
 	ErrorCount    int                `json:"errorCount"`
 	ByType        map[EntityType]int `json:"byType"`
-	Errors        []string           `json:"errors,omitempty"`
+	Errors        []ImportError      `json:"errors,omitempty"`
+	// JobID and the deleted counts are only set for ReplaceAll imports
+	JobID         string             `json:"jobId,omitempty"`
+	DeletedCount  int                `json:"deletedCount,omitempty"`
+	DeletedByType map[EntityType]int `json:"deletedByType,omitempty"`
+}
+
+// ImportError identifies a record that failed to import and why
+type ImportError struct {
+	EntityType EntityType `json:"entityType"`
+	EntityID   string     `json:"entityId"`
+	Message    string     `json:"message"`
+}
+
+// String flattens the error for logging
+func (e ImportError) String() string {
+	return fmt.Sprintf("Failed to import %s %s: %s", e.EntityType, e.EntityID, e.Message)
+}
+
+// ErrorMessages returns the flattened import errors, for logging
+func (r *ImportResult) ErrorMessages() []string {
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.String()
+	}
+	return messages
 }
 
 // ExportFilters represents filters for data export
@@ -143,6 +286,22 @@ type ExportFilters struct {
 
 	// Goal filters
 	GoalStatus []string `json:"goalStatus,omitempty"`
+
+	// Mood filters (mood value, 1-10)
+	MoodMin *float64 `json:"moodMin,omitempty"`
+	MoodMax *float64 `json:"moodMax,omitempty"`
+
+	// Focus session filters
+	FocusMinDuration *float64 `json:"focusMinDuration,omitempty"` // Minutes
+	FocusMinRating   *float64 `json:"focusMinRating,omitempty"`
+
+	// People filters (relationship type, e.g. family, friend, colleague)
+	PeopleCategory []string `json:"peopleCategory,omitempty"`
+
+	// Consistent reads every collection from the same snapshot, so
+	// references between exported documents are not broken by concurrent
+	// writes. Exports over MaxConsistentExportItems are read without it.
+	Consistent bool `json:"consistent,omitempty"`
 }
 
 // ExportSummary represents summary statistics for export preview
@@ -210,6 +369,15 @@ type ExportSummary struct {
 		Failed      int `json:"failed"`
 		TotalTokens int `json:"totalTokens"`
 	} `json:"llmLogs"`
+	// TotalItems is the size of an unfiltered export
+	TotalItems int `json:"totalItems"`
+	// ExportLimit is the user's plan limit (zero = unlimited); Exceeded warns
+	// that an unfiltered export would be rejected
+	ExportLimit struct {
+		MaxItems              int  `json:"maxItems"`
+		MaxItemsPerCollection int  `json:"maxItemsPerCollection"`
+		Exceeded              bool `json:"exceeded"`
+	} `json:"exportLimit"`
 }
 
 // ValidateImport validates import data and detects conflicts
@@ -225,6 +393,12 @@ func (s *ImportExportService) ValidateImport(ctx context.Context, uid string, da
 		return nil, fmt.Errorf("missing metadata version")
 	}
 
+	return s.validateImportData(ctx, uid, importData)
+}
+
+// validateImportData checks parsed import data against the user's existing
+// documents
+func (s *ImportExportService) validateImportData(ctx context.Context, uid string, importData ImportData) (*ValidationResult, error) {
 	result := &ValidationResult{
 		Valid:      true,
 		Conflicts:  []Conflict{},
@@ -273,23 +447,17 @@ func (s *ImportExportService) ValidateImport(ctx context.Context, uid string, da
 
 		// Query existing documents for this user
 		query := s.repo.Collection(collection).Where("uid", "==", uid).Select("id")
-		iter := query.Documents(ctx)
-		defer iter.Stop()
-
-		for {
-			doc, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				s.logger.Warn("Error fetching existing IDs",
-					zap.String("collection", collection),
-					zap.Error(err),
-				)
-				continue
-			}
+		docs, err := s.repo.CollectAll(ctx, query)
+		if err != nil {
+			s.logger.Warn("Error fetching existing IDs",
+				zap.String("collection", collection),
+				zap.Error(err),
+			)
+			continue
+		}
 
-			if idVal, ok := doc.Data()["id"]; ok {
+		for _, data := range docs {
+			if idVal, ok := data["id"]; ok {
 				if idStr, ok := idVal.(string); ok {
 					existingIDs[entityType][idStr] = true
 				}
@@ -497,6 +665,20 @@ func (s *ImportExportService) getString(m map[string]interface{}, key string) st
 	return ""
 }
 
+func (s *ImportExportService) getFloat(m map[string]interface{}, key string) (float64, bool) {
+	if val, ok := m[key]; ok {
+		switch v := val.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		case int:
+			return float64(v), true
+		}
+	}
+	return 0, false
+}
+
 func (s *ImportExportService) getStringArray(m map[string]interface{}, key string) []string {
 	if val, ok := m[key]; ok {
 		if arr, ok := val.([]interface{}); ok {
@@ -512,25 +694,189 @@ func (s *ImportExportService) getStringArray(m map[string]interface{}, key strin
 	return nil
 }
 
-// ExecuteImport executes the import with the given options
+// ExecuteImport executes the import with the given options. By default it
+// merges into existing data; with ReplaceAll (and ConfirmReplaceAll) it runs
+// as a tracked job that first deletes the user's existing documents of each
+// imported entity type.
 func (s *ImportExportService) ExecuteImport(
 	ctx context.Context,
 	uid string,
 	data *ImportData,
 	options ImportOptions,
 ) (*ImportResult, error) {
+	if options.ReplaceAll && !options.ConfirmReplaceAll {
+		return nil, ErrReplaceNotConfirmed
+	}
+
 	result := &ImportResult{
 		Success: true,
 		ByType:  make(map[EntityType]int),
-		Errors:  []string{},
+		Errors:  []ImportError{},
 	}
 
-	// Determine import order based on dependencies
-	importOrder := []struct {
-		entityType EntityType
-		collection string
-		entities   []map[string]interface{}
-	}{
+	plan := s.buildImportPlan(data, options)
+
+	// Anonymous sessions pay for every document up front, before anything
+	// is deleted or written
+	documents := 0
+	for _, item := range plan {
+		documents += len(item.entities)
+	}
+	if err := consumeAnonymousQuota(ctx, s.anonymousQuota, AnonymousQuotaDocuments, documents); err != nil {
+		return nil, err
+	}
+
+	var job *importJob
+	if options.ReplaceAll {
+		job = s.startImportJob(ctx, uid, plan, result)
+		if !s.deleteReplacedEntities(ctx, uid, plan, job, result) {
+			s.finishImportJob(ctx, uid, job, result)
+			s.InvalidateExportSummary(uid)
+			return result, nil
+		}
+	}
+
+	// Rename IDs and rewrite references before anything is written
+	linked := s.applyIDRemap(plan, options)
+	if len(linked) > 0 {
+		if s.writeLinkedEntities(ctx, uid, plan, linked, result) {
+			// Linked entities were committed atomically; skip them below
+			for i := range plan {
+				plan[i].entities = s.excludeIDs(plan[i].entities, linked[plan[i].entityType])
+			}
+		}
+	}
+
+	// Import each entity type in batches
+	for _, item := range plan {
+		for i := 0; i < len(item.entities); i += s.batchSize {
+			end := i + s.batchSize
+			if end > len(item.entities) {
+				end = len(item.entities)
+			}
+			s.importBatch(ctx, uid, item, item.entities[i:end], result)
+		}
+	}
+
+	if job != nil {
+		s.finishImportJob(ctx, uid, job, result)
+	}
+	s.InvalidateExportSummary(uid)
+	return result, nil
+}
+
+// importBatchLimit is the maximum number of writes in a single Firestore batch
+const importBatchLimit = 500
+
+// importBatch writes entities in a single batch, falling back to per-document
+// writes if the batch fails so one bad document does not fail the rest
+func (s *ImportExportService) importBatch(
+	ctx context.Context,
+	uid string,
+	item importPlanItem,
+	entities []map[string]interface{},
+	result *ImportResult,
+) {
+	batch := s.repo.Batch()
+	written := 0
+	for _, entity := range entities {
+		id := s.getString(entity, "id")
+		if id == "" {
+			continue
+		}
+
+		docRef := s.repo.Collection(item.collection).Doc(id)
+		batch.Set(docRef, s.prepareImportEntity(entity, uid), firestore.MergeAll)
+		written++
+	}
+	if written == 0 {
+		return
+	}
+
+	if _, err := batch.Commit(ctx); err != nil {
+		s.logger.Warn("Import batch failed, retrying documents individually",
+			zap.String("entityType", string(item.entityType)),
+			zap.Int("count", written),
+			zap.Error(err),
+		)
+		s.importIndividually(ctx, uid, item, entities, result)
+		return
+	}
+
+	result.ImportedCount += written
+	result.ByType[item.entityType] += written
+}
+
+// importIndividually writes each entity on its own, recording per-document errors
+func (s *ImportExportService) importIndividually(
+	ctx context.Context,
+	uid string,
+	item importPlanItem,
+	entities []map[string]interface{},
+	result *ImportResult,
+) {
+	for _, entity := range entities {
+		id := s.getString(entity, "id")
+		if id == "" {
+			continue
+		}
+
+		path := fmt.Sprintf("%s/%s", item.collection, id)
+		if err := s.repo.SetDocument(ctx, path, s.prepareImportEntity(entity, uid)); err != nil {
+			result.Errors = append(result.Errors, ImportError{EntityType: item.entityType, EntityID: id, Message: err.Error()})
+			result.ErrorCount++
+			result.Success = false
+			s.logger.Error("Import document failed",
+				zap.String("entityType", string(item.entityType)),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		result.ImportedCount++
+		result.ByType[item.entityType]++
+	}
+}
+
+// ConflictResolutionCreateNew imports a conflicting entity under a newly generated ID
+const ConflictResolutionCreateNew = "create_new"
+
+// importPlanItem is one entity type's slice of an import, in dependency order
+type importPlanItem struct {
+	entityType EntityType
+	collection string
+	entities   []map[string]interface{}
+}
+
+// referenceField is a field on an imported entity holding the ID(s) of another entity
+type referenceField struct {
+	field  string
+	target EntityType
+}
+
+// importReferenceFields lists the references rewritten when imported IDs are renamed
+var importReferenceFields = map[EntityType][]referenceField{
+	EntityTypeTasks: {
+		{field: "projectId", target: EntityTypeProjects},
+		{field: "goalId", target: EntityTypeGoals},
+		{field: "linkedThoughtIds", target: EntityTypeThoughts},
+	},
+	EntityTypeProjects: {
+		{field: "goalId", target: EntityTypeGoals},
+		{field: "goalIds", target: EntityTypeGoals},
+		{field: "parentProjectId", target: EntityTypeProjects},
+		{field: "linkedThoughtIds", target: EntityTypeThoughts},
+	},
+	EntityTypeThoughts: {
+		{field: "linkedTaskIds", target: EntityTypeTasks},
+		{field: "linkedProjectIds", target: EntityTypeProjects},
+	},
+}
+
+// buildImportPlan orders entity types by dependency and applies the selection filter
+func (s *ImportExportService) buildImportPlan(data *ImportData, options ImportOptions) []importPlanItem {
+	plan := []importPlanItem{
 		{EntityTypeGoals, "goals", data.Entities.Goals},
 		{EntityTypeProjects, "projects", data.Entities.Projects},
 		{EntityTypeThoughts, "thoughts", data.Entities.Thoughts},
@@ -544,82 +890,189 @@ func (s *ImportExportService) ExecuteImport(
 		{EntityTypeLLMLogs, "llmLogs", data.Entities.LLMLogs},
 	}
 
-	// Import each entity type
-	for _, item := range importOrder {
-		if len(item.entities) == 0 {
+	for i, item := range plan {
+		if options.Selection == nil || len(options.Selection[item.entityType]) == 0 {
 			continue
 		}
 
-		// Filter by selection if provided
-		entitiesToImport := item.entities
-		if options.Selection != nil && len(options.Selection[item.entityType]) > 0 {
-			selected := make(map[string]bool)
-			for _, id := range options.Selection[item.entityType] {
-				selected[id] = true
-			}
+		selected := make(map[string]bool)
+		for _, id := range options.Selection[item.entityType] {
+			selected[id] = true
+		}
 
-			filtered := []map[string]interface{}{}
-			for _, entity := range item.entities {
-				if id := s.getString(entity, "id"); id != "" && selected[id] {
-					filtered = append(filtered, entity)
-				}
+		filtered := []map[string]interface{}{}
+		for _, entity := range item.entities {
+			if id := s.getString(entity, "id"); id != "" && selected[id] {
+				filtered = append(filtered, entity)
 			}
-			entitiesToImport = filtered
 		}
+		plan[i].entities = filtered
+	}
 
-		// Import in batches of 500 (Firestore limit)
-		batchSize := 500
-		for i := 0; i < len(entitiesToImport); i += batchSize {
-			end := i + batchSize
-			if end > len(entitiesToImport) {
-				end = len(entitiesToImport)
+	return plan
+}
+
+// applyIDRemap gives a new ID to every entity resolved as "create_new" and, when
+// UpdateReferences is set, rewrites references to renamed IDs across the import.
+// Returns the (new) IDs of renamed entities and of entities whose references changed,
+// which must be written together.
+func (s *ImportExportService) applyIDRemap(plan []importPlanItem, options ImportOptions) map[EntityType]map[string]bool {
+	remap := make(map[EntityType]map[string]string)
+	linked := make(map[EntityType]map[string]bool)
+
+	for _, item := range plan {
+		for _, entity := range item.entities {
+			id := s.getString(entity, "id")
+			if id == "" || options.ConflictResolution[id] != ConflictResolutionCreateNew {
+				continue
 			}
+			newID := uuid.New().String()
+			entity["id"] = newID
+			if remap[item.entityType] == nil {
+				remap[item.entityType] = make(map[string]string)
+			}
+			remap[item.entityType][id] = newID
+			markLinked(linked, item.entityType, newID)
+		}
+	}
+
+	if !options.UpdateReferences || len(remap) == 0 {
+		return linked
+	}
 
-			batch := s.repo.Batch()
-			for _, entity := range entitiesToImport[i:end] {
-				id := s.getString(entity, "id")
-				if id == "" {
+	for _, item := range plan {
+		fields := importReferenceFields[item.entityType]
+		for _, entity := range item.entities {
+			changed := false
+			for _, ref := range fields {
+				targets := remap[ref.target]
+				if len(targets) == 0 {
 					continue
 				}
+				switch v := entity[ref.field].(type) {
+				case string:
+					if newID, ok := targets[v]; ok {
+						entity[ref.field] = newID
+						changed = true
+					}
+				case []interface{}:
+					for i, raw := range v {
+						if oldID, ok := raw.(string); ok {
+							if newID, ok := targets[oldID]; ok {
+								v[i] = newID
+								changed = true
+							}
+						}
+					}
+				}
+			}
+			if changed {
+				markLinked(linked, item.entityType, s.getString(entity, "id"))
+			}
+		}
+	}
 
-				// Add uid to entity
-				entity["uid"] = uid
+	s.logger.Info("Remapped imported IDs",
+		zap.Int("renamedTypes", len(remap)),
+		zap.Int("linkedTypes", len(linked)),
+	)
 
-				// Add timestamps
-				now := time.Now()
-				if _, ok := entity["createdAt"]; !ok {
-					entity["createdAt"] = now
-				}
-				entity["updatedAt"] = now
-				entity["updatedBy"] = uid
+	return linked
+}
 
-				// Sanitize for Firestore (remove undefined values)
-				sanitized := s.sanitizeForFirestore(entity)
+// writeLinkedEntities commits renamed entities and the entities referencing them
+// in a single atomic batch so references never point at missing documents.
+// Returns false (writing nothing) if the set is too large for one batch.
+func (s *ImportExportService) writeLinkedEntities(
+	ctx context.Context,
+	uid string,
+	plan []importPlanItem,
+	linked map[EntityType]map[string]bool,
+	result *ImportResult,
+) bool {
+	total := 0
+	for _, ids := range linked {
+		total += len(ids)
+	}
+	if total > importBatchLimit {
+		s.logger.Warn("Too many linked entities for an atomic import, falling back to regular batches",
+			zap.Int("count", total),
+		)
+		return false
+	}
 
-				// Create document reference
-				docRef := s.repo.Collection(item.collection).Doc(id)
-				batch.Set(docRef, sanitized, firestore.MergeAll)
+	batch := s.repo.Batch()
+	counts := make(map[EntityType]int)
+	var pending []ImportError
+	for _, item := range plan {
+		for _, entity := range item.entities {
+			id := s.getString(entity, "id")
+			if id == "" || !linked[item.entityType][id] {
+				continue
 			}
+			docRef := s.repo.Collection(item.collection).Doc(id)
+			batch.Set(docRef, s.prepareImportEntity(entity, uid), firestore.MergeAll)
+			counts[item.entityType]++
+			pending = append(pending, ImportError{EntityType: item.entityType, EntityID: id})
+		}
+	}
 
-			// Commit batch
-			if _, err := batch.Commit(ctx); err != nil {
-				errMsg := fmt.Sprintf("Failed to import %s batch: %v", item.entityType, err)
-				result.Errors = append(result.Errors, errMsg)
-				result.ErrorCount += (end - i)
-				result.Success = false
-				s.logger.Error("Import batch failed",
-					zap.String("entityType", string(item.entityType)),
-					zap.Error(err),
-				)
-			} else {
-				imported := end - i
-				result.ImportedCount += imported
-				result.ByType[item.entityType] += imported
-			}
+	if _, err := batch.Commit(ctx); err != nil {
+		// The batch is atomic, so every linked entity failed with it
+		for _, failed := range pending {
+			failed.Message = "linked entities batch failed: " + err.Error()
+			result.Errors = append(result.Errors, failed)
 		}
+		result.ErrorCount += len(pending)
+		result.Success = false
+		s.logger.Error("Linked import batch failed", zap.Error(err))
+		return true
 	}
 
-	return result, nil
+	for entityType, count := range counts {
+		result.ImportedCount += count
+		result.ByType[entityType] += count
+	}
+	return true
+}
+
+// prepareImportEntity stamps ownership and timestamps and sanitizes the entity for Firestore
+func (s *ImportExportService) prepareImportEntity(entity map[string]interface{}, uid string) map[string]interface{} {
+	// Add uid to entity
+	entity["uid"] = uid
+
+	// Add timestamps
+	now := time.Now()
+	if _, ok := entity["createdAt"]; !ok {
+		entity["createdAt"] = now
+	}
+	entity["updatedAt"] = now
+	entity["updatedBy"] = uid
+
+	// Sanitize for Firestore (remove undefined values)
+	return s.sanitizeForFirestore(entity)
+}
+
+// markLinked records an entity ID in a per-type ID set
+func markLinked(linked map[EntityType]map[string]bool, entityType EntityType, id string) {
+	if linked[entityType] == nil {
+		linked[entityType] = make(map[string]bool)
+	}
+	linked[entityType][id] = true
+}
+
+// excludeIDs returns the entities whose ID is not in ids
+func (s *ImportExportService) excludeIDs(entities []map[string]interface{}, ids map[string]bool) []map[string]interface{} {
+	if len(ids) == 0 {
+		return entities
+	}
+	remaining := make([]map[string]interface{}, 0, len(entities))
+	for _, entity := range entities {
+		if !ids[s.getString(entity, "id")] {
+			remaining = append(remaining, entity)
+		}
+	}
+	return remaining
 }
 
 // sanitizeForFirestore removes nil/undefined values recursively
@@ -654,7 +1107,11 @@ func (s *ImportExportService) sanitizeForFirestore(data map[string]interface{})
 	return sanitized
 }
 
-// ExportData exports user data with optional filters
+// ExportData exports user data with optional filters. When the user's plan
+// has export limits, matching documents are counted first and an export over
+// the limit fails with ErrExportTooLarge before any document is read. With
+// filters.Consistent, all collections are read from one snapshot whose time is
+// recorded in the metadata.
 func (s *ImportExportService) ExportData(
 	ctx context.Context,
 	uid string,
@@ -663,7 +1120,7 @@ func (s *ImportExportService) ExportData(
 	exportData := &ImportData{
 		Metadata: ExportMetadata{
 			Version:    "1.0",
-			ExportedAt: time.Now(),
+			ExportedAt: s.now(),
 			ExportedBy: uid,
 			AppVersion: "focus-notebook-backend",
 		},
@@ -674,39 +1131,59 @@ func (s *ImportExportService) ExportData(
 	typesToExport := filters.EntityTypes
 	if len(typesToExport) == 0 {
 		// Export all types by default
-		typesToExport = []EntityType{
-			EntityTypeTasks, EntityTypeProjects, EntityTypeGoals,
-			EntityTypeThoughts, EntityTypeMoods, EntityTypeFocusSessions,
-			EntityTypePeople, EntityTypePortfolios, EntityTypeSpending,
-			EntityTypeRelationships, EntityTypeLLMLogs,
+		typesToExport = allExportEntityTypes
+	}
+
+	queries := make(map[EntityType]firestore.Query, len(typesToExport))
+	for _, entityType := range typesToExport {
+		if query, ok := s.exportQuery(uid, entityType, filters); ok {
+			queries[entityType] = query
+		}
+	}
+
+	if err := s.checkExportQueries(ctx, uid, queries); err != nil {
+		return nil, err
+	}
+
+	var consistentDocs map[EntityType][]map[string]interface{}
+	if filters.Consistent {
+		if docs, readTime, ok := s.consistentExportDocs(ctx, uid, queries); ok {
+			consistentDocs = docs
+			exportData.Metadata.Consistent = true
+			exportData.Metadata.ReadTime = &readTime
 		}
 	}
 
 	// Export each entity type
-	for _, entityType := range typesToExport {
+	for entityType, query := range queries {
+		docs, ok := consistentDocs[entityType]
+		if !ok {
+			docs = s.queryToMaps(ctx, query)
+		}
+		docs = s.filterExportDocs(entityType, docs, filters)
 		switch entityType {
 		case EntityTypeTasks:
-			exportData.Entities.Tasks = s.exportTasks(ctx, uid, filters)
+			exportData.Entities.Tasks = docs
 		case EntityTypeProjects:
-			exportData.Entities.Projects = s.exportProjects(ctx, uid, filters)
+			exportData.Entities.Projects = docs
 		case EntityTypeGoals:
-			exportData.Entities.Goals = s.exportGoals(ctx, uid, filters)
+			exportData.Entities.Goals = docs
 		case EntityTypeThoughts:
-			exportData.Entities.Thoughts = s.exportThoughts(ctx, uid, filters)
+			exportData.Entities.Thoughts = docs
 		case EntityTypeMoods:
-			exportData.Entities.Moods = s.exportMoods(ctx, uid, filters)
+			exportData.Entities.Moods = docs
 		case EntityTypeFocusSessions:
-			exportData.Entities.FocusSessions = s.exportFocusSessions(ctx, uid, filters)
+			exportData.Entities.FocusSessions = docs
 		case EntityTypePeople:
-			exportData.Entities.People = s.exportPeople(ctx, uid, filters)
+			exportData.Entities.People = docs
 		case EntityTypePortfolios:
-			exportData.Entities.Portfolios = s.exportPortfolios(ctx, uid, filters)
+			exportData.Entities.Portfolios = docs
 		case EntityTypeSpending:
-			exportData.Entities.Spending = s.exportSpending(ctx, uid, filters)
+			exportData.Entities.Spending = docs
 		case EntityTypeRelationships:
-			exportData.Entities.Relationships = s.exportRelationships(ctx, uid, filters)
+			exportData.Entities.Relationships = docs
 		case EntityTypeLLMLogs:
-			exportData.Entities.LLMLogs = s.exportLLMLogs(ctx, uid, filters)
+			exportData.Entities.LLMLogs = docs
 		}
 	}
 
@@ -721,216 +1198,517 @@ func (s *ImportExportService) ExportData(
 	return exportData, nil
 }
 
-// Export functions for each entity type
-func (s *ImportExportService) exportTasks(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("tasks").Where("uid", "==", uid)
+// consistentExportDocs reads every export query from one snapshot, returning
+// the documents and the time the snapshot was taken. It reports false, falling
+// back to eventually-consistent reads, when the export is too large to finish
+// inside a read-only transaction or cannot be counted or read.
+func (s *ImportExportService) consistentExportDocs(ctx context.Context, uid string, queries map[EntityType]firestore.Query) (map[EntityType][]map[string]interface{}, time.Time, bool) {
+	total := 0
+	entityTypes := make([]EntityType, 0, len(queries))
+	ordered := make([]firestore.Query, 0, len(queries))
+	for entityType, query := range queries {
+		count, err := s.repo.Count(ctx, query)
+		if err != nil {
+			s.logger.Warn("Failed to count export for consistent read",
+				zap.String("uid", uid),
+				zap.String("entityType", string(entityType)),
+				zap.Error(err),
+			)
+			return nil, time.Time{}, false
+		}
+		total += int(count)
+		entityTypes = append(entityTypes, entityType)
+		ordered = append(ordered, query)
+	}
+	if total > s.consistentExportMaxItems {
+		s.logger.Info("Export too large for a consistent read, falling back",
+			zap.String("uid", uid),
+			zap.Int("items", total),
+			zap.Int("maxItems", s.consistentExportMaxItems),
+		)
+		return nil, time.Time{}, false
+	}
 
-	// Apply filters
-	if len(filters.TaskStatus) > 0 {
-		query = query.Where("status", "in", toInterfaceSlice(filters.TaskStatus))
+	readTime := s.now()
+	results, err := s.repo.CollectAllConsistent(ctx, ordered)
+	if err != nil {
+		s.logger.Warn("Consistent export read failed, falling back",
+			zap.String("uid", uid),
+			zap.Error(err),
+		)
+		return nil, time.Time{}, false
 	}
-	if filters.StartDate != nil {
-		query = query.Where("createdAt", ">=", *filters.StartDate)
+	docs := make(map[EntityType][]map[string]interface{}, len(entityTypes))
+	for i, entityType := range entityTypes {
+		docs[entityType] = results[i]
 	}
-	if filters.EndDate != nil {
-		query = query.Where("createdAt", "<=", *filters.EndDate)
+	return docs, readTime, true
+}
+
+// ExportCollection exports the documents of a single entity type, honoring the
+// same date, status and range filters as ExportData. collection may be an
+// entity type ("spending") or its Firestore collection ("transactions").
+func (s *ImportExportService) ExportCollection(
+	ctx context.Context,
+	uid string,
+	collection string,
+	filters ExportFilters,
+) ([]map[string]interface{}, error) {
+	entityType, ok := ExportEntityTypeFor(collection)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
 	}
 
-	return s.queryToMaps(ctx, query)
-}
+	query, _ := s.exportQuery(uid, entityType, filters)
+	if err := s.checkExportQueries(ctx, uid, map[EntityType]firestore.Query{entityType: query}); err != nil {
+		return nil, err
+	}
 
-func (s *ImportExportService) exportProjects(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("projects").Where("uid", "==", uid)
+	return s.exportDocs(ctx, entityType, query, filters), nil
+}
 
-	if len(filters.ProjectStatus) > 0 {
-		query = query.Where("status", "in", toInterfaceSlice(filters.ProjectStatus))
+// ExportEntityTypeFor resolves an entity type or Firestore collection name to
+// an exportable entity type
+func ExportEntityTypeFor(collection string) (EntityType, bool) {
+	if _, ok := exportCollections[EntityType(collection)]; ok {
+		return EntityType(collection), true
 	}
-	if filters.StartDate != nil {
-		query = query.Where("createdAt", ">=", *filters.StartDate)
+	for entityType, name := range exportCollections {
+		if name == collection {
+			return entityType, true
+		}
 	}
-	if filters.EndDate != nil {
-		query = query.Where("createdAt", "<=", *filters.EndDate)
+	return "", false
+}
+
+// checkExportQueries counts the documents each query matches and rejects the
+// export when it is over the user's plan limit. Plans without limits skip counting.
+func (s *ImportExportService) checkExportQueries(ctx context.Context, uid string, queries map[EntityType]firestore.Query) error {
+	limit := s.exportLimitFor(ctx, uid)
+	if limit.MaxItems <= 0 && limit.MaxItemsPerCollection <= 0 {
+		return nil
 	}
 
-	return s.queryToMaps(ctx, query)
+	counts := make(map[EntityType]int, len(queries))
+	for entityType, query := range queries {
+		count, err := s.repo.Count(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to count %s: %w", entityType, err)
+		}
+		counts[entityType] = int(count)
+	}
+	return checkExportLimit(counts, limit)
 }
 
-func (s *ImportExportService) exportGoals(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("goals").Where("uid", "==", uid)
+// exportDocs runs an export query and applies the filters Firestore cannot
+// combine with it
+func (s *ImportExportService) exportDocs(ctx context.Context, entityType EntityType, query firestore.Query, filters ExportFilters) []map[string]interface{} {
+	return s.filterExportDocs(entityType, s.queryToMaps(ctx, query), filters)
+}
 
-	if len(filters.GoalStatus) > 0 {
-		query = query.Where("status", "in", toInterfaceSlice(filters.GoalStatus))
-	}
-	if filters.StartDate != nil {
-		query = query.Where("createdAt", ">=", *filters.StartDate)
-	}
-	if filters.EndDate != nil {
-		query = query.Where("createdAt", "<=", *filters.EndDate)
+// filterExportDocs applies the export filters Firestore cannot combine with the
+// query. Firestore allows range filters on a single field, so mood and focus
+// session ranges are applied in memory.
+func (s *ImportExportService) filterExportDocs(entityType EntityType, docs []map[string]interface{}, filters ExportFilters) []map[string]interface{} {
+	switch entityType {
+	case EntityTypeMoods:
+		return s.filterByRange(docs, "value", filters.MoodMin, filters.MoodMax)
+	case EntityTypeFocusSessions:
+		docs = s.filterByRange(docs, "duration", filters.FocusMinDuration, nil)
+		return s.filterByRange(docs, "rating", filters.FocusMinRating, nil)
 	}
-
-	return s.queryToMaps(ctx, query)
+	return docs
 }
 
-func (s *ImportExportService) exportThoughts(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("thoughts").Where("uid", "==", uid)
+// exportQuery builds the Firestore query for one entity type. Filters that
+// Firestore cannot combine (mood and focus session ranges) are applied by the caller.
+func (s *ImportExportService) exportQuery(uid string, entityType EntityType, filters ExportFilters) (firestore.Query, bool) {
+	collection, ok := exportCollections[entityType]
+	if !ok {
+		return firestore.Query{}, false
+	}
+	query := s.repo.Collection(collection).Where("uid", "==", uid)
+
+	switch entityType {
+	case EntityTypeTasks:
+		if len(filters.TaskStatus) > 0 {
+			query = query.Where("status", "in", toInterfaceSlice(filters.TaskStatus))
+		}
+	case EntityTypeProjects:
+		if len(filters.ProjectStatus) > 0 {
+			query = query.Where("status", "in", toInterfaceSlice(filters.ProjectStatus))
+		}
+	case EntityTypeGoals:
+		if len(filters.GoalStatus) > 0 {
+			query = query.Where("status", "in", toInterfaceSlice(filters.GoalStatus))
+		}
+	case EntityTypePeople:
+		if len(filters.PeopleCategory) > 0 {
+			query = query.Where("relationshipType", "in", toInterfaceSlice(filters.PeopleCategory))
+		}
+	}
 
+	dateField := exportDateFields[entityType]
+	if dateField == "" {
+		return query, true
+	}
+	if entityType == EntityTypeSpending {
+		// Transactions store dates as YYYY-MM-DD strings
+		if filters.StartDate != nil {
+			query = query.Where(dateField, ">=", filters.StartDate.Format("2006-01-02"))
+		}
+		if filters.EndDate != nil {
+			query = query.Where(dateField, "<=", filters.EndDate.Format("2006-01-02"))
+		}
+		return query, true
+	}
 	if filters.StartDate != nil {
-		query = query.Where("createdAt", ">=", *filters.StartDate)
+		query = query.Where(dateField, ">=", *filters.StartDate)
 	}
 	if filters.EndDate != nil {
-		query = query.Where("createdAt", "<=", *filters.EndDate)
+		query = query.Where(dateField, "<=", *filters.EndDate)
 	}
-
-	return s.queryToMaps(ctx, query)
+	return query, true
 }
 
-func (s *ImportExportService) exportMoods(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("moods").Where("uid", "==", uid)
-
-	if filters.StartDate != nil {
-		query = query.Where("date", ">=", *filters.StartDate)
+// exportLimitFor returns the export limit for the user's subscription tier,
+// falling back to the free tier when the tier is unknown
+func (s *ImportExportService) exportLimitFor(ctx context.Context, uid string) config.ExportLimit {
+	if len(s.exportLimits) == 0 {
+		return config.ExportLimit{}
 	}
-	if filters.EndDate != nil {
-		query = query.Where("date", "<=", *filters.EndDate)
+	tier := "free"
+	if status, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/subscriptionStatus/%s", uid, SubscriptionStatusDoc)); err == nil {
+		if t := s.getString(status, "tier"); t != "" {
+			tier = t
+		}
 	}
+	if limit, ok := s.exportLimits[tier]; ok {
+		return limit
+	}
+	return s.exportLimits["free"]
+}
 
-	return s.queryToMaps(ctx, query)
+// checkExportLimit rejects counts over the per-collection or total limit
+func checkExportLimit(counts map[EntityType]int, limit config.ExportLimit) error {
+	total := 0
+	for _, entityType := range allExportEntityTypes {
+		count := counts[entityType]
+		total += count
+		if limit.MaxItemsPerCollection > 0 && count > limit.MaxItemsPerCollection {
+			return fmt.Errorf("%w: %d %s exceed the limit of %d per collection for your plan; "+
+				"narrow the export with startDate/endDate or export fewer entity types",
+				ErrExportTooLarge, count, entityType, limit.MaxItemsPerCollection)
+		}
+	}
+	if limit.MaxItems > 0 && total > limit.MaxItems {
+		return fmt.Errorf("%w: %d items exceed the limit of %d for your plan; "+
+			"narrow the export with startDate/endDate or export fewer entity types",
+			ErrExportTooLarge, total, limit.MaxItems)
+	}
+	return nil
 }
 
-func (s *ImportExportService) exportFocusSessions(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("focusSessions").Where("uid", "==", uid)
+// filterByRange keeps documents whose numeric field lies within [min, max].
+// A nil bound is not applied; documents missing the field are dropped when any bound is set.
+func (s *ImportExportService) filterByRange(docs []map[string]interface{}, field string, min, max *float64) []map[string]interface{} {
+	if min == nil && max == nil {
+		return docs
+	}
 
-	if filters.StartDate != nil {
-		query = query.Where("startedAt", ">=", *filters.StartDate)
+	filtered := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		value, ok := s.getFloat(doc, field)
+		if !ok {
+			continue
+		}
+		if min != nil && value < *min {
+			continue
+		}
+		if max != nil && value > *max {
+			continue
+		}
+		filtered = append(filtered, doc)
 	}
-	if filters.EndDate != nil {
-		query = query.Where("startedAt", "<=", *filters.EndDate)
+	return filtered
+}
+
+// queryToMaps executes a query and returns results as maps
+func (s *ImportExportService) queryToMaps(ctx context.Context, query firestore.Query) []map[string]interface{} {
+	results, err := s.repo.CollectAll(ctx, query)
+	if err != nil {
+		s.logger.Warn("Error fetching documents", zap.Error(err))
+		return []map[string]interface{}{}
 	}
 
-	return s.queryToMaps(ctx, query)
+	return results
 }
 
-func (s *ImportExportService) exportPeople(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("people").Where("uid", "==", uid)
-	return s.queryToMaps(ctx, query)
-}
+// GetExportSummary calculates summary statistics for export preview.
+// Collections are read concurrently, bounded by summaryConcurrency. Totals and
+// per-status breakdowns are Count() aggregations (matched exactly, as the
+// clients store them lowercase); only collections with field-based stats are
+// read in full. Summaries are cached per user for summaryCacheTTL.
+func (s *ImportExportService) GetExportSummary(ctx context.Context, uid string) (*ExportSummary, error) {
+	now := s.now()
 
-func (s *ImportExportService) exportPortfolios(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("portfolios").Where("uid", "==", uid)
-	return s.queryToMaps(ctx, query)
-}
+	s.summaryMu.Lock()
+	if cached, ok := s.summaryCache[uid]; ok && now.Before(cached.expiresAt) {
+		s.summaryMu.Unlock()
+		summary := cached.summary
+		return &summary, nil
+	}
+	s.summaryMu.Unlock()
 
-func (s *ImportExportService) exportSpending(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("transactions").Where("uid", "==", uid)
+	summary := &ExportSummary{}
+	counts := []exportSummaryCount{
+		{dest: &summary.Tasks.Total, collection: "tasks"},
+		{dest: &summary.Tasks.Active, collection: "tasks", field: "status", values: []string{"active", "in-progress"}},
+		{dest: &summary.Tasks.Completed, collection: "tasks", field: "status", values: []string{"completed", "done"}},
+		{dest: &summary.Tasks.HighPriority, collection: "tasks", field: "priority", values: []string{"high"}},
+		{dest: &summary.Projects.Total, collection: "projects"},
+		{dest: &summary.Projects.Active, collection: "projects", field: "status", values: []string{"active"}},
+		{dest: &summary.Projects.Completed, collection: "projects", field: "status", values: []string{"completed"}},
+		{dest: &summary.Projects.OnHold, collection: "projects", field: "status", values: []string{"on-hold", "paused"}},
+		{dest: &summary.Goals.Total, collection: "goals"},
+		{dest: &summary.Goals.ShortTerm, collection: "goals", field: "type", values: []string{"short-term"}},
+		{dest: &summary.Goals.LongTerm, collection: "goals", field: "type", values: []string{"long-term"}},
+		{dest: &summary.Goals.Active, collection: "goals", field: "status", values: []string{"active"}},
+		{dest: &summary.People.Total, collection: "people"},
+		{dest: &summary.People.Family, collection: "people", field: "relationshipType", values: []string{"family"}},
+		{dest: &summary.People.Friends, collection: "people", field: "relationshipType", values: []string{"friend"}},
+		{dest: &summary.People.Colleagues, collection: "people", field: "relationshipType", values: []string{"colleague"}},
+	}
+	scans := []struct {
+		collection string
+		summarize  func(docs []map[string]interface{})
+	}{
+		{"thoughts", func(docs []map[string]interface{}) { s.summarizeThoughts(summary, docs) }},
+		{"moods", func(docs []map[string]interface{}) { s.summarizeMoods(summary, docs, now) }},
+		{"focusSessions", func(docs []map[string]interface{}) { s.summarizeFocusSessions(summary, docs, now) }},
+		{"portfolios", func(docs []map[string]interface{}) { s.summarizePortfolios(summary, docs) }},
+		{"transactions", func(docs []map[string]interface{}) { s.summarizeSpending(summary, docs, now) }},
+		{"entityRelationships", func(docs []map[string]interface{}) { s.summarizeRelationships(summary, docs) }},
+		{"llmLogs", func(docs []map[string]interface{}) { s.summarizeLLMLogs(summary, docs) }},
+	}
 
-	if filters.StartDate != nil {
-		query = query.Where("date", ">=", filters.StartDate.Format("2006-01-02"))
+	// Each read fills its own summary fields. Failed reads are logged and left
+	// at zero, as the summary is only a preview, but such a summary is not cached.
+	var failed atomic.Bool
+	var g errgroup.Group
+	g.SetLimit(s.summaryConcurrency)
+	for _, c := range counts {
+		c := c
+		g.Go(func() error {
+			count, err := s.repo.Count(ctx, c.query(s.repo, uid))
+			if err != nil {
+				s.logger.Warn("Failed to count export summary",
+					zap.String("uid", uid),
+					zap.String("collection", c.collection),
+					zap.String("field", c.field),
+					zap.Error(err),
+				)
+				failed.Store(true)
+				return nil
+			}
+			*c.dest = int(count)
+			return nil
+		})
 	}
-	if filters.EndDate != nil {
-		query = query.Where("date", "<=", filters.EndDate.Format("2006-01-02"))
+	for _, scan := range scans {
+		scan := scan
+		g.Go(func() error {
+			docs, err := s.repo.CollectAll(ctx, s.repo.Collection(scan.collection).Where("uid", "==", uid))
+			if err != nil {
+				s.logger.Warn("Failed to read export summary",
+					zap.String("uid", uid),
+					zap.String("collection", scan.collection),
+					zap.Error(err),
+				)
+				failed.Store(true)
+				return nil
+			}
+			scan.summarize(docs)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	s.summarizeExportLimit(summary, s.exportLimitFor(ctx, uid))
+
+	if !failed.Load() {
+		s.summaryMu.Lock()
+		s.summaryCache[uid] = cachedExportSummary{summary: *summary, expiresAt: now.Add(s.summaryCacheTTL)}
+		s.summaryMu.Unlock()
 	}
+	return summary, nil
+}
 
-	return s.queryToMaps(ctx, query)
+// InvalidateExportSummary drops the cached export summary for a user, e.g.
+// after an import changes their data
+func (s *ImportExportService) InvalidateExportSummary(uid string) {
+	s.summaryMu.Lock()
+	delete(s.summaryCache, uid)
+	s.summaryMu.Unlock()
 }
 
-func (s *ImportExportService) exportRelationships(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("entityRelationships").Where("uid", "==", uid)
-	return s.queryToMaps(ctx, query)
+// exportSummaryCount is a Count() aggregation filling one summary field:
+// the user's documents in collection, optionally where field is one of values
+type exportSummaryCount struct {
+	dest       *int
+	collection string
+	field      string
+	values     []string
 }
 
-func (s *ImportExportService) exportLLMLogs(ctx context.Context, uid string, filters ExportFilters) []map[string]interface{} {
-	query := s.repo.Collection("llmLogs").Where("uid", "==", uid)
+func (c exportSummaryCount) query(repo interfaces.Repository, uid string) firestore.Query {
+	query := repo.Collection(c.collection).Where("uid", "==", uid)
+	switch {
+	case c.field == "":
+		return query
+	case len(c.values) == 1:
+		return query.Where(c.field, "==", c.values[0])
+	default:
+		return query.Where(c.field, "in", c.values)
+	}
+}
 
-	if filters.StartDate != nil {
-		query = query.Where("timestamp", ">=", *filters.StartDate)
+// summarizeExportLimit totals the summary and checks it against the plan limit
+func (s *ImportExportService) summarizeExportLimit(summary *ExportSummary, limit config.ExportLimit) {
+	counts := map[EntityType]int{
+		EntityTypeTasks:         summary.Tasks.Total,
+		EntityTypeProjects:      summary.Projects.Total,
+		EntityTypeGoals:         summary.Goals.Total,
+		EntityTypeThoughts:      summary.Thoughts.Total,
+		EntityTypeMoods:         summary.Moods.Total,
+		EntityTypeFocusSessions: summary.FocusSessions.Total,
+		EntityTypePeople:        summary.People.Total,
+		EntityTypePortfolios:    summary.Portfolios.Total,
+		EntityTypeSpending:      summary.Spending.Total,
+		EntityTypeRelationships: summary.Relationships.Total,
+		EntityTypeLLMLogs:       summary.LLMLogs.Total,
 	}
-	if filters.EndDate != nil {
-		query = query.Where("timestamp", "<=", *filters.EndDate)
+	summary.TotalItems = 0
+	for _, count := range counts {
+		summary.TotalItems += count
 	}
-
-	return s.queryToMaps(ctx, query)
+	summary.ExportLimit.MaxItems = limit.MaxItems
+	summary.ExportLimit.MaxItemsPerCollection = limit.MaxItemsPerCollection
+	summary.ExportLimit.Exceeded = checkExportLimit(counts, limit) != nil
 }
 
-// queryToMaps executes a query and returns results as maps
-func (s *ImportExportService) queryToMaps(ctx context.Context, query firestore.Query) []map[string]interface{} {
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	results := []map[string]interface{}{}
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
+func (s *ImportExportService) summarizeThoughts(summary *ExportSummary, thoughts []map[string]interface{}) {
+	summary.Thoughts.Total = len(thoughts)
+	for _, thought := range thoughts {
+		if deep, ok := thought["isDeepThought"].(bool); ok && deep {
+			summary.Thoughts.DeepThoughts++
 		}
-		if err != nil {
-			s.logger.Warn("Error fetching document", zap.Error(err))
-			continue
+		if suggestions, ok := thought["aiSuggestions"].([]interface{}); ok && len(suggestions) > 0 {
+			summary.Thoughts.WithSuggestions++
 		}
-
-		results = append(results, doc.Data())
 	}
-
-	return results
 }
 
-// GetExportSummary calculates summary statistics for export preview
-func (s *ImportExportService) GetExportSummary(ctx context.Context, uid string) (*ExportSummary, error) {
-	summary := &ExportSummary{}
+func (s *ImportExportService) summarizeMoods(summary *ExportSummary, moods []map[string]interface{}, now time.Time) {
+	summary.Moods.Total = len(moods)
+	total, counted := 0.0, 0
+	for _, mood := range moods {
+		if value, ok := s.getFloat(mood, "value"); ok {
+			total += value
+			counted++
+		}
+		if createdAt, ok := parseFlexibleDate(mood["createdAt"]); ok &&
+			createdAt.Year() == now.Year() && createdAt.Month() == now.Month() {
+			summary.Moods.ThisMonth++
+		}
+	}
+	if counted > 0 {
+		summary.Moods.AverageMood = total / float64(counted)
+	}
+}
 
-	// Fetch and calculate tasks summary
-	tasksQuery := s.repo.Collection("tasks").Where("uid", "==", uid)
-	tasks := s.queryToMaps(ctx, tasksQuery)
-	summary.Tasks.Total = len(tasks)
-	for _, task := range tasks {
-		status := strings.ToLower(s.getString(task, "status"))
-		if status == "active" || status == "in-progress" {
-			summary.Tasks.Active++
+func (s *ImportExportService) summarizeFocusSessions(summary *ExportSummary, sessions []map[string]interface{}, now time.Time) {
+	summary.FocusSessions.Total = len(sessions)
+	weekAgo := now.AddDate(0, 0, -7)
+	totalMinutes, totalRating, rated := 0.0, 0.0, 0
+	for _, session := range sessions {
+		if duration, ok := s.getFloat(session, "duration"); ok {
+			totalMinutes += duration
 		}
-		if status == "completed" || status == "done" {
-			summary.Tasks.Completed++
+		// Unrated sessions are stored without a rating (or with 0)
+		if rating, ok := s.getFloat(session, "rating"); ok && rating > 0 {
+			totalRating += rating
+			rated++
 		}
-		if s.getString(task, "priority") == "high" {
-			summary.Tasks.HighPriority++
+		if startTime, ok := parseFlexibleDate(session["startTime"]); ok && startTime.After(weekAgo) {
+			summary.FocusSessions.ThisWeek++
 		}
 	}
+	summary.FocusSessions.TotalMinutes = int(math.Round(totalMinutes))
+	if rated > 0 {
+		summary.FocusSessions.AverageRating = totalRating / float64(rated)
+	}
+}
 
-	// Projects summary
-	projectsQuery := s.repo.Collection("projects").Where("uid", "==", uid)
-	projects := s.queryToMaps(ctx, projectsQuery)
-	summary.Projects.Total = len(projects)
-	for _, project := range projects {
-		status := strings.ToLower(s.getString(project, "status"))
-		if status == "active" {
-			summary.Projects.Active++
+func (s *ImportExportService) summarizePortfolios(summary *ExportSummary, portfolios []map[string]interface{}) {
+	summary.Portfolios.Total = len(portfolios)
+	for _, portfolio := range portfolios {
+		if strings.ToLower(s.getString(portfolio, "status")) == "active" {
+			summary.Portfolios.Active++
 		}
-		if status == "completed" {
-			summary.Projects.Completed++
+		investments, _ := portfolio["investments"].([]interface{})
+		for _, raw := range investments {
+			if investment, ok := raw.(map[string]interface{}); ok {
+				value, _ := s.getFloat(investment, "currentValue")
+				summary.Portfolios.TotalInvestments += value
+			}
 		}
-		if status == "on-hold" || status == "paused" {
-			summary.Projects.OnHold++
+	}
+}
+
+func (s *ImportExportService) summarizeSpending(summary *ExportSummary, transactions []map[string]interface{}, now time.Time) {
+	summary.Spending.Total = len(transactions)
+	monthPrefix := now.Format("2006-01")
+	for _, txn := range transactions {
+		amount, _ := s.getFloat(txn, "amount")
+		summary.Spending.TotalAmount += amount
+		// Transaction dates are stored as YYYY-MM-DD strings
+		if strings.HasPrefix(s.getString(txn, "date"), monthPrefix) {
+			summary.Spending.ThisMonth++
 		}
 	}
+	if summary.Spending.Total > 0 {
+		summary.Spending.AverageTransaction = summary.Spending.TotalAmount / float64(summary.Spending.Total)
+	}
+}
 
-	// Goals summary
-	goalsQuery := s.repo.Collection("goals").Where("uid", "==", uid)
-	goals := s.queryToMaps(ctx, goalsQuery)
-	summary.Goals.Total = len(goals)
-	for _, goal := range goals {
-		goalType := strings.ToLower(s.getString(goal, "type"))
-		if goalType == "short-term" {
-			summary.Goals.ShortTerm++
+func (s *ImportExportService) summarizeRelationships(summary *ExportSummary, relationships []map[string]interface{}) {
+	summary.Relationships.Total = len(relationships)
+	for _, rel := range relationships {
+		if s.getString(rel, "status") == "active" {
+			summary.Relationships.Active++
 		}
-		if goalType == "long-term" {
-			summary.Goals.LongTerm++
+		if s.getString(rel, "sourceType") == "tool" || s.getString(rel, "targetType") == "tool" {
+			summary.Relationships.ToolRelated++
 		}
-		status := strings.ToLower(s.getString(goal, "status"))
-		if status == "active" {
-			summary.Goals.Active++
+		if s.getString(rel, "createdBy") == "user" {
+			summary.Relationships.Manual++
 		}
 	}
+}
 
-	// Add other summaries as needed (thoughts, moods, focus sessions, etc.)
-	// For brevity, showing pattern for a few types
-
-	return summary, nil
+func (s *ImportExportService) summarizeLLMLogs(summary *ExportSummary, logs []map[string]interface{}) {
+	summary.LLMLogs.Total = len(logs)
+	for _, log := range logs {
+		switch s.getString(log, "status") {
+		case "completed":
+			summary.LLMLogs.Completed++
+		case "failed":
+			summary.LLMLogs.Failed++
+		}
+		if usage, ok := log["usage"].(map[string]interface{}); ok {
+			tokens, _ := s.getFloat(usage, "total_tokens")
+			summary.LLMLogs.TotalTokens += int(tokens)
+		}
+	}
 }
@@ -0,0 +1,286 @@
+//go:build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+func TestImportExportRoundTrip_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	svc := NewImportExportService(repository.NewFirestoreRepository(client), zap.NewNop(), 2, nil, 0, 0, nil)
+	ctx := context.Background()
+
+	data := &ImportData{
+		Entities: EntityCollection{
+			Goals: []map[string]interface{}{
+				{"id": uid + "-goal", "title": "Run a marathon", "status": "active"},
+			},
+			Projects: []map[string]interface{}{
+				{"id": uid + "-project", "title": "Training plan", "goalId": uid + "-goal", "status": "active"},
+			},
+			Tasks: []map[string]interface{}{
+				{"id": uid + "-task-1", "title": "Long run", "projectId": uid + "-project", "status": "active"},
+				{"id": uid + "-task-2", "title": "Buy shoes", "status": "completed"},
+				{"id": uid + "-task-3", "title": "Stretch", "status": "active"},
+			},
+		},
+	}
+
+	result, err := svc.ExecuteImport(ctx, uid, data, ImportOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Errors)
+	assert.Equal(t, 5, result.ImportedCount)
+
+	exported, err := svc.ExportData(ctx, uid, ExportFilters{
+		EntityTypes: []EntityType{EntityTypeTasks, EntityTypeProjects, EntityTypeGoals},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, exported.Metadata.TotalItems)
+	require.Len(t, exported.Entities.Projects, 1)
+	assert.Equal(t, uid+"-goal", exported.Entities.Projects[0]["goalId"])
+	for _, task := range exported.Entities.Tasks {
+		assert.Equal(t, uid, task["uid"])
+	}
+
+	// Export filters run as real Firestore queries
+	active, err := svc.ExportData(ctx, uid, ExportFilters{
+		EntityTypes: []EntityType{EntityTypeTasks},
+		TaskStatus:  []string{"active"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, active.Entities.Tasks, 2)
+
+	// Single-collection export shares the same filters
+	activeTasks, err := svc.ExportCollection(ctx, uid, "tasks", ExportFilters{TaskStatus: []string{"active"}})
+	require.NoError(t, err)
+	assert.Len(t, activeTasks, 2)
+
+	// Re-importing the export must be idempotent
+	result, err = svc.ExecuteImport(ctx, uid, exported, ImportOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Errors)
+
+	again, err := svc.ExportData(ctx, uid, ExportFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, again.Metadata.TotalItems)
+}
+
+func TestImportExport_CreateNewRemapsReferences_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	svc := NewImportExportService(repository.NewFirestoreRepository(client), zap.NewNop(), 0, nil, 0, 0, nil)
+	ctx := context.Background()
+
+	projectID := uid + "-project"
+	data := &ImportData{
+		Entities: EntityCollection{
+			Projects: []map[string]interface{}{{"id": projectID, "title": "Garden"}},
+			Tasks:    []map[string]interface{}{{"id": uid + "-task", "title": "Plant seeds", "projectId": projectID}},
+		},
+	}
+
+	result, err := svc.ExecuteImport(ctx, uid, data, ImportOptions{
+		UpdateReferences:   true,
+		ConflictResolution: map[string]string{projectID: ConflictResolutionCreateNew},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Errors)
+
+	exported, err := svc.ExportData(ctx, uid, ExportFilters{
+		EntityTypes: []EntityType{EntityTypeTasks, EntityTypeProjects},
+	})
+	require.NoError(t, err)
+	require.Len(t, exported.Entities.Projects, 1)
+	require.Len(t, exported.Entities.Tasks, 1)
+
+	newProjectID := exported.Entities.Projects[0]["id"]
+	assert.NotEqual(t, projectID, newProjectID)
+	assert.Equal(t, newProjectID, exported.Entities.Tasks[0]["projectId"])
+}
+
+func TestImportExport_ConsistentExport_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	svc := NewImportExportService(repository.NewFirestoreRepository(client), zap.NewNop(), 0, nil, 0, 0, nil)
+	ctx := context.Background()
+
+	projectID := uid + "-project"
+	movedProjectID := uid + "-project-2"
+	taskID := uid + "-task"
+	result, err := svc.ExecuteImport(ctx, uid, &ImportData{
+		Entities: EntityCollection{
+			Projects: []map[string]interface{}{{"id": projectID, "title": "Garden", "status": "active"}},
+			Tasks:    []map[string]interface{}{{"id": taskID, "title": "Plant seeds", "projectId": projectID, "status": "active"}},
+		},
+	}, ImportOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Errors)
+
+	readTime := time.Now()
+	svc.now = func() time.Time { return readTime }
+
+	filters := ExportFilters{EntityTypes: []EntityType{EntityTypeTasks, EntityTypeProjects}, Consistent: true}
+	exported, err := svc.ExportData(ctx, uid, filters)
+	require.NoError(t, err)
+	assert.True(t, exported.Metadata.Consistent)
+	require.NotNil(t, exported.Metadata.ReadTime)
+	assert.True(t, exported.Metadata.ReadTime.Equal(readTime))
+
+	// Every exported task references a project from the same snapshot
+	require.Len(t, exported.Entities.Projects, 1)
+	require.Len(t, exported.Entities.Tasks, 1)
+	assert.Equal(t, projectID, exported.Entities.Projects[0]["id"])
+	assert.Equal(t, projectID, exported.Entities.Tasks[0]["projectId"])
+
+	// Writes after the export are not part of its snapshot; a later export
+	// over the size cap falls back to reading current data
+	_, err = client.Collection("projects").Doc(movedProjectID).Set(ctx, map[string]interface{}{
+		"id": movedProjectID, "uid": uid, "title": "Orchard", "status": "active",
+	})
+	require.NoError(t, err)
+	_, err = client.Collection("tasks").Doc(taskID).Update(ctx, []firestore.Update{{Path: "projectId", Value: movedProjectID}})
+	require.NoError(t, err)
+	_, err = client.Collection("projects").Doc(projectID).Delete(ctx)
+	require.NoError(t, err)
+
+	svc.consistentExportMaxItems = 1
+	fallback, err := svc.ExportData(ctx, uid, filters)
+	require.NoError(t, err)
+	assert.False(t, fallback.Metadata.Consistent)
+	assert.Nil(t, fallback.Metadata.ReadTime)
+	require.Len(t, fallback.Entities.Projects, 1)
+	assert.Equal(t, movedProjectID, fallback.Entities.Projects[0]["id"])
+	assert.Equal(t, movedProjectID, fallback.Entities.Tasks[0]["projectId"])
+}
+
+func TestImportExport_ExportSummary_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	svc := NewImportExportService(repository.NewFirestoreRepository(client), zap.NewNop(), 0, nil, 2, time.Minute, nil)
+	ctx := context.Background()
+
+	result, err := svc.ExecuteImport(ctx, uid, &ImportData{
+		Entities: EntityCollection{
+			Goals: []map[string]interface{}{
+				{"id": uid + "-goal", "title": "Run a marathon", "type": "long-term", "status": "active"},
+			},
+			Projects: []map[string]interface{}{
+				{"id": uid + "-project", "title": "Training plan", "status": "paused"},
+			},
+			Tasks: []map[string]interface{}{
+				{"id": uid + "-task-1", "title": "Long run", "status": "active", "priority": "high"},
+				{"id": uid + "-task-2", "title": "Buy shoes", "status": "done"},
+				{"id": uid + "-task-3", "title": "Stretch", "status": "in-progress"},
+			},
+			Moods: []map[string]interface{}{
+				{"id": uid + "-mood-1", "value": 6.0},
+				{"id": uid + "-mood-2", "value": 8.0},
+			},
+		},
+	}, ImportOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Errors)
+
+	summary, err := svc.GetExportSummary(ctx, uid)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Tasks.Total)
+	assert.Equal(t, 2, summary.Tasks.Active)
+	assert.Equal(t, 1, summary.Tasks.Completed)
+	assert.Equal(t, 1, summary.Tasks.HighPriority)
+	assert.Equal(t, 1, summary.Projects.OnHold)
+	assert.Equal(t, 1, summary.Goals.LongTerm)
+	assert.Equal(t, 1, summary.Goals.Active)
+	assert.Equal(t, 2, summary.Moods.Total)
+	assert.InDelta(t, 7.0, summary.Moods.AverageMood, 0.001)
+	assert.Equal(t, 7, summary.TotalItems)
+
+	// Writes outside the service are not seen until the cache expires
+	_, err = client.Collection("tasks").Doc(uid+"-task-4").Set(ctx, map[string]interface{}{
+		"id": uid + "-task-4", "uid": uid, "title": "Rest day", "status": "active",
+	})
+	require.NoError(t, err)
+	cached, err := svc.GetExportSummary(ctx, uid)
+	require.NoError(t, err)
+	assert.Equal(t, 3, cached.Tasks.Total)
+
+	svc.InvalidateExportSummary(uid)
+	refreshed, err := svc.GetExportSummary(ctx, uid)
+	require.NoError(t, err)
+	assert.Equal(t, 4, refreshed.Tasks.Total)
+	assert.Equal(t, 3, refreshed.Tasks.Active)
+}
+
+func TestImportExport_ReplaceAllVsMerge_Emulator(t *testing.T) {
+	client := testutil.NewEmulatorClient(t)
+	uid := testutil.NewTestUser(t, client)
+	svc := NewImportExportService(repository.NewFirestoreRepository(client), zap.NewNop(), 2, nil, 0, 0, nil)
+	ctx := context.Background()
+
+	existing := &ImportData{Entities: EntityCollection{
+		Tasks: []map[string]interface{}{
+			{"id": uid + "-task-1", "title": "Old task", "status": "active"},
+			{"id": uid + "-task-2", "title": "Another old task", "status": "active"},
+			{"id": uid + "-task-3", "title": "Third old task", "status": "completed"},
+		},
+		Goals: []map[string]interface{}{
+			{"id": uid + "-goal", "title": "Untouched goal", "status": "active"},
+		},
+	}}
+	backup := &ImportData{Entities: EntityCollection{
+		Tasks: []map[string]interface{}{
+			{"id": uid + "-task-1", "title": "Restored task", "status": "active"},
+			{"id": uid + "-task-4", "title": "New task", "status": "active"},
+		},
+	}}
+	taskIDs := func() []string {
+		exported, err := svc.ExportData(ctx, uid, ExportFilters{EntityTypes: []EntityType{EntityTypeTasks}})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(exported.Entities.Tasks))
+		for _, task := range exported.Entities.Tasks {
+			ids = append(ids, task["id"].(string))
+		}
+		return ids
+	}
+
+	result, err := svc.ExecuteImport(ctx, uid, existing, ImportOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Errors)
+
+	// The default merge keeps existing tasks alongside the imported ones
+	result, err = svc.ExecuteImport(ctx, uid, backup, ImportOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Errors)
+	assert.Empty(t, result.JobID)
+	assert.ElementsMatch(t, []string{uid + "-task-1", uid + "-task-2", uid + "-task-3", uid + "-task-4"}, taskIDs())
+
+	// Replace deletes every existing task first; goals are not in the backup
+	result, err = svc.ExecuteImport(ctx, uid, backup, ImportOptions{ReplaceAll: true, ConfirmReplaceAll: true})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Errors)
+	assert.Equal(t, 4, result.DeletedCount)
+	assert.Equal(t, map[EntityType]int{EntityTypeTasks: 4}, result.DeletedByType)
+	assert.Equal(t, 2, result.ImportedCount)
+	assert.ElementsMatch(t, []string{uid + "-task-1", uid + "-task-4"}, taskIDs())
+
+	goals, err := svc.ExportData(ctx, uid, ExportFilters{EntityTypes: []EntityType{EntityTypeGoals}})
+	require.NoError(t, err)
+	assert.Len(t, goals.Entities.Goals, 1)
+
+	job, err := svc.GetImportJob(ctx, uid, result.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, ImportJobStatusCompleted, job["status"])
+	assert.EqualValues(t, 4, job["deletedCount"])
+	assert.EqualValues(t, 2, job["importedCount"])
+}
@@ -1,20 +1,27 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
 )
 
 func TestNewImportExportService(t *testing.T) {
 	repo := &mocks.MockRepository{}
 	logger := zap.NewNop()
 
-	svc := NewImportExportService(repo, logger)
+	svc := NewImportExportService(repo, logger, 0, nil, 0, 0, nil)
 
 	require.NotNil(t, svc)
 	assert.Equal(t, repo, svc.repo)
@@ -24,7 +31,7 @@ func TestNewImportExportService(t *testing.T) {
 func TestNewImportExportService_WithNilRepo(t *testing.T) {
 	logger := zap.NewNop()
 
-	svc := NewImportExportService(nil, logger)
+	svc := NewImportExportService(nil, logger, 0, nil, 0, 0, nil)
 
 	require.NotNil(t, svc)
 	assert.Nil(t, svc.repo)
@@ -34,7 +41,7 @@ func TestNewImportExportService_WithNilRepo(t *testing.T) {
 func TestNewImportExportService_WithNilLogger(t *testing.T) {
 	repo := &mocks.MockRepository{}
 
-	svc := NewImportExportService(repo, nil)
+	svc := NewImportExportService(repo, nil, 0, nil, 0, 0, nil)
 
 	require.NotNil(t, svc)
 	assert.Equal(t, repo, svc.repo)
@@ -42,9 +49,513 @@ func TestNewImportExportService_WithNilLogger(t *testing.T) {
 }
 
 func TestNewImportExportService_BothNil(t *testing.T) {
-	svc := NewImportExportService(nil, nil)
+	svc := NewImportExportService(nil, nil, 0, nil, 0, 0, nil)
 
 	require.NotNil(t, svc)
 	assert.Nil(t, svc.repo)
 	assert.Nil(t, svc.logger)
 }
+
+func TestNewImportExportService_SummaryDefaults(t *testing.T) {
+	svc := NewImportExportService(nil, zap.NewNop(), 0, nil, 0, 0, nil)
+	assert.Equal(t, defaultExportSummaryConcurrency, svc.summaryConcurrency)
+	assert.Equal(t, defaultExportSummaryCacheTTL, svc.summaryCacheTTL)
+
+	svc = NewImportExportService(nil, zap.NewNop(), 0, nil, 8, time.Minute, nil)
+	assert.Equal(t, 8, svc.summaryConcurrency)
+	assert.Equal(t, time.Minute, svc.summaryCacheTTL)
+}
+
+func TestImportExportService_GetExportSummary_Cached(t *testing.T) {
+	// The mock cannot build queries, so a cache miss would panic
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	cached := ExportSummary{TotalItems: 42}
+	cached.Tasks.Total = 42
+	svc.summaryCache["user-1"] = cachedExportSummary{summary: cached, expiresAt: now.Add(time.Second)}
+
+	summary, err := svc.GetExportSummary(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 42, summary.Tasks.Total)
+
+	// Callers get a copy of the cached summary
+	summary.Tasks.Total = 0
+	assert.Equal(t, 42, svc.summaryCache["user-1"].summary.Tasks.Total)
+
+	svc.InvalidateExportSummary("user-1")
+	assert.NotContains(t, svc.summaryCache, "user-1")
+}
+
+func TestImportExportService_ExportData_Consistent(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	repo.AddDocument("tasks/task-1", map[string]interface{}{"id": "task-1", "uid": "user-1", "title": "Water the garden"})
+	repo.AddDocument("tasks/task-2", map[string]interface{}{"id": "task-2", "uid": "user-2", "title": "Not mine"})
+	repo.AddDocument("projects/project-1", map[string]interface{}{"id": "project-1", "uid": "user-1", "title": "Garden"})
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+	filters := ExportFilters{EntityTypes: []EntityType{EntityTypeTasks, EntityTypeProjects}, Consistent: true}
+
+	exported, err := svc.ExportData(ctx, "user-1", filters)
+	require.NoError(t, err)
+	assert.True(t, exported.Metadata.Consistent)
+	require.NotNil(t, exported.Metadata.ReadTime)
+	assert.True(t, exported.Metadata.ReadTime.Equal(now))
+	require.Len(t, exported.Entities.Tasks, 1)
+	assert.Equal(t, "task-1", exported.Entities.Tasks[0]["id"])
+	require.Len(t, exported.Entities.Projects, 1)
+	assert.Equal(t, "project-1", exported.Entities.Projects[0]["id"])
+	assert.Equal(t, 2, exported.Metadata.TotalItems)
+
+	// Too large for one snapshot: falls back to eventually-consistent reads
+	svc.consistentExportMaxItems = 1
+	fallback, err := svc.ExportData(ctx, "user-1", filters)
+	require.NoError(t, err)
+	assert.False(t, fallback.Metadata.Consistent)
+	assert.Nil(t, fallback.Metadata.ReadTime)
+	assert.Equal(t, 2, fallback.Metadata.TotalItems)
+}
+
+func TestImportExportService_ExportData_ConsistentReadFailure(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	repo.QueryErr = errors.New("unavailable")
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	exported, err := svc.ExportData(context.Background(), "user-1", ExportFilters{
+		EntityTypes: []EntityType{EntityTypeTasks},
+		Consistent:  true,
+	})
+	require.NoError(t, err)
+	assert.False(t, exported.Metadata.Consistent)
+	assert.Nil(t, exported.Metadata.ReadTime)
+	assert.Empty(t, exported.Entities.Tasks)
+}
+
+func TestImportExportService_ApplyIDRemap_UpdatesReferences(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	data := &ImportData{
+		Entities: EntityCollection{
+			Projects: []map[string]interface{}{
+				{"id": "project-1", "name": "Renamed project"},
+			},
+			Tasks: []map[string]interface{}{
+				{"id": "task-1", "title": "Linked task", "projectId": "project-1"},
+				{"id": "task-2", "title": "Unrelated task", "projectId": "project-2"},
+			},
+			Thoughts: []map[string]interface{}{
+				{"id": "thought-1", "linkedProjectIds": []interface{}{"project-1", "project-3"}},
+			},
+		},
+	}
+	options := ImportOptions{
+		UpdateReferences:   true,
+		ConflictResolution: map[string]string{"project-1": ConflictResolutionCreateNew},
+	}
+
+	plan := svc.buildImportPlan(data, options)
+	linked := svc.applyIDRemap(plan, options)
+
+	newID := data.Entities.Projects[0]["id"].(string)
+	assert.NotEqual(t, "project-1", newID)
+	assert.NotEmpty(t, newID)
+
+	assert.Equal(t, newID, data.Entities.Tasks[0]["projectId"])
+	assert.Equal(t, "project-2", data.Entities.Tasks[1]["projectId"])
+	assert.Equal(t, []interface{}{newID, "project-3"}, data.Entities.Thoughts[0]["linkedProjectIds"])
+
+	assert.True(t, linked[EntityTypeProjects][newID])
+	assert.True(t, linked[EntityTypeTasks]["task-1"])
+	assert.False(t, linked[EntityTypeTasks]["task-2"])
+	assert.True(t, linked[EntityTypeThoughts]["thought-1"])
+}
+
+func TestImportExportService_ApplyIDRemap_WithoutUpdateReferences(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	data := &ImportData{
+		Entities: EntityCollection{
+			Projects: []map[string]interface{}{{"id": "project-1", "name": "Project"}},
+			Tasks:    []map[string]interface{}{{"id": "task-1", "title": "Task", "projectId": "project-1"}},
+		},
+	}
+	options := ImportOptions{
+		ConflictResolution: map[string]string{"project-1": ConflictResolutionCreateNew},
+	}
+
+	linked := svc.applyIDRemap(svc.buildImportPlan(data, options), options)
+
+	assert.NotEqual(t, "project-1", data.Entities.Projects[0]["id"])
+	assert.Equal(t, "project-1", data.Entities.Tasks[0]["projectId"])
+	assert.Empty(t, linked[EntityTypeTasks])
+}
+
+func TestImportExportService_BuildImportPlan_Selection(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	data := &ImportData{
+		Entities: EntityCollection{
+			Tasks: []map[string]interface{}{{"id": "task-1"}, {"id": "task-2"}},
+		},
+	}
+	options := ImportOptions{
+		Selection: map[EntityType][]string{EntityTypeTasks: {"task-2"}},
+	}
+
+	for _, item := range svc.buildImportPlan(data, options) {
+		if item.entityType == EntityTypeTasks {
+			require.Len(t, item.entities, 1)
+			assert.Equal(t, "task-2", item.entities[0]["id"])
+		}
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestImportExportService_FilterByRange(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	moods := []map[string]interface{}{
+		{"id": "m1", "value": float64(2)},
+		{"id": "m2", "value": int64(5)},
+		{"id": "m3", "value": 9},
+		{"id": "m4"},
+	}
+
+	tests := []struct {
+		name    string
+		min     *float64
+		max     *float64
+		wantIDs []string
+	}{
+		{name: "no bounds is a no-op", wantIDs: []string{"m1", "m2", "m3", "m4"}},
+		{name: "max only", max: floatPtr(4), wantIDs: []string{"m1"}},
+		{name: "min only", min: floatPtr(5), wantIDs: []string{"m2", "m3"}},
+		{name: "min and max inclusive", min: floatPtr(2), max: floatPtr(5), wantIDs: []string{"m1", "m2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := svc.filterByRange(moods, "value", tt.min, tt.max)
+			ids := make([]string, 0, len(got))
+			for _, doc := range got {
+				ids = append(ids, doc["id"].(string))
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestImportExportService_SummarizeMoods(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	moods := []map[string]interface{}{
+		{"value": float64(8), "createdAt": "2024-06-01T09:00:00Z"},
+		{"value": int64(4), "createdAt": time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{"value": 6, "createdAt": "2024-05-31T23:00:00Z"},
+		{"note": "missing value"},
+	}
+
+	summary := &ExportSummary{}
+	svc.summarizeMoods(summary, moods, now)
+
+	assert.Equal(t, 4, summary.Moods.Total)
+	assert.InDelta(t, 6.0, summary.Moods.AverageMood, 0.0001)
+	assert.Equal(t, 2, summary.Moods.ThisMonth)
+}
+
+func TestImportExportService_SummarizeMoods_Empty(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	summary := &ExportSummary{}
+	svc.summarizeMoods(summary, nil, time.Now())
+
+	assert.Equal(t, 0, summary.Moods.Total)
+	assert.Equal(t, 0.0, summary.Moods.AverageMood)
+}
+
+func TestImportExportService_SummarizeFocusSessions(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	sessions := []map[string]interface{}{
+		{"duration": float64(25), "rating": float64(4), "startTime": "2024-06-14T10:00:00Z"},
+		{"duration": int64(50), "rating": float64(0), "startTime": "2024-06-01T10:00:00Z"},
+		{"duration": 45.5, "rating": float64(2), "startTime": "2024-06-12T10:00:00Z"},
+		{"startTime": "2024-06-15T08:00:00Z"},
+	}
+
+	summary := &ExportSummary{}
+	svc.summarizeFocusSessions(summary, sessions, now)
+
+	assert.Equal(t, 4, summary.FocusSessions.Total)
+	assert.Equal(t, 121, summary.FocusSessions.TotalMinutes)
+	assert.InDelta(t, 3.0, summary.FocusSessions.AverageRating, 0.0001)
+	assert.Equal(t, 3, summary.FocusSessions.ThisWeek)
+}
+
+func TestImportExportService_SummarizeSpendingAndLLMLogs(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	summary := &ExportSummary{}
+	svc.summarizeSpending(summary, []map[string]interface{}{
+		{"amount": float64(30), "date": "2024-06-02"},
+		{"amount": float64(10), "date": "2024-05-20"},
+	}, now)
+	svc.summarizeLLMLogs(summary, []map[string]interface{}{
+		{"status": "completed", "usage": map[string]interface{}{"total_tokens": float64(120)}},
+		{"status": "failed"},
+	})
+
+	assert.Equal(t, 40.0, summary.Spending.TotalAmount)
+	assert.Equal(t, 20.0, summary.Spending.AverageTransaction)
+	assert.Equal(t, 1, summary.Spending.ThisMonth)
+	assert.Equal(t, 1, summary.LLMLogs.Completed)
+	assert.Equal(t, 1, summary.LLMLogs.Failed)
+	assert.Equal(t, 120, summary.LLMLogs.TotalTokens)
+}
+
+func TestNewImportExportService_BatchSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		want      int
+	}{
+		{name: "default", batchSize: 0, want: 500},
+		{name: "negative", batchSize: -1, want: 500},
+		{name: "custom", batchSize: 100, want: 100},
+		{name: "capped at firestore limit", batchSize: 1000, want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewImportExportService(nil, zap.NewNop(), tt.batchSize, nil, 0, 0, nil)
+			assert.Equal(t, tt.want, svc.batchSize)
+		})
+	}
+}
+
+// failingSetRepository rejects SetDocument for specific paths
+type failingSetRepository struct {
+	*mocks.MockRepository
+	failPaths map[string]bool
+}
+
+func (r *failingSetRepository) SetDocument(ctx context.Context, path string, data map[string]interface{}) error {
+	if r.failPaths[path] {
+		return errors.New("invalid document")
+	}
+	return r.MockRepository.SetDocument(ctx, path, data)
+}
+
+func TestImportExportService_ImportIndividually_RecordsPerDocumentErrors(t *testing.T) {
+	repo := &failingSetRepository{
+		MockRepository: mocks.NewMockRepository(),
+		failPaths:      map[string]bool{"tasks/bad": true},
+	}
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	result := &ImportResult{Success: true, ByType: make(map[EntityType]int), Errors: []ImportError{}}
+	item := importPlanItem{entityType: EntityTypeTasks, collection: "tasks"}
+	entities := []map[string]interface{}{
+		{"id": "good-1", "title": "One"},
+		{"id": "bad", "title": "Broken"},
+		{"id": "good-2", "title": "Two"},
+		{"title": "No ID"},
+	}
+
+	svc.importIndividually(context.Background(), "user-1", item, entities, result)
+
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.ImportedCount)
+	assert.Equal(t, 2, result.ByType[EntityTypeTasks])
+	assert.Equal(t, 1, result.ErrorCount)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, ImportError{EntityType: EntityTypeTasks, EntityID: "bad", Message: "invalid document"}, result.Errors[0])
+	assert.Equal(t, []string{"Failed to import tasks bad: invalid document"}, result.ErrorMessages())
+
+	assert.Equal(t, "user-1", repo.Documents["tasks/good-1"]["uid"])
+	assert.NotContains(t, repo.Documents, "tasks/bad")
+}
+
+func TestCheckExportLimit(t *testing.T) {
+	limit := config.ExportLimit{MaxItems: 100, MaxItemsPerCollection: 60}
+
+	assert.NoError(t, checkExportLimit(map[EntityType]int{EntityTypeTasks: 60, EntityTypeThoughts: 40}, limit))
+
+	err := checkExportLimit(map[EntityType]int{EntityTypeTasks: 61}, limit)
+	assert.ErrorIs(t, err, ErrExportTooLarge)
+	assert.Contains(t, err.Error(), "61 tasks")
+	assert.Contains(t, err.Error(), "startDate/endDate")
+
+	err = checkExportLimit(map[EntityType]int{EntityTypeTasks: 50, EntityTypeThoughts: 50, EntityTypeMoods: 1}, limit)
+	assert.ErrorIs(t, err, ErrExportTooLarge)
+	assert.Contains(t, err.Error(), "101 items")
+
+	assert.NoError(t, checkExportLimit(map[EntityType]int{EntityTypeTasks: 1000000}, config.ExportLimit{}))
+}
+
+func TestImportExportService_ExportLimitByTier(t *testing.T) {
+	limits := map[string]config.ExportLimit{
+		"free": {MaxItems: 1000},
+		"pro":  {MaxItems: 100000},
+	}
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/pro-user/subscriptionStatus/current", map[string]interface{}{"tier": "pro"})
+	repo.AddDocument("users/odd-user/subscriptionStatus/current", map[string]interface{}{"tier": "enterprise"})
+	svc := NewImportExportService(repo, zap.NewNop(), 0, limits, 0, 0, nil)
+	ctx := context.Background()
+
+	assert.Equal(t, 100000, svc.exportLimitFor(ctx, "pro-user").MaxItems)
+	assert.Equal(t, 1000, svc.exportLimitFor(ctx, "free-user").MaxItems)
+	assert.Equal(t, 1000, svc.exportLimitFor(ctx, "odd-user").MaxItems)
+
+	unlimited := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+	assert.Equal(t, config.ExportLimit{}, unlimited.exportLimitFor(ctx, "pro-user"))
+}
+
+func TestImportExportService_SummarizeExportLimit(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	summary := &ExportSummary{}
+	summary.Tasks.Total = 700
+	summary.LLMLogs.Total = 400
+	svc.summarizeExportLimit(summary, config.ExportLimit{MaxItems: 1000})
+
+	assert.Equal(t, 1100, summary.TotalItems)
+	assert.Equal(t, 1000, summary.ExportLimit.MaxItems)
+	assert.True(t, summary.ExportLimit.Exceeded)
+
+	svc.summarizeExportLimit(summary, config.ExportLimit{})
+	assert.Equal(t, 1100, summary.TotalItems)
+	assert.False(t, summary.ExportLimit.Exceeded)
+}
+
+func TestImportResult_StructuredErrorsJSON(t *testing.T) {
+	result := ImportResult{Errors: []ImportError{
+		{EntityType: EntityTypeThoughts, EntityID: "th-9", Message: "invalid document"},
+	}}
+
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"errors":[{"entityType":"thoughts","entityId":"th-9","message":"invalid document"}]`)
+}
+
+func TestExportEntityTypeFor(t *testing.T) {
+	tests := []struct {
+		collection string
+		want       EntityType
+		wantOK     bool
+	}{
+		{"tasks", EntityTypeTasks, true},
+		{"spending", EntityTypeSpending, true},
+		{"transactions", EntityTypeSpending, true},
+		{"entityRelationships", EntityTypeRelationships, true},
+		{"relationships", EntityTypeRelationships, true},
+		{"accounts", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ExportEntityTypeFor(tt.collection)
+		assert.Equal(t, tt.wantOK, ok, tt.collection)
+		assert.Equal(t, tt.want, got, tt.collection)
+	}
+}
+
+func TestImportExportService_ExportCollection_UnknownCollection(t *testing.T) {
+	svc := NewImportExportService(&mocks.MockRepository{}, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	_, err := svc.ExportCollection(context.Background(), "user1", "accounts", ExportFilters{})
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
+
+func TestEncodeDocumentsCSV(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "t1", "title": "Buy milk, eggs", "amount": 12.5, "date": time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+		{"id": "t2", "title": "Plan trip", "tags": []interface{}{"travel", "summer"}, "done": true},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeDocumentsCSV(&buf, docs))
+
+	assert.Equal(t, "id,amount,date,done,tags,title\n"+
+		"t1,12.5,2024-03-01T09:30:00Z,,,\"Buy milk, eggs\"\n"+
+		"t2,,,true,\"[\"\"travel\"\",\"\"summer\"\"]\",Plan trip\n", buf.String())
+}
+
+func TestEncodeDocumentsCSV_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeDocumentsCSV(&buf, nil))
+	assert.Equal(t, "id\n", buf.String())
+}
+
+func TestImportExportService_ExecuteImport_ReplaceAllRequiresConfirmation(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("tasks/task-1", map[string]interface{}{"id": "task-1", "uid": "user-1"})
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	data := &ImportData{Entities: EntityCollection{
+		Tasks: []map[string]interface{}{{"id": "task-2", "title": "Restored"}},
+	}}
+	result, err := svc.ExecuteImport(context.Background(), "user-1", data, ImportOptions{ReplaceAll: true})
+	require.ErrorIs(t, err, ErrReplaceNotConfirmed)
+	assert.Nil(t, result)
+	assert.Contains(t, repo.Documents, "tasks/task-1")
+}
+
+func TestImportExportService_ExecuteImport_ChargesAnonymousQuotaPerDocument(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("anonymousSessions/anon1", map[string]interface{}{"uid": "anon1"})
+	quota := NewAnonymousService(repo, nil, zap.NewNop(), &config.AnonymousConfig{MaxDocuments: 2}, 0)
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, quota)
+
+	ctx := context.WithValue(context.Background(), "uid", "anon1")
+	ctx = context.WithValue(ctx, "isAnonymous", true)
+	data := &ImportData{Entities: EntityCollection{
+		Tasks: []map[string]interface{}{{"id": "t1"}, {"id": "t2"}, {"id": "t3"}},
+	}}
+
+	result, err := svc.ExecuteImport(ctx, "anon1", data, ImportOptions{})
+	require.ErrorIs(t, err, ErrAnonymousQuotaExceeded)
+	assert.Nil(t, result)
+	assert.Nil(t, repo.Documents["anonymousSessions/anon1"]["documentCount"])
+}
+
+func TestImportExportService_GetImportJob(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user-1/importJobs/job-1", map[string]interface{}{"id": "job-1", "status": ImportJobStatusCompleted})
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	job, err := svc.GetImportJob(context.Background(), "user-1", "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, ImportJobStatusCompleted, job["status"])
+
+	_, err = svc.GetImportJob(context.Background(), "user-2", "job-1")
+	assert.ErrorIs(t, err, ErrImportJobNotFound)
+}
+
+func TestReplacedEntityTypes(t *testing.T) {
+	svc := NewImportExportService(nil, zap.NewNop(), 0, nil, 0, 0, nil)
+	data := &ImportData{Entities: EntityCollection{
+		Goals: []map[string]interface{}{{"id": "goal-1"}},
+		Tasks: []map[string]interface{}{{"id": "task-1"}, {"id": "task-2"}},
+		Moods: []map[string]interface{}{{"id": "mood-1"}},
+	}}
+
+	// Types without entities to import, including those emptied by the
+	// selection, keep their existing documents
+	plan := svc.buildImportPlan(data, ImportOptions{
+		Selection: map[EntityType][]string{EntityTypeMoods: {"mood-other"}},
+	})
+	assert.Equal(t, []EntityType{EntityTypeGoals, EntityTypeTasks}, replacedEntityTypes(plan))
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Import job statuses; everything but running is terminal
+const (
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+// ErrImportJobNotFound is returned for unknown import job IDs
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// importJob is the progress of a ReplaceAll import, stored at
+// users/{uid}/importJobs/{jobId} so a restore that fails part way is visible
+// after the request ends
+type importJob struct {
+	id          string
+	entityTypes []EntityType
+	startedAt   time.Time
+}
+
+// startImportJob records a running replace job for the plan's entity types
+func (s *ImportExportService) startImportJob(ctx context.Context, uid string, plan []importPlanItem, result *ImportResult) *importJob {
+	job := &importJob{
+		id:          uuid.New().String(),
+		entityTypes: replacedEntityTypes(plan),
+		startedAt:   s.now(),
+	}
+	result.JobID = job.id
+	result.DeletedByType = make(map[EntityType]int)
+
+	if err := s.repo.SetDocument(ctx, importJobPath(uid, job.id), map[string]interface{}{
+		"id":          job.id,
+		"status":      ImportJobStatusRunning,
+		"replaceAll":  true,
+		"entityTypes": entityTypesToInterfaces(job.entityTypes),
+		"startedAt":   job.startedAt,
+	}); err != nil {
+		s.logger.Warn("Failed to record import job", zap.String("uid", uid), zap.String("jobId", job.id), zap.Error(err))
+	}
+	return job
+}
+
+// finishImportJob stores the job's final counts and status
+func (s *ImportExportService) finishImportJob(ctx context.Context, uid string, job *importJob, result *ImportResult) {
+	status := ImportJobStatusCompleted
+	if !result.Success {
+		status = ImportJobStatusFailed
+	}
+	deletedByType := make(map[string]interface{}, len(result.DeletedByType))
+	for entityType, count := range result.DeletedByType {
+		deletedByType[string(entityType)] = count
+	}
+
+	if err := s.repo.Update(ctx, importJobPath(uid, job.id), map[string]interface{}{
+		"status":        status,
+		"deletedCount":  result.DeletedCount,
+		"deletedByType": deletedByType,
+		"importedCount": result.ImportedCount,
+		"errorCount":    result.ErrorCount,
+		"finishedAt":    s.now(),
+	}); err != nil {
+		s.logger.Warn("Failed to update import job", zap.String("uid", uid), zap.String("jobId", job.id), zap.Error(err))
+	}
+
+	s.logger.Info("Replace import finished",
+		zap.String("uid", uid),
+		zap.String("jobId", job.id),
+		zap.String("status", status),
+		zap.Int("deleted", result.DeletedCount),
+		zap.Int("imported", result.ImportedCount),
+	)
+}
+
+// GetImportJob returns a replace import job's stored progress
+func (s *ImportExportService) GetImportJob(ctx context.Context, uid, jobID string) (map[string]interface{}, error) {
+	data, err := s.repo.Get(ctx, importJobPath(uid, jobID))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to load import job: %w", err)
+	}
+	return data, nil
+}
+
+// deleteReplacedEntities deletes every existing document of the job's entity
+// types before anything is imported, so references between restored entities
+// never point at a half-replaced collection. It reports false, and the import
+// must stop, when a deletion fails.
+func (s *ImportExportService) deleteReplacedEntities(ctx context.Context, uid string, plan []importPlanItem, job *importJob, result *ImportResult) bool {
+	collections := make(map[EntityType]string, len(plan))
+	for _, item := range plan {
+		collections[item.entityType] = item.collection
+	}
+
+	for _, entityType := range job.entityTypes {
+		deleted, err := s.deleteUserCollection(ctx, uid, collections[entityType])
+		result.DeletedCount += deleted
+		result.DeletedByType[entityType] += deleted
+		if err != nil {
+			result.Success = false
+			result.ErrorCount++
+			result.Errors = append(result.Errors, ImportError{
+				EntityType: entityType,
+				Message:    "existing documents could not be replaced: " + err.Error(),
+			})
+			s.logger.Error("Replace import failed to delete existing documents",
+				zap.String("uid", uid),
+				zap.String("jobId", job.id),
+				zap.String("entityType", string(entityType)),
+				zap.Error(err),
+			)
+			return false
+		}
+	}
+	return true
+}
+
+// deleteUserCollection deletes the user's documents in a top-level collection
+// in batches, returning how many were deleted
+func (s *ImportExportService) deleteUserCollection(ctx context.Context, uid, collection string) (int, error) {
+	var refs []*firestore.DocumentRef
+	query := s.repo.Collection(collection).Where("uid", "==", uid).Select()
+	if err := s.repo.ForEach(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		refs = append(refs, doc.Ref)
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("failed to list %s: %w", collection, err)
+	}
+
+	deleted := 0
+	for i := 0; i < len(refs); i += importBatchLimit {
+		end := i + importBatchLimit
+		if end > len(refs) {
+			end = len(refs)
+		}
+		batch := s.repo.Batch()
+		for _, ref := range refs[i:end] {
+			batch.Delete(ref)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", collection, err)
+		}
+		deleted += end - i
+	}
+	return deleted, nil
+}
+
+// replacedEntityTypes is the entity types a ReplaceAll import replaces: those
+// with entities to import after selection. Types absent from the backup are
+// left untouched.
+func replacedEntityTypes(plan []importPlanItem) []EntityType {
+	var types []EntityType
+	for _, item := range plan {
+		if len(item.entities) > 0 {
+			types = append(types, item.entityType)
+		}
+	}
+	return types
+}
+
+func entityTypesToInterfaces(types []EntityType) []interface{} {
+	values := make([]interface{}, len(types))
+	for i, entityType := range types {
+		values[i] = string(entityType)
+	}
+	return values
+}
+
+func importJobPath(uid, jobID string) string {
+	return fmt.Sprintf("users/%s/importJobs/%s", uid, jobID)
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+var (
+	// ErrInvalidIngestToken is returned for unknown or revoked ingest tokens
+	ErrInvalidIngestToken = errors.New("invalid ingest token")
+	// ErrInvalidIngestPayload is returned for ingested payloads that fail validation
+	ErrInvalidIngestPayload = errors.New("invalid ingest payload")
+	// ErrIngestRateLimited is returned when a token has used its requests for the minute
+	ErrIngestRateLimited = errors.New("ingest rate limit exceeded")
+)
+
+const (
+	// DefaultIngestRequestsPerMinute is the per-token rate limit when config sets none
+	DefaultIngestRequestsPerMinute = 30
+	// maxIngestThoughtLength caps the text of an ingested thought, in characters
+	maxIngestThoughtLength = 10000
+)
+
+// ingestCollections maps the ingestable payload types to their collections
+var ingestCollections = map[string]string{
+	"task":    "tasks",
+	"mood":    "moods",
+	"thought": "thoughts",
+}
+
+// IngestToken is a user's secret incoming-webhook token. Integrations such as
+// IFTTT or Zapier post to a URL holding the token instead of signing in.
+type IngestToken struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IngestPayload is a document sent to the incoming webhook
+type IngestPayload struct {
+	Type string                 `json:"type"` // task, mood or thought
+	Data map[string]interface{} `json:"data"`
+}
+
+// IngestResult is the document an ingested payload created
+type IngestResult struct {
+	Type     string                 `json:"type"`
+	ID       string                 `json:"id"`
+	Document map[string]interface{} `json:"document"`
+}
+
+// ingestWindow counts a token's requests in the current minute
+type ingestWindow struct {
+	start time.Time
+	count int
+}
+
+// IngestService creates documents for the owner of an incoming-webhook
+// token. Tokens are stored at ingestTokens/{token} so a request can be
+// resolved to its user, with the user's current token at
+// users/{uid}/ingestToken/current so it can be rotated and revoked.
+//
+// The per-token rate limit is counted in memory, so each server instance
+// allows requestsPerMinute on its own.
+type IngestService struct {
+	repo              interfaces.Repository
+	logger            *zap.Logger
+	documents         *DocumentService
+	tasks             *TaskService
+	moods             *MoodService
+	requestsPerMinute int
+	now               func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*ingestWindow // keyed by token
+}
+
+// NewIngestService creates a new ingest service. Payload fields are checked
+// against the collection schemas configured in documents; requestsPerMinute
+// <= 0 uses DefaultIngestRequestsPerMinute.
+func NewIngestService(repo interfaces.Repository, logger *zap.Logger, documents *DocumentService, tasks *TaskService, moods *MoodService, requestsPerMinute int) *IngestService {
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = DefaultIngestRequestsPerMinute
+	}
+	return &IngestService{
+		repo:              repo,
+		logger:            logger,
+		documents:         documents,
+		tasks:             tasks,
+		moods:             moods,
+		requestsPerMinute: requestsPerMinute,
+		now:               time.Now,
+		windows:           make(map[string]*ingestWindow),
+	}
+}
+
+// RotateToken issues a new ingest token for the user, revoking the previous one
+func (s *IngestService) RotateToken(ctx context.Context, uid string) (*IngestToken, error) {
+	if err := s.RevokeToken(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate ingest token: %w", err)
+	}
+	token := &IngestToken{
+		Token:     hex.EncodeToString(secret),
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.SetDocument(ctx, ingestTokenPath(token.Token), map[string]interface{}{
+		"uid":       uid,
+		"createdAt": token.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save ingest token: %w", err)
+	}
+	if err := s.repo.SetDocument(ctx, userIngestTokenPath(uid), map[string]interface{}{
+		"token":     token.Token,
+		"createdAt": token.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save ingest token: %w", err)
+	}
+
+	s.logger.Info("Ingest token issued", zap.String("uid", uid))
+	return token, nil
+}
+
+// RevokeToken deletes the user's ingest token, if any
+func (s *IngestService) RevokeToken(ctx context.Context, uid string) error {
+	current, err := s.repo.Get(ctx, userIngestTokenPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load ingest token: %w", err)
+	}
+
+	if token := getStringField(current, "token"); token != "" {
+		if err := s.repo.Delete(ctx, ingestTokenPath(token)); err != nil {
+			return fmt.Errorf("failed to revoke ingest token: %w", err)
+		}
+		s.mu.Lock()
+		delete(s.windows, token)
+		s.mu.Unlock()
+	}
+	if err := s.repo.Delete(ctx, userIngestTokenPath(uid)); err != nil {
+		return fmt.Errorf("failed to revoke ingest token: %w", err)
+	}
+	return nil
+}
+
+// Ingest resolves token to its user and creates the payload's document as
+// that user. Payload fields must be in the collection's configured schema,
+// and the document must pass the same validation as the task and mood
+// endpoints; server-managed fields are ignored.
+func (s *IngestService) Ingest(ctx context.Context, token string, payload IngestPayload) (*IngestResult, error) {
+	if token == "" {
+		return nil, ErrInvalidIngestToken
+	}
+	data, err := s.repo.Get(ctx, ingestTokenPath(token))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil, ErrInvalidIngestToken
+		}
+		return nil, fmt.Errorf("failed to load ingest token: %w", err)
+	}
+	uid := getStringField(data, "uid")
+	if uid == "" {
+		return nil, ErrInvalidIngestToken
+	}
+	if !s.allow(token) {
+		return nil, ErrIngestRateLimited
+	}
+
+	collection, ok := ingestCollections[payload.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: type must be task, mood or thought", ErrInvalidIngestPayload)
+	}
+	if len(payload.Data) == 0 {
+		return nil, fmt.Errorf("%w: data is required", ErrInvalidIngestPayload)
+	}
+	fields := make([]string, 0, len(payload.Data))
+	for field := range payload.Data {
+		fields = append(fields, field)
+	}
+	if err := s.documents.ValidateFields(collection, fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngestPayload, err)
+	}
+
+	var doc map[string]interface{}
+	switch payload.Type {
+	case "task":
+		doc, _, err = s.tasks.CreateTask(ctx, uid, payload.Data, false)
+		if errors.Is(err, ErrInvalidTask) {
+			err = fmt.Errorf("%w: %v", ErrInvalidIngestPayload, err)
+		}
+	case "mood":
+		doc, err = s.moods.CreateMood(ctx, uid, payload.Data)
+		if errors.Is(err, ErrInvalidMood) {
+			err = fmt.Errorf("%w: %v", ErrInvalidIngestPayload, err)
+		}
+	case "thought":
+		doc, err = s.createThought(ctx, uid, payload.Data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := getStringField(doc, "id")
+	s.logger.Info("Ingested document",
+		zap.String("uid", uid),
+		zap.String("type", payload.Type),
+		zap.String("id", id),
+	)
+	return &IngestResult{Type: payload.Type, ID: id, Document: doc}, nil
+}
+
+// createThought stores a new thought; text is required
+func (s *IngestService) createThought(ctx context.Context, uid string, fields map[string]interface{}) (map[string]interface{}, error) {
+	text, _ := fields["text"].(string)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidIngestPayload)
+	}
+	if utf8.RuneCountInString(text) > maxIngestThoughtLength {
+		return nil, fmt.Errorf("%w: text must be at most %d characters", ErrInvalidIngestPayload, maxIngestThoughtLength)
+	}
+
+	thought := make(map[string]interface{}, len(fields)+5)
+	for key, value := range fields {
+		thought[key] = value
+	}
+	for _, field := range protectedDocumentFields {
+		delete(thought, field)
+	}
+	thought["text"] = text
+
+	id := uuid.New().String()
+	now := s.now()
+	thought["id"] = id
+	thought["createdAt"] = now
+	thought["updatedAt"] = now
+	thought["updatedBy"] = uid
+	thought["version"] = 1
+
+	if err := s.repo.Create(ctx, fmt.Sprintf("users/%s/thoughts/%s", uid, id), thought); err != nil {
+		return nil, fmt.Errorf("failed to create thought: %w", err)
+	}
+	return thought, nil
+}
+
+// allow counts a request against the token's fixed one-minute window
+func (s *IngestService) allow(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	window, ok := s.windows[token]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		window = &ingestWindow{start: now}
+		s.windows[token] = window
+	}
+	if window.count >= s.requestsPerMinute {
+		return false
+	}
+	window.count++
+	return true
+}
+
+func ingestTokenPath(token string) string {
+	return fmt.Sprintf("ingestTokens/%s", token)
+}
+
+func userIngestTokenPath(uid string) string {
+	return fmt.Sprintf("users/%s/ingestToken/current", uid)
+}
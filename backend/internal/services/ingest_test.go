@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newTestIngestService(repo *mocks.MockRepository, requestsPerMinute int) *IngestService {
+	logger := zap.NewNop()
+	documents := NewDocumentService(repo, logger, &config.DocumentsConfig{
+		Collections: map[string]config.CollectionConfig{
+			"tasks": {Fields: []string{"title", "priority", "dueDate", "notes"}},
+		},
+	}, nil, nil, nil)
+	return NewIngestService(repo, logger, documents, NewTaskService(repo, logger, nil, nil, nil), NewMoodService(repo, logger), requestsPerMinute)
+}
+
+func TestIngestService_TokenValidation(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := newTestIngestService(repo, 0)
+	ctx := context.Background()
+	payload := IngestPayload{Type: "thought", Data: map[string]interface{}{"text": "Call the bank"}}
+
+	_, err := svc.Ingest(ctx, "", payload)
+	assert.True(t, errors.Is(err, ErrInvalidIngestToken))
+	_, err = svc.Ingest(ctx, "unknown", payload)
+	assert.True(t, errors.Is(err, ErrInvalidIngestToken))
+
+	first, err := svc.RotateToken(ctx, "user-1")
+	require.NoError(t, err)
+	result, err := svc.Ingest(ctx, first.Token, payload)
+	require.NoError(t, err)
+	assert.Equal(t, "thought", result.Type)
+	assert.Equal(t, "Call the bank", repo.Documents["users/user-1/thoughts/"+result.ID]["text"])
+	assert.Equal(t, "user-1", repo.Documents["users/user-1/thoughts/"+result.ID]["updatedBy"])
+
+	second, err := svc.RotateToken(ctx, "user-1")
+	require.NoError(t, err)
+	_, err = svc.Ingest(ctx, first.Token, payload)
+	assert.True(t, errors.Is(err, ErrInvalidIngestToken), "rotation revokes the previous token")
+	_, err = svc.Ingest(ctx, second.Token, payload)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeToken(ctx, "user-1"))
+	_, err = svc.Ingest(ctx, second.Token, payload)
+	assert.True(t, errors.Is(err, ErrInvalidIngestToken))
+}
+
+func TestIngestService_SchemaEnforcement(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := newTestIngestService(repo, 0)
+	ctx := context.Background()
+	token, err := svc.RotateToken(ctx, "user-1")
+	require.NoError(t, err)
+
+	invalid := []IngestPayload{
+		{Type: "goal", Data: map[string]interface{}{"title": "Run a marathon"}},
+		{Type: "task"},
+		{Type: "task", Data: map[string]interface{}{"title": "Pay rent", "assignee": "someone"}},
+		{Type: "task", Data: map[string]interface{}{"priority": "high"}},
+		{Type: "mood", Data: map[string]interface{}{"value": float64(11)}},
+		{Type: "mood", Data: map[string]interface{}{"value": float64(7), "emotions": []interface{}{"sleepy-ish"}}},
+		{Type: "thought", Data: map[string]interface{}{"text": "   "}},
+	}
+	for _, payload := range invalid {
+		_, err := svc.Ingest(ctx, token.Token, payload)
+		assert.True(t, errors.Is(err, ErrInvalidIngestPayload), "%+v: got %v", payload, err)
+	}
+
+	task, err := svc.Ingest(ctx, token.Token, IngestPayload{Type: "task", Data: map[string]interface{}{
+		"title": " Pay rent ", "priority": "high", "id": "chosen-by-client",
+	}})
+	require.NoError(t, err)
+	assert.NotEqual(t, "chosen-by-client", task.ID, "server-managed fields are ignored")
+	stored := repo.Documents["users/user-1/tasks/"+task.ID]
+	assert.Equal(t, "Pay rent", stored["title"])
+	assert.Equal(t, "high", stored["priority"])
+
+	mood, err := svc.Ingest(ctx, token.Token, IngestPayload{Type: "mood", Data: map[string]interface{}{"value": float64(7)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), repo.Documents["users/user-1/moods/"+mood.ID]["value"])
+}
+
+func TestIngestService_RateLimit(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := newTestIngestService(repo, 2)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+	token, err := svc.RotateToken(ctx, "user-1")
+	require.NoError(t, err)
+	payload := IngestPayload{Type: "thought", Data: map[string]interface{}{"text": "Idea"}}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Ingest(ctx, token.Token, payload)
+		require.NoError(t, err)
+	}
+	_, err = svc.Ingest(ctx, token.Token, payload)
+	assert.True(t, errors.Is(err, ErrIngestRateLimited))
+
+	now = now.Add(time.Minute)
+	_, err = svc.Ingest(ctx, token.Token, payload)
+	assert.NoError(t, err, "the limit resets each minute")
+}
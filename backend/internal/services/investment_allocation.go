@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+const (
+	// DefaultAllocationGroupBy is the grouping used when none is requested
+	DefaultAllocationGroupBy = "assetClass"
+	// DefaultConcentrationThreshold is the share of portfolio value (percent)
+	// above which a single holding is flagged as concentrated
+	DefaultConcentrationThreshold = 25.0
+	// UncategorizedAllocationGroup holds investments missing the grouping field
+	UncategorizedAllocationGroup = "Uncategorized"
+)
+
+// allocationGroupings are the investment fields an allocation can group by
+var allocationGroupings = map[string]bool{
+	"assetClass": true,
+	"sector":     true,
+	"currency":   true,
+}
+
+var (
+	// ErrPortfolioNotFound is returned when the user has no portfolio with the ID
+	ErrPortfolioNotFound = errors.New("portfolio not found")
+	// ErrInvalidAllocationRequest is returned for an unknown grouping or an
+	// out-of-range concentration threshold
+	ErrInvalidAllocationRequest = errors.New("invalid allocation request")
+)
+
+// PortfolioAllocation is the share of a portfolio's value in each group,
+// largest first, in the portfolio's currency
+type PortfolioAllocation struct {
+	PortfolioID            string                `json:"portfolioId"`
+	GroupBy                string                `json:"groupBy"`
+	Currency               string                `json:"currency"`
+	TotalValue             float64               `json:"totalValue"`
+	Groups                 []AllocationGroup     `json:"groups"`
+	ConcentrationThreshold float64               `json:"concentrationThreshold"`
+	Concentrated           []ConcentratedHolding `json:"concentrated"`
+	// UnconvertedCurrencies lists currencies with no exchange rate; their
+	// amounts are included unconverted
+	UnconvertedCurrencies []string `json:"unconvertedCurrencies,omitempty"`
+}
+
+// AllocationGroup is one group's value and share of the portfolio
+type AllocationGroup struct {
+	Name            string  `json:"name"`
+	Value           float64 `json:"value"`
+	Percentage      float64 `json:"percentage"`
+	InvestmentCount int     `json:"investmentCount"`
+}
+
+// ConcentratedHolding is an investment whose share of the portfolio exceeds
+// the concentration threshold
+type ConcentratedHolding struct {
+	ID         string  `json:"id"`
+	Ticker     string  `json:"ticker,omitempty"`
+	Value      float64 `json:"value"`
+	Percentage float64 `json:"percentage"`
+}
+
+// ComputeAllocation breaks a portfolio's current value down by groupBy
+// (assetClass, sector or currency; empty means DefaultAllocationGroupBy) and
+// flags holdings worth more than threshold percent of the portfolio
+// (<= 0 means DefaultConcentrationThreshold).
+func (s *InvestmentCalculationService) ComputeAllocation(
+	ctx context.Context,
+	uid string,
+	portfolioID string,
+	groupBy string,
+	threshold float64,
+) (*PortfolioAllocation, error) {
+	if groupBy == "" {
+		groupBy = DefaultAllocationGroupBy
+	}
+	if !allocationGroupings[groupBy] {
+		return nil, fmt.Errorf("%w: cannot group by %q", ErrInvalidAllocationRequest, groupBy)
+	}
+	if threshold <= 0 {
+		threshold = DefaultConcentrationThreshold
+	}
+	if threshold > 100 {
+		return nil, fmt.Errorf("%w: threshold must be at most 100", ErrInvalidAllocationRequest)
+	}
+
+	portfolio, err := s.findPortfolio(ctx, uid, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	investments, err := s.repo.ListWhere(ctx, "investments", []repository.Filter{
+		repository.Eq("uid", uid),
+		repository.Eq("portfolioId", portfolioID),
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch investments: %w", err)
+	}
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	allocation := &PortfolioAllocation{
+		PortfolioID:            portfolioID,
+		GroupBy:                groupBy,
+		Currency:               s.getStringFromMap(portfolio, "baseCurrency", prefs.Currency),
+		Groups:                 []AllocationGroup{},
+		ConcentrationThreshold: threshold,
+		Concentrated:           []ConcentratedHolding{},
+	}
+	converter := s.currency.Converter(ctx, allocation.Currency)
+
+	groups := make(map[string]*AllocationGroup)
+	holdings := make([]ConcentratedHolding, 0, len(investments))
+	for _, investment := range investments {
+		value := converter.Convert(
+			s.getFloatFromMap(investment, "currentValue", 0),
+			s.getStringFromMap(investment, "currency", "USD"),
+		)
+		allocation.TotalValue += value
+
+		name := s.getStringFromMap(investment, groupBy, "")
+		if name == "" {
+			name = UncategorizedAllocationGroup
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = &AllocationGroup{Name: name}
+			groups[name] = group
+		}
+		group.Value += value
+		group.InvestmentCount++
+
+		holdings = append(holdings, ConcentratedHolding{
+			ID:     s.getStringFromMap(investment, "id", ""),
+			Ticker: s.getStringFromMap(investment, "ticker", ""),
+			Value:  value,
+		})
+	}
+	allocation.UnconvertedCurrencies = converter.Unconverted()
+
+	for _, group := range groups {
+		group.Percentage = allocationPercent(group.Value, allocation.TotalValue)
+		allocation.Groups = append(allocation.Groups, *group)
+	}
+	sort.Slice(allocation.Groups, func(i, j int) bool {
+		if allocation.Groups[i].Value != allocation.Groups[j].Value {
+			return allocation.Groups[i].Value > allocation.Groups[j].Value
+		}
+		return allocation.Groups[i].Name < allocation.Groups[j].Name
+	})
+
+	for _, holding := range holdings {
+		holding.Percentage = allocationPercent(holding.Value, allocation.TotalValue)
+		if holding.Percentage > threshold {
+			allocation.Concentrated = append(allocation.Concentrated, holding)
+		}
+	}
+	sort.Slice(allocation.Concentrated, func(i, j int) bool {
+		return allocation.Concentrated[i].Value > allocation.Concentrated[j].Value
+	})
+
+	return allocation, nil
+}
+
+// findPortfolio fetches the user's portfolio with the given ID
+func (s *InvestmentCalculationService) findPortfolio(ctx context.Context, uid, portfolioID string) (map[string]interface{}, error) {
+	portfolios, err := s.repo.ListWhere(ctx, "portfolios", []repository.Filter{
+		repository.Eq("uid", uid),
+		repository.Eq("id", portfolioID),
+	}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch portfolios: %w", err)
+	}
+	if len(portfolios) == 0 {
+		return nil, ErrPortfolioNotFound
+	}
+	return portfolios[0], nil
+}
+
+// allocationPercent is value's share of total; an empty or negative total
+// has no meaningful shares
+func allocationPercent(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return value / total * 100
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newAllocationTestService(t *testing.T) *InvestmentCalculationService {
+	t.Helper()
+	repo := mocks.NewMockRepository()
+	uid := "test-user-123"
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"id": "p1", "uid": uid, "baseCurrency": "USD"})
+	investments := []map[string]interface{}{
+		{"id": "vti", "ticker": "VTI", "assetClass": "equity", "sector": "broad-market", "currentValue": 5000.0},
+		{"id": "aapl", "ticker": "AAPL", "assetClass": "equity", "sector": "technology", "currentValue": 3000.0},
+		{"id": "bnd", "ticker": "BND", "assetClass": "bonds", "currentValue": 1500.0},
+		{"id": "cash", "currentValue": 500.0},
+	}
+	for _, investment := range investments {
+		investment["uid"] = uid
+		investment["portfolioId"] = "p1"
+		investment["currency"] = "USD"
+		repo.AddDocument("investments/"+investment["id"].(string), investment)
+	}
+	return NewInvestmentCalculationService(repo, zap.NewNop(), 0, nil)
+}
+
+func TestComputeAllocation_ByAssetClass(t *testing.T) {
+	service := newAllocationTestService(t)
+
+	allocation, err := service.ComputeAllocation(context.Background(), "test-user-123", "p1", "", 0)
+	if err != nil {
+		t.Fatalf("ComputeAllocation failed: %v", err)
+	}
+
+	if allocation.GroupBy != "assetClass" || allocation.TotalValue != 10000 {
+		t.Fatalf("unexpected allocation: groupBy=%s total=%v", allocation.GroupBy, allocation.TotalValue)
+	}
+	want := []AllocationGroup{
+		{Name: "equity", Value: 8000, Percentage: 80, InvestmentCount: 2},
+		{Name: "bonds", Value: 1500, Percentage: 15, InvestmentCount: 1},
+		{Name: UncategorizedAllocationGroup, Value: 500, Percentage: 5, InvestmentCount: 1},
+	}
+	if len(allocation.Groups) != len(want) {
+		t.Fatalf("expected %d groups, got %+v", len(want), allocation.Groups)
+	}
+	for i, group := range allocation.Groups {
+		if group.Name != want[i].Name || group.Value != want[i].Value ||
+			math.Abs(group.Percentage-want[i].Percentage) > 1e-9 || group.InvestmentCount != want[i].InvestmentCount {
+			t.Errorf("group %d = %+v, want %+v", i, group, want[i])
+		}
+	}
+}
+
+func TestComputeAllocation_BySectorBucketsMissingField(t *testing.T) {
+	service := newAllocationTestService(t)
+
+	allocation, err := service.ComputeAllocation(context.Background(), "test-user-123", "p1", "sector", 0)
+	if err != nil {
+		t.Fatalf("ComputeAllocation failed: %v", err)
+	}
+
+	// bonds and cash have no sector
+	for _, group := range allocation.Groups {
+		if group.Name == UncategorizedAllocationGroup && (group.Value != 2000 || group.InvestmentCount != 2) {
+			t.Errorf("uncategorized group = %+v, want value 2000 from 2 investments", group)
+		}
+	}
+	if len(allocation.Groups) != 3 {
+		t.Errorf("expected 3 sector groups, got %+v", allocation.Groups)
+	}
+}
+
+func TestComputeAllocation_ConcentrationThreshold(t *testing.T) {
+	service := newAllocationTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		threshold float64
+		wantIDs   []string
+	}{
+		// VTI is 50%, AAPL 30%, BND 15%, cash 5%
+		{"default threshold of 25%", 0, []string{"vti", "aapl"}},
+		{"holdings above 40%", 40, []string{"vti"}},
+		{"exactly at the threshold is not concentrated", 50, []string{}},
+		{"low threshold", 10, []string{"vti", "aapl", "bnd"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocation, err := service.ComputeAllocation(ctx, "test-user-123", "p1", "assetClass", tt.threshold)
+			if err != nil {
+				t.Fatalf("ComputeAllocation failed: %v", err)
+			}
+			if len(allocation.Concentrated) != len(tt.wantIDs) {
+				t.Fatalf("expected concentrated %v, got %+v", tt.wantIDs, allocation.Concentrated)
+			}
+			for i, holding := range allocation.Concentrated {
+				if holding.ID != tt.wantIDs[i] {
+					t.Errorf("concentrated[%d] = %s, want %s", i, holding.ID, tt.wantIDs[i])
+				}
+				if holding.Percentage <= allocation.ConcentrationThreshold {
+					t.Errorf("%s at %.1f%% is not above the threshold", holding.ID, holding.Percentage)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeAllocation_Errors(t *testing.T) {
+	service := newAllocationTestService(t)
+	ctx := context.Background()
+
+	if _, err := service.ComputeAllocation(ctx, "test-user-123", "p1", "ticker", 0); !errors.Is(err, ErrInvalidAllocationRequest) {
+		t.Errorf("expected ErrInvalidAllocationRequest for unknown grouping, got %v", err)
+	}
+	if _, err := service.ComputeAllocation(ctx, "test-user-123", "p1", "", 120); !errors.Is(err, ErrInvalidAllocationRequest) {
+		t.Errorf("expected ErrInvalidAllocationRequest for threshold over 100, got %v", err)
+	}
+	if _, err := service.ComputeAllocation(ctx, "other-user", "p1", "", 0); !errors.Is(err, ErrPortfolioNotFound) {
+		t.Errorf("expected ErrPortfolioNotFound for another user's portfolio, got %v", err)
+	}
+}
+
+func TestComputeAllocation_EmptyPortfolio(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"id": "p1", "uid": "test-user-123"})
+	service := NewInvestmentCalculationService(repo, zap.NewNop(), 0, nil)
+
+	allocation, err := service.ComputeAllocation(context.Background(), "test-user-123", "p1", "", 0)
+	if err != nil {
+		t.Fatalf("ComputeAllocation failed: %v", err)
+	}
+	if allocation.TotalValue != 0 || len(allocation.Groups) != 0 || len(allocation.Concentrated) != 0 {
+		t.Errorf("expected an empty allocation, got %+v", allocation)
+	}
+}
@@ -8,43 +8,67 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
 
+// defaultMaxProjectionMonths is the projection horizon used when none is configured
+const defaultMaxProjectionMonths = 360
+
 // InvestmentCalculationService handles investment calculation operations
 type InvestmentCalculationService struct {
-	repo   interfaces.Repository
-	logger *zap.Logger
+	repo                interfaces.Repository
+	logger              *zap.Logger
+	maxProjectionMonths int
+	currency            *CurrencyService
 }
 
-// NewInvestmentCalculationService creates a new investment calculation service
-func NewInvestmentCalculationService(repo interfaces.Repository, logger *zap.Logger) *InvestmentCalculationService {
+// NewInvestmentCalculationService creates a new investment calculation service.
+// maxProjectionMonths <= 0 falls back to defaultMaxProjectionMonths. currency
+// may be nil, in which case totals default to DefaultCurrency and amounts in
+// other currencies are summed unconverted.
+func NewInvestmentCalculationService(repo interfaces.Repository, logger *zap.Logger, maxProjectionMonths int, currency *CurrencyService) *InvestmentCalculationService {
+	if maxProjectionMonths <= 0 {
+		maxProjectionMonths = defaultMaxProjectionMonths
+	}
 	return &InvestmentCalculationService{
-		repo:   repo,
-		logger: logger,
+		repo:                repo,
+		logger:              logger,
+		maxProjectionMonths: maxProjectionMonths,
+		currency:            currency,
 	}
 }
 
 // PortfolioMetrics represents calculated metrics for a portfolio
 type PortfolioMetrics struct {
-	TotalValue      float64            `json:"totalValue"`
-	TotalInvested   float64            `json:"totalInvested"`
-	TotalGain       float64            `json:"totalGain"`
-	ROI             float64            `json:"roi"`
-	InvestmentCount int                `json:"investmentCount"`
-	Currency        string             `json:"currency"`
-	ByInvestment    []InvestmentMetric `json:"byInvestment"`
+	TotalValue       float64            `json:"totalValue"`
+	TotalInvested    float64            `json:"totalInvested"`
+	TotalGain        float64            `json:"totalGain"`
+	RealizedGain     float64            `json:"realizedGain"`
+	UnrealizedGain   float64            `json:"unrealizedGain"`
+	ROI              float64            `json:"roi"`
+	AnnualizedReturn *float64           `json:"annualizedReturn"` // XIRR percentage, null if it cannot be computed
+	InvestmentCount  int                `json:"investmentCount"`
+	Currency         string             `json:"currency"`
+	ByInvestment     []InvestmentMetric `json:"byInvestment"`
+	// UnconvertedCurrencies lists currencies with no exchange rate; their
+	// amounts are included unconverted
+	UnconvertedCurrencies []string `json:"unconvertedCurrencies,omitempty"`
 }
 
 // InvestmentMetric represents metrics for a single investment
 type InvestmentMetric struct {
-	ID            string  `json:"id"`
-	Ticker        string  `json:"ticker,omitempty"`
-	CurrentValue  float64 `json:"currentValue"`
-	InitialAmount float64 `json:"initialAmount"`
-	Gain          float64 `json:"gain"`
-	ROI           float64 `json:"roi"`
-	Currency      string  `json:"currency"`
+	ID               string   `json:"id"`
+	Ticker           string   `json:"ticker,omitempty"`
+	CurrentValue     float64  `json:"currentValue"`
+	InitialAmount    float64  `json:"initialAmount"`
+	Gain             float64  `json:"gain"`
+	RealizedGain     float64  `json:"realizedGain"`   // from sells, FIFO cost basis
+	UnrealizedGain   float64  `json:"unrealizedGain"` // remaining shares at current price
+	CostBasis        float64  `json:"costBasis"`      // cost of shares still held
+	ROI              float64  `json:"roi"`
+	AnnualizedReturn *float64 `json:"annualizedReturn"` // XIRR percentage, null if it cannot be computed
+	Currency         string   `json:"currency"`
 }
 
 // ProjectionPoint represents a point in a projection series
@@ -65,8 +89,19 @@ type ProjectionRequest struct {
 	AnnualReturn  float64        `json:"annualReturn"`
 	Months        int            `json:"months"`
 	Contributions []Contribution `json:"contributions"`
+	Resolution    string         `json:"resolution,omitempty"` // monthly (default), quarterly, yearly
 }
 
+// Projection resolutions control how many points are returned
+const (
+	ProjectionResolutionMonthly   = "monthly"
+	ProjectionResolutionQuarterly = "quarterly"
+	ProjectionResolutionYearly    = "yearly"
+)
+
+// maxContributionStreams caps the number of recurring contributions per projection
+const maxContributionStreams = 20
+
 // Contribution represents a recurring contribution
 type Contribution struct {
 	Amount    float64 `json:"amount"`
@@ -90,20 +125,28 @@ type DashboardSummary struct {
 	TotalValue       float64                    `json:"totalValue"`
 	TotalInvested    float64                    `json:"totalInvested"`
 	TotalGain        float64                    `json:"totalGain"`
+	RealizedGain     float64                    `json:"realizedGain"`
+	UnrealizedGain   float64                    `json:"unrealizedGain"`
 	OverallROI       float64                    `json:"overallROI"`
 	PortfolioCount   int                        `json:"portfolioCount"`
 	InvestmentCount  int                        `json:"investmentCount"`
 	ByCurrency       map[string]CurrencySummary `json:"byCurrency"`
 	TopPerformers    []InvestmentPerformance    `json:"topPerformers"`
 	BottomPerformers []InvestmentPerformance    `json:"bottomPerformers"`
+	// Currency is the currency of the totals; ByCurrency keeps native amounts
+	Currency              string   `json:"currency"`
+	Locale                string   `json:"locale"`
+	UnconvertedCurrencies []string `json:"unconvertedCurrencies,omitempty"`
 }
 
 // CurrencySummary represents summary for a specific currency
 type CurrencySummary struct {
-	TotalValue    float64 `json:"totalValue"`
-	TotalInvested float64 `json:"totalInvested"`
-	TotalGain     float64 `json:"totalGain"`
-	Count         int     `json:"count"`
+	TotalValue     float64 `json:"totalValue"`
+	TotalInvested  float64 `json:"totalInvested"`
+	TotalGain      float64 `json:"totalGain"`
+	RealizedGain   float64 `json:"realizedGain"`
+	UnrealizedGain float64 `json:"unrealizedGain"`
+	Count          int     `json:"count"`
 }
 
 // InvestmentPerformance represents performance data for sorting
@@ -123,61 +166,58 @@ func (s *InvestmentCalculationService) CalculatePortfolioMetrics(
 	uid string,
 	portfolioID string,
 ) (*PortfolioMetrics, error) {
-	// Fetch all portfolios and find the one with matching ID
-	allPortfolios, err := s.repo.List(ctx, "portfolios", 0)
+	// Fetch the user's portfolio with matching ID
+	portfolio, err := s.findPortfolio(ctx, uid, portfolioID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch portfolios: %w", err)
-	}
-
-	var portfolio map[string]interface{}
-	for _, p := range allPortfolios {
-		if id, ok := p["id"].(string); ok && id == portfolioID {
-			if pUID, ok := p["uid"].(string); ok && pUID == uid {
-				portfolio = p
-				break
-			}
-		}
+		return nil, err
 	}
 
-	if portfolio == nil {
-		return nil, fmt.Errorf("portfolio not found")
+	// Fetch only the user's investments in this portfolio
+	investments, err := s.repo.ListWhere(ctx, "investments", []repository.Filter{
+		repository.Eq("uid", uid),
+		repository.Eq("portfolioId", portfolioID),
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch investments: %w", err)
 	}
 
-	// Fetch all investments for this portfolio
-	allInvestments, err := s.repo.List(ctx, "investments", 0)
+	prefs, err := s.currency.GetPreferences(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch investments: %w", err)
+		return nil, err
 	}
 
 	metrics := &PortfolioMetrics{
-		Currency:     s.getStringFromMap(portfolio, "baseCurrency", "USD"),
+		Currency:     s.getStringFromMap(portfolio, "baseCurrency", prefs.Currency),
 		ByInvestment: []InvestmentMetric{},
 	}
+	converter := s.currency.Converter(ctx, metrics.Currency)
 
-	// Filter investments by uid and portfolioId, then calculate metrics
-	for _, investment := range allInvestments {
-		// Filter by uid and portfolioId
-		if invUID, ok := investment["uid"].(string); !ok || invUID != uid {
-			continue
-		}
-		if portID, ok := investment["portfolioId"].(string); !ok || portID != portfolioID {
-			continue
-		}
+	now := time.Now()
+	portfolioFlows := []cashFlow{}
 
+	for _, investment := range investments {
 		invMetric := s.calculateInvestmentMetric(investment)
 		metrics.ByInvestment = append(metrics.ByInvestment, invMetric)
+		for _, flow := range s.investmentCashFlows(investment, now) {
+			flow.Amount = converter.Convert(flow.Amount, invMetric.Currency)
+			portfolioFlows = append(portfolioFlows, flow)
+		}
 
-		// Aggregate to portfolio level
-		metrics.TotalValue += invMetric.CurrentValue
-		metrics.TotalInvested += invMetric.InitialAmount
+		// Aggregate to portfolio level in the portfolio's currency
+		metrics.TotalValue += converter.Convert(invMetric.CurrentValue, invMetric.Currency)
+		metrics.TotalInvested += converter.Convert(invMetric.InitialAmount, invMetric.Currency)
+		metrics.RealizedGain += converter.Convert(invMetric.RealizedGain, invMetric.Currency)
+		metrics.UnrealizedGain += converter.Convert(invMetric.UnrealizedGain, invMetric.Currency)
 		metrics.InvestmentCount++
 	}
+	metrics.UnconvertedCurrencies = converter.Unconverted()
 
 	// Calculate derived metrics
 	metrics.TotalGain = metrics.TotalValue - metrics.TotalInvested
 	if metrics.TotalInvested > 0 {
 		metrics.ROI = (metrics.TotalGain / metrics.TotalInvested) * 100
 	}
+	metrics.AnnualizedReturn = annualizedReturnPercent(portfolioFlows)
 
 	return metrics, nil
 }
@@ -213,6 +253,18 @@ func (s *InvestmentCalculationService) calculateInvestmentMetric(investment map[
 		metric.ROI = (metric.Gain / metric.InitialAmount) * 100
 	}
 
+	// Split gains into realized/unrealized when the investment tracks lots;
+	// buy-and-hold investments have only unrealized gains
+	if lotGains := s.calculateLotGains(investment); lotGains != nil {
+		metric.RealizedGain = lotGains.RealizedGain
+		metric.UnrealizedGain = lotGains.UnrealizedGain
+		metric.CostBasis = lotGains.RemainingCost
+	} else {
+		metric.UnrealizedGain = metric.Gain
+		metric.CostBasis = metric.InitialAmount
+	}
+	metric.AnnualizedReturn = annualizedReturnPercent(s.investmentCashFlows(investment, time.Now()))
+
 	return metric
 }
 
@@ -222,12 +274,23 @@ func (s *InvestmentCalculationService) GenerateProjection(
 	req ProjectionRequest,
 ) (*ProjectionResponse, error) {
 	// Validate inputs
-	if req.Months <= 0 || req.Months > 360 {
-		return nil, fmt.Errorf("months must be between 1 and 360")
+	maxMonths := s.maxProjectionMonths
+	if maxMonths <= 0 {
+		maxMonths = defaultMaxProjectionMonths
+	}
+	if req.Months <= 0 || req.Months > maxMonths {
+		return nil, fmt.Errorf("months must be between 1 and %d", maxMonths)
 	}
 	if req.AnnualReturn < -100 || req.AnnualReturn > 100 {
 		return nil, fmt.Errorf("annual return must be between -100 and 100")
 	}
+	if err := s.validateContributions(req.Contributions); err != nil {
+		return nil, err
+	}
+	step, err := s.resolutionStep(req.Resolution)
+	if err != nil {
+		return nil, err
+	}
 
 	response := &ProjectionResponse{
 		Points: make([]ProjectionPoint, 0, req.Months+1),
@@ -306,9 +369,58 @@ func (s *InvestmentCalculationService) GenerateProjection(
 		response.Summary.EffectiveAnnualRate = (math.Pow(response.FinalYear.TotalValue/req.InitialAmount, 1/years) - 1) * 100
 	}
 
+	// Thin the returned series; FinalYear and Summary are computed from the full monthly series
+	response.Points = s.thinProjectionPoints(response.Points, step)
+
 	return response, nil
 }
 
+// resolutionStep converts a projection resolution into a step in months
+func (s *InvestmentCalculationService) resolutionStep(resolution string) (int, error) {
+	switch resolution {
+	case "", ProjectionResolutionMonthly:
+		return 1, nil
+	case ProjectionResolutionQuarterly:
+		return 3, nil
+	case ProjectionResolutionYearly:
+		return 12, nil
+	default:
+		return 0, fmt.Errorf("unsupported resolution %q", resolution)
+	}
+}
+
+// thinProjectionPoints keeps every step-th month plus the first and last points
+func (s *InvestmentCalculationService) thinProjectionPoints(points []ProjectionPoint, step int) []ProjectionPoint {
+	if step <= 1 || len(points) == 0 {
+		return points
+	}
+
+	thinned := make([]ProjectionPoint, 0, len(points)/step+2)
+	last := len(points) - 1
+	for i, point := range points {
+		if point.Month%step == 0 || i == last {
+			thinned = append(thinned, point)
+		}
+	}
+	return thinned
+}
+
+// validateContributions rejects negative amounts, unknown frequencies and too many streams
+func (s *InvestmentCalculationService) validateContributions(contributions []Contribution) error {
+	if len(contributions) > maxContributionStreams {
+		return fmt.Errorf("at most %d contributions are allowed", maxContributionStreams)
+	}
+	for i, contrib := range contributions {
+		if contrib.Amount < 0 || math.IsNaN(contrib.Amount) || math.IsInf(contrib.Amount, 0) {
+			return fmt.Errorf("contribution %d: amount must be a non-negative number", i)
+		}
+		if s.frequencyToMonthlyMultiplier(contrib.Frequency) == 0 {
+			return fmt.Errorf("contribution %d: unsupported frequency %q", i, contrib.Frequency)
+		}
+	}
+	return nil
+}
+
 // frequencyToMonthlyMultiplier converts contribution frequency to monthly multiplier
 func (s *InvestmentCalculationService) frequencyToMonthlyMultiplier(frequency string) float64 {
 	switch frequency {
@@ -327,50 +439,54 @@ func (s *InvestmentCalculationService) frequencyToMonthlyMultiplier(frequency st
 	}
 }
 
-// CalculateDashboardSummary calculates aggregate summary across all portfolios
+// CalculateDashboardSummary calculates aggregate summary across all portfolios,
+// converting totals into baseCurrency, or into the user's preferred currency
+// when baseCurrency is empty
 func (s *InvestmentCalculationService) CalculateDashboardSummary(
 	ctx context.Context,
 	uid string,
 	baseCurrency string,
 ) (*DashboardSummary, error) {
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if baseCurrency == "" {
+		baseCurrency = prefs.Currency
+	}
+	converter := s.currency.Converter(ctx, baseCurrency)
+
 	summary := &DashboardSummary{
 		ByCurrency:       make(map[string]CurrencySummary),
 		TopPerformers:    []InvestmentPerformance{},
 		BottomPerformers: []InvestmentPerformance{},
+		Currency:         converter.Target(),
+		Locale:           prefs.Locale,
 	}
 
-	// Fetch all portfolios
-	allPortfolios, err := s.repo.List(ctx, "portfolios", 0)
+	// Fetch the user's portfolios
+	portfolios, err := s.repo.ListWhere(ctx, "portfolios", []repository.Filter{repository.Eq("uid", uid)}, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch portfolios: %w", err)
 	}
+	summary.PortfolioCount = len(portfolios)
 
-	// Filter portfolios by uid
-	for _, p := range allPortfolios {
-		if pUID, ok := p["uid"].(string); ok && pUID == uid {
-			summary.PortfolioCount++
-		}
-	}
-
-	// Fetch all investments across all portfolios
-	allInvestments, err := s.repo.List(ctx, "investments", 0)
+	// Fetch the user's investments across all portfolios
+	investments, err := s.repo.ListWhere(ctx, "investments", []repository.Filter{repository.Eq("uid", uid)}, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch investments: %w", err)
 	}
 
 	allPerformances := []InvestmentPerformance{}
 
-	// Filter investments by uid
-	for _, investment := range allInvestments {
-		if invUID, ok := investment["uid"].(string); !ok || invUID != uid {
-			continue
-		}
-
+	for _, investment := range investments {
 		metric := s.calculateInvestmentMetric(investment)
 
-		// Aggregate totals
-		summary.TotalValue += metric.CurrentValue
-		summary.TotalInvested += metric.InitialAmount
+		// Aggregate totals in the base currency
+		summary.TotalValue += converter.Convert(metric.CurrentValue, metric.Currency)
+		summary.TotalInvested += converter.Convert(metric.InitialAmount, metric.Currency)
+		summary.RealizedGain += converter.Convert(metric.RealizedGain, metric.Currency)
+		summary.UnrealizedGain += converter.Convert(metric.UnrealizedGain, metric.Currency)
 		summary.InvestmentCount++
 
 		// Aggregate by currency
@@ -379,6 +495,8 @@ func (s *InvestmentCalculationService) CalculateDashboardSummary(
 		currSummary.TotalValue += metric.CurrentValue
 		currSummary.TotalInvested += metric.InitialAmount
 		currSummary.TotalGain += metric.Gain
+		currSummary.RealizedGain += metric.RealizedGain
+		currSummary.UnrealizedGain += metric.UnrealizedGain
 		currSummary.Count++
 		summary.ByCurrency[currency] = currSummary
 
@@ -394,6 +512,8 @@ func (s *InvestmentCalculationService) CalculateDashboardSummary(
 		})
 	}
 
+	summary.UnconvertedCurrencies = converter.Unconverted()
+
 	// Calculate overall metrics
 	summary.TotalGain = summary.TotalValue - summary.TotalInvested
 	if summary.TotalInvested > 0 {
@@ -445,35 +565,25 @@ func (s *InvestmentCalculationService) GetPortfolioSnapshots(
 	startDate *time.Time,
 	endDate *time.Time,
 ) ([]map[string]interface{}, error) {
-	// Fetch all portfolio snapshots
-	allSnapshots, err := s.repo.List(ctx, "portfolioSnapshots", 0)
+	// Snapshot dates are stored as YYYY-MM-DD strings, so range filters on them
+	// compare chronologically. Uses the (uid, portfolioId, date) composite index.
+	filters := []repository.Filter{
+		repository.Eq("uid", uid),
+		repository.Eq("portfolioId", portfolioID),
+	}
+	if startDate != nil {
+		filters = append(filters, repository.Filter{Field: "date", Op: ">=", Value: startDate.Format("2006-01-02")})
+	}
+	if endDate != nil {
+		filters = append(filters, repository.Filter{Field: "date", Op: "<=", Value: endDate.Format("2006-01-02")})
+	}
+
+	snapshots, err := s.repo.ListWhere(ctx, "portfolioSnapshots", filters, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch snapshots: %w", err)
 	}
-
-	snapshots := []map[string]interface{}{}
-
-	// Filter snapshots by uid, portfolioId, and date range
-	for _, snapshot := range allSnapshots {
-		// Filter by uid and portfolioId
-		if snapUID, ok := snapshot["uid"].(string); !ok || snapUID != uid {
-			continue
-		}
-		if snapPortID, ok := snapshot["portfolioId"].(string); !ok || snapPortID != portfolioID {
-			continue
-		}
-
-		// Filter by date range if provided
-		if dateStr, ok := snapshot["date"].(string); ok {
-			if startDate != nil && dateStr < startDate.Format("2006-01-02") {
-				continue
-			}
-			if endDate != nil && dateStr > endDate.Format("2006-01-02") {
-				continue
-			}
-		}
-
-		snapshots = append(snapshots, snapshot)
+	if snapshots == nil {
+		snapshots = []map[string]interface{}{}
 	}
 
 	return snapshots, nil
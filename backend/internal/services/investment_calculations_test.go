@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"math"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -13,7 +15,7 @@ import (
 func TestInvestmentCalculationService_GenerateProjection(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewInvestmentCalculationService(mockRepo, logger)
+	service := NewInvestmentCalculationService(mockRepo, logger, 0, nil)
 
 	ctx := context.Background()
 
@@ -87,6 +89,52 @@ func TestInvestmentCalculationService_GenerateProjection(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative contribution amount",
+			req: ProjectionRequest{
+				InitialAmount: 10000,
+				AnnualReturn:  7.0,
+				Months:        12,
+				Contributions: []Contribution{
+					{Amount: -500, Frequency: "monthly"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown contribution frequency",
+			req: ProjectionRequest{
+				InitialAmount: 10000,
+				AnnualReturn:  7.0,
+				Months:        12,
+				Contributions: []Contribution{
+					{Amount: 500, Frequency: "fortnightly"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many contribution streams",
+			req: ProjectionRequest{
+				InitialAmount: 10000,
+				AnnualReturn:  7.0,
+				Months:        12,
+				Contributions: make([]Contribution, maxContributionStreams+1),
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero contribution amount is allowed",
+			req: ProjectionRequest{
+				InitialAmount: 10000,
+				AnnualReturn:  7.0,
+				Months:        12,
+				Contributions: []Contribution{
+					{Amount: 0, Frequency: "yearly"},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid return rate",
 			req: ProjectionRequest{
@@ -144,7 +192,7 @@ func TestInvestmentCalculationService_FrequencyToMonthlyMultiplier(t *testing.T)
 func TestInvestmentCalculationService_CalculatePortfolioMetrics(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewInvestmentCalculationService(mockRepo, logger)
+	service := NewInvestmentCalculationService(mockRepo, logger, 0, nil)
 
 	uid := "test-user-123"
 	portfolioID := "portfolio-1"
@@ -220,7 +268,7 @@ func TestInvestmentCalculationService_CalculatePortfolioMetrics(t *testing.T) {
 func TestInvestmentCalculationService_CalculateDashboardSummary(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewInvestmentCalculationService(mockRepo, logger)
+	service := NewInvestmentCalculationService(mockRepo, logger, 0, nil)
 
 	uid := "test-user-123"
 	ctx := context.Background()
@@ -308,3 +356,169 @@ func TestInvestmentCalculationService_CalculateDashboardSummary(t *testing.T) {
 		t.Error("Expected some top performers")
 	}
 }
+
+func TestInvestmentCalculationService_GenerateProjection_Resolution(t *testing.T) {
+	service := NewInvestmentCalculationService(mocks.NewMockRepository(), zap.NewNop(), 0, nil)
+	ctx := context.Background()
+
+	base := ProjectionRequest{
+		InitialAmount: 10000,
+		AnnualReturn:  7.0,
+		Months:        26,
+		Contributions: []Contribution{{Amount: 100, Frequency: "monthly"}},
+	}
+
+	monthly, err := service.GenerateProjection(ctx, base)
+	if err != nil {
+		t.Fatalf("GenerateProjection() error = %v", err)
+	}
+
+	tests := []struct {
+		resolution string
+		wantMonths []int
+	}{
+		{"quarterly", []int{0, 3, 6, 9, 12, 15, 18, 21, 24, 26}},
+		{"yearly", []int{0, 12, 24, 26}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			req := base
+			req.Resolution = tt.resolution
+			resp, err := service.GenerateProjection(ctx, req)
+			if err != nil {
+				t.Fatalf("GenerateProjection() error = %v", err)
+			}
+
+			if len(resp.Points) != len(tt.wantMonths) {
+				t.Fatalf("Expected %d points, got %d", len(tt.wantMonths), len(resp.Points))
+			}
+			for i, month := range tt.wantMonths {
+				if resp.Points[i].Month != month {
+					t.Errorf("Point %d: expected month %d, got %d", i, month, resp.Points[i].Month)
+				}
+				if resp.Points[i] != monthly.Points[month] {
+					t.Errorf("Point for month %d differs from the monthly series", month)
+				}
+			}
+
+			// Final point and summary stay exact
+			if resp.FinalYear != monthly.FinalYear {
+				t.Errorf("FinalYear differs: got %+v, want %+v", resp.FinalYear, monthly.FinalYear)
+			}
+			if resp.Summary != monthly.Summary {
+				t.Errorf("Summary differs: got %+v, want %+v", resp.Summary, monthly.Summary)
+			}
+		})
+	}
+
+	req := base
+	req.Resolution = "daily"
+	if _, err := service.GenerateProjection(ctx, req); err == nil {
+		t.Error("Expected error for unsupported resolution")
+	}
+}
+
+func TestInvestmentCalculationService_GenerateProjection_ConfiguredHorizon(t *testing.T) {
+	service := NewInvestmentCalculationService(mocks.NewMockRepository(), zap.NewNop(), 600, nil)
+	ctx := context.Background()
+
+	req := ProjectionRequest{InitialAmount: 1000, AnnualReturn: 5, Months: 480, Resolution: "yearly"}
+	resp, err := service.GenerateProjection(ctx, req)
+	if err != nil {
+		t.Fatalf("Expected 480 months to be allowed with a 600 month horizon, got %v", err)
+	}
+	if len(resp.Points) != 41 {
+		t.Errorf("Expected 41 yearly points, got %d", len(resp.Points))
+	}
+
+	req.Months = 601
+	if _, err := service.GenerateProjection(ctx, req); err == nil {
+		t.Error("Expected error beyond the configured horizon")
+	}
+}
+
+// scopedQueryRepository fails unfiltered List calls so tests prove a service
+// only reads the documents its filters select
+type scopedQueryRepository struct {
+	*mocks.MockRepository
+}
+
+func (r *scopedQueryRepository) List(ctx context.Context, collectionPath string, limit int) ([]map[string]interface{}, error) {
+	return nil, errors.New("unfiltered list of " + collectionPath)
+}
+
+func TestInvestmentCalculationService_OnlyFetchesUserInvestments(t *testing.T) {
+	repo := &scopedQueryRepository{MockRepository: mocks.NewMockRepository()}
+	service := NewInvestmentCalculationService(repo, zap.NewNop(), 0, nil)
+
+	uid := "test-user-123"
+	ctx := context.Background()
+
+	repo.AddDocument("portfolios/p1", map[string]interface{}{"id": "p1", "uid": uid})
+	repo.AddDocument("portfolios/p2", map[string]interface{}{"id": "p2", "uid": "other-user"})
+	repo.AddDocument("investments/mine", map[string]interface{}{
+		"id": "mine", "uid": uid, "portfolioId": "p1",
+		"currentValue": 1500.0, "initialAmount": 1000.0, "currency": "USD",
+	})
+	repo.AddDocument("investments/theirs", map[string]interface{}{
+		"id": "theirs", "uid": "other-user", "portfolioId": "p1",
+		"currentValue": 9000.0, "initialAmount": 9000.0, "currency": "USD",
+	})
+
+	metrics, err := service.CalculatePortfolioMetrics(ctx, uid, "p1")
+	if err != nil {
+		t.Fatalf("CalculatePortfolioMetrics() error = %v", err)
+	}
+	if metrics.InvestmentCount != 1 || metrics.ByInvestment[0].ID != "mine" {
+		t.Errorf("Expected only the user's investment, got %+v", metrics.ByInvestment)
+	}
+
+	summary, err := service.CalculateDashboardSummary(ctx, uid, "USD")
+	if err != nil {
+		t.Fatalf("CalculateDashboardSummary() error = %v", err)
+	}
+	if summary.PortfolioCount != 1 || summary.InvestmentCount != 1 {
+		t.Errorf("Expected 1 portfolio and 1 investment, got %d and %d", summary.PortfolioCount, summary.InvestmentCount)
+	}
+
+	if _, err := service.CalculatePortfolioMetrics(ctx, uid, "p2"); err == nil {
+		t.Error("Expected another user's portfolio to be not found")
+	}
+}
+
+func TestInvestmentCalculationService_GetPortfolioSnapshots_Scoped(t *testing.T) {
+	repo := &scopedQueryRepository{MockRepository: mocks.NewMockRepository()}
+	service := NewInvestmentCalculationService(repo, zap.NewNop(), 0, nil)
+
+	uid := "test-user-123"
+	ctx := context.Background()
+
+	repo.AddDocument("portfolioSnapshots/s1", map[string]interface{}{"uid": uid, "portfolioId": "p1", "date": "2024-01-05"})
+	repo.AddDocument("portfolioSnapshots/s2", map[string]interface{}{"uid": uid, "portfolioId": "p1", "date": "2024-02-05"})
+	repo.AddDocument("portfolioSnapshots/s3", map[string]interface{}{"uid": uid, "portfolioId": "p2", "date": "2024-01-10"})
+	repo.AddDocument("portfolioSnapshots/s4", map[string]interface{}{"uid": "other-user", "portfolioId": "p1", "date": "2024-01-06"})
+
+	all, err := service.GetPortfolioSnapshots(ctx, uid, "p1", nil, nil)
+	if err != nil {
+		t.Fatalf("GetPortfolioSnapshots() error = %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(all))
+	}
+	for _, snap := range all {
+		if snap["uid"] != uid {
+			t.Errorf("Returned another user's snapshot: %v", snap)
+		}
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	january, err := service.GetPortfolioSnapshots(ctx, uid, "p1", &start, &end)
+	if err != nil {
+		t.Fatalf("GetPortfolioSnapshots() error = %v", err)
+	}
+	if len(january) != 1 || january[0]["id"] != "s1" {
+		t.Errorf("Expected only s1 in January, got %v", january)
+	}
+}
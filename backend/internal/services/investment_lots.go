@@ -0,0 +1,139 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+)
+
+// LotGains holds realized and unrealized gains derived from an investment's lots
+type LotGains struct {
+	RealizedGain    float64 `json:"realizedGain"`
+	UnrealizedGain  float64 `json:"unrealizedGain"`
+	RemainingShares float64 `json:"remainingShares"`
+	RemainingCost   float64 `json:"remainingCost"`
+}
+
+// sellContribution represents a "sell" contribution against an investment's lots
+type sellContribution struct {
+	Shares   float64
+	Proceeds float64
+	Date     time.Time
+}
+
+// parseLots extracts the investment's lots sorted oldest first
+func (s *InvestmentCalculationService) parseLots(investment map[string]interface{}) []models.InvestmentLot {
+	rawLots, ok := investment["lots"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	lots := make([]models.InvestmentLot, 0, len(rawLots))
+	for _, raw := range rawLots {
+		lotMap, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		shares := s.getFloatFromMap(lotMap, "shares", 0)
+		if shares <= 0 {
+			continue
+		}
+		date, _ := parseFlexibleDate(lotMap["date"])
+		lots = append(lots, models.InvestmentLot{
+			Shares:    shares,
+			CostBasis: s.getFloatFromMap(lotMap, "costBasis", 0),
+			Date:      date,
+		})
+	}
+
+	sort.SliceStable(lots, func(i, j int) bool { return lots[i].Date.Before(lots[j].Date) })
+	return lots
+}
+
+// parseSells extracts "sell" contributions sorted oldest first
+func (s *InvestmentCalculationService) parseSells(investment map[string]interface{}) []sellContribution {
+	contribs, ok := investment["contributions"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	sells := []sellContribution{}
+	for _, c := range contribs {
+		contrib, ok := c.(map[string]interface{})
+		if !ok || s.getStringFromMap(contrib, "type", "") != "sell" {
+			continue
+		}
+		shares := s.getFloatFromMap(contrib, "shares", 0)
+		if shares <= 0 {
+			continue
+		}
+		date, _ := parseFlexibleDate(contrib["date"])
+		sells = append(sells, sellContribution{
+			Shares:   shares,
+			Proceeds: s.getFloatFromMap(contrib, "amount", 0),
+			Date:     date,
+		})
+	}
+
+	sort.SliceStable(sells, func(i, j int) bool { return sells[i].Date.Before(sells[j].Date) })
+	return sells
+}
+
+// calculateLotGains applies sells to lots using FIFO cost-basis accounting and
+// values the remaining shares at the investment's current price. A sell only
+// draws on lots bought on or before its date.
+// Returns nil when the investment does not track lots.
+func (s *InvestmentCalculationService) calculateLotGains(investment map[string]interface{}) *LotGains {
+	lots := s.parseLots(investment)
+	if len(lots) == 0 {
+		return nil
+	}
+
+	gains := &LotGains{}
+
+	// Consume the oldest lots first for each sell
+	for _, sell := range s.parseSells(investment) {
+		remaining := sell.Shares
+		soldCost := 0.0
+		soldShares := 0.0
+		for i := range lots {
+			// Lots are sorted oldest first, so the rest were bought after the sell
+			if remaining <= 0 || lots[i].Date.After(sell.Date) {
+				break
+			}
+			if lots[i].Shares <= 0 {
+				continue
+			}
+			take := lots[i].Shares
+			if take > remaining {
+				take = remaining
+			}
+			cost := lots[i].CostPerShare() * take
+			lots[i].CostBasis -= cost
+			lots[i].Shares -= take
+			soldCost += cost
+			soldShares += take
+			remaining -= take
+		}
+		if soldShares == 0 {
+			continue
+		}
+		// Only the proceeds attributable to shares actually held count as realized
+		proceeds := sell.Proceeds * (soldShares / sell.Shares)
+		gains.RealizedGain += proceeds - soldCost
+	}
+
+	for _, lot := range lots {
+		gains.RemainingShares += lot.Shares
+		gains.RemainingCost += lot.CostBasis
+	}
+
+	price := s.getFloatFromMap(investment, "currentPricePerShare", 0)
+	if price == 0 && gains.RemainingShares > 0 {
+		price = s.getFloatFromMap(investment, "currentValue", 0) / gains.RemainingShares
+	}
+	gains.UnrealizedGain = price*gains.RemainingShares - gains.RemainingCost
+
+	return gains
+}
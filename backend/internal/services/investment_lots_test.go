@@ -0,0 +1,136 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInvestmentCalculationService_CalculateLotGains_FIFO(t *testing.T) {
+	service := &InvestmentCalculationService{}
+
+	investment := map[string]interface{}{
+		"currentPricePerShare": 20.0,
+		"lots": []interface{}{
+			// Deliberately out of order: FIFO must use the oldest lot first
+			map[string]interface{}{"shares": 10.0, "costBasis": 150.0, "date": "2021-06-01"},
+			map[string]interface{}{"shares": 10.0, "costBasis": 100.0, "date": "2021-01-01"},
+		},
+		"contributions": []interface{}{
+			map[string]interface{}{"type": "sell", "shares": 15.0, "amount": 270.0, "date": "2022-01-01"},
+		},
+	}
+
+	gains := service.calculateLotGains(investment)
+	if gains == nil {
+		t.Fatal("Expected lot gains")
+	}
+
+	// Sold 10 @ $10 + 5 @ $15 = $175 cost for $270 proceeds
+	if math.Abs(gains.RealizedGain-95) > 0.001 {
+		t.Errorf("Expected realized gain 95, got %f", gains.RealizedGain)
+	}
+	if math.Abs(gains.RemainingShares-5) > 0.001 {
+		t.Errorf("Expected 5 remaining shares, got %f", gains.RemainingShares)
+	}
+	if math.Abs(gains.RemainingCost-75) > 0.001 {
+		t.Errorf("Expected remaining cost 75, got %f", gains.RemainingCost)
+	}
+	// 5 shares @ $20 = $100 against $75 cost
+	if math.Abs(gains.UnrealizedGain-25) > 0.001 {
+		t.Errorf("Expected unrealized gain 25, got %f", gains.UnrealizedGain)
+	}
+}
+
+func TestInvestmentCalculationService_CalculateLotGains_OversoldAndDerivedPrice(t *testing.T) {
+	service := &InvestmentCalculationService{}
+
+	investment := map[string]interface{}{
+		"currentValue": 0.0,
+		"lots": []interface{}{
+			map[string]interface{}{"shares": 4.0, "costBasis": 40.0, "date": "2021-01-01"},
+		},
+		"contributions": []interface{}{
+			// Selling more shares than held only realizes the held portion
+			map[string]interface{}{"type": "sell", "shares": 8.0, "amount": 160.0, "date": "2022-01-01"},
+		},
+	}
+
+	gains := service.calculateLotGains(investment)
+	if gains == nil {
+		t.Fatal("Expected lot gains")
+	}
+	if math.Abs(gains.RealizedGain-40) > 0.001 {
+		t.Errorf("Expected realized gain 40, got %f", gains.RealizedGain)
+	}
+	if gains.RemainingShares != 0 || gains.UnrealizedGain != 0 {
+		t.Errorf("Expected no remaining position, got %+v", gains)
+	}
+}
+
+func TestInvestmentCalculationService_CalculateLotGains_SellBeforeLaterBuy(t *testing.T) {
+	service := &InvestmentCalculationService{}
+
+	investment := map[string]interface{}{
+		"currentPricePerShare": 20.0,
+		"lots": []interface{}{
+			map[string]interface{}{"shares": 5.0, "costBasis": 50.0, "date": "2021-01-01"},
+			map[string]interface{}{"shares": 10.0, "costBasis": 150.0, "date": "2022-06-01"},
+		},
+		"contributions": []interface{}{
+			// Only the 5 shares held on the sell date can be sold
+			map[string]interface{}{"type": "sell", "shares": 8.0, "amount": 160.0, "date": "2022-01-01"},
+		},
+	}
+
+	gains := service.calculateLotGains(investment)
+	if gains == nil {
+		t.Fatal("Expected lot gains")
+	}
+	// 5 of 8 shares sold: $100 proceeds against $50 cost
+	if math.Abs(gains.RealizedGain-50) > 0.001 {
+		t.Errorf("Expected realized gain 50, got %f", gains.RealizedGain)
+	}
+	// The later lot is untouched
+	if math.Abs(gains.RemainingShares-10) > 0.001 {
+		t.Errorf("Expected 10 remaining shares, got %f", gains.RemainingShares)
+	}
+	if math.Abs(gains.RemainingCost-150) > 0.001 {
+		t.Errorf("Expected remaining cost 150, got %f", gains.RemainingCost)
+	}
+}
+
+func TestInvestmentCalculationService_CalculateLotGains_NoLots(t *testing.T) {
+	service := &InvestmentCalculationService{}
+
+	if gains := service.calculateLotGains(map[string]interface{}{"currentValue": 100.0}); gains != nil {
+		t.Errorf("Expected nil gains without lots, got %+v", gains)
+	}
+}
+
+func TestInvestmentCalculationService_CalculateInvestmentMetric_Lots(t *testing.T) {
+	service := &InvestmentCalculationService{}
+
+	withLots := service.calculateInvestmentMetric(map[string]interface{}{
+		"id":            "inv-1",
+		"initialAmount": 100.0,
+		"currentValue":  150.0,
+		"lots": []interface{}{
+			map[string]interface{}{"shares": 10.0, "costBasis": 100.0, "date": "2021-01-01"},
+		},
+	})
+	if math.Abs(withLots.UnrealizedGain-50) > 0.001 || withLots.RealizedGain != 0 {
+		t.Errorf("Unexpected lot-based gains: %+v", withLots)
+	}
+	if math.Abs(withLots.CostBasis-100) > 0.001 {
+		t.Errorf("Expected cost basis 100, got %f", withLots.CostBasis)
+	}
+
+	buyAndHold := service.calculateInvestmentMetric(map[string]interface{}{
+		"id":            "inv-2",
+		"initialAmount": 100.0,
+		"currentValue":  120.0,
+	})
+	if buyAndHold.UnrealizedGain != buyAndHold.Gain || buyAndHold.RealizedGain != 0 {
+		t.Errorf("Expected buy-and-hold gain to be unrealized, got %+v", buyAndHold)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// rebalanceTargetTolerance is how far (in percentage points) targets may
+	// sum from 100, allowing for rounded inputs like 33.3/33.3/33.3
+	rebalanceTargetTolerance = 0.5
+	// rebalanceMinTrade is the smallest amount suggested as a trade; smaller
+	// differences are reported as hold
+	rebalanceMinTrade = 0.01
+)
+
+// Rebalance trade actions
+const (
+	RebalanceActionBuy  = "buy"
+	RebalanceActionSell = "sell"
+	RebalanceActionHold = "hold"
+)
+
+// ErrInvalidRebalanceRequest is returned for targets that are negative or do
+// not sum to about 100%, or a negative new cash amount
+var ErrInvalidRebalanceRequest = errors.New("invalid rebalance request")
+
+// RebalanceRequest describes the target allocation to rebalance a portfolio to
+type RebalanceRequest struct {
+	// GroupBy is the allocation grouping the targets refer to (see ComputeAllocation)
+	GroupBy string `json:"groupBy"`
+	// Targets maps group name to target percentage; groups held but not
+	// listed target 0%
+	Targets map[string]float64 `json:"targets"`
+	// NewCash is additional money to deploy, in the portfolio's currency
+	NewCash float64 `json:"newCash"`
+	// BuyOnly deploys NewCash into underweight groups without selling
+	BuyOnly bool `json:"buyOnly"`
+}
+
+// RebalancePlan is the suggested trades and the drift (resulting minus target
+// percentage) before and after them. It is a calculation only; nothing is traded.
+type RebalancePlan struct {
+	PortfolioID       string           `json:"portfolioId"`
+	GroupBy           string           `json:"groupBy"`
+	Currency          string           `json:"currency"`
+	CurrentValue      float64          `json:"currentValue"`
+	NewCash           float64          `json:"newCash"`
+	ResultingValue    float64          `json:"resultingValue"`
+	BuyOnly           bool             `json:"buyOnly"`
+	Trades            []RebalanceTrade `json:"trades"`
+	MaxCurrentDrift   float64          `json:"maxCurrentDrift"`
+	MaxResultingDrift float64          `json:"maxResultingDrift"`
+	// UnconvertedCurrencies lists currencies with no exchange rate; their
+	// amounts are included unconverted
+	UnconvertedCurrencies []string `json:"unconvertedCurrencies,omitempty"`
+}
+
+// RebalanceTrade is the suggested trade for one group. Amount is positive for
+// buys and negative for sells.
+type RebalanceTrade struct {
+	Group               string  `json:"group"`
+	Action              string  `json:"action"`
+	Amount              float64 `json:"amount"`
+	CurrentValue        float64 `json:"currentValue"`
+	CurrentPercentage   float64 `json:"currentPercentage"`
+	TargetPercentage    float64 `json:"targetPercentage"`
+	ResultingValue      float64 `json:"resultingValue"`
+	ResultingPercentage float64 `json:"resultingPercentage"`
+	CurrentDrift        float64 `json:"currentDrift"`
+	ResultingDrift      float64 `json:"resultingDrift"`
+}
+
+// SuggestRebalance computes the buys and sells that move a portfolio's
+// allocation, plus any new cash, to the target percentages. With BuyOnly the
+// new cash is split across underweight groups in proportion to how far each
+// is below target, so some drift may remain.
+func (s *InvestmentCalculationService) SuggestRebalance(
+	ctx context.Context,
+	uid string,
+	portfolioID string,
+	req RebalanceRequest,
+) (*RebalancePlan, error) {
+	if err := validateRebalanceRequest(req); err != nil {
+		return nil, err
+	}
+
+	allocation, err := s.ComputeAllocation(ctx, uid, portfolioID, req.GroupBy, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RebalancePlan{
+		PortfolioID:           portfolioID,
+		GroupBy:               allocation.GroupBy,
+		Currency:              allocation.Currency,
+		CurrentValue:          allocation.TotalValue,
+		NewCash:               req.NewCash,
+		ResultingValue:        allocation.TotalValue + req.NewCash,
+		BuyOnly:               req.BuyOnly,
+		Trades:                []RebalanceTrade{},
+		UnconvertedCurrencies: allocation.UnconvertedCurrencies,
+	}
+
+	current := make(map[string]float64, len(allocation.Groups))
+	for _, group := range allocation.Groups {
+		current[group.Name] = group.Value
+	}
+	names := make([]string, 0, len(current)+len(req.Targets))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range req.Targets {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Full rebalance moves every group to its target value
+	amounts := make(map[string]float64, len(names))
+	for _, name := range names {
+		amounts[name] = plan.ResultingValue*req.Targets[name]/100 - current[name]
+	}
+	if req.BuyOnly {
+		amounts = buyOnlyAmounts(amounts, req.NewCash)
+	}
+
+	for _, name := range names {
+		amount := amounts[name]
+		if math.Abs(amount) < rebalanceMinTrade {
+			amount = 0
+		}
+		trade := RebalanceTrade{
+			Group:             name,
+			Action:            RebalanceActionHold,
+			Amount:            amount,
+			CurrentValue:      current[name],
+			CurrentPercentage: allocationPercent(current[name], plan.CurrentValue),
+			TargetPercentage:  req.Targets[name],
+			ResultingValue:    current[name] + amount,
+		}
+		switch {
+		case amount > 0:
+			trade.Action = RebalanceActionBuy
+		case amount < 0:
+			trade.Action = RebalanceActionSell
+		}
+		trade.ResultingPercentage = allocationPercent(trade.ResultingValue, plan.ResultingValue)
+		trade.CurrentDrift = trade.CurrentPercentage - trade.TargetPercentage
+		trade.ResultingDrift = trade.ResultingPercentage - trade.TargetPercentage
+		plan.MaxCurrentDrift = math.Max(plan.MaxCurrentDrift, math.Abs(trade.CurrentDrift))
+		plan.MaxResultingDrift = math.Max(plan.MaxResultingDrift, math.Abs(trade.ResultingDrift))
+		plan.Trades = append(plan.Trades, trade)
+	}
+
+	return plan, nil
+}
+
+// buyOnlyAmounts splits newCash across the groups a full rebalance would buy,
+// in proportion to those buys. Since a full rebalance's buys exceed its sells
+// by exactly newCash, the buys always cover it.
+func buyOnlyAmounts(full map[string]float64, newCash float64) map[string]float64 {
+	shortfall := 0.0
+	for _, amount := range full {
+		if amount > 0 {
+			shortfall += amount
+		}
+	}
+
+	amounts := make(map[string]float64, len(full))
+	for name, amount := range full {
+		if amount > 0 && shortfall > 0 {
+			amounts[name] = newCash * amount / shortfall
+		} else {
+			amounts[name] = 0
+		}
+	}
+	return amounts
+}
+
+func validateRebalanceRequest(req RebalanceRequest) error {
+	if len(req.Targets) == 0 {
+		return fmt.Errorf("%w: targets are required", ErrInvalidRebalanceRequest)
+	}
+	sum := 0.0
+	for name, target := range req.Targets {
+		if name == "" || target < 0 || target > 100 {
+			return fmt.Errorf("%w: target for %q must be between 0 and 100", ErrInvalidRebalanceRequest, name)
+		}
+		sum += target
+	}
+	if math.Abs(sum-100) > rebalanceTargetTolerance {
+		return fmt.Errorf("%w: targets sum to %.2f%%, expected 100%%", ErrInvalidRebalanceRequest, sum)
+	}
+	if req.NewCash < 0 {
+		return fmt.Errorf("%w: newCash cannot be negative", ErrInvalidRebalanceRequest)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func findTrade(t *testing.T, plan *RebalancePlan, group string) RebalanceTrade {
+	t.Helper()
+	for _, trade := range plan.Trades {
+		if trade.Group == group {
+			return trade
+		}
+	}
+	t.Fatalf("no trade for group %s in %+v", group, plan.Trades)
+	return RebalanceTrade{}
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestSuggestRebalance_FullRebalance(t *testing.T) {
+	// equity 8000, bonds 1500, uncategorized cash 500
+	service := newAllocationTestService(t)
+
+	plan, err := service.SuggestRebalance(context.Background(), "test-user-123", "p1", RebalanceRequest{
+		Targets: map[string]float64{"equity": 60, "bonds": 30, "international": 10},
+	})
+	if err != nil {
+		t.Fatalf("SuggestRebalance failed: %v", err)
+	}
+
+	assertClose(t, "resulting value", plan.ResultingValue, 10000)
+	equity := findTrade(t, plan, "equity")
+	if equity.Action != RebalanceActionSell {
+		t.Errorf("equity action = %s, want sell", equity.Action)
+	}
+	assertClose(t, "equity amount", equity.Amount, -2000)
+	assertClose(t, "equity current drift", equity.CurrentDrift, 20)
+	assertClose(t, "bonds amount", findTrade(t, plan, "bonds").Amount, 1500)
+	assertClose(t, "international amount", findTrade(t, plan, "international").Amount, 1000)
+
+	// Held groups without a target are sold off
+	uncategorized := findTrade(t, plan, UncategorizedAllocationGroup)
+	assertClose(t, "uncategorized amount", uncategorized.Amount, -500)
+	assertClose(t, "uncategorized target", uncategorized.TargetPercentage, 0)
+
+	assertClose(t, "max current drift", plan.MaxCurrentDrift, 20)
+	assertClose(t, "max resulting drift", plan.MaxResultingDrift, 0)
+}
+
+func TestSuggestRebalance_WithNewCash(t *testing.T) {
+	service := newAllocationTestService(t)
+
+	plan, err := service.SuggestRebalance(context.Background(), "test-user-123", "p1", RebalanceRequest{
+		Targets: map[string]float64{"equity": 70, "bonds": 25, UncategorizedAllocationGroup: 5},
+		NewCash: 2000,
+	})
+	if err != nil {
+		t.Fatalf("SuggestRebalance failed: %v", err)
+	}
+
+	assertClose(t, "resulting value", plan.ResultingValue, 12000)
+	assertClose(t, "equity amount", findTrade(t, plan, "equity").Amount, 400)
+	assertClose(t, "bonds amount", findTrade(t, plan, "bonds").Amount, 1500)
+	assertClose(t, "uncategorized amount", findTrade(t, plan, UncategorizedAllocationGroup).Amount, 100)
+
+	total := 0.0
+	for _, trade := range plan.Trades {
+		total += trade.Amount
+	}
+	assertClose(t, "net trades", total, 2000)
+}
+
+func TestSuggestRebalance_BuyOnly(t *testing.T) {
+	service := newAllocationTestService(t)
+
+	plan, err := service.SuggestRebalance(context.Background(), "test-user-123", "p1", RebalanceRequest{
+		Targets: map[string]float64{"equity": 60, "bonds": 35, UncategorizedAllocationGroup: 5},
+		NewCash: 1000,
+		BuyOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("SuggestRebalance failed: %v", err)
+	}
+
+	// Full rebalance of 11000 would buy bonds 2350 and cash 50, sell equity 1400;
+	// the 1000 of new cash is split across the buys
+	equity := findTrade(t, plan, "equity")
+	if equity.Action != RebalanceActionHold || equity.Amount != 0 {
+		t.Errorf("buy-only must not sell equity, got %+v", equity)
+	}
+	assertClose(t, "bonds amount", findTrade(t, plan, "bonds").Amount, 1000*2350.0/2400)
+	assertClose(t, "uncategorized amount", findTrade(t, plan, UncategorizedAllocationGroup).Amount, 1000*50.0/2400)
+	if plan.MaxResultingDrift <= 0 || plan.MaxResultingDrift >= plan.MaxCurrentDrift {
+		t.Errorf("expected remaining but reduced drift, got current %v resulting %v", plan.MaxCurrentDrift, plan.MaxResultingDrift)
+	}
+}
+
+func TestSuggestRebalance_Validation(t *testing.T) {
+	service := newAllocationTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     RebalanceRequest
+		wantErr error
+	}{
+		{"no targets", RebalanceRequest{}, ErrInvalidRebalanceRequest},
+		{"targets under 100", RebalanceRequest{Targets: map[string]float64{"equity": 60, "bonds": 30}}, ErrInvalidRebalanceRequest},
+		{"targets over 100", RebalanceRequest{Targets: map[string]float64{"equity": 80, "bonds": 30}}, ErrInvalidRebalanceRequest},
+		{"negative target", RebalanceRequest{Targets: map[string]float64{"equity": 110, "bonds": -10}}, ErrInvalidRebalanceRequest},
+		{"negative cash", RebalanceRequest{Targets: map[string]float64{"equity": 100}, NewCash: -1}, ErrInvalidRebalanceRequest},
+		{"unknown grouping", RebalanceRequest{GroupBy: "ticker", Targets: map[string]float64{"equity": 100}}, ErrInvalidAllocationRequest},
+		{"rounded thirds", RebalanceRequest{Targets: map[string]float64{"equity": 33.3, "bonds": 33.3, "cash": 33.3}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SuggestRebalance(ctx, "test-user-123", "p1", tt.req)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// cashFlow represents a dated money movement from the investor's perspective
+// (negative = money in, positive = money out / current value)
+type cashFlow struct {
+	Amount float64
+	Date   time.Time
+}
+
+const (
+	xirrMaxIterations = 100
+	xirrTolerance     = 1e-7
+	xirrLowerBound    = -0.999999
+	xirrUpperBound    = 1e6
+)
+
+// investmentCashFlows builds the cash flow series for a single investment:
+// the initial amount, dated deposits/withdrawals, and the current value as of asOf
+func (s *InvestmentCalculationService) investmentCashFlows(investment map[string]interface{}, asOf time.Time) []cashFlow {
+	flows := []cashFlow{}
+	var earliest time.Time
+
+	if contribs, ok := investment["contributions"].([]interface{}); ok {
+		for _, c := range contribs {
+			contrib, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			date, ok := parseFlexibleDate(contrib["date"])
+			if !ok {
+				continue
+			}
+			amount := s.getFloatFromMap(contrib, "amount", 0)
+			switch s.getStringFromMap(contrib, "type", "") {
+			case "deposit":
+				flows = append(flows, cashFlow{Amount: -amount, Date: date})
+			case "withdrawal", "sell":
+				flows = append(flows, cashFlow{Amount: amount, Date: date})
+			default:
+				continue
+			}
+			if earliest.IsZero() || date.Before(earliest) {
+				earliest = date
+			}
+		}
+	}
+
+	if initial := s.getFloatFromMap(investment, "initialAmount", 0); initial != 0 {
+		date, ok := parseFlexibleDate(investment["createdAt"])
+		if !ok {
+			// Without a creation date, assume the initial amount predates all contributions
+			date = earliest
+		}
+		if !date.IsZero() {
+			flows = append(flows, cashFlow{Amount: -initial, Date: date})
+		}
+	}
+
+	if len(flows) == 0 {
+		return flows
+	}
+
+	flows = append(flows, cashFlow{
+		Amount: s.getFloatFromMap(investment, "currentValue", 0),
+		Date:   asOf,
+	})
+
+	return flows
+}
+
+// annualizedReturnPercent returns the XIRR of the flows as a percentage,
+// or nil if it cannot be computed or does not converge
+func annualizedReturnPercent(flows []cashFlow) *float64 {
+	rate, ok := computeXIRR(flows)
+	if !ok {
+		return nil
+	}
+	pct := rate * 100
+	return &pct
+}
+
+// computeXIRR computes the money-weighted annual rate of return for irregular
+// cash flows using Newton-Raphson, falling back to bisection when Newton fails
+func computeXIRR(flows []cashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+
+	sorted := make([]cashFlow, len(flows))
+	copy(sorted, flows)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	// A rate only exists if money flows both ways over a non-zero period
+	hasPositive, hasNegative := false, false
+	for _, f := range sorted {
+		if f.Amount > 0 {
+			hasPositive = true
+		} else if f.Amount < 0 {
+			hasNegative = true
+		}
+	}
+	if !hasPositive || !hasNegative {
+		return 0, false
+	}
+	if sorted[len(sorted)-1].Date.Sub(sorted[0].Date) < 24*time.Hour {
+		return 0, false
+	}
+
+	start := sorted[0].Date
+	years := make([]float64, len(sorted))
+	for i, f := range sorted {
+		years[i] = f.Date.Sub(start).Hours() / 24 / 365
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for i, f := range sorted {
+			total += f.Amount / math.Pow(1+rate, years[i])
+		}
+		return total
+	}
+	dnpv := func(rate float64) float64 {
+		total := 0.0
+		for i, f := range sorted {
+			total -= years[i] * f.Amount / math.Pow(1+rate, years[i]+1)
+		}
+		return total
+	}
+
+	// Newton-Raphson
+	rate := 0.1
+	for i := 0; i < xirrMaxIterations; i++ {
+		value := npv(rate)
+		if math.Abs(value) < xirrTolerance {
+			return rate, true
+		}
+		derivative := dnpv(rate)
+		if derivative == 0 || math.IsNaN(derivative) || math.IsInf(derivative, 0) {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < xirrTolerance {
+			return next, true
+		}
+		rate = next
+	}
+
+	// Bisection fallback
+	lo, hi := xirrLowerBound, xirrUpperBound
+	fLo, fHi := npv(lo), npv(hi)
+	if math.IsNaN(fLo) || math.IsNaN(fHi) || fLo*fHi > 0 {
+		return 0, false
+	}
+	for i := 0; i < 1000; i++ {
+		mid := (lo + hi) / 2
+		fMid := npv(mid)
+		if math.Abs(fMid) < xirrTolerance || (hi-lo)/2 < xirrTolerance {
+			return mid, true
+		}
+		if fLo*fMid < 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+
+	return 0, false
+}
+
+// parseFlexibleDate converts a Firestore timestamp or date string into a time.Time
+func parseFlexibleDate(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, v); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("invalid date %q: %v", s, err)
+	}
+	return d
+}
+
+func TestComputeXIRR_KnownExamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []cashFlow
+		want  float64
+	}{
+		{
+			// Example from the spreadsheet XIRR documentation
+			name: "irregular withdrawals",
+			flows: []cashFlow{
+				{Amount: -10000, Date: mustDate(t, "2008-01-01")},
+				{Amount: 2750, Date: mustDate(t, "2008-03-01")},
+				{Amount: 4250, Date: mustDate(t, "2008-10-30")},
+				{Amount: 3250, Date: mustDate(t, "2009-02-15")},
+				{Amount: 2750, Date: mustDate(t, "2009-04-01")},
+			},
+			want: 0.373362535,
+		},
+		{
+			name: "ten percent over one year",
+			flows: []cashFlow{
+				{Amount: -1000, Date: mustDate(t, "2021-01-01")},
+				{Amount: 1100, Date: mustDate(t, "2022-01-01")},
+			},
+			want: 0.10,
+		},
+		{
+			name: "loss over two years",
+			flows: []cashFlow{
+				{Amount: -1000, Date: mustDate(t, "2020-01-01")},
+				{Amount: 810, Date: mustDate(t, "2021-12-31")},
+			},
+			want: -0.1,
+		},
+		{
+			name: "unsorted input",
+			flows: []cashFlow{
+				{Amount: 1100, Date: mustDate(t, "2022-01-01")},
+				{Amount: -1000, Date: mustDate(t, "2021-01-01")},
+			},
+			want: 0.10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := computeXIRR(tt.flows)
+			if !ok {
+				t.Fatal("Expected XIRR to converge")
+			}
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("computeXIRR() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeXIRR_NoSolution(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []cashFlow
+	}{
+		{name: "empty", flows: nil},
+		{name: "single flow", flows: []cashFlow{{Amount: -100, Date: mustDate(t, "2021-01-01")}}},
+		{
+			name: "only outflows",
+			flows: []cashFlow{
+				{Amount: -100, Date: mustDate(t, "2021-01-01")},
+				{Amount: -100, Date: mustDate(t, "2022-01-01")},
+			},
+		},
+		{
+			name: "same day",
+			flows: []cashFlow{
+				{Amount: -100, Date: mustDate(t, "2021-01-01")},
+				{Amount: 120, Date: mustDate(t, "2021-01-01")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := computeXIRR(tt.flows); ok {
+				t.Error("Expected XIRR not to be computable")
+			}
+			if annualizedReturnPercent(tt.flows) != nil {
+				t.Error("Expected nil annualized return")
+			}
+		})
+	}
+}
+
+func TestInvestmentCalculationService_InvestmentCashFlows(t *testing.T) {
+	service := &InvestmentCalculationService{}
+	asOf := mustDate(t, "2022-01-01")
+
+	investment := map[string]interface{}{
+		"initialAmount": 1000.0,
+		"currentValue":  1650.0,
+		"createdAt":     "2021-01-01T00:00:00Z",
+		"contributions": []interface{}{
+			map[string]interface{}{"type": "deposit", "amount": 500.0, "date": "2021-07-01"},
+			map[string]interface{}{"type": "withdrawal", "amount": 100.0, "date": "2021-10-01"},
+			map[string]interface{}{"type": "value-update", "amount": 1600.0, "date": "2021-11-01"},
+			map[string]interface{}{"type": "deposit", "amount": 50.0}, // undated, ignored
+		},
+	}
+
+	flows := service.investmentCashFlows(investment, asOf)
+	if len(flows) != 4 {
+		t.Fatalf("Expected 4 cash flows, got %d", len(flows))
+	}
+
+	total := 0.0
+	for _, f := range flows {
+		total += f.Amount
+	}
+	// -1000 - 500 + 100 + 1650
+	if math.Abs(total-250) > 0.001 {
+		t.Errorf("Expected net cash flow 250, got %f", total)
+	}
+
+	pct := annualizedReturnPercent(flows)
+	if pct == nil {
+		t.Fatal("Expected annualized return to be computed")
+	}
+	if *pct <= 0 {
+		t.Errorf("Expected positive annualized return, got %f", *pct)
+	}
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MaxPatchOperations caps the number of operations in one JSON Patch request
+const MaxPatchOperations = 100
+
+var (
+	// ErrInvalidPatch is wrapped into errors for malformed or inapplicable patches
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPatchTestFailed is returned when a "test" operation does not match
+	ErrPatchTestFailed = errors.New("patch test failed")
+	// ErrProtectedField is returned when a patch would modify a server-managed field
+	ErrProtectedField = errors.New("field is protected")
+)
+
+// PatchOperation is a single RFC 6902 operation. Only add, remove, replace
+// and test are supported.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// DecodeJSONPatch parses an RFC 6902 document. Whole numbers decode as int64
+// so they round-trip with Firestore integers.
+func DecodeJSONPatch(data []byte) ([]PatchOperation, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var ops []PatchOperation
+	if err := decoder.Decode(&ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: at least one operation is required", ErrInvalidPatch)
+	}
+	if len(ops) > MaxPatchOperations {
+		return nil, fmt.Errorf("%w: at most %d operations are allowed", ErrInvalidPatch, MaxPatchOperations)
+	}
+	for i := range ops {
+		ops[i].Value = normalizeJSONNumbers(ops[i].Value)
+	}
+	return ops, nil
+}
+
+// applyJSONPatch applies ops to a copy of doc, leaving doc untouched on error.
+// Mutating operations on protected top-level fields are rejected; "test" may
+// read them, e.g. to check "version" for optimistic concurrency.
+func applyJSONPatch(doc map[string]interface{}, ops []PatchOperation, protected []string) (map[string]interface{}, error) {
+	result, _ := deepCopyValue(doc).(map[string]interface{})
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+
+	for i, op := range ops {
+		tokens, err := parseJSONPointer(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%w: operation %d targets the whole document", ErrInvalidPatch, i)
+		}
+
+		switch op.Op {
+		case "test":
+			current, err := lookupJSONPointer(result, tokens)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			if !patchValuesEqual(current, op.Value) {
+				return nil, fmt.Errorf("%w: %s", ErrPatchTestFailed, op.Path)
+			}
+			continue
+		case "add", "remove", "replace":
+		default:
+			return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidPatch, op.Op)
+		}
+
+		for _, field := range protected {
+			if tokens[0] == field {
+				return nil, fmt.Errorf("%w: %s", ErrProtectedField, field)
+			}
+		}
+
+		if _, err := patchNode(result, tokens, op); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// patchNode applies op at tokens beneath node and returns the updated node
+func patchNode(node interface{}, tokens []string, op PatchOperation) (interface{}, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, exists := n[token]
+		if !last {
+			if !exists {
+				return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, op.Path)
+			}
+			updated, err := patchNode(child, tokens[1:], op)
+			if err != nil {
+				return nil, err
+			}
+			n[token] = updated
+			return n, nil
+		}
+
+		switch op.Op {
+		case "add":
+			n[token] = deepCopyValue(op.Value)
+		case "replace":
+			if !exists {
+				return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, op.Path)
+			}
+			n[token] = deepCopyValue(op.Value)
+		case "remove":
+			if !exists {
+				return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, op.Path)
+			}
+			delete(n, token)
+		}
+		return n, nil
+
+	case []interface{}:
+		if last && op.Op == "add" {
+			idx := len(n)
+			if token != "-" {
+				parsed, err := parseArrayIndex(token, len(n)+1)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s", err, op.Path)
+				}
+				idx = parsed
+			}
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = deepCopyValue(op.Value)
+			return n, nil
+		}
+
+		idx, err := parseArrayIndex(token, len(n))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, op.Path)
+		}
+		if !last {
+			updated, err := patchNode(n[idx], tokens[1:], op)
+			if err != nil {
+				return nil, err
+			}
+			n[idx] = updated
+			return n, nil
+		}
+
+		switch op.Op {
+		case "replace":
+			n[idx] = deepCopyValue(op.Value)
+		case "remove":
+			n = append(n[:idx], n[idx+1:]...)
+		}
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, op.Path)
+	}
+}
+
+// lookupJSONPointer returns the value at tokens beneath node
+func lookupJSONPointer(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: path does not exist", ErrPatchTestFailed)
+			}
+			node = child
+		case []interface{}:
+			idx, err := parseArrayIndex(token, len(n))
+			if err != nil {
+				return nil, fmt.Errorf("%w: path does not exist", ErrPatchTestFailed)
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("%w: path does not exist", ErrPatchTestFailed)
+		}
+	}
+	return node, nil
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into unescaped tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// parseArrayIndex parses an array index token that must be below limit
+func parseArrayIndex(token string, limit int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx >= limit {
+		return 0, fmt.Errorf("%w: array index %q out of range", ErrInvalidPatch, token)
+	}
+	return idx, nil
+}
+
+// patchValuesEqual compares JSON values, treating all numeric types by value
+func patchValuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			other, ok := bv[k]
+			if !ok || !patchValuesEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !patchValuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// normalizeJSONNumbers converts json.Number values to int64 or float64
+func normalizeJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeJSONNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSONNumbers(item)
+		}
+		return v
+	}
+	return value
+}
+
+// deepCopyValue copies nested maps and slices so patches never alias the source
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			copied[k] = deepCopyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopyValue(item)
+		}
+		return copied
+	}
+	return value
+}
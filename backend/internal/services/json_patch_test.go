@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchTestDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"title":   "Trip",
+		"version": int64(3),
+		"settings": map[string]interface{}{
+			"reminders": map[string]interface{}{"enabled": false},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"name": "passport", "packed": false},
+			map[string]interface{}{"name": "charger", "packed": true},
+		},
+		"tags": []interface{}{"work"},
+		"a/b":  "slash",
+	}
+}
+
+func mustDecodePatch(t *testing.T, raw string) []PatchOperation {
+	t.Helper()
+	ops, err := DecodeJSONPatch([]byte(raw))
+	require.NoError(t, err)
+	return ops
+}
+
+func TestApplyJSONPatch_NestedObjects(t *testing.T) {
+	doc := patchTestDocument()
+	ops := mustDecodePatch(t, `[
+		{"op": "replace", "path": "/settings/reminders/enabled", "value": true},
+		{"op": "add", "path": "/settings/reminders/minutes", "value": 15},
+		{"op": "remove", "path": "/title"},
+		{"op": "replace", "path": "/a~1b", "value": "escaped"}
+	]`)
+
+	result, err := applyJSONPatch(doc, ops, protectedDocumentFields)
+	require.NoError(t, err)
+
+	reminders := result["settings"].(map[string]interface{})["reminders"].(map[string]interface{})
+	assert.Equal(t, true, reminders["enabled"])
+	assert.Equal(t, int64(15), reminders["minutes"])
+	assert.NotContains(t, result, "title")
+	assert.Equal(t, "escaped", result["a/b"])
+
+	// The source document is not modified
+	assert.Equal(t, "Trip", doc["title"])
+	assert.Equal(t, false, doc["settings"].(map[string]interface{})["reminders"].(map[string]interface{})["enabled"])
+}
+
+func TestApplyJSONPatch_Arrays(t *testing.T) {
+	ops := mustDecodePatch(t, `[
+		{"op": "add", "path": "/tags/-", "value": "travel"},
+		{"op": "add", "path": "/tags/0", "value": "first"},
+		{"op": "replace", "path": "/items/0/packed", "value": true},
+		{"op": "remove", "path": "/items/1"},
+		{"op": "add", "path": "/items/-", "value": {"name": "socks", "packed": false}}
+	]`)
+
+	result, err := applyJSONPatch(patchTestDocument(), ops, protectedDocumentFields)
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"first", "work", "travel"}, result["tags"])
+	items := result["items"].([]interface{})
+	require.Len(t, items, 2)
+	assert.Equal(t, map[string]interface{}{"name": "passport", "packed": true}, items[0])
+	assert.Equal(t, "socks", items[1].(map[string]interface{})["name"])
+}
+
+func TestApplyJSONPatch_TestOperation(t *testing.T) {
+	ok := mustDecodePatch(t, `[
+		{"op": "test", "path": "/version", "value": 3},
+		{"op": "test", "path": "/items/1", "value": {"name": "charger", "packed": true}},
+		{"op": "replace", "path": "/title", "value": "Updated"}
+	]`)
+	result, err := applyJSONPatch(patchTestDocument(), ok, protectedDocumentFields)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", result["title"])
+
+	stale := mustDecodePatch(t, `[
+		{"op": "test", "path": "/version", "value": 2},
+		{"op": "replace", "path": "/title", "value": "Updated"}
+	]`)
+	_, err = applyJSONPatch(patchTestDocument(), stale, protectedDocumentFields)
+	assert.ErrorIs(t, err, ErrPatchTestFailed)
+
+	missing := mustDecodePatch(t, `[{"op": "test", "path": "/nope", "value": 1}]`)
+	_, err = applyJSONPatch(patchTestDocument(), missing, protectedDocumentFields)
+	assert.ErrorIs(t, err, ErrPatchTestFailed)
+}
+
+func TestApplyJSONPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr error
+	}{
+		{name: "protected field", patch: `[{"op": "replace", "path": "/version", "value": 9}]`, wantErr: ErrProtectedField},
+		{name: "protected id", patch: `[{"op": "remove", "path": "/id"}]`, wantErr: ErrProtectedField},
+		{name: "unsupported op", patch: `[{"op": "move", "from": "/title", "path": "/name"}]`, wantErr: ErrInvalidPatch},
+		{name: "whole document", patch: `[{"op": "replace", "path": "", "value": {}}]`, wantErr: ErrInvalidPatch},
+		{name: "missing parent", patch: `[{"op": "add", "path": "/missing/child", "value": 1}]`, wantErr: ErrInvalidPatch},
+		{name: "replace missing key", patch: `[{"op": "replace", "path": "/missing", "value": 1}]`, wantErr: ErrInvalidPatch},
+		{name: "index out of range", patch: `[{"op": "remove", "path": "/tags/5"}]`, wantErr: ErrInvalidPatch},
+		{name: "leading zero index", patch: `[{"op": "replace", "path": "/tags/00", "value": "x"}]`, wantErr: ErrInvalidPatch},
+		{name: "path without slash", patch: `[{"op": "remove", "path": "title"}]`, wantErr: ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyJSONPatch(patchTestDocument(), mustDecodePatch(t, tt.patch), protectedDocumentFields)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDecodeJSONPatch_Invalid(t *testing.T) {
+	for _, raw := range []string{`{}`, `[]`, `not json`} {
+		_, err := DecodeJSONPatch([]byte(raw))
+		assert.ErrorIs(t, err, ErrInvalidPatch, raw)
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	linkPreviewTimeout      = 5 * time.Second
+	linkPreviewMaxBytes     = 512 * 1024
+	linkPreviewMaxRedirects = 3
+	linkPreviewCacheTTL     = 24 * time.Hour
+	linkPreviewCacheSize    = 1000
+	linkPreviewMaxURLLength = 2048
+
+	// linkPreviewMaxField caps each returned text field
+	linkPreviewMaxField = 500
+)
+
+var (
+	// ErrInvalidLinkPreviewURL is returned for URLs that are not absolute http(s) URLs
+	ErrInvalidLinkPreviewURL = errors.New("invalid link preview URL")
+	// ErrLinkPreviewBlocked is returned when a URL resolves to an address the
+	// server must not reach, such as loopback or a private network
+	ErrLinkPreviewBlocked = errors.New("link preview URL is not allowed")
+	// ErrLinkPreviewFetch is returned when the page could not be fetched
+	ErrLinkPreviewFetch = errors.New("failed to fetch link preview")
+)
+
+// LinkPreview is the title, description and image a page advertises
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SiteName    string `json:"siteName"`
+}
+
+type linkPreviewEntry struct {
+	preview   *LinkPreview
+	fetchedAt time.Time
+}
+
+// LinkPreviewService fetches pages server-side and reads their Open Graph
+// and title tags. Connections are only made to public addresses, checked
+// after DNS resolution so a hostname cannot point the server at itself.
+// Previews are cached in memory by URL.
+type LinkPreviewService struct {
+	client *http.Client
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]linkPreviewEntry
+}
+
+// NewLinkPreviewService creates a new link preview service
+func NewLinkPreviewService(logger *zap.Logger) *LinkPreviewService {
+	return newLinkPreviewService(logger, isBlockedLinkPreviewAddr)
+}
+
+// newLinkPreviewService creates a link preview service whose connections are
+// refused for addresses blocked reports true for
+func newLinkPreviewService(logger *zap.Logger, blocked func(netip.Addr) bool) *LinkPreviewService {
+	dialer := &net.Dialer{
+		Timeout: linkPreviewTimeout,
+		// Control runs for every connection attempt with the resolved IP, so
+		// redirects and DNS rebinding are covered too
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || blocked(addr) {
+				return ErrLinkPreviewBlocked
+			}
+			return nil
+		},
+	}
+
+	return &LinkPreviewService{
+		client: &http.Client{
+			Timeout: linkPreviewTimeout,
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   linkPreviewTimeout,
+				ResponseHeaderTimeout: linkPreviewTimeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > linkPreviewMaxRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrLinkPreviewBlocked
+				}
+				return nil
+			},
+		},
+		logger: logger,
+		now:    time.Now,
+		cache:  make(map[string]linkPreviewEntry),
+	}
+}
+
+// isBlockedLinkPreviewAddr reports whether addr is loopback, private,
+// link-local, multicast, unspecified or otherwise not a public unicast address
+func isBlockedLinkPreviewAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return true
+	}
+	for _, prefix := range blockedLinkPreviewPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedLinkPreviewPrefixes are the special-purpose ranges IsGlobalUnicast
+// and IsPrivate do not already cover
+var blockedLinkPreviewPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, can reach IPv4 private ranges
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// Preview returns the preview for rawURL, from the cache when fresh
+func (s *LinkPreviewService) Preview(ctx context.Context, rawURL string) (*LinkPreview, error) {
+	target, err := normalizeLinkPreviewURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	key := target.String()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && s.now().Sub(entry.fetchedAt) < linkPreviewCacheTTL {
+		return entry.preview, nil
+	}
+
+	preview, err := s.fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= linkPreviewCacheSize {
+		s.evictLocked()
+	}
+	s.cache[key] = linkPreviewEntry{preview: preview, fetchedAt: s.now()}
+	s.mu.Unlock()
+	return preview, nil
+}
+
+// evictLocked drops expired entries, or the oldest one when none have expired
+func (s *LinkPreviewService) evictLocked() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.cache {
+		if s.now().Sub(entry.fetchedAt) >= linkPreviewCacheTTL {
+			delete(s.cache, key)
+			continue
+		}
+		if oldestKey == "" || entry.fetchedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.fetchedAt
+		}
+	}
+	if len(s.cache) >= linkPreviewCacheSize {
+		delete(s.cache, oldestKey)
+	}
+}
+
+func (s *LinkPreviewService) fetch(ctx context.Context, target *url.URL) (*LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLinkPreviewURL, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "FocusNotebookLinkPreview/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrLinkPreviewBlocked) {
+			return nil, ErrLinkPreviewBlocked
+		}
+		return nil, fmt.Errorf("%w: %v", ErrLinkPreviewFetch, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: status %d", ErrLinkPreviewFetch, resp.StatusCode)
+	}
+
+	preview := &LinkPreview{URL: target.String()}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		// Images, PDFs and the like have no tags to read
+		return preview, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, linkPreviewMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLinkPreviewFetch, err)
+	}
+	parseLinkPreview(preview, string(body), resp.Request.URL)
+	return preview, nil
+}
+
+// normalizeLinkPreviewURL checks rawURL is an absolute http(s) URL without
+// credentials, dropping its fragment
+func normalizeLinkPreviewURL(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || len(rawURL) > linkPreviewMaxURLLength {
+		return nil, ErrInvalidLinkPreviewURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return nil, ErrInvalidLinkPreviewURL
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u, nil
+}
+
+var (
+	linkPreviewMetaTag   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	linkPreviewTitleTag  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	linkPreviewAttribute = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	linkPreviewBodyStart = regexp.MustCompile(`(?i)<body[\s>]`)
+	linkPreviewSpace     = regexp.MustCompile(`\s+`)
+)
+
+// parseLinkPreview fills preview from the page's Open Graph tags, falling
+// back to Twitter card tags, the description meta tag and the <title>. Only
+// the document head is read.
+func parseLinkPreview(preview *LinkPreview, page string, base *url.URL) {
+	if loc := linkPreviewBodyStart.FindStringIndex(page); loc != nil {
+		page = page[:loc[0]]
+	}
+
+	meta := map[string]string{}
+	for _, tag := range linkPreviewMetaTag.FindAllString(page, -1) {
+		attrs := map[string]string{}
+		for _, m := range linkPreviewAttribute.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+		}
+		name := attrs["property"]
+		if name == "" {
+			name = attrs["name"]
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		content := cleanLinkPreviewText(attrs["content"])
+		if name != "" && content != "" && meta[name] == "" {
+			meta[name] = content
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if meta[key] != "" {
+				return meta[key]
+			}
+		}
+		return ""
+	}
+
+	preview.Title = first("og:title", "twitter:title")
+	if preview.Title == "" {
+		if m := linkPreviewTitleTag.FindStringSubmatch(page); m != nil {
+			preview.Title = cleanLinkPreviewText(m[1])
+		}
+	}
+	preview.Description = first("og:description", "twitter:description", "description")
+	preview.SiteName = first("og:site_name", "application-name")
+	if image := first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"); image != "" {
+		preview.Image = resolveLinkPreviewImage(image, base)
+	}
+	if preview.SiteName == "" && base != nil {
+		preview.SiteName = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+}
+
+// cleanLinkPreviewText unescapes entities, collapses whitespace and caps the length
+func cleanLinkPreviewText(s string) string {
+	s = strings.TrimSpace(linkPreviewSpace.ReplaceAllString(html.UnescapeString(s), " "))
+	if runes := []rune(s); len(runes) > linkPreviewMaxField {
+		s = strings.TrimSpace(string(runes[:linkPreviewMaxField])) + "…"
+	}
+	return s
+}
+
+// resolveLinkPreviewImage makes a relative image URL absolute, dropping
+// anything that is not http(s)
+func resolveLinkPreviewImage(image string, base *url.URL) string {
+	u, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	defaultLLMLogCompactAfter = 30 * 24 * time.Hour
+	defaultLLMLogCompactBatch = 500
+
+	// llmLogCompactionStatePath records how far compaction has got, so each
+	// run only reads logs it has not seen
+	llmLogCompactionStatePath = "cronState/llmLogCompaction"
+)
+
+// llmLogBodyFields are the large, possibly sensitive parts of an LLM log.
+// Everything else is metadata kept for usage accounting.
+var llmLogBodyFields = []string{"prompt", "rawResponse"}
+
+// AI features tagged on LLM logs, so usage can be broken down by where the
+// tokens were spent
+const (
+	AIFeatureThoughtProcessing = "thought-processing"
+	AIFeatureTaskEnrichment    = "task-enrichment"
+)
+
+// LLMLogEntry is one AI request recorded in users/{uid}/llmLogs
+type LLMLogEntry struct {
+	Feature          string // one of the AIFeature constants
+	Trigger          string
+	PromptType       string
+	ThoughtID        string
+	Model            string
+	Prompt           string
+	RawResponse      string
+	Status           string
+	Error            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	// How much user context went into the prompt, for requests that gather it
+	ContextItemsIncluded   int
+	ContextItemsDropped    int
+	ContextEstimatedTokens int
+	ContextMaxTokens       int
+}
+
+// LLMLogSettings is a user's choice of what goes into their LLM logs
+type LLMLogSettings struct {
+	// StoreBodies keeps full prompts and responses; when false only metadata is written
+	StoreBodies bool `json:"storeBodies"`
+}
+
+// LLMLogRecorder writes LLM logs
+type LLMLogRecorder interface {
+	Record(ctx context.Context, uid string, entry LLMLogEntry) (string, error)
+}
+
+// LLMLogService records AI requests and compacts old logs down to their
+// metadata
+type LLMLogService struct {
+	repo         interfaces.Repository
+	logger       *zap.Logger
+	compactAfter time.Duration
+	compactBatch int
+	modelCosts   map[string]float64
+	now          func() time.Time
+}
+
+// NewLLMLogService creates a new LLM log service
+func NewLLMLogService(repo interfaces.Repository, logger *zap.Logger, cfg config.LLMLogsConfig) *LLMLogService {
+	if cfg.CompactAfter <= 0 {
+		cfg.CompactAfter = defaultLLMLogCompactAfter
+	}
+	if cfg.CompactBatch <= 0 {
+		cfg.CompactBatch = defaultLLMLogCompactBatch
+	}
+	return &LLMLogService{
+		repo:         repo,
+		logger:       logger,
+		compactAfter: cfg.CompactAfter,
+		compactBatch: cfg.CompactBatch,
+		modelCosts:   cfg.CostPerMillionTokens,
+		now:          time.Now,
+	}
+}
+
+// GetSettings returns the user's LLM log settings. Bodies are stored unless
+// the user has turned them off.
+func (s *LLMLogService) GetSettings(ctx context.Context, uid string) (LLMLogSettings, error) {
+	settings := LLMLogSettings{StoreBodies: true}
+	doc, err := s.repo.Get(ctx, llmLogSettingsPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to load LLM log settings: %w", err)
+	}
+	if storeBodies, ok := doc["storeBodies"].(bool); ok {
+		settings.StoreBodies = storeBodies
+	}
+	return settings, nil
+}
+
+// UpdateSettings stores the user's LLM log settings. Logs already written
+// are left as they are.
+func (s *LLMLogService) UpdateSettings(ctx context.Context, uid string, settings LLMLogSettings) (LLMLogSettings, error) {
+	if err := s.repo.SetDocument(ctx, llmLogSettingsPath(uid), map[string]interface{}{
+		"storeBodies": settings.StoreBodies,
+		"updatedAt":   s.now(),
+	}); err != nil {
+		return settings, fmt.Errorf("failed to save LLM log settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Record writes an LLM log for the user, leaving out the prompt and
+// response when the user has turned off storing them
+func (s *LLMLogService) Record(ctx context.Context, uid string, entry LLMLogEntry) (string, error) {
+	settings, err := s.GetSettings(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	data := map[string]interface{}{
+		"id":           id,
+		"trigger":      entry.Trigger,
+		"status":       entry.Status,
+		"bodiesStored": settings.StoreBodies,
+		"usage": map[string]interface{}{
+			"prompt_tokens":     entry.PromptTokens,
+			"completion_tokens": entry.CompletionTokens,
+			"total_tokens":      entry.TotalTokens,
+		},
+		"createdAt": s.now(),
+	}
+	setIfNotEmpty(data, "feature", entry.Feature)
+	setIfNotEmpty(data, "promptType", entry.PromptType)
+	setIfNotEmpty(data, "thoughtId", entry.ThoughtID)
+	setIfNotEmpty(data, "error", entry.Error)
+	if entry.Model != "" {
+		data["metadata"] = map[string]interface{}{"model": entry.Model}
+	}
+	if entry.ContextMaxTokens > 0 || entry.ContextItemsIncluded > 0 || entry.ContextItemsDropped > 0 {
+		data["context"] = map[string]interface{}{
+			"itemsIncluded":   entry.ContextItemsIncluded,
+			"itemsDropped":    entry.ContextItemsDropped,
+			"estimatedTokens": entry.ContextEstimatedTokens,
+			"maxTokens":       entry.ContextMaxTokens,
+		}
+	}
+	if settings.StoreBodies {
+		data["prompt"] = entry.Prompt
+		data["rawResponse"] = entry.RawResponse
+	}
+
+	if err := s.repo.Create(ctx, fmt.Sprintf("users/%s/llmLogs/%s", uid, id), data); err != nil {
+		return "", fmt.Errorf("failed to record LLM log: %w", err)
+	}
+	return id, nil
+}
+
+// CompactExpired strips the prompt and response from up to one batch of logs
+// older than the compaction age, across all users. Runs pick up where the
+// previous one stopped. Returns the number of logs compacted.
+func (s *LLMLogService) CompactExpired(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-s.compactAfter)
+	var since time.Time
+	if state, err := s.repo.Get(ctx, llmLogCompactionStatePath); err == nil {
+		since, _ = parseFlexibleDate(state["compactedThrough"])
+	} else if !errors.Is(err, interfaces.ErrNotFound) {
+		return 0, fmt.Errorf("failed to load compaction state: %w", err)
+	}
+
+	// Logs created at exactly since may not have been reached last run, so
+	// they are read again; ones already compacted are skipped
+	query := s.repo.Client().CollectionGroup("llmLogs").
+		Where("createdAt", ">=", since).
+		Where("createdAt", "<", cutoff).
+		OrderBy("createdAt", firestore.Asc).
+		Limit(s.compactBatch)
+
+	compacted := 0
+	reached := since
+	err := s.repo.ForEach(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		data := doc.Data()
+		if createdAt, ok := parseFlexibleDate(data["createdAt"]); ok {
+			reached = createdAt
+		}
+		compactedLog, changed := compactLLMLog(data, s.now())
+		if !changed {
+			return nil
+		}
+		if _, err := doc.Ref.Set(ctx, compactedLog); err != nil {
+			return fmt.Errorf("failed to compact LLM log %s: %w", doc.Ref.Path, err)
+		}
+		compacted++
+		return nil
+	})
+	if err != nil {
+		return compacted, err
+	}
+
+	if reached.After(since) {
+		if err := s.repo.SetDocument(ctx, llmLogCompactionStatePath, map[string]interface{}{
+			"compactedThrough": reached,
+			"updatedAt":        s.now(),
+		}); err != nil {
+			return compacted, fmt.Errorf("failed to save compaction state: %w", err)
+		}
+	}
+
+	if compacted > 0 {
+		s.logger.Info("Compacted LLM logs",
+			zap.Int("logs", compacted),
+			zap.Time("cutoff", cutoff),
+		)
+	}
+	return compacted, nil
+}
+
+// compactLLMLog returns the log without its prompt and response, recording
+// their sizes. changed is false when there was no body to remove.
+func compactLLMLog(data map[string]interface{}, now time.Time) (map[string]interface{}, bool) {
+	compacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		compacted[key] = value
+	}
+
+	changed := false
+	for _, field := range llmLogBodyFields {
+		body, ok := compacted[field]
+		if !ok {
+			continue
+		}
+		if text, ok := body.(string); ok {
+			compacted[field+"Chars"] = len(text)
+		}
+		delete(compacted, field)
+		changed = true
+	}
+	if !changed {
+		return data, false
+	}
+	compacted["bodiesStored"] = false
+	compacted["compactedAt"] = now
+	return compacted, true
+}
+
+func llmLogSettingsPath(uid string) string {
+	return fmt.Sprintf("users/%s/settings/llmLogs", uid)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
+)
+
+func TestLLMLogService_Record_StoresBodiesByDefault(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{})
+
+	id, err := service.Record(context.Background(), "user1", LLMLogEntry{
+		Trigger:     "auto",
+		ThoughtID:   "thought1",
+		Model:       "gpt-4o",
+		Prompt:      "Analyze this thought",
+		RawResponse: `{"actions": []}`,
+		Status:      "completed",
+		TotalTokens: 42,
+	})
+	require.NoError(t, err)
+
+	log := repo.Documents["users/user1/llmLogs/"+id]
+	require.NotNil(t, log)
+	assert.Equal(t, "Analyze this thought", log["prompt"])
+	assert.Equal(t, `{"actions": []}`, log["rawResponse"])
+	assert.Equal(t, true, log["bodiesStored"])
+	assert.Equal(t, "thought1", log["thoughtId"])
+	assert.Equal(t, map[string]interface{}{"model": "gpt-4o"}, log["metadata"])
+	assert.Equal(t, 42, log["usage"].(map[string]interface{})["total_tokens"])
+}
+
+func TestLLMLogService_Record_SkipsBodiesWhenTurnedOff(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{})
+	ctx := context.Background()
+
+	settings, err := service.UpdateSettings(ctx, "user1", LLMLogSettings{StoreBodies: false})
+	require.NoError(t, err)
+	assert.False(t, settings.StoreBodies)
+
+	settings, err = service.GetSettings(ctx, "user1")
+	require.NoError(t, err)
+	assert.False(t, settings.StoreBodies)
+
+	id, err := service.Record(ctx, "user1", LLMLogEntry{
+		Trigger:     "manual",
+		Prompt:      "Analyze this thought",
+		RawResponse: "{}",
+		Status:      "completed",
+		TotalTokens: 10,
+	})
+	require.NoError(t, err)
+
+	log := repo.Documents["users/user1/llmLogs/"+id]
+	require.NotNil(t, log)
+	assert.NotContains(t, log, "prompt")
+	assert.NotContains(t, log, "rawResponse")
+	assert.Equal(t, false, log["bodiesStored"])
+	assert.Equal(t, 10, log["usage"].(map[string]interface{})["total_tokens"])
+
+	// Other users keep the default
+	settings, err = service.GetSettings(ctx, "user2")
+	require.NoError(t, err)
+	assert.True(t, settings.StoreBodies)
+}
+
+func TestCompactLLMLog(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := now.Add(-60 * 24 * time.Hour)
+	usage := map[string]interface{}{"total_tokens": 120}
+	log := map[string]interface{}{
+		"prompt":       "Analyze this thought",
+		"rawResponse":  "{}",
+		"status":       "completed",
+		"trigger":      "auto",
+		"usage":        usage,
+		"metadata":     map[string]interface{}{"model": "gpt-4o"},
+		"bodiesStored": true,
+		"createdAt":    createdAt,
+	}
+
+	compacted, changed := compactLLMLog(log, now)
+	require.True(t, changed)
+	assert.NotContains(t, compacted, "prompt")
+	assert.NotContains(t, compacted, "rawResponse")
+	assert.Equal(t, 20, compacted["promptChars"])
+	assert.Equal(t, 2, compacted["rawResponseChars"])
+	assert.Equal(t, false, compacted["bodiesStored"])
+	assert.Equal(t, now, compacted["compactedAt"])
+	assert.Equal(t, "completed", compacted["status"])
+	assert.Equal(t, "auto", compacted["trigger"])
+	assert.Equal(t, usage, compacted["usage"])
+	assert.Equal(t, map[string]interface{}{"model": "gpt-4o"}, compacted["metadata"])
+	assert.Equal(t, createdAt, compacted["createdAt"])
+
+	// The original is left untouched
+	assert.Contains(t, log, "prompt")
+
+	// A log with no bodies needs no write
+	_, changed = compactLLMLog(compacted, now)
+	assert.False(t, changed)
+}
+
+func TestLLMLogService_CompactExpired_ResumesFromLastRun(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	service := NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{CompactBatch: 2})
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Logs already compacted need no write, so each run only advances the state
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	for path, createdAt := range map[string]time.Time{
+		"users/u1/llmLogs/a":      day(4, 1),
+		"users/u2/llmLogs/b":      day(4, 2),
+		"users/u1/llmLogs/c":      day(4, 3),
+		"users/u1/llmLogs/recent": day(5, 20),
+	} {
+		repo.AddDocument(path, map[string]interface{}{"status": "completed", "bodiesStored": false, "createdAt": createdAt})
+	}
+	repo.AddDocument("users/u1/tasks/t1", map[string]interface{}{"createdAt": day(1, 1)})
+
+	compactedThrough := func() time.Time {
+		state, err := repo.Get(ctx, llmLogCompactionStatePath)
+		require.NoError(t, err)
+		return state["compactedThrough"].(time.Time)
+	}
+
+	compacted, err := service.CompactExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, compacted)
+	assert.Equal(t, day(4, 2), compactedThrough())
+
+	// The next batch starts from the last log reached, across users
+	_, err = service.CompactExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, day(4, 3), compactedThrough())
+
+	// Logs newer than the cutoff are left for later runs
+	_, err = service.CompactExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, day(4, 3), compactedThrough())
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxLocationLabelLength caps a location label in characters
+const maxLocationLabelLength = 100
+
+// Location tags a focus session or mood with where it happened. Coordinates
+// are optional, but lat and lng must be given together.
+type Location struct {
+	Label string   `json:"label"`
+	Lat   *float64 `json:"lat,omitempty"`
+	Lng   *float64 `json:"lng,omitempty"`
+}
+
+// LocationCoordinates is the mean position of the entries tagged with a label
+type LocationCoordinates struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// LocationStats aggregates the focus sessions and moods tagged with one
+// location label. AverageMood is nil when no mood was logged there.
+type LocationStats struct {
+	Label        string               `json:"label"`
+	Sessions     int                  `json:"sessions"`
+	FocusMinutes float64              `json:"focusMinutes"`
+	Moods        int                  `json:"moods"`
+	AverageMood  *float64             `json:"averageMood"`
+	Coordinates  *LocationCoordinates `json:"coordinates,omitempty"`
+}
+
+// LocationAnalytics lists the user's locations, most focus time first
+type LocationAnalytics struct {
+	Locations []LocationStats `json:"locations"`
+}
+
+// ComputeByLocation aggregates completed focus minutes and average mood by
+// location label. Labels are grouped case-insensitively. Coordinates are
+// only included when includeCoordinates is set.
+func (s *DashboardAnalyticsService) ComputeByLocation(ctx context.Context, uid string, includeCoordinates bool) (*LocationAnalytics, error) {
+	var sessions, moods []map[string]interface{}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		sessions, err = s.repo.List(gctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		moods, err = s.repo.List(gctx, fmt.Sprintf("users/%s/moods", uid), 0)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	type locationTotals struct {
+		stats      LocationStats
+		moodSum    float64
+		lat, lng   float64
+		positioned int
+	}
+	byLabel := map[string]*locationTotals{}
+	tally := func(doc map[string]interface{}) *locationTotals {
+		location, err := parseLocation(doc["location"])
+		if err != nil || location == nil {
+			return nil
+		}
+		label := location["label"].(string)
+		key := strings.ToLower(label)
+		totals, ok := byLabel[key]
+		if !ok {
+			totals = &locationTotals{stats: LocationStats{Label: label}}
+			byLabel[key] = totals
+		}
+		lat, hasLat := location["lat"].(float64)
+		lng, hasLng := location["lng"].(float64)
+		if hasLat && hasLng {
+			totals.lat += lat
+			totals.lng += lng
+			totals.positioned++
+		}
+		return totals
+	}
+
+	for _, session := range sessions {
+		// Active sessions only hold their planned duration
+		if active, _ := session["isActive"].(bool); active {
+			continue
+		}
+		totals := tally(session)
+		if totals == nil {
+			continue
+		}
+		totals.stats.Sessions++
+		if minutes, ok := toFloat(session["duration"]); ok && minutes > 0 {
+			totals.stats.FocusMinutes += minutes
+		}
+	}
+	for _, mood := range moods {
+		value, ok := toFloat(mood["value"])
+		if !ok {
+			continue
+		}
+		totals := tally(mood)
+		if totals == nil {
+			continue
+		}
+		totals.stats.Moods++
+		totals.moodSum += value
+	}
+
+	result := &LocationAnalytics{Locations: make([]LocationStats, 0, len(byLabel))}
+	for _, totals := range byLabel {
+		stats := totals.stats
+		if stats.Moods > 0 {
+			average := math.Round(totals.moodSum/float64(stats.Moods)*100) / 100
+			stats.AverageMood = &average
+		}
+		if includeCoordinates && totals.positioned > 0 {
+			stats.Coordinates = &LocationCoordinates{
+				Lat: totals.lat / float64(totals.positioned),
+				Lng: totals.lng / float64(totals.positioned),
+			}
+		}
+		result.Locations = append(result.Locations, stats)
+	}
+	sort.Slice(result.Locations, func(i, j int) bool {
+		a, b := result.Locations[i], result.Locations[j]
+		if a.FocusMinutes != b.FocusMinutes {
+			return a.FocusMinutes > b.FocusMinutes
+		}
+		return a.Label < b.Label
+	})
+	return result, nil
+}
+
+// normalize validates the location and returns it in its stored form
+func (l Location) normalize() (map[string]interface{}, error) {
+	label := strings.TrimSpace(l.Label)
+	if label == "" {
+		return nil, errors.New("location label is required")
+	}
+	if len([]rune(label)) > maxLocationLabelLength {
+		return nil, fmt.Errorf("location label must be at most %d characters", maxLocationLabelLength)
+	}
+	location := map[string]interface{}{"label": label}
+
+	if (l.Lat == nil) != (l.Lng == nil) {
+		return nil, errors.New("location lat and lng must be given together")
+	}
+	if l.Lat != nil {
+		if math.IsNaN(*l.Lat) || *l.Lat < -90 || *l.Lat > 90 {
+			return nil, errors.New("location lat must be between -90 and 90")
+		}
+		if math.IsNaN(*l.Lng) || *l.Lng < -180 || *l.Lng > 180 {
+			return nil, errors.New("location lng must be between -180 and 180")
+		}
+		location["lat"] = *l.Lat
+		location["lng"] = *l.Lng
+	}
+	return location, nil
+}
+
+// parseLocation validates a location read from a request body or a stored
+// document. A nil location is valid and returns nil.
+func parseLocation(raw interface{}) (map[string]interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("location must be an object")
+	}
+
+	var location Location
+	if label, ok := fields["label"].(string); ok {
+		location.Label = label
+	}
+	for key, target := range map[string]**float64{"lat": &location.Lat, "lng": &location.Lng} {
+		value, present := fields[key]
+		if !present || value == nil {
+			continue
+		}
+		f, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("location %s must be a number", key)
+		}
+		*target = &f
+	}
+	return location.normalize()
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestDashboardAnalyticsService_ComputeByLocation(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	home := map[string]interface{}{"label": "Home", "lat": 45.0, "lng": -73.0}
+	office := map[string]interface{}{"label": "Office"}
+	repo.AddDocument("users/user1/focusSessions/s1", map[string]interface{}{"duration": int64(50), "isActive": false, "location": office})
+	repo.AddDocument("users/user1/focusSessions/s2", map[string]interface{}{"duration": int64(25), "isActive": false, "location": map[string]interface{}{"label": "  Office"}})
+	repo.AddDocument("users/user1/focusSessions/s3", map[string]interface{}{"duration": int64(30), "isActive": false, "location": home})
+	repo.AddDocument("users/user1/focusSessions/s4", map[string]interface{}{"duration": float64(90), "isActive": true, "location": home})
+	repo.AddDocument("users/user1/focusSessions/s5", map[string]interface{}{"duration": int64(40), "isActive": false})
+	repo.AddDocument("users/user1/moods/m1", map[string]interface{}{"value": int64(8), "location": map[string]interface{}{"label": "home"}})
+	repo.AddDocument("users/user1/moods/m2", map[string]interface{}{"value": int64(5), "location": map[string]interface{}{"label": "Home", "lat": 47.0, "lng": -71.0}})
+	repo.AddDocument("users/user1/moods/m3", map[string]interface{}{"value": int64(3)})
+
+	analytics, err := svc.ComputeByLocation(ctx, "user1", false)
+	require.NoError(t, err)
+	require.Len(t, analytics.Locations, 2)
+
+	officeStats := analytics.Locations[0]
+	assert.Equal(t, "Office", officeStats.Label)
+	assert.Equal(t, 2, officeStats.Sessions)
+	assert.Equal(t, 75.0, officeStats.FocusMinutes)
+	assert.Equal(t, 0, officeStats.Moods)
+	assert.Nil(t, officeStats.AverageMood)
+
+	homeStats := analytics.Locations[1]
+	assert.Equal(t, "Home", homeStats.Label)
+	assert.Equal(t, 1, homeStats.Sessions)
+	assert.Equal(t, 30.0, homeStats.FocusMinutes)
+	assert.Equal(t, 2, homeStats.Moods)
+	require.NotNil(t, homeStats.AverageMood)
+	assert.Equal(t, 6.5, *homeStats.AverageMood)
+	assert.Nil(t, homeStats.Coordinates, "coordinates are only exposed on request")
+
+	analytics, err = svc.ComputeByLocation(ctx, "user1", true)
+	require.NoError(t, err)
+	assert.Nil(t, analytics.Locations[0].Coordinates, "office has no coordinates")
+	assert.Equal(t, &LocationCoordinates{Lat: 46, Lng: -72}, analytics.Locations[1].Coordinates)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// ErrInvalidMerchantAlias is returned when an alias rule is incomplete or its pattern does not compile
+var ErrInvalidMerchantAlias = errors.New("invalid merchant alias")
+
+// merchantAliasRule is a compiled alias rule
+type merchantAliasRule struct {
+	canonical string
+	prefix    string
+	pattern   *regexp.Regexp
+}
+
+func (r merchantAliasRule) matches(normalized string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(normalized)
+	}
+	return r.prefix != "" && strings.HasPrefix(normalized, r.prefix)
+}
+
+// MerchantMatcher maps merchant names to canonical names using an ordered list
+// of alias rules. A nil matcher leaves every name unchanged.
+type MerchantMatcher struct {
+	rules []merchantAliasRule
+}
+
+// Canonicalize returns the canonical name for a merchant and whether an alias
+// rule matched. Rules are matched against the normalized name; the first match wins.
+func (m *MerchantMatcher) Canonicalize(name string) (string, bool) {
+	if m == nil {
+		return name, false
+	}
+	normalized := normalizeMerchantName(name)
+	if normalized == "" {
+		return name, false
+	}
+	for _, rule := range m.rules {
+		if rule.matches(normalized) {
+			return rule.canonical, true
+		}
+	}
+	return name, false
+}
+
+// MerchantAliasService manages merchant alias rules. Global rules come from
+// config; each user can add rules in users/{uid}/merchantAliases, which take
+// precedence over the global ones.
+type MerchantAliasService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	global []merchantAliasRule
+}
+
+// NewMerchantAliasService creates a new merchant alias service. Invalid config
+// rules are logged and skipped.
+func NewMerchantAliasService(repo interfaces.Repository, logger *zap.Logger, cfg *config.MerchantsConfig) *MerchantAliasService {
+	s := &MerchantAliasService{
+		repo:   repo,
+		logger: logger,
+	}
+	if cfg == nil {
+		return s
+	}
+	for _, alias := range cfg.Aliases {
+		rule, err := compileMerchantAlias(alias.Canonical, normalizeMerchantName(alias.Prefix), alias.Pattern)
+		if err != nil {
+			logger.Warn("Skipping merchant alias from config",
+				zap.String("canonical", alias.Canonical),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.global = append(s.global, rule)
+	}
+	return s
+}
+
+// Matcher returns a matcher with the user's rules followed by the global rules.
+// If the user's rules cannot be loaded, only the global rules are used.
+func (s *MerchantAliasService) Matcher(ctx context.Context, uid string) *MerchantMatcher {
+	if s == nil {
+		return nil
+	}
+
+	rules := []merchantAliasRule{}
+	aliases, err := s.repo.List(ctx, merchantAliasesPath(uid), 0)
+	if err != nil {
+		s.logger.Warn("Failed to load merchant aliases", zap.String("uid", uid), zap.Error(err))
+	}
+	for _, alias := range aliases {
+		canonical, _ := alias["canonical"].(string)
+		prefix, _ := alias["prefix"].(string)
+		pattern, _ := alias["pattern"].(string)
+		rule, err := compileMerchantAlias(canonical, prefix, pattern)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return &MerchantMatcher{rules: append(rules, s.global...)}
+}
+
+// MerchantAliasInput is a user-defined alias rule. Exactly one of Prefix or
+// Pattern must be set.
+type MerchantAliasInput struct {
+	Canonical string `json:"canonical"`
+	Prefix    string `json:"prefix,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+}
+
+// ListAliases returns the user's own alias rules
+func (s *MerchantAliasService) ListAliases(ctx context.Context, uid string) ([]map[string]interface{}, error) {
+	aliases, err := s.repo.List(ctx, merchantAliasesPath(uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchant aliases: %w", err)
+	}
+	if aliases == nil {
+		aliases = []map[string]interface{}{}
+	}
+	return aliases, nil
+}
+
+// AddAlias validates and stores a user alias rule. Prefixes are normalized the
+// same way merchant names are, so "AMZN Mktp" matches "amzn mktp us".
+func (s *MerchantAliasService) AddAlias(ctx context.Context, uid string, input MerchantAliasInput) (map[string]interface{}, error) {
+	input.Canonical = strings.TrimSpace(input.Canonical)
+	input.Prefix = normalizeMerchantName(input.Prefix)
+	input.Pattern = strings.TrimSpace(input.Pattern)
+
+	if _, err := compileMerchantAlias(input.Canonical, input.Prefix, input.Pattern); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	alias := map[string]interface{}{
+		"id":        id,
+		"canonical": input.Canonical,
+		"createdAt": time.Now(),
+	}
+	if input.Prefix != "" {
+		alias["prefix"] = input.Prefix
+	}
+	if input.Pattern != "" {
+		alias["pattern"] = input.Pattern
+	}
+
+	if err := s.repo.Create(ctx, fmt.Sprintf("%s/%s", merchantAliasesPath(uid), id), alias); err != nil {
+		return nil, fmt.Errorf("failed to create merchant alias: %w", err)
+	}
+	return alias, nil
+}
+
+// DeleteAlias removes one of the user's alias rules
+func (s *MerchantAliasService) DeleteAlias(ctx context.Context, uid, aliasID string) error {
+	path := fmt.Sprintf("%s/%s", merchantAliasesPath(uid), aliasID)
+	if _, err := s.repo.Get(ctx, path); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete merchant alias: %w", err)
+	}
+	return nil
+}
+
+func merchantAliasesPath(uid string) string {
+	return fmt.Sprintf("users/%s/merchantAliases", uid)
+}
+
+func compileMerchantAlias(canonical, prefix, pattern string) (merchantAliasRule, error) {
+	if canonical == "" {
+		return merchantAliasRule{}, fmt.Errorf("%w: canonical is required", ErrInvalidMerchantAlias)
+	}
+	if (prefix == "") == (pattern == "") {
+		return merchantAliasRule{}, fmt.Errorf("%w: exactly one of prefix or pattern is required", ErrInvalidMerchantAlias)
+	}
+
+	rule := merchantAliasRule{canonical: canonical, prefix: prefix}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return merchantAliasRule{}, fmt.Errorf("%w: %v", ErrInvalidMerchantAlias, err)
+		}
+		rule.pattern = re
+	}
+	return rule, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// testMerchantsConfig mirrors the aliases shipped in config/config.yaml
+var testMerchantsConfig = &config.MerchantsConfig{
+	Aliases: []config.MerchantAliasRule{
+		{Canonical: "Amazon", Pattern: "^(amzn|amazon)"},
+		{Canonical: "Whole Foods Market", Pattern: "^(wholefds|whole foods)"},
+		{Canonical: "Uber Eats", Pattern: "^uber ?eats"},
+		{Canonical: "Uber", Prefix: "UBER"},
+		{Canonical: "Starbucks", Prefix: "starbucks"},
+		{Canonical: "Netflix", Prefix: "netflix"},
+		{Canonical: "Apple", Pattern: "^(apple ?com|apl ?itunes)"},
+	},
+}
+
+func TestMerchantMatcher_RealWorldNames(t *testing.T) {
+	service := NewMerchantAliasService(mocks.NewMockRepository(), zap.NewNop(), testMerchantsConfig)
+	matcher := service.Matcher(context.Background(), "user-1")
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"AMZN Mktp US*2K4LP9XY2", "Amazon"},
+		{"Amazon.com*MK1AB2CD3", "Amazon"},
+		{"AMAZON PRIME*TL8Q04YZ0", "Amazon"},
+		{"WHOLEFDS MKT 10234", "Whole Foods Market"},
+		{"Whole Foods Market #1027", "Whole Foods Market"},
+		{"UBER *EATS PENDING", "Uber Eats"},
+		{"UberEats", "Uber Eats"},
+		{"UBER   *TRIP HELP.UBER.COM", "Uber"},
+		{"STARBUCKS STORE 01458", "Starbucks"},
+		{"NETFLIX.COM", "Netflix"},
+		{"APPLE.COM/BILL", "Apple"},
+		{"APL*ITUNES.COM/BILL", "Apple"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := matcher.Canonicalize(tt.raw)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, ok := matcher.Canonicalize("Corner Bakery #22")
+	assert.False(t, ok)
+	assert.Equal(t, "Corner Bakery #22", got)
+}
+
+func TestMerchantMatcher_Nil(t *testing.T) {
+	var matcher *MerchantMatcher
+	got, ok := matcher.Canonicalize("AMZN Mktp US")
+	assert.False(t, ok)
+	assert.Equal(t, "AMZN Mktp US", got)
+
+	var service *MerchantAliasService
+	assert.Nil(t, service.Matcher(context.Background(), "user-1"))
+}
+
+func TestMerchantAliasService_UserAliasesTakePrecedence(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewMerchantAliasService(repo, zap.NewNop(), testMerchantsConfig)
+	ctx := context.Background()
+
+	_, err := service.AddAlias(ctx, "user-1", MerchantAliasInput{Canonical: "Amazon Fresh", Prefix: "AMZN Fresh"})
+	require.NoError(t, err)
+	_, err = service.AddAlias(ctx, "user-1", MerchantAliasInput{Canonical: "Blue Bottle", Pattern: "^sq ?blue bottle"})
+	require.NoError(t, err)
+
+	matcher := service.Matcher(ctx, "user-1")
+
+	got, _ := matcher.Canonicalize("AMZN FRESH*RT4KL")
+	assert.Equal(t, "Amazon Fresh", got)
+	got, _ = matcher.Canonicalize("AMZN Mktp US*2K4LP9XY2")
+	assert.Equal(t, "Amazon", got)
+	got, _ = matcher.Canonicalize("SQ *BLUE BOTTLE COFFE")
+	assert.Equal(t, "Blue Bottle", got)
+
+	// Another user's aliases do not apply
+	got, ok := service.Matcher(ctx, "user-2").Canonicalize("SQ *BLUE BOTTLE COFFE")
+	assert.False(t, ok)
+	assert.Equal(t, "SQ *BLUE BOTTLE COFFE", got)
+}
+
+func TestMerchantAliasService_AddAlias_Invalid(t *testing.T) {
+	service := NewMerchantAliasService(mocks.NewMockRepository(), zap.NewNop(), nil)
+	ctx := context.Background()
+
+	inputs := []MerchantAliasInput{
+		{Prefix: "amzn"},
+		{Canonical: "Amazon"},
+		{Canonical: "Amazon", Prefix: "amzn", Pattern: "^amzn"},
+		{Canonical: "Amazon", Pattern: "^(amzn"},
+	}
+	for _, input := range inputs {
+		_, err := service.AddAlias(ctx, "user-1", input)
+		assert.True(t, errors.Is(err, ErrInvalidMerchantAlias), "input %+v", input)
+	}
+}
+
+func TestMerchantAliasService_ListAndDelete(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewMerchantAliasService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	alias, err := service.AddAlias(ctx, "user-1", MerchantAliasInput{Canonical: "Costco", Prefix: "COSTCO WHSE"})
+	require.NoError(t, err)
+	assert.Equal(t, "costco whse", alias["prefix"])
+
+	aliases, err := service.ListAliases(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, aliases, 1)
+
+	require.NoError(t, service.DeleteAlias(ctx, "user-1", alias["id"].(string)))
+	assert.Error(t, service.DeleteAlias(ctx, "user-1", alias["id"].(string)))
+
+	aliases, err = service.ListAliases(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, aliases)
+}
+
+func TestNewMerchantAliasService_SkipsInvalidConfigRules(t *testing.T) {
+	service := NewMerchantAliasService(mocks.NewMockRepository(), zap.NewNop(), &config.MerchantsConfig{
+		Aliases: []config.MerchantAliasRule{
+			{Canonical: "Broken", Pattern: "("},
+			{Canonical: "Target", Prefix: "target"},
+		},
+	})
+
+	got, ok := service.Matcher(context.Background(), "user-1").Canonicalize("TARGET 00012345")
+	assert.True(t, ok)
+	assert.Equal(t, "Target", got)
+}
+
+func TestSpendingAnalytics_TopMerchantsGroupAliases(t *testing.T) {
+	aliases := NewMerchantAliasService(mocks.NewMockRepository(), zap.NewNop(), testMerchantsConfig)
+	service := NewSpendingAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), aliases, nil)
+
+	transactions := []map[string]interface{}{
+		{"merchant": map[string]interface{}{"name": "AMZN Mktp US*2K4LP9XY2"}, "amount": 20.0},
+		{"merchant": map[string]interface{}{"name": "Amazon.com*MK1AB2CD3"}, "amount": 30.0},
+		{"merchant": "STARBUCKS STORE 01458", "amount": 5.0},
+	}
+
+	merchants := service.computeTopMerchants(transactions, aliases.Matcher(context.Background(), "user-1"))
+	require.Len(t, merchants, 2)
+	assert.Equal(t, "Amazon", merchants[0].Name)
+	assert.Equal(t, 50.0, merchants[0].Value)
+	assert.Equal(t, "Starbucks", merchants[1].Name)
+}
+
+func TestMerchantFields(t *testing.T) {
+	matcher := NewMerchantAliasService(mocks.NewMockRepository(), zap.NewNop(), testMerchantsConfig).
+		Matcher(context.Background(), "user-1")
+
+	fields := merchantFields(matcher, "AMZN Mktp US*2K4LP9XY2")
+	assert.Equal(t, "AMZN Mktp US*2K4LP9XY2", fields["name"])
+	assert.Equal(t, "Amazon", fields["canonical"])
+	assert.Equal(t, "amazon", fields["normalized"])
+
+	fields = merchantFields(nil, "Corner Bakery #22")
+	assert.Equal(t, "corner bakery 22", fields["normalized"])
+	assert.NotContains(t, fields, "canonical")
+}
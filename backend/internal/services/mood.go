@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// MinMoodValue and MaxMoodValue bound a mood's value
+	MinMoodValue = 1
+	MaxMoodValue = 10
+)
+
+// ErrInvalidMood is returned for mood entries that fail validation
+var ErrInvalidMood = errors.New("invalid mood")
+
+// moodEmotions is the emotion vocabulary of the mood tracker
+var moodEmotions = map[string]bool{
+	"anxious": true, "sad": true, "stressed": true, "angry": true, "tired": true,
+	"happy": true, "overwhelmed": true, "low-energy": true, "depressed": true,
+	"hopeless": true, "empty": true, "lonely": true, "isolated": true,
+	"worried": true, "nervous": true, "scared": true, "panicked": true,
+	"frustrated": true, "irritated": true, "annoyed": true, "resentful": true,
+	"guilty": true, "ashamed": true, "embarrassed": true, "regretful": true,
+	"exhausted": true, "burned-out": true, "unmotivated": true, "confused": true,
+	"uncertain": true, "doubtful": true, "joyful": true, "excited": true,
+	"content": true, "peaceful": true, "grateful": true, "proud": true,
+	"hopeful": true, "optimistic": true, "energized": true, "confident": true,
+	"calm": true,
+}
+
+// MoodService creates and updates mood entries, keeping their values, dates
+// and emotions consistent for mood analytics
+type MoodService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewMoodService creates a new mood service
+func NewMoodService(repo interfaces.Repository, logger *zap.Logger) *MoodService {
+	return &MoodService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateMood validates and stores a new mood entry. date defaults to now.
+func (s *MoodService) CreateMood(ctx context.Context, uid string, fields map[string]interface{}) (map[string]interface{}, error) {
+	mood := make(map[string]interface{}, len(fields)+6)
+	for key, value := range fields {
+		mood[key] = value
+	}
+	for _, field := range protectedDocumentFields {
+		delete(mood, field)
+	}
+
+	now := time.Now()
+	setDefault(mood, "date", now)
+	if err := normalizeMood(mood, true); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	mood["id"] = id
+	mood["createdAt"] = now
+	mood["updatedAt"] = now
+	mood["updatedBy"] = uid
+	mood["version"] = 1
+
+	if err := s.repo.Create(ctx, moodPath(uid, id), mood); err != nil {
+		return nil, fmt.Errorf("failed to create mood: %w", err)
+	}
+
+	s.logger.Info("Mood created", zap.String("uid", uid), zap.String("moodId", id))
+	return mood, nil
+}
+
+// UpdateMood validates and applies changes to an existing mood entry
+func (s *MoodService) UpdateMood(ctx context.Context, uid, id string, fields map[string]interface{}) (map[string]interface{}, error) {
+	changes := make(map[string]interface{}, len(fields)+3)
+	for key, value := range fields {
+		changes[key] = value
+	}
+	for _, field := range protectedDocumentFields {
+		delete(changes, field)
+	}
+	if err := normalizeMood(changes, false); err != nil {
+		return nil, err
+	}
+
+	path := moodPath(uid, id)
+	existing, err := s.repo.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	mood := make(map[string]interface{}, len(existing)+len(changes))
+	for key, value := range existing {
+		mood[key] = value
+	}
+	for key, value := range changes {
+		mood[key] = value
+	}
+	stampPatchedDocument(mood, uid, time.Now())
+	for _, field := range []string{"version", "updatedAt", "updatedBy"} {
+		changes[field] = mood[field]
+	}
+
+	if err := s.repo.Update(ctx, path, changes); err != nil {
+		return nil, fmt.Errorf("failed to update mood: %w", err)
+	}
+
+	s.logger.Info("Mood updated", zap.String("uid", uid), zap.String("moodId", id))
+	return mood, nil
+}
+
+// normalizeMood validates a mood's value, date, emotions and location in
+// place, converting value to an integer, date to a time and emotions to a
+// deduped list. value is required only when requireValue is set; absent fields are
+// left alone.
+func normalizeMood(mood map[string]interface{}, requireValue bool) error {
+	if raw, ok := mood["value"]; ok || requireValue {
+		if raw == nil {
+			return fmt.Errorf("%w: value is required", ErrInvalidMood)
+		}
+		value, ok := toFloat(raw)
+		if !ok || value != math.Trunc(value) {
+			return fmt.Errorf("%w: value must be an integer", ErrInvalidMood)
+		}
+		if value < MinMoodValue || value > MaxMoodValue {
+			return fmt.Errorf("%w: value must be between %d and %d", ErrInvalidMood, MinMoodValue, MaxMoodValue)
+		}
+		mood["value"] = int64(value)
+	}
+
+	if raw, ok := mood["date"]; ok {
+		date, ok := parseFlexibleDate(raw)
+		if !ok {
+			return fmt.Errorf("%w: date must be an RFC 3339 timestamp or YYYY-MM-DD", ErrInvalidMood)
+		}
+		mood["date"] = date
+	}
+
+	if raw, ok := mood["emotions"]; ok && raw != nil {
+		items, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: emotions must be an array", ErrInvalidMood)
+		}
+		emotions := make([]string, 0, len(items))
+		for _, item := range items {
+			emotion, _ := item.(string)
+			emotion = strings.ToLower(strings.TrimSpace(emotion))
+			if !moodEmotions[emotion] {
+				return fmt.Errorf("%w: unknown emotion %v", ErrInvalidMood, item)
+			}
+			emotions = append(emotions, emotion)
+		}
+		mood["emotions"] = toInterfaceSlice(dedupeStrings(emotions))
+	}
+
+	// A null location clears it
+	if raw, ok := mood["location"]; ok && raw != nil {
+		location, err := parseLocation(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidMood, err)
+		}
+		mood["location"] = location
+	}
+	return nil
+}
+
+func moodPath(uid, id string) string {
+	return fmt.Sprintf("users/%s/moods/%s", uid, id)
+}
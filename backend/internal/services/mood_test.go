@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestMoodService_CreateMoodValidatesValue(t *testing.T) {
+	svc := NewMoodService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{"minimum", float64(1), false},
+		{"maximum", float64(10), false},
+		{"int64", int64(5), false},
+		{"below minimum", float64(0), true},
+		{"above maximum", float64(11), true},
+		{"negative", float64(-3), true},
+		{"fractional", 5.5, true},
+		{"string", "7", true},
+		{"missing", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]interface{}{}
+			if tt.value != nil {
+				fields["value"] = tt.value
+			}
+			mood, err := svc.CreateMood(ctx, "user1", fields)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMood)
+				return
+			}
+			require.NoError(t, err)
+			value, _ := toFloat(tt.value)
+			assert.Equal(t, int64(value), mood["value"])
+		})
+	}
+}
+
+func TestMoodService_CreateMoodNormalizesDateAndEmotions(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewMoodService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	before := time.Now()
+	mood, err := svc.CreateMood(ctx, "user1", map[string]interface{}{"value": float64(6), "version": float64(9)})
+	require.NoError(t, err)
+	date, ok := mood["date"].(time.Time)
+	require.True(t, ok)
+	assert.False(t, date.Before(before))
+	assert.Equal(t, 1, mood["version"])
+	assert.Contains(t, repo.Documents, "users/user1/moods/"+mood["id"].(string))
+
+	mood, err = svc.CreateMood(ctx, "user1", map[string]interface{}{
+		"value":    float64(4),
+		"date":     "2024-03-15",
+		"emotions": []interface{}{"Anxious", "tired", "anxious"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), mood["date"])
+	assert.Equal(t, []interface{}{"anxious", "tired"}, mood["emotions"])
+
+	_, err = svc.CreateMood(ctx, "user1", map[string]interface{}{"value": float64(4), "date": "last tuesday"})
+	assert.ErrorIs(t, err, ErrInvalidMood)
+
+	_, err = svc.CreateMood(ctx, "user1", map[string]interface{}{"value": float64(4), "emotions": []interface{}{"hangry"}})
+	assert.ErrorIs(t, err, ErrInvalidMood)
+
+	_, err = svc.CreateMood(ctx, "user1", map[string]interface{}{"value": float64(4), "emotions": "calm"})
+	assert.ErrorIs(t, err, ErrInvalidMood)
+}
+
+func TestMoodService_UpdateMood(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/moods/m1", map[string]interface{}{
+		"id":      "m1",
+		"value":   int64(5),
+		"note":    "fine",
+		"version": int64(1),
+	})
+	svc := NewMoodService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	mood, err := svc.UpdateMood(ctx, "user1", "m1", map[string]interface{}{"value": float64(8)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), mood["value"])
+	assert.Equal(t, "fine", mood["note"])
+	assert.Equal(t, int64(2), mood["version"])
+	assert.Equal(t, int64(8), repo.Documents["users/user1/moods/m1"]["value"])
+
+	// Updates without a value keep the stored one
+	mood, err = svc.UpdateMood(ctx, "user1", "m1", map[string]interface{}{"note": "better"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), mood["value"])
+
+	_, err = svc.UpdateMood(ctx, "user1", "m1", map[string]interface{}{"value": float64(11)})
+	assert.ErrorIs(t, err, ErrInvalidMood)
+	assert.Equal(t, int64(8), repo.Documents["users/user1/moods/m1"]["value"])
+
+	_, err = svc.UpdateMood(ctx, "user1", "m1", map[string]interface{}{"value": nil})
+	assert.ErrorIs(t, err, ErrInvalidMood)
+
+	_, err = svc.UpdateMood(ctx, "user1", "missing", map[string]interface{}{"value": float64(3)})
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
+
+func TestMoodService_MoodLocation(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewMoodService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	mood, err := svc.CreateMood(ctx, "user1", map[string]interface{}{
+		"value":    float64(7),
+		"location": map[string]interface{}{"label": "  Home ", "lat": 45.5, "lng": -73.6},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"label": "Home", "lat": 45.5, "lng": -73.6}, mood["location"])
+
+	invalid := []map[string]interface{}{
+		{"label": ""},
+		{"label": "Home", "lat": 45.5},
+		{"label": "Home", "lat": 91.0, "lng": 0.0},
+		{"label": "Home", "lat": 0.0, "lng": 181.0},
+		{"label": "Home", "lat": "45", "lng": "0"},
+	}
+	for _, location := range invalid {
+		_, err := svc.CreateMood(ctx, "user1", map[string]interface{}{"value": float64(7), "location": location})
+		assert.ErrorIs(t, err, ErrInvalidMood, "location %v", location)
+	}
+
+	// A location without coordinates is fine, and null clears it
+	updated, err := svc.UpdateMood(ctx, "user1", mood["id"].(string), map[string]interface{}{
+		"location": map[string]interface{}{"label": "Office"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"label": "Office"}, updated["location"])
+
+	updated, err = svc.UpdateMood(ctx, "user1", mood["id"].(string), map[string]interface{}{"location": nil})
+	require.NoError(t, err)
+	assert.Nil(t, updated["location"])
+}
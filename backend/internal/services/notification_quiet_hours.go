@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// ErrInvalidQuietHours is returned for malformed quiet hours
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// quietHoursLayout is the wall-clock format of quiet hours bounds
+const quietHoursLayout = "15:04"
+
+// QuietHours is the daily window in which the user does not want to be
+// notified, stored at users/{uid}/settings/quietHours. Start and End are
+// HH:MM wall-clock times in Timezone; a window with End before Start runs
+// past midnight. Empty bounds mean no quiet hours.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+// Enabled reports whether the window is set
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" && q.End != ""
+}
+
+// NotificationWindow says whether the user may be notified now. When they
+// may not, NextAllowedAt is when quiet hours end.
+type NotificationWindow struct {
+	QuietHours    QuietHours `json:"quietHours"`
+	NotifyNow     bool       `json:"notifyNow"`
+	NextAllowedAt *time.Time `json:"nextAllowedAt,omitempty"`
+}
+
+// GetQuietHours returns the user's quiet hours; the zero value when unset
+func (s *NotificationService) GetQuietHours(ctx context.Context, uid string) (QuietHours, error) {
+	doc, err := s.repo.Get(ctx, quietHoursPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return QuietHours{}, nil
+		}
+		return QuietHours{}, fmt.Errorf("failed to load quiet hours: %w", err)
+	}
+	return QuietHours{
+		Start:    getStringField(doc, "start"),
+		End:      getStringField(doc, "end"),
+		Timezone: getStringField(doc, "timezone"),
+	}, nil
+}
+
+// UpdateQuietHours validates and stores the user's quiet hours. Empty start
+// and end turn them off; an empty timezone means UTC.
+func (s *NotificationService) UpdateQuietHours(ctx context.Context, uid string, quiet QuietHours) (QuietHours, error) {
+	if err := validateQuietHours(&quiet); err != nil {
+		return quiet, err
+	}
+	if err := s.repo.SetDocument(ctx, quietHoursPath(uid), map[string]interface{}{
+		"start":     quiet.Start,
+		"end":       quiet.End,
+		"timezone":  quiet.Timezone,
+		"updatedAt": s.now(),
+	}); err != nil {
+		return quiet, fmt.Errorf("failed to save quiet hours: %w", err)
+	}
+	return quiet, nil
+}
+
+// NotificationWindow reports whether the user may be notified right now
+func (s *NotificationService) NotificationWindow(ctx context.Context, uid string) (*NotificationWindow, error) {
+	quiet, err := s.GetQuietHours(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	window := &NotificationWindow{QuietHours: quiet, NotifyNow: true}
+	if next, quietNow := quiet.nextAllowed(s.now()); quietNow {
+		window.NotifyNow = false
+		window.NextAllowedAt = &next
+	}
+	return window, nil
+}
+
+// nextAllowed reports whether now falls within the quiet hours and, if so,
+// when they end. Bounds are resolved on the local date, so a window keeps
+// its wall-clock times across daylight saving changes. Malformed settings
+// never hold notifications back.
+func (q QuietHours) nextAllowed(now time.Time) (time.Time, bool) {
+	if !q.Enabled() {
+		return time.Time{}, false
+	}
+	start, errStart := time.Parse(quietHoursLayout, q.Start)
+	end, errEnd := time.Parse(quietHoursLayout, q.End)
+	loc, errLoc := loadQuietHoursLocation(q.Timezone)
+	if errStart != nil || errEnd != nil || errLoc != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	if startMinute < endMinute {
+		quiet = minute >= startMinute && minute < endMinute
+	} else {
+		quiet = minute >= startMinute || minute < endMinute
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	next := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, end.Hour(), end.Minute(), 0, 0, loc)
+	}
+	return next, true
+}
+
+// validateQuietHours checks both bounds are HH:MM and differ, and that the
+// timezone is an IANA name, defaulting it to UTC
+func validateQuietHours(quiet *QuietHours) error {
+	if quiet.Start == "" && quiet.End == "" {
+		quiet.Timezone = ""
+		return nil
+	}
+	for _, bound := range []string{quiet.Start, quiet.End} {
+		if _, err := time.Parse(quietHoursLayout, bound); err != nil {
+			return fmt.Errorf("%w: %q is not an HH:MM time", ErrInvalidQuietHours, bound)
+		}
+	}
+	if quiet.Start == quiet.End {
+		return fmt.Errorf("%w: start and end must differ", ErrInvalidQuietHours)
+	}
+	if quiet.Timezone == "" {
+		quiet.Timezone = "UTC"
+	}
+	if _, err := loadQuietHoursLocation(quiet.Timezone); err != nil {
+		return fmt.Errorf("%w: %w %q", ErrInvalidQuietHours, ErrInvalidTimezone, quiet.Timezone)
+	}
+	return nil
+}
+
+// loadQuietHoursLocation resolves a quiet hours timezone; empty means UTC
+func loadQuietHoursLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+func quietHoursPath(uid string) string {
+	return fmt.Sprintf("users/%s/settings/quietHours", uid)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestNotificationService_NotificationWindow_AcrossQuietHours(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := newTestNotificationService(repo)
+	ctx := context.Background()
+
+	_, err := svc.UpdateQuietHours(ctx, "u1", QuietHours{Start: "22:00", End: "07:00", Timezone: "America/New_York"})
+	require.NoError(t, err)
+
+	utcAt := func(value string) time.Time {
+		at, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return at
+	}
+
+	tests := []struct {
+		name      string
+		now       string
+		notifyNow bool
+		next      string
+	}{
+		// 21:59 and 22:00 in New York (EDT, UTC-4)
+		{name: "before quiet hours start", now: "2024-06-15T01:59:00Z", notifyNow: true},
+		{name: "at quiet hours start", now: "2024-06-15T02:00:00Z", next: "2024-06-15T11:00:00Z"},
+		// 06:59 and 07:00 the next morning
+		{name: "just before quiet hours end", now: "2024-06-15T10:59:00Z", next: "2024-06-15T11:00:00Z"},
+		{name: "at quiet hours end", now: "2024-06-15T11:00:00Z", notifyNow: true},
+		// 23:00 EST the night clocks go forward ends at 07:00 EDT, 7 hours later
+		{name: "across daylight saving change", now: "2024-03-10T04:00:00Z", next: "2024-03-10T11:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := utcAt(tt.now)
+			svc.now = func() time.Time { return now }
+
+			window, err := svc.NotificationWindow(ctx, "u1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.notifyNow, window.NotifyNow)
+			if tt.notifyNow {
+				assert.Nil(t, window.NextAllowedAt)
+				return
+			}
+			require.NotNil(t, window.NextAllowedAt)
+			assert.True(t, utcAt(tt.next).Equal(*window.NextAllowedAt), "next allowed %s", window.NextAllowedAt.UTC())
+		})
+	}
+}
+
+func TestNotificationService_NotifySuppressedDuringQuietHours(t *testing.T) {
+	sink := &notificationSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/u1/notificationChannels/c1", map[string]interface{}{
+		"id": "c1", "kind": NotificationChannelSlack, "webhookUrl": server.URL,
+		"events": []interface{}{NotificationEventGoalCompleted},
+	})
+	repo.AddDocument("users/u1/settings/quietHours", map[string]interface{}{
+		"start": "09:00", "end": "17:00", "timezone": "Asia/Tokyo",
+	})
+	svc := newTestNotificationService(repo)
+	event := NotificationEvent{Type: NotificationEventGoalCompleted, Title: "Goal completed"}
+
+	// 10:00 in Tokyo
+	svc.now = func() time.Time { return time.Date(2024, 6, 15, 1, 0, 0, 0, time.UTC) }
+	svc.Notify(context.Background(), "u1", event)
+	svc.Wait()
+	assert.Empty(t, sink.payloads)
+
+	// 17:00 in Tokyo
+	svc.now = func() time.Time { return time.Date(2024, 6, 15, 8, 0, 0, 0, time.UTC) }
+	svc.Notify(context.Background(), "u1", event)
+	svc.Wait()
+	assert.Len(t, sink.payloads, 1)
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	valid := QuietHours{Start: "22:30", End: "06:00"}
+	require.NoError(t, validateQuietHours(&valid))
+	assert.Equal(t, "UTC", valid.Timezone)
+
+	off := QuietHours{Timezone: "Europe/Paris"}
+	require.NoError(t, validateQuietHours(&off))
+	assert.False(t, off.Enabled())
+
+	for _, quiet := range []QuietHours{
+		{Start: "22:00"},
+		{Start: "25:00", End: "06:00"},
+		{Start: "10pm", End: "06:00"},
+		{Start: "07:00", End: "07:00"},
+		{Start: "22:00", End: "06:00", Timezone: "Mars/Olympus"},
+	} {
+		assert.ErrorIs(t, validateQuietHours(&quiet), ErrInvalidQuietHours, "%+v", quiet)
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Notification event types a channel can subscribe to
+const (
+	NotificationEventGoalCompleted = "goal.completed"
+)
+
+// Notification channel kinds
+const (
+	NotificationChannelSlack   = "slack"
+	NotificationChannelDiscord = "discord"
+)
+
+// MaxNotificationChannels caps the channels one user can configure
+const MaxNotificationChannels = 10
+
+const (
+	defaultNotificationTimeout        = 10 * time.Second
+	defaultNotificationMaxAttempts    = 3
+	defaultNotificationInitialBackoff = time.Second
+	defaultNotificationMaxBackoff     = 30 * time.Second
+)
+
+// notificationEventTypes lists the events something actually sends; a type
+// is added here together with the code that emits it, so users cannot
+// subscribe to events that never fire
+var notificationEventTypes = map[string]bool{
+	NotificationEventGoalCompleted: true,
+}
+
+// ErrInvalidNotificationChannel is returned for malformed channel configs
+var ErrInvalidNotificationChannel = errors.New("invalid notification channel")
+
+// NotificationEvent is something worth telling the user about outside the app
+type NotificationEvent struct {
+	Type  string
+	Title string
+	Body  string
+	Link  string
+}
+
+// NotificationChannel is a user's outbound webhook, stored at
+// users/{uid}/notificationChannels/{id}
+type NotificationChannel struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	WebhookURL string    `json:"webhookUrl"`
+	Events     []string  `json:"events"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// NotificationAdapter formats events for one kind of webhook
+type NotificationAdapter interface {
+	// ValidWebhookURL reports whether u points at this kind's webhook host
+	ValidWebhookURL(u *url.URL) bool
+	// Payload builds the JSON body posted for an event
+	Payload(event NotificationEvent) map[string]interface{}
+}
+
+// notificationAdapters maps channel kinds to their adapters
+var notificationAdapters = map[string]NotificationAdapter{
+	NotificationChannelSlack:   slackAdapter{},
+	NotificationChannelDiscord: discordAdapter{},
+}
+
+// slackAdapter posts to Slack incoming webhooks
+type slackAdapter struct{}
+
+func (slackAdapter) ValidWebhookURL(u *url.URL) bool {
+	return u.Host == "hooks.slack.com" && strings.HasPrefix(u.Path, "/services/")
+}
+
+func (slackAdapter) Payload(event NotificationEvent) map[string]interface{} {
+	return map[string]interface{}{"text": formatNotification(event, "*")}
+}
+
+// discordAdapter posts to Discord channel webhooks
+type discordAdapter struct{}
+
+func (discordAdapter) ValidWebhookURL(u *url.URL) bool {
+	return (u.Host == "discord.com" || u.Host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/")
+}
+
+func (discordAdapter) Payload(event NotificationEvent) map[string]interface{} {
+	return map[string]interface{}{"content": formatNotification(event, "**")}
+}
+
+// formatNotification renders an event as a short message, bolding the title
+// with the given markup
+func formatNotification(event NotificationEvent, bold string) string {
+	lines := []string{bold + event.Title + bold}
+	if event.Body != "" {
+		lines = append(lines, event.Body)
+	}
+	if event.Link != "" {
+		lines = append(lines, event.Link)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NotificationService pushes events to users' Slack and Discord webhooks
+type NotificationService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	client *http.Client
+	retry  config.RetryConfig
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewNotificationService creates a new notification service. Deliveries are
+// retried per retry; zero values fall back to 3 attempts with 1s backoff
+// doubling up to 30s, retrying 408, 429 and 5xx responses.
+func NewNotificationService(repo interfaces.Repository, logger *zap.Logger, retry config.RetryConfig) *NotificationService {
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaultNotificationMaxAttempts
+	}
+	if retry.InitialBackoff <= 0 {
+		retry.InitialBackoff = defaultNotificationInitialBackoff
+	}
+	if retry.MaxBackoff <= 0 {
+		retry.MaxBackoff = defaultNotificationMaxBackoff
+	}
+	if retry.Multiplier < 1 {
+		retry.Multiplier = 2
+	}
+	return &NotificationService{
+		repo:   repo,
+		logger: logger,
+		client: &http.Client{Timeout: defaultNotificationTimeout},
+		retry:  retry,
+		now:    time.Now,
+	}
+}
+
+// ListChannels returns the user's notification channels
+func (s *NotificationService) ListChannels(ctx context.Context, uid string) ([]NotificationChannel, error) {
+	docs, err := s.repo.List(ctx, channelsPath(uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification channels: %w", err)
+	}
+	channels := make([]NotificationChannel, 0, len(docs))
+	for _, doc := range docs {
+		channels = append(channels, parseNotificationChannel(doc))
+	}
+	return channels, nil
+}
+
+// CreateChannel validates and stores a new notification channel
+func (s *NotificationService) CreateChannel(ctx context.Context, uid string, channel NotificationChannel) (*NotificationChannel, error) {
+	if err := validateNotificationChannel(&channel); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ListChannels(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxNotificationChannels {
+		return nil, fmt.Errorf("%w: at most %d channels", ErrInvalidNotificationChannel, MaxNotificationChannels)
+	}
+
+	channel.ID = uuid.New().String()
+	channel.CreatedAt = time.Now()
+	if err := s.repo.SetDocument(ctx, fmt.Sprintf("%s/%s", channelsPath(uid), channel.ID), map[string]interface{}{
+		"id":         channel.ID,
+		"kind":       channel.Kind,
+		"webhookUrl": channel.WebhookURL,
+		"events":     toInterfaceSlice(channel.Events),
+		"createdAt":  channel.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save notification channel: %w", err)
+	}
+
+	s.logger.Info("Notification channel created",
+		zap.String("uid", uid),
+		zap.String("channelId", channel.ID),
+		zap.String("kind", channel.Kind),
+	)
+	return &channel, nil
+}
+
+// DeleteChannel removes a notification channel
+func (s *NotificationService) DeleteChannel(ctx context.Context, uid, channelID string) error {
+	path := fmt.Sprintf("%s/%s", channelsPath(uid), channelID)
+	if _, err := s.repo.Get(ctx, path); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete notification channel: %w", err)
+	}
+	return nil
+}
+
+// Notify delivers an event to every channel of the user subscribed to its
+// type. It returns immediately; delivery runs in the background and failures
+// are logged. Events raised during the user's quiet hours are dropped. A nil
+// service does nothing.
+func (s *NotificationService) Notify(ctx context.Context, uid string, event NotificationEvent) {
+	if s == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		window, err := s.NotificationWindow(ctx, uid)
+		if err != nil {
+			s.logger.Warn("Failed to load quiet hours", zap.String("uid", uid), zap.Error(err))
+		} else if !window.NotifyNow {
+			s.logger.Info("Notification suppressed during quiet hours",
+				zap.String("uid", uid),
+				zap.String("event", event.Type),
+				zap.Time("nextAllowedAt", *window.NextAllowedAt),
+			)
+			return
+		}
+
+		channels, err := s.ListChannels(ctx, uid)
+		if err != nil {
+			s.logger.Warn("Failed to load notification channels", zap.String("uid", uid), zap.Error(err))
+			return
+		}
+		for _, channel := range channels {
+			if !channelSubscribes(channel, event.Type) {
+				continue
+			}
+			if err := s.deliver(ctx, channel, event); err != nil {
+				s.logger.Warn("Failed to deliver notification",
+					zap.String("uid", uid),
+					zap.String("channelId", channel.ID),
+					zap.String("event", event.Type),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+// Wait blocks until background deliveries have finished
+func (s *NotificationService) Wait() {
+	s.wg.Wait()
+}
+
+// deliver posts an event to a channel, retrying retryable failures with
+// exponential backoff
+func (s *NotificationService) deliver(ctx context.Context, channel NotificationChannel, event NotificationEvent) error {
+	adapter, ok := notificationAdapters[channel.Kind]
+	if !ok {
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidNotificationChannel, channel.Kind)
+	}
+	body, err := json.Marshal(adapter.Payload(event))
+	if err != nil {
+		return err
+	}
+
+	backoff := s.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.post(ctx, channel.WebhookURL, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= s.retry.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = time.Duration(float64(backoff) * s.retry.Multiplier)
+		if backoff > s.retry.MaxBackoff {
+			backoff = s.retry.MaxBackoff
+		}
+	}
+}
+
+// post sends one delivery attempt, reporting whether a failure is worth retrying
+func (s *NotificationService) post(ctx context.Context, webhookURL string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	return s.retryableStatus(resp.StatusCode), fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
+
+func (s *NotificationService) retryableStatus(code int) bool {
+	if len(s.retry.RetryableCodes) == 0 {
+		return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+	}
+	for _, retryable := range s.retry.RetryableCodes {
+		if code == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNotificationChannel checks a channel's kind, webhook URL and event
+// subscriptions, normalizing them in place
+func validateNotificationChannel(channel *NotificationChannel) error {
+	channel.Kind = strings.ToLower(strings.TrimSpace(channel.Kind))
+	channel.WebhookURL = strings.TrimSpace(channel.WebhookURL)
+
+	adapter, ok := notificationAdapters[channel.Kind]
+	if !ok {
+		return fmt.Errorf("%w: kind must be %s or %s", ErrInvalidNotificationChannel, NotificationChannelSlack, NotificationChannelDiscord)
+	}
+	u, err := url.Parse(channel.WebhookURL)
+	if err != nil || u.Scheme != "https" || !adapter.ValidWebhookURL(u) {
+		return fmt.Errorf("%w: webhookUrl is not a %s webhook URL", ErrInvalidNotificationChannel, channel.Kind)
+	}
+
+	channel.Events = dedupeStrings(channel.Events)
+	if len(channel.Events) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidNotificationChannel)
+	}
+	for _, eventType := range channel.Events {
+		if !notificationEventTypes[eventType] {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidNotificationChannel, eventType)
+		}
+	}
+	return nil
+}
+
+func parseNotificationChannel(doc map[string]interface{}) NotificationChannel {
+	channel := NotificationChannel{
+		ID:         getStringField(doc, "id"),
+		Kind:       getStringField(doc, "kind"),
+		WebhookURL: getStringField(doc, "webhookUrl"),
+		Events:     toStringSlice(doc["events"]),
+	}
+	if createdAt, ok := doc["createdAt"].(time.Time); ok {
+		channel.CreatedAt = createdAt
+	}
+	return channel
+}
+
+func channelSubscribes(channel NotificationChannel, eventType string) bool {
+	for _, subscribed := range channel.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func channelsPath(uid string) string {
+	return fmt.Sprintf("users/%s/notificationChannels", uid)
+}
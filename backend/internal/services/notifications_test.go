@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// notificationSink is a fake webhook endpoint recording the payloads it receives
+type notificationSink struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+	failures int // respond 503 to this many requests first
+}
+
+func (s *notificationSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var payload map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+	s.payloads = append(s.payloads, payload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestNotificationService(repo *mocks.MockRepository) *NotificationService {
+	return NewNotificationService(repo, zap.NewNop(), config.RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+}
+
+func TestNotificationAdapters_Payload(t *testing.T) {
+	event := NotificationEvent{
+		Type:  NotificationEventGoalCompleted,
+		Title: "Goal completed",
+		Body:  "Run a marathon",
+		Link:  "https://app.example.com/goals/g1",
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"text": "*Goal completed*\nRun a marathon\nhttps://app.example.com/goals/g1",
+	}, slackAdapter{}.Payload(event))
+	assert.Equal(t, map[string]interface{}{
+		"content": "**Goal completed**\nRun a marathon\nhttps://app.example.com/goals/g1",
+	}, discordAdapter{}.Payload(event))
+	assert.Equal(t, "*Weekly review ready*", formatNotification(NotificationEvent{Title: "Weekly review ready"}, "*"))
+}
+
+func TestValidateNotificationChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel NotificationChannel
+		wantErr bool
+	}{
+		{name: "slack", channel: NotificationChannel{Kind: "Slack", WebhookURL: "https://hooks.slack.com/services/T0/B0/x", Events: []string{NotificationEventGoalCompleted}}},
+		{name: "discord", channel: NotificationChannel{Kind: "discord", WebhookURL: "https://discord.com/api/webhooks/1/abc", Events: []string{NotificationEventGoalCompleted}}},
+		{name: "unknown kind", channel: NotificationChannel{Kind: "teams", WebhookURL: "https://hooks.slack.com/services/x", Events: []string{NotificationEventGoalCompleted}}, wantErr: true},
+		{name: "foreign host", channel: NotificationChannel{Kind: "slack", WebhookURL: "https://example.com/services/x", Events: []string{NotificationEventGoalCompleted}}, wantErr: true},
+		{name: "plain http", channel: NotificationChannel{Kind: "discord", WebhookURL: "http://discord.com/api/webhooks/1/abc", Events: []string{NotificationEventGoalCompleted}}, wantErr: true},
+		{name: "no events", channel: NotificationChannel{Kind: "slack", WebhookURL: "https://hooks.slack.com/services/x"}, wantErr: true},
+		{name: "unknown event", channel: NotificationChannel{Kind: "slack", WebhookURL: "https://hooks.slack.com/services/x", Events: []string{"task.created"}}, wantErr: true},
+		{name: "event never emitted", channel: NotificationChannel{Kind: "slack", WebhookURL: "https://hooks.slack.com/services/x", Events: []string{"budget.exceeded"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNotificationChannel(&tt.channel)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidNotificationChannel)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNotificationService_NotifyDeliversToSubscribedChannels(t *testing.T) {
+	slackSink := &notificationSink{failures: 1}
+	slackServer := httptest.NewServer(slackSink)
+	defer slackServer.Close()
+	discordSink := &notificationSink{}
+	discordServer := httptest.NewServer(discordSink)
+	defer discordServer.Close()
+
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/u1/notificationChannels/c1", map[string]interface{}{
+		"id": "c1", "kind": NotificationChannelSlack, "webhookUrl": slackServer.URL,
+		"events": []interface{}{NotificationEventGoalCompleted},
+	})
+	repo.AddDocument("users/u1/notificationChannels/c2", map[string]interface{}{
+		"id": "c2", "kind": NotificationChannelDiscord, "webhookUrl": discordServer.URL,
+		"events": []interface{}{"task.created"},
+	})
+	svc := newTestNotificationService(repo)
+
+	svc.Notify(context.Background(), "u1", NotificationEvent{
+		Type:  NotificationEventGoalCompleted,
+		Title: "Goal completed",
+		Body:  "Learn Go",
+	})
+	svc.Wait()
+
+	// The first Slack attempt fails with 503 and is retried
+	require.Len(t, slackSink.payloads, 1)
+	assert.Equal(t, "*Goal completed*\nLearn Go", slackSink.payloads[0]["text"])
+	assert.Empty(t, discordSink.payloads)
+}
+
+func TestNotificationService_DeliverGivesUp(t *testing.T) {
+	sink := &notificationSink{failures: 5}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	svc := newTestNotificationService(mocks.NewMockRepository())
+	err := svc.deliver(context.Background(), NotificationChannel{Kind: NotificationChannelDiscord, WebhookURL: server.URL}, NotificationEvent{Title: "x"})
+	assert.Error(t, err)
+	// Three attempts were made
+	assert.Equal(t, 2, sink.failures)
+}
+
+func TestNotificationService_CreateChannel(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := newTestNotificationService(repo)
+
+	channel, err := svc.CreateChannel(context.Background(), "u1", NotificationChannel{
+		Kind:       "discord",
+		WebhookURL: "https://discord.com/api/webhooks/1/abc",
+		Events:     []string{NotificationEventGoalCompleted, NotificationEventGoalCompleted},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, channel.ID)
+	assert.Equal(t, []string{NotificationEventGoalCompleted}, channel.Events)
+
+	channels, err := svc.ListChannels(context.Background(), "u1")
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, channel.ID, channels[0].ID)
+	assert.Equal(t, "https://discord.com/api/webhooks/1/abc", channels[0].WebhookURL)
+
+	require.NoError(t, svc.DeleteChannel(context.Background(), "u1", channel.ID))
+	assert.Error(t, svc.DeleteChannel(context.Background(), "u1", channel.ID))
+}
+
+func TestGoalCompleted(t *testing.T) {
+	assert.True(t, goalCompleted(map[string]interface{}{"status": "active"}, map[string]interface{}{"status": "completed"}))
+	assert.False(t, goalCompleted(map[string]interface{}{"status": "completed"}, map[string]interface{}{"status": "completed"}))
+	assert.False(t, goalCompleted(map[string]interface{}{"status": "active"}, map[string]interface{}{"status": "paused"}))
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,10 @@ import (
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
 
+// ErrPackingListNotFound is wrapped into errors for a trip that has no packing list yet.
+// Missing trips are reported via interfaces.ErrNotFound alone.
+var ErrPackingListNotFound = errors.New("packing list not found")
+
 // PackingListService handles packing list operations
 type PackingListService struct {
 	repo   interfaces.Repository
@@ -128,7 +133,7 @@ func (s *PackingListService) UpdatePackingList(
 	// Update packing list
 	packingListPath := fmt.Sprintf("%s/packingList/data", tripPath)
 	if _, err := s.repo.Get(ctx, packingListPath); err != nil {
-		return fmt.Errorf("packing list not found: %w", err)
+		return packingListError(err)
 	}
 
 	updates["updatedAt"] = time.Now()
@@ -168,7 +173,7 @@ func (s *PackingListService) SetItemStatus(
 	packingListPath := fmt.Sprintf("%s/packingList/data", tripPath)
 	data, err := s.repo.Get(ctx, packingListPath)
 	if err != nil {
-		return fmt.Errorf("packing list not found: %w", err)
+		return packingListError(err)
 	}
 
 	// Update item statuses
@@ -555,3 +560,11 @@ func parseItem(data map[string]interface{}) PackingItem {
 
 	return item
 }
+
+// packingListError tags a missing packing list with ErrPackingListNotFound
+func packingListError(err error) error {
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return fmt.Errorf("%w: %w", ErrPackingListNotFound, err)
+	}
+	return fmt.Errorf("failed to get packing list: %w", err)
+}
@@ -2,14 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"google.golang.org/api/iterator"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
 )
@@ -62,15 +68,31 @@ type PhotoBattle struct {
 	UpdatedAt    time.Time         `json:"updatedAt,omitempty" firestore:"updatedAt,omitempty"`
 }
 
-// VoteHistory represents a vote history entry
+// VoteHistory represents a vote history entry, stored at
+// photoBattles/{sessionId}/history/{id}. WinnerBefore and LoserBefore snapshot
+// both photos before the vote so it can be undone and rankings reconstructed;
+// entries written before snapshots were recorded have them nil.
 type VoteHistory struct {
-	WinnerID  string    `json:"winnerId" firestore:"winnerId"`
-	LoserID   string    `json:"loserId" firestore:"loserId"`
-	VoterID   string    `json:"voterId,omitempty" firestore:"voterId,omitempty"`
-	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+	ID           string             `json:"id" firestore:"id"`
+	WinnerID     string             `json:"winnerId" firestore:"winnerId"`
+	LoserID      string             `json:"loserId" firestore:"loserId"`
+	VoterID      string             `json:"voterId,omitempty" firestore:"voterId,omitempty"`
+	WinnerBefore *PhotoVoteSnapshot `json:"winnerBefore,omitempty" firestore:"winnerBefore,omitempty"`
+	LoserBefore  *PhotoVoteSnapshot `json:"loserBefore,omitempty" firestore:"loserBefore,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" firestore:"createdAt"`
 }
 
-// SubmitVote processes a photo vote using Elo rating algorithm
+// PhotoVoteSnapshot is a battle photo's standing at a point in the vote history
+type PhotoVoteSnapshot struct {
+	Rating     int `json:"rating" firestore:"rating"`
+	Wins       int `json:"wins" firestore:"wins"`
+	Losses     int `json:"losses" firestore:"losses"`
+	TotalVotes int `json:"totalVotes" firestore:"totalVotes"`
+}
+
+// SubmitVote processes a photo vote using Elo rating algorithm. The ratings,
+// the vote history entry and the photo library stats are written in one
+// transaction.
 func (s *PhotoService) SubmitVote(
 	ctx context.Context,
 	sessionID string,
@@ -78,163 +100,187 @@ func (s *PhotoService) SubmitVote(
 	loserID string,
 	voterID string,
 ) error {
-	// Get session
-	sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
-	sessionData, err := s.repo.Get(ctx, sessionPath)
-	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
-	}
-
-	// Parse photos
-	photosRaw, ok := sessionData["photos"].([]interface{})
-	if !ok {
-		return fmt.Errorf("invalid photos data")
+	if winnerID == loserID {
+		return fmt.Errorf("%w: winner and loser must be different photos", ErrInvalidVote)
 	}
 
-	photos := make([]BattlePhoto, len(photosRaw))
-	for i, p := range photosRaw {
-		photoMap, ok := p.(map[string]interface{})
-		if !ok {
-			continue
+	sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
+	return s.repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		// Get session
+		sessionData, err := tx.Get(sessionPath)
+		if err != nil {
+			return fmt.Errorf("session not found: %w", err)
 		}
-		photos[i] = parseBattlePhoto(photoMap)
-	}
 
-	// Find winner and loser
-	var winner, loser *BattlePhoto
-	var winnerIdx, loserIdx int
-	for i := range photos {
-		if photos[i].ID == winnerID {
-			winner = &photos[i]
-			winnerIdx = i
+		// Parse photos, merging repeated IDs so the vote lands on one entry
+		if _, ok := sessionData["photos"].([]interface{}); !ok {
+			return fmt.Errorf("invalid photos data")
 		}
-		if photos[i].ID == loserID {
-			loser = &photos[i]
-			loserIdx = i
+		photos, _ := parseBattlePhotos(sessionData["photos"])
+
+		// Find winner and loser
+		var winner, loser *BattlePhoto
+		for i := range photos {
+			switch photos[i].ID {
+			case winnerID:
+				winner = &photos[i]
+			case loserID:
+				loser = &photos[i]
+			}
 		}
-	}
 
-	if winner == nil || loser == nil {
-		return fmt.Errorf("invalid photos selected")
-	}
+		var missing []string
+		if winner == nil {
+			missing = append(missing, winnerID)
+		}
+		if loser == nil {
+			missing = append(missing, loserID)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrPhotoNotInBattle, strings.Join(missing, ", "))
+		}
 
-	ownerID, _ := sessionData["ownerId"].(string)
-	winnerLibraryID := winner.LibraryID
-	loserLibraryID := loser.LibraryID
+		ownerID, _ := sessionData["ownerId"].(string)
+		libraries, err := readVoteLibraries(tx, ownerID, winner.LibraryID, loser.LibraryID)
+		if err != nil {
+			return err
+		}
 
-	// Calculate Elo ratings
-	K := 32.0
-	expectedWinner := 1.0 / (1.0 + math.Pow(10, float64(loser.Rating-winner.Rating)/400.0))
-	expectedLoser := 1.0 / (1.0 + math.Pow(10, float64(winner.Rating-loser.Rating)/400.0))
+		// Calculate Elo ratings
+		winnerBefore, loserBefore := newPhotoVoteSnapshot(*winner), newPhotoVoteSnapshot(*loser)
+		winner.Rating, loser.Rating = eloRatings(winner.Rating, loser.Rating)
+		winner.Wins++
+		winner.TotalVotes++
+		loser.Losses++
+		loser.TotalVotes++
+
+		// Count the vote in the photos' libraries, remembering which ones
+		// this vote added the session to so an undo can take it out again
+		sessionLibraries := []string{}
+		for _, result := range []struct {
+			libraryID string
+			won       bool
+		}{{winner.LibraryID, true}, {loser.LibraryID, false}} {
+			library, ok := libraries[result.libraryID]
+			if !ok {
+				continue
+			}
+			newSession := !slices.Contains(toStringSlice(library["sessionIds"]), sessionID)
+			if newSession {
+				sessionLibraries = append(sessionLibraries, result.libraryID)
+			}
+			applyLibraryVote(library, sessionID, result.won, 1, newSession)
+		}
 
-	winner.Rating = int(math.Max(0, math.Round(float64(winner.Rating)+K*(1.0-expectedWinner))))
-	loser.Rating = int(math.Max(0, math.Round(float64(loser.Rating)+K*(0.0-expectedLoser))))
-	winner.Wins++
-	winner.TotalVotes++
-	loser.Losses++
-	loser.TotalVotes++
+		historyID := uuid.New().String()
+		now := time.Now()
+		if err := tx.Set(sessionPath, map[string]interface{}{
+			"photos":     battlePhotosToMaps(photos),
+			"lastVoteId": historyID,
+			"updatedAt":  now,
+		}); err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
 
-	// Update photos in array
-	photos[winnerIdx] = *winner
-	photos[loserIdx] = *loser
+		// Save vote history, chained to the previous vote for undo
+		if err := tx.Set(fmt.Sprintf("%s/%s", voteHistoryPath(sessionID), historyID), map[string]interface{}{
+			"id":               historyID,
+			"winnerId":         winnerID,
+			"loserId":          loserID,
+			"voterId":          voterID,
+			"winnerBefore":     winnerBefore.toMap(),
+			"loserBefore":      loserBefore.toMap(),
+			"previousVoteId":   getStringField(sessionData, "lastVoteId"),
+			"sessionLibraries": sessionLibraries,
+			"createdAt":        now,
+		}); err != nil {
+			return fmt.Errorf("failed to save vote history: %w", err)
+		}
 
-	// Update session
-	updateData := map[string]interface{}{
-		"photos":    photos,
-		"updatedAt": time.Now(),
-	}
-	if err := s.repo.Update(ctx, sessionPath, updateData); err != nil {
-		return fmt.Errorf("failed to update session: %w", err)
-	}
+		return writeVoteLibraries(tx, ownerID, libraries)
+	})
+}
 
-	// Save vote history
-	historyPath := fmt.Sprintf("%s/history/%d", sessionPath, time.Now().UnixNano())
-	historyData := map[string]interface{}{
-		"winnerId":  winnerID,
-		"loserId":   loserID,
-		"voterId":   voterID,
-		"createdAt": time.Now(),
-	}
-	if err := s.repo.Create(ctx, historyPath, historyData); err != nil {
-		s.logger.Warn("Failed to save vote history", zap.Error(err))
+// readVoteLibraries reads the library documents of a vote's photos, keyed by
+// library ID. Photos without a library, or whose library has been deleted,
+// are skipped.
+func readVoteLibraries(tx interfaces.Transaction, ownerID string, libraryIDs ...string) (map[string]map[string]interface{}, error) {
+	libraries := make(map[string]map[string]interface{})
+	if ownerID == "" {
+		return libraries, nil
 	}
-
-	// Update library stats (if library IDs exist)
-	if ownerID != "" {
-		if winnerLibraryID != "" {
-			s.updateLibraryStats(ctx, ownerID, winnerLibraryID, "win", sessionID)
+	for _, libraryID := range libraryIDs {
+		if libraryID == "" || libraries[libraryID] != nil {
+			continue
 		}
-		if loserLibraryID != "" {
-			s.updateLibraryStats(ctx, ownerID, loserLibraryID, "loss", sessionID)
+		data, err := tx.Get(photoLibraryPath(ownerID, libraryID))
+		if errors.Is(err, interfaces.ErrNotFound) {
+			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get library stats: %w", err)
+		}
+		libraries[libraryID] = data
 	}
-
-	return nil
+	return libraries, nil
 }
 
-// updateLibraryStats updates photo library stats
-func (s *PhotoService) updateLibraryStats(
-	ctx context.Context,
-	ownerID string,
-	libraryID string,
-	result string,
-	sessionID string,
-) {
-	statsPath := fmt.Sprintf("users/%s/photoLibrary/%s", ownerID, libraryID)
-
-	// Get current data to check session tracking
-	data, err := s.repo.Get(ctx, statsPath)
-	if err != nil {
-		s.logger.Warn("Failed to get library stats", zap.Error(err))
-		return
-	}
-
-	sessionIDs := []string{}
-	if sessionsRaw, ok := data["sessionIds"].([]interface{}); ok {
-		for _, sid := range sessionsRaw {
-			if sidStr, ok := sid.(string); ok {
-				sessionIDs = append(sessionIDs, sidStr)
-			}
+// writeVoteLibraries stores the stats updated by applyLibraryVote
+func writeVoteLibraries(tx interfaces.Transaction, ownerID string, libraries map[string]map[string]interface{}) error {
+	for libraryID, data := range libraries {
+		if err := tx.Set(photoLibraryPath(ownerID, libraryID), map[string]interface{}{
+			"stats":      data["stats"],
+			"sessionIds": data["sessionIds"],
+		}); err != nil {
+			return fmt.Errorf("failed to update library stats: %w", err)
 		}
 	}
+	return nil
+}
 
-	alreadyCounted := false
-	for _, sid := range sessionIDs {
-		if sid == sessionID {
-			alreadyCounted = true
-			break
+// applyLibraryVote counts a vote in a library document's stats when delta is
+// 1, or takes it back out when delta is -1. With changeSession the battle is
+// also added to or removed from the library's sessions. Counts stop at zero.
+func applyLibraryVote(data map[string]interface{}, sessionID string, won bool, delta int, changeSession bool) {
+	stats := map[string]interface{}{}
+	if existing, ok := data["stats"].(map[string]interface{}); ok {
+		for k, v := range existing {
+			stats[k] = v
 		}
 	}
-
-	// Update stats
-	updates := map[string]interface{}{
-		"stats.totalVotes":  incrementValue(1),
-		"stats.lastVotedAt": time.Now(),
+	addCount := func(key string, n int) {
+		current, _ := toFloat(stats[key])
+		stats[key] = int64(max(0, int(current)+n))
 	}
 
-	if result == "win" {
-		updates["stats.yesVotes"] = incrementValue(1)
+	addCount("totalVotes", delta)
+	if won {
+		addCount("yesVotes", delta)
 	}
-
-	if !alreadyCounted {
-		updates["stats.sessionCount"] = incrementValue(1)
-		sessionIDs = append(sessionIDs, sessionID)
-		updates["sessionIds"] = sessionIDs
+	if delta > 0 {
+		stats["lastVotedAt"] = time.Now()
 	}
 
-	if err := s.repo.Update(ctx, statsPath, updates); err != nil {
-		s.logger.Warn("Failed to update library stats", zap.Error(err))
+	sessionIDs := toStringSlice(data["sessionIds"])
+	if changeSession {
+		addCount("sessionCount", delta)
+		if delta > 0 {
+			sessionIDs = append(sessionIDs, sessionID)
+		} else {
+			sessionIDs = slices.DeleteFunc(slices.Clone(sessionIDs), func(id string) bool { return id == sessionID })
+		}
 	}
+
+	data["stats"] = stats
+	data["sessionIds"] = sessionIDs
 }
 
-// Helper to create increment value
-func incrementValue(n int) interface{} {
-	// This is a placeholder - in actual implementation would use Firestore FieldValue.increment
-	// For now we'll handle it differently
-	return n
+func photoLibraryPath(ownerID, libraryID string) string {
+	return fmt.Sprintf("users/%s/photoLibrary/%s", ownerID, libraryID)
 }
 
-// GetNextPair selects the next optimal photo pair using Swiss-system pairing
+// GetNextPair selects the next optimal photo pair using Swiss-system pairing,
+// favouring photos below the battle's minVotesPerPhoto until all reach it
 func (s *PhotoService) GetNextPair(ctx context.Context, sessionID string) (*BattlePhoto, *BattlePhoto, error) {
 	// Get session
 	sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
@@ -243,27 +289,18 @@ func (s *PhotoService) GetNextPair(ctx context.Context, sessionID string) (*Batt
 		return nil, nil, fmt.Errorf("session not found: %w", err)
 	}
 
-	// Parse photos
-	photosRaw, ok := sessionData["photos"].([]interface{})
-	if !ok || len(photosRaw) < 2 {
-		return nil, nil, fmt.Errorf("need at least two photos for a battle")
-	}
-
-	photos := make([]BattlePhoto, len(photosRaw))
-	for i, p := range photosRaw {
-		photoMap, ok := p.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		photos[i] = parseBattlePhoto(photoMap)
-	}
-
+	// Parse photos; a photo added twice must not be paired against itself
+	photos, _ := parseBattlePhotos(sessionData["photos"])
 	if len(photos) < 2 {
 		return nil, nil, fmt.Errorf("need at least two photos for a battle")
 	}
 
-	// Choose pair using Swiss-system algorithm
-	left, right := s.choosePairForRanking(photos)
+	// Cover photos short of the battle's vote threshold first, then choose
+	// pairs using the Swiss-system algorithm
+	left, right, ok := choosePairForCoverage(photos, battleMinVotes(sessionData))
+	if !ok {
+		left, right = s.choosePairForRanking(photos)
+	}
 
 	// Enrich with library data if needed
 	ownerID, _ := sessionData["ownerId"].(string)
@@ -524,21 +561,99 @@ func (s *PhotoService) GetSignedURL(
 	return url, expires, nil
 }
 
+// ReadObject returns the contents of a stored object
+func (s *PhotoService) ReadObject(ctx context.Context, path string) ([]byte, error) {
+	reader, err := s.storageClient.Bucket(s.storageBucket).Object(path).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// WriteObject stores data at path, replacing any existing object
+func (s *PhotoService) WriteObject(ctx context.Context, path, contentType string, data []byte) error {
+	writer := s.storageClient.Bucket(s.storageBucket).Object(path).NewWriter(ctx)
+	writer.ContentType = contentType
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// DeleteObject removes a storage object; an already missing object is not an error
+func (s *PhotoService) DeleteObject(ctx context.Context, path string) error {
+	err := s.storageClient.Bucket(s.storageBucket).Object(path).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	return nil
+}
+
+// PrefixSize returns the total size in bytes and the number of objects stored
+// under prefix. Every object is listed, so callers should cache the result.
+func (s *PhotoService) PrefixSize(ctx context.Context, prefix string) (int64, int, error) {
+	query := &storage.Query{Prefix: prefix}
+	if err := query.SetAttrSelection([]string{"Size"}); err != nil {
+		return 0, 0, err
+	}
+
+	var bytes int64
+	objects := 0
+	it := s.storageClient.Bucket(s.storageBucket).Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		bytes += attrs.Size
+		objects++
+	}
+	return bytes, objects, nil
+}
+
 // assertUserOwnsPath verifies user owns the storage path
 func (s *PhotoService) assertUserOwnsPath(userID string, path string) error {
-	// Path must start with "images/"
-	if len(path) < 7 || path[:7] != "images/" {
-		return fmt.Errorf("invalid storage path")
-	}
+	return userOwnsPath(userID, path)
+}
 
-	// Path format: images/{type}/{uid}/{filename}
-	// Split and verify UID matches
+// userOwnsPath verifies a storage path belongs to userID. Accepted layouts are
+// images/{type}/{uid}/{filename} and users/{uid}/attachments/{filename}.
+func userOwnsPath(userID string, path string) error {
 	segments := splitPath(path)
-	if len(segments) < 4 {
-		return fmt.Errorf("path is incomplete")
+	for _, segment := range segments {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage path")
+		}
+	}
+
+	var pathUID string
+	switch {
+	case strings.HasPrefix(path, "images/"):
+		if len(segments) < 4 {
+			return fmt.Errorf("path is incomplete")
+		}
+		pathUID = segments[2]
+	case strings.HasPrefix(path, "users/") && len(segments) >= 3 && segments[2] == "attachments":
+		if len(segments) < 4 {
+			return fmt.Errorf("path is incomplete")
+		}
+		pathUID = segments[1]
+	default:
+		return fmt.Errorf("invalid storage path")
 	}
 
-	pathUID := segments[2]
 	if pathUID != userID {
 		return fmt.Errorf("permission denied: cannot access other users' files")
 	}
@@ -566,6 +681,37 @@ func splitPath(path string) []string {
 	return result
 }
 
+// parseBattlePhotos parses a session's photos, dropping entries without an ID
+// and merging repeated IDs. The merged photo keeps the position of the first
+// occurrence and the standing of the most-voted one, since votes may have
+// landed on either copy. duplicates counts the entries merged away.
+func parseBattlePhotos(raw interface{}) (photos []BattlePhoto, duplicates int) {
+	items, _ := raw.([]interface{})
+	photos = make([]BattlePhoto, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		photoMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		photo := parseBattlePhoto(photoMap)
+		if photo.ID == "" {
+			continue
+		}
+		i, seen := index[photo.ID]
+		if !seen {
+			index[photo.ID] = len(photos)
+			photos = append(photos, photo)
+			continue
+		}
+		duplicates++
+		if photo.TotalVotes > photos[i].TotalVotes {
+			photos[i] = photo
+		}
+	}
+	return photos, duplicates
+}
+
 // parseBattlePhoto parses a BattlePhoto from Firestore data
 func parseBattlePhoto(data map[string]interface{}) BattlePhoto {
 	photo := BattlePhoto{
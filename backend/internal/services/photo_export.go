@@ -0,0 +1,181 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"go.uber.org/zap"
+)
+
+// MediaExportEntry is one original in a media export
+type MediaExportEntry struct {
+	ID             string    `json:"id"`
+	File           string    `json:"file"` // path inside the ZIP
+	StoragePath    string    `json:"storagePath"`
+	ContentHash    string    `json:"contentHash"`
+	MediaUpdatedAt time.Time `json:"mediaUpdatedAt"`
+	Size           int64     `json:"size"`
+}
+
+// MediaExportManifest describes one page of a media export. Since is nil for
+// a full export; Unchanged counts the photos a delta export left out, and
+// Missing lists the storage paths of library photos whose original is gone.
+// NextCursor is set when the page is full and more originals remain.
+type MediaExportManifest struct {
+	Since      *time.Time         `json:"since,omitempty"`
+	Cursor     string             `json:"cursor,omitempty"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	Media      []MediaExportEntry `json:"media"`
+	Unchanged  int                `json:"unchanged"`
+	Missing    []string           `json:"missing"`
+}
+
+// photoMediaMetadata records the content hash and change time of a library
+// photo's original, which incremental media exports compare against
+func photoMediaMetadata(data []byte, at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"contentHash":    photoContentHash(data),
+		"mediaUpdatedAt": at,
+	}
+}
+
+// photoContentHash is the hex SHA-256 of an object's content
+func photoContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// photoMediaUpdatedAt is when a library photo's original last changed:
+// mediaUpdatedAt, or createdAt for photos stored before it was recorded.
+// ok is false when neither is readable.
+func photoMediaUpdatedAt(photo map[string]interface{}) (time.Time, bool) {
+	if at, ok := parseFlexibleDate(photo["mediaUpdatedAt"]); ok {
+		return at, true
+	}
+	return parseFlexibleDate(photo["createdAt"])
+}
+
+// ExportMedia writes a ZIP of the originals of the user's library photos to
+// w, under media/, followed by a manifest.json. With a non-zero since, only
+// originals added or changed after since are included, so successive calls
+// make cheap incremental backups. Photos without a readable change time are
+// always included. Thumbnails are left out since they can be regenerated.
+//
+// Exports are paged in storage path order, at most MaxMediaExportPhotos
+// originals and MaxMediaExportBytes of them per page (a single larger
+// original still makes a page). Pass the manifest's NextCursor as cursor to
+// get the next page; an empty cursor starts from the beginning.
+// On error, w may hold a partial archive.
+func (s *PhotoUploadService) ExportMedia(ctx context.Context, uid string, since time.Time, cursor string, w io.Writer) (*MediaExportManifest, error) {
+	photos, err := s.repo.List(ctx, fmt.Sprintf("users/%s/photoLibrary", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list photos: %w", err)
+	}
+
+	manifest := &MediaExportManifest{
+		Cursor:     cursor,
+		ExportedAt: time.Now().UTC(),
+		Media:      []MediaExportEntry{},
+		Missing:    []string{},
+	}
+	if !since.IsZero() {
+		manifest.Since = &since
+	}
+
+	var changed []map[string]interface{}
+	for _, photo := range photos {
+		storagePath := getStringField(photo, "storagePath")
+		if storagePath == "" || storagePath <= cursor || userOwnsPath(uid, storagePath) != nil {
+			continue
+		}
+		if at, ok := photoMediaUpdatedAt(photo); ok && !since.IsZero() && !at.After(since) {
+			manifest.Unchanged++
+			continue
+		}
+		changed = append(changed, photo)
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return getStringField(changed[i], "storagePath") < getStringField(changed[j], "storagePath")
+	})
+
+	archive := zip.NewWriter(w)
+	var exportedBytes int64
+	lastPath := cursor
+	for _, photo := range changed {
+		storagePath := getStringField(photo, "storagePath")
+		if len(manifest.Media) >= s.exportMaxPhotos {
+			manifest.NextCursor = lastPath
+			break
+		}
+		data, err := s.storage.ReadObject(ctx, storagePath)
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			manifest.Missing = append(manifest.Missing, storagePath)
+			lastPath = storagePath
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", storagePath, err)
+		}
+		if len(manifest.Media) > 0 && exportedBytes+int64(len(data)) > s.exportMaxBytes {
+			manifest.NextCursor = lastPath
+			break
+		}
+		exportedBytes += int64(len(data))
+		lastPath = storagePath
+
+		entry := MediaExportEntry{
+			ID:          getStringField(photo, "id"),
+			File:        "media/" + path.Base(storagePath),
+			StoragePath: storagePath,
+			ContentHash: photoContentHash(data),
+			Size:        int64(len(data)),
+		}
+		if at, ok := photoMediaUpdatedAt(photo); ok {
+			entry.MediaUpdatedAt = at
+		}
+
+		file, err := archive.Create(entry.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", entry.File, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", entry.File, err)
+		}
+		manifest.Media = append(manifest.Media, entry)
+	}
+
+	file, err := archive.Create("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add manifest: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to add manifest: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	s.logger.Info("Media exported",
+		zap.String("uid", uid),
+		zap.Bool("delta", !since.IsZero()),
+		zap.Int("exported", len(manifest.Media)),
+		zap.Int("unchanged", manifest.Unchanged),
+		zap.Int("missing", len(manifest.Missing)),
+		zap.Int64("bytes", exportedBytes),
+		zap.Bool("more", manifest.NextCursor != ""),
+	)
+	return manifest, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/color"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// readMediaExport returns the files of an export ZIP and its manifest
+func readMediaExport(t *testing.T, data []byte) (map[string][]byte, MediaExportManifest) {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	var manifest MediaExportManifest
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		if file.Name == "manifest.json" {
+			require.NoError(t, json.Unmarshal(content, &manifest))
+			continue
+		}
+		files[file.Name] = content
+	}
+	return files, manifest
+}
+
+func TestPhotoUploadService_ExportMediaDelta(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	store := newFakePhotoStorage()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), store, config.UploadConfig{})
+	ctx := context.Background()
+	lastBackup := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	addPhoto := func(id string, fields map[string]interface{}) {
+		storagePath := "images/original/user-1/" + id + ".jpg"
+		store.objects[storagePath] = []byte("jpeg-" + id)
+		photo := map[string]interface{}{"id": id, "storagePath": storagePath}
+		for key, value := range fields {
+			photo[key] = value
+		}
+		repo.AddDocument(photoLibraryPath("user-1", id), photo)
+	}
+	addPhoto("old", map[string]interface{}{"mediaUpdatedAt": lastBackup.Add(-24 * time.Hour)})
+	addPhoto("rotated", map[string]interface{}{
+		"createdAt":      "2026-01-01T00:00:00Z",
+		"mediaUpdatedAt": lastBackup.Add(time.Hour),
+	})
+	addPhoto("legacy-old", map[string]interface{}{"createdAt": "2026-08-01T10:00:00Z"})
+	addPhoto("legacy-new", map[string]interface{}{"createdAt": "2026-09-02T10:00:00Z"})
+	addPhoto("unknown-age", nil)
+	repo.AddDocument(photoLibraryPath("user-1", "gone"), map[string]interface{}{
+		"id": "gone", "storagePath": "images/original/user-1/gone.jpg", "createdAt": "2026-09-03T00:00:00Z",
+	})
+
+	var delta bytes.Buffer
+	manifest, err := svc.ExportMedia(ctx, "user-1", lastBackup, "", &delta)
+	require.NoError(t, err)
+
+	files, written := readMediaExport(t, delta.Bytes())
+	assert.Equal(t, map[string][]byte{
+		"media/legacy-new.jpg":  []byte("jpeg-legacy-new"),
+		"media/rotated.jpg":     []byte("jpeg-rotated"),
+		"media/unknown-age.jpg": []byte("jpeg-unknown-age"),
+	}, files, "unchanged media is omitted from the delta")
+	assert.Equal(t, 2, manifest.Unchanged)
+	assert.Equal(t, []string{"images/original/user-1/gone.jpg"}, manifest.Missing)
+	require.Len(t, written.Media, 3)
+	assert.Equal(t, photoContentHash([]byte("jpeg-rotated")), written.Media[1].ContentHash)
+	assert.True(t, written.Since.Equal(lastBackup))
+
+	var full bytes.Buffer
+	_, err = svc.ExportMedia(ctx, "user-1", time.Time{}, "", &full)
+	require.NoError(t, err)
+	files, written = readMediaExport(t, full.Bytes())
+	assert.Len(t, files, 5)
+	assert.Nil(t, written.Since)
+}
+
+func TestPhotoUploadService_ExportMediaPages(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	store := newFakePhotoStorage()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), store, config.UploadConfig{})
+	svc.exportMaxPhotos = 2
+	svc.exportMaxBytes = 10
+	ctx := context.Background()
+
+	for id, content := range map[string]string{"a": "12345", "b": "1234", "c": "123", "d": "123456789012", "e": "1"} {
+		storagePath := "images/original/user-1/" + id + ".jpg"
+		store.objects[storagePath] = []byte(content)
+		repo.AddDocument(photoLibraryPath("user-1", id), map[string]interface{}{"id": id, "storagePath": storagePath})
+	}
+
+	var pages [][]string
+	cursor := ""
+	for {
+		var archive bytes.Buffer
+		manifest, err := svc.ExportMedia(ctx, "user-1", time.Time{}, cursor, &archive)
+		require.NoError(t, err)
+		files, _ := readMediaExport(t, archive.Bytes())
+		var names []string
+		for _, entry := range manifest.Media {
+			names = append(names, entry.File)
+			assert.Contains(t, files, entry.File)
+		}
+		pages = append(pages, names)
+		if manifest.NextCursor == "" {
+			break
+		}
+		require.Less(t, len(pages), 5, "export should finish")
+		cursor = manifest.NextCursor
+	}
+
+	// Two per page at most, within 10 bytes unless one original is larger
+	assert.Equal(t, [][]string{
+		{"media/a.jpg", "media/b.jpg"},
+		{"media/c.jpg"},
+		{"media/d.jpg"},
+		{"media/e.jpg"},
+	}, pages)
+}
+
+func TestPhotoUploadService_UploadRecordsMediaMetadata(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), newFakePhotoStorage(), config.UploadConfig{})
+	data := testPhoto(t, "jpeg", 40, 30, color.RGBA{R: 200, A: 255})
+
+	results, err := svc.UploadBatch(context.Background(), "user-1", []PhotoUploadFile{{Name: "a.jpg", Data: data}})
+	require.NoError(t, err)
+	require.True(t, results[0].Success)
+
+	photo := repo.Documents[photoLibraryPath("user-1", results[0].ID)]
+	assert.Equal(t, photoContentHash(data), photo["contentHash"])
+	assert.IsType(t, time.Time{}, photo["mediaUpdatedAt"])
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// DefaultVoteHistoryLimit is the page size when none is requested
+	DefaultVoteHistoryLimit = 50
+	// MaxVoteHistoryLimit caps the votes returned in one page
+	MaxVoteHistoryLimit = 200
+
+	// eloK is the Elo K-factor, the largest rating change a single vote makes
+	eloK = 32.0
+)
+
+var (
+	// ErrNotPhotoBattleOwner is returned when a user manages another user's battle
+	ErrNotPhotoBattleOwner = errors.New("not the owner of this photo battle")
+	// ErrInvalidVoteHistoryCursor is returned for a startAfter that is not a vote in the battle
+	ErrInvalidVoteHistoryCursor = errors.New("invalid vote history cursor")
+	// ErrNoVoteToUndo is returned when a battle has no votes left to undo
+	ErrNoVoteToUndo = errors.New("no vote to undo")
+	// ErrVoteNotUndoable is returned for a vote recorded without a pre-vote
+	// snapshot, or whose photos are no longer in the battle
+	ErrVoteNotUndoable = errors.New("vote cannot be undone")
+	// ErrInvalidVote is returned for a vote whose winner and loser are the same photo
+	ErrInvalidVote = errors.New("invalid vote")
+	// ErrPhotoNotInBattle is returned for a vote naming a photo the battle does not contain
+	ErrPhotoNotInBattle = errors.New("photo not in battle")
+)
+
+// VoteHistoryPage is one page of a battle's votes, newest first. NextCursor is
+// the startAfter for the next page and is empty on the last page.
+type VoteHistoryPage struct {
+	Votes      []VoteHistory `json:"votes"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// ListVoteHistory returns the owner's battle votes ordered by createdAt
+// descending, starting after the vote with ID startAfter
+func (s *PhotoService) ListVoteHistory(ctx context.Context, uid, sessionID string, limit int, startAfter string) (*VoteHistoryPage, error) {
+	if _, err := s.ownedBattle(ctx, uid, sessionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultVoteHistoryLimit
+	}
+	if limit > MaxVoteHistoryLimit {
+		limit = MaxVoteHistoryLimit
+	}
+
+	historyPath := voteHistoryPath(sessionID)
+	query := s.repo.Collection(historyPath).OrderBy("createdAt", firestore.Desc)
+	if startAfter != "" {
+		cursor, err := s.repo.GetDocument(ctx, fmt.Sprintf("%s/%s", historyPath, startAfter))
+		if err != nil {
+			if errors.Is(err, interfaces.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidVoteHistoryCursor, startAfter)
+			}
+			return nil, fmt.Errorf("failed to load vote history cursor: %w", err)
+		}
+		query = query.StartAfter(cursor)
+	}
+
+	// Read one extra vote to know whether there is a next page
+	page := &VoteHistoryPage{Votes: []VoteHistory{}}
+	if err := s.repo.ForEach(ctx, query.Limit(limit+1), func(doc *firestore.DocumentSnapshot) error {
+		page.Votes = append(page.Votes, parseVoteHistory(doc.Ref.ID, doc.Data()))
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list vote history: %w", err)
+	}
+	if len(page.Votes) > limit {
+		page.Votes = page.Votes[:limit]
+		page.NextCursor = page.Votes[limit-1].ID
+	}
+	return page, nil
+}
+
+// UndoLastVote reverses the battle's most recent vote and removes it from the
+// history. The winner and loser get back their pre-vote snapshot values and
+// the vote is taken out of their photo library stats, all in one transaction.
+func (s *PhotoService) UndoLastVote(ctx context.Context, uid, sessionID string) (*VoteHistory, error) {
+	sessionData, err := s.ownedBattle(ctx, uid, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Battles voted on before lastVoteId was recorded fall back to the newest
+	// history entry. It is only used if the session still has no lastVoteId
+	// inside the transaction.
+	historyPath := voteHistoryPath(sessionID)
+	fallbackVoteID := ""
+	if getStringField(sessionData, "lastVoteId") == "" {
+		docs, err := s.repo.ListOrdered(ctx, historyPath, []interfaces.Ordering{{Field: "createdAt", Direction: firestore.Desc}}, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load last vote: %w", err)
+		}
+		if len(docs) == 0 {
+			return nil, ErrNoVoteToUndo
+		}
+		if fallbackVoteID = getStringField(docs[0], "id"); fallbackVoteID == "" {
+			return nil, fmt.Errorf("%w: no pre-vote snapshot recorded", ErrVoteNotUndoable)
+		}
+	}
+
+	sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
+	var vote VoteHistory
+	err = s.repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		sessionData, err := tx.Get(sessionPath)
+		if err != nil {
+			return fmt.Errorf("session not found: %w", err)
+		}
+		ownerID, _ := sessionData["ownerId"].(string)
+		if ownerID != uid {
+			return ErrNotPhotoBattleOwner
+		}
+
+		voteID := getStringField(sessionData, "lastVoteId")
+		if voteID == "" {
+			voteID = fallbackVoteID
+		}
+		if voteID == "" {
+			return ErrNoVoteToUndo
+		}
+		voteData, err := tx.Get(fmt.Sprintf("%s/%s", historyPath, voteID))
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return ErrNoVoteToUndo
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load last vote: %w", err)
+		}
+		vote = parseVoteHistory(voteID, voteData)
+		if vote.WinnerBefore == nil || vote.LoserBefore == nil {
+			return fmt.Errorf("%w: no pre-vote snapshot recorded", ErrVoteNotUndoable)
+		}
+
+		photos, _ := parseBattlePhotos(sessionData["photos"])
+		winnerIdx, loserIdx := -1, -1
+		for i, photo := range photos {
+			switch photo.ID {
+			case vote.WinnerID:
+				winnerIdx = i
+			case vote.LoserID:
+				loserIdx = i
+			}
+		}
+		if winnerIdx < 0 || loserIdx < 0 {
+			return fmt.Errorf("%w: photo no longer in battle", ErrVoteNotUndoable)
+		}
+		winner, loser := &photos[winnerIdx], &photos[loserIdx]
+
+		libraries, err := readVoteLibraries(tx, ownerID, winner.LibraryID, loser.LibraryID)
+		if err != nil {
+			return err
+		}
+
+		winner.restore(*vote.WinnerBefore)
+		loser.restore(*vote.LoserBefore)
+
+		sessionLibraries := toStringSlice(voteData["sessionLibraries"])
+		for _, result := range []struct {
+			libraryID string
+			won       bool
+		}{{winner.LibraryID, true}, {loser.LibraryID, false}} {
+			library, ok := libraries[result.libraryID]
+			if !ok {
+				continue
+			}
+			removeSession := slices.Contains(sessionLibraries, result.libraryID)
+			sessionLibraries = slices.DeleteFunc(sessionLibraries, func(id string) bool { return id == result.libraryID })
+			applyLibraryVote(library, sessionID, result.won, -1, removeSession)
+		}
+
+		if err := tx.Set(sessionPath, map[string]interface{}{
+			"photos":     battlePhotosToMaps(photos),
+			"lastVoteId": getStringField(voteData, "previousVoteId"),
+			"updatedAt":  time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+		if err := writeVoteLibraries(tx, ownerID, libraries); err != nil {
+			return err
+		}
+		if err := tx.Delete(fmt.Sprintf("%s/%s", historyPath, vote.ID)); err != nil {
+			return fmt.Errorf("failed to remove vote: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Photo vote undone",
+		zap.String("sessionId", sessionID),
+		zap.String("voteId", vote.ID),
+		zap.String("winnerId", vote.WinnerID),
+		zap.String("loserId", vote.LoserID),
+	)
+	return &vote, nil
+}
+
+// BattleRepairResult reports a RepairBattles run
+type BattleRepairResult struct {
+	Sessions          int `json:"sessions"`
+	Repaired          int `json:"repaired"`
+	DuplicatesRemoved int `json:"duplicatesRemoved"`
+}
+
+// RepairBattles rewrites the owner's battles whose photo arrays repeat a
+// photo ID, keeping one entry per photo as parseBattlePhotos merges them
+func (s *PhotoService) RepairBattles(ctx context.Context, uid string) (*BattleRepairResult, error) {
+	sessions, err := s.repo.ListWhere(ctx, "photoBattles", []repository.Filter{repository.Eq("ownerId", uid)}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list photo battles: %w", err)
+	}
+
+	result := &BattleRepairResult{Sessions: len(sessions)}
+	for _, session := range sessions {
+		if _, duplicates := parseBattlePhotos(session["photos"]); duplicates == 0 {
+			continue
+		}
+		sessionID := getStringField(session, "id")
+		sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
+		removed := 0
+		err := s.repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+			sessionData, err := tx.Get(sessionPath)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			photos, duplicates := parseBattlePhotos(sessionData["photos"])
+			if duplicates == 0 {
+				return nil
+			}
+			removed = duplicates
+			return tx.Set(sessionPath, map[string]interface{}{
+				"photos":    battlePhotosToMaps(photos),
+				"updatedAt": time.Now(),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair photo battle %s: %w", sessionID, err)
+		}
+		if removed > 0 {
+			result.Repaired++
+			result.DuplicatesRemoved += removed
+		}
+	}
+
+	if result.Repaired > 0 {
+		s.logger.Info("Photo battles repaired",
+			zap.String("uid", uid),
+			zap.Int("repaired", result.Repaired),
+			zap.Int("duplicatesRemoved", result.DuplicatesRemoved),
+		)
+	}
+	return result, nil
+}
+
+// ownedBattle loads a battle session, checking that uid owns it
+func (s *PhotoService) ownedBattle(ctx context.Context, uid, sessionID string) (map[string]interface{}, error) {
+	sessionData, err := s.repo.Get(ctx, fmt.Sprintf("photoBattles/%s", sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	if ownerID, _ := sessionData["ownerId"].(string); ownerID != uid {
+		return nil, ErrNotPhotoBattleOwner
+	}
+	return sessionData, nil
+}
+
+// eloRatings returns the winner's and loser's ratings after a vote
+func eloRatings(winnerRating, loserRating int) (int, int) {
+	expectedWinner := 1.0 / (1.0 + math.Pow(10, float64(loserRating-winnerRating)/400.0))
+	expectedLoser := 1.0 / (1.0 + math.Pow(10, float64(winnerRating-loserRating)/400.0))
+
+	newWinner := int(math.Max(0, math.Round(float64(winnerRating)+eloK*(1.0-expectedWinner))))
+	newLoser := int(math.Max(0, math.Round(float64(loserRating)+eloK*(0.0-expectedLoser))))
+	return newWinner, newLoser
+}
+
+// battlePhotosToMaps converts photos to the map form Firestore reads them back as
+func battlePhotosToMaps(photos []BattlePhoto) []interface{} {
+	result := make([]interface{}, len(photos))
+	for i, photo := range photos {
+		data := map[string]interface{}{
+			"id":          photo.ID,
+			"url":         photo.URL,
+			"storagePath": photo.StoragePath,
+			"rating":      int64(photo.Rating),
+			"wins":        int64(photo.Wins),
+			"losses":      int64(photo.Losses),
+			"totalVotes":  int64(photo.TotalVotes),
+		}
+		setIfNotEmpty(data, "libraryId", photo.LibraryID)
+		setIfNotEmpty(data, "thumbnailUrl", photo.ThumbnailURL)
+		setIfNotEmpty(data, "thumbnailPath", photo.ThumbnailPath)
+		result[i] = data
+	}
+	return result
+}
+
+func setIfNotEmpty(data map[string]interface{}, key, value string) {
+	if value != "" {
+		data[key] = value
+	}
+}
+
+func parseVoteHistory(id string, data map[string]interface{}) VoteHistory {
+	vote := VoteHistory{
+		ID:       id,
+		WinnerID: getStringField(data, "winnerId"),
+		LoserID:  getStringField(data, "loserId"),
+		VoterID:  getStringField(data, "voterId"),
+	}
+	vote.CreatedAt, _ = parseFlexibleDate(data["createdAt"])
+	vote.WinnerBefore = parsePhotoVoteSnapshot(data["winnerBefore"])
+	vote.LoserBefore = parsePhotoVoteSnapshot(data["loserBefore"])
+	return vote
+}
+
+// restore sets the photo's standing back to a snapshot
+func (p *BattlePhoto) restore(snapshot PhotoVoteSnapshot) {
+	p.Rating = snapshot.Rating
+	p.Wins = snapshot.Wins
+	p.Losses = snapshot.Losses
+	p.TotalVotes = snapshot.TotalVotes
+}
+
+func newPhotoVoteSnapshot(photo BattlePhoto) PhotoVoteSnapshot {
+	return PhotoVoteSnapshot{
+		Rating:     photo.Rating,
+		Wins:       photo.Wins,
+		Losses:     photo.Losses,
+		TotalVotes: photo.TotalVotes,
+	}
+}
+
+func (s PhotoVoteSnapshot) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"rating":     int64(s.Rating),
+		"wins":       int64(s.Wins),
+		"losses":     int64(s.Losses),
+		"totalVotes": int64(s.TotalVotes),
+	}
+}
+
+// parsePhotoVoteSnapshot reads a stored snapshot; nil when it is missing or
+// has no rating, as in history written before snapshots were recorded
+func parsePhotoVoteSnapshot(raw interface{}) *PhotoVoteSnapshot {
+	data, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	rating, ok := toFloat(data["rating"])
+	if !ok {
+		return nil
+	}
+	snapshot := &PhotoVoteSnapshot{Rating: int(rating)}
+	if wins, ok := toFloat(data["wins"]); ok {
+		snapshot.Wins = int(wins)
+	}
+	if losses, ok := toFloat(data["losses"]); ok {
+		snapshot.Losses = int(losses)
+	}
+	if totalVotes, ok := toFloat(data["totalVotes"]); ok {
+		snapshot.TotalVotes = int(totalVotes)
+	}
+	return snapshot
+}
+
+func voteHistoryPath(sessionID string) string {
+	return fmt.Sprintf("photoBattles/%s/history", sessionID)
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// MaxBulkPhotoUpdates is the maximum number of photos in a bulk tag update
+	MaxBulkPhotoUpdates = 500
+	// MaxPhotoTagLength caps the length of a single tag
+	MaxPhotoTagLength = 50
+	// MaxAlbumPhotos caps the number of photos in an album
+	MaxAlbumPhotos = 500
+)
+
+// ErrInvalidPhotoLibraryRequest is wrapped into validation errors for tag and album requests
+var ErrInvalidPhotoLibraryRequest = errors.New("invalid photo library request")
+
+// PhotoLibraryService organizes a user's photo library with tags and albums.
+// Tags are a "tags" array on each photoLibrary document; albums live in
+// users/{uid}/albums as {name, photoIds}.
+type PhotoLibraryService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewPhotoLibraryService creates a new photo library service
+func NewPhotoLibraryService(repo interfaces.Repository, logger *zap.Logger) *PhotoLibraryService {
+	return &PhotoLibraryService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// PhotoLibraryFilter narrows a library listing; empty fields are ignored
+type PhotoLibraryFilter struct {
+	Tag     string
+	AlbumID string
+}
+
+// PhotoTagResult is the outcome of a tag update for a single photo
+type PhotoTagResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PhotoAlbum groups library photos
+type PhotoAlbum struct {
+	ID        string    `json:"id" firestore:"id"`
+	Name      string    `json:"name" firestore:"name"`
+	PhotoIDs  []string  `json:"photoIds" firestore:"photoIds"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// AddTags adds tags to many photos, reporting the outcome per photo
+func (s *PhotoLibraryService) AddTags(ctx context.Context, uid string, photoIDs, tags []string) ([]PhotoTagResult, error) {
+	return s.updateTags(ctx, uid, photoIDs, tags, true)
+}
+
+// RemoveTags removes tags from many photos, reporting the outcome per photo
+func (s *PhotoLibraryService) RemoveTags(ctx context.Context, uid string, photoIDs, tags []string) ([]PhotoTagResult, error) {
+	return s.updateTags(ctx, uid, photoIDs, tags, false)
+}
+
+func (s *PhotoLibraryService) updateTags(ctx context.Context, uid string, photoIDs, tags []string, add bool) ([]PhotoTagResult, error) {
+	if len(photoIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one photo id is required", ErrInvalidPhotoLibraryRequest)
+	}
+	if len(photoIDs) > MaxBulkPhotoUpdates {
+		return nil, fmt.Errorf("%w: maximum is %d photos", ErrInvalidPhotoLibraryRequest, MaxBulkPhotoUpdates)
+	}
+	normalized, err := normalizePhotoTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(normalized))
+	for i, tag := range normalized {
+		values[i] = tag
+	}
+
+	results := make([]PhotoTagResult, 0, len(photoIDs))
+	updated := 0
+	seen := make(map[string]bool)
+
+	for _, id := range photoIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		path := fmt.Sprintf("users/%s/photoLibrary/%s", uid, id)
+		if _, err := s.repo.Get(ctx, path); err != nil {
+			result := PhotoTagResult{ID: id, Error: "failed to load photo"}
+			if errors.Is(err, interfaces.ErrNotFound) {
+				result.Error = "photo not found"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		// Array transforms are atomic, so concurrent tag edits on a photo
+		// never overwrite each other
+		var err error
+		if add {
+			err = s.repo.ArrayUnion(ctx, path, "tags", values...)
+		} else {
+			err = s.repo.ArrayRemove(ctx, path, "tags", values...)
+		}
+		if err != nil {
+			s.logger.Error("Photo tag update failed", zap.String("uid", uid), zap.String("photoId", id), zap.Error(err))
+			results = append(results, PhotoTagResult{ID: id, Error: "failed to update photo"})
+			continue
+		}
+		results = append(results, PhotoTagResult{ID: id, Success: true})
+		updated++
+	}
+
+	if updated > 0 {
+		s.logger.Info("Bulk photo tags updated",
+			zap.String("uid", uid),
+			zap.Bool("add", add),
+			zap.Strings("tags", normalized),
+			zap.Int("updated", updated),
+		)
+	}
+
+	return results, nil
+}
+
+// CreateAlbum creates an album from existing library photos
+func (s *PhotoLibraryService) CreateAlbum(ctx context.Context, uid, name string, photoIDs []string) (*PhotoAlbum, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: album name is required", ErrInvalidPhotoLibraryRequest)
+	}
+	photoIDs = dedupeStrings(photoIDs)
+	if len(photoIDs) > MaxAlbumPhotos {
+		return nil, fmt.Errorf("%w: maximum is %d photos per album", ErrInvalidPhotoLibraryRequest, MaxAlbumPhotos)
+	}
+
+	for _, id := range photoIDs {
+		if _, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/photoLibrary/%s", uid, id)); err != nil {
+			if errors.Is(err, interfaces.ErrNotFound) {
+				return nil, fmt.Errorf("%w: photo %s not found", ErrInvalidPhotoLibraryRequest, id)
+			}
+			return nil, err
+		}
+	}
+
+	album := &PhotoAlbum{
+		ID:        generateID(),
+		Name:      name,
+		PhotoIDs:  photoIDs,
+		CreatedAt: time.Now(),
+	}
+	err := s.repo.CreateDocument(ctx, fmt.Sprintf("users/%s/albums/%s", uid, album.ID), map[string]interface{}{
+		"id":        album.ID,
+		"name":      album.Name,
+		"photoIds":  album.PhotoIDs,
+		"createdAt": album.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create album: %w", err)
+	}
+
+	s.logger.Info("Photo album created",
+		zap.String("uid", uid),
+		zap.String("albumId", album.ID),
+		zap.Int("photos", len(photoIDs)),
+	)
+
+	return album, nil
+}
+
+// ListAlbums returns the user's albums
+func (s *PhotoLibraryService) ListAlbums(ctx context.Context, uid string) ([]PhotoAlbum, error) {
+	docs, err := s.repo.List(ctx, fmt.Sprintf("users/%s/albums", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list albums: %w", err)
+	}
+
+	albums := make([]PhotoAlbum, 0, len(docs))
+	for _, doc := range docs {
+		albums = append(albums, parsePhotoAlbum(doc))
+	}
+	return albums, nil
+}
+
+// ListPhotos returns library photos, optionally restricted to a tag and/or album.
+// Tag-only filters run as an array-contains query; album filters load the
+// album's photos and apply any tag filter to them.
+func (s *PhotoLibraryService) ListPhotos(ctx context.Context, uid string, filter PhotoLibraryFilter) ([]map[string]interface{}, error) {
+	collectionPath := fmt.Sprintf("users/%s/photoLibrary", uid)
+	tag := strings.ToLower(strings.TrimSpace(filter.Tag))
+
+	if filter.AlbumID == "" {
+		var opts []interfaces.QueryOption
+		if tag != "" {
+			opts = append(opts, func(q firestore.Query) firestore.Query {
+				return q.Where("tags", "array-contains", tag)
+			})
+		}
+		docs, err := s.repo.QueryCollection(ctx, collectionPath, opts...)
+		if err != nil {
+			return nil, err
+		}
+		photos := make([]map[string]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			data := doc.Data()
+			data["id"] = doc.Ref.ID
+			photos = append(photos, data)
+		}
+		return photos, nil
+	}
+
+	albumData, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/albums/%s", uid, filter.AlbumID))
+	if err != nil {
+		return nil, err
+	}
+	album := parsePhotoAlbum(albumData)
+
+	photos := []map[string]interface{}{}
+	for _, id := range album.PhotoIDs {
+		data, err := s.repo.Get(ctx, fmt.Sprintf("%s/%s", collectionPath, id))
+		if err != nil {
+			if errors.Is(err, interfaces.ErrNotFound) {
+				// Photo was deleted after being added to the album
+				continue
+			}
+			return nil, err
+		}
+		if tag != "" && !photoHasTag(data, tag) {
+			continue
+		}
+		data["id"] = id
+		photos = append(photos, data)
+	}
+	return photos, nil
+}
+
+// normalizePhotoTags trims, lowercases and dedupes tags
+func normalizePhotoTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if len(tag) > MaxPhotoTagLength {
+			return nil, fmt.Errorf("%w: tags must be at most %d characters", ErrInvalidPhotoLibraryRequest, MaxPhotoTagLength)
+		}
+		normalized = append(normalized, tag)
+	}
+	normalized = dedupeStrings(normalized)
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", ErrInvalidPhotoLibraryRequest)
+	}
+	return normalized, nil
+}
+
+func photoHasTag(data map[string]interface{}, tag string) bool {
+	for _, t := range toStringSlice(data["tags"]) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePhotoAlbum(data map[string]interface{}) PhotoAlbum {
+	album := PhotoAlbum{PhotoIDs: toStringSlice(data["photoIds"])}
+	album.ID, _ = data["id"].(string)
+	album.Name, _ = data["name"].(string)
+	album.CreatedAt, _ = parseFlexibleDate(data["createdAt"])
+	return album
+}
+
+func toStringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return []string{}
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newPhotoLibraryTestRepo() *mocks.MockRepository {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/photoLibrary/p1", map[string]interface{}{"url": "a.jpg", "tags": []interface{}{"beach", "summer"}})
+	repo.AddDocument("users/user1/photoLibrary/p2", map[string]interface{}{"url": "b.jpg", "tags": []interface{}{"city"}})
+	repo.AddDocument("users/user1/photoLibrary/p3", map[string]interface{}{"url": "c.jpg"})
+	return repo
+}
+
+func TestNormalizePhotoTags(t *testing.T) {
+	tags, err := normalizePhotoTags([]string{" Beach ", "beach", "", "Sunset"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beach", "sunset"}, tags)
+
+	_, err = normalizePhotoTags([]string{" ", ""})
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+
+	_, err = normalizePhotoTags([]string{string(make([]byte, MaxPhotoTagLength+1))})
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+}
+
+func TestPhotoLibraryService_AddTagsValidation(t *testing.T) {
+	svc := NewPhotoLibraryService(newPhotoLibraryTestRepo(), zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.AddTags(ctx, "user1", nil, []string{"beach"})
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+
+	_, err = svc.AddTags(ctx, "user1", []string{"p1"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+
+	results, err := svc.RemoveTags(ctx, "user1", []string{"missing", "missing"}, []string{"beach"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "photo not found", results[0].Error)
+}
+
+func TestPhotoLibraryService_UpdateTags(t *testing.T) {
+	repo := newPhotoLibraryTestRepo()
+	svc := NewPhotoLibraryService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	results, err := svc.AddTags(ctx, "user1", []string{"p1", "p3", "missing"}, []string{"Sunset", "beach"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.False(t, results[2].Success)
+	assert.Equal(t, []interface{}{"beach", "summer", "sunset"}, repo.Documents["users/user1/photoLibrary/p1"]["tags"])
+	assert.Equal(t, []interface{}{"sunset", "beach"}, repo.Documents["users/user1/photoLibrary/p3"]["tags"])
+
+	results, err = svc.RemoveTags(ctx, "user1", []string{"p1", "p2"}, []string{"beach"})
+	require.NoError(t, err)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.Equal(t, []interface{}{"summer", "sunset"}, repo.Documents["users/user1/photoLibrary/p1"]["tags"])
+	assert.Equal(t, []interface{}{"city"}, repo.Documents["users/user1/photoLibrary/p2"]["tags"])
+}
+
+func TestPhotoLibraryService_Albums(t *testing.T) {
+	repo := newPhotoLibraryTestRepo()
+	svc := NewPhotoLibraryService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	album, err := svc.CreateAlbum(ctx, "user1", "  Holidays ", []string{"p1", "p2", "p1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Holidays", album.Name)
+	assert.Equal(t, []string{"p1", "p2"}, album.PhotoIDs)
+
+	albums, err := svc.ListAlbums(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	assert.Equal(t, album.ID, albums[0].ID)
+	assert.Equal(t, []string{"p1", "p2"}, albums[0].PhotoIDs)
+
+	_, err = svc.CreateAlbum(ctx, "user1", "", nil)
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+
+	_, err = svc.CreateAlbum(ctx, "user1", "Bad", []string{"missing"})
+	assert.ErrorIs(t, err, ErrInvalidPhotoLibraryRequest)
+}
+
+func TestPhotoLibraryService_ListPhotosByAlbumAndTag(t *testing.T) {
+	repo := newPhotoLibraryTestRepo()
+	repo.AddDocument("users/user1/albums/a1", map[string]interface{}{
+		"id":       "a1",
+		"name":     "Trip",
+		"photoIds": []interface{}{"p1", "p2", "deleted"},
+	})
+	svc := NewPhotoLibraryService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	photos, err := svc.ListPhotos(ctx, "user1", PhotoLibraryFilter{AlbumID: "a1"})
+	require.NoError(t, err)
+	require.Len(t, photos, 2)
+	assert.Equal(t, "p1", photos[0]["id"])
+	assert.Equal(t, "p2", photos[1]["id"])
+
+	photos, err = svc.ListPhotos(ctx, "user1", PhotoLibraryFilter{AlbumID: "a1", Tag: "Beach"})
+	require.NoError(t, err)
+	require.Len(t, photos, 1)
+	assert.Equal(t, "p1", photos[0]["id"])
+
+	_, err = svc.ListPhotos(ctx, "user1", PhotoLibraryFilter{AlbumID: "missing"})
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// exifOrientationTag is the TIFF tag holding the EXIF orientation (1-8)
+	exifOrientationTag = 0x0112
+	// normalizedJPEGQuality is used when re-encoding rotated photos
+	normalizedJPEGQuality = 90
+)
+
+// NormalizePhotoOrientation rotates a library photo and its thumbnail so their
+// pixels are upright, removing the need for viewers to honour EXIF orientation.
+// Re-encoding drops the EXIF block, so the orientation cannot be applied twice.
+// Returns the storage paths that were rewritten.
+func (s *PhotoService) NormalizePhotoOrientation(ctx context.Context, userID, libraryID string) ([]string, error) {
+	libraryPath := fmt.Sprintf("users/%s/photoLibrary/%s", userID, libraryID)
+	libraryData, err := s.repo.Get(ctx, libraryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, field := range []string{"storagePath", "thumbnailPath"} {
+		if path, ok := libraryData[field].(string); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	normalized := []string{}
+	updates := map[string]interface{}{
+		"orientationNormalizedAt": time.Now(),
+	}
+	for _, path := range paths {
+		if err := s.assertUserOwnsPath(userID, path); err != nil {
+			return nil, err
+		}
+
+		output, changed, err := s.normalizeStoredImage(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize %s: %w", path, err)
+		}
+		if !changed {
+			continue
+		}
+		normalized = append(normalized, path)
+		// A rewritten original counts as changed media for incremental exports
+		if path == libraryData["storagePath"] {
+			for key, value := range photoMediaMetadata(output, time.Now()) {
+				updates[key] = value
+			}
+		}
+	}
+
+	if err := s.repo.UpdateDocument(ctx, libraryPath, updates); err != nil {
+		return nil, fmt.Errorf("failed to update photo: %w", err)
+	}
+
+	s.logger.Info("Photo orientation normalized",
+		zap.String("uid", userID),
+		zap.String("libraryId", libraryID),
+		zap.Strings("rewritten", normalized),
+	)
+
+	return normalized, nil
+}
+
+// normalizeStoredImage rewrites a stored image upright, returning the new
+// content; changed is false if it already was upright
+func (s *PhotoService) normalizeStoredImage(ctx context.Context, path string) (output []byte, changed bool, err error) {
+	data, err := s.ReadObject(ctx, path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	output, changed, err = NormalizeImageOrientation(data)
+	if err != nil || !changed {
+		return nil, false, err
+	}
+
+	if err := s.WriteObject(ctx, path, "image/jpeg", output); err != nil {
+		return nil, false, err
+	}
+	return output, true, nil
+}
+
+// NormalizeImageOrientation applies a JPEG's EXIF orientation to its pixels and
+// re-encodes it without EXIF. Images that are not JPEG or are already upright
+// are returned unchanged with changed == false.
+func NormalizeImageOrientation(data []byte) (output []byte, changed bool, err error) {
+	orientation := readJPEGOrientation(data)
+	if orientation <= 1 || orientation > 8 {
+		return data, false, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, applyOrientation(img, orientation), &jpeg.Options{Quality: normalizedJPEGQuality}); err != nil {
+		return nil, false, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// readJPEGOrientation returns the EXIF orientation of a JPEG, or 0 if absent
+func readJPEGOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // Start of scan / end of image: no more metadata
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return 0
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return readTIFFOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 0
+}
+
+// readTIFFOrientation reads the orientation tag from IFD0 of a TIFF header
+func readTIFFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+	return 0
+}
+
+// applyOrientation returns img transformed so that it displays upright for
+// the given EXIF orientation (2-8); orientations 5-8 swap width and height
+func applyOrientation(img image.Image, orientation int) image.Image {
+	src := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Needs 90 clockwise rotation
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Needs 90 counter-clockwise rotation
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testRed  = color.RGBA{R: 255, A: 255}
+	testBlue = color.RGBA{B: 255, A: 255}
+)
+
+// orientationTestJPEG encodes a 32x16 image whose left half is red and right
+// half is blue, with an EXIF APP1 segment carrying the given orientation
+func orientationTestJPEG(t *testing.T, orientation uint16, order binary.ByteOrder) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			c := testRed
+			if x >= 16 {
+				c = testBlue
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8) // IFD0 offset
+	order.PutUint16(tiff[8:], 1) // One entry
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1) // Count
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	encoded := buf.Bytes()
+	result := append([]byte{}, encoded[:2]...)
+	result = append(result, segment...)
+	return append(result, encoded[2:]...)
+}
+
+func assertColorNear(t *testing.T, want color.RGBA, got color.Color) {
+	t.Helper()
+	r, g, b, _ := got.RGBA()
+	near := func(a uint8, b uint32) bool {
+		diff := int(a) - int(b>>8)
+		return diff > -60 && diff < 60
+	}
+	assert.True(t, near(want.R, r) && near(want.G, g) && near(want.B, b), "want %v, got %v", want, got)
+}
+
+func TestReadJPEGOrientation(t *testing.T) {
+	assert.Equal(t, 6, readJPEGOrientation(orientationTestJPEG(t, 6, binary.BigEndian)))
+	assert.Equal(t, 8, readJPEGOrientation(orientationTestJPEG(t, 8, binary.LittleEndian)))
+	assert.Equal(t, 0, readJPEGOrientation([]byte("not a jpeg")))
+}
+
+func TestNormalizeImageOrientation_RotatesClockwise(t *testing.T) {
+	input := orientationTestJPEG(t, 6, binary.BigEndian)
+
+	output, changed, err := NormalizeImageOrientation(input)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// The output carries no orientation, so no further rotation is needed
+	assert.Equal(t, 0, readJPEGOrientation(output))
+	again, changedAgain, err := NormalizeImageOrientation(output)
+	require.NoError(t, err)
+	assert.False(t, changedAgain)
+	assert.Equal(t, output, again)
+
+	img, err := jpeg.Decode(bytes.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, 16, img.Bounds().Dx())
+	assert.Equal(t, 32, img.Bounds().Dy())
+
+	// Rotating 90 clockwise moves the red left half to the top
+	assertColorNear(t, testRed, img.At(8, 4))
+	assertColorNear(t, testBlue, img.At(8, 28))
+}
+
+func TestNormalizeImageOrientation_UprightUnchanged(t *testing.T) {
+	input := orientationTestJPEG(t, 1, binary.BigEndian)
+
+	output, changed, err := NormalizeImageOrientation(input)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, input, output)
+}
+
+func TestApplyOrientation(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, testRed)
+	src.SetRGBA(1, 0, testBlue)
+
+	tests := []struct {
+		orientation int
+		wantW       int
+		wantH       int
+		redAt       image.Point
+	}{
+		{orientation: 2, wantW: 2, wantH: 1, redAt: image.Pt(1, 0)},
+		{orientation: 3, wantW: 2, wantH: 1, redAt: image.Pt(1, 0)},
+		{orientation: 4, wantW: 2, wantH: 1, redAt: image.Pt(0, 0)},
+		{orientation: 5, wantW: 1, wantH: 2, redAt: image.Pt(0, 0)},
+		{orientation: 6, wantW: 1, wantH: 2, redAt: image.Pt(0, 0)},
+		{orientation: 7, wantW: 1, wantH: 2, redAt: image.Pt(0, 1)},
+		{orientation: 8, wantW: 1, wantH: 2, redAt: image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		out := applyOrientation(src, tt.orientation).(*image.RGBA)
+		assert.Equal(t, tt.wantW, out.Bounds().Dx(), "orientation %d width", tt.orientation)
+		assert.Equal(t, tt.wantH, out.Bounds().Dy(), "orientation %d height", tt.orientation)
+		assert.Equal(t, testRed, out.RGBAAt(tt.redAt.X, tt.redAt.Y), "orientation %d", tt.orientation)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// initialBattleRating is the Elo rating every battle photo starts from
+const initialBattleRating = 1200
+
+// ErrBattleChanged is returned when a vote lands while ratings are recomputed
+var ErrBattleChanged = errors.New("battle changed during recompute")
+
+// RatingRecompute reports a RecomputeRatings run. Skipped counts votes for
+// photos no longer in the battle; Changed counts photos whose standing moved.
+type RatingRecompute struct {
+	Votes   int           `json:"votes"`
+	Skipped int           `json:"skipped"`
+	Changed int           `json:"changed"`
+	Photos  []BattlePhoto `json:"photos"`
+}
+
+// RecomputeRatings resets the owner's battle photos to the starting rating and
+// replays the vote history in order, writing back the resulting ratings and
+// counts. The pre-vote snapshots in the history are rewritten to match, so
+// undo keeps working. Fails with ErrBattleChanged if a vote arrives meanwhile.
+func (s *PhotoService) RecomputeRatings(ctx context.Context, uid, sessionID string) (*RatingRecompute, error) {
+	sessionData, err := s.ownedBattle(ctx, uid, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	lastVoteID := getStringField(sessionData, "lastVoteId")
+
+	historyPath := voteHistoryPath(sessionID)
+	docs, err := s.repo.ListOrdered(ctx, historyPath, []interfaces.Ordering{{Field: "createdAt", Direction: firestore.Asc}}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vote history: %w", err)
+	}
+	votes := orderVoteHistory(docs, lastVoteID)
+
+	stored, _ := parseBattlePhotos(sessionData["photos"])
+	photos := make([]BattlePhoto, len(stored))
+	index := make(map[string]int, len(stored))
+	for i, photo := range stored {
+		photo.restore(PhotoVoteSnapshot{Rating: initialBattleRating})
+		photos[i] = photo
+		index[photo.ID] = i
+	}
+
+	result := &RatingRecompute{}
+	snapshots := map[string]map[string]interface{}{}
+	for _, vote := range votes {
+		winnerIdx, winnerOK := index[getStringField(vote, "winnerId")]
+		loserIdx, loserOK := index[getStringField(vote, "loserId")]
+		if !winnerOK || !loserOK || winnerIdx == loserIdx {
+			result.Skipped++
+			continue
+		}
+		result.Votes++
+		winner, loser := &photos[winnerIdx], &photos[loserIdx]
+
+		winnerBefore, loserBefore := newPhotoVoteSnapshot(*winner), newPhotoVoteSnapshot(*loser)
+		if !snapshotMatches(vote["winnerBefore"], winnerBefore) || !snapshotMatches(vote["loserBefore"], loserBefore) {
+			snapshots[getStringField(vote, "id")] = map[string]interface{}{
+				"winnerBefore": winnerBefore.toMap(),
+				"loserBefore":  loserBefore.toMap(),
+			}
+		}
+
+		winner.Rating, loser.Rating = eloRatings(winner.Rating, loser.Rating)
+		winner.Wins++
+		winner.TotalVotes++
+		loser.Losses++
+		loser.TotalVotes++
+	}
+	for i := range photos {
+		if newPhotoVoteSnapshot(photos[i]) != newPhotoVoteSnapshot(stored[i]) {
+			result.Changed++
+		}
+	}
+	result.Photos = photos
+
+	sessionPath := fmt.Sprintf("photoBattles/%s", sessionID)
+	err = s.repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		current, err := tx.Get(sessionPath)
+		if err != nil {
+			return fmt.Errorf("session not found: %w", err)
+		}
+		if getStringField(current, "lastVoteId") != lastVoteID {
+			return ErrBattleChanged
+		}
+		return tx.Set(sessionPath, map[string]interface{}{
+			"photos":    battlePhotosToMaps(photos),
+			"updatedAt": time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for voteID, snapshot := range snapshots {
+		if err := s.repo.Update(ctx, fmt.Sprintf("%s/%s", historyPath, voteID), snapshot); err != nil {
+			return nil, fmt.Errorf("failed to update vote %s: %w", voteID, err)
+		}
+	}
+
+	s.logger.Info("Photo ratings recomputed",
+		zap.String("sessionId", sessionID),
+		zap.Int("votes", result.Votes),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("changed", result.Changed),
+	)
+	return result, nil
+}
+
+// orderVoteHistory puts votes in the order they were cast. Votes are chained
+// through previousVoteId back from the session's lastVoteId, which orders
+// votes cast within the same instant; votes from before the chain was
+// recorded come first, in createdAt order as listed.
+func orderVoteHistory(docs []map[string]interface{}, lastVoteID string) []map[string]interface{} {
+	byID := make(map[string]map[string]interface{}, len(docs))
+	for _, doc := range docs {
+		byID[getStringField(doc, "id")] = doc
+	}
+
+	var chain []map[string]interface{}
+	chained := map[string]bool{}
+	for id := lastVoteID; id != "" && !chained[id]; {
+		doc, ok := byID[id]
+		if !ok {
+			break
+		}
+		chained[id] = true
+		chain = append(chain, doc)
+		id = getStringField(doc, "previousVoteId")
+	}
+
+	ordered := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		if !chained[getStringField(doc, "id")] {
+			ordered = append(ordered, doc)
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		ordered = append(ordered, chain[i])
+	}
+	return ordered
+}
+
+// snapshotMatches reports whether a stored pre-vote snapshot equals want
+func snapshotMatches(raw interface{}, want PhotoVoteSnapshot) bool {
+	snapshot := parsePhotoVoteSnapshot(raw)
+	return snapshot != nil && *snapshot == want
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// MaxMinVotesPerPhoto caps a battle's minVotesPerPhoto setting
+const MaxMinVotesPerPhoto = 100
+
+// ErrInvalidBattleSettings is returned for battle settings out of range
+var ErrInvalidBattleSettings = errors.New("invalid battle settings")
+
+// BattleSettings are the owner-controlled options of a battle session.
+// MinVotesPerPhoto is the number of votes every photo needs before the
+// results are settled; 0 means results are settled from the start.
+type BattleSettings struct {
+	MinVotesPerPhoto int `json:"minVotesPerPhoto"`
+}
+
+// BattleResultPhoto is a photo's standing in a battle's results
+type BattleResultPhoto struct {
+	BattlePhoto
+	Rank        int `json:"rank"`
+	VotesNeeded int `json:"votesNeeded"`
+}
+
+// BattleResults ranks a battle's photos by rating. Settled is set once every
+// photo has MinVotesPerPhoto votes.
+type BattleResults struct {
+	Photos           []BattleResultPhoto `json:"photos"`
+	MinVotesPerPhoto int                 `json:"minVotesPerPhoto"`
+	Settled          bool                `json:"settled"`
+	VotesNeeded      int                 `json:"votesNeeded"`
+}
+
+// GetResults returns the owner's battle photos ranked by rating, with how
+// many more votes each needs before the results are settled
+func (s *PhotoService) GetResults(ctx context.Context, uid, sessionID string) (*BattleResults, error) {
+	sessionData, err := s.ownedBattle(ctx, uid, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, _ := parseBattlePhotos(sessionData["photos"])
+	sort.SliceStable(photos, func(i, j int) bool {
+		if photos[i].Rating != photos[j].Rating {
+			return photos[i].Rating > photos[j].Rating
+		}
+		return photos[i].TotalVotes > photos[j].TotalVotes
+	})
+
+	minVotes := battleMinVotes(sessionData)
+	results := &BattleResults{
+		Photos:           make([]BattleResultPhoto, len(photos)),
+		MinVotesPerPhoto: minVotes,
+	}
+	for i, photo := range photos {
+		needed := max(0, minVotes-photo.TotalVotes)
+		results.Photos[i] = BattleResultPhoto{BattlePhoto: photo, Rank: i + 1, VotesNeeded: needed}
+		results.VotesNeeded += needed
+	}
+	results.Settled = results.VotesNeeded == 0
+	return results, nil
+}
+
+// UpdateSettings changes the owner's battle settings
+func (s *PhotoService) UpdateSettings(ctx context.Context, uid, sessionID string, settings BattleSettings) (*BattleSettings, error) {
+	if settings.MinVotesPerPhoto < 0 || settings.MinVotesPerPhoto > MaxMinVotesPerPhoto {
+		return nil, fmt.Errorf("%w: minVotesPerPhoto must be between 0 and %d", ErrInvalidBattleSettings, MaxMinVotesPerPhoto)
+	}
+	if _, err := s.ownedBattle(ctx, uid, sessionID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, fmt.Sprintf("photoBattles/%s", sessionID), map[string]interface{}{
+		"minVotesPerPhoto": int64(settings.MinVotesPerPhoto),
+		"updatedAt":        time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update battle settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// choosePairForCoverage pairs the photos still short of minVotes until each
+// has reached it: the least-voted photo against the next least-voted one, or
+// against the closest-rated photo once it is the only one left. ok is false
+// when every photo has enough votes.
+func choosePairForCoverage(photos []BattlePhoto, minVotes int) (BattlePhoto, BattlePhoto, bool) {
+	var underVoted []BattlePhoto
+	for _, photo := range photos {
+		if photo.TotalVotes < minVotes {
+			underVoted = append(underVoted, photo)
+		}
+	}
+	if len(underVoted) == 0 || len(photos) < 2 {
+		return BattlePhoto{}, BattlePhoto{}, false
+	}
+
+	// Shuffle first so ties on votes are broken at random
+	rand.Shuffle(len(underVoted), func(i, j int) {
+		underVoted[i], underVoted[j] = underVoted[j], underVoted[i]
+	})
+	sort.SliceStable(underVoted, func(i, j int) bool {
+		return underVoted[i].TotalVotes < underVoted[j].TotalVotes
+	})
+	anchor := underVoted[0]
+
+	var opponent BattlePhoto
+	if len(underVoted) > 1 {
+		opponent = underVoted[1]
+	} else {
+		closest := math.MaxInt
+		for _, photo := range photos {
+			diff := int(math.Abs(float64(photo.Rating - anchor.Rating)))
+			if photo.ID != anchor.ID && diff < closest {
+				opponent, closest = photo, diff
+			}
+		}
+	}
+
+	// #nosec G404 -- non-cryptographic random is fine for UI photo ordering
+	if rand.Float64() > 0.5 {
+		return anchor, opponent, true
+	}
+	return opponent, anchor, true
+}
+
+// battleMinVotes reads a session's minVotesPerPhoto, treating a missing or
+// invalid value as no threshold
+func battleMinVotes(sessionData map[string]interface{}) int {
+	minVotes, ok := toFloat(sessionData["minVotesPerPhoto"])
+	if !ok || minVotes < 0 {
+		return 0
+	}
+	return int(math.Min(minVotes, MaxMinVotesPerPhoto))
+}
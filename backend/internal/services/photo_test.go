@@ -4,11 +4,14 @@ import (
 	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/testutil"
 )
 
 func TestCalculateRatingDeviation(t *testing.T) {
@@ -255,6 +258,11 @@ func TestAssertUserOwnsPath(t *testing.T) {
 		{"invalid - not images prefix", "user123", "other/photos/user123/file.jpg", true},
 		{"invalid - too short", "user123", "images/a", true},
 		{"invalid - incomplete", "user123", "images/photos", true},
+		{"valid attachment path", "user123", "users/user123/attachments/receipt.pdf", false},
+		{"invalid - other user's attachment", "user123", "users/user456/attachments/receipt.pdf", true},
+		{"invalid - attachment dir only", "user123", "users/user123/attachments", true},
+		{"invalid - other users dir", "user123", "users/user123/statements/file.csv", true},
+		{"invalid - traversal", "user123", "users/user123/attachments/../../user456/attachments/x.pdf", true},
 	}
 
 	for _, tt := range tests {
@@ -382,10 +390,247 @@ func TestPhotoService_SubmitVote_InvalidSession(t *testing.T) {
 	ctx := context.Background()
 
 	err := service.SubmitVote(ctx, "nonexistent", "photo1", "photo2", "voter1")
-	// Should handle gracefully (session not found returns nil data)
+	// Missing session surfaces the repository not-found error
 	assert.Error(t, err)
 }
 
+func duplicatePhotoBattle() map[string]interface{} {
+	return map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "photo1", "rating": int64(1200), "wins": int64(1), "totalVotes": int64(1)},
+			map[string]interface{}{"id": "photo2", "rating": int64(1200)},
+			map[string]interface{}{"id": "photo1", "rating": int64(1250), "wins": int64(3), "totalVotes": int64(3)},
+			map[string]interface{}{"rating": int64(1200)},
+		},
+	}
+}
+
+func TestParseBattlePhotos_MergesDuplicates(t *testing.T) {
+	photos, duplicates := parseBattlePhotos(duplicatePhotoBattle()["photos"])
+
+	assert.Equal(t, 1, duplicates)
+	require.Len(t, photos, 2)
+	assert.Equal(t, "photo1", photos[0].ID)
+	assert.Equal(t, 1250, photos[0].Rating, "the most-voted copy is kept")
+	assert.Equal(t, 3, photos[0].TotalVotes)
+	assert.Equal(t, "photo2", photos[1].ID)
+}
+
+func TestPhotoService_SubmitVote_DuplicatePhotos(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	mockRepo.AddDocument("photoBattles/dupes", duplicatePhotoBattle())
+
+	require.NoError(t, service.SubmitVote(ctx, "dupes", "photo1", "photo2", "voter1"))
+
+	photos := mockRepo.Documents["photoBattles/dupes"]["photos"].([]interface{})
+	require.Len(t, photos, 2, "the vote writes back one entry per photo")
+	winner := photos[0].(map[string]interface{})
+	assert.Equal(t, "photo1", winner["id"])
+	assert.Equal(t, int64(4), winner["totalVotes"])
+	assert.Equal(t, int64(4), winner["wins"])
+}
+
+func TestPhotoService_SubmitVote_InvalidPhotos(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	mockRepo.AddDocument("photoBattles/dupes", duplicatePhotoBattle())
+
+	err := service.SubmitVote(ctx, "dupes", "photo1", "photo9", "voter1")
+	assert.ErrorIs(t, err, ErrPhotoNotInBattle)
+	assert.Contains(t, err.Error(), "photo9")
+
+	err = service.SubmitVote(ctx, "dupes", "photo1", "photo1", "voter1")
+	assert.ErrorIs(t, err, ErrInvalidVote)
+}
+
+func TestPhotoService_GetNextPair_DuplicatePhotosOnly(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	mockRepo.AddDocument("photoBattles/same", map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "photo1"},
+			map[string]interface{}{"id": "photo1"},
+		},
+	})
+
+	_, _, err := service.GetNextPair(context.Background(), "same")
+	assert.ErrorContains(t, err, "need at least two photos")
+}
+
+func TestPhotoService_RepairBattles(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	mockRepo.AddDocument("photoBattles/dupes", duplicatePhotoBattle())
+	mockRepo.AddDocument("photoBattles/clean", map[string]interface{}{
+		"ownerId": "user123",
+		"photos":  []interface{}{map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "b"}},
+	})
+	other := duplicatePhotoBattle()
+	other["ownerId"] = "someone-else"
+	mockRepo.AddDocument("photoBattles/other", other)
+
+	result, err := service.RepairBattles(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, &BattleRepairResult{Sessions: 2, Repaired: 1, DuplicatesRemoved: 1}, result)
+	assert.Len(t, mockRepo.Documents["photoBattles/dupes"]["photos"], 2)
+	assert.Len(t, mockRepo.Documents["photoBattles/other"]["photos"], 4, "other owners' battles are left alone")
+
+	// A second run has nothing left to repair
+	result, err = service.RepairBattles(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Repaired)
+}
+
+func TestChoosePairForCoverage(t *testing.T) {
+	photos := []BattlePhoto{
+		{ID: "new", Rating: 1200, TotalVotes: 0},
+		{ID: "low", Rating: 1300, TotalVotes: 1},
+		{ID: "a", Rating: 1210, TotalVotes: 12},
+		{ID: "b", Rating: 1400, TotalVotes: 12},
+	}
+
+	// The two least-voted photos are paired while both are short
+	for i := 0; i < 20; i++ {
+		left, right, ok := choosePairForCoverage(photos, 3)
+		require.True(t, ok)
+		assert.ElementsMatch(t, []string{"new", "low"}, []string{left.ID, right.ID})
+	}
+
+	// A lone under-voted photo meets the closest-rated one
+	photos[1].TotalVotes = 3
+	for i := 0; i < 20; i++ {
+		left, right, ok := choosePairForCoverage(photos, 3)
+		require.True(t, ok)
+		assert.ElementsMatch(t, []string{"new", "a"}, []string{left.ID, right.ID})
+	}
+
+	// Once every photo has the votes, ranking pairing takes over
+	photos[0].TotalVotes = 3
+	_, _, ok := choosePairForCoverage(photos, 3)
+	assert.False(t, ok)
+	_, _, ok = choosePairForCoverage(photos, 0)
+	assert.False(t, ok)
+}
+
+func TestPhotoService_GetNextPair_FavorsUnderVotedPhotos(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	photos := []interface{}{map[string]interface{}{"id": "fresh", "totalVotes": int64(2)}}
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5"} {
+		photos = append(photos, map[string]interface{}{"id": id, "totalVotes": int64(20), "wins": int64(10), "losses": int64(10)})
+	}
+	mockRepo.AddDocument("photoBattles/threshold", map[string]interface{}{
+		"ownerId":          "user123",
+		"minVotesPerPhoto": int64(10),
+		"photos":           photos,
+	})
+
+	for i := 0; i < 20; i++ {
+		left, right, err := service.GetNextPair(context.Background(), "threshold")
+		require.NoError(t, err)
+		assert.Contains(t, []string{left.ID, right.ID}, "fresh", "every pair includes the under-voted photo")
+	}
+}
+
+func TestPhotoService_GetResults(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	mockRepo.AddDocument("photoBattles/b1", map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "low", "rating": int64(1150), "totalVotes": int64(4)},
+			map[string]interface{}{"id": "top", "rating": int64(1260), "totalVotes": int64(6)},
+		},
+	})
+
+	results, err := service.GetResults(ctx, "user123", "b1")
+	require.NoError(t, err)
+	assert.True(t, results.Settled, "no threshold means settled")
+	require.Len(t, results.Photos, 2)
+	assert.Equal(t, "top", results.Photos[0].ID)
+	assert.Equal(t, 1, results.Photos[0].Rank)
+
+	_, err = service.UpdateSettings(ctx, "user123", "b1", BattleSettings{MinVotesPerPhoto: 5})
+	require.NoError(t, err)
+	results, err = service.GetResults(ctx, "user123", "b1")
+	require.NoError(t, err)
+	assert.False(t, results.Settled)
+	assert.Equal(t, 5, results.MinVotesPerPhoto)
+	assert.Equal(t, 0, results.Photos[0].VotesNeeded)
+	assert.Equal(t, 1, results.Photos[1].VotesNeeded)
+	assert.Equal(t, 1, results.VotesNeeded)
+
+	_, err = service.UpdateSettings(ctx, "user123", "b1", BattleSettings{MinVotesPerPhoto: -1})
+	assert.ErrorIs(t, err, ErrInvalidBattleSettings)
+	_, err = service.GetResults(ctx, "someone-else", "b1")
+	assert.ErrorIs(t, err, ErrNotPhotoBattleOwner)
+}
+
+func TestPhotoService_RecomputeRatings_ReproducesIncrementalRatings(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	service := NewPhotoService(mockRepo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	mockRepo.AddDocument("photoBattles/b1", map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "a"},
+			map[string]interface{}{"id": "b"},
+			map[string]interface{}{"id": "c"},
+		},
+	})
+	for _, vote := range [][2]string{{"a", "b"}, {"a", "c"}, {"c", "b"}, {"b", "a"}, {"a", "c"}, {"c", "b"}} {
+		require.NoError(t, service.SubmitVote(ctx, "b1", vote[0], vote[1], "voter"))
+	}
+	incremental, _ := parseBattlePhotos(mockRepo.Documents["photoBattles/b1"]["photos"])
+
+	// Replaying the history from the stored ratings changes nothing
+	result, err := service.RecomputeRatings(ctx, "user123", "b1")
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Votes)
+	assert.Equal(t, 0, result.Changed)
+	assert.Equal(t, incremental, result.Photos)
+
+	// Ratings that drifted from the history are rebuilt from it
+	mockRepo.Documents["photoBattles/b1"]["photos"] = battlePhotosToMaps([]BattlePhoto{
+		{ID: "a", Rating: 1500, TotalVotes: 1},
+		{ID: "b", Rating: 900},
+		{ID: "c", Rating: 1200, Wins: 9, TotalVotes: 9},
+	})
+	result, err = service.RecomputeRatings(ctx, "user123", "b1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Changed)
+	recomputed, _ := parseBattlePhotos(mockRepo.Documents["photoBattles/b1"]["photos"])
+	assert.Equal(t, incremental, recomputed)
+
+	_, err = service.RecomputeRatings(ctx, "someone-else", "b1")
+	assert.ErrorIs(t, err, ErrNotPhotoBattleOwner)
+}
+
+func TestOrderVoteHistory_FollowsChain(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Listed by createdAt; v2 and v3 share a timestamp and are listed out of order
+	docs := []map[string]interface{}{
+		{"id": "legacy", "createdAt": at.Add(-time.Hour)},
+		{"id": "v1", "createdAt": at},
+		{"id": "v3", "createdAt": at.Add(time.Second), "previousVoteId": "v2"},
+		{"id": "v2", "createdAt": at.Add(time.Second), "previousVoteId": "v1"},
+	}
+
+	ordered := orderVoteHistory(docs, "v3")
+	ids := make([]string, len(ordered))
+	for i, doc := range ordered {
+		ids[i] = doc["id"].(string)
+	}
+	assert.Equal(t, []string{"legacy", "v1", "v2", "v3"}, ids)
+}
+
 func TestPhotoService_GetNextPair_NotEnoughPhotos(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
@@ -500,10 +745,262 @@ func TestEloRatingCalculation(t *testing.T) {
 	}
 }
 
-func TestIncrementValue(t *testing.T) {
-	result := incrementValue(5)
-	assert.Equal(t, 5, result)
+func newVoteTestBattle(repo *mocks.MockRepository, sessionID string) {
+	repo.AddDocument("photoBattles/"+sessionID, map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "photo1", "rating": float64(1200), "wins": float64(5), "losses": float64(3), "totalVotes": float64(8)},
+			map[string]interface{}{"id": "photo2", "rating": float64(1350), "wins": float64(4), "losses": float64(4), "totalVotes": float64(8)},
+			map[string]interface{}{"id": "photo3", "rating": float64(1100)},
+		},
+	})
+}
+
+func battlePhotos(t *testing.T, repo *mocks.MockRepository, sessionID string) map[string]BattlePhoto {
+	session, err := repo.Get(context.Background(), "photoBattles/"+sessionID)
+	require.NoError(t, err)
+	photos := make(map[string]BattlePhoto)
+	for _, raw := range session["photos"].([]interface{}) {
+		photo := parseBattlePhoto(raw.(map[string]interface{}))
+		photos[photo.ID] = photo
+	}
+	return photos
+}
+
+func TestPhotoService_SubmitVote_RecordsPreVoteSnapshot(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	newVoteTestBattle(repo, "session1")
+
+	require.NoError(t, service.SubmitVote(ctx, "session1", "photo1", "photo2", "voter1"))
+
+	history, err := repo.List(ctx, "photoBattles/session1/history", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	vote := parseVoteHistory(getStringField(history[0], "id"), history[0])
+	assert.NotEmpty(t, vote.ID)
+	assert.Equal(t, "voter1", vote.VoterID)
+	assert.Equal(t, &PhotoVoteSnapshot{Rating: 1200, Wins: 5, Losses: 3, TotalVotes: 8}, vote.WinnerBefore)
+	assert.Equal(t, &PhotoVoteSnapshot{Rating: 1350, Wins: 4, Losses: 4, TotalVotes: 8}, vote.LoserBefore)
+
+	// The snapshot plus the Elo update gives the stored post-vote standing
+	winnerAfter, loserAfter := eloRatings(1200, 1350)
+	photos := battlePhotos(t, repo, "session1")
+	assert.Equal(t, winnerAfter, photos["photo1"].Rating)
+	assert.Equal(t, loserAfter, photos["photo2"].Rating)
+}
+
+func TestParseVoteHistory_WithoutSnapshots(t *testing.T) {
+	vote := parseVoteHistory("legacy", map[string]interface{}{
+		"winnerId":  "photo1",
+		"loserId":   "photo2",
+		"createdAt": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(t, "legacy", vote.ID)
+	assert.Equal(t, "photo1", vote.WinnerID)
+	assert.Nil(t, vote.WinnerBefore)
+	assert.Nil(t, vote.LoserBefore)
+	assert.Nil(t, parsePhotoVoteSnapshot(map[string]interface{}{"wins": int64(3)}))
+}
+
+func TestPhotoService_UndoLastVote_RestoresPreVoteState(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	newVoteTestBattle(repo, "session1")
+	original := battlePhotos(t, repo, "session1")
+
+	// An upset win and then a second vote involving the winner
+	require.NoError(t, service.SubmitVote(ctx, "session1", "photo1", "photo2", "voter1"))
+	afterFirst := battlePhotos(t, repo, "session1")
+	time.Sleep(time.Millisecond)
+	require.NoError(t, service.SubmitVote(ctx, "session1", "photo3", "photo1", "voter2"))
+
+	history, err := repo.List(ctx, "photoBattles/session1/history", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	undone, err := service.UndoLastVote(ctx, "user123", "session1")
+	require.NoError(t, err)
+	assert.Equal(t, "photo3", undone.WinnerID)
+	assert.Equal(t, "photo1", undone.LoserID)
+	assert.Equal(t, afterFirst, battlePhotos(t, repo, "session1"))
+
+	undone, err = service.UndoLastVote(ctx, "user123", "session1")
+	require.NoError(t, err)
+	assert.Equal(t, "photo1", undone.WinnerID)
+	assert.Equal(t, original, battlePhotos(t, repo, "session1"))
+
+	history, err = repo.List(ctx, "photoBattles/session1/history", 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	_, err = service.UndoLastVote(ctx, "user123", "session1")
+	assert.ErrorIs(t, err, ErrNoVoteToUndo)
+}
+
+func TestPhotoService_UndoLastVote_RestoresSnapshotValues(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	newVoteTestBattle(repo, "session1")
+
+	// The snapshot is restored as recorded, whatever the photos' standing now
+	repo.AddDocument("photoBattles/session1/history/vote-1", map[string]interface{}{
+		"id":           "vote-1",
+		"winnerId":     "photo1",
+		"loserId":      "photo2",
+		"winnerBefore": map[string]interface{}{"rating": int64(1000), "wins": int64(4), "losses": int64(3), "totalVotes": int64(7)},
+		"loserBefore":  map[string]interface{}{"rating": int64(1400), "wins": int64(4), "losses": int64(3), "totalVotes": int64(7)},
+		"createdAt":    time.Now(),
+	})
+
+	_, err := service.UndoLastVote(context.Background(), "user123", "session1")
+	require.NoError(t, err)
+
+	photos := battlePhotos(t, repo, "session1")
+	assert.Equal(t, 1000, photos["photo1"].Rating)
+	assert.Equal(t, 4, photos["photo1"].Wins)
+	assert.Equal(t, 7, photos["photo1"].TotalVotes)
+	assert.Equal(t, 1400, photos["photo2"].Rating)
+	assert.Equal(t, 3, photos["photo2"].Losses)
+	assert.Equal(t, 7, photos["photo2"].TotalVotes)
+	assert.Equal(t, 1100, photos["photo3"].Rating)
+}
+
+func TestPhotoService_UndoLastVote_RevertsLibraryStats(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	repo.AddDocument("photoBattles/session1", map[string]interface{}{
+		"ownerId": "user123",
+		"photos": []interface{}{
+			map[string]interface{}{"id": "photo1", "libraryId": "lib1", "rating": float64(1200)},
+			map[string]interface{}{"id": "photo2", "libraryId": "lib2", "rating": float64(1200)},
+		},
+	})
+	repo.AddDocument("users/user123/photoLibrary/lib1", map[string]interface{}{
+		"stats":      map[string]interface{}{"totalVotes": int64(5), "yesVotes": int64(3), "sessionCount": int64(1)},
+		"sessionIds": []interface{}{"older"},
+	})
+	repo.AddDocument("users/user123/photoLibrary/lib2", map[string]interface{}{
+		"stats":      map[string]interface{}{"totalVotes": int64(2), "yesVotes": int64(1), "sessionCount": int64(1)},
+		"sessionIds": []interface{}{"session1"},
+	})
+
+	require.NoError(t, service.SubmitVote(ctx, "session1", "photo1", "photo2", "voter1"))
+	require.NoError(t, service.SubmitVote(ctx, "session1", "photo1", "photo2", "voter1"))
+
+	lib1, err := repo.Get(ctx, "users/user123/photoLibrary/lib1")
+	require.NoError(t, err)
+	stats := lib1["stats"].(map[string]interface{})
+	assert.Equal(t, int64(7), stats["totalVotes"])
+	assert.Equal(t, int64(5), stats["yesVotes"])
+	assert.Equal(t, int64(2), stats["sessionCount"])
+	assert.Equal(t, []string{"older", "session1"}, lib1["sessionIds"])
+
+	// Undoing the second vote keeps the session, which the first vote added
+	_, err = service.UndoLastVote(ctx, "user123", "session1")
+	require.NoError(t, err)
+	lib1, _ = repo.Get(ctx, "users/user123/photoLibrary/lib1")
+	stats = lib1["stats"].(map[string]interface{})
+	assert.Equal(t, int64(6), stats["totalVotes"])
+	assert.Equal(t, int64(4), stats["yesVotes"])
+	assert.Equal(t, int64(2), stats["sessionCount"])
+
+	_, err = service.UndoLastVote(ctx, "user123", "session1")
+	require.NoError(t, err)
+	lib1, _ = repo.Get(ctx, "users/user123/photoLibrary/lib1")
+	stats = lib1["stats"].(map[string]interface{})
+	assert.Equal(t, int64(5), stats["totalVotes"])
+	assert.Equal(t, int64(3), stats["yesVotes"])
+	assert.Equal(t, int64(1), stats["sessionCount"])
+	assert.Equal(t, []string{"older"}, lib1["sessionIds"])
+
+	// The loser's library already counted the session before these votes
+	lib2, _ := repo.Get(ctx, "users/user123/photoLibrary/lib2")
+	stats = lib2["stats"].(map[string]interface{})
+	assert.Equal(t, int64(2), stats["totalVotes"])
+	assert.Equal(t, int64(1), stats["yesVotes"])
+	assert.Equal(t, int64(1), stats["sessionCount"])
+	assert.Equal(t, []string{"session1"}, lib2["sessionIds"])
+}
+
+func TestPhotoService_UndoLastVote_Errors(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	newVoteTestBattle(repo, "session1")
+
+	_, err := service.UndoLastVote(ctx, "someone-else", "session1")
+	assert.ErrorIs(t, err, ErrNotPhotoBattleOwner)
+
+	_, err = service.UndoLastVote(ctx, "user123", "missing")
+	assert.Error(t, err)
+
+	// Votes recorded before pre-vote snapshots were stored cannot be undone
+	repo.AddDocument("photoBattles/session1/history/legacy", map[string]interface{}{
+		"winnerId":  "photo1",
+		"loserId":   "photo2",
+		"createdAt": time.Now(),
+	})
+	_, err = service.UndoLastVote(ctx, "user123", "session1")
+	assert.ErrorIs(t, err, ErrVoteNotUndoable)
+	assert.Equal(t, 1200, battlePhotos(t, repo, "session1")["photo1"].Rating)
+}
+
+func TestPhotoService_ListVoteHistory_Pages(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.Client_ = testutil.NewOfflineClient(t)
+	service := NewPhotoService(repo, nil, "test-bucket", zap.NewNop())
+	ctx := context.Background()
+	newVoteTestBattle(repo, "session1")
+
+	votedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"v1", "v2", "v3"} {
+		repo.AddDocument("photoBattles/session1/history/"+id, map[string]interface{}{
+			"winnerId":  "photo1",
+			"loserId":   "photo2",
+			"createdAt": votedAt.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// Other battles' votes are not listed
+	repo.AddDocument("photoBattles/session2/history/other", map[string]interface{}{
+		"winnerId":  "photo1",
+		"loserId":   "photo2",
+		"createdAt": votedAt.Add(time.Hour),
+	})
+
+	voteIDs := func(page *VoteHistoryPage) []string {
+		ids := make([]string, len(page.Votes))
+		for i, vote := range page.Votes {
+			ids[i] = vote.ID
+		}
+		return ids
+	}
+
+	first, err := service.ListVoteHistory(ctx, "user123", "session1", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v2"}, voteIDs(first))
+	assert.Equal(t, "v2", first.NextCursor)
+	assert.True(t, votedAt.Add(2*time.Minute).Equal(first.Votes[0].CreatedAt))
+
+	second, err := service.ListVoteHistory(ctx, "user123", "session1", 2, first.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, voteIDs(second))
+	assert.Empty(t, second.NextCursor)
+
+	_, err = service.ListVoteHistory(ctx, "user123", "session1", 2, "missing")
+	assert.ErrorIs(t, err, ErrInvalidVoteHistoryCursor)
+}
+
+func TestEloRatings_MatchesVote(t *testing.T) {
+	winner, loser := eloRatings(1200, 1200)
+	assert.Equal(t, 1216, winner)
+	assert.Equal(t, 1184, loser)
 
-	result = incrementValue(1)
-	assert.Equal(t, 1, result)
+	// Upsets move ratings further than expected wins
+	upsetWinner, _ := eloRatings(1000, 1400)
+	expectedWinner, _ := eloRatings(1400, 1000)
+	assert.Greater(t, upsetWinner-1000, expectedWinner-1400)
 }
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// Thumbnail backfill job statuses
+const (
+	ThumbnailJobRunning   = "running"
+	ThumbnailJobCompleted = "completed"
+)
+
+var (
+	// ErrThumbnailUnavailable is returned when a photo has no readable
+	// original to make a thumbnail from
+	ErrThumbnailUnavailable = errors.New("cannot generate a thumbnail for this photo")
+	// ErrThumbnailJobRunning is returned when the user already has a backfill running
+	ErrThumbnailJobRunning = errors.New("a thumbnail job is already running")
+	// ErrThumbnailJobNotFound is returned for unknown job IDs
+	ErrThumbnailJobNotFound = errors.New("thumbnail job not found")
+)
+
+// ThumbnailJob is the progress of a thumbnail backfill, stored at
+// users/{uid}/thumbnailJobs/{jobId}
+type ThumbnailJob struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Total       int        `json:"total"`
+	Regenerated int        `json:"regenerated"`
+	Failed      int        `json:"failed"`
+	StartedAt   time.Time  `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (j *ThumbnailJob) toMap() map[string]interface{} {
+	data := map[string]interface{}{
+		"id":          j.ID,
+		"status":      j.Status,
+		"total":       j.Total,
+		"regenerated": j.Regenerated,
+		"failed":      j.Failed,
+		"startedAt":   j.StartedAt,
+	}
+	if j.FinishedAt != nil {
+		data["finishedAt"] = *j.FinishedAt
+	}
+	return data
+}
+
+// thumbnailJobs tracks the running backfill of each user
+type thumbnailJobs struct {
+	mu      sync.Mutex
+	running map[string]string // jobID keyed by uid
+	wg      sync.WaitGroup
+}
+
+// RegenerateThumbnail re-reads a library photo's original from storage, renders
+// a new thumbnail with the current resizer and replaces the stored one,
+// creating it for photos that never had a thumbnail. Returns the updated
+// thumbnail fields.
+func (s *PhotoUploadService) RegenerateThumbnail(ctx context.Context, uid, photoID string) (map[string]interface{}, error) {
+	libraryPath := photoLibraryPath(uid, photoID)
+	photo, err := s.repo.Get(ctx, libraryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	storagePath := getStringField(photo, "storagePath")
+	if storagePath == "" {
+		return nil, fmt.Errorf("%w: no original is stored", ErrThumbnailUnavailable)
+	}
+	if err := userOwnsPath(uid, storagePath); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThumbnailUnavailable, err)
+	}
+	thumbnailPath := getStringField(photo, "thumbnailPath")
+	if thumbnailPath == "" || userOwnsPath(uid, thumbnailPath) != nil {
+		thumbnailPath = defaultThumbnailPath(uid, photoID, storagePath)
+	}
+
+	original, err := s.storage.ReadObject(ctx, storagePath)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: the original is missing from storage", ErrThumbnailUnavailable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, err := photoThumbnail(original)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThumbnailUnavailable, err)
+	}
+	if err := s.storage.WriteObject(ctx, thumbnailPath, "image/jpeg", thumbnail); err != nil {
+		return nil, err
+	}
+	thumbnailURL, _, err := s.storage.GetSignedURL(ctx, uid, thumbnailPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"thumbnailPath":          thumbnailPath,
+		"thumbnailUrl":           thumbnailURL,
+		"thumbnailRegeneratedAt": time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.repo.UpdateDocument(ctx, libraryPath, updates); err != nil {
+		return nil, fmt.Errorf("failed to update photo: %w", err)
+	}
+	updates["id"] = photoID
+	return updates, nil
+}
+
+// defaultThumbnailPath is where the thumbnail of an original belongs: the
+// same file name under images/thumb, as uploads and the storage trigger use
+func defaultThumbnailPath(uid, photoID, storagePath string) string {
+	if prefix := fmt.Sprintf("images/original/%s/", uid); strings.HasPrefix(storagePath, prefix) {
+		return fmt.Sprintf("images/thumb/%s/%s", uid, path.Base(storagePath))
+	}
+	return fmt.Sprintf("images/thumb/%s/%s.jpg", uid, photoID)
+}
+
+// StartThumbnailBackfill regenerates, in a background job, the thumbnails of
+// the user's library photos that have a stored original but no thumbnailPath.
+// Returns the job's initial status. A user can only have one job running.
+func (s *PhotoUploadService) StartThumbnailBackfill(ctx context.Context, uid string) (*ThumbnailJob, error) {
+	s.thumbnails.mu.Lock()
+	if jobID, ok := s.thumbnails.running[uid]; ok {
+		s.thumbnails.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrThumbnailJobRunning, jobID)
+	}
+	job := &ThumbnailJob{
+		ID:        uuid.New().String(),
+		Status:    ThumbnailJobRunning,
+		StartedAt: time.Now(),
+	}
+	s.thumbnails.running[uid] = job.ID
+	s.thumbnails.mu.Unlock()
+
+	photos, err := s.repo.List(ctx, fmt.Sprintf("users/%s/photoLibrary", uid), 0)
+	if err != nil {
+		s.finishThumbnailBackfill(uid)
+		return nil, fmt.Errorf("failed to list photos: %w", err)
+	}
+	var ids []string
+	for _, photo := range photos {
+		id := getStringField(photo, "id")
+		if id != "" && getStringField(photo, "thumbnailPath") == "" && getStringField(photo, "storagePath") != "" {
+			ids = append(ids, id)
+		}
+	}
+	job.Total = len(ids)
+
+	if err := s.repo.SetDocument(ctx, thumbnailJobPath(uid, job.ID), job.toMap()); err != nil {
+		s.finishThumbnailBackfill(uid)
+		return nil, fmt.Errorf("failed to save thumbnail job: %w", err)
+	}
+
+	s.logger.Info("Thumbnail backfill started",
+		zap.String("uid", uid),
+		zap.String("jobId", job.ID),
+		zap.Int("photos", job.Total),
+	)
+
+	snapshot := *job
+	s.thumbnails.wg.Add(1)
+	go s.runThumbnailBackfill(context.WithoutCancel(ctx), uid, job, ids)
+	return &snapshot, nil
+}
+
+// GetThumbnailJob returns a thumbnail backfill's stored progress
+func (s *PhotoUploadService) GetThumbnailJob(ctx context.Context, uid, jobID string) (map[string]interface{}, error) {
+	data, err := s.repo.Get(ctx, thumbnailJobPath(uid, jobID))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return nil, ErrThumbnailJobNotFound
+		}
+		return nil, fmt.Errorf("failed to load thumbnail job: %w", err)
+	}
+	return data, nil
+}
+
+// WaitThumbnailJobs blocks until running thumbnail backfills have finished
+func (s *PhotoUploadService) WaitThumbnailJobs() {
+	s.thumbnails.wg.Wait()
+}
+
+// runThumbnailBackfill regenerates each photo in turn, writing progress after each
+func (s *PhotoUploadService) runThumbnailBackfill(ctx context.Context, uid string, job *ThumbnailJob, ids []string) {
+	defer s.thumbnails.wg.Done()
+	defer s.finishThumbnailBackfill(uid)
+
+	statusPath := thumbnailJobPath(uid, job.ID)
+	for _, id := range ids {
+		if _, err := s.RegenerateThumbnail(ctx, uid, id); err != nil {
+			job.Failed++
+			s.logger.Warn("Failed to regenerate thumbnail",
+				zap.String("uid", uid),
+				zap.String("jobId", job.ID),
+				zap.String("photoId", id),
+				zap.Error(err),
+			)
+		} else {
+			job.Regenerated++
+		}
+		if err := s.repo.SetDocument(ctx, statusPath, job.toMap()); err != nil {
+			s.logger.Warn("Failed to save thumbnail job progress", zap.Error(err))
+		}
+	}
+
+	finishedAt := time.Now()
+	job.Status = ThumbnailJobCompleted
+	job.FinishedAt = &finishedAt
+	if err := s.repo.SetDocument(ctx, statusPath, job.toMap()); err != nil {
+		s.logger.Error("Failed to save thumbnail job status", zap.Error(err))
+	}
+
+	s.logger.Info("Thumbnail backfill finished",
+		zap.String("uid", uid),
+		zap.String("jobId", job.ID),
+		zap.Int("regenerated", job.Regenerated),
+		zap.Int("failed", job.Failed),
+	)
+}
+
+func (s *PhotoUploadService) finishThumbnailBackfill(uid string) {
+	s.thumbnails.mu.Lock()
+	defer s.thumbnails.mu.Unlock()
+	delete(s.thumbnails.running, uid)
+}
+
+func thumbnailJobPath(uid, jobID string) string {
+	return fmt.Sprintf("users/%s/thumbnailJobs/%s", uid, jobID)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestPhotoUploadService_RegenerateThumbnail(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	storage := newFakePhotoStorage()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), storage, config.UploadConfig{})
+	ctx := context.Background()
+
+	storage.objects["images/original/user1/p1.png"] = testPhoto(t, "png", 1200, 600, color.RGBA{G: 200, A: 255})
+	storage.objects["images/thumb/user1/p1.png"] = []byte("stale thumbnail")
+	repo.AddDocument("users/user1/photoLibrary/p1", map[string]interface{}{
+		"id":            "p1",
+		"storagePath":   "images/original/user1/p1.png",
+		"thumbnailPath": "images/thumb/user1/p1.png",
+	})
+
+	photo, err := svc.RegenerateThumbnail(ctx, "user1", "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "images/thumb/user1/p1.png", photo["thumbnailPath"])
+
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(storage.objects["images/thumb/user1/p1.png"]))
+	require.NoError(t, err, "the stale thumbnail should be replaced with a JPEG")
+	assert.Equal(t, photoThumbnailSize, thumb.Width)
+	assert.Equal(t, photoThumbnailSize/2, thumb.Height)
+
+	_, err = svc.RegenerateThumbnail(ctx, "user1", "missing")
+	assert.True(t, errors.Is(err, interfaces.ErrNotFound))
+
+	repo.AddDocument("users/user1/photoLibrary/gone", map[string]interface{}{
+		"id": "gone", "storagePath": "images/original/user1/gone.jpg",
+	})
+	_, err = svc.RegenerateThumbnail(ctx, "user1", "gone")
+	assert.True(t, errors.Is(err, ErrThumbnailUnavailable))
+
+	repo.AddDocument("users/user1/photoLibrary/other", map[string]interface{}{
+		"id": "other", "storagePath": "images/original/user2/other.jpg",
+	})
+	_, err = svc.RegenerateThumbnail(ctx, "user1", "other")
+	assert.True(t, errors.Is(err, ErrThumbnailUnavailable), "another user's original must not be read")
+}
+
+func TestPhotoUploadService_ThumbnailBackfill(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	storage := newFakePhotoStorage()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), storage, config.UploadConfig{})
+	ctx := context.Background()
+
+	storage.objects["images/original/user1/a.jpg"] = testPhoto(t, "jpeg", 50, 40, color.White)
+	repo.AddDocument("users/user1/photoLibrary/a", map[string]interface{}{
+		"id": "a", "storagePath": "images/original/user1/a.jpg",
+	})
+	repo.AddDocument("users/user1/photoLibrary/b", map[string]interface{}{
+		"id": "b", "storagePath": "images/original/user1/b.jpg", // original was lost
+	})
+	repo.AddDocument("users/user1/photoLibrary/c", map[string]interface{}{
+		"id": "c", "storagePath": "images/original/user1/c.jpg", "thumbnailPath": "images/thumb/user1/c.jpg",
+	})
+
+	job, err := svc.StartThumbnailBackfill(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, ThumbnailJobRunning, job.Status)
+	assert.Equal(t, 2, job.Total)
+	svc.WaitThumbnailJobs()
+
+	photo, err := repo.Get(ctx, "users/user1/photoLibrary/a")
+	require.NoError(t, err)
+	assert.Equal(t, "images/thumb/user1/a.jpg", photo["thumbnailPath"])
+	assert.Equal(t, "https://storage.test/images/thumb/user1/a.jpg", photo["thumbnailUrl"])
+	_, err = jpeg.DecodeConfig(bytes.NewReader(storage.objects["images/thumb/user1/a.jpg"]))
+	require.NoError(t, err)
+
+	status, err := svc.GetThumbnailJob(ctx, "user1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ThumbnailJobCompleted, status["status"])
+	assert.Equal(t, 1, status["regenerated"])
+	assert.Equal(t, 1, status["failed"])
+
+	// The finished job no longer blocks a new one
+	_, err = svc.StartThumbnailBackfill(ctx, "user1")
+	require.NoError(t, err)
+	svc.WaitThumbnailJobs()
+
+	_, err = svc.GetThumbnailJob(ctx, "user1", "missing")
+	assert.True(t, errors.Is(err, ErrThumbnailJobNotFound))
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder for uploads
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// MaxBatchPhotoFiles caps the number of files in a batch upload
+	MaxBatchPhotoFiles = 20
+	// MaxBatchPhotoBytes caps the total size of a batch upload request
+	MaxBatchPhotoBytes = 100 << 20
+	// MaxMediaExportPhotos caps the originals in one media export page
+	MaxMediaExportPhotos = 200
+	// MaxMediaExportBytes caps the original bytes in one media export page,
+	// which is built in memory
+	MaxMediaExportBytes = 100 << 20
+	// defaultMaxPhotoFileSize applies when upload.max_file_size is not set
+	defaultMaxPhotoFileSize = 10 << 20
+	// photoThumbnailSize bounds both sides of a thumbnail, like the storage trigger's
+	photoThumbnailSize = 360
+	// photoThumbnailQuality is the JPEG quality of thumbnails
+	photoThumbnailQuality = 70
+)
+
+// ErrInvalidPhotoUpload is wrapped into validation errors for photo uploads
+var ErrInvalidPhotoUpload = errors.New("invalid photo upload")
+
+// uploadablePhotoTypes maps the image types thumbnails can be made from to
+// their file extension
+var uploadablePhotoTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+}
+
+// PhotoStorage reads, writes, removes and signs uploaded photo objects
+type PhotoStorage interface {
+	ReadObject(ctx context.Context, path string) ([]byte, error)
+	WriteObject(ctx context.Context, path, contentType string, data []byte) error
+	DeleteObject(ctx context.Context, path string) error
+	GetSignedURL(ctx context.Context, userID, path string, expiresAt *time.Time) (string, time.Time, error)
+}
+
+// PhotoUploadFile is one file of a batch upload
+type PhotoUploadFile struct {
+	Name string
+	Data []byte
+}
+
+// PhotoUploadResult is the outcome of uploading a single file. Existing is
+// set when the same photo was already in the library, e.g. on a retry.
+type PhotoUploadResult struct {
+	Name     string                 `json:"name"`
+	Success  bool                   `json:"success"`
+	ID       string                 `json:"id,omitempty"`
+	Existing bool                   `json:"existing,omitempty"`
+	Photo    map[string]interface{} `json:"photo,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// PhotoUploadService stores uploaded photos, their thumbnails and their
+// photoLibrary documents. Photos are keyed by a hash of their content, so
+// uploading the same file again returns the existing photo.
+type PhotoUploadService struct {
+	repo         interfaces.Repository
+	logger       *zap.Logger
+	storage      PhotoStorage
+	maxFileSize  int64
+	allowedTypes map[string]bool
+	thumbnails   thumbnailJobs
+	// exportMaxPhotos and exportMaxBytes bound a media export page
+	exportMaxPhotos int
+	exportMaxBytes  int64
+}
+
+// NewPhotoUploadService creates a new photo upload service. Accepted types are
+// the JPEG and PNG entries of cfg's "images" types, or both when none are set.
+func NewPhotoUploadService(repo interfaces.Repository, logger *zap.Logger, storage PhotoStorage, cfg config.UploadConfig) *PhotoUploadService {
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = defaultMaxPhotoFileSize
+	}
+	allowed := make(map[string]bool, len(uploadablePhotoTypes))
+	for _, contentType := range cfg.AllowedTypes["images"] {
+		if _, ok := uploadablePhotoTypes[contentType]; ok {
+			allowed[contentType] = true
+		}
+	}
+	if len(allowed) == 0 {
+		for contentType := range uploadablePhotoTypes {
+			allowed[contentType] = true
+		}
+	}
+	return &PhotoUploadService{
+		repo:         repo,
+		logger:       logger,
+		storage:      storage,
+		maxFileSize:  cfg.MaxFileSize,
+		allowedTypes: allowed,
+		thumbnails:   thumbnailJobs{running: make(map[string]string)},
+
+		exportMaxPhotos: MaxMediaExportPhotos,
+		exportMaxBytes:  MaxMediaExportBytes,
+	}
+}
+
+// UploadBatch uploads each file independently, returning one result per file
+// in order so the client can retry only the failures
+func (s *PhotoUploadService) UploadBatch(ctx context.Context, uid string, files []PhotoUploadFile) ([]PhotoUploadResult, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: at least one file is required", ErrInvalidPhotoUpload)
+	}
+	if len(files) > MaxBatchPhotoFiles {
+		return nil, fmt.Errorf("%w: maximum is %d files", ErrInvalidPhotoUpload, MaxBatchPhotoFiles)
+	}
+
+	results := make([]PhotoUploadResult, len(files))
+	uploaded := 0
+	for i, file := range files {
+		result := PhotoUploadResult{Name: file.Name}
+		photo, existing, err := s.uploadPhoto(ctx, uid, file)
+		switch {
+		case err == nil:
+			result.Success = true
+			result.ID, _ = photo["id"].(string)
+			result.Existing = existing
+			result.Photo = photo
+			uploaded++
+		case errors.Is(err, ErrInvalidPhotoUpload):
+			result.Error = err.Error()
+		default:
+			s.logger.Error("Failed to upload photo",
+				zap.String("uid", uid),
+				zap.String("name", file.Name),
+				zap.Error(err),
+			)
+			result.Error = "failed to store photo"
+		}
+		results[i] = result
+	}
+
+	s.logger.Info("Photo batch uploaded",
+		zap.String("uid", uid),
+		zap.Int("files", len(files)),
+		zap.Int("uploaded", uploaded),
+	)
+	return results, nil
+}
+
+// uploadPhoto stores one photo and its thumbnail and creates its library
+// document, returning the document and whether it already existed
+func (s *PhotoUploadService) uploadPhoto(ctx context.Context, uid string, file PhotoUploadFile) (map[string]interface{}, bool, error) {
+	if len(file.Data) == 0 {
+		return nil, false, fmt.Errorf("%w: file is empty", ErrInvalidPhotoUpload)
+	}
+	if int64(len(file.Data)) > s.maxFileSize {
+		return nil, false, fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidPhotoUpload, s.maxFileSize)
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(file.Data))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: file is not a supported image", ErrInvalidPhotoUpload)
+	}
+	contentType := "image/" + format
+	extension, ok := uploadablePhotoTypes[contentType]
+	if !ok || !s.allowedTypes[contentType] {
+		return nil, false, fmt.Errorf("%w: %s images are not accepted", ErrInvalidPhotoUpload, format)
+	}
+
+	sum := sha256.Sum256(file.Data)
+	id := hex.EncodeToString(sum[:16])
+	libraryPath := photoLibraryPath(uid, id)
+	existing, err := s.repo.Get(ctx, libraryPath)
+	if err == nil {
+		existing["id"] = id
+		return existing, true, nil
+	}
+	if !errors.Is(err, interfaces.ErrNotFound) {
+		return nil, false, fmt.Errorf("failed to load photo: %w", err)
+	}
+
+	thumbnail, err := photoThumbnail(file.Data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidPhotoUpload, err)
+	}
+
+	// The thumbnail keeps the original's file name, as the storage trigger
+	// that thumbnails new originals expects, and is written first so the
+	// trigger finds it and leaves it alone
+	storagePath := fmt.Sprintf("images/original/%s/%s.%s", uid, id, extension)
+	thumbnailPath := fmt.Sprintf("images/thumb/%s/%s.%s", uid, id, extension)
+	if err := s.storage.WriteObject(ctx, thumbnailPath, "image/jpeg", thumbnail); err != nil {
+		return nil, false, err
+	}
+	if err := s.storage.WriteObject(ctx, storagePath, contentType, file.Data); err != nil {
+		s.removeObjects(ctx, thumbnailPath)
+		return nil, false, err
+	}
+
+	url, _, err := s.storage.GetSignedURL(ctx, uid, storagePath, nil)
+	if err != nil {
+		s.removeObjects(ctx, storagePath, thumbnailPath)
+		return nil, false, err
+	}
+	thumbnailURL, _, err := s.storage.GetSignedURL(ctx, uid, thumbnailPath, nil)
+	if err != nil {
+		s.removeObjects(ctx, storagePath, thumbnailPath)
+		return nil, false, err
+	}
+
+	photo := map[string]interface{}{
+		"id":            id,
+		"ownerId":       uid,
+		"name":          file.Name,
+		"url":           url,
+		"storagePath":   storagePath,
+		"thumbnailUrl":  thumbnailURL,
+		"thumbnailPath": thumbnailPath,
+		"contentType":   contentType,
+		"size":          int64(len(file.Data)),
+		"createdAt":     time.Now().UTC().Format(time.RFC3339),
+		"stats": map[string]interface{}{
+			"yesVotes":     int64(0),
+			"totalVotes":   int64(0),
+			"sessionCount": int64(0),
+		},
+	}
+	for key, value := range photoMediaMetadata(file.Data, time.Now()) {
+		photo[key] = value
+	}
+	if err := s.repo.CreateDocument(ctx, libraryPath, photo); err != nil {
+		s.removeObjects(ctx, storagePath, thumbnailPath)
+		return nil, false, fmt.Errorf("failed to create photo: %w", err)
+	}
+	return photo, false, nil
+}
+
+// removeObjects deletes the objects of a failed upload. Failures are only
+// logged; the upload has already failed.
+func (s *PhotoUploadService) removeObjects(ctx context.Context, paths ...string) {
+	for _, path := range paths {
+		if err := s.storage.DeleteObject(ctx, path); err != nil {
+			s.logger.Warn("Failed to remove object of failed upload", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+// photoThumbnail renders an upright JPEG of an image that fits within
+// photoThumbnailSize on both sides. Transparent areas become white.
+func photoThumbnail(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if orientation := readJPEGOrientation(data); orientation > 1 && orientation <= 8 {
+		img = applyOrientation(img, orientation)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, fitWithin(src, photoThumbnailSize), &jpeg.Options{Quality: photoThumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitWithin scales src down so neither side exceeds size, averaging the
+// source pixels behind each output pixel. Smaller images are returned as is.
+func fitWithin(src *image.RGBA, size int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= size && h <= size {
+		return src
+	}
+	scale := float64(size) / float64(max(w, h))
+	dstW := max(1, int(math.Round(float64(w)*scale)))
+	dstH := max(1, int(math.Round(float64(h)*scale)))
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*h/dstH, (y+1)*h/dstH
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*w/dstW, (x+1)*w/dstW
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r, g, b, a = r+int(c.R), g+int(c.G), b+int(c.B), a+int(c.A)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+// fakePhotoStorage keeps written objects in memory; writes to paths
+// containing failOn fail
+type fakePhotoStorage struct {
+	objects map[string][]byte
+	failOn  string
+}
+
+func newFakePhotoStorage() *fakePhotoStorage {
+	return &fakePhotoStorage{objects: map[string][]byte{}}
+}
+
+func (f *fakePhotoStorage) ReadObject(ctx context.Context, path string) ([]byte, error) {
+	data, ok := f.objects[path]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return data, nil
+}
+
+func (f *fakePhotoStorage) WriteObject(ctx context.Context, path, contentType string, data []byte) error {
+	if f.failOn != "" && strings.Contains(path, f.failOn) {
+		return errors.New("storage unavailable")
+	}
+	f.objects[path] = data
+	return nil
+}
+
+func (f *fakePhotoStorage) DeleteObject(ctx context.Context, path string) error {
+	delete(f.objects, path)
+	return nil
+}
+
+func (f *fakePhotoStorage) GetSignedURL(ctx context.Context, userID, path string, expiresAt *time.Time) (string, time.Time, error) {
+	return "https://storage.test/" + path, time.Now().Add(time.Hour), nil
+}
+
+// testPhoto encodes a solid w x h image as "jpeg" or "png"
+func testPhoto(t *testing.T, format string, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if format == "png" {
+		require.NoError(t, png.Encode(&buf, img))
+	} else {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	}
+	return buf.Bytes()
+}
+
+func TestPhotoUploadService_UploadBatch_PartialSuccess(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	storage := newFakePhotoStorage()
+	svc := NewPhotoUploadService(repo, zap.NewNop(), storage, config.UploadConfig{MaxFileSize: 64 << 10})
+
+	files := []PhotoUploadFile{
+		{Name: "beach.jpg", Data: testPhoto(t, "jpeg", 800, 400, color.RGBA{R: 200, A: 255})},
+		{Name: "notes.txt", Data: []byte("not an image")},
+		{Name: "logo.png", Data: testPhoto(t, "png", 100, 100, color.RGBA{B: 200, A: 255})},
+		{Name: "empty.jpg"},
+		{Name: "huge.png", Data: append(testPhoto(t, "png", 10, 10, color.Black), make([]byte, 64<<10)...)},
+	}
+
+	results, err := svc.UploadBatch(context.Background(), "user1", files)
+	require.NoError(t, err)
+	require.Len(t, results, len(files))
+
+	for i, want := range []bool{true, false, true, false, false} {
+		assert.Equal(t, files[i].Name, results[i].Name)
+		assert.Equal(t, want, results[i].Success, "%s: %s", files[i].Name, results[i].Error)
+	}
+	assert.Contains(t, results[1].Error, "not a supported image")
+	assert.Contains(t, results[3].Error, "empty")
+	assert.Contains(t, results[4].Error, "larger than")
+
+	beach := results[0]
+	storagePath := "images/original/user1/" + beach.ID + ".jpg"
+	thumbnailPath := "images/thumb/user1/" + beach.ID + ".jpg"
+	assert.Equal(t, files[0].Data, storage.objects[storagePath])
+	thumbnail, format, err := image.Decode(bytes.NewReader(storage.objects[thumbnailPath]))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Pt(360, 180), thumbnail.Bounds().Size())
+
+	doc, err := repo.Get(context.Background(), "users/user1/photoLibrary/"+beach.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", doc["ownerId"])
+	assert.Equal(t, storagePath, doc["storagePath"])
+	assert.Equal(t, thumbnailPath, doc["thumbnailPath"])
+	assert.Equal(t, "https://storage.test/"+storagePath, doc["url"])
+
+	// Small images keep their size; the thumbnail is still a JPEG
+	logo := results[2]
+	thumbnail, format, err = image.Decode(bytes.NewReader(storage.objects["images/thumb/user1/"+logo.ID+".png"]))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Pt(100, 100), thumbnail.Bounds().Size())
+	assert.Len(t, storage.objects, 4)
+}
+
+func TestPhotoUploadService_UploadBatch_RetryIsIdempotent(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	storage := newFakePhotoStorage()
+	storage.failOn = "images/original/"
+	svc := NewPhotoUploadService(repo, zap.NewNop(), storage, config.UploadConfig{})
+	photo := PhotoUploadFile{Name: "a.jpg", Data: testPhoto(t, "jpeg", 20, 20, color.White)}
+
+	results, err := svc.UploadBatch(context.Background(), "user1", []PhotoUploadFile{photo})
+	require.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "failed to store photo", results[0].Error)
+	assert.Empty(t, storage.objects, "thumbnail of the failed upload should be removed")
+
+	storage.failOn = ""
+	results, err = svc.UploadBatch(context.Background(), "user1", []PhotoUploadFile{photo, photo})
+	require.NoError(t, err)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[0].Existing)
+	assert.True(t, results[1].Success)
+	assert.True(t, results[1].Existing)
+	assert.Equal(t, results[0].ID, results[1].ID)
+	assert.Len(t, repo.GetCollectionDocuments("users/user1/photoLibrary"), 1)
+}
+
+func TestPhotoUploadService_UploadBatch_Limits(t *testing.T) {
+	svc := NewPhotoUploadService(mocks.NewMockRepository(), zap.NewNop(), newFakePhotoStorage(), config.UploadConfig{})
+
+	_, err := svc.UploadBatch(context.Background(), "user1", nil)
+	assert.True(t, errors.Is(err, ErrInvalidPhotoUpload))
+
+	_, err = svc.UploadBatch(context.Background(), "user1", make([]PhotoUploadFile, MaxBatchPhotoFiles+1))
+	assert.True(t, errors.Is(err, ErrInvalidPhotoUpload))
+}
+
+func TestPhotoUploadService_AllowedTypes(t *testing.T) {
+	svc := NewPhotoUploadService(mocks.NewMockRepository(), zap.NewNop(), newFakePhotoStorage(), config.UploadConfig{
+		AllowedTypes: map[string][]string{"images": {"image/jpeg", "image/webp"}},
+	})
+
+	results, err := svc.UploadBatch(context.Background(), "user1", []PhotoUploadFile{
+		{Name: "a.png", Data: testPhoto(t, "png", 10, 10, color.White)},
+		{Name: "a.jpg", Data: testPhoto(t, "jpeg", 10, 10, color.White)},
+	})
+	require.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "png images are not accepted")
+	assert.True(t, results[1].Success)
+}
+
+func TestPhotoThumbnail_AppliesOrientation(t *testing.T) {
+	// 32x16 with EXIF orientation 6 displays as 16x32
+	thumbnail, err := photoThumbnail(orientationTestJPEG(t, 6, binary.BigEndian))
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(16, 32), img.Bounds().Size())
+}
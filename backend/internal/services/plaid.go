@@ -15,17 +15,20 @@ import (
 
 // PlaidService handles Plaid banking operations
 type PlaidService struct {
-	plaidClient *clients.PlaidClient
-	repo        interfaces.Repository
-	logger      *zap.Logger
+	plaidClient     *clients.PlaidClient
+	repo            interfaces.Repository
+	logger          *zap.Logger
+	merchantAliases *MerchantAliasService
 }
 
-// NewPlaidService creates a new Plaid service
-func NewPlaidService(plaidClient *clients.PlaidClient, repo interfaces.Repository, logger *zap.Logger) *PlaidService {
+// NewPlaidService creates a new Plaid service. merchantAliases may be nil, in
+// which case merchant names are only normalized.
+func NewPlaidService(plaidClient *clients.PlaidClient, repo interfaces.Repository, logger *zap.Logger, merchantAliases *MerchantAliasService) *PlaidService {
 	return &PlaidService{
-		plaidClient: plaidClient,
-		repo:        repo,
-		logger:      logger,
+		plaidClient:     plaidClient,
+		repo:            repo,
+		logger:          logger,
+		merchantAliases: merchantAliases,
 	}
 }
 
@@ -214,9 +217,9 @@ func (s *PlaidService) ExchangePublicToken(ctx context.Context, req ExchangePubl
 		})
 	}
 
-	// Trigger initial transaction sync (async)
+	// Trigger initial transaction sync (async); detached from the request deadline
+	syncCtx := context.WithoutCancel(ctx)
 	go func() {
-		syncCtx := context.Background()
 		if _, err := s.syncTransactions(syncCtx, itemID, accessToken, req.UID, nil); err != nil {
 			s.logger.Error("Failed to sync transactions after exchange", zap.Error(err))
 		}
@@ -281,9 +284,9 @@ func (s *PlaidService) MarkRelinking(ctx context.Context, req MarkRelinkingReque
 		cursor = &cursorVal
 	}
 
-	// Trigger sync (async)
+	// Trigger sync (async); detached from the request deadline
+	syncCtx := context.WithoutCancel(ctx)
 	go func() {
-		syncCtx := context.Background()
 		if _, err := s.syncTransactions(syncCtx, req.ItemID, accessToken, req.UID, cursor); err != nil {
 			s.logger.Error("Failed to sync transactions after relinking", zap.Error(err))
 		}
@@ -369,9 +372,9 @@ func (s *PlaidService) HandleWebhook(ctx context.Context, webhookType string, we
 func (s *PlaidService) handleTransactionsWebhook(ctx context.Context, code string, itemID string) error {
 	switch code {
 	case "SYNC_UPDATES_AVAILABLE":
-		// Trigger sync in background
+		// Trigger sync in background; detached from the request deadline
+		syncCtx := context.WithoutCancel(ctx)
 		go func() {
-			syncCtx := context.Background()
 			itemPath := fmt.Sprintf("plaidItems/%s", itemID)
 			itemData, err := s.repo.Get(syncCtx, itemPath)
 			if err != nil {
@@ -434,6 +437,7 @@ func (s *PlaidService) syncTransactions(ctx context.Context, itemID string, acce
 
 	var currentCursor *string = cursor
 	hasMore := true
+	merchants := s.merchantAliases.Matcher(ctx, uid)
 
 	for hasMore {
 		result, err := s.plaidClient.SyncTransactions(ctx, clients.SyncTransactionsRequest{
@@ -448,19 +452,16 @@ func (s *PlaidService) syncTransactions(ctx context.Context, itemID string, acce
 		for _, txn := range result.Added {
 			txnPath := fmt.Sprintf("transactions/%s", txn.TransactionID)
 			txnData := map[string]interface{}{
-				"uid":                uid,
-				"itemId":             itemID,
-				"accountId":          txn.AccountID,
-				"plaidTransactionId": txn.TransactionID,
-				"postedAt":           txn.Date,
-				"authorizedAt":       txn.AuthorizedDate,
-				"pending":            txn.Pending,
-				"amount":             txn.Amount,
-				"isoCurrency":        txn.IsoCurrency,
-				"merchant": map[string]interface{}{
-					"name":       txn.Name,
-					"normalized": normalizeMerchantName(txn.Name),
-				},
+				"uid":                 uid,
+				"itemId":              itemID,
+				"accountId":           txn.AccountID,
+				"plaidTransactionId":  txn.TransactionID,
+				"postedAt":            txn.Date,
+				"authorizedAt":        txn.AuthorizedDate,
+				"pending":             txn.Pending,
+				"amount":              txn.Amount,
+				"isoCurrency":         txn.IsoCurrency,
+				"merchant":            merchantFields(merchants, txn.Name),
 				"originalDescription": txn.Name,
 				"category_base":       txn.Category,
 				"category_premium":    txn.PersonalFinanceCategory,
@@ -473,8 +474,7 @@ func (s *PlaidService) syncTransactions(ctx context.Context, itemID string, acce
 			}
 
 			if txn.MerchantName != nil {
-				txnData["merchant"].(map[string]interface{})["name"] = *txn.MerchantName
-				txnData["merchant"].(map[string]interface{})["normalized"] = normalizeMerchantName(*txn.MerchantName)
+				txnData["merchant"] = merchantFields(merchants, *txn.MerchantName)
 			}
 
 			if err := s.repo.SetDocument(ctx, txnPath, txnData); err != nil {
@@ -588,6 +588,21 @@ func (s *PlaidService) decryptAccessToken(encryptedToken string) string {
 	return strings.TrimPrefix(encryptedToken, "encrypted:")
 }
 
+// merchantFields builds a transaction's merchant map. When an alias rule
+// matches, canonical holds the shared name and normalized is derived from it,
+// so variants of one merchant group together.
+func merchantFields(merchants *MerchantMatcher, name string) map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":       name,
+		"normalized": normalizeMerchantName(name),
+	}
+	if canonical, ok := merchants.Canonicalize(name); ok {
+		fields["canonical"] = canonical
+		fields["normalized"] = normalizeMerchantName(canonical)
+	}
+	return fields
+}
+
 // normalizeMerchantName normalizes a merchant name for matching
 func normalizeMerchantName(name string) string {
 	// Convert to lowercase
@@ -46,6 +46,26 @@ func (m *MockRepositoryForPlaid) List(ctx context.Context, collectionPath string
 	return nil, nil
 }
 
+func (m *MockRepositoryForPlaid) ListOrdered(ctx context.Context, collectionPath string, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForPlaid) ListWhere(ctx context.Context, collectionPath string, filters []interfaces.Filter, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForPlaid) GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForPlaid) DeleteAll(ctx context.Context, paths []string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockRepositoryForPlaid) ListWhereOrdered(ctx context.Context, collectionPath string, filters []interfaces.Filter, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
 func (m *MockRepositoryForPlaid) GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error) {
 	return nil, nil
 }
@@ -70,6 +90,42 @@ func (m *MockRepositoryForPlaid) QueryCollection(ctx context.Context, collection
 	return nil, nil
 }
 
+func (m *MockRepositoryForPlaid) ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error {
+	return nil
+}
+
+func (m *MockRepositoryForPlaid) CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForPlaid) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx interfaces.Transaction) error) error {
+	return nil
+}
+
+func (m *MockRepositoryForPlaid) IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
+func (m *MockRepositoryForPlaid) Increment(ctx context.Context, path, field string, delta int64) error {
+	return nil
+}
+
+func (m *MockRepositoryForPlaid) ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepositoryForPlaid) ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepositoryForPlaid) CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForPlaid) Count(ctx context.Context, query firestore.Query) (int64, error) {
+	return 0, nil
+}
+
 func (m *MockRepositoryForPlaid) Collection(path string) *firestore.CollectionRef {
 	return nil
 }
@@ -89,7 +145,7 @@ func TestNewPlaidService(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
 	logger := zap.NewNop()
 
-	service := NewPlaidService(plaidClient, repo, logger)
+	service := NewPlaidService(plaidClient, repo, logger, nil)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, plaidClient, service.plaidClient)
@@ -101,7 +157,7 @@ func TestNewPlaidService_WithNilClient(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
 	logger := zap.NewNop()
 
-	service := NewPlaidService(nil, repo, logger)
+	service := NewPlaidService(nil, repo, logger, nil)
 
 	assert.NotNil(t, service)
 	assert.Nil(t, service.plaidClient)
@@ -112,7 +168,7 @@ func TestNewPlaidService_WithNilRepository(t *testing.T) {
 	plaidClient := &clients.PlaidClient{}
 	logger := zap.NewNop()
 
-	service := NewPlaidService(plaidClient, nil, logger)
+	service := NewPlaidService(plaidClient, nil, logger, nil)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, plaidClient, service.plaidClient)
@@ -123,7 +179,7 @@ func TestNewPlaidService_WithNilLogger(t *testing.T) {
 	plaidClient := &clients.PlaidClient{}
 	repo := NewMockRepositoryForPlaid()
 
-	service := NewPlaidService(plaidClient, repo, nil)
+	service := NewPlaidService(plaidClient, repo, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, plaidClient, service.plaidClient)
@@ -132,7 +188,7 @@ func TestNewPlaidService_WithNilLogger(t *testing.T) {
 }
 
 func TestNewPlaidService_AllNil(t *testing.T) {
-	service := NewPlaidService(nil, nil, nil)
+	service := NewPlaidService(nil, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Nil(t, service.plaidClient)
@@ -144,7 +200,7 @@ func TestPlaidService_Fields(t *testing.T) {
 	plaidClient := &clients.PlaidClient{}
 	repo := NewMockRepositoryForPlaid()
 	logger := zap.NewNop()
-	service := NewPlaidService(plaidClient, repo, logger)
+	service := NewPlaidService(plaidClient, repo, logger, nil)
 
 	assert.NotNil(t, service.plaidClient)
 	assert.NotNil(t, service.repo)
@@ -153,27 +209,27 @@ func TestPlaidService_Fields(t *testing.T) {
 
 func TestPlaidService_PlaidClientStorage(t *testing.T) {
 	plaidClient := &clients.PlaidClient{}
-	service := NewPlaidService(plaidClient, nil, nil)
+	service := NewPlaidService(plaidClient, nil, nil, nil)
 
 	assert.Equal(t, plaidClient, service.plaidClient)
 }
 
 func TestPlaidService_RepositoryStorage(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
-	service := NewPlaidService(nil, repo, nil)
+	service := NewPlaidService(nil, repo, nil, nil)
 
 	assert.Equal(t, repo, service.repo)
 }
 
 func TestPlaidService_LoggerStorage(t *testing.T) {
 	logger := zap.NewNop()
-	service := NewPlaidService(nil, nil, logger)
+	service := NewPlaidService(nil, nil, logger, nil)
 
 	assert.Equal(t, logger, service.logger)
 }
 
 func TestPlaidService_Constructor(t *testing.T) {
-	service := NewPlaidService(nil, nil, nil)
+	service := NewPlaidService(nil, nil, nil, nil)
 
 	assert.Nil(t, service.plaidClient)
 	assert.Nil(t, service.repo)
@@ -185,8 +241,8 @@ func TestPlaidService_MultipleInstances(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
 	logger := zap.NewNop()
 
-	service1 := NewPlaidService(plaidClient, repo, logger)
-	service2 := NewPlaidService(plaidClient, repo, logger)
+	service1 := NewPlaidService(plaidClient, repo, logger, nil)
+	service2 := NewPlaidService(plaidClient, repo, logger, nil)
 
 	assert.NotNil(t, service1)
 	assert.NotNil(t, service2)
@@ -196,7 +252,7 @@ func TestPlaidService_MultipleInstances(t *testing.T) {
 
 func TestPlaidService_WithClient(t *testing.T) {
 	plaidClient := &clients.PlaidClient{}
-	service := NewPlaidService(plaidClient, nil, nil)
+	service := NewPlaidService(plaidClient, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.plaidClient)
@@ -204,7 +260,7 @@ func TestPlaidService_WithClient(t *testing.T) {
 
 func TestPlaidService_WithRepository(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
-	service := NewPlaidService(nil, repo, nil)
+	service := NewPlaidService(nil, repo, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.repo)
@@ -212,14 +268,14 @@ func TestPlaidService_WithRepository(t *testing.T) {
 
 func TestPlaidService_WithLogger(t *testing.T) {
 	logger := zap.NewNop()
-	service := NewPlaidService(nil, nil, logger)
+	service := NewPlaidService(nil, nil, logger, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.logger)
 }
 
 func TestPlaidService_ImplementsExpectedMethods(t *testing.T) {
-	service := NewPlaidService(nil, nil, nil)
+	service := NewPlaidService(nil, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	// Service should have CreateLinkToken and other methods
@@ -230,7 +286,7 @@ func TestPlaidService_AllFields(t *testing.T) {
 	repo := NewMockRepositoryForPlaid()
 	logger := zap.NewNop()
 
-	service := NewPlaidService(plaidClient, repo, logger)
+	service := NewPlaidService(plaidClient, repo, logger, nil)
 
 	assert.NotNil(t, service.plaidClient)
 	assert.NotNil(t, service.repo)
@@ -239,28 +295,28 @@ func TestPlaidService_AllFields(t *testing.T) {
 
 func TestPlaidService_ConstructorVariations(t *testing.T) {
 	// Variation 1: all nil
-	s1 := NewPlaidService(nil, nil, nil)
+	s1 := NewPlaidService(nil, nil, nil, nil)
 	assert.Nil(t, s1.plaidClient)
 	assert.Nil(t, s1.repo)
 	assert.Nil(t, s1.logger)
 
 	// Variation 2: client only
 	client := &clients.PlaidClient{}
-	s2 := NewPlaidService(client, nil, nil)
+	s2 := NewPlaidService(client, nil, nil, nil)
 	assert.NotNil(t, s2.plaidClient)
 	assert.Nil(t, s2.repo)
 	assert.Nil(t, s2.logger)
 
 	// Variation 3: repo only
 	repo := NewMockRepositoryForPlaid()
-	s3 := NewPlaidService(nil, repo, nil)
+	s3 := NewPlaidService(nil, repo, nil, nil)
 	assert.Nil(t, s3.plaidClient)
 	assert.NotNil(t, s3.repo)
 	assert.Nil(t, s3.logger)
 
 	// Variation 4: all
 	logger := zap.NewNop()
-	s4 := NewPlaidService(client, repo, logger)
+	s4 := NewPlaidService(client, repo, logger, nil)
 	assert.NotNil(t, s4.plaidClient)
 	assert.NotNil(t, s4.repo)
 	assert.NotNil(t, s4.logger)
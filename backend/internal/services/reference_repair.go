@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+// maxReportedBrokenReferences caps the individual references listed in a repair result
+const maxReportedBrokenReferences = 500
+
+// BrokenReference is a reference to a document that does not exist
+type BrokenReference struct {
+	EntityType EntityType `json:"entityType"`
+	EntityID   string     `json:"entityId"`
+	Field      string     `json:"field"`
+	TargetID   string     `json:"targetId"`
+}
+
+// ReferenceRepairResult reports the broken references found and, unless
+// DryRun, repaired. Counts is keyed by "entityType.field".
+type ReferenceRepairResult struct {
+	DryRun           bool              `json:"dryRun"`
+	Scanned          int               `json:"scanned"`
+	BrokenCount      int               `json:"brokenCount"`
+	Counts           map[string]int    `json:"counts"`
+	RepairedEntities int               `json:"repairedEntities"`
+	References       []BrokenReference `json:"references"`
+	Truncated        bool              `json:"truncated,omitempty"`
+}
+
+// RepairReferences scans the user's entities for the references the importer
+// rewrites (importReferenceFields) and finds those pointing at documents that
+// no longer exist. Unless dryRun, single references are set to null and
+// dangling IDs are removed from reference lists.
+func (s *ImportExportService) RepairReferences(ctx context.Context, uid string, dryRun bool) (*ReferenceRepairResult, error) {
+	result := &ReferenceRepairResult{
+		DryRun:     dryRun,
+		Counts:     make(map[string]int),
+		References: []BrokenReference{},
+	}
+
+	// Load every entity type that holds or is the target of a reference
+	entities := make(map[EntityType][]map[string]interface{})
+	existing := make(map[EntityType]map[string]bool)
+	load := func(entityType EntityType) error {
+		if _, ok := entities[entityType]; ok {
+			return nil
+		}
+		docs, err := s.repo.ListWhere(ctx, exportCollections[entityType], []repository.Filter{repository.Eq("uid", uid)}, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", entityType, err)
+		}
+		entities[entityType] = docs
+		existing[entityType] = s.buildIDMap(docs)
+		return nil
+	}
+	sourceTypes := make([]EntityType, 0, len(importReferenceFields))
+	for entityType, fields := range importReferenceFields {
+		sourceTypes = append(sourceTypes, entityType)
+		if err := load(entityType); err != nil {
+			return nil, err
+		}
+		for _, ref := range fields {
+			if err := load(ref.target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(sourceTypes, func(i, j int) bool { return sourceTypes[i] < sourceTypes[j] })
+
+	for _, entityType := range sourceTypes {
+		for _, entity := range entities[entityType] {
+			result.Scanned++
+			id := s.getString(entity, "id")
+			changes := s.findBrokenReferences(entityType, entity, existing, result)
+			if dryRun || len(changes) == 0 || id == "" {
+				continue
+			}
+			changes["updatedAt"] = s.now()
+			if err := s.repo.Update(ctx, fmt.Sprintf("%s/%s", exportCollections[entityType], id), changes); err != nil {
+				return nil, fmt.Errorf("failed to repair %s %s: %w", entityType, id, err)
+			}
+			result.RepairedEntities++
+		}
+	}
+
+	s.logger.Info("Reference repair completed",
+		zap.String("uid", uid),
+		zap.Bool("dryRun", dryRun),
+		zap.Int("scanned", result.Scanned),
+		zap.Int("broken", result.BrokenCount),
+		zap.Int("repaired", result.RepairedEntities),
+	)
+	return result, nil
+}
+
+// findBrokenReferences records the entity's broken references in result and
+// returns the field updates that would repair them
+func (s *ImportExportService) findBrokenReferences(
+	entityType EntityType,
+	entity map[string]interface{},
+	existing map[EntityType]map[string]bool,
+	result *ReferenceRepairResult,
+) map[string]interface{} {
+	changes := make(map[string]interface{})
+	record := func(field, targetID string) {
+		result.BrokenCount++
+		result.Counts[fmt.Sprintf("%s.%s", entityType, field)]++
+		if len(result.References) >= maxReportedBrokenReferences {
+			result.Truncated = true
+			return
+		}
+		result.References = append(result.References, BrokenReference{
+			EntityType: entityType,
+			EntityID:   s.getString(entity, "id"),
+			Field:      field,
+			TargetID:   targetID,
+		})
+	}
+
+	for _, ref := range importReferenceFields[entityType] {
+		targets := existing[ref.target]
+		switch v := entity[ref.field].(type) {
+		case string:
+			if v != "" && !targets[v] {
+				record(ref.field, v)
+				changes[ref.field] = nil
+			}
+		case []interface{}:
+			kept := make([]interface{}, 0, len(v))
+			for _, raw := range v {
+				if targetID, ok := raw.(string); ok && !targets[targetID] {
+					record(ref.field, targetID)
+					continue
+				}
+				kept = append(kept, raw)
+			}
+			if len(kept) != len(v) {
+				changes[ref.field] = kept
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func seedBrokenReferences(repo *mocks.MockRepository) {
+	repo.AddDocument("goals/goal-1", map[string]interface{}{"id": "goal-1", "uid": "user-1"})
+	repo.AddDocument("projects/project-1", map[string]interface{}{
+		"id":      "project-1",
+		"uid":     "user-1",
+		"goalId":  "goal-1",
+		"goalIds": []interface{}{"goal-1", "goal-deleted"},
+	})
+	repo.AddDocument("tasks/task-1", map[string]interface{}{
+		"id":               "task-1",
+		"uid":              "user-1",
+		"projectId":        "project-deleted",
+		"goalId":           "goal-1",
+		"linkedThoughtIds": []interface{}{"thought-1", "thought-deleted"},
+	})
+	repo.AddDocument("thoughts/thought-1", map[string]interface{}{
+		"id":            "thought-1",
+		"uid":           "user-1",
+		"linkedTaskIds": []interface{}{"task-1"},
+	})
+	// Another user's documents are neither scanned nor valid targets
+	repo.AddDocument("projects/project-other", map[string]interface{}{
+		"id":     "project-other",
+		"uid":    "user-2",
+		"goalId": "goal-missing",
+	})
+	repo.AddDocument("tasks/task-other", map[string]interface{}{
+		"id":        "task-other",
+		"uid":       "user-1",
+		"projectId": "project-other",
+	})
+}
+
+func TestRepairReferences_DryRun(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	seedBrokenReferences(repo)
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	result, err := svc.RepairReferences(context.Background(), "user-1", true)
+	require.NoError(t, err)
+
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 4, result.Scanned)
+	assert.Equal(t, 4, result.BrokenCount)
+	assert.Equal(t, map[string]int{
+		"tasks.projectId":        2,
+		"tasks.linkedThoughtIds": 1,
+		"projects.goalIds":       1,
+	}, result.Counts)
+	assert.Len(t, result.References, 4)
+	assert.Zero(t, result.RepairedEntities)
+
+	// Nothing is written
+	assert.Equal(t, "project-deleted", repo.Documents["tasks/task-1"]["projectId"])
+	assert.Len(t, repo.Documents["projects/project-1"]["goalIds"], 2)
+}
+
+func TestRepairReferences_Repair(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	seedBrokenReferences(repo)
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+
+	result, err := svc.RepairReferences(context.Background(), "user-1", false)
+	require.NoError(t, err)
+
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 4, result.BrokenCount)
+	assert.Equal(t, 3, result.RepairedEntities)
+
+	task := repo.Documents["tasks/task-1"]
+	assert.Nil(t, task["projectId"])
+	assert.Equal(t, "goal-1", task["goalId"])
+	assert.Equal(t, []interface{}{"thought-1"}, task["linkedThoughtIds"])
+	assert.Nil(t, repo.Documents["tasks/task-other"]["projectId"])
+	assert.Equal(t, []interface{}{"goal-1"}, repo.Documents["projects/project-1"]["goalIds"])
+	assert.NotContains(t, repo.Documents["thoughts/thought-1"], "updatedAt")
+	assert.Equal(t, "goal-missing", repo.Documents["projects/project-other"]["goalId"])
+
+	// A second pass finds nothing left to repair
+	result, err = svc.RepairReferences(context.Background(), "user-1", true)
+	require.NoError(t, err)
+	assert.Zero(t, result.BrokenCount)
+	assert.Empty(t, result.Counts)
+}
+
+func TestRepairReferences_NoEntities(t *testing.T) {
+	svc := NewImportExportService(mocks.NewMockRepository(), zap.NewNop(), 0, nil, 0, 0, nil)
+
+	result, err := svc.RepairReferences(context.Background(), "user-1", false)
+	require.NoError(t, err)
+	assert.Zero(t, result.Scanned)
+	assert.Zero(t, result.BrokenCount)
+	assert.NotNil(t, result.References)
+}
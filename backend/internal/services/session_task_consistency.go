@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// actualMinutesTolerance is how far a stored actualMinutes may be from the
+// logged time before it counts as drifted; clients round to whole minutes
+const actualMinutesTolerance = 0.5
+
+// OrphanedSessionTask is a focus session entry referencing a deleted task
+type OrphanedSessionTask struct {
+	SessionID string `json:"sessionId"`
+	TaskID    string `json:"taskId"`
+	TimeSpent int    `json:"timeSpent"` // Seconds
+}
+
+// TaskTimeMismatch is a task whose stored actualMinutes disagrees with the
+// time logged for it across focus sessions
+type TaskTimeMismatch struct {
+	TaskID        string  `json:"taskId"`
+	Title         string  `json:"title"`
+	StoredMinutes float64 `json:"storedMinutes"`
+	LoggedMinutes float64 `json:"loggedMinutes"`
+}
+
+// SessionTaskConsistency reports where focus sessions and tasks disagree and,
+// when Repaired, how many tasks had actualMinutes recomputed
+type SessionTaskConsistency struct {
+	Repaired        bool                  `json:"repaired"`
+	SessionsScanned int                   `json:"sessionsScanned"`
+	TasksScanned    int                   `json:"tasksScanned"`
+	OrphanedTasks   []OrphanedSessionTask `json:"orphanedTasks"`
+	Mismatches      []TaskTimeMismatch    `json:"mismatches"`
+	RepairedTasks   int                   `json:"repairedTasks"`
+}
+
+// CheckSessionTaskConsistency finds focus session entries referencing tasks
+// that no longer exist, and tasks whose stored actualMinutes differs from the
+// timeSpent logged for them across sessions. With repair, the mismatched
+// tasks get actualMinutes recomputed from the sessions; orphaned entries are
+// only reported, as they still count towards the session's focus time.
+func (s *DashboardAnalyticsService) CheckSessionTaskConsistency(ctx context.Context, uid string, repair bool) (*SessionTaskConsistency, error) {
+	var tasks, sessions []map[string]interface{}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		tasks, err = s.repo.List(gctx, fmt.Sprintf("users/%s/tasks", uid), 0)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		sessions, err = s.repo.List(gctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := s.computeSessionTaskConsistency(tasks, sessions)
+	if repair {
+		result.Repaired = true
+		for _, mismatch := range result.Mismatches {
+			updates := map[string]interface{}{
+				"actualMinutes": mismatch.LoggedMinutes,
+				"updatedAt":     time.Now(),
+			}
+			if err := s.repo.UpdateDocument(ctx, fmt.Sprintf("users/%s/tasks/%s", uid, mismatch.TaskID), updates); err != nil {
+				return nil, fmt.Errorf("failed to repair task %s: %w", mismatch.TaskID, err)
+			}
+			result.RepairedTasks++
+		}
+	}
+
+	s.logger.Info("Session task consistency checked",
+		zap.String("uid", uid),
+		zap.Bool("repair", repair),
+		zap.Int("orphaned", len(result.OrphanedTasks)),
+		zap.Int("mismatches", len(result.Mismatches)),
+		zap.Int("repaired", result.RepairedTasks),
+	)
+	return result, nil
+}
+
+// computeSessionTaskConsistency compares session task entries with the tasks
+func (s *DashboardAnalyticsService) computeSessionTaskConsistency(tasks, sessions []map[string]interface{}) *SessionTaskConsistency {
+	result := &SessionTaskConsistency{
+		SessionsScanned: len(sessions),
+		TasksScanned:    len(tasks),
+		OrphanedTasks:   []OrphanedSessionTask{},
+		Mismatches:      []TaskTimeMismatch{},
+	}
+
+	existing := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if id := getStringField(task, "id"); id != "" {
+			existing[id] = true
+		}
+	}
+
+	secondsByTask := make(map[string]int)
+	for _, session := range sessions {
+		sessionTasks, ok := session["tasks"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, task := range sessionTasks {
+			taskMap, ok := task.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id := s.sessionTaskID(taskMap)
+			if id == "" {
+				continue
+			}
+			seconds := s.sessionTaskTime(taskMap)
+			if !existing[id] {
+				result.OrphanedTasks = append(result.OrphanedTasks, OrphanedSessionTask{
+					SessionID: getStringField(session, "id"),
+					TaskID:    id,
+					TimeSpent: seconds,
+				})
+				continue
+			}
+			secondsByTask[id] += seconds
+		}
+	}
+
+	for _, task := range tasks {
+		id := getStringField(task, "id")
+		stored, ok := toFloat(task["actualMinutes"])
+		if id == "" || !ok {
+			continue
+		}
+		logged := math.Round(float64(secondsByTask[id])/60*10) / 10
+		if math.Abs(stored-logged) <= actualMinutesTolerance {
+			continue
+		}
+		result.Mismatches = append(result.Mismatches, TaskTimeMismatch{
+			TaskID:        id,
+			Title:         getStringField(task, "title"),
+			StoredMinutes: stored,
+			LoggedMinutes: logged,
+		})
+	}
+
+	sort.Slice(result.OrphanedTasks, func(i, j int) bool {
+		a, b := result.OrphanedTasks[i], result.OrphanedTasks[j]
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		return a.TaskID < b.TaskID
+	})
+	sort.Slice(result.Mismatches, func(i, j int) bool {
+		return result.Mismatches[i].TaskID < result.Mismatches[j].TaskID
+	})
+	return result
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func seedSessionTaskDrift(repo *mocks.MockRepository) {
+	repo.AddDocument("users/user-1/tasks/in-sync", map[string]interface{}{
+		"id": "in-sync", "title": "In sync", "actualMinutes": float64(30),
+	})
+	repo.AddDocument("users/user-1/tasks/drifted", map[string]interface{}{
+		"id": "drifted", "title": "Drifted", "actualMinutes": int64(5),
+	})
+	repo.AddDocument("users/user-1/tasks/untracked", map[string]interface{}{
+		"id": "untracked", "title": "No stored minutes",
+	})
+
+	session1 := focusSessionWith(sessionTask("in-sync", 1200), sessionTask("drifted", 600), sessionTask("deleted", 900))
+	session1["id"] = "session-1"
+	repo.AddDocument("users/user-1/focusSessions/session-1", session1)
+	session2 := focusSessionWith(sessionTask("in-sync", 600), sessionTask("drifted", 90), sessionTask("untracked", 300))
+	session2["id"] = "session-2"
+	repo.AddDocument("users/user-1/focusSessions/session-2", session2)
+}
+
+func TestCheckSessionTaskConsistency_Report(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	seedSessionTaskDrift(repo)
+	svc := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+
+	result, err := svc.CheckSessionTaskConsistency(context.Background(), "user-1", false)
+	require.NoError(t, err)
+
+	assert.False(t, result.Repaired)
+	assert.Equal(t, 2, result.SessionsScanned)
+	assert.Equal(t, 3, result.TasksScanned)
+	assert.Equal(t, []OrphanedSessionTask{{SessionID: "session-1", TaskID: "deleted", TimeSpent: 900}}, result.OrphanedTasks)
+	assert.Equal(t, []TaskTimeMismatch{{TaskID: "drifted", Title: "Drifted", StoredMinutes: 5, LoggedMinutes: 11.5}}, result.Mismatches)
+	assert.Zero(t, result.RepairedTasks)
+	assert.Equal(t, int64(5), repo.Documents["users/user-1/tasks/drifted"]["actualMinutes"], "report mode writes nothing")
+}
+
+func TestCheckSessionTaskConsistency_Repair(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	seedSessionTaskDrift(repo)
+	svc := NewDashboardAnalyticsService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	result, err := svc.CheckSessionTaskConsistency(ctx, "user-1", true)
+	require.NoError(t, err)
+
+	assert.True(t, result.Repaired)
+	assert.Equal(t, 1, result.RepairedTasks)
+	assert.Equal(t, 11.5, repo.Documents["users/user-1/tasks/drifted"]["actualMinutes"])
+	assert.NotContains(t, repo.Documents["users/user-1/tasks/untracked"], "actualMinutes")
+
+	result, err = svc.CheckSessionTaskConsistency(ctx, "user-1", false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Mismatches)
+	assert.Len(t, result.OrphanedTasks, 1, "orphaned entries are only reported")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,15 +15,21 @@ import (
 
 // SpendingAnalyticsService handles spending analytics computations
 type SpendingAnalyticsService struct {
-	repo   interfaces.Repository
-	logger *zap.Logger
+	repo            interfaces.Repository
+	logger          *zap.Logger
+	merchantAliases *MerchantAliasService
+	currency        *CurrencyService
 }
 
-// NewSpendingAnalyticsService creates a new spending analytics service
-func NewSpendingAnalyticsService(repo interfaces.Repository, logger *zap.Logger) *SpendingAnalyticsService {
+// NewSpendingAnalyticsService creates a new spending analytics service.
+// merchantAliases may be nil, in which case merchants are grouped by stored name.
+// currency may be nil, in which case amounts are reported in DefaultCurrency.
+func NewSpendingAnalyticsService(repo interfaces.Repository, logger *zap.Logger, merchantAliases *MerchantAliasService, currency *CurrencyService) *SpendingAnalyticsService {
 	return &SpendingAnalyticsService{
-		repo:   repo,
-		logger: logger,
+		repo:            repo,
+		logger:          logger,
+		merchantAliases: merchantAliases,
+		currency:        currency,
 	}
 }
 
@@ -34,6 +41,11 @@ type SpendingAnalytics struct {
 	TrendData         []TrendItem    `json:"trendData"`
 	TopMerchants      []MerchantItem `json:"topMerchants"`
 	DateRange         DateRangeInfo  `json:"dateRange"`
+	Currency          string         `json:"currency"`
+	Locale            string         `json:"locale"`
+	// UnconvertedCurrencies lists currencies with no exchange rate; their
+	// amounts are included unconverted
+	UnconvertedCurrencies []string `json:"unconvertedCurrencies,omitempty"`
 }
 
 // SpendingStats holds overall spending statistics
@@ -97,11 +109,18 @@ func (s *SpendingAnalyticsService) ComputeSpendingAnalytics(ctx context.Context,
 		return nil, fmt.Errorf("invalid end date: %w", err)
 	}
 
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
 	// Fetch transactions
 	transactions, err := s.fetchTransactions(ctx, uid, start, end, accountIDs)
 	if err != nil {
 		return nil, err
 	}
+	converter := s.currency.Converter(ctx, prefs.Currency)
+	transactions = s.convertTransactions(transactions, converter)
 
 	s.logger.Debug("Fetched transactions",
 		zap.Int("count", len(transactions)),
@@ -117,6 +136,9 @@ func (s *SpendingAnalyticsService) ComputeSpendingAnalytics(ctx context.Context,
 			End:   endDate,
 			Days:  days,
 		},
+		Currency:              converter.Target(),
+		Locale:                prefs.Locale,
+		UnconvertedCurrencies: converter.Unconverted(),
 	}
 
 	// Compute stats
@@ -132,7 +154,7 @@ func (s *SpendingAnalyticsService) ComputeSpendingAnalytics(ctx context.Context,
 	analytics.TrendData = s.computeTrendData(transactions)
 
 	// Compute top merchants
-	analytics.TopMerchants = s.computeTopMerchants(transactions)
+	analytics.TopMerchants = s.computeTopMerchants(transactions, s.merchantAliases.Matcher(ctx, uid))
 
 	s.logger.Info("Spending analytics computed",
 		zap.String("uid", uid),
@@ -157,6 +179,11 @@ func (s *SpendingAnalyticsService) fetchTransactions(ctx context.Context, uid st
 	endDateStr := endDate.Format("2006-01-02")
 
 	for _, txn := range allTransactions {
+		// Skip records merged into another by DeduplicateTransactions
+		if s.getStringField(txn, "duplicateOf") != "" {
+			continue
+		}
+
 		// Filter by date range
 		if postedAt, ok := txn["postedAt"].(string); ok {
 			if postedAt < startDateStr || postedAt > endDateStr {
@@ -188,6 +215,41 @@ func (s *SpendingAnalyticsService) fetchTransactions(ctx context.Context, uid st
 	return transactions, nil
 }
 
+// convertTransactions returns transactions with amount and signedAmount in
+// the converter's currency
+func (s *SpendingAnalyticsService) convertTransactions(transactions []map[string]interface{}, converter *CurrencyConverter) []map[string]interface{} {
+	return convertTransactionAmounts(transactions, converter)
+}
+
+// convertTransactionAmounts returns transactions with amount and signedAmount
+// in the converter's currency. Transactions are copied only when converted.
+func convertTransactionAmounts(transactions []map[string]interface{}, converter *CurrencyConverter) []map[string]interface{} {
+	converted := make([]map[string]interface{}, len(transactions))
+	for i, txn := range transactions {
+		currency := getStringField(txn, "isoCurrency")
+		if currency == "" {
+			currency = getStringField(txn, "currency")
+		}
+		if currency == "" || strings.EqualFold(currency, converter.Target()) {
+			converted[i] = txn
+			continue
+		}
+
+		copied := make(map[string]interface{}, len(txn))
+		for key, value := range txn {
+			copied[key] = value
+		}
+		for _, field := range []string{"amount", "signedAmount"} {
+			if _, ok := txn[field]; ok {
+				amount, _ := toFloat(txn[field])
+				copied[field] = converter.Convert(amount, currency)
+			}
+		}
+		converted[i] = copied
+	}
+	return converted
+}
+
 // computeStats computes overall spending statistics
 func (s *SpendingAnalyticsService) computeStats(transactions []map[string]interface{}, days int) SpendingStats {
 	stats := SpendingStats{
@@ -241,15 +303,7 @@ func (s *SpendingAnalyticsService) computeCategoryBreakdown(transactions []map[s
 			continue // Skip income
 		}
 
-		category := s.getStringField(txn, "category")
-		if category == "" {
-			// Try category_base (array)
-			if catBase, ok := txn["category_base"].([]interface{}); ok && len(catBase) > 0 {
-				if cat, ok := catBase[0].(string); ok {
-					category = cat
-				}
-			}
-		}
+		category := s.getCategory(txn)
 		if category == "" {
 			category = "Uncategorized"
 		}
@@ -355,8 +409,9 @@ func (s *SpendingAnalyticsService) computeTrendData(transactions []map[string]in
 	return items
 }
 
-// computeTopMerchants computes top merchants by spending
-func (s *SpendingAnalyticsService) computeTopMerchants(transactions []map[string]interface{}) []MerchantItem {
+// computeTopMerchants computes top merchants by spending. Names are mapped
+// through the alias rules so variants of one merchant are totalled together.
+func (s *SpendingAnalyticsService) computeTopMerchants(transactions []map[string]interface{}, merchants *MerchantMatcher) []MerchantItem {
 	totals := make(map[string]float64)
 
 	for _, txn := range transactions {
@@ -365,7 +420,7 @@ func (s *SpendingAnalyticsService) computeTopMerchants(transactions []map[string
 			continue // Skip income
 		}
 
-		merchant := s.getMerchantName(txn)
+		merchant, _ := merchants.Canonicalize(s.getMerchantName(txn))
 		if merchant == "" {
 			merchant = "Unknown"
 		}
@@ -397,23 +452,31 @@ func (s *SpendingAnalyticsService) computeTopMerchants(transactions []map[string
 // Helper functions
 
 func (s *SpendingAnalyticsService) getSignedAmount(txn map[string]interface{}) float64 {
+	return signedTransactionAmount(txn)
+}
+
+// signedTransactionAmount is a transaction's amount with outflows positive
+// and inflows negative
+func signedTransactionAmount(txn map[string]interface{}) float64 {
 	// Try signedAmount first
-	if signedAmount := s.getFloatField(txn, "signedAmount"); signedAmount != 0 {
+	if signedAmount, _ := toFloat(txn["signedAmount"]); signedAmount != 0 {
 		return signedAmount
 	}
 
 	// Fallback to amount + isIncome
-	amount := s.getFloatField(txn, "amount")
-	isIncome := s.getBoolField(txn, "isIncome")
-	if isIncome {
+	amount, _ := toFloat(txn["amount"])
+	if isIncome, _ := txn["isIncome"].(bool); isIncome {
 		return -amount
 	}
 	return amount
 }
 
 func (s *SpendingAnalyticsService) getMerchantName(txn map[string]interface{}) string {
-	// Try merchant.name first (object)
+	// Try merchant.canonical, then merchant.name (object)
 	if merchant, ok := txn["merchant"].(map[string]interface{}); ok {
+		if canonical, ok := merchant["canonical"].(string); ok && canonical != "" {
+			return canonical
+		}
 		if name, ok := merchant["name"].(string); ok && name != "" {
 			return name
 		}
@@ -437,6 +500,20 @@ func (s *SpendingAnalyticsService) getMerchantName(txn map[string]interface{}) s
 	return ""
 }
 
+// getCategory returns the transaction's category, falling back to the first
+// Plaid base category
+func (s *SpendingAnalyticsService) getCategory(txn map[string]interface{}) string {
+	if category := s.getStringField(txn, "category"); category != "" {
+		return category
+	}
+	if catBase, ok := txn["category_base"].([]interface{}); ok && len(catBase) > 0 {
+		if cat, ok := catBase[0].(string); ok {
+			return cat
+		}
+	}
+	return ""
+}
+
 func (s *SpendingAnalyticsService) getFloatField(txn map[string]interface{}, field string) float64 {
 	if val, ok := txn[field].(float64); ok {
 		return val
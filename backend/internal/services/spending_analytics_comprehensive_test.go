@@ -389,7 +389,7 @@ func TestSpendingAnalyticsService_ComputeTopMerchants(t *testing.T) {
 		{"signedAmount": -500.0, "merchantName": "Employer"}, // Income, ignored
 	}
 
-	merchants := service.computeTopMerchants(transactions)
+	merchants := service.computeTopMerchants(transactions, nil)
 
 	require.GreaterOrEqual(t, len(merchants), 3)
 
@@ -412,7 +412,7 @@ func TestSpendingAnalyticsService_ComputeTopMerchants_Max5(t *testing.T) {
 		})
 	}
 
-	merchants := service.computeTopMerchants(transactions)
+	merchants := service.computeTopMerchants(transactions, nil)
 
 	// Should return max 5 merchants
 	assert.LessOrEqual(t, len(merchants), 5)
@@ -421,7 +421,7 @@ func TestSpendingAnalyticsService_ComputeTopMerchants_Max5(t *testing.T) {
 func TestSpendingAnalyticsService_ComputeSpendingAnalytics(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewSpendingAnalyticsService(mockRepo, logger)
+	service := NewSpendingAnalyticsService(mockRepo, logger, nil, nil)
 
 	ctx := context.Background()
 	uid := "test-user-123"
@@ -457,7 +457,7 @@ func TestSpendingAnalyticsService_ComputeSpendingAnalytics(t *testing.T) {
 func TestSpendingAnalyticsService_ComputeSpendingAnalytics_InvalidDate(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	logger := zap.NewNop()
-	service := NewSpendingAnalyticsService(mockRepo, logger)
+	service := NewSpendingAnalyticsService(mockRepo, logger, nil, nil)
 
 	ctx := context.Background()
 
@@ -45,6 +45,26 @@ func (m *MockRepositoryForSpending) List(ctx context.Context, collectionPath str
 	return nil, nil
 }
 
+func (m *MockRepositoryForSpending) ListOrdered(ctx context.Context, collectionPath string, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForSpending) ListWhere(ctx context.Context, collectionPath string, filters []interfaces.Filter, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForSpending) GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForSpending) DeleteAll(ctx context.Context, paths []string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockRepositoryForSpending) ListWhereOrdered(ctx context.Context, collectionPath string, filters []interfaces.Filter, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
 func (m *MockRepositoryForSpending) GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error) {
 	return nil, nil
 }
@@ -69,6 +89,42 @@ func (m *MockRepositoryForSpending) QueryCollection(ctx context.Context, collect
 	return nil, nil
 }
 
+func (m *MockRepositoryForSpending) ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error {
+	return nil
+}
+
+func (m *MockRepositoryForSpending) CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForSpending) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx interfaces.Transaction) error) error {
+	return nil
+}
+
+func (m *MockRepositoryForSpending) IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
+func (m *MockRepositoryForSpending) Increment(ctx context.Context, path, field string, delta int64) error {
+	return nil
+}
+
+func (m *MockRepositoryForSpending) ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepositoryForSpending) ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepositoryForSpending) CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepositoryForSpending) Count(ctx context.Context, query firestore.Query) (int64, error) {
+	return 0, nil
+}
+
 func (m *MockRepositoryForSpending) Collection(path string) *firestore.CollectionRef {
 	return nil
 }
@@ -87,7 +143,7 @@ func TestNewSpendingAnalyticsService(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
 	logger := zap.NewNop()
 
-	service := NewSpendingAnalyticsService(repo, logger)
+	service := NewSpendingAnalyticsService(repo, logger, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, repo, service.repo)
@@ -97,7 +153,7 @@ func TestNewSpendingAnalyticsService(t *testing.T) {
 func TestNewSpendingAnalyticsService_WithNilRepository(t *testing.T) {
 	logger := zap.NewNop()
 
-	service := NewSpendingAnalyticsService(nil, logger)
+	service := NewSpendingAnalyticsService(nil, logger, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Nil(t, service.repo)
@@ -107,7 +163,7 @@ func TestNewSpendingAnalyticsService_WithNilRepository(t *testing.T) {
 func TestNewSpendingAnalyticsService_WithNilLogger(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
 
-	service := NewSpendingAnalyticsService(repo, nil)
+	service := NewSpendingAnalyticsService(repo, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, repo, service.repo)
@@ -115,7 +171,7 @@ func TestNewSpendingAnalyticsService_WithNilLogger(t *testing.T) {
 }
 
 func TestNewSpendingAnalyticsService_AllNil(t *testing.T) {
-	service := NewSpendingAnalyticsService(nil, nil)
+	service := NewSpendingAnalyticsService(nil, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.Nil(t, service.repo)
@@ -125,7 +181,7 @@ func TestNewSpendingAnalyticsService_AllNil(t *testing.T) {
 func TestSpendingAnalyticsService_Fields(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
 	logger := zap.NewNop()
-	service := NewSpendingAnalyticsService(repo, logger)
+	service := NewSpendingAnalyticsService(repo, logger, nil, nil)
 
 	assert.NotNil(t, service.repo)
 	assert.NotNil(t, service.logger)
@@ -133,20 +189,20 @@ func TestSpendingAnalyticsService_Fields(t *testing.T) {
 
 func TestSpendingAnalyticsService_RepositoryStorage(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
-	service := NewSpendingAnalyticsService(repo, nil)
+	service := NewSpendingAnalyticsService(repo, nil, nil, nil)
 
 	assert.Equal(t, repo, service.repo)
 }
 
 func TestSpendingAnalyticsService_LoggerStorage(t *testing.T) {
 	logger := zap.NewNop()
-	service := NewSpendingAnalyticsService(nil, logger)
+	service := NewSpendingAnalyticsService(nil, logger, nil, nil)
 
 	assert.Equal(t, logger, service.logger)
 }
 
 func TestSpendingAnalyticsService_Constructor(t *testing.T) {
-	service := NewSpendingAnalyticsService(nil, nil)
+	service := NewSpendingAnalyticsService(nil, nil, nil, nil)
 
 	assert.Nil(t, service.repo)
 	assert.Nil(t, service.logger)
@@ -156,8 +212,8 @@ func TestSpendingAnalyticsService_MultipleInstances(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
 	logger := zap.NewNop()
 
-	service1 := NewSpendingAnalyticsService(repo, logger)
-	service2 := NewSpendingAnalyticsService(repo, logger)
+	service1 := NewSpendingAnalyticsService(repo, logger, nil, nil)
+	service2 := NewSpendingAnalyticsService(repo, logger, nil, nil)
 
 	assert.NotNil(t, service1)
 	assert.NotNil(t, service2)
@@ -167,7 +223,7 @@ func TestSpendingAnalyticsService_MultipleInstances(t *testing.T) {
 
 func TestSpendingAnalyticsService_WithRepository(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
-	service := NewSpendingAnalyticsService(repo, nil)
+	service := NewSpendingAnalyticsService(repo, nil, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.repo)
@@ -175,14 +231,14 @@ func TestSpendingAnalyticsService_WithRepository(t *testing.T) {
 
 func TestSpendingAnalyticsService_WithLogger(t *testing.T) {
 	logger := zap.NewNop()
-	service := NewSpendingAnalyticsService(nil, logger)
+	service := NewSpendingAnalyticsService(nil, logger, nil, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.logger)
 }
 
 func TestSpendingAnalyticsService_ImplementsExpectedMethods(t *testing.T) {
-	service := NewSpendingAnalyticsService(nil, nil)
+	service := NewSpendingAnalyticsService(nil, nil, nil, nil)
 
 	assert.NotNil(t, service)
 }
@@ -191,7 +247,7 @@ func TestSpendingAnalyticsService_AllFields(t *testing.T) {
 	repo := NewMockRepositoryForSpending()
 	logger := zap.NewNop()
 
-	service := NewSpendingAnalyticsService(repo, logger)
+	service := NewSpendingAnalyticsService(repo, logger, nil, nil)
 
 	assert.NotNil(t, service.repo)
 	assert.NotNil(t, service.logger)
@@ -268,24 +324,24 @@ func TestAccountItem_EmptyValues(t *testing.T) {
 
 func TestSpendingAnalyticsService_ConstructorVariations(t *testing.T) {
 	// Variation 1: both nil
-	s1 := NewSpendingAnalyticsService(nil, nil)
+	s1 := NewSpendingAnalyticsService(nil, nil, nil, nil)
 	assert.Nil(t, s1.repo)
 	assert.Nil(t, s1.logger)
 
 	// Variation 2: repo only
 	repo := NewMockRepositoryForSpending()
-	s2 := NewSpendingAnalyticsService(repo, nil)
+	s2 := NewSpendingAnalyticsService(repo, nil, nil, nil)
 	assert.NotNil(t, s2.repo)
 	assert.Nil(t, s2.logger)
 
 	// Variation 3: logger only
 	logger := zap.NewNop()
-	s3 := NewSpendingAnalyticsService(nil, logger)
+	s3 := NewSpendingAnalyticsService(nil, logger, nil, nil)
 	assert.Nil(t, s3.repo)
 	assert.NotNil(t, s3.logger)
 
 	// Variation 4: both
-	s4 := NewSpendingAnalyticsService(repo, logger)
+	s4 := NewSpendingAnalyticsService(repo, logger, nil, nil)
 	assert.NotNil(t, s4.repo)
 	assert.NotNil(t, s4.logger)
 }
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Spending anomaly reasons
+const (
+	AnomalyReasonUnusualAmount = "unusual_amount"
+	AnomalyReasonNewMerchant   = "new_merchant"
+)
+
+// Spending anomaly baselines
+const (
+	AnomalyBaselineMerchant = "merchant"
+	AnomalyBaselineCategory = "category"
+	AnomalyBaselineOverall  = "overall"
+)
+
+const (
+	defaultAnomalyLookbackMonths = 6
+	maxAnomalyLookbackMonths     = 24
+
+	// DefaultAnomalyZScore is how many standard deviations above the baseline
+	// mean a charge must be to be flagged
+	DefaultAnomalyZScore = 3.0
+
+	// minAnomalyBaseline is the fewest other charges a baseline needs
+	minAnomalyBaseline = 3
+
+	// minAnomalyDeviation floors the standard deviation as a fraction of the
+	// mean, so a merchant that always charges the same amount is not flagged
+	// for a few cents of difference
+	minAnomalyDeviation = 0.1
+)
+
+// SpendingAnomalies holds the charges flagged as unusual
+type SpendingAnomalies struct {
+	Anomalies      []SpendingAnomaly `json:"anomalies"`
+	LookbackMonths int               `json:"lookbackMonths"`
+	ZScore         float64           `json:"zScore"`
+	Currency       string            `json:"currency"`
+}
+
+// SpendingAnomaly is a charge well above what the user usually spends at the
+// merchant, in the category, or overall for a first charge at a new merchant
+type SpendingAnomaly struct {
+	TransactionID string  `json:"transactionId"`
+	Date          string  `json:"date"`
+	Merchant      string  `json:"merchant"`
+	Category      string  `json:"category"`
+	Amount        float64 `json:"amount"`
+	Reason        string  `json:"reason"`
+	Baseline      string  `json:"baseline"`
+	ZScore        float64 `json:"zScore"`
+	ExpectedMin   float64 `json:"expectedMin"`
+	ExpectedMax   float64 `json:"expectedMax"`
+}
+
+// anomalyCharge is a spend transaction considered for anomaly detection
+type anomalyCharge struct {
+	id          string
+	date        time.Time
+	merchant    string
+	merchantKey string
+	category    string
+	amount      float64
+}
+
+// anomalyStats accumulates the amounts of a group of charges
+type anomalyStats struct {
+	n     int
+	sum   float64
+	sumSq float64
+}
+
+func (a *anomalyStats) add(amount float64) {
+	a.n++
+	a.sum += amount
+	a.sumSq += amount * amount
+}
+
+// without returns the mean and standard deviation of the group with amount
+// left out, and false when fewer than minAnomalyBaseline charges remain
+func (a *anomalyStats) without(amount float64) (mean, stddev float64, ok bool) {
+	if a == nil || a.n-1 < minAnomalyBaseline {
+		return 0, 0, false
+	}
+	n := a.n - 1
+	mean = (a.sum - amount) / float64(n)
+	variance := (a.sumSq-amount*amount)/float64(n) - mean*mean
+	stddev = math.Sqrt(math.Max(variance, 0))
+	return mean, math.Max(stddev, mean*minAnomalyDeviation), true
+}
+
+// DetectAnomalies flags charges in the last lookbackMonths months that are
+// more than zScore standard deviations above the user's usual spend. Each
+// charge is compared with the other charges at the same merchant; merchants
+// with too little history fall back to the charge's category. The first charge
+// at a merchant is compared with all of the user's spending and flagged as a
+// new merchant. A zScore of zero uses DefaultAnomalyZScore.
+func (s *SpendingAnalyticsService) DetectAnomalies(ctx context.Context, uid string, lookbackMonths int, zScore float64) (*SpendingAnomalies, error) {
+	if lookbackMonths <= 0 {
+		lookbackMonths = defaultAnomalyLookbackMonths
+	}
+	if lookbackMonths > maxAnomalyLookbackMonths {
+		lookbackMonths = maxAnomalyLookbackMonths
+	}
+	if zScore <= 0 {
+		zScore = DefaultAnomalyZScore
+	}
+
+	now := time.Now().UTC()
+	start := now.AddDate(0, -lookbackMonths, 0)
+
+	prefs, err := s.currency.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.fetchTransactions(ctx, uid, start, now, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := s.currency.Converter(ctx, prefs.Currency)
+	transactions = s.convertTransactions(transactions, converter)
+	charges := s.anomalyCharges(transactions, s.merchantAliases.Matcher(ctx, uid))
+
+	return &SpendingAnomalies{
+		Anomalies:      detectAnomalies(charges, zScore),
+		LookbackMonths: lookbackMonths,
+		ZScore:         zScore,
+		Currency:       converter.Target(),
+	}, nil
+}
+
+// anomalyCharges returns the spend transactions with a parseable date, oldest first
+func (s *SpendingAnalyticsService) anomalyCharges(transactions []map[string]interface{}, merchants *MerchantMatcher) []anomalyCharge {
+	charges := make([]anomalyCharge, 0, len(transactions))
+	for _, txn := range transactions {
+		amount := s.getSignedAmount(txn)
+		if amount <= 0 {
+			continue
+		}
+		date, ok := parseTransactionDate(txn["postedAt"])
+		if !ok {
+			date, ok = parseTransactionDate(txn["date"])
+		}
+		if !ok {
+			continue
+		}
+
+		merchant, _ := merchants.Canonicalize(s.getMerchantName(txn))
+		charges = append(charges, anomalyCharge{
+			id:          s.getStringField(txn, "id"),
+			date:        date,
+			merchant:    merchant,
+			merchantKey: normalizeMerchantName(merchant),
+			category:    s.getCategory(txn),
+			amount:      amount,
+		})
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].date.Before(charges[j].date)
+	})
+	return charges
+}
+
+// detectAnomalies flags charges against baselines built from the other
+// charges; charges must be sorted oldest first. Anomalies are returned newest first.
+func detectAnomalies(charges []anomalyCharge, zScore float64) []SpendingAnomaly {
+	overall := &anomalyStats{}
+	byMerchant := map[string]*anomalyStats{}
+	byCategory := map[string]*anomalyStats{}
+	for _, c := range charges {
+		overall.add(c.amount)
+		if c.merchantKey != "" {
+			if byMerchant[c.merchantKey] == nil {
+				byMerchant[c.merchantKey] = &anomalyStats{}
+			}
+			byMerchant[c.merchantKey].add(c.amount)
+		}
+		if c.category != "" {
+			key := strings.ToLower(c.category)
+			if byCategory[key] == nil {
+				byCategory[key] = &anomalyStats{}
+			}
+			byCategory[key].add(c.amount)
+		}
+	}
+
+	anomalies := []SpendingAnomaly{}
+	seenMerchants := map[string]bool{}
+	for _, c := range charges {
+		firstAtMerchant := c.merchantKey != "" && !seenMerchants[c.merchantKey]
+		seenMerchants[c.merchantKey] = true
+
+		reason, baseline := AnomalyReasonUnusualAmount, AnomalyBaselineMerchant
+		mean, stddev, ok := byMerchant[c.merchantKey].without(c.amount)
+		if !ok && firstAtMerchant {
+			reason, baseline = AnomalyReasonNewMerchant, AnomalyBaselineOverall
+			mean, stddev, ok = overall.without(c.amount)
+		}
+		if !ok && c.category != "" {
+			reason, baseline = AnomalyReasonUnusualAmount, AnomalyBaselineCategory
+			mean, stddev, ok = byCategory[strings.ToLower(c.category)].without(c.amount)
+		}
+		if !ok || stddev == 0 {
+			continue
+		}
+
+		z := (c.amount - mean) / stddev
+		if z <= zScore {
+			continue
+		}
+		anomalies = append(anomalies, SpendingAnomaly{
+			TransactionID: c.id,
+			Date:          c.date.Format("2006-01-02"),
+			Merchant:      c.merchant,
+			Category:      c.category,
+			Amount:        roundAnomaly(c.amount),
+			Reason:        reason,
+			Baseline:      baseline,
+			ZScore:        roundAnomaly(z),
+			ExpectedMin:   roundAnomaly(math.Max(mean-zScore*stddev, 0)),
+			ExpectedMax:   roundAnomaly(mean + zScore*stddev),
+		})
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Date > anomalies[j].Date
+	})
+	return anomalies
+}
+
+// roundAnomaly rounds to two decimal places
+func roundAnomaly(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, nil)
+	uid := "user-1"
+
+	daysAgo := func(days int) string {
+		return time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	}
+	add := func(id string, days int, merchant, category string, amount float64) {
+		repo.AddDocument(fmt.Sprintf("users/%s/transactions/%s", uid, id), map[string]interface{}{
+			"id": id, "postedAt": daysAgo(days), "merchantName": merchant, "category": category, "amount": amount,
+		})
+	}
+
+	// Weekly groceries around $50 with one $500 outlier
+	for i, amount := range []float64{48, 52, 50, 49, 51, 50, 47, 53} {
+		add(fmt.Sprintf("grocer-%d", i), 70-i*7, "Fresh Grocer", "Groceries", amount)
+	}
+	add("grocer-outlier", 3, "Fresh Grocer", "Groceries", 500)
+	// Coffee is small and regular
+	for i := 0; i < 5; i++ {
+		add(fmt.Sprintf("coffee-%d", i), 60-i*10, "Corner Cafe", "Food", 4.5)
+	}
+	// A large first charge at a new merchant and a small one
+	add("tv", 10, "Big Electronics", "Shopping", 1200)
+	add("bookshop", 12, "Book Nook", "Shopping", 25)
+	// Income and charges outside the lookback window are ignored
+	repo.AddDocument("users/"+uid+"/transactions/pay", map[string]interface{}{
+		"id": "pay", "postedAt": daysAgo(5), "merchantName": "ACME PAYROLL", "amount": -5000.0,
+	})
+	add("old", 400, "Fresh Grocer", "Groceries", 5000)
+
+	result, err := service.DetectAnomalies(context.Background(), uid, 6, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAnomalyZScore, result.ZScore)
+	assert.Equal(t, 6, result.LookbackMonths)
+	require.Len(t, result.Anomalies, 2)
+
+	outlier := result.Anomalies[0]
+	assert.Equal(t, "grocer-outlier", outlier.TransactionID)
+	assert.Equal(t, AnomalyReasonUnusualAmount, outlier.Reason)
+	assert.Equal(t, AnomalyBaselineMerchant, outlier.Baseline)
+	assert.Equal(t, 500.0, outlier.Amount)
+	assert.Greater(t, outlier.ZScore, DefaultAnomalyZScore)
+	assert.Less(t, outlier.ExpectedMin, 50.0)
+	assert.Greater(t, outlier.ExpectedMax, 50.0)
+	assert.Less(t, outlier.ExpectedMax, 100.0)
+
+	tv := result.Anomalies[1]
+	assert.Equal(t, "tv", tv.TransactionID)
+	assert.Equal(t, AnomalyReasonNewMerchant, tv.Reason)
+	assert.Equal(t, AnomalyBaselineOverall, tv.Baseline)
+	assert.Equal(t, "Big Electronics", tv.Merchant)
+}
+
+func TestDetectAnomalies_CategoryFallback(t *testing.T) {
+	charges := []anomalyCharge{}
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Different gas stations, each visited twice, share a category baseline
+	for i, amount := range []float64{40, 42, 38, 41, 39, 40} {
+		merchant := fmt.Sprintf("Station %d", i/2)
+		charges = append(charges, anomalyCharge{
+			id: fmt.Sprint(i), date: date.AddDate(0, 0, i), merchant: merchant,
+			merchantKey: normalizeMerchantName(merchant), category: "Gas", amount: amount,
+		})
+	}
+	charges = append(charges, anomalyCharge{
+		id: "spike", date: date.AddDate(0, 0, 10), merchant: "Station 0",
+		merchantKey: normalizeMerchantName("Station 0"), category: "Gas", amount: 180,
+	})
+
+	anomalies := detectAnomalies(charges, DefaultAnomalyZScore)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "spike", anomalies[0].TransactionID)
+	assert.Equal(t, AnomalyBaselineCategory, anomalies[0].Baseline)
+
+	// A looser threshold flags nothing
+	assert.Empty(t, detectAnomalies(charges, 50))
+}
+
+func TestDetectAnomalies_ConstantAmountsNotFlagged(t *testing.T) {
+	charges := []anomalyCharge{}
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, amount := range []float64{15.99, 15.99, 15.99, 15.99, 16.49} {
+		charges = append(charges, anomalyCharge{
+			id: fmt.Sprint(i), date: date.AddDate(0, i, 0), merchant: "Streamly",
+			merchantKey: "streamly", category: "Entertainment", amount: amount,
+		})
+	}
+
+	assert.Empty(t, detectAnomalies(charges, DefaultAnomalyZScore), "a small price change is not an anomaly")
+}
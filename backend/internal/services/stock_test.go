@@ -46,6 +46,26 @@ func (m *MockRepository) List(ctx context.Context, collectionPath string, limit
 	return nil, nil
 }
 
+func (m *MockRepository) ListOrdered(ctx context.Context, collectionPath string, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) ListWhere(ctx context.Context, collectionPath string, filters []interfaces.Filter, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) GetAll(ctx context.Context, paths []string) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) DeleteAll(ctx context.Context, paths []string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockRepository) ListWhereOrdered(ctx context.Context, collectionPath string, filters []interfaces.Filter, orderings []interfaces.Ordering, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
 func (m *MockRepository) GetDocument(ctx context.Context, path string) (*firestore.DocumentSnapshot, error) {
 	return nil, nil
 }
@@ -70,6 +90,42 @@ func (m *MockRepository) QueryCollection(ctx context.Context, collectionPath str
 	return nil, nil
 }
 
+func (m *MockRepository) ForEach(ctx context.Context, query firestore.Query, fn func(doc *firestore.DocumentSnapshot) error) error {
+	return nil
+}
+
+func (m *MockRepository) CollectAll(ctx context.Context, query firestore.Query) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx interfaces.Transaction) error) error {
+	return nil
+}
+
+func (m *MockRepository) IncrementWithinLimit(ctx context.Context, path, field string, delta, limit int64, updates map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
+func (m *MockRepository) Increment(ctx context.Context, path, field string, delta int64) error {
+	return nil
+}
+
+func (m *MockRepository) ArrayUnion(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepository) ArrayRemove(ctx context.Context, path, field string, values ...interface{}) error {
+	return nil
+}
+
+func (m *MockRepository) CollectAllConsistent(ctx context.Context, queries []firestore.Query) ([][]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) Count(ctx context.Context, query firestore.Query) (int64, error) {
+	return 0, nil
+}
+
 func (m *MockRepository) Collection(path string) *firestore.CollectionRef {
 	return nil
 }
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// ErrInvalidStreakRequest is returned for an invalid collection, date field or timezone
+var ErrInvalidStreakRequest = errors.New("invalid streak request")
+
+// streakNamePattern limits collection and field names to plain identifiers, so
+// a request cannot reach outside the user's own collections
+var streakNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// Streak is the number of consecutive days with activity, ending today
+type Streak struct {
+	Collection     string `json:"collection"`
+	DateField      string `json:"dateField"`
+	Timezone       string `json:"timezone"`
+	Current        int    `json:"current"`
+	LastActiveDate string `json:"lastActiveDate,omitempty"`
+}
+
+// StreakService computes habit streaks over any user collection
+type StreakService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewStreakService creates a new streak service
+func NewStreakService(repo interfaces.Repository, logger *zap.Logger) *StreakService {
+	return &StreakService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Compute counts consecutive days, ending today in the given IANA timezone,
+// with at least one document in users/{uid}/{collection} whose dateField falls
+// on that day. Timestamps are converted to the timezone; date-only strings
+// (YYYY-MM-DD) are taken as calendar days as written. An empty timezone is UTC.
+func (s *StreakService) Compute(ctx context.Context, uid, collection, dateField, timezone string) (*Streak, error) {
+	if !streakNamePattern.MatchString(collection) {
+		return nil, fmt.Errorf("%w: invalid collection %q", ErrInvalidStreakRequest, collection)
+	}
+	if !streakNamePattern.MatchString(dateField) {
+		return nil, fmt.Errorf("%w: invalid date field %q", ErrInvalidStreakRequest, dateField)
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidStreakRequest, timezone)
+	}
+
+	docs, err := s.repo.List(ctx, fmt.Sprintf("users/%s/%s", uid, collection), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", collection, err)
+	}
+
+	activeDays := make(map[string]bool)
+	lastActive := ""
+	for _, doc := range docs {
+		day, ok := activityDay(doc[dateField], loc)
+		if !ok {
+			continue
+		}
+		activeDays[day] = true
+		if day > lastActive {
+			lastActive = day
+		}
+	}
+
+	return &Streak{
+		Collection:     collection,
+		DateField:      dateField,
+		Timezone:       loc.String(),
+		Current:        countStreak(activeDays, time.Now().In(loc)),
+		LastActiveDate: lastActive,
+	}, nil
+}
+
+// activityDay returns the YYYY-MM-DD calendar day of a date field value in loc
+func activityDay(value interface{}, loc *time.Location) (string, bool) {
+	if s, ok := value.(string); ok {
+		if day, err := time.Parse("2006-01-02", s); err == nil {
+			return day.Format("2006-01-02"), true
+		}
+	}
+	t, ok := parseTransactionDate(value)
+	if !ok {
+		return "", false
+	}
+	return t.In(loc).Format("2006-01-02"), true
+}
+
+// countStreak counts backwards from the calendar day of reference while each
+// day (YYYY-MM-DD) is in activeDays
+func countStreak(activeDays map[string]bool, reference time.Time) int {
+	year, month, day := reference.Date()
+	current := time.Date(year, month, day, 0, 0, 0, 0, reference.Location())
+
+	streak := 0
+	for activeDays[current.Format("2006-01-02")] {
+		streak++
+		current = current.AddDate(0, 0, -1)
+	}
+	return streak
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestCountStreak_StopsAtGap(t *testing.T) {
+	active := map[string]bool{
+		"2024-03-10": true,
+		"2024-03-09": true,
+		"2024-03-08": true,
+		// 2024-03-07 missing
+		"2024-03-06": true,
+		"2024-03-05": true,
+	}
+
+	assert.Equal(t, 3, countStreak(active, time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, 2, countStreak(active, time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, countStreak(active, time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, countStreak(active, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestActivityDay_RespectsTimezone(t *testing.T) {
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+
+	// 02:30 UTC on the 11th is still the evening of the 10th in Toronto
+	ts := time.Date(2024, 3, 11, 2, 30, 0, 0, time.UTC)
+	day, ok := activityDay(ts, toronto)
+	require.True(t, ok)
+	assert.Equal(t, "2024-03-10", day)
+
+	day, ok = activityDay(ts, time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, "2024-03-11", day)
+
+	// Date-only strings are calendar days and are not shifted
+	day, ok = activityDay("2024-03-11", toronto)
+	require.True(t, ok)
+	assert.Equal(t, "2024-03-11", day)
+
+	_, ok = activityDay(nil, toronto)
+	assert.False(t, ok)
+}
+
+func TestStreakService_Compute(t *testing.T) {
+	today := time.Now().UTC()
+	day := func(offset int) string { return today.AddDate(0, 0, -offset).Format("2006-01-02") }
+
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/moods/m1", map[string]interface{}{"date": day(0)})
+	repo.AddDocument("users/user1/moods/m2", map[string]interface{}{"date": day(0)})
+	repo.AddDocument("users/user1/moods/m3", map[string]interface{}{"date": day(1)})
+	repo.AddDocument("users/user1/moods/m4", map[string]interface{}{"date": day(2)})
+	// Gap at day(3); older activity does not count
+	repo.AddDocument("users/user1/moods/m5", map[string]interface{}{"date": day(4)})
+	repo.AddDocument("users/user1/moods/m6", map[string]interface{}{"note": "undated"})
+	svc := NewStreakService(repo, zap.NewNop())
+
+	streak, err := svc.Compute(context.Background(), "user1", "moods", "date", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, streak.Current)
+	assert.Equal(t, day(0), streak.LastActiveDate)
+	assert.Equal(t, "UTC", streak.Timezone)
+
+	streak, err = svc.Compute(context.Background(), "user1", "moods", "createdAt", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, 0, streak.Current)
+	assert.Empty(t, streak.LastActiveDate)
+}
+
+func TestStreakService_ComputeRejectsInvalidInput(t *testing.T) {
+	svc := NewStreakService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.Compute(ctx, "user1", "../moods", "date", "")
+	assert.ErrorIs(t, err, ErrInvalidStreakRequest)
+
+	_, err = svc.Compute(ctx, "user1", "moods", "date.nested", "")
+	assert.ErrorIs(t, err, ErrInvalidStreakRequest)
+
+	_, err = svc.Compute(ctx, "user1", "moods", "date", "Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidStreakRequest)
+}
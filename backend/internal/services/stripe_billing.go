@@ -24,18 +24,22 @@ type StripeBillingService struct {
 	stripeClient *clients.StripeClient
 	repo         *repository.FirestoreRepository
 	logger       *zap.Logger
+	audit        *AuditService
 }
 
-// NewStripeBillingService creates a new Stripe billing service
+// NewStripeBillingService creates a new Stripe billing service. Subscription
+// changes from webhooks are recorded in the user's audit log; audit may be nil.
 func NewStripeBillingService(
 	stripeClient *clients.StripeClient,
 	repo *repository.FirestoreRepository,
 	logger *zap.Logger,
+	audit *AuditService,
 ) *StripeBillingService {
 	return &StripeBillingService{
 		stripeClient: stripeClient,
 		repo:         repo,
 		logger:       logger,
+		audit:        audit,
 	}
 }
 
@@ -170,7 +174,20 @@ func (s *StripeBillingService) handleSubscriptionDeleted(ctx context.Context, ev
 		"canceledAt": time.Now(),
 	}
 
-	return s.repo.UpdateDocument(ctx, statusPath, updates)
+	if err := s.repo.UpdateDocument(ctx, statusPath, updates); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, uid, AuditEntry{
+		Action: AuditActionSubscriptionChange,
+		Actor:  AuditActorStripe,
+		Details: map[string]interface{}{
+			"subscriptionId": subscription.ID,
+			"status":         "canceled",
+			"tier":           "free",
+		},
+	})
+	return nil
 }
 
 // handleCheckoutCompleted handles checkout.session.completed event
@@ -292,6 +309,15 @@ func (s *StripeBillingService) syncSubscriptionToFirestore(ctx context.Context,
 		zap.String("subscriptionId", subscription.ID),
 		zap.String("status", string(subscription.Status)),
 	)
+	s.audit.Record(ctx, uid, AuditEntry{
+		Action: AuditActionSubscriptionChange,
+		Actor:  AuditActorStripe,
+		Details: map[string]interface{}{
+			"subscriptionId": subscription.ID,
+			"status":         string(subscription.Status),
+			"tier":           status["tier"],
+		},
+	})
 
 	return nil
 }
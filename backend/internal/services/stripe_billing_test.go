@@ -11,7 +11,7 @@ import (
 func TestNewStripeBillingService(t *testing.T) {
 	// Test that service can be created with nil dependencies
 	// (nil checks should be handled by the caller)
-	service := NewStripeBillingService(nil, nil, nil)
+	service := NewStripeBillingService(nil, nil, nil, nil)
 	assert.NotNil(t, service)
 }
 
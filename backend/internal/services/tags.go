@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// MaxTagLength caps the length of a document tag
+const MaxTagLength = 50
+
+// ErrInvalidTag is returned for empty, oversized or unchanged tag names
+var ErrInvalidTag = errors.New("invalid tag")
+
+// taggedCollections lists the user collections whose "tags" arrays are counted
+var taggedCollections = map[string]bool{
+	"tasks":    true,
+	"thoughts": true,
+	"notes":    true,
+}
+
+// TagCount is a tag and the number of documents using it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TagService keeps per-user tag usage counts for tagged collections. Counts
+// live in users/{uid}/tagCounts/{collection} as a map of tag to count; they
+// are rebuilt from the documents the first time a collection is listed.
+type TagService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewTagService creates a new tag service
+func NewTagService(repo interfaces.Repository, logger *zap.Logger) *TagService {
+	return &TagService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RecordChange updates tag counts for a document write. before is nil for a
+// created document and after is nil for a deleted one. Untagged collections
+// and a nil service are ignored. Counts are adjusted with a read-modify-write,
+// so concurrent writes can drift them until the next RebuildCounts.
+func (s *TagService) RecordChange(ctx context.Context, uid, collection string, before, after map[string]interface{}) error {
+	if s == nil || !taggedCollections[collection] {
+		return nil
+	}
+
+	deltas := map[string]int{}
+	for _, tag := range documentTags(before) {
+		deltas[tag]--
+	}
+	for _, tag := range documentTags(after) {
+		deltas[tag]++
+	}
+	for tag, delta := range deltas {
+		if delta == 0 {
+			delete(deltas, tag)
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	counts, found, err := s.loadCounts(ctx, uid, collection)
+	if err != nil || !found {
+		// Without stored counts there is nothing to adjust; the first
+		// ListTags rebuilds them from the documents
+		return err
+	}
+	for tag, delta := range deltas {
+		counts[tag] = max(counts[tag]+delta, 0)
+	}
+	return s.saveCounts(ctx, uid, collection, counts)
+}
+
+// ListTags returns a collection's tags sorted by usage, most used first
+func (s *TagService) ListTags(ctx context.Context, uid, collection string) ([]TagCount, error) {
+	if !taggedCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	counts, found, err := s.loadCounts(ctx, uid, collection)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if counts, err = s.RebuildCounts(ctx, uid, collection); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		if count > 0 {
+			tags = append(tags, TagCount{Tag: tag, Count: count})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// RebuildCounts recounts a collection's tags from its documents, repairing
+// counts that drifted through writes made outside the API
+func (s *TagService) RebuildCounts(ctx context.Context, uid, collection string) (map[string]int, error) {
+	if !taggedCollections[collection] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+
+	docs, err := s.repo.List(ctx, fmt.Sprintf("users/%s/%s", uid, collection), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", collection, err)
+	}
+
+	counts := map[string]int{}
+	for _, doc := range docs {
+		for _, tag := range documentTags(doc) {
+			counts[tag]++
+		}
+	}
+	if err := s.saveCounts(ctx, uid, collection, counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// RenameTag replaces from with to on every document in the collection that
+// uses it, merging the two tags where a document already has both. Returns
+// the number of documents updated.
+func (s *TagService) RenameTag(ctx context.Context, uid, collection, from, to string) (int, error) {
+	if !taggedCollections[collection] {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCollection, collection)
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return 0, fmt.Errorf("%w: from and to are required", ErrInvalidTag)
+	}
+	if len(to) > MaxTagLength {
+		return 0, fmt.Errorf("%w: tags must be at most %d characters", ErrInvalidTag, MaxTagLength)
+	}
+	if from == to {
+		return 0, fmt.Errorf("%w: new name must differ", ErrInvalidTag)
+	}
+
+	collectionPath := fmt.Sprintf("users/%s/%s", uid, collection)
+	docs, err := s.repo.ListWhere(ctx, collectionPath, []repository.Filter{
+		{Field: "tags", Op: "array-contains", Value: from},
+	}, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find documents tagged %q: %w", from, err)
+	}
+
+	now := time.Now()
+	renamed, added := 0, 0
+	for _, doc := range docs {
+		id, _ := doc["id"].(string)
+		if id == "" {
+			continue
+		}
+
+		tags := toStringSlice(doc["tags"])
+		hadTarget := false
+		updated := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if tag == to {
+				hadTarget = true
+			}
+			if tag == from {
+				tag = to
+			}
+			updated = append(updated, tag)
+		}
+
+		if err := s.repo.Update(ctx, fmt.Sprintf("%s/%s", collectionPath, id), map[string]interface{}{
+			"tags":      dedupeStrings(updated),
+			"updatedAt": now,
+		}); err != nil {
+			return renamed, fmt.Errorf("failed to rename tag on %s: %w", id, err)
+		}
+		renamed++
+		if !hadTarget {
+			added++
+		}
+	}
+
+	counts, found, err := s.loadCounts(ctx, uid, collection)
+	if err != nil {
+		return renamed, err
+	}
+	if found {
+		counts[to] += added
+		counts[from] = 0
+		err = s.saveCounts(ctx, uid, collection, counts)
+	} else {
+		_, err = s.RebuildCounts(ctx, uid, collection)
+	}
+	if err != nil {
+		return renamed, err
+	}
+
+	s.logger.Info("Tag renamed",
+		zap.String("uid", uid),
+		zap.String("collection", collection),
+		zap.String("from", from),
+		zap.String("to", to),
+		zap.Int("documents", renamed),
+	)
+	return renamed, nil
+}
+
+// loadCounts reads a collection's tag counts; found is false when none are stored yet
+func (s *TagService) loadCounts(ctx context.Context, uid, collection string) (map[string]int, bool, error) {
+	data, err := s.repo.Get(ctx, tagCountsPath(uid, collection))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return map[string]int{}, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to load tag counts: %w", err)
+	}
+
+	counts := map[string]int{}
+	stored, _ := data["counts"].(map[string]interface{})
+	for tag, value := range stored {
+		switch n := value.(type) {
+		case int:
+			counts[tag] = n
+		case int64:
+			counts[tag] = int(n)
+		case float64:
+			counts[tag] = int(n)
+		}
+	}
+	return counts, true, nil
+}
+
+// saveCounts writes the full count map. Unused tags are kept with a zero count
+// because a merging write cannot remove map keys.
+func (s *TagService) saveCounts(ctx context.Context, uid, collection string, counts map[string]int) error {
+	stored := make(map[string]interface{}, len(counts))
+	for tag, count := range counts {
+		stored[tag] = count
+	}
+	if err := s.repo.SetDocument(ctx, tagCountsPath(uid, collection), map[string]interface{}{
+		"collection": collection,
+		"counts":     stored,
+		"updatedAt":  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to save tag counts: %w", err)
+	}
+	return nil
+}
+
+// documentTags returns the trimmed, de-duplicated tags of a document
+func documentTags(doc map[string]interface{}) []string {
+	if doc == nil {
+		return nil
+	}
+	tags := []string{}
+	for _, tag := range toStringSlice(doc["tags"]) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return dedupeStrings(tags)
+}
+
+func tagCountsPath(uid, collection string) string {
+	return fmt.Sprintf("users/%s/tagCounts/%s", uid, collection)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestTagService_ListTagsRebuildsAndSortsByUsage(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"tags": []interface{}{"work", "urgent"}})
+	repo.AddDocument("users/user1/tasks/t2", map[string]interface{}{"tags": []interface{}{"work", " work "}})
+	repo.AddDocument("users/user1/tasks/t3", map[string]interface{}{"tags": []interface{}{"home", "urgent", "work"}})
+	repo.AddDocument("users/user1/tasks/t4", map[string]interface{}{"title": "untagged"})
+	svc := NewTagService(repo, zap.NewNop())
+
+	tags, err := svc.ListTags(context.Background(), "user1", "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{
+		{Tag: "work", Count: 3},
+		{Tag: "urgent", Count: 2},
+		{Tag: "home", Count: 1},
+	}, tags)
+	assert.Contains(t, repo.Documents, "users/user1/tagCounts/tasks")
+
+	_, err = svc.ListTags(context.Background(), "user1", "goals")
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
+
+func TestTagService_RecordChange(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/notes/n1", map[string]interface{}{"tags": []interface{}{"ideas"}})
+	svc := NewTagService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.ListTags(ctx, "user1", "notes")
+	require.NoError(t, err)
+
+	// Created, updated, then deleted
+	created := map[string]interface{}{"tags": []interface{}{"ideas", "books"}}
+	require.NoError(t, svc.RecordChange(ctx, "user1", "notes", nil, created))
+	updated := map[string]interface{}{"tags": []interface{}{"books", "reading"}}
+	require.NoError(t, svc.RecordChange(ctx, "user1", "notes", created, updated))
+
+	tags, err := svc.ListTags(ctx, "user1", "notes")
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "books", Count: 1}, {Tag: "ideas", Count: 1}, {Tag: "reading", Count: 1}}, tags)
+
+	require.NoError(t, svc.RecordChange(ctx, "user1", "notes", updated, nil))
+	tags, err = svc.ListTags(ctx, "user1", "notes")
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "ideas", Count: 1}}, tags)
+}
+
+func TestTagService_RecordChangeIgnoresUntrackedWrites(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewTagService(repo, zap.NewNop())
+	doc := map[string]interface{}{"tags": []interface{}{"work"}}
+
+	// No counts stored yet: left for the first ListTags to rebuild
+	require.NoError(t, svc.RecordChange(context.Background(), "user1", "tasks", nil, doc))
+	assert.NotContains(t, repo.Documents, "users/user1/tagCounts/tasks")
+
+	require.NoError(t, svc.RecordChange(context.Background(), "user1", "projects", nil, doc))
+
+	var nilService *TagService
+	assert.NoError(t, nilService.RecordChange(context.Background(), "user1", "tasks", nil, doc))
+}
+
+func TestTagService_RenameTagPropagates(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/thoughts/a", map[string]interface{}{"tags": []interface{}{"wrok", "ideas"}})
+	repo.AddDocument("users/user1/thoughts/b", map[string]interface{}{"tags": []interface{}{"work", "wrok"}})
+	repo.AddDocument("users/user1/thoughts/c", map[string]interface{}{"tags": []interface{}{"ideas"}})
+	repo.AddDocument("users/user2/thoughts/d", map[string]interface{}{"tags": []interface{}{"wrok"}})
+	svc := NewTagService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.ListTags(ctx, "user1", "thoughts")
+	require.NoError(t, err)
+
+	updated, err := svc.RenameTag(ctx, "user1", "thoughts", "wrok", "work")
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	assert.Equal(t, []string{"work", "ideas"}, repo.Documents["users/user1/thoughts/a"]["tags"])
+	assert.Equal(t, []string{"work"}, repo.Documents["users/user1/thoughts/b"]["tags"])
+	assert.Equal(t, []interface{}{"ideas"}, repo.Documents["users/user1/thoughts/c"]["tags"])
+	assert.Equal(t, []interface{}{"wrok"}, repo.Documents["users/user2/thoughts/d"]["tags"])
+
+	tags, err := svc.ListTags(ctx, "user1", "thoughts")
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "ideas", Count: 2}, {Tag: "work", Count: 2}}, tags)
+
+	// Counts stay consistent with a full recount
+	rebuilt, err := svc.RebuildCounts(ctx, "user1", "thoughts")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ideas": 2, "work": 2}, rebuilt)
+}
+
+func TestTagService_RenameTagInvalid(t *testing.T) {
+	svc := NewTagService(mocks.NewMockRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.RenameTag(ctx, "user1", "tasks", "", "work")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = svc.RenameTag(ctx, "user1", "tasks", "work", " work ")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = svc.RenameTag(ctx, "user1", "trips", "a", "b")
+	assert.ErrorIs(t, err, ErrUnsupportedCollection)
+}
+
+func TestDocumentService_DuplicateRecordsTags(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	repo.AddDocument("users/user1/tasks/task1", map[string]interface{}{"id": "task1", "tags": []interface{}{"weekly"}})
+	tags := NewTagService(repo, zap.NewNop())
+	svc := NewDocumentService(repo, zap.NewNop(), nil, tags, nil, nil)
+	ctx := context.Background()
+
+	_, err := tags.ListTags(ctx, "user1", "tasks")
+	require.NoError(t, err)
+
+	_, err = svc.Duplicate(ctx, "user1", "tasks", "task1", nil)
+	require.NoError(t, err)
+
+	counts, err := tags.ListTags(ctx, "user1", "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "weekly", Count: 2}}, counts)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// MaxBulkTaskUpdates is the maximum number of tasks in a bulk update (Firestore batch limit)
+const MaxBulkTaskUpdates = 500
+
+// ErrInvalidTask is returned for task create requests that fail validation
+var ErrInvalidTask = errors.New("invalid task")
+
+// AIAccessChecker decides whether a user may make AI requests and records
+// their usage; SubscriptionService implements it
+type AIAccessChecker interface {
+	IsAIAllowed(ctx context.Context, uid string, isAnonymous bool) (bool, string, error)
+	IncrementUsage(ctx context.Context, uid string, tokensUsed int) error
+}
+
+// TaskService handles task operations
+type TaskService struct {
+	repo     interfaces.Repository
+	logger   *zap.Logger
+	aiClient *clients.OpenAIClient
+	access   AIAccessChecker
+	llmLogs  LLMLogRecorder
+}
+
+// NewTaskService creates a new task service. aiClient and access may be nil,
+// in which case tasks are created without AI enrichment; enrichment calls are
+// written to llmLogs unless it is nil.
+func NewTaskService(repo interfaces.Repository, logger *zap.Logger, aiClient *clients.OpenAIClient, access AIAccessChecker, llmLogs LLMLogRecorder) *TaskService {
+	return &TaskService{
+		repo:     repo,
+		logger:   logger,
+		aiClient: aiClient,
+		access:   access,
+		llmLogs:  llmLogs,
+	}
+}
+
+// CreateTask creates a task from the given fields. title is required;
+// server-managed fields in the input are ignored. With enrich, missing
+// category, priority and estimatedMinutes are suggested by the AI from the
+// title when the user has AI access; fields the user provided are never
+// replaced, and any AI failure falls back to the plain create. enriched
+// reports whether AI suggestions were applied.
+func (s *TaskService) CreateTask(ctx context.Context, uid string, fields map[string]interface{}, enrich bool) (task map[string]interface{}, enriched bool, err error) {
+	title, _ := fields["title"].(string)
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, false, fmt.Errorf("%w: title is required", ErrInvalidTask)
+	}
+
+	task = make(map[string]interface{}, len(fields)+8)
+	for key, value := range fields {
+		task[key] = value
+	}
+	for _, field := range protectedDocumentFields {
+		delete(task, field)
+	}
+	task["title"] = title
+
+	if enrich {
+		enriched = s.enrichTask(ctx, uid, task)
+	}
+
+	setDefault(task, "done", false)
+	setDefault(task, "status", "active")
+	setDefault(task, "priority", "medium")
+	setDefault(task, "focusEligible", true)
+
+	id := uuid.New().String()
+	now := time.Now()
+	task["id"] = id
+	task["createdAt"] = now
+	task["updatedAt"] = now
+	task["updatedBy"] = uid
+	task["version"] = 1
+
+	if err := s.repo.Create(ctx, fmt.Sprintf("users/%s/tasks/%s", uid, id), task); err != nil {
+		return nil, false, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	s.logger.Info("Task created",
+		zap.String("uid", uid),
+		zap.String("taskId", id),
+		zap.Bool("aiEnriched", enriched),
+	)
+	return task, enriched, nil
+}
+
+// setDefault sets doc[field] to value unless the field is already present
+func setDefault(doc map[string]interface{}, field string, value interface{}) {
+	if _, ok := doc[field]; !ok {
+		doc[field] = value
+	}
+}
+
+// TaskStatusResult is the outcome of a status update for a single task
+type TaskStatusResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkUpdateStatus marks tasks done or reopens them in a single batch.
+// Completing stamps completedAt with the server time; reopening clears it.
+func (s *TaskService) BulkUpdateStatus(ctx context.Context, uid string, ids []string, done bool) ([]TaskStatusResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one task id is required")
+	}
+	if len(ids) > MaxBulkTaskUpdates {
+		return nil, fmt.Errorf("too many tasks: maximum is %d", MaxBulkTaskUpdates)
+	}
+
+	results := make([]TaskStatusResult, 0, len(ids))
+	pending := []int{}
+	seen := make(map[string]bool)
+	batch := s.repo.Batch()
+	updates := taskStatusUpdates(done, time.Now())
+
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		path := fmt.Sprintf("users/%s/tasks/%s", uid, id)
+		if _, err := s.repo.Get(ctx, path); err != nil {
+			result := TaskStatusResult{ID: id, Error: "failed to load task"}
+			if errors.Is(err, interfaces.ErrNotFound) {
+				result.Error = "task not found"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		batch.Update(s.repo.Client().Doc(path), updates)
+		results = append(results, TaskStatusResult{ID: id})
+		pending = append(pending, len(results)-1)
+	}
+
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	if _, err := batch.Commit(ctx); err != nil {
+		s.logger.Error("Bulk task status update failed",
+			zap.String("uid", uid),
+			zap.Int("count", len(pending)),
+			zap.Error(err),
+		)
+		for _, i := range pending {
+			results[i].Error = "failed to update task"
+		}
+		return results, nil
+	}
+
+	for _, i := range pending {
+		results[i].Success = true
+	}
+
+	s.logger.Info("Bulk task status updated",
+		zap.String("uid", uid),
+		zap.Bool("done", done),
+		zap.Int("updated", len(pending)),
+	)
+
+	return results, nil
+}
+
+// taskStatusUpdates builds the field updates for completing or reopening a task.
+// completedAt is stored as a timestamp so analytics can read it back as time.Time.
+func taskStatusUpdates(done bool, now time.Time) []firestore.Update {
+	if done {
+		return []firestore.Update{
+			{Path: "done", Value: true},
+			{Path: "status", Value: "completed"},
+			{Path: "completedAt", Value: now},
+			{Path: "updatedAt", Value: now},
+		}
+	}
+	return []firestore.Update{
+		{Path: "done", Value: false},
+		{Path: "status", Value: "active"},
+		{Path: "completedAt", Value: firestore.Delete},
+		{Path: "updatedAt", Value: now},
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// maxTaskCategories caps the categories a user can configure
+const maxTaskCategories = 20
+
+// DefaultTaskCategories are the CBT mastery/pleasure buckets used until a
+// user configures their own
+var DefaultTaskCategories = []string{"mastery", "pleasure"}
+
+// ErrInvalidTaskCategories is returned for a category set that fails validation
+var ErrInvalidTaskCategories = errors.New("invalid task categories")
+
+// taskCategoryPattern keeps category names usable as analytics keys
+var taskCategoryPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// reservedTaskCategories are keys the per-day analytics already use
+var reservedTaskCategories = map[string]bool{"date": true, "total": true}
+
+// TaskCategorySettings is a user's task category set. Tasks without a
+// category count towards the first one.
+type TaskCategorySettings struct {
+	Categories []string `json:"categories"`
+}
+
+// TaskCategoryService keeps users' task categories at
+// users/{uid}/settings/taskCategories
+type TaskCategoryService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+}
+
+// NewTaskCategoryService creates a new task category service
+func NewTaskCategoryService(repo interfaces.Repository, logger *zap.Logger) *TaskCategoryService {
+	return &TaskCategoryService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetCategories returns the user's task categories, or the defaults
+func (s *TaskCategoryService) GetCategories(ctx context.Context, uid string) (TaskCategorySettings, error) {
+	categories, err := loadTaskCategories(ctx, s.repo, uid)
+	return TaskCategorySettings{Categories: categories}, err
+}
+
+// UpdateCategories validates and stores the user's task categories. Names
+// are lowercased and deduplicated, keeping their order.
+func (s *TaskCategoryService) UpdateCategories(ctx context.Context, uid string, settings TaskCategorySettings) (TaskCategorySettings, error) {
+	categories := make([]string, 0, len(settings.Categories))
+	for _, category := range settings.Categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !taskCategoryPattern.MatchString(category) || reservedTaskCategories[category] {
+			return settings, fmt.Errorf("%w: %q is not a valid category name", ErrInvalidTaskCategories, category)
+		}
+		categories = append(categories, category)
+	}
+	categories = dedupeStrings(categories)
+	if len(categories) == 0 || len(categories) > maxTaskCategories {
+		return settings, fmt.Errorf("%w: between 1 and %d categories are required", ErrInvalidTaskCategories, maxTaskCategories)
+	}
+
+	if err := s.repo.SetDocument(ctx, taskCategoriesPath(uid), map[string]interface{}{
+		"categories": toInterfaceSlice(categories),
+		"updatedAt":  time.Now(),
+	}); err != nil {
+		return settings, fmt.Errorf("failed to save task categories: %w", err)
+	}
+	return TaskCategorySettings{Categories: categories}, nil
+}
+
+// loadTaskCategories reads the user's task categories, falling back to
+// DefaultTaskCategories when none are stored
+func loadTaskCategories(ctx context.Context, repo interfaces.Repository, uid string) ([]string, error) {
+	doc, err := repo.Get(ctx, taskCategoriesPath(uid))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrNotFound) {
+			return slices.Clone(DefaultTaskCategories), nil
+		}
+		return nil, fmt.Errorf("failed to load task categories: %w", err)
+	}
+	if categories := toStringSlice(doc["categories"]); len(categories) > 0 {
+		return categories, nil
+	}
+	return slices.Clone(DefaultTaskCategories), nil
+}
+
+func taskCategoriesPath(uid string) string {
+	return fmt.Sprintf("users/%s/settings/taskCategories", uid)
+}
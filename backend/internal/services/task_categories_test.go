@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestTaskCategoryService_UpdateCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		want       []string
+		wantErr    bool
+	}{
+		{"lowercased and deduped", []string{" Health", "wealth", "HEALTH", "deep-work"}, []string{"health", "wealth", "deep-work"}, false},
+		{"empty", []string{}, nil, true},
+		{"reserved name", []string{"date"}, nil, true},
+		{"invalid name", []string{"two words"}, nil, true},
+		{"too many", make([]string, maxTaskCategories+1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTaskCategoryService(mocks.NewMockRepository(), zap.NewNop())
+
+			got, err := service.UpdateCategories(context.Background(), "user-1", TaskCategorySettings{Categories: tt.categories})
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidTaskCategories), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Categories)
+
+			stored, err := service.GetCategories(context.Background(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stored.Categories)
+		})
+	}
+}
+
+func TestTaskCategoryService_GetCategories_Defaults(t *testing.T) {
+	service := NewTaskCategoryService(mocks.NewMockRepository(), zap.NewNop())
+
+	got, err := service.GetCategories(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTaskCategories, got.Categories)
+}
+
+func TestDashboardAnalyticsService_ComputeAnalytics_CustomCategories(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	uid := "user-1"
+	ctx := context.Background()
+
+	_, err := NewTaskCategoryService(repo, zap.NewNop()).UpdateCategories(ctx, uid, TaskCategorySettings{
+		Categories: []string{"health", "wealth", "connection"},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for id, category := range map[string]interface{}{
+		"t1": "health",
+		"t2": "health",
+		"t3": "connection",
+		"t4": "mastery", // not in the set
+		"t5": nil,       // counts towards the first category
+	} {
+		task := map[string]interface{}{"status": "completed", "completedAt": now}
+		if category != nil {
+			task["category"] = category
+		}
+		repo.AddDocument("users/"+uid+"/tasks/"+id, task)
+	}
+
+	analytics, err := NewDashboardAnalyticsService(repo, zap.NewNop(), nil).ComputeAnalytics(ctx, uid, PeriodToday)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"health", "wealth", "connection"}, analytics.TaskCategories)
+	assert.Equal(t, map[string]int{"health": 3, "wealth": 0, "connection": 1}, analytics.Stats.Categories)
+	assert.Zero(t, analytics.Stats.MasteryTasks)
+	assert.Zero(t, analytics.Stats.PleasureTasks)
+
+	today := analytics.CategoryData[len(analytics.CategoryData)-1]
+	assert.Equal(t, 3, today["health"])
+	assert.Equal(t, 0, today["wealth"])
+	assert.Equal(t, 1, today["connection"])
+	assert.NotContains(t, today, "mastery")
+
+	taskDay := analytics.TaskData[len(analytics.TaskData)-1]
+	assert.Equal(t, 5, taskDay["total"])
+	assert.Equal(t, 3, taskDay["health"])
+}
+
+func TestDashboardAnalyticsService_ComputeAnalytics_DefaultCategories(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	uid := "user-1"
+	repo.AddDocument("users/"+uid+"/tasks/t1", map[string]interface{}{"status": "completed", "completedAt": time.Now(), "category": "pleasure"})
+	repo.AddDocument("users/"+uid+"/tasks/t2", map[string]interface{}{"status": "completed", "completedAt": time.Now()})
+
+	analytics, err := NewDashboardAnalyticsService(repo, zap.NewNop(), nil).ComputeAnalytics(context.Background(), uid, PeriodToday)
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultTaskCategories, analytics.TaskCategories)
+	assert.Equal(t, 1, analytics.Stats.MasteryTasks)
+	assert.Equal(t, 1, analytics.Stats.PleasureTasks)
+	assert.Equal(t, map[string]int{"mastery": 1, "pleasure": 1}, analytics.Stats.Categories)
+}
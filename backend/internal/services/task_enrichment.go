@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+)
+
+// maxEnrichedEstimateMinutes caps an AI time estimate at a full working day
+const maxEnrichedEstimateMinutes = 480
+
+// taskEnrichmentFields are the optional task fields the AI may fill in
+var taskEnrichmentFields = []string{"category", "priority", "estimatedMinutes"}
+
+var (
+	validTaskCategories = map[string]bool{"mastery": true, "pleasure": true}
+	validTaskPriorities = map[string]bool{"low": true, "medium": true, "high": true, "urgent": true}
+)
+
+// enrichTask fills the task's missing enrichment fields with AI suggestions
+// and marks it aiEnriched. Returns false, leaving the task untouched, when
+// nothing is missing, AI is unavailable or not allowed, or the call fails.
+func (s *TaskService) enrichTask(ctx context.Context, uid string, task map[string]interface{}) bool {
+	var missing []string
+	for _, field := range taskEnrichmentFields {
+		if value, ok := task[field]; !ok || value == nil || value == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) == 0 || s.aiClient == nil || s.access == nil {
+		return false
+	}
+
+	isAnonymous, _ := ctx.Value("isAnonymous").(bool)
+	allowed, reason, err := s.access.IsAIAllowed(ctx, uid, isAnonymous)
+	if err != nil || !allowed {
+		s.logger.Info("Skipping task enrichment",
+			zap.String("uid", uid),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	title, _ := task["title"].(string)
+	response, err := s.aiClient.ChatCompletion(ctx, clients.ChatCompletionRequest{
+		Messages: []clients.ChatMessage{
+			{Role: "system", Content: taskEnrichmentPrompt},
+			{Role: "user", Content: title},
+		},
+		Temperature:    0.2,
+		MaxTokens:      100,
+		ResponseFormat: &clients.ResponseFormat{Type: "json_object"},
+	})
+	s.recordEnrichmentLog(ctx, uid, title, response, err)
+	if err != nil {
+		s.logger.Warn("Task enrichment failed", zap.String("uid", uid), zap.Error(err))
+		return false
+	}
+	if err := s.access.IncrementUsage(ctx, uid, response.TokensUsed); err != nil {
+		s.logger.Warn("Failed to record task enrichment usage", zap.String("uid", uid), zap.Error(err))
+	}
+
+	suggestions, err := parseTaskEnrichment(response.Content)
+	if err != nil {
+		s.logger.Warn("Unusable task enrichment response", zap.String("uid", uid), zap.Error(err))
+		return false
+	}
+
+	var filled []interface{}
+	for _, field := range missing {
+		if value, ok := suggestions[field]; ok {
+			task[field] = value
+			filled = append(filled, field)
+		}
+	}
+	if len(filled) == 0 {
+		return false
+	}
+	task["aiEnriched"] = true
+	task["aiEnrichedFields"] = filled
+	return true
+}
+
+// recordEnrichmentLog logs an enrichment call. Failures to log are not fatal.
+func (s *TaskService) recordEnrichmentLog(ctx context.Context, uid, title string, response *clients.ChatCompletionResponse, callErr error) {
+	if s.llmLogs == nil {
+		return
+	}
+	entry := LLMLogEntry{
+		Feature:    AIFeatureTaskEnrichment,
+		Trigger:    "api",
+		PromptType: "task-enrichment",
+		Prompt:     title,
+		Status:     "completed",
+	}
+	if callErr != nil {
+		entry.Status = "failed"
+		entry.Error = callErr.Error()
+	}
+	if response != nil {
+		entry.Model = response.Model
+		entry.RawResponse = response.Content
+		entry.TotalTokens = response.TokensUsed
+	}
+	if _, err := s.llmLogs.Record(ctx, uid, entry); err != nil {
+		s.logger.Warn("Failed to record LLM log", zap.String("uid", uid), zap.Error(err))
+	}
+}
+
+const taskEnrichmentPrompt = `You are a task enrichment assistant for a personal productivity app.
+Given a task title, suggest:
+- "category": "mastery" for work, learning or chores that build capability, "pleasure" for enjoyable or restful activities
+- "priority": one of "low", "medium", "high", "urgent"
+- "estimatedMinutes": a whole number of minutes the task is likely to take
+
+Respond with a JSON object with exactly these keys.`
+
+// parseTaskEnrichment validates an AI enrichment response, keeping only
+// well-formed suggestions
+func parseTaskEnrichment(content string) (map[string]interface{}, error) {
+	var raw struct {
+		Category         string      `json:"category"`
+		Priority         string      `json:"priority"`
+		EstimatedMinutes json.Number `json:"estimatedMinutes"`
+	}
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	suggestions := make(map[string]interface{})
+	if category := strings.ToLower(strings.TrimSpace(raw.Category)); validTaskCategories[category] {
+		suggestions["category"] = category
+	}
+	if priority := strings.ToLower(strings.TrimSpace(raw.Priority)); validTaskPriorities[priority] {
+		suggestions["priority"] = priority
+	}
+	if minutes, err := raw.EstimatedMinutes.Float64(); err == nil && minutes >= 1 {
+		suggestions["estimatedMinutes"] = int(math.Min(math.Round(minutes), maxEnrichedEstimateMinutes))
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxQuickAddLength caps the length of quick-add input in characters
+	MaxQuickAddLength = 500
+	// quickAddTrailingPunct is stripped from words before they are matched
+	quickAddTrailingPunct = ",.;!?"
+)
+
+// QuickAddParse is what quick-add input was parsed into. DueDate is
+// YYYY-MM-DD in the user's timezone and DueTime is HH:MM when a time was
+// given; both are empty when no date or time was found.
+type QuickAddParse struct {
+	Title    string   `json:"title"`
+	DueDate  string   `json:"dueDate,omitempty"`
+	DueTime  string   `json:"dueTime,omitempty"`
+	Tags     []string `json:"tags"`
+	Priority string   `json:"priority,omitempty"`
+}
+
+var (
+	quickAddTimePattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	quickAdd24HourPattern  = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+	quickAddDayOfMonth     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	quickAddDatePrefixes   = map[string]bool{"on": true, "by": true, "due": true}
+	quickAddPriorityTokens = map[string]string{
+		"!urgent": "urgent", "!high": "high", "!medium": "medium", "!low": "low",
+		"p1": "urgent", "p2": "high", "p3": "medium", "p4": "low",
+	}
+	quickAddPriorityWords = map[string]bool{"urgent": true, "high": true, "medium": true, "low": true}
+	quickAddWeekdays      = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+	quickAddMonths = map[string]time.Month{
+		"jan": time.January, "january": time.January,
+		"feb": time.February, "february": time.February,
+		"mar": time.March, "march": time.March,
+		"apr": time.April, "april": time.April,
+		"may": time.May,
+		"jun": time.June, "june": time.June,
+		"jul": time.July, "july": time.July,
+		"aug": time.August, "august": time.August,
+		"sep": time.September, "sept": time.September, "september": time.September,
+		"oct": time.October, "october": time.October,
+		"nov": time.November, "november": time.November,
+		"dec": time.December, "december": time.December,
+	}
+)
+
+// QuickAdd parses natural-language input with ParseQuickAdd and creates the
+// task. timezone is an IANA name for relative dates; empty means UTC.
+func (s *TaskService) QuickAdd(ctx context.Context, uid, text, timezone string) (map[string]interface{}, *QuickAddParse, error) {
+	if len([]rune(text)) > MaxQuickAddLength {
+		return nil, nil, fmt.Errorf("%w: input must be at most %d characters", ErrInvalidTask, MaxQuickAddLength)
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+
+	parsed := ParseQuickAdd(text, time.Now().In(loc))
+	fields := map[string]interface{}{"title": parsed.Title}
+	setIfNotEmpty(fields, "dueDate", parsed.DueDate)
+	setIfNotEmpty(fields, "dueTime", parsed.DueTime)
+	setIfNotEmpty(fields, "priority", parsed.Priority)
+	if len(parsed.Tags) > 0 {
+		fields["tags"] = toInterfaceSlice(parsed.Tags)
+	}
+
+	task, _, err := s.CreateTask(ctx, uid, fields, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, &parsed, nil
+}
+
+// ParseQuickAdd parses input like "call mom tomorrow 3pm #family high" into
+// task fields without the AI. Relative dates are resolved against now, in
+// its location. Recognised, and removed from the title, are:
+//   - #tags
+//   - today, tonight, tomorrow, weekdays ("friday", "on fri", "next fri"), "in 3
+//     days", "in 2 weeks", "next week", YYYY-MM-DD and "oct 20"/"20 oct",
+//     optionally after "on", "by" or "due"
+//   - times as 3pm, 3:30pm, 15:00, noon or midnight, optionally after "at";
+//     a time without a date is today, or tomorrow once it has passed
+//   - priorities as !high, p1-p4, "high priority", or a trailing priority word
+//
+// Only the first date and time are used; later ones stay in the title.
+func ParseQuickAdd(input string, now time.Time) QuickAddParse {
+	tokens := strings.Fields(input)
+	used := make([]bool, len(tokens))
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = strings.ToLower(strings.TrimRight(token, quickAddTrailingPunct))
+	}
+
+	parsed := QuickAddParse{Tags: []string{}}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var due *time.Time
+	hour, minute := -1, 0
+
+	for i := 0; i < len(tokens); i++ {
+		word := words[i]
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			parsed.Tags = append(parsed.Tags, word[1:])
+			used[i] = true
+			continue
+		case quickAddPriorityTokens[word] != "" && parsed.Priority == "":
+			parsed.Priority = quickAddPriorityTokens[word]
+			used[i] = true
+			continue
+		case quickAddPriorityWords[word] && i+1 < len(tokens) && words[i+1] == "priority" && parsed.Priority == "":
+			parsed.Priority = word
+			used[i], used[i+1] = true, true
+			i++
+			continue
+		}
+
+		if due == nil {
+			prefixed := i > 0 && quickAddDatePrefixes[words[i-1]]
+			if day, n := parseQuickAddDate(words[i:], today, prefixed); n > 0 {
+				due = &day
+				markQuickAddUsed(used, i, n, words, quickAddDatePrefixes)
+				i += n - 1
+				continue
+			}
+		}
+		if hour < 0 {
+			if h, m, n := parseQuickAddTime(words[i:]); n > 0 {
+				hour, minute = h, m
+				markQuickAddUsed(used, i, n, words, map[string]bool{"at": true})
+				i += n - 1
+				continue
+			}
+		}
+	}
+
+	// A bare priority word counts only at the end of what is left, so titles
+	// like "high school reunion" keep it
+	if parsed.Priority == "" {
+		for i := len(tokens) - 1; i >= 0; i-- {
+			if used[i] {
+				continue
+			}
+			if quickAddPriorityWords[words[i]] {
+				parsed.Priority = words[i]
+				used[i] = true
+			}
+			break
+		}
+	}
+
+	if hour >= 0 {
+		if due == nil {
+			at := time.Date(today.Year(), today.Month(), today.Day(), hour, minute, 0, 0, today.Location())
+			day := today
+			if !at.After(now) {
+				day = today.AddDate(0, 0, 1)
+			}
+			due = &day
+		}
+		parsed.DueTime = fmt.Sprintf("%02d:%02d", hour, minute)
+	}
+	if due != nil {
+		parsed.DueDate = due.Format("2006-01-02")
+	}
+
+	var title []string
+	for i, token := range tokens {
+		if !used[i] {
+			title = append(title, token)
+		}
+	}
+	parsed.Title = strings.TrimRight(strings.Join(title, " "), quickAddTrailingPunct+" ")
+	parsed.Tags = dedupeStrings(parsed.Tags)
+	return parsed
+}
+
+// markQuickAddUsed marks n tokens from start as parsed, along with a
+// preceding connective like "on" or "at" that is not already used
+func markQuickAddUsed(used []bool, start, n int, words []string, connectives map[string]bool) {
+	for j := start; j < start+n; j++ {
+		used[j] = true
+	}
+	if start > 0 && !used[start-1] && connectives[words[start-1]] {
+		used[start-1] = true
+	}
+}
+
+// parseQuickAddDate parses a date phrase at the start of words, returning the
+// day and how many words it spans (0 when there is none). Weekday
+// abbreviations like "sun" or "sat" only count when prefixed, e.g. "on sat".
+func parseQuickAddDate(words []string, today time.Time, prefixed bool) (time.Time, int) {
+	next := func(i int) string {
+		if i < len(words) {
+			return words[i]
+		}
+		return ""
+	}
+
+	switch word := words[0]; word {
+	case "today", "tonight":
+		return today, 1
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), 1
+	case "next", "this":
+		if next(1) == "week" && word == "next" {
+			return today.AddDate(0, 0, 7), 2
+		}
+		if weekday, ok := quickAddWeekdays[next(1)]; ok {
+			day := upcomingWeekday(today, weekday)
+			if word == "next" {
+				day = day.AddDate(0, 0, 7)
+			}
+			return day, 2
+		}
+		return time.Time{}, 0
+	case "in":
+		count, err := strconv.Atoi(next(1))
+		if next(1) == "a" || next(1) == "an" {
+			count, err = 1, nil
+		}
+		if err != nil || count < 1 || count > 365 {
+			return time.Time{}, 0
+		}
+		switch strings.TrimSuffix(next(2), "s") {
+		case "day":
+			return today.AddDate(0, 0, count), 3
+		case "week":
+			return today.AddDate(0, 0, 7*count), 3
+		}
+		return time.Time{}, 0
+	}
+
+	if weekday, ok := quickAddWeekdays[words[0]]; ok && (prefixed || strings.HasSuffix(words[0], "day")) {
+		return upcomingWeekday(today, weekday), 1
+	}
+	if day, err := time.ParseInLocation("2006-01-02", words[0], today.Location()); err == nil {
+		return day, 1
+	}
+	// "oct 20" or "20 oct"
+	if month, ok := quickAddMonths[words[0]]; ok {
+		if match := quickAddDayOfMonth.FindStringSubmatch(next(1)); match != nil {
+			if day, ok := upcomingMonthDay(today, month, match[1]); ok {
+				return day, 2
+			}
+		}
+	}
+	if match := quickAddDayOfMonth.FindStringSubmatch(words[0]); match != nil {
+		if month, ok := quickAddMonths[next(1)]; ok {
+			if day, ok := upcomingMonthDay(today, month, match[1]); ok {
+				return day, 2
+			}
+		}
+	}
+	return time.Time{}, 0
+}
+
+// parseQuickAddTime parses a time at the start of words, returning the hour,
+// minute and how many words it spans (0 when there is none)
+func parseQuickAddTime(words []string) (int, int, int) {
+	switch words[0] {
+	case "noon":
+		return 12, 0, 1
+	case "midnight":
+		return 0, 0, 1
+	}
+	if match := quickAdd24HourPattern.FindStringSubmatch(words[0]); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		return hour, minute, 1
+	}
+
+	word, n := words[0], 1
+	if len(words) > 1 && (words[1] == "am" || words[1] == "pm") {
+		word, n = word+words[1], 2
+	}
+	match := quickAddTimePattern.FindStringSubmatch(word)
+	if match == nil {
+		return 0, 0, 0
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	if hour < 1 || hour > 12 || minute > 59 {
+		return 0, 0, 0
+	}
+	hour %= 12
+	if match[3] == "pm" {
+		hour += 12
+	}
+	return hour, minute, n
+}
+
+// upcomingWeekday returns the next day on or after today that is weekday
+func upcomingWeekday(today time.Time, weekday time.Weekday) time.Time {
+	return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+}
+
+// upcomingMonthDay returns the next occurrence on or after today of the day
+// of month, rolling over to next year once it has passed
+func upcomingMonthDay(today time.Time, month time.Month, dayOfMonth string) (time.Time, bool) {
+	day, _ := strconv.Atoi(dayOfMonth)
+	date := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if date.Month() != month || day < 1 {
+		return time.Time{}, false
+	}
+	if date.Before(today) {
+		date = time.Date(today.Year()+1, month, day, 0, 0, 0, 0, today.Location())
+		if date.Month() != month {
+			return time.Time{}, false
+		}
+	}
+	return date, true
+}
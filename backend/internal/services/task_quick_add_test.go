@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+	// A Wednesday afternoon
+	now := time.Date(2026, 10, 14, 14, 0, 0, 0, toronto)
+
+	tests := []struct {
+		input string
+		want  QuickAddParse
+	}{
+		{
+			input: "call mom tomorrow 3pm #family high",
+			want:  QuickAddParse{Title: "call mom", DueDate: "2026-10-15", DueTime: "15:00", Tags: []string{"family"}, Priority: "high"},
+		},
+		{
+			input: "submit report by friday !urgent",
+			want:  QuickAddParse{Title: "submit report", DueDate: "2026-10-16", Tags: []string{}, Priority: "urgent"},
+		},
+		{
+			input: "dentist next monday at 9:30am",
+			want:  QuickAddParse{Title: "dentist", DueDate: "2026-10-26", DueTime: "09:30", Tags: []string{}},
+		},
+		{
+			input: "Wednesday sync",
+			want:  QuickAddParse{Title: "sync", DueDate: "2026-10-14", Tags: []string{}},
+		},
+		{
+			input: "pay rent on oct 1",
+			want:  QuickAddParse{Title: "pay rent", DueDate: "2027-10-01", Tags: []string{}},
+		},
+		{
+			input: "lunch with sam 20 nov noon",
+			want:  QuickAddParse{Title: "lunch with sam", DueDate: "2026-11-20", DueTime: "12:00", Tags: []string{}},
+		},
+		{
+			input: "plan trip in 2 weeks #Travel #travel",
+			want:  QuickAddParse{Title: "plan trip", DueDate: "2026-10-28", Tags: []string{"travel"}},
+		},
+		{
+			input: "book flights 2026-12-20 low priority",
+			want:  QuickAddParse{Title: "book flights", DueDate: "2026-12-20", Tags: []string{}, Priority: "low"},
+		},
+		{
+			// Past times without a date roll over to tomorrow
+			input: "review draft 10 am",
+			want:  QuickAddParse{Title: "review draft", DueDate: "2026-10-15", DueTime: "10:00", Tags: []string{}},
+		},
+		{
+			input: "standup at 15:00 p2",
+			want:  QuickAddParse{Title: "standup", DueDate: "2026-10-14", DueTime: "15:00", Tags: []string{}, Priority: "high"},
+		},
+		{
+			input: "high school reunion",
+			want:  QuickAddParse{Title: "high school reunion", Tags: []string{}},
+		},
+		{
+			input: "sit in the sun on sat",
+			want:  QuickAddParse{Title: "sit in the sun", DueDate: "2026-10-17", Tags: []string{}},
+		},
+		{
+			input: "check in on bob",
+			want:  QuickAddParse{Title: "check in on bob", Tags: []string{}},
+		},
+		{
+			input: "buy milk",
+			want:  QuickAddParse{Title: "buy milk", Tags: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseQuickAdd(tt.input, now))
+		})
+	}
+}
+
+func TestParseQuickAdd_UsesTimezone(t *testing.T) {
+	// 02:00 UTC on the 15th is still the evening of the 14th in Toronto
+	instant := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2026-10-16", ParseQuickAdd("gym tomorrow", instant).DueDate)
+	assert.Equal(t, "2026-10-15", ParseQuickAdd("gym tomorrow", instant.In(toronto)).DueDate)
+}
+
+func TestTaskService_QuickAdd(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewTaskService(repo, zap.NewNop(), nil, nil, nil)
+
+	task, parsed, err := svc.QuickAdd(context.Background(), "user1", "call mom 2026-10-20 3pm #family high", "America/Toronto")
+	require.NoError(t, err)
+	assert.Equal(t, "call mom", parsed.Title)
+
+	stored, err := repo.Get(context.Background(), "users/user1/tasks/"+task["id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "call mom", stored["title"])
+	assert.Equal(t, "2026-10-20", stored["dueDate"])
+	assert.Equal(t, "15:00", stored["dueTime"])
+	assert.Equal(t, "high", stored["priority"])
+	assert.Equal(t, []interface{}{"family"}, stored["tags"])
+
+	_, _, err = svc.QuickAdd(context.Background(), "user1", "buy milk", "Mars/Base")
+	assert.True(t, errors.Is(err, ErrInvalidTimezone))
+
+	_, _, err = svc.QuickAdd(context.Background(), "user1", "#family tomorrow", "")
+	assert.True(t, errors.Is(err, ErrInvalidTask), "input without a title should be rejected")
+}
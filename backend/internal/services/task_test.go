@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+type fakeAIAccess struct {
+	allowed bool
+	err     error
+	tokens  int
+}
+
+func (f *fakeAIAccess) IsAIAllowed(ctx context.Context, uid string, isAnonymous bool) (bool, string, error) {
+	if !f.allowed {
+		return false, "upgrade_required", f.err
+	}
+	return true, "", f.err
+}
+
+func (f *fakeAIAccess) IncrementUsage(ctx context.Context, uid string, tokensUsed int) error {
+	f.tokens += tokensUsed
+	return nil
+}
+
+func newEnrichingTaskService(repo *mocks.MockRepository, access AIAccessChecker) *TaskService {
+	return NewTaskService(repo, zap.NewNop(), clients.NewSandboxOpenAIClient(&config.OpenAIConfig{}, zap.NewNop()), access, nil)
+}
+
+func TestTaskService_CreateTask_Enriches(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	access := &fakeAIAccess{allowed: true}
+	svc := newEnrichingTaskService(repo, access)
+
+	task, enriched, err := svc.CreateTask(context.Background(), "user1", map[string]interface{}{"title": "  Write quarterly report "}, true)
+
+	require.NoError(t, err)
+	assert.True(t, enriched)
+	assert.Equal(t, "Write quarterly report", task["title"])
+	assert.Equal(t, "mastery", task["category"])
+	assert.Equal(t, "high", task["priority"])
+	assert.Equal(t, 30, task["estimatedMinutes"])
+	assert.Equal(t, true, task["aiEnriched"])
+	assert.Equal(t, []interface{}{"category", "priority", "estimatedMinutes"}, task["aiEnrichedFields"])
+	assert.Positive(t, access.tokens)
+
+	stored := repo.Documents["users/user1/tasks/"+task["id"].(string)]
+	require.NotNil(t, stored)
+	assert.Equal(t, true, stored["aiEnriched"])
+	assert.Equal(t, 1, stored["version"])
+}
+
+func TestTaskService_CreateTask_KeepsUserFields(t *testing.T) {
+	svc := newEnrichingTaskService(mocks.NewMockRepository(), &fakeAIAccess{allowed: true})
+
+	task, enriched, err := svc.CreateTask(context.Background(), "user1", map[string]interface{}{
+		"title":    "Read a novel",
+		"category": "pleasure",
+		"priority": "low",
+	}, true)
+
+	require.NoError(t, err)
+	assert.True(t, enriched)
+	assert.Equal(t, "pleasure", task["category"])
+	assert.Equal(t, "low", task["priority"])
+	assert.Equal(t, 30, task["estimatedMinutes"])
+	assert.Equal(t, []interface{}{"estimatedMinutes"}, task["aiEnrichedFields"])
+}
+
+func TestTaskService_CreateTask_FallsBackWithoutAI(t *testing.T) {
+	tests := []struct {
+		name   string
+		access *fakeAIAccess
+		enrich bool
+	}{
+		{"enrich not requested", &fakeAIAccess{allowed: true}, false},
+		{"ai not allowed", &fakeAIAccess{allowed: false}, true},
+		{"access check fails", &fakeAIAccess{allowed: false, err: errors.New("firestore unavailable")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newEnrichingTaskService(mocks.NewMockRepository(), tt.access)
+
+			task, enriched, err := svc.CreateTask(context.Background(), "user1", map[string]interface{}{"title": "Plan trip"}, tt.enrich)
+
+			require.NoError(t, err)
+			assert.False(t, enriched)
+			assert.NotContains(t, task, "aiEnriched")
+			assert.NotContains(t, task, "category")
+			assert.Equal(t, "medium", task["priority"])
+			assert.Equal(t, "active", task["status"])
+			assert.Zero(t, tt.access.tokens)
+		})
+	}
+}
+
+func TestTaskService_CreateTask_Validation(t *testing.T) {
+	svc := NewTaskService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil)
+
+	_, _, err := svc.CreateTask(context.Background(), "user1", map[string]interface{}{"title": "   "}, false)
+	assert.ErrorIs(t, err, ErrInvalidTask)
+
+	task, _, err := svc.CreateTask(context.Background(), "user1", map[string]interface{}{"title": "Call mom", "version": 7, "updatedBy": "someone"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, task["version"])
+	assert.Equal(t, "user1", task["updatedBy"])
+	assert.NotContains(t, task, "aiEnriched")
+}
+
+func TestParseTaskEnrichment(t *testing.T) {
+	suggestions, err := parseTaskEnrichment(`{"category":"Pleasure","priority":"someday","estimatedMinutes":1000}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"category": "pleasure", "estimatedMinutes": maxEnrichedEstimateMinutes}, suggestions)
+
+	suggestions, err = parseTaskEnrichment(`{"estimatedMinutes":0}`)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	_, err = parseTaskEnrichment("not json")
+	assert.Error(t, err)
+}
+
+func updatesByPath(updates []firestore.Update) map[string]interface{} {
+	byPath := make(map[string]interface{})
+	for _, u := range updates {
+		byPath[u.Path] = u.Value
+	}
+	return byPath
+}
+
+func TestTaskStatusUpdates_Complete(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	fields := updatesByPath(taskStatusUpdates(true, now))
+
+	assert.Equal(t, true, fields["done"])
+	assert.Equal(t, "completed", fields["status"])
+	completedAt, ok := fields["completedAt"].(time.Time)
+	require.True(t, ok, "completedAt must be a time.Time for dashboard analytics")
+	assert.Equal(t, now, completedAt)
+}
+
+func TestTaskStatusUpdates_Reopen(t *testing.T) {
+	fields := updatesByPath(taskStatusUpdates(false, time.Now()))
+
+	assert.Equal(t, false, fields["done"])
+	assert.Equal(t, "active", fields["status"])
+	assert.Equal(t, firestore.Delete, fields["completedAt"])
+}
+
+func TestTaskStatusUpdates_CompletedTaskCountedByDashboard(t *testing.T) {
+	now := time.Now()
+	task := map[string]interface{}{"id": "task1"}
+	for path, value := range updatesByPath(taskStatusUpdates(true, now)) {
+		task[path] = value
+	}
+
+	dashboard := NewDashboardAnalyticsService(mocks.NewMockRepository(), zap.NewNop(), nil)
+	completed := dashboard.filterCompletedTasks([]map[string]interface{}{task}, now.Add(-time.Hour), now.Add(time.Hour))
+
+	assert.Len(t, completed, 1)
+}
+
+func TestTaskService_BulkUpdateStatus_Validation(t *testing.T) {
+	svc := NewTaskService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := svc.BulkUpdateStatus(ctx, "user1", nil, true)
+	assert.Error(t, err)
+
+	tooMany := make([]string, MaxBulkTaskUpdates+1)
+	for i := range tooMany {
+		tooMany[i] = "task"
+	}
+	_, err = svc.BulkUpdateStatus(ctx, "user1", tooMany, true)
+	assert.Error(t, err)
+}
+
+func TestTaskService_BulkUpdateStatus_NotFound(t *testing.T) {
+	svc := NewTaskService(mocks.NewMockRepository(), zap.NewNop(), nil, nil, nil)
+
+	results, err := svc.BulkUpdateStatus(context.Background(), "user1", []string{"missing", "missing", ""}, true)
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "missing", results[0].ID)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "task not found", results[0].Error)
+}
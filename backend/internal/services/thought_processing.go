@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
 )
@@ -23,9 +25,20 @@ type ThoughtProcessingService struct {
 	subscriptionSvc *SubscriptionService
 	actionProcessor *ActionProcessor
 	logger          *zap.Logger
+	reprocessCfg    config.AIReprocessConfig
+	reprocess       *reprocessJobs
+	responseCache   *AIResponseCache
+	anonymousQuota  AnonymousQuotaConsumer
+	llmLogs         LLMLogRecorder
 }
 
-// NewThoughtProcessingService creates a new thought processing service
+// thoughtPromptVersion identifies the prompt template in AI response cache
+// keys; bump it when buildPrompt changes so stale responses are not reused
+const thoughtPromptVersion = "thought-prompt-v1"
+
+// NewThoughtProcessingService creates a new thought processing service.
+// AI calls made for anonymous sessions are charged to anonymousQuota, and
+// provider calls are written to llmLogs; nil disables either.
 func NewThoughtProcessingService(
 	repo *repository.FirestoreRepository,
 	openaiClient *clients.OpenAIClient,
@@ -34,7 +47,21 @@ func NewThoughtProcessingService(
 	subscriptionSvc *SubscriptionService,
 	actionProcessor *ActionProcessor,
 	logger *zap.Logger,
+	reprocessCfg config.AIReprocessConfig,
+	responseCache *AIResponseCache,
+	anonymousQuota AnonymousQuotaConsumer,
+	llmLogs LLMLogRecorder,
 ) *ThoughtProcessingService {
+	if reprocessCfg.Concurrency <= 0 {
+		reprocessCfg.Concurrency = defaultReprocessConcurrency
+	}
+	if reprocessCfg.Interval <= 0 {
+		reprocessCfg.Interval = defaultReprocessInterval
+	}
+	if reprocessCfg.MaxThoughts <= 0 {
+		reprocessCfg.MaxThoughts = defaultReprocessMaxThoughts
+	}
+
 	return &ThoughtProcessingService{
 		repo:            repo,
 		openaiClient:    openaiClient,
@@ -43,11 +70,26 @@ func NewThoughtProcessingService(
 		subscriptionSvc: subscriptionSvc,
 		actionProcessor: actionProcessor,
 		logger:          logger,
+		reprocessCfg:    reprocessCfg,
+		reprocess:       &reprocessJobs{running: make(map[string]runningReprocessJob)},
+		responseCache:   responseCache,
+		anonymousQuota:  anonymousQuota,
+		llmLogs:         llmLogs,
 	}
 }
 
-// ProcessThought processes a thought with AI
-func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID string, thought map[string]interface{}, modelName string) error {
+// ThoughtProcessingResult summarizes a completed thought processing run
+type ThoughtProcessingResult struct {
+	ContextUsage *ContextUsage `json:"contextUsage,omitempty"`
+	Warnings     []string      `json:"warnings,omitempty"`
+	// Cached is set when the actions came from a cached AI response for the
+	// same thought text, prompt and model instead of a new provider call
+	Cached bool `json:"cached"`
+}
+
+// ProcessThought processes a thought with AI. A recent cached response for
+// the same thought text, prompt template and model is reused unless force is set.
+func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID string, thought map[string]interface{}, modelName string, force bool) (*ThoughtProcessingResult, error) {
 	uid := ctx.Value("uid").(string)
 	isAnonymous := ctx.Value("isAnonymous").(bool)
 
@@ -60,17 +102,17 @@ func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID
 	// 1. Check AI access
 	allowed, reason, err := s.subscriptionSvc.IsAIAllowed(ctx, uid, isAnonymous)
 	if err != nil {
-		return fmt.Errorf("failed to check AI access: %w", err)
+		return nil, fmt.Errorf("failed to check AI access: %w", err)
 	}
 	if !allowed {
-		return fmt.Errorf("AI access denied: %s", reason)
+		return nil, fmt.Errorf("AI access denied: %s", reason)
 	}
 
 	// 2. Check if already processed
 	if tags, ok := thought["tags"].([]interface{}); ok {
 		for _, tag := range tags {
 			if tag == "processed" {
-				return fmt.Errorf("thought already processed")
+				return nil, fmt.Errorf("thought already processed")
 			}
 		}
 	}
@@ -81,38 +123,49 @@ func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID
 		"aiProcessingStatus": "processing",
 	})
 	if err != nil {
-		return fmt.Errorf("failed to update thought status: %w", err)
+		return nil, fmt.Errorf("failed to update thought status: %w", err)
 	}
 
 	// 4. Gather user context
-	userContext, err := s.contextGatherer.GatherContext(ctx, uid)
+	userContext, contextUsage, err := s.contextGatherer.GatherContext(ctx, uid)
 	if err != nil {
 		s.logger.Warn("Failed to gather context", zap.Error(err))
 		userContext = &models.UserContext{} // Continue with empty context
+		contextUsage = &ContextUsage{}
 	}
 
 	// 5. Build prompt
 	prompt := s.buildPrompt(thought, userContext)
 
-	// 6. Call AI
-	var response *clients.ChatCompletionResponse
-	if modelName == "" || strings.Contains(modelName, "gpt") {
-		// Use OpenAI
-		response, err = s.openaiClient.ChatCompletion(ctx, clients.ChatCompletionRequest{
-			Model: modelName,
-			Messages: []clients.ChatMessage{
-				{Role: "system", Content: prompt},
-			},
-			ResponseFormat: &clients.ResponseFormat{Type: "json_object"},
-		})
-	} else {
+	// 6. Call AI, or reuse a cached response. User context is not part of the
+	// cache key; the cache TTL bounds how stale it can get. Only real provider
+	// calls count against an anonymous session's quota.
+	cacheKey := AIResponseCacheKey(thoughtPromptVersion, modelName, getStringField(thought, "text"))
+	response, cached, err := s.responseCache.GetOrCall(ctx, uid, cacheKey, force, func() (*clients.ChatCompletionResponse, error) {
+		if err := consumeAnonymousQuota(ctx, s.anonymousQuota, AnonymousQuotaAICalls, 1); err != nil {
+			return nil, err
+		}
+		if modelName == "" || strings.Contains(modelName, "gpt") {
+			// Use OpenAI
+			return s.openaiClient.ChatCompletion(ctx, clients.ChatCompletionRequest{
+				Model: modelName,
+				Messages: []clients.ChatMessage{
+					{Role: "system", Content: prompt},
+				},
+				ResponseFormat: &clients.ResponseFormat{Type: "json_object"},
+			})
+		}
 		// Use Anthropic
-		response, err = s.anthropicClient.ChatCompletion(ctx, clients.ChatCompletionRequest{
+		return s.anthropicClient.ChatCompletion(ctx, clients.ChatCompletionRequest{
 			Model: modelName,
 			Messages: []clients.ChatMessage{
 				{Role: "user", Content: prompt},
 			},
 		})
+	})
+
+	if !cached {
+		s.recordLLMLog(ctx, uid, thoughtID, modelName, prompt, contextUsage, response, err)
 	}
 
 	if err != nil {
@@ -121,21 +174,28 @@ func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID
 			"aiProcessingStatus": "failed",
 			"aiProcessingError":  err.Error(),
 		})
-		return fmt.Errorf("AI request failed: %w", err)
+		return nil, fmt.Errorf("AI request failed: %w", err)
 	}
 
 	// 7. Parse AI response
 	var aiResponse models.ThoughtProcessingResponse
 	if parseErr := json.Unmarshal([]byte(response.Content), &aiResponse); parseErr != nil {
 		s.logger.Error("Failed to parse AI response", zap.Error(parseErr), zap.String("content", response.Content))
-		return fmt.Errorf("failed to parse AI response: %w", parseErr)
+		return nil, fmt.Errorf("failed to parse AI response: %w", parseErr)
 	}
 
 	// 8. Execute actions
 	executedActions := 0
+	var relationships []map[string]interface{}
 	for _, action := range aiResponse.Actions {
 		// Only auto-execute high confidence actions
 		if action.Confidence >= 95 {
+			// Relationships are created together below so the cap on
+			// relationships per thought is applied once
+			if action.Type == "createRelationship" {
+				relationships = append(relationships, action.Data)
+				continue
+			}
 			actionErr := s.actionProcessor.ExecuteAction(ctx, uid, thoughtID, action)
 			if actionErr != nil {
 				s.logger.Warn("Failed to execute action",
@@ -147,6 +207,16 @@ func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID
 			}
 		}
 	}
+	if len(relationships) > 0 {
+		created, actionErr := s.actionProcessor.CreateRelationships(ctx, uid, thoughtID, relationships)
+		if actionErr != nil {
+			s.logger.Warn("Failed to execute action",
+				zap.Error(actionErr),
+				zap.String("actionType", "createRelationship"),
+			)
+		}
+		executedActions += created
+	}
 
 	// 9. Update thought with results
 	tags := []interface{}{"processed"}
@@ -164,25 +234,74 @@ func (s *ThoughtProcessingService) ProcessThought(ctx context.Context, thoughtID
 			"actionsFound":    len(aiResponse.Actions),
 			"actionsExecuted": executedActions,
 			"processedAt":     time.Now(),
+			"cached":          cached,
+			"context": map[string]interface{}{
+				"itemsIncluded":   contextUsage.IncludedItems,
+				"itemsDropped":    contextUsage.DroppedItems,
+				"estimatedTokens": contextUsage.EstimatedTokens,
+				"maxTokens":       contextUsage.MaxTokens,
+			},
 		},
 	}
 
 	err = s.repo.UpdateDocument(ctx, thoughtPath, updates)
 	if err != nil {
-		return fmt.Errorf("failed to update thought: %w", err)
+		return nil, fmt.Errorf("failed to update thought: %w", err)
 	}
 
-	// 10. Increment usage stats (error not critical)
-	_ = s.subscriptionSvc.IncrementUsage(ctx, uid, response.TokensUsed)
+	// 10. Increment usage stats (error not critical); cached responses cost nothing
+	if !cached {
+		_ = s.subscriptionSvc.IncrementUsage(ctx, uid, response.TokensUsed)
+	}
 
 	s.logger.Info("Thought processing completed",
 		zap.String("uid", uid),
 		zap.String("thoughtId", thoughtID),
 		zap.Int("tokensUsed", response.TokensUsed),
 		zap.Int("actionsExecuted", executedActions),
+		zap.Bool("cached", cached),
 	)
 
-	return nil
+	result := &ThoughtProcessingResult{ContextUsage: contextUsage, Cached: cached}
+	if warning := contextUsage.Warning(); warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+	return result, nil
+}
+
+// recordLLMLog logs a provider call with how much user context its prompt
+// carried. Failures to log are not fatal.
+func (s *ThoughtProcessingService) recordLLMLog(ctx context.Context, uid, thoughtID, modelName, prompt string, contextUsage *ContextUsage, response *clients.ChatCompletionResponse, callErr error) {
+	if s.llmLogs == nil || errors.Is(callErr, ErrAnonymousQuotaExceeded) {
+		return
+	}
+	entry := LLMLogEntry{
+		Feature:    AIFeatureThoughtProcessing,
+		Trigger:    "api",
+		PromptType: "thought-processing",
+		ThoughtID:  thoughtID,
+		Model:      modelName,
+		Prompt:     prompt,
+		Status:     "completed",
+	}
+	if contextUsage != nil {
+		entry.ContextItemsIncluded = contextUsage.IncludedItems
+		entry.ContextItemsDropped = contextUsage.DroppedItems
+		entry.ContextEstimatedTokens = contextUsage.EstimatedTokens
+		entry.ContextMaxTokens = contextUsage.MaxTokens
+	}
+	if callErr != nil {
+		entry.Status = "failed"
+		entry.Error = callErr.Error()
+	}
+	if response != nil {
+		entry.Model = response.Model
+		entry.RawResponse = response.Content
+		entry.TotalTokens = response.TokensUsed
+	}
+	if _, err := s.llmLogs.Record(ctx, uid, entry); err != nil {
+		s.logger.Warn("Failed to record LLM log", zap.String("uid", uid), zap.Error(err))
+	}
 }
 
 // buildPrompt builds the AI prompt for thought processing
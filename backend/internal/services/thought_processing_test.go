@@ -1,11 +1,17 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/clients"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
 )
 
 func TestGetStringField(t *testing.T) {
@@ -314,8 +320,11 @@ func TestBuildPrompt_JSONFormatInstructions(t *testing.T) {
 }
 
 func TestNewThoughtProcessingService(t *testing.T) {
-	service := NewThoughtProcessingService(nil, nil, nil, nil, nil, nil, nil)
+	service := NewThoughtProcessingService(nil, nil, nil, nil, nil, nil, nil, config.AIReprocessConfig{}, nil, nil, nil)
 	assert.NotNil(t, service)
+	assert.Equal(t, defaultReprocessConcurrency, service.reprocessCfg.Concurrency)
+	assert.Equal(t, defaultReprocessInterval, service.reprocessCfg.Interval)
+	assert.Equal(t, defaultReprocessMaxThoughts, service.reprocessCfg.MaxThoughts)
 }
 
 func TestBuildContextSection_HandlesNilFields(t *testing.T) {
@@ -360,3 +369,25 @@ func TestBuildContextSection_MixedTypes(t *testing.T) {
 	assert.Contains(t, result, "7/10")
 	assert.Contains(t, result, "0/10") // Invalid value defaults to 0
 }
+
+func TestRecordLLMLog_IncludesContextUsage(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := &ThoughtProcessingService{
+		logger:  zap.NewNop(),
+		llmLogs: NewLLMLogService(repo, zap.NewNop(), config.LLMLogsConfig{}),
+	}
+	usage := &ContextUsage{IncludedItems: 12, DroppedItems: 3, EstimatedTokens: 1800, MaxTokens: 2000}
+	response := &clients.ChatCompletionResponse{Model: "gpt-4o", Content: "{}", TokensUsed: 2400}
+
+	svc.recordLLMLog(context.Background(), "user1", "thought1", "gpt-4o", "prompt", usage, response, nil)
+
+	logs := repo.GetCollectionDocuments("users/user1/llmLogs")
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"itemsIncluded":   12,
+		"itemsDropped":    3,
+		"estimatedTokens": 1800,
+		"maxTokens":       2000,
+	}, logs[0]["context"])
+	assert.Equal(t, "thought1", logs[0]["thoughtId"])
+}
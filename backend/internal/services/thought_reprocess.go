@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+// Reprocess job statuses; everything but running is terminal
+const (
+	ReprocessStatusRunning         = "running"
+	ReprocessStatusCompleted       = "completed"
+	ReprocessStatusCancelled       = "cancelled"
+	ReprocessStatusBudgetExhausted = "budget_exhausted"
+)
+
+const (
+	defaultReprocessConcurrency = 2
+	defaultReprocessInterval    = 2 * time.Second
+	defaultReprocessMaxThoughts = 500
+)
+
+var (
+	// ErrReprocessJobRunning is returned when the user already has a job running
+	ErrReprocessJobRunning = errors.New("a reprocess job is already running")
+	// ErrReprocessJobNotFound is returned for unknown job IDs
+	ErrReprocessJobNotFound = errors.New("reprocess job not found")
+	// ErrInvalidReprocessRequest is returned for an empty or oversized thought list
+	ErrInvalidReprocessRequest = errors.New("invalid reprocess request")
+	// ErrAIAccessDenied is returned when the user's subscription does not allow AI requests
+	ErrAIAccessDenied = errors.New("AI access denied")
+)
+
+// ReprocessJob is the progress of a bulk reprocess job, stored at
+// users/{uid}/reprocessStatus/{jobId}
+type ReprocessJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Model      string     `json:"model,omitempty"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Failed     int        `json:"failed"`
+	Skipped    int        `json:"skipped"`
+	StopReason string     `json:"stopReason,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (j *ReprocessJob) toMap() map[string]interface{} {
+	data := map[string]interface{}{
+		"id":         j.ID,
+		"status":     j.Status,
+		"model":      j.Model,
+		"total":      j.Total,
+		"processed":  j.Processed,
+		"failed":     j.Failed,
+		"skipped":    j.Skipped,
+		"stopReason": j.StopReason,
+		"startedAt":  j.StartedAt,
+	}
+	if j.FinishedAt != nil {
+		data["finishedAt"] = *j.FinishedAt
+	}
+	return data
+}
+
+// reprocessJobs tracks the running job of each user so it can be cancelled
+type reprocessJobs struct {
+	mu      sync.Mutex
+	running map[string]runningReprocessJob // keyed by uid
+}
+
+type runningReprocessJob struct {
+	jobID  string
+	cancel context.CancelFunc
+}
+
+// StartReprocess queues the given thoughts for reprocessing in a background
+// job and returns its initial status. Thoughts are processed at most
+// Concurrency at a time with Interval between AI requests; the subscription is
+// re-checked before each one and the job stops with budget_exhausted once AI
+// access is denied. A user can only have one job running at a time.
+func (s *ThoughtProcessingService) StartReprocess(ctx context.Context, thoughtIDs []string, modelName string) (*ReprocessJob, error) {
+	uid := ctx.Value("uid").(string)
+	isAnonymous, _ := ctx.Value("isAnonymous").(bool)
+
+	thoughtIDs = dedupeStrings(thoughtIDs)
+	if len(thoughtIDs) == 0 {
+		return nil, fmt.Errorf("%w: thoughtIds is required", ErrInvalidReprocessRequest)
+	}
+	if len(thoughtIDs) > s.reprocessCfg.MaxThoughts {
+		return nil, fmt.Errorf("%w: at most %d thoughts can be reprocessed at once", ErrInvalidReprocessRequest, s.reprocessCfg.MaxThoughts)
+	}
+
+	allowed, reason, err := s.subscriptionSvc.IsAIAllowed(ctx, uid, isAnonymous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check AI access: %w", err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s", ErrAIAccessDenied, reason)
+	}
+
+	// The job outlives the request; keep its values (uid, isAnonymous) but
+	// not its deadline
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	s.reprocess.mu.Lock()
+	if running, ok := s.reprocess.running[uid]; ok {
+		s.reprocess.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("%w: %s", ErrReprocessJobRunning, running.jobID)
+	}
+	job := &ReprocessJob{
+		ID:        uuid.New().String(),
+		Status:    ReprocessStatusRunning,
+		Model:     modelName,
+		Total:     len(thoughtIDs),
+		StartedAt: time.Now(),
+	}
+	s.reprocess.running[uid] = runningReprocessJob{jobID: job.ID, cancel: cancel}
+	s.reprocess.mu.Unlock()
+
+	if err := s.repo.SetDocument(ctx, reprocessStatusPath(uid, job.ID), job.toMap()); err != nil {
+		s.finishReprocess(uid)
+		return nil, fmt.Errorf("failed to save reprocess status: %w", err)
+	}
+
+	s.logger.Info("Reprocess job started",
+		zap.String("uid", uid),
+		zap.String("jobId", job.ID),
+		zap.Int("thoughts", job.Total),
+	)
+
+	snapshot := *job
+	go s.runReprocess(jobCtx, uid, job, thoughtIDs, isAnonymous)
+	return &snapshot, nil
+}
+
+// GetReprocessStatus returns a reprocess job's stored progress
+func (s *ThoughtProcessingService) GetReprocessStatus(ctx context.Context, uid, jobID string) (map[string]interface{}, error) {
+	data, err := s.repo.Get(ctx, reprocessStatusPath(uid, jobID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrReprocessJobNotFound
+		}
+		return nil, fmt.Errorf("failed to load reprocess status: %w", err)
+	}
+	return data, nil
+}
+
+// CancelReprocess stops a running job from starting further thoughts; those
+// already in flight finish. Cancelling a finished job is a no-op.
+func (s *ThoughtProcessingService) CancelReprocess(ctx context.Context, uid, jobID string) (map[string]interface{}, error) {
+	s.reprocess.mu.Lock()
+	if running, ok := s.reprocess.running[uid]; ok && running.jobID == jobID {
+		running.cancel()
+	}
+	s.reprocess.mu.Unlock()
+
+	return s.GetReprocessStatus(ctx, uid, jobID)
+}
+
+// runReprocess drives a job to completion, writing progress after each thought
+func (s *ThoughtProcessingService) runReprocess(ctx context.Context, uid string, job *ReprocessJob, thoughtIDs []string, isAnonymous bool) {
+	defer s.finishReprocess(uid)
+
+	// Progress and the final status are written even after cancellation
+	writeCtx := context.WithoutCancel(ctx)
+	statusPath := reprocessStatusPath(uid, job.ID)
+	var mu sync.Mutex
+	var quotaExhausted atomic.Bool
+
+	allowed := func(ctx context.Context) (bool, string) {
+		if quotaExhausted.Load() {
+			return false, ErrAnonymousQuotaExceeded.Error()
+		}
+		ok, reason, err := s.subscriptionSvc.IsAIAllowed(ctx, uid, isAnonymous)
+		if err != nil {
+			s.logger.Warn("Failed to check AI access during reprocess", zap.Error(err))
+			return false, "failed to check AI access"
+		}
+		return ok, reason
+	}
+
+	process := func(thoughtID string) error {
+		thought, err := s.repo.Get(writeCtx, fmt.Sprintf("users/%s/thoughts/%s", uid, thoughtID))
+		if err != nil {
+			return err
+		}
+		_, err = s.ProcessThought(writeCtx, thoughtID, StripProcessedTag(thought), job.Model, false)
+		if errors.Is(err, ErrAnonymousQuotaExceeded) {
+			quotaExhausted.Store(true)
+		}
+		return err
+	}
+
+	progress := func(thoughtID string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case err == nil:
+			job.Processed++
+		case errors.Is(err, repository.ErrNotFound):
+			job.Skipped++
+		default:
+			job.Failed++
+			s.logger.Warn("Failed to reprocess thought",
+				zap.String("uid", uid),
+				zap.String("jobId", job.ID),
+				zap.String("thoughtId", thoughtID),
+				zap.Error(err),
+			)
+		}
+		if err := s.repo.SetDocument(writeCtx, statusPath, job.toMap()); err != nil {
+			s.logger.Warn("Failed to save reprocess progress", zap.Error(err))
+		}
+	}
+
+	status, reason := runReprocessQueue(ctx, thoughtIDs, s.reprocessCfg.Concurrency, s.reprocessCfg.Interval, allowed, process, progress)
+
+	mu.Lock()
+	defer mu.Unlock()
+	finishedAt := time.Now()
+	job.Status = status
+	job.StopReason = reason
+	job.FinishedAt = &finishedAt
+	if err := s.repo.SetDocument(writeCtx, statusPath, job.toMap()); err != nil {
+		s.logger.Error("Failed to save reprocess status", zap.Error(err))
+	}
+
+	s.logger.Info("Reprocess job finished",
+		zap.String("uid", uid),
+		zap.String("jobId", job.ID),
+		zap.String("status", status),
+		zap.Int("processed", job.Processed),
+		zap.Int("failed", job.Failed),
+		zap.Int("skipped", job.Skipped),
+	)
+}
+
+func (s *ThoughtProcessingService) finishReprocess(uid string) {
+	s.reprocess.mu.Lock()
+	defer s.reprocess.mu.Unlock()
+	if running, ok := s.reprocess.running[uid]; ok {
+		running.cancel()
+		delete(s.reprocess.running, uid)
+	}
+}
+
+// runReprocessQueue calls process for each ID with at most concurrency calls
+// in flight, starting them at least interval apart. allowed is checked before
+// each start and a denial stops the queue; so does cancelling ctx. Calls
+// already started run to completion and report through progress. Returns the
+// terminal job status and, for budget exhaustion, the reason given.
+func runReprocessQueue(
+	ctx context.Context,
+	ids []string,
+	concurrency int,
+	interval time.Duration,
+	allowed func(ctx context.Context) (bool, string),
+	process func(id string) error,
+	progress func(id string, err error),
+) (string, string) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var pace <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		pace = ticker.C
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	status, reason := ReprocessStatusCompleted, ""
+
+queue:
+	for i, id := range ids {
+		if i > 0 && pace != nil {
+			select {
+			case <-pace:
+			case <-ctx.Done():
+				status = ReprocessStatusCancelled
+				break queue
+			}
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			status = ReprocessStatusCancelled
+			break queue
+		}
+		if ctx.Err() != nil {
+			<-slots
+			status = ReprocessStatusCancelled
+			break
+		}
+		if ok, why := allowed(ctx); !ok {
+			<-slots
+			status, reason = ReprocessStatusBudgetExhausted, why
+			break
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			progress(id, process(id))
+		}(id)
+	}
+
+	wg.Wait()
+	return status, reason
+}
+
+// StripProcessedTag removes the "processed" tag so a thought can be processed again
+func StripProcessedTag(thought map[string]interface{}) map[string]interface{} {
+	if tags, ok := thought["tags"].([]interface{}); ok {
+		var newTags []interface{}
+		for _, tag := range tags {
+			if tag != "processed" {
+				newTags = append(newTags, tag)
+			}
+		}
+		thought["tags"] = newTags
+	}
+	return thought
+}
+
+func reprocessStatusPath(uid, jobID string) string {
+	return fmt.Sprintf("users/%s/reprocessStatus/%s", uid, jobID)
+}
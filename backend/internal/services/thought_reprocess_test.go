@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+)
+
+func allowAll(context.Context) (bool, string) { return true, "" }
+
+func reprocessTestConfig(maxThoughts int) config.AIReprocessConfig {
+	return config.AIReprocessConfig{Concurrency: 1, Interval: time.Millisecond, MaxThoughts: maxThoughts}
+}
+
+func TestRunReprocessQueue_ProcessesAllAndReportsProgress(t *testing.T) {
+	ids := []string{"t1", "t2", "t3", "t4"}
+	var mu sync.Mutex
+	results := map[string]error{}
+
+	status, reason := runReprocessQueue(context.Background(), ids, 2, 0, allowAll,
+		func(id string) error {
+			if id == "t3" {
+				return errors.New("AI request failed")
+			}
+			return nil
+		},
+		func(id string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results[id] = err
+		},
+	)
+
+	assert.Equal(t, ReprocessStatusCompleted, status)
+	assert.Empty(t, reason)
+	require.Len(t, results, 4)
+	assert.Error(t, results["t3"])
+	assert.NoError(t, results["t1"])
+}
+
+func TestRunReprocessQueue_LimitsConcurrency(t *testing.T) {
+	ids := []string{"t1", "t2", "t3", "t4", "t5", "t6"}
+	var inFlight, peak int32
+
+	runReprocessQueue(context.Background(), ids, 2, 0, allowAll,
+		func(id string) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		},
+		func(string, error) {},
+	)
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestRunReprocessQueue_PacesRequests(t *testing.T) {
+	ids := []string{"t1", "t2", "t3"}
+	interval := 20 * time.Millisecond
+	var mu sync.Mutex
+	var starts []time.Time
+
+	runReprocessQueue(context.Background(), ids, 3, interval, allowAll,
+		func(id string) error {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+			return nil
+		},
+		func(string, error) {},
+	)
+
+	require.Len(t, starts, 3)
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[0]), 2*interval-5*time.Millisecond)
+}
+
+func TestRunReprocessQueue_StopsWhenBudgetExhausted(t *testing.T) {
+	ids := []string{"t1", "t2", "t3", "t4"}
+	checks := 0
+	var processed []string
+
+	status, reason := runReprocessQueue(context.Background(), ids, 1, 0,
+		func(context.Context) (bool, string) {
+			checks++
+			if checks > 2 {
+				return false, "AI credits exhausted"
+			}
+			return true, ""
+		},
+		func(id string) error {
+			processed = append(processed, id)
+			return nil
+		},
+		func(string, error) {},
+	)
+
+	assert.Equal(t, ReprocessStatusBudgetExhausted, status)
+	assert.Equal(t, "AI credits exhausted", reason)
+	assert.Equal(t, []string{"t1", "t2"}, processed)
+}
+
+func TestRunReprocessQueue_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ids := []string{"t1", "t2", "t3", "t4"}
+	var processed []string
+
+	status, _ := runReprocessQueue(ctx, ids, 1, 0, allowAll,
+		func(id string) error {
+			processed = append(processed, id)
+			if id == "t2" {
+				cancel()
+			}
+			return nil
+		},
+		func(string, error) {},
+	)
+
+	assert.Equal(t, ReprocessStatusCancelled, status)
+	assert.Equal(t, []string{"t1", "t2"}, processed)
+}
+
+func TestStartReprocess_RejectsInvalidRequests(t *testing.T) {
+	service := NewThoughtProcessingService(nil, nil, nil, nil, nil, nil, nil, reprocessTestConfig(2), nil, nil, nil)
+	ctx := context.WithValue(context.Background(), "uid", "user1")
+
+	_, err := service.StartReprocess(ctx, nil, "")
+	assert.ErrorIs(t, err, ErrInvalidReprocessRequest)
+
+	_, err = service.StartReprocess(ctx, []string{"t1", "t2", "t3"}, "")
+	assert.ErrorIs(t, err, ErrInvalidReprocessRequest)
+}
+
+func TestStripProcessedTag(t *testing.T) {
+	thought := StripProcessedTag(map[string]interface{}{
+		"tags": []interface{}{"health", "processed", "work"},
+	})
+	assert.Equal(t, []interface{}{"health", "work"}, thought["tags"])
+}
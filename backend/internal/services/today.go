@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// todayConcurrency caps the fetches run at once for a today summary
+const todayConcurrency = 3
+
+// TodayTask is an open task due today or earlier
+type TodayTask struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Priority string `json:"priority,omitempty"`
+	DueDate  string `json:"dueDate"`
+}
+
+// Today is everything the home screen shows for the current day. Date is the
+// calendar day in Timezone that the other sections are computed for.
+type Today struct {
+	Date         string      `json:"date"`
+	Timezone     string      `json:"timezone"`
+	Tasks        []TodayTask `json:"tasks"`
+	Overdue      []TodayTask `json:"overdue"`
+	Streak       *Streak     `json:"streak"`
+	MoodDue      bool        `json:"moodDue"`
+	FocusMinutes float64     `json:"focusMinutes"`
+	AtRiskGoals  []GoalRisk  `json:"atRiskGoals"`
+}
+
+// TodayService assembles the home screen summary from the task, mood, focus
+// session, streak and goal data
+type TodayService struct {
+	repo    interfaces.Repository
+	logger  *zap.Logger
+	streaks *StreakService
+	goals   *GoalService
+	now     func() time.Time
+}
+
+// NewTodayService creates a new today service
+func NewTodayService(repo interfaces.Repository, logger *zap.Logger, streaks *StreakService, goals *GoalService) *TodayService {
+	return &TodayService{
+		repo:    repo,
+		logger:  logger,
+		streaks: streaks,
+		goals:   goals,
+		now:     time.Now,
+	}
+}
+
+// GetToday returns today's and overdue tasks, the focus session streak,
+// whether a mood entry is due, today's focus minutes and the at-risk goals.
+// Days are calendar days in timezone, an IANA name; empty means UTC.
+func (s *TodayService) GetToday(ctx context.Context, uid, timezone string) (*Today, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+	today := s.now().In(loc).Format("2006-01-02")
+	result := &Today{Date: today, Timezone: loc.String(), Tasks: []TodayTask{}, Overdue: []TodayTask{}}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(todayConcurrency)
+	g.Go(func() error {
+		tasks, err := s.repo.List(gctx, fmt.Sprintf("users/%s/tasks", uid), 0)
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+		result.Tasks, result.Overdue = dueTasks(tasks, today, loc)
+		return nil
+	})
+	g.Go(func() error {
+		moods, err := s.repo.List(gctx, fmt.Sprintf("users/%s/moods", uid), 0)
+		if err != nil {
+			return fmt.Errorf("failed to list moods: %w", err)
+		}
+		result.MoodDue = true
+		for _, mood := range moods {
+			if day, ok := activityDay(mood["date"], loc); ok && day == today {
+				result.MoodDue = false
+				break
+			}
+		}
+		return nil
+	})
+	g.Go(func() error {
+		sessions, err := s.repo.List(gctx, fmt.Sprintf("users/%s/focusSessions", uid), 0)
+		if err != nil {
+			return fmt.Errorf("failed to list focus sessions: %w", err)
+		}
+		for _, session := range sessions {
+			// Active sessions only hold their planned duration
+			if active, _ := session["isActive"].(bool); active {
+				continue
+			}
+			if day, ok := activityDay(session["startTime"], loc); !ok || day != today {
+				continue
+			}
+			if minutes, ok := toFloat(session["duration"]); ok && minutes > 0 {
+				result.FocusMinutes += minutes
+			}
+		}
+		return nil
+	})
+	g.Go(func() error {
+		streak, err := s.streaks.Compute(gctx, uid, "focusSessions", "startTime", loc.String())
+		if err != nil {
+			return err
+		}
+		result.Streak = streak
+		return nil
+	})
+	g.Go(func() error {
+		atRisk, err := s.goals.AtRisk(gctx, uid)
+		if err != nil {
+			return err
+		}
+		result.AtRiskGoals = atRisk.Goals
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// dueTasks splits the open tasks with a dueDate into those due today and
+// those overdue, each ordered by due date then title
+func dueTasks(tasks []map[string]interface{}, today string, loc *time.Location) (due, overdue []TodayTask) {
+	due, overdue = []TodayTask{}, []TodayTask{}
+	for _, task := range tasks {
+		if taskDone(task) || getStringField(task, "status") == "archived" {
+			continue
+		}
+		day, ok := activityDay(task["dueDate"], loc)
+		if !ok || day > today {
+			continue
+		}
+		item := TodayTask{
+			ID:       getStringField(task, "id"),
+			Title:    getStringField(task, "title"),
+			Priority: getStringField(task, "priority"),
+			DueDate:  day,
+		}
+		if day == today {
+			due = append(due, item)
+		} else {
+			overdue = append(overdue, item)
+		}
+	}
+	for _, list := range [][]TodayTask{due, overdue} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].DueDate != list[j].DueDate {
+				return list[i].DueDate < list[j].DueDate
+			}
+			return list[i].Title < list[j].Title
+		})
+	}
+	return due, overdue
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func newTestTodayService(repo *mocks.MockRepository) *TodayService {
+	logger := zap.NewNop()
+	return NewTodayService(repo, logger, NewStreakService(repo, logger), NewGoalService(repo, logger, config.GoalsConfig{}))
+}
+
+func TestTodayService_GetToday(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	loc, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+	now := time.Now().In(loc)
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+
+	repo.AddDocument("users/user1/tasks/t1", map[string]interface{}{"id": "t1", "title": "Call bank", "dueDate": today, "priority": "high"})
+	repo.AddDocument("users/user1/tasks/t2", map[string]interface{}{"id": "t2", "title": "File taxes", "dueDate": yesterday})
+	repo.AddDocument("users/user1/tasks/t3", map[string]interface{}{"id": "t3", "title": "Plan trip", "dueDate": tomorrow})
+	repo.AddDocument("users/user1/tasks/t4", map[string]interface{}{"id": "t4", "title": "Done already", "dueDate": today, "done": true})
+	repo.AddDocument("users/user1/focusSessions/s1", map[string]interface{}{"startTime": now, "duration": int64(25), "isActive": false})
+	repo.AddDocument("users/user1/focusSessions/s2", map[string]interface{}{"startTime": now, "duration": int64(50), "isActive": true})
+	repo.AddDocument("users/user1/focusSessions/s3", map[string]interface{}{"startTime": now.AddDate(0, 0, -3), "duration": int64(40), "isActive": false})
+	repo.AddDocument("users/user1/goals/g1", map[string]interface{}{
+		"id": "g1", "title": "Launch", "status": "active", "createdAt": now.AddDate(0, -2, 0), "targetDate": now.AddDate(0, 0, -1),
+	})
+
+	svc := newTestTodayService(repo)
+	result, err := svc.GetToday(context.Background(), "user1", "America/Toronto")
+	require.NoError(t, err)
+
+	assert.Equal(t, today, result.Date)
+	assert.Equal(t, "America/Toronto", result.Timezone)
+	assert.Equal(t, []TodayTask{{ID: "t1", Title: "Call bank", Priority: "high", DueDate: today}}, result.Tasks)
+	assert.Equal(t, []TodayTask{{ID: "t2", Title: "File taxes", DueDate: yesterday}}, result.Overdue)
+	assert.Equal(t, 25.0, result.FocusMinutes)
+	require.NotNil(t, result.Streak)
+	assert.Equal(t, 1, result.Streak.Current)
+	assert.True(t, result.MoodDue)
+	require.Len(t, result.AtRiskGoals, 1)
+	assert.Equal(t, "g1", result.AtRiskGoals[0].GoalID)
+
+	// Logging a mood today clears the prompt
+	repo.AddDocument("users/user1/moods/m1", map[string]interface{}{"value": int64(7), "date": now})
+	result, err = svc.GetToday(context.Background(), "user1", "America/Toronto")
+	require.NoError(t, err)
+	assert.False(t, result.MoodDue)
+}
+
+func TestTodayService_GetToday_InvalidTimezone(t *testing.T) {
+	svc := newTestTodayService(mocks.NewMockRepository())
+
+	_, err := svc.GetToday(context.Background(), "user1", "Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidTimezone)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+const (
+	// maxCategoryRules caps the rules in one categorize request
+	maxCategoryRules = 100
+	// categoryRuleSampleSize is the number of changes listed in a result
+	categoryRuleSampleSize = 20
+)
+
+// ErrInvalidCategoryRule is returned when a category rule is incomplete or its pattern does not compile
+var ErrInvalidCategoryRule = errors.New("invalid category rule")
+
+// CategoryRuleInput assigns Category to transactions whose merchant matches.
+// Exactly one of Prefix or Pattern must be set; both are matched like merchant
+// alias rules, against the normalized merchant name and its alias.
+type CategoryRuleInput struct {
+	Category string `json:"category"`
+	Prefix   string `json:"prefix,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+// CategoryRuleChange is one transaction whose category a rule set changes
+type CategoryRuleChange struct {
+	TransactionID string  `json:"transactionId"`
+	Merchant      string  `json:"merchant"`
+	Date          string  `json:"date,omitempty"`
+	Amount        float64 `json:"amount"`
+	FromCategory  string  `json:"fromCategory"`
+	ToCategory    string  `json:"toCategory"`
+}
+
+// CategoryRulesResult reports the effect of a rule set. Matched counts the
+// transactions a rule matched; Changed those whose category differs from the
+// rule's. Sample lists up to categoryRuleSampleSize of the changes.
+type CategoryRulesResult struct {
+	Scanned    int                  `json:"scanned"`
+	Matched    int                  `json:"matched"`
+	Changed    int                  `json:"changed"`
+	ByCategory map[string]int       `json:"byCategory"`
+	Sample     []CategoryRuleChange `json:"sample"`
+	Applied    bool                 `json:"applied"`
+}
+
+type categoryRuleChange struct {
+	path   string
+	change CategoryRuleChange
+}
+
+// PreviewCategoryRules reports which transactions the rules would
+// recategorize, without writing anything
+func (s *SpendingAnalyticsService) PreviewCategoryRules(ctx context.Context, uid string, inputs []CategoryRuleInput) (*CategoryRulesResult, error) {
+	result, _, err := s.planCategoryRules(ctx, uid, inputs)
+	return result, err
+}
+
+// ApplyCategoryRules sets the category of every transaction the rules
+// recategorize. The result counts match what PreviewCategoryRules reported for
+// the same rules and transactions.
+func (s *SpendingAnalyticsService) ApplyCategoryRules(ctx context.Context, uid string, inputs []CategoryRuleInput) (*CategoryRulesResult, error) {
+	result, changes, err := s.planCategoryRules(ctx, uid, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, change := range changes {
+		if err := s.repo.Update(ctx, change.path, map[string]interface{}{
+			"category":      change.change.ToCategory,
+			"categorizedBy": "rule",
+			"updatedAt":     now,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to recategorize %s: %w", change.path, err)
+		}
+	}
+	result.Applied = true
+
+	s.logger.Info("Applied category rules",
+		zap.String("uid", uid),
+		zap.Int("rules", len(inputs)),
+		zap.Int("scanned", result.Scanned),
+		zap.Int("changed", result.Changed),
+	)
+	return result, nil
+}
+
+// planCategoryRules runs the rules over the user's transactions, both those
+// under users/{uid} and those synced top level. The first matching rule wins;
+// records merged into another by DeduplicateTransactions are skipped.
+func (s *SpendingAnalyticsService) planCategoryRules(ctx context.Context, uid string, inputs []CategoryRuleInput) (*CategoryRulesResult, []categoryRuleChange, error) {
+	rules, err := compileCategoryRules(inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userPath := fmt.Sprintf("users/%s/transactions", uid)
+	userTransactions, err := s.repo.List(ctx, userPath, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	syncedTransactions, err := s.repo.ListWhere(ctx, "transactions", []repository.Filter{repository.Eq("uid", uid)}, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list synced transactions: %w", err)
+	}
+
+	merchants := s.merchantAliases.Matcher(ctx, uid)
+	result := &CategoryRulesResult{
+		ByCategory: map[string]int{},
+		Sample:     []CategoryRuleChange{},
+	}
+	var changes []categoryRuleChange
+	plan := func(collectionPath string, docs []map[string]interface{}) {
+		for _, txn := range docs {
+			id := s.getStringField(txn, "id")
+			if id == "" {
+				id = s.getStringField(txn, "plaidTransactionId")
+			}
+			if id == "" || s.getStringField(txn, "duplicateOf") != "" {
+				continue
+			}
+			result.Scanned++
+
+			merchant := s.getMerchantName(txn)
+			category, ok := matchCategoryRule(rules, merchant, merchants)
+			if !ok {
+				continue
+			}
+			result.Matched++
+
+			current := s.getCategory(txn)
+			if current == category {
+				continue
+			}
+			change := CategoryRuleChange{
+				TransactionID: id,
+				Merchant:      merchant,
+				Date:          s.getStringField(txn, "postedAt"),
+				Amount:        s.getFloatField(txn, "amount"),
+				FromCategory:  current,
+				ToCategory:    category,
+			}
+			if change.Date == "" {
+				change.Date = s.getStringField(txn, "date")
+			}
+			result.Changed++
+			result.ByCategory[category]++
+			if len(result.Sample) < categoryRuleSampleSize {
+				result.Sample = append(result.Sample, change)
+			}
+			changes = append(changes, categoryRuleChange{path: fmt.Sprintf("%s/%s", collectionPath, id), change: change})
+		}
+	}
+	plan(userPath, userTransactions)
+	plan("transactions", syncedTransactions)
+
+	return result, changes, nil
+}
+
+// matchCategoryRule returns the category of the first rule matching the
+// merchant's normalized name or its alias
+func matchCategoryRule(rules []merchantAliasRule, merchant string, merchants *MerchantMatcher) (string, bool) {
+	names := []string{normalizeMerchantName(merchant)}
+	if canonical, ok := merchants.Canonicalize(merchant); ok {
+		names = append(names, normalizeMerchantName(canonical))
+	}
+	for _, rule := range rules {
+		for _, name := range names {
+			if name != "" && rule.matches(name) {
+				return rule.canonical, true
+			}
+		}
+	}
+	return "", false
+}
+
+// compileCategoryRules validates and compiles a rule set into alias rules
+// whose canonical name is the category. Prefixes are normalized the same way
+// merchant names are.
+func compileCategoryRules(inputs []CategoryRuleInput) ([]merchantAliasRule, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one rule is required", ErrInvalidCategoryRule)
+	}
+	if len(inputs) > maxCategoryRules {
+		return nil, fmt.Errorf("%w: at most %d rules", ErrInvalidCategoryRule, maxCategoryRules)
+	}
+
+	rules := make([]merchantAliasRule, 0, len(inputs))
+	for i, input := range inputs {
+		category := strings.TrimSpace(input.Category)
+		if category == "" {
+			return nil, fmt.Errorf("%w: rule %d: category is required", ErrInvalidCategoryRule, i)
+		}
+		rule, err := compileMerchantAlias(category, normalizeMerchantName(input.Prefix), strings.TrimSpace(input.Pattern))
+		if err != nil {
+			// Report the alias error's detail under this request's error
+			detail := strings.TrimPrefix(err.Error(), ErrInvalidMerchantAlias.Error()+": ")
+			return nil, fmt.Errorf("%w: rule %d: %s", ErrInvalidCategoryRule, i, detail)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/config"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func seedCategoryRuleTransactions(repo *mocks.MockRepository, uid string) {
+	repo.AddDocument("users/"+uid+"/transactions/csv-1", map[string]interface{}{
+		"id": "csv-1", "date": "2024-03-04", "merchant": "STARBUCKS STORE 01458", "amount": 6.45, "category": "Shopping",
+	})
+	repo.AddDocument("users/"+uid+"/transactions/csv-2", map[string]interface{}{
+		"id": "csv-2", "date": "2024-03-05", "merchant": "Starbucks", "amount": 4.10, "category": "Coffee",
+	})
+	repo.AddDocument("users/"+uid+"/transactions/csv-3", map[string]interface{}{
+		"id": "csv-3", "date": "2024-03-06", "merchant": "Shell Oil 1234", "amount": 40.00,
+	})
+	// Merged into another record, so never recategorized
+	repo.AddDocument("users/"+uid+"/transactions/csv-4", map[string]interface{}{
+		"id": "csv-4", "date": "2024-03-06", "merchant": "Starbucks", "amount": 6.45, "duplicateOf": "txn_1",
+	})
+	repo.AddDocument("transactions/txn_1", map[string]interface{}{
+		"uid": uid, "plaidTransactionId": "txn_1", "postedAt": "2024-03-07", "amount": 12.00,
+		"merchant": map[string]interface{}{"name": "SQ *BLUE BOTTLE"}, "category_base": []interface{}{"Food and Drink"},
+	})
+	repo.AddDocument("transactions/txn_other", map[string]interface{}{
+		"uid": "user-2", "plaidTransactionId": "txn_other", "postedAt": "2024-03-07", "amount": 3.00,
+		"merchant": map[string]interface{}{"name": "Starbucks"},
+	})
+}
+
+var testCategoryRules = []CategoryRuleInput{
+	{Category: "Coffee", Prefix: "Starbucks"},
+	// Matches the alias configured for Blue Bottle rather than the raw name
+	{Category: "Coffee", Pattern: "^blue bottle coffee$"},
+	{Category: "Transportation", Pattern: `\bshell\b`},
+}
+
+func newCategoryRuleTestService(repo *mocks.MockRepository) *SpendingAnalyticsService {
+	aliases := NewMerchantAliasService(repo, zap.NewNop(), &config.MerchantsConfig{
+		Aliases: []config.MerchantAliasRule{{Canonical: "Blue Bottle Coffee", Pattern: "blue bottle"}},
+	})
+	return NewSpendingAnalyticsService(repo, zap.NewNop(), aliases, nil)
+}
+
+func TestPreviewCategoryRules_MatchesApply(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := newCategoryRuleTestService(repo)
+	ctx := context.Background()
+	uid := "user-1"
+	seedCategoryRuleTransactions(repo, uid)
+
+	preview, err := service.PreviewCategoryRules(ctx, uid, testCategoryRules)
+	require.NoError(t, err)
+	assert.False(t, preview.Applied)
+	assert.Equal(t, 4, preview.Scanned)
+	assert.Equal(t, 4, preview.Matched)
+	assert.Equal(t, 3, preview.Changed)
+	assert.Equal(t, map[string]int{"Coffee": 2, "Transportation": 1}, preview.ByCategory)
+	require.Len(t, preview.Sample, 3)
+	assert.Contains(t, preview.Sample, CategoryRuleChange{
+		TransactionID: "csv-1",
+		Merchant:      "STARBUCKS STORE 01458",
+		Date:          "2024-03-04",
+		Amount:        6.45,
+		FromCategory:  "Shopping",
+		ToCategory:    "Coffee",
+	})
+
+	// The preview writes nothing
+	csv, err := repo.Get(ctx, "users/"+uid+"/transactions/csv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Shopping", csv["category"])
+
+	applied, err := service.ApplyCategoryRules(ctx, uid, testCategoryRules)
+	require.NoError(t, err)
+	assert.True(t, applied.Applied)
+	assert.Equal(t, preview.Scanned, applied.Scanned)
+	assert.Equal(t, preview.Matched, applied.Matched)
+	assert.Equal(t, preview.Changed, applied.Changed)
+	assert.Equal(t, preview.ByCategory, applied.ByCategory)
+
+	for path, category := range map[string]string{
+		"users/" + uid + "/transactions/csv-1": "Coffee",
+		"users/" + uid + "/transactions/csv-3": "Transportation",
+		"transactions/txn_1":                   "Coffee",
+	} {
+		txn, err := repo.Get(ctx, path)
+		require.NoError(t, err)
+		assert.Equal(t, category, txn["category"], path)
+		assert.Equal(t, "rule", txn["categorizedBy"], path)
+	}
+	duplicate, err := repo.Get(ctx, "users/"+uid+"/transactions/csv-4")
+	require.NoError(t, err)
+	assert.NotContains(t, duplicate, "category")
+	other, err := repo.Get(ctx, "transactions/txn_other")
+	require.NoError(t, err)
+	assert.NotContains(t, other, "category")
+
+	// Once applied, previewing the same rules finds nothing left to change
+	again, err := service.PreviewCategoryRules(ctx, uid, testCategoryRules)
+	require.NoError(t, err)
+	assert.Equal(t, 4, again.Matched)
+	assert.Equal(t, 0, again.Changed)
+	assert.Empty(t, again.Sample)
+}
+
+func TestPreviewCategoryRules_FirstRuleWins(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := newCategoryRuleTestService(repo)
+	seedCategoryRuleTransactions(repo, "user-1")
+
+	preview, err := service.PreviewCategoryRules(context.Background(), "user-1", []CategoryRuleInput{
+		{Category: "Treats", Pattern: "starbucks store"},
+		{Category: "Coffee", Prefix: "starbucks"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Treats": 1}, preview.ByCategory)
+}
+
+func TestCompileCategoryRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []CategoryRuleInput
+	}{
+		{"no rules", nil},
+		{"missing category", []CategoryRuleInput{{Prefix: "starbucks"}}},
+		{"no matcher", []CategoryRuleInput{{Category: "Coffee"}}},
+		{"prefix and pattern", []CategoryRuleInput{{Category: "Coffee", Prefix: "a", Pattern: "b"}}},
+		{"bad pattern", []CategoryRuleInput{{Category: "Coffee", Pattern: "("}}},
+		{"too many", make([]CategoryRuleInput, maxCategoryRules+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileCategoryRules(tt.rules)
+			assert.ErrorIs(t, err, ErrInvalidCategoryRule)
+		})
+	}
+}
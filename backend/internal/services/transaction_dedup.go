@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository"
+)
+
+const (
+	// duplicateAmountTolerance absorbs float rounding between CSV and Plaid amounts
+	duplicateAmountTolerance = 0.005
+	// duplicateDateWindow allows for posting-date drift between sources
+	duplicateDateWindow = 24 * time.Hour
+)
+
+// transactionDateLayouts are the date formats written by Plaid sync and CSV uploads
+var transactionDateLayouts = []string{"2006-01-02", time.RFC3339, "01/02/2006", "1/2/2006"}
+
+// csvMergeFields are copied from a duplicate CSV record onto the Plaid record
+// when the Plaid record does not have them, so user edits are not lost
+var csvMergeFields = []string{"category", "notes", "tags", "tripLinkId", "csvFileName"}
+
+// DeduplicationSummary reports the result of a de-duplication pass
+type DeduplicationSummary struct {
+	Scanned int               `json:"scanned"`
+	Merged  int               `json:"merged"`
+	Pairs   []DuplicateMerged `json:"pairs"`
+}
+
+// DuplicateMerged links a duplicate transaction to the record it was merged into
+type DuplicateMerged struct {
+	DuplicateID string `json:"duplicateId"`
+	CanonicalID string `json:"canonicalId"`
+}
+
+type dedupTransaction struct {
+	id       string
+	path     string
+	data     map[string]interface{}
+	plaid    bool
+	date     time.Time
+	amount   float64
+	merchant map[string]bool
+}
+
+// DeduplicateTransactions finds transactions imported by both a CSV upload and
+// Plaid sync: same merchant (after alias normalization), same amount, and dates
+// at most a day apart. The Plaid record is kept as canonical; fields it lacks
+// are filled from the CSV record, which is then marked with duplicateOf so
+// analytics skip it. Records are flagged rather than deleted so a bad match can
+// be undone.
+func (s *SpendingAnalyticsService) DeduplicateTransactions(ctx context.Context, uid string) (*DeduplicationSummary, error) {
+	userPath := fmt.Sprintf("users/%s/transactions", uid)
+	userTransactions, err := s.repo.List(ctx, userPath, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	syncedTransactions, err := s.repo.ListWhere(ctx, "transactions", []repository.Filter{repository.Eq("uid", uid)}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synced transactions: %w", err)
+	}
+
+	merchants := s.merchantAliases.Matcher(ctx, uid)
+	var canonical, candidates []*dedupTransaction
+	collect := func(collectionPath string, docs []map[string]interface{}) {
+		for _, data := range docs {
+			txn := s.toDedupTransaction(collectionPath, data, merchants)
+			if txn == nil {
+				continue
+			}
+			if txn.plaid {
+				canonical = append(canonical, txn)
+			} else if s.getStringField(data, "duplicateOf") == "" {
+				candidates = append(candidates, txn)
+			}
+		}
+	}
+	collect(userPath, userTransactions)
+	collect("transactions", syncedTransactions)
+
+	summary := &DeduplicationSummary{
+		Scanned: len(userTransactions) + len(syncedTransactions),
+		Pairs:   []DuplicateMerged{},
+	}
+	used := make(map[string]bool)
+	now := time.Now()
+
+	for _, candidate := range candidates {
+		match := findDuplicateMatch(candidate, canonical, used)
+		if match == nil {
+			continue
+		}
+
+		if updates := mergeDuplicateFields(match.data, candidate.data); len(updates) > 0 {
+			if err := s.repo.Update(ctx, match.path, updates); err != nil {
+				return summary, fmt.Errorf("failed to merge into %s: %w", match.path, err)
+			}
+		}
+		if err := s.repo.Update(ctx, candidate.path, map[string]interface{}{
+			"duplicateOf": match.id,
+			"mergedAt":    now,
+		}); err != nil {
+			return summary, fmt.Errorf("failed to flag duplicate %s: %w", candidate.path, err)
+		}
+
+		used[match.path] = true
+		summary.Merged++
+		summary.Pairs = append(summary.Pairs, DuplicateMerged{DuplicateID: candidate.id, CanonicalID: match.id})
+	}
+
+	s.logger.Info("Deduplicated transactions",
+		zap.String("uid", uid),
+		zap.Int("scanned", summary.Scanned),
+		zap.Int("merged", summary.Merged),
+	)
+	return summary, nil
+}
+
+// toDedupTransaction extracts the fields used for matching. Returns nil for
+// records without an ID or a parseable date.
+func (s *SpendingAnalyticsService) toDedupTransaction(collectionPath string, data map[string]interface{}, merchants *MerchantMatcher) *dedupTransaction {
+	id := s.getStringField(data, "id")
+	if id == "" {
+		id = s.getStringField(data, "plaidTransactionId")
+	}
+	if id == "" {
+		return nil
+	}
+
+	date, ok := parseTransactionDate(data["postedAt"])
+	if !ok {
+		date, ok = parseTransactionDate(data["date"])
+	}
+	if !ok {
+		return nil
+	}
+
+	names := map[string]bool{}
+	addName := func(name string) {
+		if name == "" {
+			return
+		}
+		canonical, _ := merchants.Canonicalize(name)
+		if normalized := normalizeMerchantName(canonical); normalized != "" {
+			names[normalized] = true
+		}
+	}
+	addName(s.getMerchantName(data))
+	addName(s.getStringField(data, "description"))
+	addName(s.getStringField(data, "originalDescription"))
+
+	return &dedupTransaction{
+		id:       id,
+		path:     fmt.Sprintf("%s/%s", collectionPath, id),
+		data:     data,
+		plaid:    s.getStringField(data, "source") == "plaid" || s.getStringField(data, "plaidTransactionId") != "",
+		date:     date,
+		amount:   math.Abs(s.getFloatField(data, "amount")),
+		merchant: names,
+	}
+}
+
+// findDuplicateMatch returns the first unused canonical record matching the candidate
+func findDuplicateMatch(candidate *dedupTransaction, canonical []*dedupTransaction, used map[string]bool) *dedupTransaction {
+	for _, txn := range canonical {
+		if used[txn.path] {
+			continue
+		}
+		if math.Abs(txn.amount-candidate.amount) > duplicateAmountTolerance {
+			continue
+		}
+		if gap := txn.date.Sub(candidate.date); gap > duplicateDateWindow || gap < -duplicateDateWindow {
+			continue
+		}
+		for name := range candidate.merchant {
+			if txn.merchant[name] {
+				return txn
+			}
+		}
+	}
+	return nil
+}
+
+// mergeDuplicateFields returns the CSV fields missing from the canonical record
+func mergeDuplicateFields(canonical, duplicate map[string]interface{}) map[string]interface{} {
+	updates := map[string]interface{}{}
+	for _, field := range csvMergeFields {
+		value, ok := duplicate[field]
+		if !ok || isEmptyValue(value) {
+			continue
+		}
+		if existing, ok := canonical[field]; ok && !isEmptyValue(existing) {
+			continue
+		}
+		updates[field] = value
+	}
+	return updates
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []interface{}:
+		return len(val) == 0
+	case []string:
+		return len(val) == 0
+	}
+	return false
+}
+
+func parseTransactionDate(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		for _, layout := range transactionDateLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func seedDuplicatePair(repo *mocks.MockRepository, uid string) {
+	repo.AddDocument("users/"+uid+"/transactions/csv-1", map[string]interface{}{
+		"id":          "csv-1",
+		"accountId":   "csv-upload",
+		"date":        "2024-03-04",
+		"description": "STARBUCKS STORE 01458",
+		"merchant":    "Starbucks",
+		"amount":      6.45,
+		"category":    "Coffee",
+		"notes":       "Team coffee",
+		"source":      "csv-upload",
+	})
+	repo.AddDocument("transactions/txn_sandbox_1", map[string]interface{}{
+		"uid":                 uid,
+		"plaidTransactionId":  "txn_sandbox_1",
+		"postedAt":            "2024-03-05",
+		"amount":              6.45,
+		"merchant":            map[string]interface{}{"name": "Starbucks", "normalized": "starbucks"},
+		"originalDescription": "STARBUCKS STORE 1458",
+		"source":              "plaid",
+	})
+}
+
+func TestDeduplicateTransactions_MergesCSVIntoPlaid(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, nil)
+	ctx := context.Background()
+	uid := "user-1"
+
+	seedDuplicatePair(repo, uid)
+	// Same amount and date but a different merchant
+	repo.AddDocument("users/"+uid+"/transactions/csv-2", map[string]interface{}{
+		"id": "csv-2", "date": "2024-03-05", "description": "BLUE BOTTLE", "amount": 6.45, "source": "csv-upload",
+	})
+	// Same merchant and amount, three days apart
+	repo.AddDocument("users/"+uid+"/transactions/csv-3", map[string]interface{}{
+		"id": "csv-3", "date": "2024-03-08", "description": "STARBUCKS STORE 01458", "amount": 6.45, "source": "csv-upload",
+	})
+	// Another user's Plaid record never matches
+	repo.AddDocument("transactions/txn_other", map[string]interface{}{
+		"uid": "user-2", "plaidTransactionId": "txn_other", "postedAt": "2024-03-08",
+		"amount": 6.45, "merchant": map[string]interface{}{"name": "Starbucks"}, "source": "plaid",
+	})
+
+	summary, err := service.DeduplicateTransactions(ctx, uid)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Merged)
+	require.Len(t, summary.Pairs, 1)
+	assert.Equal(t, DuplicateMerged{DuplicateID: "csv-1", CanonicalID: "txn_sandbox_1"}, summary.Pairs[0])
+
+	csv, err := repo.Get(ctx, "users/"+uid+"/transactions/csv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "txn_sandbox_1", csv["duplicateOf"])
+
+	plaid, err := repo.Get(ctx, "transactions/txn_sandbox_1")
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", plaid["category"])
+	assert.Equal(t, "Team coffee", plaid["notes"])
+
+	other, err := repo.Get(ctx, "transactions/txn_other")
+	require.NoError(t, err)
+	assert.NotContains(t, other, "category")
+
+	// A second pass finds nothing new
+	summary, err = service.DeduplicateTransactions(ctx, uid)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Merged)
+}
+
+func TestDeduplicateTransactions_UsesMerchantAliases(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	aliases := NewMerchantAliasService(repo, zap.NewNop(), testMerchantsConfig)
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), aliases, nil)
+	uid := "user-1"
+
+	repo.AddDocument("users/"+uid+"/transactions/csv-1", map[string]interface{}{
+		"id": "csv-1", "date": "03/10/2024", "description": "AMZN Mktp US*2K4LP9XY2", "amount": -42.10, "source": "csv-upload",
+	})
+	repo.AddDocument("transactions/txn_1", map[string]interface{}{
+		"uid": uid, "plaidTransactionId": "txn_1", "postedAt": "2024-03-10",
+		"amount": 42.10, "merchant": map[string]interface{}{"name": "Amazon"}, "source": "plaid",
+	})
+
+	summary, err := service.DeduplicateTransactions(context.Background(), uid)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Merged)
+}
+
+func TestFetchTransactions_SkipsMergedDuplicates(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	service := NewSpendingAnalyticsService(repo, zap.NewNop(), nil, nil)
+	uid := "user-1"
+
+	repo.AddDocument("users/"+uid+"/transactions/a", map[string]interface{}{"postedAt": "2024-03-04", "amount": 5.0})
+	repo.AddDocument("users/"+uid+"/transactions/b", map[string]interface{}{"postedAt": "2024-03-04", "amount": 5.0, "duplicateOf": "a"})
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	txns, err := service.fetchTransactions(context.Background(), uid, start, end, nil)
+	require.NoError(t, err)
+	assert.Len(t, txns, 1)
+}
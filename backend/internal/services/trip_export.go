@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+// TripBundle is a trip with its packing list, places and linked spending,
+// exported as one self-contained file
+type TripBundle struct {
+	Metadata     ExportMetadata           `json:"metadata"`
+	Trip         map[string]interface{}   `json:"trip"`
+	PackingList  *PackingList             `json:"packingList,omitempty"`
+	Places       []map[string]interface{} `json:"places"`
+	Transactions []map[string]interface{} `json:"transactions"`
+}
+
+// ExportTrip assembles a trip into a TripBundle. Places are those listed in
+// the trip's placeIds plus places whose name or city is the trip destination;
+// transactions are those linked to the trip via /api/spending/link-trip.
+// A missing trip is reported as interfaces.ErrNotFound.
+func (s *ImportExportService) ExportTrip(ctx context.Context, uid, tripID string) (*TripBundle, error) {
+	tripPath := fmt.Sprintf("users/%s/trips/%s", uid, tripID)
+	trip, err := s.repo.Get(ctx, tripPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := trip["id"]; !ok {
+		trip["id"] = tripID
+	}
+
+	bundle := &TripBundle{
+		Trip:         trip,
+		Places:       []map[string]interface{}{},
+		Transactions: []map[string]interface{}{},
+	}
+
+	packingList, err := s.repo.Get(ctx, tripPath+"/packingList/data")
+	switch {
+	case err == nil:
+		bundle.PackingList = parsePackingList(tripID, packingList)
+	case !errors.Is(err, interfaces.ErrNotFound):
+		return nil, fmt.Errorf("failed to read packing list: %w", err)
+	}
+
+	if bundle.Places, err = s.tripPlaces(ctx, uid, trip); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.List(ctx, fmt.Sprintf("users/%s/transactions", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	for _, txn := range transactions {
+		if getStringField(txn, "duplicateOf") != "" {
+			continue
+		}
+		if link, ok := txn["tripLink"].(map[string]interface{}); ok && getStringField(link, "tripId") == tripID {
+			bundle.Transactions = append(bundle.Transactions, txn)
+		}
+	}
+
+	total := 1 + len(bundle.Places) + len(bundle.Transactions)
+	if bundle.PackingList != nil {
+		total++
+	}
+	bundle.Metadata = ExportMetadata{
+		Version:     "1.0",
+		ExportedAt:  s.now(),
+		ExportedBy:  uid,
+		TotalItems:  total,
+		AppVersion:  "focus-notebook-backend",
+		Description: "Trip: " + getStringField(trip, "name"),
+	}
+	return bundle, nil
+}
+
+// tripPlaces returns the places listed in the trip's placeIds and the places
+// matching its destination, each once
+func (s *ImportExportService) tripPlaces(ctx context.Context, uid string, trip map[string]interface{}) ([]map[string]interface{}, error) {
+	places := []map[string]interface{}{}
+	seen := map[string]bool{}
+
+	for _, id := range toStringSlice(trip["placeIds"]) {
+		if seen[id] {
+			continue
+		}
+		place, err := s.repo.Get(ctx, fmt.Sprintf("users/%s/places/%s", uid, id))
+		if errors.Is(err, interfaces.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read place %s: %w", id, err)
+		}
+		place["id"] = id
+		seen[id] = true
+		places = append(places, place)
+	}
+
+	destination := strings.TrimSpace(getStringField(trip, "destination"))
+	if destination == "" {
+		return places, nil
+	}
+	all, err := s.repo.List(ctx, fmt.Sprintf("users/%s/places", uid), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list places: %w", err)
+	}
+	for _, place := range all {
+		id := getStringField(place, "id")
+		if id == "" || seen[id] {
+			continue
+		}
+		if strings.EqualFold(getStringField(place, "name"), destination) || strings.EqualFold(getStringField(place, "city"), destination) {
+			seen[id] = true
+			places = append(places, place)
+		}
+	}
+	return places, nil
+}
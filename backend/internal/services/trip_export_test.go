@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestImportExportService_ExportTrip(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+	ctx := context.Background()
+
+	repo.AddDocument("users/user1/trips/trip1", map[string]interface{}{
+		"name": "Spring in Lisbon", "destination": "Lisbon", "placeIds": []interface{}{"sintra", "missing"},
+	})
+	repo.AddDocument("users/user1/trips/trip1/packingList/data", map[string]interface{}{
+		"tripId": "trip1",
+		"userId": "user1",
+		"sections": []interface{}{
+			map[string]interface{}{
+				"id": "essentials", "title": "Essentials",
+				"groups": []interface{}{
+					map[string]interface{}{
+						"id": "docs", "title": "Documents",
+						"items": []interface{}{map[string]interface{}{"id": "passport", "name": "Passport"}},
+					},
+				},
+			},
+		},
+		"packedItemIds": []interface{}{"passport"},
+	})
+	repo.AddDocument("users/user1/places/sintra", map[string]interface{}{"name": "Sintra", "country": "Portugal"})
+	repo.AddDocument("users/user1/places/lisbon", map[string]interface{}{"id": "lisbon", "name": "Lisbon", "type": "visit"})
+	repo.AddDocument("users/user1/places/tokyo", map[string]interface{}{"id": "tokyo", "name": "Tokyo"})
+	repo.AddDocument("users/user1/transactions/t1", map[string]interface{}{
+		"id": "t1", "amount": 42.0, "tripLink": map[string]interface{}{"tripId": "trip1"},
+	})
+	repo.AddDocument("users/user1/transactions/t2", map[string]interface{}{
+		"id": "t2", "amount": 10.0, "tripLink": map[string]interface{}{"tripId": "trip2"},
+	})
+	repo.AddDocument("users/user1/transactions/t3", map[string]interface{}{"id": "t3", "amount": 5.0})
+
+	bundle, err := svc.ExportTrip(ctx, "user1", "trip1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "trip1", bundle.Trip["id"])
+	assert.Equal(t, "Spring in Lisbon", bundle.Trip["name"])
+
+	require.NotNil(t, bundle.PackingList)
+	require.Len(t, bundle.PackingList.Sections, 1)
+	assert.Equal(t, "Passport", bundle.PackingList.Sections[0].Groups[0].Items[0].Name)
+	assert.Equal(t, []string{"passport"}, bundle.PackingList.PackedItemIDs)
+
+	placeIDs := []interface{}{}
+	for _, place := range bundle.Places {
+		placeIDs = append(placeIDs, place["id"])
+	}
+	assert.Equal(t, []interface{}{"sintra", "lisbon"}, placeIDs)
+
+	require.Len(t, bundle.Transactions, 1)
+	assert.Equal(t, "t1", bundle.Transactions[0]["id"])
+
+	assert.Equal(t, 5, bundle.Metadata.TotalItems)
+	assert.Equal(t, "user1", bundle.Metadata.ExportedBy)
+}
+
+func TestImportExportService_ExportTrip_WithoutPackingList(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	svc := NewImportExportService(repo, zap.NewNop(), 0, nil, 0, 0, nil)
+	repo.AddDocument("users/user1/trips/trip1", map[string]interface{}{"name": "Weekend away"})
+
+	bundle, err := svc.ExportTrip(context.Background(), "user1", "trip1")
+	require.NoError(t, err)
+	assert.Nil(t, bundle.PackingList)
+	assert.Empty(t, bundle.Places)
+	assert.Empty(t, bundle.Transactions)
+	assert.Equal(t, 1, bundle.Metadata.TotalItems)
+
+	_, err = svc.ExportTrip(context.Background(), "user1", "nope")
+	assert.ErrorIs(t, err, interfaces.ErrNotFound)
+}
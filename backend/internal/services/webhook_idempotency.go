@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/interfaces"
+)
+
+const (
+	// ProcessedWebhooksCollection records webhook deliveries that were already handled
+	ProcessedWebhooksCollection = "processedWebhooks"
+
+	WebhookProviderStripe = "stripe"
+	WebhookProviderPlaid  = "plaid"
+
+	// defaultWebhookDedupTTL covers Stripe's three-day retry window
+	defaultWebhookDedupTTL = 72 * time.Hour
+)
+
+// WebhookIdempotencyService deduplicates webhook deliveries. Each processed event
+// is recorded in processedWebhooks with an expiresAt, which a Firestore TTL
+// policy can use to delete old records; expired records are also ignored on read.
+type WebhookIdempotencyService struct {
+	repo   interfaces.Repository
+	logger *zap.Logger
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewWebhookIdempotencyService creates a new webhook idempotency service.
+// ttl <= 0 uses the default of 72 hours.
+func NewWebhookIdempotencyService(repo interfaces.Repository, logger *zap.Logger, ttl time.Duration) *WebhookIdempotencyService {
+	if ttl <= 0 {
+		ttl = defaultWebhookDedupTTL
+	}
+	return &WebhookIdempotencyService{
+		repo:   repo,
+		logger: logger,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Claim records a webhook event as processed. Returns false if the event was
+// already recorded and has not expired, meaning the delivery is a duplicate.
+// The check and the write run in one transaction, so of near-simultaneous
+// deliveries exactly one is claimed; an expired record is taken over.
+func (s *WebhookIdempotencyService) Claim(ctx context.Context, provider, eventID string) (bool, error) {
+	path := webhookRecordPath(provider, eventID)
+
+	var claimed bool
+	err := s.repo.RunTransaction(ctx, func(ctx context.Context, tx interfaces.Transaction) error {
+		claimed = false
+		existing, err := tx.Get(path)
+		if err != nil && !errors.Is(err, interfaces.ErrNotFound) {
+			return fmt.Errorf("failed to check processed webhook: %w", err)
+		}
+		now := s.now()
+		if err == nil {
+			if expiresAt, ok := existing["expiresAt"].(time.Time); !ok || expiresAt.After(now) {
+				return nil
+			}
+		}
+
+		claimed = true
+		return tx.Set(path, map[string]interface{}{
+			"provider":    provider,
+			"eventId":     eventID,
+			"processedAt": now,
+			"expiresAt":   now.Add(s.ttl),
+		})
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record processed webhook: %w", err)
+	}
+	return claimed, nil
+}
+
+// Release forgets a claimed event so a redelivery is processed again.
+// Used when processing fails after the event was claimed.
+func (s *WebhookIdempotencyService) Release(ctx context.Context, provider, eventID string) {
+	if err := s.repo.DeleteDocument(ctx, webhookRecordPath(provider, eventID)); err != nil {
+		s.logger.Warn("Failed to release processed webhook",
+			zap.String("provider", provider),
+			zap.String("eventId", eventID),
+			zap.Error(err),
+		)
+	}
+}
+
+// PlaidWebhookKey synthesizes a stable event key for a Plaid webhook, which has
+// no native event ID. Redeliveries carry the same body, so when the payload has
+// no timestamp a hash of the raw body stands in for it.
+func PlaidWebhookKey(webhookType, webhookCode, itemID, timestamp string, body []byte) string {
+	if timestamp == "" {
+		sum := sha256.Sum256(body)
+		timestamp = hex.EncodeToString(sum[:8])
+	}
+	return strings.Join([]string{webhookType, webhookCode, itemID, timestamp}, ":")
+}
+
+// webhookRecordPath returns the processedWebhooks document for an event.
+// Event IDs are hashed so arbitrary provider keys are safe as document IDs.
+func webhookRecordPath(provider, eventID string) string {
+	sum := sha256.Sum256([]byte(eventID))
+	return fmt.Sprintf("%s/%s_%s", ProcessedWebhooksCollection, provider, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/repository/mocks"
+)
+
+func TestWebhookIdempotencyService_ClaimReplay(t *testing.T) {
+	svc := NewWebhookIdempotencyService(mocks.NewMockRepository(), zap.NewNop(), 0)
+	assert.Equal(t, defaultWebhookDedupTTL, svc.ttl)
+	ctx := context.Background()
+
+	claimed, err := svc.Claim(ctx, WebhookProviderStripe, "evt_123")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = svc.Claim(ctx, WebhookProviderStripe, "evt_123")
+	require.NoError(t, err)
+	assert.False(t, claimed, "replayed event should be a duplicate")
+
+	// The same ID from another provider is a different event
+	claimed, err = svc.Claim(ctx, WebhookProviderPlaid, "evt_123")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestWebhookIdempotencyService_ExpiredRecordIsReclaimed(t *testing.T) {
+	svc := NewWebhookIdempotencyService(mocks.NewMockRepository(), zap.NewNop(), time.Hour)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	claimed, err := svc.Claim(ctx, WebhookProviderStripe, "evt_1")
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	now = now.Add(2 * time.Hour)
+	claimed, err = svc.Claim(ctx, WebhookProviderStripe, "evt_1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestWebhookIdempotencyService_ConcurrentClaims(t *testing.T) {
+	svc := NewWebhookIdempotencyService(mocks.NewMockRepository(), zap.NewNop(), 0)
+	ctx := context.Background()
+
+	var claims atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claimed, err := svc.Claim(ctx, WebhookProviderStripe, "evt_concurrent")
+			assert.NoError(t, err)
+			if claimed {
+				claims.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), claims.Load(), "exactly one delivery should be claimed")
+}
+
+func TestWebhookIdempotencyService_Release(t *testing.T) {
+	svc := NewWebhookIdempotencyService(mocks.NewMockRepository(), zap.NewNop(), 0)
+	ctx := context.Background()
+
+	_, err := svc.Claim(ctx, WebhookProviderStripe, "evt_1")
+	require.NoError(t, err)
+	svc.Release(ctx, WebhookProviderStripe, "evt_1")
+
+	claimed, err := svc.Claim(ctx, WebhookProviderStripe, "evt_1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestPlaidWebhookKey(t *testing.T) {
+	body := []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item1"}`)
+
+	assert.Equal(t,
+		PlaidWebhookKey("TRANSACTIONS", "SYNC_UPDATES_AVAILABLE", "item1", "", body),
+		PlaidWebhookKey("TRANSACTIONS", "SYNC_UPDATES_AVAILABLE", "item1", "", body))
+	assert.NotEqual(t,
+		PlaidWebhookKey("TRANSACTIONS", "SYNC_UPDATES_AVAILABLE", "item1", "", body),
+		PlaidWebhookKey("TRANSACTIONS", "SYNC_UPDATES_AVAILABLE", "item1", "", append(body, ' ')))
+	assert.Equal(t, "ITEM:ERROR:item1:2024-03-01T00:00:00Z",
+		PlaidWebhookKey("ITEM", "ERROR", "item1", "2024-03-01T00:00:00Z", body))
+}
@@ -0,0 +1,206 @@
+// Package testutil provides helpers for integration tests that run against
+// the Firestore emulator.
+//
+// Start the emulator and point tests at it:
+//
+//	firebase emulators:start --only firestore
+//	FIRESTORE_EMULATOR_HOST=localhost:8080 go test -tags=integration ./...
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// EmulatorHostEnv is read by the Firestore client to route requests to the emulator
+	EmulatorHostEnv = "FIRESTORE_EMULATOR_HOST"
+	// EmulatorProjectEnv overrides the project ID used against the emulator
+	EmulatorProjectEnv = "FIRESTORE_EMULATOR_PROJECT"
+
+	// defaultEmulatorProject uses the demo- prefix so nothing can reach a real project
+	defaultEmulatorProject = "demo-focus-notebook"
+	emulatorDialTimeout    = 2 * time.Second
+)
+
+// TopLevelUserCollections are the top-level collections whose documents are
+// owned through a uid field rather than living under users/{uid}
+var TopLevelUserCollections = []string{
+	"tasks", "projects", "goals", "thoughts", "moods", "focusSessions",
+	"people", "portfolios", "transactions", "entityRelationships", "llmLogs",
+}
+
+// NewEmulatorClient connects to the Firestore emulator. The test is skipped
+// when FIRESTORE_EMULATOR_HOST is unset or the emulator is not reachable.
+func NewEmulatorClient(t *testing.T) *firestore.Client {
+	t.Helper()
+
+	host := os.Getenv(EmulatorHostEnv)
+	if host == "" {
+		t.Skipf("%s not set; skipping Firestore emulator test", EmulatorHostEnv)
+	}
+	conn, err := net.DialTimeout("tcp", host, emulatorDialTimeout)
+	if err != nil {
+		t.Skipf("Firestore emulator not reachable at %s: %v", host, err)
+	}
+	conn.Close()
+
+	projectID := os.Getenv(EmulatorProjectEnv)
+	if projectID == "" {
+		projectID = defaultEmulatorProject
+	}
+
+	client, err := firestore.NewClient(context.Background(), projectID)
+	if err != nil {
+		t.Fatalf("failed to create Firestore emulator client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// NewOfflineClient returns a client that never connects, for unit tests that
+// only build queries and hand them to a mock repository
+func NewOfflineClient(t *testing.T) *firestore.Client {
+	t.Helper()
+
+	// The emulator setting skips credential lookup; the connection is lazy, so
+	// nothing is dialed unless a query actually runs
+	t.Setenv(EmulatorHostEnv, "localhost:0")
+	client, err := firestore.NewClient(context.Background(), defaultEmulatorProject)
+	if err != nil {
+		t.Fatalf("failed to create offline Firestore client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// NewTestUser returns a unique uid and deletes all of its data when the test ends
+func NewTestUser(t *testing.T, client *firestore.Client) string {
+	t.Helper()
+
+	uid := "test-" + uuid.New().String()
+	t.Cleanup(func() {
+		if err := DeleteUserData(context.Background(), client, uid); err != nil {
+			t.Errorf("failed to tear down test user %s: %v", uid, err)
+		}
+	})
+
+	return uid
+}
+
+// SeedUserDocs writes documents to users/{uid}/{collection}. Each document
+// must have an "id" field, which becomes the document ID.
+func SeedUserDocs(t *testing.T, client *firestore.Client, uid, collection string, docs ...map[string]interface{}) {
+	t.Helper()
+	seedDocs(t, client, fmt.Sprintf("users/%s/%s", uid, collection), docs, nil)
+}
+
+// SeedTopLevelDocs writes documents to a top-level collection, stamping each
+// with the owner's uid. Each document must have an "id" field.
+func SeedTopLevelDocs(t *testing.T, client *firestore.Client, uid, collection string, docs ...map[string]interface{}) {
+	t.Helper()
+	seedDocs(t, client, collection, docs, map[string]interface{}{"uid": uid})
+}
+
+func seedDocs(t *testing.T, client *firestore.Client, collectionPath string, docs []map[string]interface{}, extra map[string]interface{}) {
+	t.Helper()
+
+	ctx := context.Background()
+	batch := client.Batch()
+	for _, doc := range docs {
+		id, ok := doc["id"].(string)
+		if !ok || id == "" {
+			t.Fatalf("seed document in %s is missing an id", collectionPath)
+		}
+		data := make(map[string]interface{}, len(doc)+len(extra))
+		for k, v := range doc {
+			data[k] = v
+		}
+		for k, v := range extra {
+			data[k] = v
+		}
+		batch.Set(client.Collection(collectionPath).Doc(id), data)
+	}
+	if _, err := batch.Commit(ctx); err != nil {
+		t.Fatalf("failed to seed %s: %v", collectionPath, err)
+	}
+}
+
+// DeleteUserData removes everything under users/{uid}, including nested
+// subcollections, and every top-level document whose uid field matches
+func DeleteUserData(ctx context.Context, client *firestore.Client, uid string) error {
+	userDoc := client.Collection("users").Doc(uid)
+	if err := deleteDocumentTree(ctx, userDoc); err != nil {
+		return err
+	}
+
+	for _, collection := range TopLevelUserCollections {
+		iter := client.Collection(collection).Where("uid", "==", uid).Documents(ctx)
+		refs, err := collectRefs(iter)
+		if err != nil {
+			return fmt.Errorf("failed to list %s for %s: %w", collection, uid, err)
+		}
+		for _, ref := range refs {
+			if err := deleteDocumentTree(ctx, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteDocumentTree deletes a document after recursively deleting its subcollections
+func deleteDocumentTree(ctx context.Context, doc *firestore.DocumentRef) error {
+	collections := doc.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list subcollections of %s: %w", doc.Path, err)
+		}
+
+		refs, err := collectRefs(collection.Documents(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", collection.Path, err)
+		}
+		for _, ref := range refs {
+			if err := deleteDocumentTree(ctx, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := doc.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", doc.Path, err)
+	}
+	return nil
+}
+
+func collectRefs(iter *firestore.DocumentIterator) ([]*firestore.DocumentRef, error) {
+	defer iter.Stop()
+
+	var refs []*firestore.DocumentRef
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, snap.Ref)
+	}
+}
@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
 )
@@ -74,28 +76,7 @@ func ParseCSV(csvContent string) ([]models.CSVTransaction, error) {
 		values := ParseCSVLine(line)
 
 		if len(values) >= 3 {
-			// Parse amount - handle negative numbers, currency symbols, parentheses
-			amountStr := values[2]
-			amountStr = strings.ReplaceAll(amountStr, "$", "")
-			amountStr = strings.ReplaceAll(amountStr, ",", "")
-
-			amount := 0.0
-
-			// Handle parentheses notation for negative numbers (common in accounting)
-			if strings.Contains(amountStr, "(") && strings.Contains(amountStr, ")") {
-				amountStr = strings.ReplaceAll(amountStr, "(", "")
-				amountStr = strings.ReplaceAll(amountStr, ")", "")
-				parsedAmount, err := strconv.ParseFloat(amountStr, 64)
-				if err == nil {
-					amount = -parsedAmount
-				}
-			} else {
-				parsedAmount, err := strconv.ParseFloat(amountStr, 64)
-				if err == nil {
-					amount = parsedAmount
-				}
-			}
-
+			amount, _ := parseCSVAmount(values[2])
 			transactions = append(transactions, models.CSVTransaction{
 				Date:        values[0],
 				Description: values[1],
@@ -110,3 +91,158 @@ func ParseCSV(csvContent string) ([]models.CSVTransaction, error) {
 
 	return transactions, nil
 }
+
+// parseCSVAmount parses an amount, handling currency symbols, thousands
+// separators and parentheses notation for negative numbers (common in accounting)
+func parseCSVAmount(value string) (float64, bool) {
+	value = strings.ReplaceAll(value, "$", "")
+	value = strings.ReplaceAll(value, ",", "")
+
+	negate := false
+	if strings.Contains(value, "(") && strings.Contains(value, ")") {
+		value = strings.ReplaceAll(value, "(", "")
+		value = strings.ReplaceAll(value, ")", "")
+		negate = true
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, false
+	}
+	if negate {
+		amount = -amount
+	}
+	return amount, true
+}
+
+// ErrCSVColumnNotFound is returned when a mapped or detected column is not in the header
+var ErrCSVColumnNotFound = errors.New("CSV column not found")
+
+// csvColumnKeywords are header words used to detect columns a mapping leaves empty
+var csvColumnKeywords = map[string][]string{
+	"date":        {"transaction date", "date"},
+	"description": {"description", "details", "memo", "payee", "narrative", "merchant", "name"},
+	"amount":      {"amount", "value"},
+}
+
+// CSVHeader returns the normalized header row of CSV content: trimmed,
+// lowercased column names
+func CSVHeader(csvContent string) []string {
+	first, _, _ := strings.Cut(strings.TrimSpace(csvContent), "\n")
+	first = strings.TrimPrefix(strings.TrimSpace(first), "\ufeff")
+	columns := ParseCSVLine(first)
+	for i, column := range columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+	return columns
+}
+
+// ParseCSVWithMapping parses CSV content with a header row using a saved
+// mapping. Rows whose amount or date cannot be parsed are skipped; at most
+// limit transactions are returned when limit > 0.
+func ParseCSVWithMapping(csvContent string, mapping models.CSVMapping, limit int) ([]models.CSVTransaction, error) {
+	lines := strings.Split(strings.TrimSpace(csvContent), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	header := CSVHeader(lines[0])
+	dateIdx, err := resolveCSVColumn(header, mapping.DateColumn, "date")
+	if err != nil {
+		return nil, err
+	}
+	descriptionIdx, err := resolveCSVColumn(header, mapping.DescriptionColumn, "description")
+	if err != nil {
+		return nil, err
+	}
+	amountIdx, err := resolveCSVColumn(header, mapping.AmountColumn, "amount")
+	if err != nil {
+		return nil, err
+	}
+
+	layout := ""
+	if mapping.DateFormat != "" {
+		if layout, err = CSVDateLayout(mapping.DateFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	var transactions []models.CSVTransaction
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		values := ParseCSVLine(line)
+		if len(values) <= dateIdx || len(values) <= descriptionIdx || len(values) <= amountIdx {
+			continue
+		}
+
+		amount, ok := parseCSVAmount(values[amountIdx])
+		if !ok {
+			continue
+		}
+		if mapping.AmountSignConvention == models.AmountSignDebitNegative {
+			amount = -amount
+		}
+
+		date := values[dateIdx]
+		if layout != "" {
+			parsed, err := time.Parse(layout, date)
+			if err != nil {
+				continue
+			}
+			date = parsed.Format("2006-01-02")
+		}
+
+		transactions = append(transactions, models.CSVTransaction{
+			Date:        date,
+			Description: values[descriptionIdx],
+			Amount:      amount,
+		})
+		if limit > 0 && len(transactions) >= limit {
+			break
+		}
+	}
+
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("no valid transactions found in CSV")
+	}
+	return transactions, nil
+}
+
+// resolveCSVColumn finds a column by its mapped name, or by keyword when the
+// mapping leaves it empty
+func resolveCSVColumn(header []string, name, field string) (int, error) {
+	if name != "" {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for i, column := range header {
+			if column == name {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: %s column %q", ErrCSVColumnNotFound, field, name)
+	}
+	for _, keyword := range csvColumnKeywords[field] {
+		for i, column := range header {
+			if strings.Contains(column, keyword) {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: no %s column detected", ErrCSVColumnNotFound, field)
+}
+
+// CSVDateLayout converts a date format written with YYYY, YY, MM, M, DD and D
+// tokens into a Go time layout
+func CSVDateLayout(format string) (string, error) {
+	upper := strings.ToUpper(format)
+	if !strings.Contains(upper, "YY") || !strings.Contains(upper, "M") || !strings.Contains(upper, "D") {
+		return "", fmt.Errorf("invalid date format %q: needs year, month and day", format)
+	}
+	layout := strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "M", "1", "D", "2").Replace(upper)
+	if strings.ContainsAny(layout, "ABCEFGHIJKLNOPQRSTUVWXYZ") {
+		return "", fmt.Errorf("invalid date format %q", format)
+	}
+	return layout, nil
+}
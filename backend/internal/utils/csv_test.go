@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/mesbahtanvir/focus-notebook/backend/internal/models"
 )
 
 func TestParseCSVLine_SimpleFields(t *testing.T) {
@@ -279,3 +281,81 @@ func TestParseCSV_SmallAmount(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0.01, transactions[0].Amount)
 }
+
+func TestParseCSVWithMapping_ChaseLayout(t *testing.T) {
+	content := "Transaction Date,Post Date,Description,Category,Type,Amount,Memo\r\n" +
+		"01/15/2024,01/16/2024,STARBUCKS STORE 123,Food & Drink,Sale,-5.75,\r\n" +
+		"01/17/2024,01/17/2024,Payment Thank You-Mobile,,Payment,500.00,\r\n"
+	mapping := models.CSVMapping{
+		DateColumn:           "Transaction Date",
+		DescriptionColumn:    "Description",
+		AmountColumn:         "Amount",
+		DateFormat:           "MM/DD/YYYY",
+		AmountSignConvention: models.AmountSignDebitNegative,
+	}
+
+	transactions, err := ParseCSVWithMapping(content, mapping, 0)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "2024-01-15", transactions[0].Date)
+	assert.Equal(t, "STARBUCKS STORE 123", transactions[0].Description)
+	assert.Equal(t, 5.75, transactions[0].Amount)
+	assert.Equal(t, -500.0, transactions[1].Amount)
+}
+
+func TestParseCSVWithMapping_AmexLayout(t *testing.T) {
+	content := `Date,Description,Card Member,Account #,Amount
+02/03/2024,"AMAZON.COM, SEATTLE WA",JANE DOE,-41001,"1,234.56"
+02/04/2024,AUTOPAY PAYMENT,JANE DOE,-41001,-200.00`
+	mapping := models.CSVMapping{DateFormat: "MM/DD/YYYY"}
+
+	transactions, err := ParseCSVWithMapping(content, mapping, 0)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "2024-02-03", transactions[0].Date)
+	assert.Equal(t, "AMAZON.COM, SEATTLE WA", transactions[0].Description)
+	assert.Equal(t, 1234.56, transactions[0].Amount)
+	assert.Equal(t, -200.0, transactions[1].Amount)
+}
+
+func TestParseCSVWithMapping_UKDayFirstLayout(t *testing.T) {
+	content := "\ufeffDate,Type,Details,Value,Balance\n" +
+		"31/01/2024,DEB,TESCO STORES 2041,-23.10,1000.00\n" +
+		"not a date,DEB,SKIPPED,-1.00,999.00\n" +
+		"01/02/2024,BGC,SALARY,2500.00,3500.00\n"
+	mapping := models.CSVMapping{
+		DateColumn:           "date",
+		DescriptionColumn:    "details",
+		AmountColumn:         "value",
+		DateFormat:           "DD/MM/YYYY",
+		AmountSignConvention: models.AmountSignDebitNegative,
+	}
+
+	transactions, err := ParseCSVWithMapping(content, mapping, 0)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "2024-01-31", transactions[0].Date)
+	assert.Equal(t, 23.10, transactions[0].Amount)
+	assert.Equal(t, "2024-02-01", transactions[1].Date)
+	assert.Equal(t, -2500.0, transactions[1].Amount)
+
+	limited, err := ParseCSVWithMapping(content, mapping, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+}
+
+func TestParseCSVWithMapping_MissingColumn(t *testing.T) {
+	_, err := ParseCSVWithMapping("Date,Description,Amount\n2024-01-01,Coffee,5", models.CSVMapping{AmountColumn: "debit"}, 0)
+	assert.ErrorIs(t, err, ErrCSVColumnNotFound)
+}
+
+func TestCSVDateLayout(t *testing.T) {
+	layout, err := CSVDateLayout("dd.mm.yyyy")
+	require.NoError(t, err)
+	assert.Equal(t, "02.01.2006", layout)
+
+	_, err = CSVDateLayout("MM/YYYY")
+	assert.Error(t, err)
+	_, err = CSVDateLayout("MM/DD/YYYY HH")
+	assert.Error(t, err)
+}
@@ -17,6 +17,10 @@ type Admin struct {
 	Auth      *auth.Client
 	Firestore *firestore.Client
 	Storage   *storage.Client
+
+	projectID string
+	opt       option.ClientOption
+	databases []*firestore.Client // opened by OpenFirestoreDatabase
 }
 
 // Config holds Firebase initialization configuration
@@ -55,8 +59,13 @@ func Initialize(ctx context.Context, cfg *Config) (*Admin, error) {
 		return nil, fmt.Errorf("failed to initialize Auth client: %w", err)
 	}
 
-	// Initialize Firestore client
-	firestoreClient, err := app.Firestore(ctx)
+	// Initialize Firestore client; app.Firestore only reaches the default database
+	var firestoreClient *firestore.Client
+	if cfg.DatabaseID == "" || cfg.DatabaseID == firestore.DefaultDatabaseID {
+		firestoreClient, err = app.Firestore(ctx)
+	} else {
+		firestoreClient, err = firestore.NewClientWithDatabase(ctx, cfg.ProjectID, cfg.DatabaseID, opt)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Firestore client: %w", err)
 	}
@@ -72,11 +81,29 @@ func Initialize(ctx context.Context, cfg *Config) (*Admin, error) {
 		Auth:      authClient,
 		Firestore: firestoreClient,
 		Storage:   storageClient,
+		projectID: cfg.ProjectID,
+		opt:       opt,
 	}, nil
 }
 
+// OpenFirestoreDatabase opens a client on another Firestore database of the
+// same project. Close closes it along with the default client.
+func (a *Admin) OpenFirestoreDatabase(ctx context.Context, databaseID string) (*firestore.Client, error) {
+	client, err := firestore.NewClientWithDatabase(ctx, a.projectID, databaseID, a.opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Firestore database %q: %w", databaseID, err)
+	}
+	a.databases = append(a.databases, client)
+	return client, nil
+}
+
 // Close closes all Firebase clients
 func (a *Admin) Close() error {
+	for _, client := range a.databases {
+		if err := client.Close(); err != nil {
+			return fmt.Errorf("failed to close Firestore client: %w", err)
+		}
+	}
 	if a.Firestore != nil {
 		if err := a.Firestore.Close(); err != nil {
 			return fmt.Errorf("failed to close Firestore client: %w", err)